@@ -11,8 +11,16 @@ import (
 	"time"
 
 	httpHandler "xyz-multifinance/internal/delivery/http"
+	"xyz-multifinance/internal/domain"
 	"xyz-multifinance/internal/middleware"
 	"xyz-multifinance/internal/pkg/crypto"
+	"xyz-multifinance/internal/pkg/eventbus"
+	"xyz-multifinance/internal/pkg/notify"
+	"xyz-multifinance/internal/pkg/ratelimit"
+	"xyz-multifinance/internal/pkg/scheduler"
+	"xyz-multifinance/internal/pkg/scorecard"
+	"xyz-multifinance/internal/pkg/signing"
+	"xyz-multifinance/internal/pkg/waf"
 	"xyz-multifinance/internal/repository"
 	"xyz-multifinance/internal/usecase"
 
@@ -50,10 +58,158 @@ func main() {
 	// Initialize repositories
 	customerRepo := repository.NewCustomerRepository(db)
 	transactionRepo := repository.NewTransactionRepository(db)
+	erasureRepo := repository.NewErasureRequestRepository(db)
+	partnerRepo := repository.NewPartnerRepository(db)
+	merchantRepo := repository.NewMerchantRepository(db)
+	settlementRepo := repository.NewSettlementRepository(db)
+	webhookRepo := repository.NewWebhookRepository(db)
+	outboxRepo := repository.NewOutboxRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
+	paymentRepo := repository.NewPaymentRepository(db)
+	reversalRepo := repository.NewReversalRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+	restructuringRepo := repository.NewRestructuringRepository(db)
+	underwritingRepo := repository.NewUnderwritingRepository(db)
+	blacklistRepo := repository.NewBlacklistRepository(db)
+	creditScoreRepo := repository.NewCreditScoreRepository(db)
+	screeningRepo := repository.NewScreeningRepository(db)
+	collectionRepo := repository.NewCollectionRepository(db)
+	provisioningRepo := repository.NewProvisioningRepository(db)
+	writeOffRepo := repository.NewWriteOffRepository(db)
+	ledgerRepo := repository.NewLedgerRepository(db)
+	accrualRepo := repository.NewAccrualRepository(db)
+	regulatoryReportRepo := repository.NewRegulatoryReportRepository(db)
+	analyticsRepo := repository.NewAnalyticsRepository(db)
+	exportRepo := repository.NewExportRepository(db)
 
 	// Initialize use cases
-	customerUseCase := usecase.NewCustomerUseCase(customerRepo)
-	transactionUseCase := usecase.NewTransactionUseCase(transactionRepo, customerUseCase, redisClient)
+	screeningPolicy := domain.ScreeningPolicy{
+		DuplicateIdentity: domain.RiskFlag(viper.GetString("screening.duplicate_identity")),
+		Velocity:          loadVelocityRules(),
+	}
+	if err := screeningPolicy.Validate(); err != nil {
+		sugar.Fatalf("Invalid screening configuration: %v", err)
+	}
+	screeningUseCase := usecase.NewScreeningUseCase(screeningRepo, blacklistRepo, customerRepo, screeningPolicy)
+	blacklistUseCase := usecase.NewBlacklistUseCase(blacklistRepo)
+	customerUseCase := usecase.NewCustomerUseCase(customerRepo, screeningUseCase)
+	transactionUseCase := usecase.NewTransactionUseCase(transactionRepo, customerUseCase, merchantRepo, screeningUseCase, redisClient)
+	erasureUseCase := usecase.NewErasureUseCase(
+		customerRepo,
+		erasureRepo,
+		time.Duration(viper.GetInt("privacy.retention_days"))*24*time.Hour,
+	)
+	merchantUseCase := usecase.NewMerchantUseCase(merchantRepo)
+	settlementUseCase := usecase.NewSettlementUseCase(settlementRepo, merchantRepo)
+	partnerUseCase := usecase.NewPartnerUseCase(
+		partnerRepo,
+		time.Duration(viper.GetInt("partner.key_rotation_grace"))*time.Second,
+	)
+	webhookUseCase := usecase.NewWebhookUseCase(
+		webhookRepo,
+		partnerRepo,
+		&http.Client{
+			Timeout: time.Duration(viper.GetInt("webhook.timeout")) * time.Second,
+			// A redirect would deliver the signed payload to an unregistered URL
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		domain.WebhookRetryPolicy{
+			MaxAttempts:    viper.GetInt("webhook.max_attempts"),
+			InitialBackoff: time.Duration(viper.GetInt("webhook.initial_backoff")) * time.Second,
+			MaxBackoff:     time.Duration(viper.GetInt("webhook.max_backoff")) * time.Second,
+		},
+		viper.GetInt("webhook.batch_size"),
+	)
+
+	bankPrefixes, bankSecrets := loadVirtualAccountBanks()
+	paymentUseCase := usecase.NewPaymentUseCase(
+		paymentRepo,
+		transactionRepo,
+		transactionUseCase,
+		bankPrefixes,
+		viper.GetString("virtual_account.statement_dir"),
+	)
+	reversalUseCase := usecase.NewReversalUseCase(
+		reversalRepo,
+		transactionRepo,
+		paymentRepo,
+		redisClient,
+		domain.LateFeePolicy{
+			DailyRate: viper.GetFloat64("installment.late_fee.daily_rate"),
+			MaxRate:   viper.GetFloat64("installment.late_fee.max_rate"),
+		},
+	)
+	auditUseCase := usecase.NewAuditUseCase(auditRepo)
+	restructuringUseCase := usecase.NewRestructuringUseCase(
+		restructuringRepo,
+		transactionRepo,
+		domain.RestructuringPolicy{
+			MaxTenor:         viper.GetInt("restructuring.max_tenor"),
+			MaxHolidayMonths: viper.GetInt("restructuring.max_holiday_months"),
+			MaxInterestRate:  viper.GetFloat64("restructuring.max_interest_rate"),
+		},
+	)
+	card, err := scorecard.LoadFile(viper.GetString("scoring.scorecard_file"))
+	if err != nil {
+		sugar.Fatalf("Failed to load scorecard: %v", err)
+	}
+	scoringUseCase := usecase.NewScoringUseCase(creditScoreRepo, customerRepo, card)
+	underwritingUseCase := usecase.NewUnderwritingUseCase(
+		underwritingRepo,
+		transactionRepo,
+		customerRepo,
+		blacklistRepo,
+		scoringUseCase,
+		domain.UnderwritingPolicy{
+			ReferDBR:             viper.GetFloat64("underwriting.refer_dbr"),
+			MaxDBR:               viper.GetFloat64("underwriting.max_dbr"),
+			MinAge:               viper.GetInt("underwriting.min_age"),
+			MaxAge:               viper.GetInt("underwriting.max_age"),
+			MaxOverdueDays:       viper.GetInt("underwriting.max_overdue_days"),
+			MaxAutoApproveAmount: viper.GetFloat64("underwriting.max_auto_approve_amount"),
+			ReviewSLA:            time.Duration(viper.GetInt("underwriting.review_sla")) * time.Second,
+		},
+	)
+	collectionUseCase := usecase.NewCollectionUseCase(
+		collectionRepo,
+		domain.CollectionPolicy{
+			FieldDPD:          viper.GetInt("collections.field_dpd"),
+			LegalDPD:          viper.GetInt("collections.legal_dpd"),
+			MaxBrokenPromises: viper.GetInt("collections.max_broken_promises"),
+		},
+	)
+	provisioningPolicy := domain.ProvisioningPolicy{Grades: loadProvisioningGrades()}
+	if err := provisioningPolicy.Validate(); err != nil {
+		sugar.Fatalf("Invalid provisioning configuration: %v", err)
+	}
+	provisioningUseCase := usecase.NewProvisioningUseCase(provisioningRepo, transactionRepo, provisioningPolicy)
+	writeOffUseCase := usecase.NewWriteOffUseCase(writeOffRepo, transactionRepo, provisioningPolicy)
+	ledgerUseCase := usecase.NewLedgerUseCase(ledgerRepo, transactionRepo)
+	accrualUseCase := usecase.NewAccrualUseCase(accrualRepo, transactionRepo, provisioningPolicy)
+	regulatoryReportUseCase := usecase.NewRegulatoryReportUseCase(regulatoryReportRepo, provisioningPolicy, viper.GetString("regulatory.reporter_code"))
+	analyticsUseCase := usecase.NewAnalyticsUseCase(analyticsRepo, redisClient, domain.AnalyticsConfig{
+		CacheTTL:   time.Duration(viper.GetInt("analytics.cache_ttl")) * time.Second,
+		VintageDPD: viper.GetInt("analytics.vintage_dpd"),
+		MaxMOB:     viper.GetInt("analytics.vintage_max_mob"),
+	})
+	exportUseCase := usecase.NewExportUseCase(exportRepo, customerRepo, transactionRepo)
+	notificationUseCase := usecase.NewNotificationUseCase(
+		notificationRepo,
+		customerRepo,
+		transactionRepo,
+		initNotificationSenders(logger),
+		viper.GetIntSlice("notification.reminder_days"),
+	)
+	eventBus := eventbus.New(redisClient, viper.GetString("events.stream"), viper.GetInt64("events.max_len"), logger)
+	eventRelayUseCase := usecase.NewEventRelayUseCase(outboxRepo, eventBus, viper.GetInt("events.batch_size"))
+	eventHandlers := []domain.EventHandler{
+		usecase.NewCreditLimitEventHandler(customerUseCase, transactionRepo),
+		usecase.NewNotificationEventHandler(notificationUseCase),
+		usecase.NewUnderwritingEventHandler(underwritingUseCase),
+		usecase.NewLedgerEventHandler(ledgerUseCase),
+	}
 
 	// Initialize Gin router
 	router := gin.Default()
@@ -64,26 +220,246 @@ func main() {
 		Issuer:    viper.GetString("jwt.issuer"),
 	}
 	rateLimiterConfig := middleware.RateLimiterConfig{
-		RedisClient: redisClient,
-		MaxRequests: viper.GetInt("rate_limit.max_requests"),
-		Window:      time.Duration(viper.GetInt("rate_limit.window")) * time.Second,
+		Limiter:  ratelimit.NewRedisLimiter(redisClient),
+		Default:  loadRateLimitPolicy("default", "rate_limit"),
+		Policies: loadRateLimitPolicies(),
+		FailOpen: viper.GetBool("rate_limit.fail_open"),
+		Logger:   logger,
+	}
+	corsDefault := loadCORSPolicy("cors", middleware.CORSPolicy{})
+	if err := corsDefault.Validate(); err != nil {
+		sugar.Fatalf("Invalid CORS configuration: %v", err)
+	}
+	corsConfig := middleware.CORSConfig{
+		Default: corsDefault,
+		Routes:  make(map[string]middleware.CORSPolicy),
+	}
+	for name := range viper.GetStringMap("cors.routes") {
+		key := "cors.routes." + name
+		corsConfig.Routes[viper.GetString(key+".path")] = loadCORSPolicy(key, corsDefault)
+	}
+	for path, policy := range corsConfig.Routes {
+		if err := policy.Validate(); err != nil {
+			sugar.Fatalf("Invalid CORS configuration for %s: %v", path, err)
+		}
+	}
+	partnerAuthConfig := middleware.PartnerAuthConfig{
+		Partners:     partnerUseCase,
+		Nonces:       signing.NewRedisNonceStore(redisClient),
+		MaxClockSkew: time.Duration(viper.GetInt("partner.max_clock_skew")) * time.Second,
+	}
+	bankCallbackConfig := middleware.BankCallbackConfig{
+		Secrets:      bankSecrets,
+		Nonces:       signing.NewRedisNonceStore(redisClient),
+		MaxClockSkew: time.Duration(viper.GetInt("virtual_account.max_clock_skew")) * time.Second,
+	}
+	wafEngine, err := waf.LoadFile(viper.GetString("waf.rules_file"))
+	if err != nil {
+		sugar.Fatalf("Failed to load WAF rules: %v", err)
+	}
+	wafConfig := middleware.WAFConfig{
+		Engine:         wafEngine,
+		Mode:           waf.Mode(viper.GetString("waf.mode")),
+		MaxBodyBytes:   viper.GetInt64("waf.max_body_bytes"),
+		InspectHeaders: viper.GetStringSlice("waf.inspect_headers"),
+		UploadRoutes:   viper.GetStringSlice("waf.upload_routes"),
+		Logger:         logger,
 	}
 
 	// Apply global middlewares
 	router.Use(
+		middleware.NewErrorHandlerMiddleware(logger),
+		middleware.NewCORSMiddleware(corsConfig),
 		middleware.SecurityHeadersMiddleware(),
-		middleware.NewSQLInjectionMiddleware(),
-		middleware.NewRateLimiterMiddleware(rateLimiterConfig),
+		middleware.NewWAFMiddleware(wafConfig),
 	)
 
-	// Initialize HTTP handlers
-	httpHandler.NewCustomerHandler(router, customerUseCase)
-	httpHandler.NewTransactionHandler(router, transactionUseCase)
+	// Initialize HTTP handlers. Requests are rate limited after
+	// authentication, so they are counted per user or partner.
+	rateLimit := middleware.NewRateLimiterMiddleware(rateLimiterConfig)
+	httpHandler.NewCustomerHandler(router, customerUseCase, rateLimit,
+		middleware.NewAuthMiddleware(authConfig),
+		rateLimit,
+		middleware.RequireRole("admin", "operator"),
+	)
+	httpHandler.NewTransactionHandler(router, transactionUseCase, rateLimit, middleware.NewPartnerAuthMiddleware(partnerAuthConfig),
+		middleware.NewAuthMiddleware(authConfig),
+		rateLimit,
+		middleware.RequireRole("admin", "operator"),
+	)
+	httpHandler.NewErasureHandler(router, erasureUseCase,
+		middleware.NewAuthMiddleware(authConfig),
+		rateLimit,
+		middleware.RequireRole("admin", "operator"),
+	)
+	httpHandler.NewNotificationHandler(router, notificationUseCase,
+		middleware.NewAuthMiddleware(authConfig),
+		rateLimit,
+		middleware.RequireCustomerOrRole("id", "admin", "operator"),
+	)
+	httpHandler.NewPartnerHandler(router, partnerUseCase,
+		middleware.NewAuthMiddleware(authConfig),
+		rateLimit,
+		middleware.RequireRole("admin"),
+	)
+	httpHandler.NewMerchantHandler(router, merchantUseCase, settlementUseCase,
+		middleware.NewAuthMiddleware(authConfig),
+		rateLimit,
+		middleware.RequireRole("admin", "finance"),
+	)
+	httpHandler.NewSettlementHandler(router, settlementUseCase,
+		middleware.NewAuthMiddleware(authConfig),
+		rateLimit,
+		middleware.RequireRole("admin", "finance"),
+	)
+	httpHandler.NewPaymentHandler(router, paymentUseCase, rateLimit,
+		middleware.NewBankCallbackMiddleware(bankCallbackConfig),
+		middleware.NewAuthMiddleware(authConfig),
+		rateLimit,
+		middleware.RequireRole("admin", "finance"),
+	)
+	httpHandler.NewWebhookHandler(router, webhookUseCase,
+		middleware.NewAuthMiddleware(authConfig),
+		rateLimit,
+		middleware.RequireRole("admin"),
+	)
+	httpHandler.NewReversalHandler(router, reversalUseCase,
+		middleware.NewAuthMiddleware(authConfig),
+		rateLimit,
+		middleware.RequireRole("operator"),
+	)
+	httpHandler.NewRestructuringHandler(router, restructuringUseCase,
+		middleware.RequireRole("admin"),
+		middleware.NewAuthMiddleware(authConfig),
+		rateLimit,
+		middleware.RequireRole("admin", "operator"),
+	)
+	httpHandler.NewUnderwritingHandler(router, underwritingUseCase,
+		middleware.NewAuthMiddleware(authConfig),
+		rateLimit,
+		middleware.RequireRole("admin", "operator"),
+	)
+	httpHandler.NewScoringHandler(router, scoringUseCase,
+		middleware.NewAuthMiddleware(authConfig),
+		rateLimit,
+		middleware.RequireRole("admin", "operator"),
+	)
+	httpHandler.NewBlacklistHandler(router, blacklistUseCase,
+		middleware.NewAuthMiddleware(authConfig),
+		rateLimit,
+		middleware.RequireRole("admin", "operator"),
+	)
+	httpHandler.NewCollectionHandler(router, collectionUseCase,
+		middleware.RequireRole("admin", "operator"),
+		middleware.NewAuthMiddleware(authConfig),
+		rateLimit,
+		middleware.RequireRole("admin", "operator", "collector"),
+	)
+	httpHandler.NewProvisioningHandler(router, provisioningUseCase, writeOffUseCase,
+		middleware.NewAuthMiddleware(authConfig),
+		rateLimit,
+		middleware.RequireRole("admin", "finance"),
+	)
+	httpHandler.NewLedgerHandler(router, ledgerUseCase,
+		middleware.NewAuthMiddleware(authConfig),
+		rateLimit,
+		middleware.RequireRole("admin", "finance"),
+	)
+	httpHandler.NewInterestHandler(router, accrualUseCase,
+		middleware.NewAuthMiddleware(authConfig),
+		rateLimit,
+		middleware.RequireRole("admin", "finance"),
+	)
+	httpHandler.NewRegulatoryReportHandler(router, regulatoryReportUseCase,
+		middleware.NewAuthMiddleware(authConfig),
+		rateLimit,
+		middleware.RequireRole("admin", "finance"),
+	)
+	httpHandler.NewAnalyticsHandler(router, analyticsUseCase,
+		middleware.NewAuthMiddleware(authConfig),
+		rateLimit,
+		middleware.RequireRole("admin", "finance"),
+	)
+	httpHandler.NewExportHandler(router, exportUseCase,
+		middleware.NewAuthMiddleware(authConfig),
+		rateLimit,
+		middleware.RequireRole("admin", "operator", "finance"),
+	)
+	httpHandler.NewAuditHandler(router, auditUseCase,
+		middleware.NewAuthMiddleware(authConfig),
+		rateLimit,
+		middleware.RequireRole("admin"),
+	)
 
 	// Protected routes
 	protected := router.Group("/api/v1")
 	protected.Use(middleware.NewAuthMiddleware(authConfig))
 
+	// Start background jobs
+	jobCtx, stopJobs := context.WithCancel(context.Background())
+	jobs := scheduler.New(logger)
+	jobs.Every(jobCtx, "customer_erasure", time.Duration(viper.GetInt("privacy.erasure_interval"))*time.Second, func(ctx context.Context) error {
+		_, err := erasureUseCase.ProcessDueRequests(time.Now())
+		return err
+	})
+	jobs.Every(jobCtx, "merchant_settlement", time.Duration(viper.GetInt("settlement.interval"))*time.Second, func(ctx context.Context) error {
+		_, err := settlementUseCase.RunDaily(time.Now())
+		return err
+	})
+	jobs.Every(jobCtx, "installment_overdue", time.Duration(viper.GetInt("installment.overdue_interval"))*time.Second, func(ctx context.Context) error {
+		_, err := transactionUseCase.MarkOverdueInstallments(time.Now())
+		return err
+	})
+	jobs.Every(jobCtx, "underwriting_expiry", time.Duration(viper.GetInt("underwriting.expiry_interval"))*time.Second, func(ctx context.Context) error {
+		_, err := underwritingUseCase.ExpireStale(time.Now())
+		return err
+	})
+	jobs.Every(jobCtx, "collections_sync", time.Duration(viper.GetInt("collections.sync_interval"))*time.Second, func(ctx context.Context) error {
+		_, err := collectionUseCase.Sync(time.Now())
+		return err
+	})
+	jobs.Every(jobCtx, "provision_report", time.Duration(viper.GetInt("provisioning.report_interval"))*time.Second, func(ctx context.Context) error {
+		_, err := provisioningUseCase.GenerateMonthly(time.Now())
+		return err
+	})
+	jobs.Every(jobCtx, "interest_accrual", time.Duration(viper.GetInt("interest.accrual_interval"))*time.Second, func(ctx context.Context) error {
+		_, err := accrualUseCase.AccrueDaily(time.Now())
+		return err
+	})
+	jobs.Every(jobCtx, "webhook_dispatch", time.Duration(viper.GetInt("webhook.dispatch_interval"))*time.Second, func(ctx context.Context) error {
+		_, err := webhookUseCase.Dispatch(ctx, time.Now())
+		return err
+	})
+
+	jobs.Every(jobCtx, "va_reconciliation", time.Duration(viper.GetInt("virtual_account.reconcile_interval"))*time.Second, func(ctx context.Context) error {
+		_, err := paymentUseCase.ReconcileDaily(time.Now())
+		return err
+	})
+	jobs.Every(jobCtx, "installment_reminder", time.Duration(viper.GetInt("notification.reminder_interval"))*time.Second, func(ctx context.Context) error {
+		_, err := notificationUseCase.SendReminders(ctx, time.Now())
+		return err
+	})
+	jobs.Every(jobCtx, "event_relay", time.Duration(viper.GetInt("events.relay_interval"))*time.Second, func(ctx context.Context) error {
+		_, err := eventRelayUseCase.Relay(ctx)
+		return err
+	})
+
+	// Each process consumes as its own member of every consumer group
+	hostname, _ := os.Hostname()
+	consumerConfig := eventbus.ConsumerConfig{
+		Consumer:      fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		Batch:         viper.GetInt64("events.batch_size"),
+		Block:         time.Duration(viper.GetInt("events.block")) * time.Second,
+		ClaimIdle:     time.Duration(viper.GetInt("events.claim_idle")) * time.Second,
+		MaxDeliveries: viper.GetInt64("events.max_deliveries"),
+	}
+	for _, handler := range eventHandlers {
+		handler := handler
+		jobs.Every(jobCtx, "events:"+handler.Group(), time.Duration(viper.GetInt("events.poll_interval"))*time.Second, func(ctx context.Context) error {
+			return eventBus.Poll(ctx, handler, consumerConfig)
+		})
+	}
+
 	// Start server
 	srv := &http.Server{
 		Addr:    fmt.Sprintf(":%d", viper.GetInt("server.port")),
@@ -103,6 +479,9 @@ func main() {
 	<-quit
 	sugar.Info("Shutting down server...")
 
+	stopJobs()
+	jobs.Wait()
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	if err := srv.Shutdown(ctx); err != nil {
@@ -122,6 +501,97 @@ func loadConfig() {
 	}
 }
 
+func loadRateLimitPolicy(name, key string) ratelimit.Policy {
+	return ratelimit.Policy{
+		Name:      name,
+		Algorithm: ratelimit.Algorithm(viper.GetString(key + ".algorithm")),
+		Limit:     viper.GetInt(key + ".max_requests"),
+		Window:    time.Duration(viper.GetInt(key+".window")) * time.Second,
+		Burst:     viper.GetInt(key + ".burst"),
+	}
+}
+
+// loadVirtualAccountBanks reads the account number prefix and callback
+// secret of every bank offering virtual accounts
+func loadVirtualAccountBanks() (map[string]string, map[string][]byte) {
+	prefixes := make(map[string]string)
+	secrets := make(map[string][]byte)
+	for code := range viper.GetStringMap("virtual_account.banks") {
+		key := "virtual_account.banks." + code
+		prefixes[code] = viper.GetString(key + ".prefix")
+		secrets[code] = []byte(viper.GetString(key + ".callback_secret"))
+	}
+	return prefixes, secrets
+}
+
+// loadVelocityRules reads the screening velocity rules, named by their key
+// in the configuration
+func loadVelocityRules() []domain.VelocityRule {
+	var rules []domain.VelocityRule
+	for name := range viper.GetStringMap("screening.velocity") {
+		key := "screening.velocity." + name
+		rules = append(rules, domain.VelocityRule{
+			Name:         name,
+			Key:          domain.VelocityKey(viper.GetString(key + ".key")),
+			Window:       time.Duration(viper.GetInt(key+".window")) * time.Second,
+			MaxContracts: viper.GetInt(key + ".max_contracts"),
+			Action:       domain.RiskFlag(viper.GetString(key + ".action")),
+		})
+	}
+	return rules
+}
+
+// loadProvisioningGrades reads the collectibility grades, keyed by their OJK
+// name in the configuration, from the best to the worst
+func loadProvisioningGrades() []domain.GradeRule {
+	var grades []domain.GradeRule
+	for _, collectibility := range domain.Collectibilities {
+		key := "provisioning.grades." + collectibility.Name()
+		if !viper.IsSet(key) {
+			continue
+		}
+		grades = append(grades, domain.GradeRule{
+			Collectibility: collectibility,
+			MaxDPD:         viper.GetInt(key + ".max_dpd"),
+			ProvisionRate:  viper.GetFloat64(key + ".provision_rate"),
+		})
+	}
+	return grades
+}
+
+func loadRateLimitPolicies() map[string]ratelimit.Policy {
+	policies := make(map[string]ratelimit.Policy)
+	for name := range viper.GetStringMap("rate_limit.policies") {
+		key := "rate_limit.policies." + name
+		policies[viper.GetString(key+".route")] = loadRateLimitPolicy(name, key)
+	}
+	return policies
+}
+
+// loadCORSPolicy reads the CORS policy at key, inheriting unset values from base
+func loadCORSPolicy(key string, base middleware.CORSPolicy) middleware.CORSPolicy {
+	policy := base
+	if viper.IsSet(key + ".allowed_origins") {
+		policy.AllowedOrigins = viper.GetStringSlice(key + ".allowed_origins")
+	}
+	if viper.IsSet(key + ".allowed_methods") {
+		policy.AllowedMethods = viper.GetStringSlice(key + ".allowed_methods")
+	}
+	if viper.IsSet(key + ".allowed_headers") {
+		policy.AllowedHeaders = viper.GetStringSlice(key + ".allowed_headers")
+	}
+	if viper.IsSet(key + ".exposed_headers") {
+		policy.ExposedHeaders = viper.GetStringSlice(key + ".exposed_headers")
+	}
+	if viper.IsSet(key + ".allow_credentials") {
+		policy.AllowCredentials = viper.GetBool(key + ".allow_credentials")
+	}
+	if viper.IsSet(key + ".max_age") {
+		policy.MaxAge = time.Duration(viper.GetInt(key+".max_age")) * time.Second
+	}
+	return policy
+}
+
 func initDB() (*gorm.DB, error) {
 	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
 		viper.GetString("database.host"),
@@ -141,3 +611,37 @@ func initRedis() *redis.Client {
 		DB:       viper.GetInt("redis.db"),
 	})
 }
+
+// initNotificationSenders creates the sender of every notification channel,
+// falling back to a fake sender that only logs messages
+func initNotificationSenders(logger *zap.Logger) map[domain.NotificationChannel]domain.NotificationSender {
+	client := &http.Client{Timeout: time.Duration(viper.GetInt("notification.timeout")) * time.Second}
+	gateway := func(key string) notify.GatewayConfig {
+		return notify.GatewayConfig{
+			URL:    viper.GetString(key + ".url"),
+			APIKey: viper.GetString(key + ".api_key"),
+		}
+	}
+
+	senders := make(map[domain.NotificationChannel]domain.NotificationSender)
+	for _, channel := range domain.NotificationChannels {
+		key := "notification." + string(channel)
+		switch driver := viper.GetString(key + ".driver"); {
+		case channel == domain.ChannelEmail && driver == "smtp":
+			senders[channel] = notify.NewSMTPSender(notify.SMTPConfig{
+				Addr:     viper.GetString(key + ".addr"),
+				Username: viper.GetString(key + ".username"),
+				Password: viper.GetString(key + ".password"),
+				From:     viper.GetString(key + ".from"),
+			})
+		case channel == domain.ChannelSMS && driver == "gateway":
+			senders[channel] = notify.NewSMSSender(gateway(key), client)
+		case channel == domain.ChannelPush && driver == "gateway":
+			senders[channel] = notify.NewPushSender(gateway(key), client)
+		case driver == "disabled":
+		default:
+			senders[channel] = notify.NewFake(logger.With(zap.String("channel", string(channel))))
+		}
+	}
+	return senders
+}
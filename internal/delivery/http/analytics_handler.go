@@ -0,0 +1,121 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+	"xyz-multifinance/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// monthLayout formats the months of analytics queries
+const monthLayout = "2006-01"
+
+type AnalyticsHandler struct {
+	analyticsUseCase domain.AnalyticsUseCase
+}
+
+// NewAnalyticsHandler registers the portfolio analytics routes behind the
+// given middlewares, which are expected to authenticate finance staff
+func NewAnalyticsHandler(router *gin.Engine, analyticsUseCase domain.AnalyticsUseCase, middlewares ...gin.HandlerFunc) {
+	handler := &AnalyticsHandler{
+		analyticsUseCase: analyticsUseCase,
+	}
+
+	routes := router.Group("/api/v1/analytics", middlewares...)
+	{
+		routes.GET("/outstanding", handler.Outstanding)
+		routes.GET("/disbursements", handler.Disbursements)
+		routes.GET("/utilisation", handler.Utilisation)
+		routes.GET("/collection-rate", handler.CollectionRates)
+		routes.GET("/vintage", handler.Vintage)
+		routes.GET("/roll-rate", handler.RollRate)
+	}
+}
+
+// Outstanding returns the outstanding principal and interest of the
+// approved contracts, by source and tenor
+func (h *AnalyticsHandler) Outstanding(c *gin.Context) {
+	summary, err := h.analyticsUseCase.Outstanding(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// Disbursements totals the contracts disbursed by month and source, over
+// the last twelve months by default
+func (h *AnalyticsHandler) Disbursements(c *gin.Context) {
+	from, to := monthQuery(c)
+	disbursements, err := h.analyticsUseCase.Disbursements(c.Request.Context(), from, to)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, disbursements)
+}
+
+// Utilisation returns the distribution of credit limit utilisation
+func (h *AnalyticsHandler) Utilisation(c *gin.Context) {
+	utilisation, err := h.analyticsUseCase.Utilisation(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, utilisation)
+}
+
+// CollectionRates compares the installments due by month with the amounts
+// collected on them, over the last twelve months by default
+func (h *AnalyticsHandler) CollectionRates(c *gin.Context) {
+	from, to := monthQuery(c)
+	rates, err := h.analyticsUseCase.CollectionRates(c.Request.Context(), from, to)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rates)
+}
+
+// Vintage returns the delinquency rates of the monthly cohorts by months on
+// book, over the last twelve months by default
+func (h *AnalyticsHandler) Vintage(c *gin.Context) {
+	from, to := monthQuery(c)
+	dpd, _ := strconv.Atoi(c.DefaultQuery("dpd", "0"))
+
+	vintage, err := h.analyticsUseCase.Vintage(c.Request.Context(), from, to, dpd)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, vintage)
+}
+
+// RollRate returns the contracts rolling between delinquency states over a
+// month, the last month ended by default
+func (h *AnalyticsHandler) RollRate(c *gin.Context) {
+	now := time.Now()
+	month := c.DefaultQuery("month", time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, now.Location()).Format(monthLayout))
+	rollRate, err := h.analyticsUseCase.RollRate(c.Request.Context(), month)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rollRate)
+}
+
+// monthQuery reads the from and to months of a query, defaulting to the
+// twelve months up to the current one
+func monthQuery(c *gin.Context) (string, string) {
+	now := time.Now()
+	return c.DefaultQuery("from", time.Date(now.Year(), now.Month()-11, 1, 0, 0, 0, 0, now.Location()).Format(monthLayout)),
+		c.DefaultQuery("to", now.Format(monthLayout))
+}
@@ -0,0 +1,50 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"xyz-multifinance/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditHandler struct {
+	auditUseCase domain.AuditUseCase
+}
+
+// NewAuditHandler registers the audit log routes behind the given
+// middlewares, which are expected to restrict access to administrators
+func NewAuditHandler(router *gin.Engine, auditUseCase domain.AuditUseCase, middlewares ...gin.HandlerFunc) {
+	handler := &AuditHandler{
+		auditUseCase: auditUseCase,
+	}
+
+	auditRoutes := router.Group("/api/v1/audit-logs", middlewares...)
+	{
+		auditRoutes.GET("", handler.List)
+	}
+}
+
+// List lists audit log entries, newest first, optionally filtered by
+// entity_type and entity_id
+func (h *AuditHandler) List(c *gin.Context) {
+	var entityID uint
+	if value := c.Query("entity_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			c.Error(domain.NewError(domain.ErrValidation, "invalid_entity_id", "invalid entity ID"))
+			return
+		}
+		entityID = uint(id)
+	}
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	logs, err := h.auditUseCase.List(c.Query("entity_type"), entityID, offset, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, logs)
+}
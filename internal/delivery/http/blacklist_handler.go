@@ -0,0 +1,92 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"xyz-multifinance/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type BlacklistHandler struct {
+	blacklistUseCase domain.BlacklistUseCase
+	validate         *validator.Validate
+}
+
+// NewBlacklistHandler registers the blacklist maintenance routes behind the
+// given middlewares, which are expected to authenticate back office staff
+func NewBlacklistHandler(router *gin.Engine, blacklistUseCase domain.BlacklistUseCase, middlewares ...gin.HandlerFunc) {
+	handler := &BlacklistHandler{
+		blacklistUseCase: blacklistUseCase,
+		validate:         validator.New(),
+	}
+
+	routes := router.Group("/api/v1/blacklist", middlewares...)
+	{
+		routes.GET("", handler.List)
+		routes.POST("", handler.Add)
+		routes.DELETE("/:id", handler.Remove)
+	}
+}
+
+type AddBlacklistEntryRequest struct {
+	Type   string `json:"type" validate:"required,oneof=nik phone device"`
+	Value  string `json:"value" validate:"required,max=100"`
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+func (h *BlacklistHandler) List(c *gin.Context) {
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	entries, err := h.blacklistUseCase.List(domain.BlacklistType(c.Query("type")), offset, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// Add bars an identity number, phone number or device from registering and
+// from new contracts
+func (h *BlacklistHandler) Add(c *gin.Context) {
+	var req AddBlacklistEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	entry := &domain.BlacklistEntry{
+		Type:   domain.BlacklistType(req.Type),
+		Value:  req.Value,
+		Reason: req.Reason,
+	}
+	if err := h.blacklistUseCase.Add(entry, actor(c)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, entry)
+}
+
+func (h *BlacklistHandler) Remove(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_blacklist_entry_id", "invalid blacklist entry ID"))
+		return
+	}
+
+	if err := h.blacklistUseCase.Remove(uint(id), actor(c)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
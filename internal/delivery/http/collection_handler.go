@@ -0,0 +1,267 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+	"xyz-multifinance/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type CollectionHandler struct {
+	collectionUseCase domain.CollectionUseCase
+	validate          *validator.Validate
+}
+
+// NewCollectionHandler registers the collections routes behind the given
+// middlewares, which are expected to authenticate back office staff and
+// collectors. Managing collectors and assigning accounts additionally
+// require managerAuth.
+func NewCollectionHandler(router *gin.Engine, collectionUseCase domain.CollectionUseCase, managerAuth gin.HandlerFunc, middlewares ...gin.HandlerFunc) {
+	handler := &CollectionHandler{
+		collectionUseCase: collectionUseCase,
+		validate:          validator.New(),
+	}
+
+	routes := router.Group("/api/v1/collections", middlewares...)
+	{
+		routes.GET("/collectors", managerAuth, handler.ListCollectors)
+		routes.POST("/collectors", managerAuth, handler.CreateCollector)
+		routes.PUT("/collectors/:id", managerAuth, handler.UpdateCollector)
+		routes.GET("/worklist", handler.Worklist)
+		routes.GET("/accounts/:id", handler.GetAccount)
+		routes.PUT("/accounts/:id/assign", managerAuth, handler.Assign)
+		routes.POST("/accounts/:id/activities", handler.LogActivity)
+	}
+}
+
+type CollectorRequest struct {
+	UserID      uint   `json:"user_id" validate:"required"`
+	Name        string `json:"name" validate:"required,max=100"`
+	Level       string `json:"level" validate:"required,oneof=desk field legal"`
+	Region      string `json:"region" validate:"max=50"`
+	MaxAccounts int    `json:"max_accounts" validate:"gte=0"`
+}
+
+type UpdateCollectorRequest struct {
+	Name        string `json:"name" validate:"required,max=100"`
+	Level       string `json:"level" validate:"required,oneof=desk field legal"`
+	Region      string `json:"region" validate:"max=50"`
+	MaxAccounts int    `json:"max_accounts" validate:"gte=0"`
+	Active      bool   `json:"active"`
+}
+
+type AssignAccountRequest struct {
+	CollectorID uint `json:"collector_id" validate:"required"`
+}
+
+type CollectionActivityRequest struct {
+	Type          string  `json:"type" validate:"required,oneof=call message visit note promise_to_pay"`
+	Outcome       string  `json:"outcome" validate:"max=50"`
+	Note          string  `json:"note" validate:"max=1000"`
+	PromiseDate   string  `json:"promise_date" validate:"required_if=Type promise_to_pay"`
+	PromiseAmount float64 `json:"promise_amount" validate:"required_if=Type promise_to_pay,gte=0"`
+}
+
+func (h *CollectionHandler) ListCollectors(c *gin.Context) {
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	collectors, err := h.collectionUseCase.ListCollectors(offset, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, collectors)
+}
+
+func (h *CollectionHandler) CreateCollector(c *gin.Context) {
+	var req CollectorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	collector := &domain.Collector{
+		UserID:      req.UserID,
+		Name:        req.Name,
+		Level:       domain.CollectionLevel(req.Level),
+		Region:      req.Region,
+		MaxAccounts: req.MaxAccounts,
+	}
+	if err := h.collectionUseCase.CreateCollector(collector); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, collector)
+}
+
+func (h *CollectionHandler) UpdateCollector(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_collector_id", "invalid collector ID"))
+		return
+	}
+
+	var req UpdateCollectorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	collector := &domain.Collector{
+		ID:          uint(id),
+		Name:        req.Name,
+		Level:       domain.CollectionLevel(req.Level),
+		Region:      req.Region,
+		MaxAccounts: req.MaxAccounts,
+		Active:      req.Active,
+	}
+	if err := h.collectionUseCase.UpdateCollector(collector); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, collector)
+}
+
+// Worklist lists open accounts, the most days past due first, filtered by
+// bucket, region, level and collector. Collectors only see their own
+// accounts.
+func (h *CollectionHandler) Worklist(c *gin.Context) {
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	filter := domain.WorklistFilter{
+		Bucket: domain.DPDBucket(c.Query("bucket")),
+		Region: c.Query("region"),
+		Level:  domain.CollectionLevel(c.Query("level")),
+	}
+	if value := c.Query("collector_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			c.Error(domain.NewError(domain.ErrValidation, "invalid_collector_id", "invalid collector ID"))
+			return
+		}
+		collectorID := uint(id)
+		filter.CollectorID = &collectorID
+	}
+
+	accounts, err := h.collectionUseCase.Worklist(filter, actor(c), offset, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, accounts)
+}
+
+// GetAccount returns an account with its activity log, the latest first
+func (h *CollectionHandler) GetAccount(c *gin.Context) {
+	id, ok := accountID(c)
+	if !ok {
+		return
+	}
+
+	account, err := h.collectionUseCase.GetAccount(id, actor(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, account)
+}
+
+func (h *CollectionHandler) Assign(c *gin.Context) {
+	id, ok := accountID(c)
+	if !ok {
+		return
+	}
+
+	var req AssignAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	account, err := h.collectionUseCase.Assign(id, req.CollectorID, actor(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, account)
+}
+
+// LogActivity records a call, message, visit, note or promise to pay on an
+// account
+func (h *CollectionHandler) LogActivity(c *gin.Context) {
+	id, ok := accountID(c)
+	if !ok {
+		return
+	}
+
+	var req CollectionActivityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	activity := &domain.CollectionActivity{
+		Type:          domain.CollectionActivityType(req.Type),
+		Outcome:       req.Outcome,
+		Note:          req.Note,
+		PromiseAmount: req.PromiseAmount,
+	}
+	if req.PromiseDate != "" {
+		date, err := time.ParseInLocation("2006-01-02", req.PromiseDate, time.Local)
+		if err != nil {
+			c.Error(domain.NewError(domain.ErrValidation, "invalid_promise_date", "invalid date format"))
+			return
+		}
+		activity.PromiseDate = &date
+	}
+
+	account, err := h.collectionUseCase.LogActivity(id, activity, actor(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, account)
+}
+
+// accountID reads the collection account ID path parameter, reporting a
+// validation error when invalid
+func accountID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_collection_account_id", "invalid collection account ID"))
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -15,19 +15,27 @@ type CustomerHandler struct {
 	validate        *validator.Validate
 }
 
-func NewCustomerHandler(router *gin.Engine, customerUseCase domain.CustomerUseCase) {
+// NewCustomerHandler registers the customer routes, the public ones behind
+// rateLimit. Customers can only be deleted by back-office staff
+// authenticated by backOffice.
+func NewCustomerHandler(router *gin.Engine, customerUseCase domain.CustomerUseCase, rateLimit gin.HandlerFunc, backOffice ...gin.HandlerFunc) {
 	handler := &CustomerHandler{
 		customerUseCase: customerUseCase,
 		validate:        validator.New(),
 	}
 
-	customerRoutes := router.Group("/api/v1/customers")
+	customerRoutes := router.Group("/api/v1/customers", rateLimit)
 	{
 		customerRoutes.POST("", handler.Register)
 		customerRoutes.GET("/:id", handler.GetProfile)
 		customerRoutes.PUT("/:id", handler.UpdateProfile)
 		customerRoutes.GET("/:id/credit-limits", handler.GetCreditLimits)
 	}
+
+	backOfficeRoutes := router.Group("/api/v1/customers", backOffice...)
+	{
+		backOfficeRoutes.DELETE("/:id", handler.Delete)
+	}
 }
 
 type RegisterRequest struct {
@@ -39,24 +47,27 @@ type RegisterRequest struct {
 	Salary       float64 `json:"salary" validate:"required,gt=0"`
 	KTPPhoto     string  `json:"ktp_photo" validate:"required,url"`
 	SelfiePhoto  string  `json:"selfie_photo" validate:"required,url"`
+	Phone        string  `json:"phone" validate:"omitempty,max=20"`
+	Region       string  `json:"region" validate:"omitempty,max=50"`
+	DeviceID     string  `json:"device_id" validate:"omitempty,max=100"`
 }
 
 func (h *CustomerHandler) Register(c *gin.Context) {
 	var req RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
 		return
 	}
 
 	if err := h.validate.Struct(req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
 		return
 	}
 
 	// Parse date of birth
 	dob, err := time.Parse("2006-01-02", req.DateOfBirth)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date format"})
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_date_of_birth", "invalid date format"))
 		return
 	}
 
@@ -69,10 +80,13 @@ func (h *CustomerHandler) Register(c *gin.Context) {
 		Salary:       req.Salary,
 		KTPPhoto:     req.KTPPhoto,
 		SelfiePhoto:  req.SelfiePhoto,
+		Phone:        req.Phone,
+		Region:       req.Region,
+		DeviceID:     req.DeviceID,
 	}
 
 	if err := h.customerUseCase.Register(customer); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -82,16 +96,17 @@ func (h *CustomerHandler) Register(c *gin.Context) {
 func (h *CustomerHandler) GetProfile(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid customer ID"})
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_customer_id", "invalid customer ID"))
 		return
 	}
 
 	customer, err := h.customerUseCase.GetProfile(uint(id))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "customer not found"})
+		c.Error(err)
 		return
 	}
 
+	setETag(c, customer.Version)
 	c.JSON(http.StatusOK, customer)
 }
 
@@ -104,18 +119,24 @@ type UpdateProfileRequest struct {
 func (h *CustomerHandler) UpdateProfile(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid customer ID"})
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_customer_id", "invalid customer ID"))
+		return
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
 	var req UpdateProfileRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
 		return
 	}
 
 	if err := h.validate.Struct(req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
 		return
 	}
 
@@ -124,26 +145,43 @@ func (h *CustomerHandler) UpdateProfile(c *gin.Context) {
 		FullName:  req.FullName,
 		LegalName: req.LegalName,
 		Salary:    req.Salary,
+		Version:   version,
 	}
 
 	if err := h.customerUseCase.UpdateProfile(customer); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
+	setETag(c, customer.Version)
 	c.JSON(http.StatusOK, customer)
 }
 
+func (h *CustomerHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_customer_id", "invalid customer ID"))
+		return
+	}
+
+	if err := h.customerUseCase.Delete(uint(id)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
 func (h *CustomerHandler) GetCreditLimits(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid customer ID"})
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_customer_id", "invalid customer ID"))
 		return
 	}
 
 	limits, err := h.customerUseCase.GetCreditLimits(uint(id))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
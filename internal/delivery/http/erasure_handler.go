@@ -0,0 +1,42 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"xyz-multifinance/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ErasureHandler struct {
+	erasureUseCase domain.ErasureUseCase
+}
+
+// NewErasureHandler registers the erasure request routes behind the given
+// middlewares, which are expected to authenticate back-office staff
+func NewErasureHandler(router *gin.Engine, erasureUseCase domain.ErasureUseCase, middlewares ...gin.HandlerFunc) {
+	handler := &ErasureHandler{
+		erasureUseCase: erasureUseCase,
+	}
+
+	erasureRoutes := router.Group("/api/v1/customers", middlewares...)
+	{
+		erasureRoutes.POST("/:id/erasure-requests", handler.RequestErasure)
+	}
+}
+
+func (h *ErasureHandler) RequestErasure(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_customer_id", "invalid customer ID"))
+		return
+	}
+
+	request, err := h.erasureUseCase.RequestErasure(uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, request)
+}
@@ -0,0 +1,31 @@
+package http
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"xyz-multifinance/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setETag exposes the entity version as an ETag header
+func setETag(c *gin.Context, version int) {
+	c.Header("ETag", fmt.Sprintf(`"%d"`, version))
+}
+
+// ifMatchVersion returns the entity version sent in the If-Match header
+func ifMatchVersion(c *gin.Context) (int, error) {
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		return 0, domain.NewError(domain.ErrPreconditionRequired, "if_match_required", "If-Match header is required")
+	}
+
+	tag := strings.TrimPrefix(strings.TrimSpace(header), "W/")
+	version, err := strconv.Atoi(strings.Trim(tag, `"`))
+	if err != nil || version < 1 {
+		return 0, domain.NewError(domain.ErrValidation, "invalid_if_match", "If-Match must contain a single entity tag")
+	}
+
+	return version, nil
+}
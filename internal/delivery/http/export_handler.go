@@ -0,0 +1,158 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+	"xyz-multifinance/internal/domain"
+	"xyz-multifinance/internal/pkg/export"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ExportHandler struct {
+	exportUseCase domain.ExportUseCase
+}
+
+// NewExportHandler registers the data export routes behind the given
+// middlewares. Exports are written as CSV or XLSX by the format parameter.
+func NewExportHandler(router *gin.Engine, exportUseCase domain.ExportUseCase, middlewares ...gin.HandlerFunc) {
+	handler := &ExportHandler{
+		exportUseCase: exportUseCase,
+	}
+
+	routes := router.Group("/api/v1/exports", middlewares...)
+	{
+		routes.GET("/transactions", handler.Transactions)
+		routes.GET("/transactions/:id/installments", handler.Installments)
+		routes.GET("/customers/:id/statement", handler.Statement)
+	}
+}
+
+// Transactions exports the transactions matching the filters of the
+// transaction listing, optionally of one customer
+func (h *ExportHandler) Transactions(c *gin.Context) {
+	format, ok := exportFormat(c)
+	if !ok {
+		return
+	}
+	filter, ok := transactionFilter(c)
+	if !ok {
+		return
+	}
+	if value := c.Query("customer_id"); value != "" {
+		customerID, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			c.Error(domain.NewError(domain.ErrValidation, "invalid_customer_id", "invalid customer ID"))
+			return
+		}
+		filter.CustomerID = uint(customerID)
+	}
+
+	w := newExportResponse(c, format, "transactions-"+time.Now().Format("20060102"))
+	w.finish(h.exportUseCase.Transactions(filter, w))
+}
+
+// Installments exports the installment schedule of a transaction
+func (h *ExportHandler) Installments(c *gin.Context) {
+	format, ok := exportFormat(c)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_transaction_id", "invalid transaction ID"))
+		return
+	}
+
+	w := newExportResponse(c, format, fmt.Sprintf("installments-%d", id))
+	w.finish(h.exportUseCase.Installments(uint(id), w))
+}
+
+// Statement exports the account statement of a customer from one date to
+// another, both included and formatted YYYY-MM-DD
+func (h *ExportHandler) Statement(c *gin.Context) {
+	format, ok := exportFormat(c)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_customer_id", "invalid customer ID"))
+		return
+	}
+	from, err := time.ParseInLocation("2006-01-02", c.Query("from"), time.Local)
+	if err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_from", "from must be formatted YYYY-MM-DD"))
+		return
+	}
+	to, err := time.ParseInLocation("2006-01-02", c.Query("to"), time.Local)
+	if err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_to", "to must be formatted YYYY-MM-DD"))
+		return
+	}
+
+	filename := fmt.Sprintf("statement-%d-%s-%s", id, from.Format("20060102"), to.Format("20060102"))
+	w := newExportResponse(c, format, filename)
+	w.finish(h.exportUseCase.Statement(uint(id), from, to.AddDate(0, 0, 1), w))
+}
+
+// exportFormat parses the format query parameter, CSV by default, recording
+// an error when unsupported
+func exportFormat(c *gin.Context) (domain.ExportFormat, bool) {
+	format := domain.ExportFormat(c.DefaultQuery("format", string(domain.ExportCSV)))
+	if format != domain.ExportCSV && format != domain.ExportXLSX {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_format", "format must be csv or xlsx"))
+		return "", false
+	}
+	return format, true
+}
+
+// exportResponse streams the rows of an export to the response. Headers are
+// only sent with the first row, so errors raised before it are rendered as
+// problems.
+type exportResponse struct {
+	c        *gin.Context
+	format   domain.ExportFormat
+	filename string
+	w        export.Writer
+}
+
+func newExportResponse(c *gin.Context, format domain.ExportFormat, filename string) *exportResponse {
+	return &exportResponse{c: c, format: format, filename: filename}
+}
+
+// WriteRow implements domain.RowWriter
+func (r *exportResponse) WriteRow(cells ...interface{}) error {
+	if r.w == nil {
+		r.c.Header("Content-Type", export.ContentType(r.format))
+		r.c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, r.filename, r.format))
+		r.c.Status(http.StatusOK)
+
+		w, err := export.NewWriter(r.c.Writer, r.format)
+		if err != nil {
+			return err
+		}
+		r.w = w
+	}
+	return r.w.WriteRow(cells...)
+}
+
+// finish completes the export, or records the error that ended it. An export
+// that fails once rows were flushed is left truncated, as its status has been
+// sent.
+func (r *exportResponse) finish(err error) {
+	if err == nil && r.w != nil {
+		err = r.w.Close()
+	}
+	if err == nil {
+		return
+	}
+	if !r.c.Writer.Written() {
+		// Rows still buffered are dropped, the problem is rendered instead
+		r.c.Writer.Header().Del("Content-Type")
+		r.c.Writer.Header().Del("Content-Disposition")
+	}
+	r.c.Error(err)
+}
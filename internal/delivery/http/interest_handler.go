@@ -0,0 +1,77 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"xyz-multifinance/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+type InterestHandler struct {
+	accrualUseCase domain.AccrualUseCase
+}
+
+// NewInterestHandler registers the interest accrual routes behind the given
+// middlewares, which are expected to authenticate finance staff
+func NewInterestHandler(router *gin.Engine, accrualUseCase domain.AccrualUseCase, middlewares ...gin.HandlerFunc) {
+	handler := &InterestHandler{
+		accrualUseCase: accrualUseCase,
+	}
+
+	routes := router.Group("/api/v1", middlewares...)
+	{
+		routes.GET("/transactions/:id/interest", handler.Contract)
+		routes.GET("/transactions/:id/accruals", handler.ListAccruals)
+		routes.GET("/interest/portfolio", handler.Portfolio)
+	}
+}
+
+// Contract returns the principal and interest split of a contract's
+// installments, with the interest accrued and received
+func (h *InterestHandler) Contract(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_transaction_id", "invalid transaction ID"))
+		return
+	}
+
+	contract, err := h.accrualUseCase.Contract(uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, contract)
+}
+
+// ListAccruals lists the daily interest accruals of a contract
+func (h *InterestHandler) ListAccruals(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_transaction_id", "invalid transaction ID"))
+		return
+	}
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "31"))
+
+	accruals, err := h.accrualUseCase.ListAccruals(uint(id), offset, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, accruals)
+}
+
+// Portfolio totals the interest accrued and received of the approved
+// contracts
+func (h *InterestHandler) Portfolio(c *gin.Context) {
+	portfolio, err := h.accrualUseCase.Portfolio()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, portfolio)
+}
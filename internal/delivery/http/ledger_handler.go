@@ -0,0 +1,244 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+	"xyz-multifinance/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type LedgerHandler struct {
+	ledgerUseCase domain.LedgerUseCase
+	validate      *validator.Validate
+}
+
+// NewLedgerHandler registers the general ledger routes behind the given
+// middlewares, which are expected to authenticate finance staff
+func NewLedgerHandler(router *gin.Engine, ledgerUseCase domain.LedgerUseCase, middlewares ...gin.HandlerFunc) {
+	handler := &LedgerHandler{
+		ledgerUseCase: ledgerUseCase,
+		validate:      validator.New(),
+	}
+
+	routes := router.Group("/api/v1/ledger", middlewares...)
+	{
+		routes.GET("/accounts", handler.ListAccounts)
+		routes.POST("/accounts", handler.CreateAccount)
+		routes.PUT("/accounts/:code", handler.UpdateAccount)
+		routes.GET("/entries", handler.ListEntries)
+		routes.POST("/entries", handler.PostEntry)
+		routes.GET("/entries/:id", handler.GetEntry)
+		routes.GET("/trial-balance", handler.TrialBalance)
+	}
+}
+
+type AccountRequest struct {
+	Code          string `json:"code" validate:"required,numeric,max=10"`
+	Name          string `json:"name" validate:"required,max=100"`
+	Type          string `json:"type" validate:"required,oneof=asset liability equity income expense"`
+	NormalBalance string `json:"normal_balance" validate:"required,oneof=debit credit"`
+}
+
+type UpdateAccountRequest struct {
+	Name   string `json:"name" validate:"required,max=100"`
+	Active bool   `json:"active"`
+}
+
+type JournalLineRequest struct {
+	AccountCode string  `json:"account_code" validate:"required"`
+	Debit       float64 `json:"debit" validate:"gte=0"`
+	Credit      float64 `json:"credit" validate:"gte=0"`
+}
+
+type JournalEntryRequest struct {
+	Description   string               `json:"description" validate:"required,max=500"`
+	PostedAt      string               `json:"posted_at"` // YYYY-MM-DD, defaults to now
+	TransactionID *uint                `json:"transaction_id"`
+	Lines         []JournalLineRequest `json:"lines" validate:"required,min=2,dive"`
+}
+
+func (h *LedgerHandler) ListAccounts(c *gin.Context) {
+	accounts, err := h.ledgerUseCase.ListAccounts()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, accounts)
+}
+
+func (h *LedgerHandler) CreateAccount(c *gin.Context) {
+	var req AccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	account := &domain.Account{
+		Code:          req.Code,
+		Name:          req.Name,
+		Type:          domain.AccountType(req.Type),
+		NormalBalance: domain.NormalBalance(req.NormalBalance),
+	}
+	if err := h.ledgerUseCase.CreateAccount(account); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, account)
+}
+
+// UpdateAccount renames an account, or deactivates it so it takes no new
+// postings
+func (h *LedgerHandler) UpdateAccount(c *gin.Context) {
+	var req UpdateAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	account := &domain.Account{
+		Code:   c.Param("code"),
+		Name:   req.Name,
+		Active: req.Active,
+	}
+	if err := h.ledgerUseCase.UpdateAccount(account); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, account)
+}
+
+// ListEntries lists journal entries, optionally of a contract, of a type and
+// posted within from and to, formatted YYYY-MM-DD
+func (h *LedgerHandler) ListEntries(c *gin.Context) {
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	transactionID, ok := optionalTransactionID(c)
+	if !ok {
+		return
+	}
+	filter := domain.JournalFilter{Type: domain.JournalType(c.Query("type"))}
+	if transactionID != 0 {
+		filter.TransactionID = &transactionID
+	}
+	if value := c.Query("from"); value != "" {
+		from, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			c.Error(domain.NewError(domain.ErrValidation, "invalid_from", "from must be formatted YYYY-MM-DD"))
+			return
+		}
+		filter.From = &from
+	}
+	if value := c.Query("to"); value != "" {
+		to, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			c.Error(domain.NewError(domain.ErrValidation, "invalid_to", "to must be formatted YYYY-MM-DD"))
+			return
+		}
+		to = to.AddDate(0, 0, 1)
+		filter.To = &to
+	}
+
+	entries, err := h.ledgerUseCase.ListEntries(filter, offset, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// PostEntry posts a manual journal entry, which must balance
+func (h *LedgerHandler) PostEntry(c *gin.Context) {
+	var req JournalEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	entry := &domain.JournalEntry{
+		Description:   req.Description,
+		TransactionID: req.TransactionID,
+	}
+	if req.PostedAt != "" {
+		postedAt, err := time.ParseInLocation("2006-01-02", req.PostedAt, time.Local)
+		if err != nil {
+			c.Error(domain.NewError(domain.ErrValidation, "invalid_posted_at", "posted_at must be formatted YYYY-MM-DD"))
+			return
+		}
+		entry.PostedAt = postedAt
+	}
+	for _, line := range req.Lines {
+		entry.Lines = append(entry.Lines, domain.JournalLine{
+			AccountCode: line.AccountCode,
+			Debit:       line.Debit,
+			Credit:      line.Credit,
+		})
+	}
+
+	if err := h.ledgerUseCase.PostManual(entry, actor(c)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, entry)
+}
+
+func (h *LedgerHandler) GetEntry(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_journal_entry_id", "invalid journal entry ID"))
+		return
+	}
+
+	entry, err := h.ledgerUseCase.GetEntry(uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// TrialBalance returns the account balances at the end of as_of, formatted
+// YYYY-MM-DD, or now
+func (h *LedgerHandler) TrialBalance(c *gin.Context) {
+	asOf := time.Now()
+	if value := c.Query("as_of"); value != "" {
+		date, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			c.Error(domain.NewError(domain.ErrValidation, "invalid_as_of", "as_of must be formatted YYYY-MM-DD"))
+			return
+		}
+		asOf = date.AddDate(0, 0, 1)
+	}
+
+	report, err := h.ledgerUseCase.TrialBalance(asOf)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,192 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"xyz-multifinance/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type MerchantHandler struct {
+	merchantUseCase   domain.MerchantUseCase
+	settlementUseCase domain.SettlementUseCase
+	validate          *validator.Validate
+}
+
+// NewMerchantHandler registers the merchant routes behind the given
+// middlewares, which are expected to restrict access to finance staff
+func NewMerchantHandler(router *gin.Engine, merchantUseCase domain.MerchantUseCase, settlementUseCase domain.SettlementUseCase, middlewares ...gin.HandlerFunc) {
+	handler := &MerchantHandler{
+		merchantUseCase:   merchantUseCase,
+		settlementUseCase: settlementUseCase,
+		validate:          validator.New(),
+	}
+
+	merchantRoutes := router.Group("/api/v1/merchants", middlewares...)
+	{
+		merchantRoutes.POST("", handler.Create)
+		merchantRoutes.GET("", handler.List)
+		merchantRoutes.GET("/:id", handler.GetByID)
+		merchantRoutes.PUT("/:id", handler.Update)
+		merchantRoutes.DELETE("/:id", handler.Delete)
+		merchantRoutes.GET("/:id/settlements", handler.ListSettlements)
+	}
+}
+
+type MerchantRequest struct {
+	Code              string                `json:"code" validate:"required,alphanum,max=50"`
+	Name              string                `json:"name" validate:"required,max=100"`
+	Type              domain.MerchantType   `json:"type" validate:"required,oneof=store dealer"`
+	CommissionRate    float64               `json:"commission_rate" validate:"gte=0,lt=1"`
+	BankName          string                `json:"bank_name" validate:"required,max=100"`
+	BankAccountNumber string                `json:"bank_account_number" validate:"required,numeric,max=30"`
+	BankAccountName   string                `json:"bank_account_name" validate:"required,max=100"`
+	Status            domain.MerchantStatus `json:"status" validate:"omitempty,oneof=active inactive"`
+}
+
+func (h *MerchantHandler) Create(c *gin.Context) {
+	var req MerchantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	merchant := req.toMerchant()
+	if err := h.merchantUseCase.Create(merchant); err != nil {
+		c.Error(err)
+		return
+	}
+
+	setETag(c, merchant.Version)
+	c.JSON(http.StatusCreated, merchant)
+}
+
+func (h *MerchantHandler) List(c *gin.Context) {
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	merchants, err := h.merchantUseCase.List(offset, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, merchants)
+}
+
+func (h *MerchantHandler) GetByID(c *gin.Context) {
+	id, ok := merchantID(c)
+	if !ok {
+		return
+	}
+
+	merchant, err := h.merchantUseCase.GetByID(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	setETag(c, merchant.Version)
+	c.JSON(http.StatusOK, merchant)
+}
+
+func (h *MerchantHandler) Update(c *gin.Context) {
+	id, ok := merchantID(c)
+	if !ok {
+		return
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req MerchantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	merchant := req.toMerchant()
+	merchant.ID = id
+	merchant.Version = version
+	if merchant.Status == "" {
+		merchant.Status = domain.MerchantActive
+	}
+
+	if err := h.merchantUseCase.Update(merchant); err != nil {
+		c.Error(err)
+		return
+	}
+
+	setETag(c, merchant.Version)
+	c.JSON(http.StatusOK, merchant)
+}
+
+func (h *MerchantHandler) Delete(c *gin.Context) {
+	id, ok := merchantID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.merchantUseCase.Delete(id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *MerchantHandler) ListSettlements(c *gin.Context) {
+	id, ok := merchantID(c)
+	if !ok {
+		return
+	}
+
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	settlements, err := h.settlementUseCase.ListByMerchant(id, offset, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, settlements)
+}
+
+func (req MerchantRequest) toMerchant() *domain.Merchant {
+	return &domain.Merchant{
+		Code:              req.Code,
+		Name:              req.Name,
+		Type:              req.Type,
+		CommissionRate:    req.CommissionRate,
+		BankName:          req.BankName,
+		BankAccountNumber: req.BankAccountNumber,
+		BankAccountName:   req.BankAccountName,
+		Status:            req.Status,
+	}
+}
+
+// merchantID parses the merchant ID path parameter, recording an error when invalid
+func merchantID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_merchant_id", "invalid merchant ID"))
+		return 0, false
+	}
+	return uint(id), true
+}
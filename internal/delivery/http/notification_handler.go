@@ -0,0 +1,122 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"xyz-multifinance/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type NotificationHandler struct {
+	notificationUseCase domain.NotificationUseCase
+	validate            *validator.Validate
+}
+
+// NewNotificationHandler registers the notification routes behind the given
+// middlewares, which must authenticate the customer or back-office staff:
+// preferences decide where reminders and receipts are sent.
+func NewNotificationHandler(router *gin.Engine, notificationUseCase domain.NotificationUseCase, middlewares ...gin.HandlerFunc) {
+	handler := &NotificationHandler{
+		notificationUseCase: notificationUseCase,
+		validate:            validator.New(),
+	}
+
+	notificationRoutes := router.Group("/api/v1/customers", middlewares...)
+	{
+		notificationRoutes.GET("/:id/notification-preferences", handler.GetPreference)
+		notificationRoutes.PUT("/:id/notification-preferences", handler.UpdatePreference)
+		notificationRoutes.GET("/:id/notifications", handler.ListNotifications)
+	}
+}
+
+// UpdatePreferenceRequest replaces the preference; omitted channels are
+// opted out
+type UpdatePreferenceRequest struct {
+	Locale       domain.Locale `json:"locale" validate:"required,oneof=id en"`
+	Email        string        `json:"email" validate:"omitempty,email,max=255"`
+	Phone        string        `json:"phone" validate:"omitempty,e164"`
+	PushToken    string        `json:"push_token" validate:"omitempty,max=500"`
+	EmailEnabled bool          `json:"email_enabled"`
+	SMSEnabled   bool          `json:"sms_enabled"`
+	PushEnabled  bool          `json:"push_enabled"`
+}
+
+func (h *NotificationHandler) GetPreference(c *gin.Context) {
+	id, ok := customerID(c)
+	if !ok {
+		return
+	}
+
+	preference, err := h.notificationUseCase.GetPreference(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, preference)
+}
+
+func (h *NotificationHandler) UpdatePreference(c *gin.Context) {
+	id, ok := customerID(c)
+	if !ok {
+		return
+	}
+
+	var req UpdatePreferenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	preference, err := h.notificationUseCase.UpdatePreference(&domain.NotificationPreference{
+		CustomerID:   id,
+		Locale:       req.Locale,
+		Email:        req.Email,
+		Phone:        req.Phone,
+		PushToken:    req.PushToken,
+		EmailEnabled: req.EmailEnabled,
+		SMSEnabled:   req.SMSEnabled,
+		PushEnabled:  req.PushEnabled,
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, preference)
+}
+
+func (h *NotificationHandler) ListNotifications(c *gin.Context) {
+	id, ok := customerID(c)
+	if !ok {
+		return
+	}
+
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	notifications, err := h.notificationUseCase.ListNotifications(id, offset, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, notifications)
+}
+
+// customerID parses the customer ID path parameter, recording an error when invalid
+func customerID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_customer_id", "invalid customer ID"))
+		return 0, false
+	}
+	return uint(id), true
+}
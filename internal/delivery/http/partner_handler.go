@@ -0,0 +1,195 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"xyz-multifinance/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type PartnerHandler struct {
+	partnerUseCase domain.PartnerUseCase
+	validate       *validator.Validate
+}
+
+// NewPartnerHandler registers the partner registry routes behind the given
+// middlewares, which are expected to restrict access to administrators
+func NewPartnerHandler(router *gin.Engine, partnerUseCase domain.PartnerUseCase, middlewares ...gin.HandlerFunc) {
+	handler := &PartnerHandler{
+		partnerUseCase: partnerUseCase,
+		validate:       validator.New(),
+	}
+
+	partnerRoutes := router.Group("/api/v1/partners", middlewares...)
+	{
+		partnerRoutes.POST("", handler.Register)
+		partnerRoutes.GET("", handler.List)
+		partnerRoutes.GET("/:id", handler.GetByID)
+		partnerRoutes.PUT("/:id/status", handler.UpdateStatus)
+		partnerRoutes.GET("/:id/keys", handler.ListKeys)
+		partnerRoutes.POST("/:id/keys", handler.IssueKey)
+		partnerRoutes.POST("/:id/keys/rotate", handler.RotateKey)
+		partnerRoutes.DELETE("/:id/keys/:key_id", handler.RevokeKey)
+	}
+}
+
+type RegisterPartnerRequest struct {
+	Code   string                   `json:"code" validate:"required,alphanum,max=50"`
+	Name   string                   `json:"name" validate:"required,max=100"`
+	Source domain.TransactionSource `json:"source" validate:"required,oneof=e-commerce website dealer"`
+}
+
+func (h *PartnerHandler) Register(c *gin.Context) {
+	var req RegisterPartnerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	partner := &domain.Partner{
+		Code:   req.Code,
+		Name:   req.Name,
+		Source: req.Source,
+	}
+
+	if err := h.partnerUseCase.Register(partner); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, partner)
+}
+
+func (h *PartnerHandler) List(c *gin.Context) {
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	partners, err := h.partnerUseCase.List(offset, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, partners)
+}
+
+func (h *PartnerHandler) GetByID(c *gin.Context) {
+	id, ok := partnerID(c)
+	if !ok {
+		return
+	}
+
+	partner, err := h.partnerUseCase.GetByID(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, partner)
+}
+
+type UpdatePartnerStatusRequest struct {
+	Status domain.PartnerStatus `json:"status" validate:"required,oneof=active suspended"`
+}
+
+func (h *PartnerHandler) UpdateStatus(c *gin.Context) {
+	id, ok := partnerID(c)
+	if !ok {
+		return
+	}
+
+	var req UpdatePartnerStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	partner, err := h.partnerUseCase.UpdateStatus(id, req.Status)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, partner)
+}
+
+func (h *PartnerHandler) ListKeys(c *gin.Context) {
+	id, ok := partnerID(c)
+	if !ok {
+		return
+	}
+
+	keys, err := h.partnerUseCase.ListKeys(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+func (h *PartnerHandler) IssueKey(c *gin.Context) {
+	id, ok := partnerID(c)
+	if !ok {
+		return
+	}
+
+	key, err := h.partnerUseCase.IssueKey(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, key)
+}
+
+func (h *PartnerHandler) RotateKey(c *gin.Context) {
+	id, ok := partnerID(c)
+	if !ok {
+		return
+	}
+
+	key, err := h.partnerUseCase.RotateKey(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, key)
+}
+
+func (h *PartnerHandler) RevokeKey(c *gin.Context) {
+	id, ok := partnerID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.partnerUseCase.RevokeKey(id, c.Param("key_id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// partnerID parses the partner ID path parameter, recording an error when invalid
+func partnerID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_partner_id", "invalid partner ID"))
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,204 @@
+package http
+
+import (
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"xyz-multifinance/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// maxStatementBytes bounds uploaded bank statements
+const maxStatementBytes = 20 << 20
+
+type PaymentHandler struct {
+	paymentUseCase domain.PaymentUseCase
+	validate       *validator.Validate
+}
+
+// NewPaymentHandler registers the virtual account payment routes. Bank
+// callbacks are limited by rateLimit per client IP and verified by
+// callbackAuth; the remaining routes sit behind the given middlewares, which
+// are expected to restrict access to finance staff.
+func NewPaymentHandler(router *gin.Engine, paymentUseCase domain.PaymentUseCase, rateLimit, callbackAuth gin.HandlerFunc, middlewares ...gin.HandlerFunc) {
+	handler := &PaymentHandler{
+		paymentUseCase: paymentUseCase,
+		validate:       validator.New(),
+	}
+
+	router.POST("/api/v1/payments/callbacks/:bank", rateLimit, callbackAuth, handler.Callback)
+
+	paymentRoutes := router.Group("/api/v1/payments", middlewares...)
+	{
+		paymentRoutes.POST("/virtual-accounts", handler.CreateVirtualAccount)
+		paymentRoutes.GET("/virtual-accounts", handler.ListVirtualAccounts)
+		paymentRoutes.POST("/reconciliations", handler.Reconcile)
+		paymentRoutes.GET("/reconciliations", handler.ListReconciliations)
+		paymentRoutes.GET("/reconciliations/:id", handler.GetReconciliation)
+	}
+}
+
+type CreateVirtualAccountRequest struct {
+	TransactionID uint   `json:"transaction_id" validate:"required"`
+	BankCode      string `json:"bank_code" validate:"required,max=20"`
+}
+
+type VACallbackRequest struct {
+	VANumber  string  `json:"va_number" validate:"required,numeric,max=30"`
+	Reference string  `json:"reference" validate:"required,max=100"`
+	Amount    float64 `json:"amount" validate:"required,gt=0"`
+	PaidAt    string  `json:"paid_at" validate:"required"` // RFC 3339
+}
+
+func (h *PaymentHandler) CreateVirtualAccount(c *gin.Context) {
+	var req CreateVirtualAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	account, err := h.paymentUseCase.CreateVirtualAccount(req.TransactionID, req.BankCode)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, account)
+}
+
+func (h *PaymentHandler) ListVirtualAccounts(c *gin.Context) {
+	transactionID, err := strconv.ParseUint(c.Query("transaction_id"), 10, 32)
+	if err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_transaction_id", "invalid transaction ID"))
+		return
+	}
+
+	accounts, err := h.paymentUseCase.ListVirtualAccounts(uint(transactionID))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, accounts)
+}
+
+// Callback records a payment reported by a bank. Rejected payments are
+// acknowledged too, with the reason the bank should refund them.
+func (h *PaymentHandler) Callback(c *gin.Context) {
+	var req VACallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	paidAt, err := time.Parse(time.RFC3339, req.PaidAt)
+	if err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_paid_at", "paid_at must be an RFC 3339 timestamp"))
+		return
+	}
+
+	payment, err := h.paymentUseCase.HandleCallback(c.GetString("bank_code"), &domain.VACallback{
+		Number:    req.VANumber,
+		Reference: req.Reference,
+		Amount:    req.Amount,
+		PaidAt:    paidAt,
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, payment)
+}
+
+// Reconcile reconciles an uploaded statement, sent as multipart form fields
+// bank_code, statement_date (YYYY-MM-DD) and file. The format follows the
+// file extension: .csv, or .mt940 / .sta for MT940.
+func (h *PaymentHandler) Reconcile(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxStatementBytes)
+
+	bankCode := c.PostForm("bank_code")
+	if bankCode == "" {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", "bank_code is required"))
+		return
+	}
+	statementDate, err := time.Parse("2006-01-02", c.PostForm("statement_date"))
+	if err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_statement_date", "statement_date must be formatted as YYYY-MM-DD"))
+		return
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", "file is required"))
+		return
+	}
+	var format domain.StatementFormat
+	switch strings.ToLower(filepath.Ext(header.Filename)) {
+	case ".csv":
+		format = domain.StatementCSV
+	case ".mt940", ".sta":
+		format = domain.StatementMT940
+	default:
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_statement_format", "statement must be a .csv, .mt940 or .sta file"))
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	defer file.Close()
+
+	reconciliation, err := h.paymentUseCase.Reconcile(bankCode, statementDate, format, filepath.Base(header.Filename), file)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, reconciliation)
+}
+
+func (h *PaymentHandler) ListReconciliations(c *gin.Context) {
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	reconciliations, err := h.paymentUseCase.ListReconciliations(c.Query("bank_code"), offset, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, reconciliations)
+}
+
+func (h *PaymentHandler) GetReconciliation(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_reconciliation_id", "invalid reconciliation ID"))
+		return
+	}
+
+	reconciliation, err := h.paymentUseCase.GetReconciliation(uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, reconciliation)
+}
@@ -0,0 +1,204 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+	"xyz-multifinance/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type ProvisioningHandler struct {
+	provisioningUseCase domain.ProvisioningUseCase
+	writeOffUseCase     domain.WriteOffUseCase
+	validate            *validator.Validate
+}
+
+// NewProvisioningHandler registers the collectibility, provisioning and
+// write-off routes behind the given middlewares, which are expected to
+// authenticate finance staff
+func NewProvisioningHandler(router *gin.Engine, provisioningUseCase domain.ProvisioningUseCase, writeOffUseCase domain.WriteOffUseCase, middlewares ...gin.HandlerFunc) {
+	handler := &ProvisioningHandler{
+		provisioningUseCase: provisioningUseCase,
+		writeOffUseCase:     writeOffUseCase,
+		validate:            validator.New(),
+	}
+
+	routes := router.Group("/api/v1", middlewares...)
+	{
+		routes.GET("/transactions/:id/collectibility", handler.Classify)
+		routes.POST("/transactions/:id/write-off", handler.WriteOff)
+		routes.GET("/provisioning/portfolio", handler.Portfolio)
+		routes.GET("/provisioning/reports", handler.ListReports)
+		routes.GET("/provisioning/reports/:period", handler.GetReport)
+		routes.GET("/write-offs", handler.ListWriteOffs)
+		routes.GET("/write-offs/:id", handler.GetWriteOff)
+		routes.POST("/write-offs/:id/recoveries", handler.RecordRecovery)
+	}
+}
+
+type WriteOffRequest struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+type RecoveryRequest struct {
+	Amount     float64 `json:"amount" validate:"required,gt=0"`
+	Reference  string  `json:"reference" validate:"max=100"`
+	Note       string  `json:"note" validate:"max=500"`
+	ReceivedAt string  `json:"received_at"` // RFC 3339, defaults to now
+}
+
+// Classify returns the current collectibility grade and provision of a
+// contract
+func (h *ProvisioningHandler) Classify(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_transaction_id", "invalid transaction ID"))
+		return
+	}
+
+	classification, err := h.provisioningUseCase.Classify(uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, classification)
+}
+
+// WriteOff writes off a contract classified macet
+func (h *ProvisioningHandler) WriteOff(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_transaction_id", "invalid transaction ID"))
+		return
+	}
+
+	var req WriteOffRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	writeOff, err := h.writeOffUseCase.WriteOff(uint(id), req.Reason, actor(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, writeOff)
+}
+
+// Portfolio totals the current grades and provisions of the portfolio
+func (h *ProvisioningHandler) Portfolio(c *gin.Context) {
+	report, err := h.provisioningUseCase.Portfolio(time.Now())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+func (h *ProvisioningHandler) ListReports(c *gin.Context) {
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "12"))
+
+	reports, err := h.provisioningUseCase.ListReports(offset, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, reports)
+}
+
+// GetReport returns the provision report of a month, formatted YYYY-MM
+func (h *ProvisioningHandler) GetReport(c *gin.Context) {
+	report, err := h.provisioningUseCase.GetReport(c.Param("period"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+func (h *ProvisioningHandler) ListWriteOffs(c *gin.Context) {
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	writeOffs, err := h.writeOffUseCase.List(offset, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, writeOffs)
+}
+
+// GetWriteOff returns a write-off with its recoveries
+func (h *ProvisioningHandler) GetWriteOff(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_write_off_id", "invalid write-off ID"))
+		return
+	}
+
+	writeOff, err := h.writeOffUseCase.GetByID(uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, writeOff)
+}
+
+// RecordRecovery records an amount collected after a write-off
+func (h *ProvisioningHandler) RecordRecovery(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_write_off_id", "invalid write-off ID"))
+		return
+	}
+
+	var req RecoveryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	recovery := &domain.Recovery{
+		Amount:    req.Amount,
+		Reference: req.Reference,
+		Note:      req.Note,
+	}
+	if req.ReceivedAt != "" {
+		receivedAt, err := time.Parse(time.RFC3339, req.ReceivedAt)
+		if err != nil {
+			c.Error(domain.NewError(domain.ErrValidation, "invalid_received_at", "received_at must be an RFC 3339 timestamp"))
+			return
+		}
+		recovery.ReceivedAt = receivedAt
+	}
+
+	writeOff, err := h.writeOffUseCase.RecordRecovery(uint(id), recovery, actor(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, writeOff)
+}
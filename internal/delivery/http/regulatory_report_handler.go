@@ -0,0 +1,173 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"xyz-multifinance/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type RegulatoryReportHandler struct {
+	reportUseCase domain.RegulatoryReportUseCase
+	validate      *validator.Validate
+}
+
+// NewRegulatoryReportHandler registers the SLIK reporting routes behind the
+// given middlewares, which are expected to authenticate finance staff
+func NewRegulatoryReportHandler(router *gin.Engine, reportUseCase domain.RegulatoryReportUseCase, middlewares ...gin.HandlerFunc) {
+	handler := &RegulatoryReportHandler{
+		reportUseCase: reportUseCase,
+		validate:      validator.New(),
+	}
+
+	routes := router.Group("/api/v1/regulatory-reports", middlewares...)
+	{
+		routes.GET("/validate", handler.Validate)
+		routes.POST("", handler.Generate)
+		routes.GET("", handler.List)
+		routes.GET("/:id", handler.GetByID)
+		routes.GET("/:id/file", handler.Download)
+		routes.POST("/:id/submit", handler.Submit)
+	}
+}
+
+type GenerateReportRequest struct {
+	Period  string `json:"period" validate:"required"` // YYYY-MM
+	Segment string `json:"segment" validate:"required,oneof=D01 F01"`
+	Format  string `json:"format" validate:"required,oneof=fixed_width csv xml"`
+}
+
+type SubmitReportRequest struct {
+	Reference string `json:"reference" validate:"required,max=100"`
+	Checksum  string `json:"checksum" validate:"omitempty,len=64,hexadecimal"` // SHA-256 of the file uploaded
+}
+
+// Validate lists the mandatory field issues of a segment for a period
+func (h *RegulatoryReportHandler) Validate(c *gin.Context) {
+	issues, err := h.reportUseCase.Validate(c.Query("period"), domain.ReportSegment(c.Query("segment")))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"valid": len(issues) == 0, "issues": issues})
+}
+
+// Generate builds and records the file of a segment for a period
+func (h *RegulatoryReportHandler) Generate(c *gin.Context) {
+	var req GenerateReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	report, err := h.reportUseCase.Generate(req.Period, domain.ReportSegment(req.Segment), domain.ReportFormat(req.Format), actor(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, report)
+}
+
+func (h *RegulatoryReportHandler) List(c *gin.Context) {
+	filter := domain.RegulatoryReportFilter{
+		Period:  c.Query("period"),
+		Segment: domain.ReportSegment(c.Query("segment")),
+		Status:  domain.ReportStatus(c.Query("status")),
+	}
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	reports, err := h.reportUseCase.List(filter, offset, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, reports)
+}
+
+func (h *RegulatoryReportHandler) GetByID(c *gin.Context) {
+	id, ok := reportID(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.reportUseCase.GetByID(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// Download returns the file as generated, with its checksum
+func (h *RegulatoryReportHandler) Download(c *gin.Context) {
+	id, ok := reportID(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.reportUseCase.GetByID(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	contentType := "text/plain; charset=utf-8"
+	switch report.Format {
+	case domain.ReportCSV:
+		contentType = "text/csv"
+	case domain.ReportXML:
+		contentType = "application/xml"
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, report.FileName))
+	c.Header("X-Checksum-SHA256", report.Checksum)
+	c.Data(http.StatusOK, contentType, report.Content)
+}
+
+// Submit records the upload of a file to SLIK
+func (h *RegulatoryReportHandler) Submit(c *gin.Context) {
+	id, ok := reportID(c)
+	if !ok {
+		return
+	}
+
+	var req SubmitReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	report, err := h.reportUseCase.Submit(id, req.Reference, req.Checksum, actor(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+func reportID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_report_id", "invalid report ID"))
+		return 0, false
+	}
+	return uint(id), true
+}
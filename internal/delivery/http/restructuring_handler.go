@@ -0,0 +1,158 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"xyz-multifinance/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type RestructuringHandler struct {
+	restructuringUseCase domain.RestructuringUseCase
+	validate             *validator.Validate
+}
+
+// NewRestructuringHandler registers the contract restructuring routes behind
+// the given middlewares, which are expected to authenticate back office
+// staff. Approving and rejecting additionally require approverAuth.
+func NewRestructuringHandler(router *gin.Engine, restructuringUseCase domain.RestructuringUseCase, approverAuth gin.HandlerFunc, middlewares ...gin.HandlerFunc) {
+	handler := &RestructuringHandler{
+		restructuringUseCase: restructuringUseCase,
+		validate:             validator.New(),
+	}
+
+	routes := router.Group("/api/v1", middlewares...)
+	{
+		routes.POST("/transactions/:id/restructurings", handler.Request)
+		routes.GET("/transactions/:id/restructurings", handler.ListByTransaction)
+		routes.GET("/restructurings/:id", handler.GetByID)
+		routes.PUT("/restructurings/:id/approve", approverAuth, handler.Approve)
+		routes.PUT("/restructurings/:id/reject", approverAuth, handler.Reject)
+	}
+}
+
+type RestructuringRequest struct {
+	Type          domain.RestructuringType `json:"type" validate:"required,oneof=tenor_extension payment_holiday"`
+	Reason        string                   `json:"reason" validate:"required,max=500"`
+	InterestRate  float64                  `json:"interest_rate" validate:"gte=0"`
+	NewTenor      int                      `json:"new_tenor" validate:"gte=0"`
+	HolidayMonths int                      `json:"holiday_months" validate:"gte=0"`
+}
+
+type ReviewRestructuringRequest struct {
+	Note string `json:"note" validate:"max=500"`
+}
+
+// Request requests a restructuring of an approved contract, to be reviewed
+func (h *RestructuringHandler) Request(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_transaction_id", "invalid transaction ID"))
+		return
+	}
+
+	var req RestructuringRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	restructuring, err := h.restructuringUseCase.Request(&domain.RestructuringRequest{
+		TransactionID: uint(id),
+		Type:          req.Type,
+		Reason:        req.Reason,
+		InterestRate:  req.InterestRate,
+		NewTenor:      req.NewTenor,
+		HolidayMonths: req.HolidayMonths,
+		Actor:         actor(c),
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, restructuring)
+}
+
+func (h *RestructuringHandler) ListByTransaction(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_transaction_id", "invalid transaction ID"))
+		return
+	}
+
+	restructurings, err := h.restructuringUseCase.ListByTransaction(uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, restructurings)
+}
+
+func (h *RestructuringHandler) GetByID(c *gin.Context) {
+	id, ok := restructuringID(c)
+	if !ok {
+		return
+	}
+
+	restructuring, err := h.restructuringUseCase.GetByID(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, restructuring)
+}
+
+// Approve approves a pending restructuring, replacing the outstanding
+// installments with the new schedule
+func (h *RestructuringHandler) Approve(c *gin.Context) {
+	h.review(c, h.restructuringUseCase.Approve)
+}
+
+func (h *RestructuringHandler) Reject(c *gin.Context) {
+	h.review(c, h.restructuringUseCase.Reject)
+}
+
+func (h *RestructuringHandler) review(c *gin.Context, decide func(id uint, note string, actor domain.Actor) (*domain.Restructuring, error)) {
+	id, ok := restructuringID(c)
+	if !ok {
+		return
+	}
+
+	var req ReviewRestructuringRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	restructuring, err := decide(id, req.Note, actor(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, restructuring)
+}
+
+func restructuringID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_restructuring_id", "invalid restructuring ID"))
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,205 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"xyz-multifinance/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type ReversalHandler struct {
+	reversalUseCase domain.ReversalUseCase
+	validate        *validator.Validate
+}
+
+// NewReversalHandler registers the payment reversal and refund routes behind
+// the given middlewares, which are expected to authenticate the operator and
+// restrict access to operators
+func NewReversalHandler(router *gin.Engine, reversalUseCase domain.ReversalUseCase, middlewares ...gin.HandlerFunc) {
+	handler := &ReversalHandler{
+		reversalUseCase: reversalUseCase,
+		validate:        validator.New(),
+	}
+
+	routes := router.Group("/api/v1", middlewares...)
+	{
+		routes.POST("/transactions/installments/:id/reversal", handler.ReverseInstallment)
+		routes.GET("/reversals", handler.ListReversals)
+		routes.POST("/refunds", handler.CreateRefund)
+		routes.GET("/refunds", handler.ListRefunds)
+		routes.PUT("/refunds/:id/complete", handler.CompleteRefund)
+	}
+}
+
+type ReverseInstallmentRequest struct {
+	Reason       domain.ReversalReason `json:"reason" validate:"required,oneof=bounced mistaken"`
+	Note         string                `json:"note" validate:"max=500"`
+	RefundAmount float64               `json:"refund_amount" validate:"gte=0"`
+}
+
+type CreateRefundRequest struct {
+	TransactionID uint    `json:"transaction_id" validate:"required_without=PaymentID"`
+	PaymentID     *uint   `json:"payment_id"`
+	Amount        float64 `json:"amount" validate:"required,gt=0"`
+	Reason        string  `json:"reason" validate:"required,max=100"`
+}
+
+type CompleteRefundRequest struct {
+	Reference string `json:"reference" validate:"required,max=100"`
+}
+
+// ReverseInstallment reverses the payment of an installment. The If-Match
+// header must carry the installment version the operator reviewed.
+func (h *ReversalHandler) ReverseInstallment(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_installment_id", "invalid installment ID"))
+		return
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req ReverseInstallmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	reversal, err := h.reversalUseCase.ReverseInstallment(&domain.ReverseInstallmentRequest{
+		InstallmentID: uint(id),
+		Version:       version,
+		Reason:        req.Reason,
+		Note:          req.Note,
+		RefundAmount:  req.RefundAmount,
+		Actor:         actor(c),
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	setETag(c, version+1)
+	c.JSON(http.StatusCreated, reversal)
+}
+
+func (h *ReversalHandler) ListReversals(c *gin.Context) {
+	transactionID, ok := optionalTransactionID(c)
+	if !ok {
+		return
+	}
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	reversals, err := h.reversalUseCase.ListReversals(transactionID, offset, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, reversals)
+}
+
+func (h *ReversalHandler) CreateRefund(c *gin.Context) {
+	var req CreateRefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	refund, err := h.reversalUseCase.CreateRefund(&domain.Refund{
+		TransactionID: req.TransactionID,
+		PaymentID:     req.PaymentID,
+		Amount:        req.Amount,
+		Reason:        req.Reason,
+	}, actor(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, refund)
+}
+
+func (h *ReversalHandler) ListRefunds(c *gin.Context) {
+	transactionID, ok := optionalTransactionID(c)
+	if !ok {
+		return
+	}
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	refunds, err := h.reversalUseCase.ListRefunds(transactionID, offset, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, refunds)
+}
+
+// CompleteRefund records the bank transfer that returned a refund
+func (h *ReversalHandler) CompleteRefund(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_refund_id", "invalid refund ID"))
+		return
+	}
+
+	var req CompleteRefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	refund, err := h.reversalUseCase.CompleteRefund(uint(id), req.Reference, actor(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, refund)
+}
+
+// actor returns the authenticated user, as set by the auth middleware
+func actor(c *gin.Context) domain.Actor {
+	return domain.Actor{
+		ID:   c.GetUint("user_id"),
+		Role: c.GetString("role"),
+	}
+}
+
+// optionalTransactionID reads the transaction_id query parameter, zero when
+// absent
+func optionalTransactionID(c *gin.Context) (uint, bool) {
+	value := c.Query("transaction_id")
+	if value == "" {
+		return 0, true
+	}
+	id, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_transaction_id", "invalid transaction ID"))
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,61 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"xyz-multifinance/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ScoringHandler struct {
+	scoringUseCase domain.ScoringUseCase
+}
+
+// NewScoringHandler registers the credit scoring routes behind the given
+// middlewares, which are expected to authenticate back office staff
+func NewScoringHandler(router *gin.Engine, scoringUseCase domain.ScoringUseCase, middlewares ...gin.HandlerFunc) {
+	handler := &ScoringHandler{
+		scoringUseCase: scoringUseCase,
+	}
+
+	routes := router.Group("/api/v1/customers", middlewares...)
+	{
+		routes.POST("/:id/scores", handler.Score)
+		routes.GET("/:id/scores", handler.ListHistory)
+	}
+}
+
+// Score calculates the current score of a customer, with the points of
+// every characteristic and the reasons lowering it
+func (h *ScoringHandler) Score(c *gin.Context) {
+	id, ok := customerID(c)
+	if !ok {
+		return
+	}
+
+	score, err := h.scoringUseCase.Score(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, score)
+}
+
+func (h *ScoringHandler) ListHistory(c *gin.Context) {
+	id, ok := customerID(c)
+	if !ok {
+		return
+	}
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	scores, err := h.scoringUseCase.ListHistory(id, offset, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, scores)
+}
@@ -0,0 +1,133 @@
+package http
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"xyz-multifinance/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type SettlementHandler struct {
+	settlementUseCase domain.SettlementUseCase
+	validate          *validator.Validate
+}
+
+// NewSettlementHandler registers the settlement routes behind the given
+// middlewares, which are expected to restrict access to finance staff
+func NewSettlementHandler(router *gin.Engine, settlementUseCase domain.SettlementUseCase, middlewares ...gin.HandlerFunc) {
+	handler := &SettlementHandler{
+		settlementUseCase: settlementUseCase,
+		validate:          validator.New(),
+	}
+
+	settlementRoutes := router.Group("/api/v1/settlements", middlewares...)
+	{
+		settlementRoutes.GET("/:id", handler.GetByID)
+		settlementRoutes.GET("/:id/statement", handler.GetStatement)
+		settlementRoutes.PUT("/:id/paid", handler.MarkPaid)
+	}
+}
+
+func (h *SettlementHandler) GetByID(c *gin.Context) {
+	id, ok := settlementID(c)
+	if !ok {
+		return
+	}
+
+	settlement, err := h.settlementUseCase.GetByID(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, settlement)
+}
+
+// GetStatement renders the settlement as a CSV statement for the merchant
+func (h *SettlementHandler) GetStatement(c *gin.Context) {
+	id, ok := settlementID(c)
+	if !ok {
+		return
+	}
+
+	settlement, err := h.settlementUseCase.GetByID(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	filename := fmt.Sprintf("settlement-%d-%s.csv", settlement.ID, settlement.SettlementDate.Format("20060102"))
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	if settlement.Merchant != nil {
+		w.Write([]string{"Merchant", settlement.Merchant.Code, settlement.Merchant.Name})
+		w.Write([]string{"Bank Account", settlement.Merchant.BankName, settlement.Merchant.BankAccountNumber, settlement.Merchant.BankAccountName})
+	}
+	w.Write([]string{"Settlement Date", settlement.SettlementDate.Format("2006-01-02")})
+	w.Write([]string{"Status", string(settlement.Status), settlement.PaymentReference})
+	w.Write(nil)
+	w.Write([]string{"Contract Number", "Gross Amount", "Commission Rate", "Commission Amount", "Net Amount"})
+	for _, item := range settlement.Items {
+		w.Write([]string{
+			item.ContractNumber,
+			formatAmount(item.GrossAmount),
+			strconv.FormatFloat(item.CommissionRate, 'f', -1, 64),
+			formatAmount(item.CommissionAmount),
+			formatAmount(item.NetAmount),
+		})
+	}
+	w.Write([]string{"Total", formatAmount(settlement.GrossAmount), "", formatAmount(settlement.CommissionAmount), formatAmount(settlement.NetAmount)})
+	w.Flush()
+}
+
+type MarkSettlementPaidRequest struct {
+	PaymentReference string `json:"payment_reference" validate:"required,max=100"`
+}
+
+func (h *SettlementHandler) MarkPaid(c *gin.Context) {
+	id, ok := settlementID(c)
+	if !ok {
+		return
+	}
+
+	var req MarkSettlementPaidRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	settlement, err := h.settlementUseCase.MarkPaid(id, req.PaymentReference)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, settlement)
+}
+
+// settlementID parses the settlement ID path parameter, recording an error when invalid
+func settlementID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_settlement_id", "invalid settlement ID"))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// formatAmount formats a currency amount with two decimals
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
@@ -3,6 +3,7 @@ package http
 import (
 	"net/http"
 	"strconv"
+	"time"
 	"xyz-multifinance/internal/domain"
 
 	"github.com/gin-gonic/gin"
@@ -14,60 +15,82 @@ type TransactionHandler struct {
 	validate           *validator.Validate
 }
 
-func NewTransactionHandler(router *gin.Engine, transactionUseCase domain.TransactionUseCase) {
+// NewTransactionHandler registers the transaction routes behind rateLimit.
+// Transactions can only be created by partners authenticated by
+// partnerAuth, which runs first so partners are limited by their identity.
+// Statuses can only be changed by back-office staff authenticated by
+// backOffice.
+func NewTransactionHandler(router *gin.Engine, transactionUseCase domain.TransactionUseCase, rateLimit, partnerAuth gin.HandlerFunc, backOffice ...gin.HandlerFunc) {
 	handler := &TransactionHandler{
 		transactionUseCase: transactionUseCase,
 		validate:           validator.New(),
 	}
 
-	transactionRoutes := router.Group("/api/v1/transactions")
+	router.POST("/api/v1/transactions", partnerAuth, rateLimit, handler.Create)
+
+	transactionRoutes := router.Group("/api/v1/transactions", rateLimit)
 	{
-		transactionRoutes.POST("", handler.Create)
 		transactionRoutes.GET("/:id", handler.GetByID)
 		transactionRoutes.GET("/contract/:number", handler.GetByContractNumber)
-		transactionRoutes.PUT("/:id/status", handler.UpdateStatus)
 		transactionRoutes.GET("/customer/:customer_id", handler.GetCustomerTransactions)
 		transactionRoutes.GET("/:id/installments", handler.GetInstallments)
 		transactionRoutes.POST("/installments/:id/pay", handler.PayInstallment)
 	}
+
+	backOfficeRoutes := router.Group("/api/v1/transactions", backOffice...)
+	{
+		backOfficeRoutes.PUT("/:id/status", handler.UpdateStatus)
+	}
 }
 
 type CreateTransactionRequest struct {
-	CustomerID        uint                     `json:"customer_id" validate:"required"`
-	Source            domain.TransactionSource `json:"source" validate:"required,oneof=e-commerce website dealer"`
-	AssetName         string                   `json:"asset_name" validate:"required"`
-	OTRAmount         float64                  `json:"otr_amount" validate:"required,gt=0"`
-	AdminFee          float64                  `json:"admin_fee" validate:"required,gte=0"`
-	InstallmentAmount float64                  `json:"installment_amount" validate:"required,gt=0"`
-	InterestAmount    float64                  `json:"interest_amount" validate:"required,gte=0"`
-	Tenor             int                      `json:"tenor" validate:"required,oneof=1 2 3 4"`
+	CustomerID        uint    `json:"customer_id" validate:"required"`
+	MerchantID        *uint   `json:"merchant_id" validate:"omitempty,gt=0"`
+	AssetName         string  `json:"asset_name" validate:"required"`
+	OTRAmount         float64 `json:"otr_amount" validate:"required,gt=0"`
+	AdminFee          float64 `json:"admin_fee" validate:"required,gte=0"`
+	InstallmentAmount float64 `json:"installment_amount" validate:"required,gt=0"`
+	InterestAmount    float64 `json:"interest_amount" validate:"required,gte=0"`
+	Tenor             int     `json:"tenor" validate:"required,oneof=1 2 3 4"`
+	DeviceID          string  `json:"device_id" validate:"omitempty,max=100"` // Device the customer applied from, screened for fraud
 }
 
 func (h *TransactionHandler) Create(c *gin.Context) {
+	// The source is derived from the verified partner, never from the body
+	value, _ := c.Get("partner")
+	partner, ok := value.(*domain.Partner)
+	if !ok {
+		c.Error(domain.NewError(domain.ErrUnauthorized, "partner_required", "partner credentials are required"))
+		return
+	}
+
 	var req CreateTransactionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
 		return
 	}
 
 	if err := h.validate.Struct(req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
 		return
 	}
 
 	tx := &domain.Transaction{
 		CustomerID:        req.CustomerID,
-		Source:            req.Source,
+		Source:            partner.Source,
+		PartnerID:         &partner.ID,
+		MerchantID:        req.MerchantID,
 		AssetName:         req.AssetName,
 		OTRAmount:         req.OTRAmount,
 		AdminFee:          req.AdminFee,
 		InstallmentAmount: req.InstallmentAmount,
 		InterestAmount:    req.InterestAmount,
 		Tenor:             req.Tenor,
+		DeviceID:          req.DeviceID,
 	}
 
 	if err := h.transactionUseCase.Create(tx); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -77,16 +100,17 @@ func (h *TransactionHandler) Create(c *gin.Context) {
 func (h *TransactionHandler) GetByID(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid transaction ID"})
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_transaction_id", "invalid transaction ID"))
 		return
 	}
 
 	tx, err := h.transactionUseCase.GetByID(uint(id))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "transaction not found"})
+		c.Error(err)
 		return
 	}
 
+	setETag(c, tx.Version)
 	c.JSON(http.StatusOK, tx)
 }
 
@@ -94,56 +118,74 @@ func (h *TransactionHandler) GetByContractNumber(c *gin.Context) {
 	number := c.Param("number")
 	tx, err := h.transactionUseCase.GetByContractNumber(number)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "transaction not found"})
+		c.Error(err)
 		return
 	}
 
+	setETag(c, tx.Version)
 	c.JSON(http.StatusOK, tx)
 }
 
+// UpdateStatusRequest only cancels contracts. Approvals and rejections are
+// decided by underwriting.
 type UpdateStatusRequest struct {
-	Status domain.TransactionStatus `json:"status" validate:"required,oneof=pending approved rejected cancelled"`
+	Status domain.TransactionStatus `json:"status" validate:"required,oneof=cancelled"`
 }
 
 func (h *TransactionHandler) UpdateStatus(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid transaction ID"})
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_transaction_id", "invalid transaction ID"))
+		return
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
 	var req UpdateStatusRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
 		return
 	}
 
 	if err := h.validate.Struct(req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
 		return
 	}
 
-	if err := h.transactionUseCase.UpdateStatus(uint(id), req.Status); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	tx, err := h.transactionUseCase.UpdateStatus(uint(id), req.Status, version)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
+	setETag(c, tx.Version)
 	c.JSON(http.StatusOK, gin.H{"message": "status updated successfully"})
 }
 
+// GetCustomerTransactions lists the transactions of a customer, narrowed by
+// the filters of transactionFilter
 func (h *TransactionHandler) GetCustomerTransactions(c *gin.Context) {
 	customerID, err := strconv.ParseUint(c.Param("customer_id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid customer ID"})
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_customer_id", "invalid customer ID"))
 		return
 	}
 
+	filter, ok := transactionFilter(c)
+	if !ok {
+		return
+	}
+	filter.CustomerID = uint(customerID)
 	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
 
-	transactions, err := h.transactionUseCase.GetCustomerTransactions(uint(customerID), offset, limit)
+	transactions, err := h.transactionUseCase.GetCustomerTransactions(filter, offset, limit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -153,13 +195,13 @@ func (h *TransactionHandler) GetCustomerTransactions(c *gin.Context) {
 func (h *TransactionHandler) GetInstallments(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid transaction ID"})
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_transaction_id", "invalid transaction ID"))
 		return
 	}
 
 	installments, err := h.transactionUseCase.GetInstallments(uint(id))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -169,14 +211,41 @@ func (h *TransactionHandler) GetInstallments(c *gin.Context) {
 func (h *TransactionHandler) PayInstallment(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid installment ID"})
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_installment_id", "invalid installment ID"))
 		return
 	}
 
 	if err := h.transactionUseCase.PayInstallment(uint(id)); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "installment paid successfully"})
 }
+
+// transactionFilter reads the status, source and the from and to creation
+// dates, formatted YYYY-MM-DD, narrowing a listing or export of transactions
+func transactionFilter(c *gin.Context) (domain.TransactionFilter, bool) {
+	filter := domain.TransactionFilter{
+		Status: domain.TransactionStatus(c.Query("status")),
+		Source: domain.TransactionSource(c.Query("source")),
+	}
+	if value := c.Query("from"); value != "" {
+		from, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			c.Error(domain.NewError(domain.ErrValidation, "invalid_from", "from must be formatted YYYY-MM-DD"))
+			return filter, false
+		}
+		filter.CreatedFrom = &from
+	}
+	if value := c.Query("to"); value != "" {
+		to, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			c.Error(domain.NewError(domain.ErrValidation, "invalid_to", "to must be formatted YYYY-MM-DD"))
+			return filter, false
+		}
+		to = to.AddDate(0, 0, 1)
+		filter.CreatedTo = &to
+	}
+	return filter, true
+}
@@ -0,0 +1,131 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"xyz-multifinance/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type UnderwritingHandler struct {
+	underwritingUseCase domain.UnderwritingUseCase
+	validate            *validator.Validate
+}
+
+// NewUnderwritingHandler registers the underwriting work queue routes behind
+// the given middlewares, which are expected to authenticate back office
+// staff
+func NewUnderwritingHandler(router *gin.Engine, underwritingUseCase domain.UnderwritingUseCase, middlewares ...gin.HandlerFunc) {
+	handler := &UnderwritingHandler{
+		underwritingUseCase: underwritingUseCase,
+		validate:            validator.New(),
+	}
+
+	routes := router.Group("/api/v1", middlewares...)
+	{
+		routes.GET("/transactions/:id/underwriting", handler.GetByTransaction)
+		routes.GET("/underwriting/queue", handler.ListQueue)
+		routes.PUT("/underwriting/:id/claim", handler.Claim)
+		routes.PUT("/underwriting/:id/approve", handler.Approve)
+		routes.PUT("/underwriting/:id/reject", handler.Reject)
+	}
+}
+
+type ReviewApplicationRequest struct {
+	Note string `json:"note" validate:"max=500"`
+}
+
+// GetByTransaction returns the underwriting decision of a contract, with the
+// outcome of every rule
+func (h *UnderwritingHandler) GetByTransaction(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_transaction_id", "invalid transaction ID"))
+		return
+	}
+
+	decision, err := h.underwritingUseCase.GetByTransaction(uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, decision)
+}
+
+// ListQueue lists the referred applications awaiting review, the earliest
+// due first
+func (h *UnderwritingHandler) ListQueue(c *gin.Context) {
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	decisions, err := h.underwritingUseCase.ListQueue(offset, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, decisions)
+}
+
+// Claim assigns a referred application to the calling operator
+func (h *UnderwritingHandler) Claim(c *gin.Context) {
+	id, ok := underwritingID(c)
+	if !ok {
+		return
+	}
+
+	decision, err := h.underwritingUseCase.Claim(id, actor(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, decision)
+}
+
+// Approve approves a referred application and its contract
+func (h *UnderwritingHandler) Approve(c *gin.Context) {
+	h.review(c, h.underwritingUseCase.Approve)
+}
+
+func (h *UnderwritingHandler) Reject(c *gin.Context) {
+	h.review(c, h.underwritingUseCase.Reject)
+}
+
+func (h *UnderwritingHandler) review(c *gin.Context, decide func(id uint, note string, actor domain.Actor) (*domain.UnderwritingDecision, error)) {
+	id, ok := underwritingID(c)
+	if !ok {
+		return
+	}
+
+	var req ReviewApplicationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	decision, err := decide(id, req.Note, actor(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, decision)
+}
+
+func underwritingID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_underwriting_id", "invalid underwriting ID"))
+		return 0, false
+	}
+	return uint(id), true
+}
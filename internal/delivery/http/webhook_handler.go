@@ -0,0 +1,216 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"xyz-multifinance/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type WebhookHandler struct {
+	webhookUseCase domain.WebhookUseCase
+	validate       *validator.Validate
+}
+
+// NewWebhookHandler registers the webhook subscription routes behind the
+// given middlewares, which are expected to restrict access to administrators
+func NewWebhookHandler(router *gin.Engine, webhookUseCase domain.WebhookUseCase, middlewares ...gin.HandlerFunc) {
+	handler := &WebhookHandler{
+		webhookUseCase: webhookUseCase,
+		validate:       validator.New(),
+	}
+
+	webhookRoutes := router.Group("/api/v1/webhooks", middlewares...)
+	{
+		webhookRoutes.POST("/subscriptions", handler.CreateSubscription)
+		webhookRoutes.GET("/subscriptions", handler.ListSubscriptions)
+		webhookRoutes.GET("/subscriptions/:id", handler.GetSubscription)
+		webhookRoutes.PUT("/subscriptions/:id", handler.UpdateSubscription)
+		webhookRoutes.DELETE("/subscriptions/:id", handler.DeleteSubscription)
+		webhookRoutes.POST("/subscriptions/:id/secret", handler.RotateSecret)
+		webhookRoutes.GET("/subscriptions/:id/deliveries", handler.ListDeliveries)
+		webhookRoutes.POST("/deliveries/:id/retry", handler.RetryDelivery)
+	}
+}
+
+type CreateSubscriptionRequest struct {
+	PartnerID  uint               `json:"partner_id" validate:"required"`
+	URL        string             `json:"url" validate:"required,url,max=500"`
+	EventTypes []domain.EventType `json:"event_types" validate:"required,min=1,dive,required"`
+}
+
+type UpdateSubscriptionRequest struct {
+	URL        string                    `json:"url" validate:"required,url,max=500"`
+	EventTypes []domain.EventType        `json:"event_types" validate:"required,min=1,dive,required"`
+	Status     domain.SubscriptionStatus `json:"status" validate:"required,oneof=active disabled"`
+}
+
+func (h *WebhookHandler) CreateSubscription(c *gin.Context) {
+	var req CreateSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	subscription, err := h.webhookUseCase.CreateSubscription(&domain.WebhookSubscription{
+		PartnerID:  req.PartnerID,
+		URL:        req.URL,
+		EventTypes: req.EventTypes,
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, subscription)
+}
+
+func (h *WebhookHandler) ListSubscriptions(c *gin.Context) {
+	partnerID, err := strconv.ParseUint(c.DefaultQuery("partner_id", "0"), 10, 32)
+	if err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_partner_id", "invalid partner ID"))
+		return
+	}
+
+	subscriptions, err := h.webhookUseCase.ListSubscriptions(uint(partnerID))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, subscriptions)
+}
+
+func (h *WebhookHandler) GetSubscription(c *gin.Context) {
+	id, ok := subscriptionID(c)
+	if !ok {
+		return
+	}
+
+	subscription, err := h.webhookUseCase.GetSubscription(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription)
+}
+
+func (h *WebhookHandler) UpdateSubscription(c *gin.Context) {
+	id, ok := subscriptionID(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_request", err.Error()))
+		return
+	}
+
+	subscription, err := h.webhookUseCase.UpdateSubscription(&domain.WebhookSubscription{
+		ID:         id,
+		URL:        req.URL,
+		EventTypes: req.EventTypes,
+		Status:     req.Status,
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription)
+}
+
+func (h *WebhookHandler) DeleteSubscription(c *gin.Context) {
+	id, ok := subscriptionID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.webhookUseCase.DeleteSubscription(id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *WebhookHandler) RotateSecret(c *gin.Context) {
+	id, ok := subscriptionID(c)
+	if !ok {
+		return
+	}
+
+	subscription, err := h.webhookUseCase.RotateSecret(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, subscription)
+}
+
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	id, ok := subscriptionID(c)
+	if !ok {
+		return
+	}
+
+	status := domain.DeliveryStatus(c.Query("status"))
+	switch status {
+	case "", domain.DeliveryPending, domain.DeliveryDelivered, domain.DeliveryDead:
+	default:
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_status", "status must be pending, delivered or dead"))
+		return
+	}
+
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	deliveries, err := h.webhookUseCase.ListDeliveries(id, status, offset, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+func (h *WebhookHandler) RetryDelivery(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_delivery_id", "invalid delivery ID"))
+		return
+	}
+
+	delivery, err := h.webhookUseCase.RetryDelivery(uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, delivery)
+}
+
+// subscriptionID parses the subscription ID path parameter, recording an error when invalid
+func subscriptionID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(domain.NewError(domain.ErrValidation, "invalid_subscription_id", "invalid subscription ID"))
+		return 0, false
+	}
+	return uint(id), true
+}
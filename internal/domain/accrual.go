@@ -0,0 +1,147 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+// InterestAccrual is the interest a contract earned on a day, accrued on
+// its installments
+type InterestAccrual struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	TransactionID uint      `json:"transaction_id" gorm:"not null"`
+	AccrualDate   time.Time `json:"accrual_date" gorm:"type:date;not null"` // Interest is accrued up to the start of this day
+	Amount        float64   `json:"amount" gorm:"not null"`
+	CreatedAt     time.Time `json:"created_at"`
+
+	// Accrued is the interest each installment has accrued with this
+	// accrual, replacing what it had accrued before
+	Accrued []InstallmentAccrual `json:"-" gorm:"-"`
+	// Entry is posted to the general ledger together with the accrual
+	Entry *JournalEntry `json:"-" gorm:"-"`
+}
+
+// InstallmentAccrual moves the interest accrued on an installment from
+// Previous to Accrued
+type InstallmentAccrual struct {
+	InstallmentID uint
+	Previous      float64
+	Accrued       float64
+}
+
+// ContractInterest compares the interest a contract accrued with the
+// interest paid with its installments
+type ContractInterest struct {
+	TransactionID  uint           `json:"transaction_id"`
+	ContractNumber string         `json:"contract_number"`
+	Collectibility Collectibility `json:"collectibility"`
+	Accruing       bool           `json:"accruing"` // False while non-performing or no longer approved
+	Principal      float64        `json:"principal"`
+	Interest       float64        `json:"interest"`
+	Accrued        float64        `json:"accrued"`
+	Received       float64        `json:"received"` // Interest of the paid installments
+	Installments   []Installment  `json:"installments"`
+}
+
+// PortfolioInterest totals the interest of the approved contracts
+type PortfolioInterest struct {
+	Contracts int     `json:"contracts"`
+	Interest  float64 `json:"interest"`
+	Accrued   float64 `json:"accrued"`
+	Received  float64 `json:"received"`
+	Unaccrued float64 `json:"unaccrued"`
+}
+
+// SplitInstallments splits the installments of a schedule, in due date
+// order, into principal and interest by the effective interest method: the
+// monthly rate at which the installments are worth principal is charged on
+// the balance still owed each month. The last installment absorbs rounding.
+func SplitInstallments(principal float64, installments []Installment) {
+	total := 0.0
+	for _, installment := range installments {
+		total += installment.Amount
+	}
+	if len(installments) == 0 || total <= principal || principal <= 0 {
+		for i := range installments {
+			installments[i].Principal = installments[i].Amount
+			installments[i].Interest = 0
+		}
+		return
+	}
+
+	rate := effectiveRate(principal, installments)
+	balance := principal
+	for i := range installments {
+		interest := math.Round(balance*rate*100) / 100
+		if i == len(installments)-1 {
+			interest = math.Round((installments[i].Amount-balance)*100) / 100
+		}
+		installments[i].Interest = interest
+		installments[i].Principal = math.Round((installments[i].Amount-interest)*100) / 100
+		balance -= installments[i].Principal
+	}
+}
+
+// effectiveRate returns the monthly rate discounting the installments to
+// principal, found by bisection
+func effectiveRate(principal float64, installments []Installment) float64 {
+	presentValue := func(rate float64) float64 {
+		value := 0.0
+		for i, installment := range installments {
+			value += installment.Amount / math.Pow(1+rate, float64(i+1))
+		}
+		return value
+	}
+
+	low, high := 0.0, 1.0
+	for presentValue(high) > principal {
+		high *= 2
+	}
+	for i := 0; i < 100; i++ {
+		mid := (low + high) / 2
+		if presentValue(mid) > principal {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+	return (low + high) / 2
+}
+
+// AccruedInterestAt returns the interest of an installment accrued by asOf,
+// earned evenly over the days of the month before its due date
+func (i *Installment) AccruedInterestAt(asOf time.Time) float64 {
+	start := i.DueDate.AddDate(0, -1, 0)
+	if !asOf.After(start) {
+		return 0
+	}
+	if !asOf.Before(i.DueDate) {
+		return i.Interest
+	}
+	days := math.Round(i.DueDate.Sub(start).Hours() / 24)
+	elapsed := math.Floor(asOf.Sub(start).Hours() / 24)
+	return math.Round(i.Interest*elapsed/days*100) / 100
+}
+
+// AccrualRepository represents the interest accrual repository contract
+type AccrualRepository interface {
+	// ListAccruing lists the approved contracts after afterTransactionID
+	// with interest still to accrue, by ID
+	ListAccruing(afterTransactionID uint, limit int) ([]uint, error)
+	// Accrue records an accrual, the interest accrued on its installments,
+	// checked against what they had accrued, and its journal entry.
+	// A contract accrues once a day.
+	Accrue(accrual *InterestAccrual) error
+	ListAccruals(transactionID uint, offset, limit int) ([]InterestAccrual, error)
+	Portfolio() (*PortfolioInterest, error)
+}
+
+// AccrualUseCase represents the interest accrual use case contract
+type AccrualUseCase interface {
+	// AccrueDaily accrues the interest of the performing contracts up to the
+	// start of the day of now, returning the contracts accrued
+	AccrueDaily(now time.Time) (int, error)
+	Contract(transactionID uint) (*ContractInterest, error)
+	ListAccruals(transactionID uint, offset, limit int) ([]InterestAccrual, error)
+	Portfolio() (*PortfolioInterest, error)
+}
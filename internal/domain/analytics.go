@@ -0,0 +1,182 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Roll rate states besides the delinquency buckets of collections
+const (
+	BucketCurrent DPDBucket = "current" // Nothing past due
+	BucketClosed  DPDBucket = "closed"  // Nothing outstanding, only as the state rolled to
+)
+
+// RollRateBuckets lists the states of a roll rate matrix in order
+var RollRateBuckets = []DPDBucket{
+	BucketCurrent,
+	Bucket1To30,
+	Bucket31To60,
+	Bucket61To90,
+	Bucket91To180,
+	BucketOver180,
+	BucketClosed,
+}
+
+// AnalyticsConfig configures the computation and caching of analytics
+type AnalyticsConfig struct {
+	CacheTTL   time.Duration // Results are computed again after the TTL, 0 disables the cache
+	VintageDPD int           // Days past due contracts count as delinquent after, by default
+	MaxMOB     int           // Months on book of the vintage matrix
+}
+
+// OutstandingGroup is the outstanding balance of the contracts sharing a key
+type OutstandingGroup struct {
+	Key       string  `json:"key"`
+	Contracts int     `json:"contracts"`
+	Principal float64 `json:"principal"`
+	Interest  float64 `json:"interest"`
+	Overdue   float64 `json:"overdue"` // Principal of the installments past due
+}
+
+// OutstandingSummary is the outstanding balance of the approved contracts
+type OutstandingSummary struct {
+	AsOf      time.Time          `json:"as_of"`
+	Contracts int                `json:"contracts"`
+	Principal float64            `json:"principal"`
+	Interest  float64            `json:"interest"`
+	Overdue   float64            `json:"overdue"`
+	BySource  []OutstandingGroup `json:"by_source"`
+	ByTenor   []OutstandingGroup `json:"by_tenor"`
+}
+
+// Disbursement totals the contracts of a source disbursed in a month, at
+// their financed amount
+type Disbursement struct {
+	Month     string            `json:"month"` // YYYY-MM
+	Source    TransactionSource `json:"source"`
+	Contracts int               `json:"contracts"`
+	Amount    float64           `json:"amount"`
+}
+
+// UtilisationGroup totals the credit limits sharing a key, a utilisation
+// band or a tenor
+type UtilisationGroup struct {
+	Key    string  `json:"key"`
+	Limits int     `json:"limits"`
+	Amount float64 `json:"amount"`
+	Used   float64 `json:"used"`
+	Rate   float64 `json:"rate"` // Used of amount
+}
+
+// Utilisation is the distribution of credit limit utilisation
+type Utilisation struct {
+	AsOf    time.Time          `json:"as_of"`
+	Limits  int                `json:"limits"`
+	Amount  float64            `json:"amount"`
+	Used    float64            `json:"used"`
+	Rate    float64            `json:"rate"`
+	Bands   []UtilisationGroup `json:"bands"`
+	ByTenor []UtilisationGroup `json:"by_tenor"`
+}
+
+// CollectionRate compares the installments due in a month with the amounts
+// collected on them
+type CollectionRate struct {
+	Month      string  `json:"month"` // YYYY-MM
+	Due        float64 `json:"due"`
+	Collected  float64 `json:"collected"` // Paid by the end of the month
+	OnTime     float64 `json:"on_time"`   // Paid by the due date
+	Rate       float64 `json:"rate"`
+	OnTimeRate float64 `json:"on_time_rate"`
+}
+
+// VintagePoint is the delinquency of a cohort of contracts some months on
+// book
+type VintagePoint struct {
+	Cohort     string  `json:"cohort"` // Month disbursed, YYYY-MM
+	MOB        int     `json:"mob"`    // Months on book
+	Contracts  int     `json:"contracts"`
+	Amount     float64 `json:"amount"`     // Financed amount of the cohort
+	Delinquent float64 `json:"delinquent"` // Financed amount of the contracts past the DPD threshold
+}
+
+// VintageCell is the delinquency rate of a cohort some months on book
+type VintageCell struct {
+	MOB        int     `json:"mob"`
+	Delinquent float64 `json:"delinquent"`
+	Rate       float64 `json:"rate"`
+}
+
+// VintageCohort is a row of a vintage matrix
+type VintageCohort struct {
+	Cohort    string        `json:"cohort"`
+	Contracts int           `json:"contracts"`
+	Amount    float64       `json:"amount"`
+	Cells     []VintageCell `json:"cells"`
+}
+
+// Vintage is the matrix of the delinquency rates of monthly cohorts by
+// months on book. Cells are only given for months that have ended.
+type Vintage struct {
+	DPD     int             `json:"dpd"` // Days past due a contract counts as delinquent after
+	Cohorts []VintageCohort `json:"cohorts"`
+}
+
+// RollTransition counts the contracts moving between two states over a month
+type RollTransition struct {
+	From      DPDBucket `json:"from"`
+	To        DPDBucket `json:"to"`
+	Contracts int       `json:"contracts"`
+}
+
+// RollRateCell is the share of a row's contracts that rolled to a state
+type RollRateCell struct {
+	To        DPDBucket `json:"to"`
+	Contracts int       `json:"contracts"`
+	Rate      float64   `json:"rate"`
+}
+
+// RollRateRow is a row of a roll rate matrix
+type RollRateRow struct {
+	From      DPDBucket      `json:"from"`
+	Contracts int            `json:"contracts"`
+	To        []RollRateCell `json:"to"`
+}
+
+// RollRate is the matrix of the contracts rolling between delinquency states
+// from the start to the end of a month
+type RollRate struct {
+	Month string        `json:"month"` // YYYY-MM
+	Rows  []RollRateRow `json:"rows"`
+}
+
+// AnalyticsRepository represents the analytics repository contract. Balances
+// are read as they are now; payments carry their date so delinquency is
+// computed at past dates.
+type AnalyticsRepository interface {
+	Outstanding(asOf time.Time) (*OutstandingSummary, error)
+	// Disbursements totals the contracts disbursed within [from, to) by
+	// month and source
+	Disbursements(from, to time.Time) ([]Disbursement, error)
+	Utilisation() (*Utilisation, error)
+	// CollectionRates compares the installments due within [from, to) by
+	// month with their payments
+	CollectionRates(from, to time.Time) ([]CollectionRate, error)
+	// Vintage computes the delinquency past dpd of the cohorts disbursed
+	// within [from, to), up to maxMOB months on book ended before asOf
+	Vintage(from, to time.Time, dpd, maxMOB int, asOf time.Time) ([]VintagePoint, error)
+	// RollRates counts the contracts open at from by their state at from and
+	// at to
+	RollRates(from, to time.Time) ([]RollTransition, error)
+}
+
+// AnalyticsUseCase represents the analytics use case contract. Months are
+// formatted YYYY-MM, ranges include both ends.
+type AnalyticsUseCase interface {
+	Outstanding(ctx context.Context) (*OutstandingSummary, error)
+	Disbursements(ctx context.Context, fromMonth, toMonth string) ([]Disbursement, error)
+	Utilisation(ctx context.Context) (*Utilisation, error)
+	CollectionRates(ctx context.Context, fromMonth, toMonth string) ([]CollectionRate, error)
+	Vintage(ctx context.Context, fromMonth, toMonth string, dpd int) (*Vintage, error)
+	RollRate(ctx context.Context, month string) (*RollRate, error)
+}
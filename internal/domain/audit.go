@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Actor identifies the authenticated user performing an operation
+type Actor struct {
+	ID   uint
+	Role string
+}
+
+// AuditLog records an operation performed by a back office user. Entries are
+// written in the same database transaction as the change they describe.
+type AuditLog struct {
+	ID         uint            `json:"id" gorm:"primaryKey"`
+	ActorID    uint            `json:"actor_id" gorm:"not null"`
+	ActorRole  string          `json:"actor_role" gorm:"not null"`
+	Action     string          `json:"action" gorm:"not null"`      // e.g. installment.reversed
+	EntityType string          `json:"entity_type" gorm:"not null"` // e.g. installment
+	EntityID   uint            `json:"entity_id" gorm:"not null"`
+	Details    json.RawMessage `json:"details,omitempty" gorm:"type:jsonb"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// AuditRepository represents the audit log repository contract
+type AuditRepository interface {
+	List(entityType string, entityID uint, offset, limit int) ([]AuditLog, error)
+}
+
+// AuditUseCase represents the audit log use case contract
+type AuditUseCase interface {
+	List(entityType string, entityID uint, offset, limit int) ([]AuditLog, error)
+}
@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// BlacklistType is the kind of identifier a blacklist entry bars
+type BlacklistType string
+
+const (
+	BlacklistNIK    BlacklistType = "nik"    // National identity number
+	BlacklistPhone  BlacklistType = "phone"  // Mobile number, normalised to the 08 prefix
+	BlacklistDevice BlacklistType = "device" // Device identifier reported by the partner or app
+)
+
+// Valid reports whether t is a known blacklist type
+func (t BlacklistType) Valid() bool {
+	switch t {
+	case BlacklistNIK, BlacklistPhone, BlacklistDevice:
+		return true
+	default:
+		return false
+	}
+}
+
+// BlacklistEntry bars an identifier from registering and from new contracts
+type BlacklistEntry struct {
+	ID        uint          `json:"id" gorm:"primaryKey"`
+	Type      BlacklistType `json:"type" gorm:"not null"`
+	Value     string        `json:"value" gorm:"not null"` // Unique per type, normalised
+	Reason    string        `json:"reason" gorm:"not null"`
+	CreatedBy *uint         `json:"created_by,omitempty"` // Back-office user who added the entry
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// NormalizeIdentifier normalises an identifier of type t so that the
+// spellings of one phone number match the same entry
+func NormalizeIdentifier(t BlacklistType, value string) string {
+	value = strings.TrimSpace(value)
+	if t != BlacklistPhone {
+		return value
+	}
+
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, value)
+	if strings.HasPrefix(digits, "62") {
+		digits = "0" + digits[2:]
+	}
+	return digits
+}
+
+// BlacklistRepository represents the blacklist repository contract
+type BlacklistRepository interface {
+	// Create returns a conflict if the identifier is blacklisted already
+	Create(entry *BlacklistEntry, audit *AuditLog) error
+	Delete(id uint, audit *AuditLog) error
+	List(entryType BlacklistType, offset, limit int) ([]BlacklistEntry, error)
+	// Match returns the entries barring any of the normalised identifiers,
+	// empty identifiers being skipped
+	Match(identifiers map[BlacklistType]string) ([]BlacklistEntry, error)
+}
+
+// BlacklistUseCase represents the blacklist use case contract
+type BlacklistUseCase interface {
+	Add(entry *BlacklistEntry, actor Actor) error
+	Remove(id uint, actor Actor) error
+	// List lists the entries of entryType, every entry when empty
+	List(entryType BlacklistType, offset, limit int) ([]BlacklistEntry, error)
+}
@@ -0,0 +1,230 @@
+package domain
+
+import (
+	"time"
+)
+
+// DPDBucket groups delinquent accounts by their days past due
+type DPDBucket string
+
+const (
+	Bucket1To30   DPDBucket = "1-30"
+	Bucket31To60  DPDBucket = "31-60"
+	Bucket61To90  DPDBucket = "61-90"
+	Bucket91To180 DPDBucket = "91-180"
+	BucketOver180 DPDBucket = "180+"
+)
+
+// BucketOf returns the bucket of an account dpd days past due
+func BucketOf(dpd int) DPDBucket {
+	switch {
+	case dpd > 180:
+		return BucketOver180
+	case dpd > 90:
+		return Bucket91To180
+	case dpd > 60:
+		return Bucket61To90
+	case dpd > 30:
+		return Bucket31To60
+	default:
+		return Bucket1To30
+	}
+}
+
+// CollectionLevel is the stage of collection an account is worked at
+type CollectionLevel string
+
+const (
+	LevelDesk  CollectionLevel = "desk"  // Calls and messages
+	LevelField CollectionLevel = "field" // Visits by field collectors
+	LevelLegal CollectionLevel = "legal" // Legal action and asset recovery
+)
+
+// Rank orders levels, accounts only ever escalating to a higher rank
+func (l CollectionLevel) Rank() int {
+	switch l {
+	case LevelLegal:
+		return 2
+	case LevelField:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Next returns the level above l, l itself at the highest level
+func (l CollectionLevel) Next() CollectionLevel {
+	switch l {
+	case LevelDesk:
+		return LevelField
+	default:
+		return LevelLegal
+	}
+}
+
+// CollectionStatus represents the status of a collection account
+type CollectionStatus string
+
+const (
+	CollectionOpen   CollectionStatus = "open"
+	CollectionClosed CollectionStatus = "closed" // No installment overdue anymore
+)
+
+// Collector is a back office user chasing overdue accounts of one level,
+// in one region or, without a region, in every region
+type Collector struct {
+	ID          uint            `json:"id" gorm:"primaryKey"`
+	UserID      uint            `json:"user_id" gorm:"unique;not null"` // Back office user the collector signs in as
+	Name        string          `json:"name" gorm:"not null"`
+	Level       CollectionLevel `json:"level" gorm:"not null"`
+	Region      string          `json:"region,omitempty"`
+	MaxAccounts int             `json:"max_accounts" gorm:"not null;default:0"` // Open accounts assigned automatically at most, 0 for no bound
+	Active      bool            `json:"active" gorm:"not null;default:true"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// CollectionAccount tracks the collection of a contract with overdue
+// installments. An account is reopened when its contract falls overdue
+// again, keeping its activity history.
+type CollectionAccount struct {
+	ID             uint             `json:"id" gorm:"primaryKey"`
+	TransactionID  uint             `json:"transaction_id" gorm:"unique;not null"`
+	CustomerID     uint             `json:"customer_id" gorm:"not null"`
+	Region         string           `json:"region,omitempty"` // Region of the customer
+	Status         CollectionStatus `json:"status" gorm:"not null"`
+	DPD            int              `json:"dpd" gorm:"column:dpd;not null"` // Days past due of the oldest overdue installment
+	Bucket         DPDBucket        `json:"bucket" gorm:"not null"`
+	Level          CollectionLevel  `json:"level" gorm:"not null"`
+	CollectorID    *uint            `json:"collector_id,omitempty"`
+	OverdueAmount  float64          `json:"overdue_amount" gorm:"not null"` // Overdue installments and their late fees
+	OldestDueDate  time.Time        `json:"oldest_due_date" gorm:"not null"`
+	PromiseDate    *time.Time       `json:"promise_date,omitempty"` // Open promise to pay
+	PromiseAmount  float64          `json:"promise_amount" gorm:"not null;default:0"`
+	BrokenPromises int              `json:"broken_promises" gorm:"not null;default:0"` // Broken since the last escalation
+	LastActivityAt *time.Time       `json:"last_activity_at,omitempty"`
+	EscalatedAt    *time.Time       `json:"escalated_at,omitempty"`
+	ClosedAt       *time.Time       `json:"closed_at,omitempty"`
+	Version        int              `json:"version" gorm:"not null;default:1"` // For optimistic locking
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+
+	// Relations
+	Collector  *Collector           `json:"collector,omitempty" gorm:"foreignKey:CollectorID"`
+	Activities []CollectionActivity `json:"activities,omitempty" gorm:"foreignKey:AccountID"`
+
+	// Logged is written together with the account
+	Logged []CollectionActivity `json:"-" gorm:"-"`
+}
+
+// CollectionActivityType represents the kind of collection activity
+type CollectionActivityType string
+
+const (
+	ActivityCall          CollectionActivityType = "call"
+	ActivityMessage       CollectionActivityType = "message"
+	ActivityVisit         CollectionActivityType = "visit"
+	ActivityNote          CollectionActivityType = "note"
+	ActivityPromiseToPay  CollectionActivityType = "promise_to_pay"
+	ActivityPromiseBroken CollectionActivityType = "promise_broken" // Recorded by the system
+	ActivityAssignment    CollectionActivityType = "assignment"     // Recorded by the system
+	ActivityEscalation    CollectionActivityType = "escalation"     // Recorded by the system
+)
+
+// CollectionActivity is an entry of the activity log of an account
+type CollectionActivity struct {
+	ID            uint                   `json:"id" gorm:"primaryKey"`
+	AccountID     uint                   `json:"account_id" gorm:"not null"`
+	Type          CollectionActivityType `json:"type" gorm:"not null"`
+	Outcome       string                 `json:"outcome,omitempty"` // e.g. reached, no_answer, refused
+	Note          string                 `json:"note,omitempty"`
+	PromiseDate   *time.Time             `json:"promise_date,omitempty"`
+	PromiseAmount float64                `json:"promise_amount,omitempty"`
+	ActorID       *uint                  `json:"actor_id,omitempty"` // Nil for activities recorded by the system
+	CreatedAt     time.Time              `json:"created_at"`
+}
+
+// Delinquency summarises the overdue installments of a contract
+type Delinquency struct {
+	TransactionID       uint
+	CustomerID          uint
+	Region              string
+	OverdueInstallments int
+	OverdueAmount       float64
+	OldestDueDate       time.Time
+}
+
+// CollectionPolicy configures escalation
+type CollectionPolicy struct {
+	FieldDPD          int // Days past due escalating accounts to field collection
+	LegalDPD          int // Days past due escalating accounts to legal
+	MaxBrokenPromises int // Broken promises escalating an account one level, 0 to never escalate on promises
+}
+
+// LevelFor returns the level an account dpd days past due is worked at
+func (p CollectionPolicy) LevelFor(dpd int) CollectionLevel {
+	switch {
+	case p.LegalDPD > 0 && dpd >= p.LegalDPD:
+		return LevelLegal
+	case p.FieldDPD > 0 && dpd >= p.FieldDPD:
+		return LevelField
+	default:
+		return LevelDesk
+	}
+}
+
+// WorklistFilter filters open collection accounts
+type WorklistFilter struct {
+	Bucket      DPDBucket
+	Region      string
+	Level       CollectionLevel
+	CollectorID *uint
+}
+
+// CollectionRepository represents the collection repository contract
+type CollectionRepository interface {
+	CreateCollector(collector *Collector) error
+	UpdateCollector(collector *Collector) error
+	GetCollector(id uint) (*Collector, error)
+	GetCollectorByUser(userID uint) (*Collector, error)
+	ListCollectors(offset, limit int) ([]Collector, error)
+	// LeastLoadedCollector returns the active collector of level with the
+	// fewest open accounts and room for another, preferring collectors of
+	// region to those without a region
+	LeastLoadedCollector(level CollectionLevel, region string) (*Collector, error)
+
+	// ListDelinquencies lists the contracts with overdue installments by
+	// transaction ID, after afterTransactionID
+	ListDelinquencies(afterTransactionID uint, limit int) ([]Delinquency, error)
+	GetAccount(id uint) (*CollectionAccount, error)
+	GetAccountByTransaction(transactionID uint) (*CollectionAccount, error)
+	// SaveAccount creates the account, or updates it when it has an ID, with
+	// the activities it logged
+	SaveAccount(account *CollectionAccount) error
+	// CloseResolved closes the open accounts without overdue installments
+	CloseResolved(at time.Time) (int, error)
+	ListUnassigned(limit int) ([]CollectionAccount, error)
+	// ListWorklist lists open accounts, the most days past due first
+	ListWorklist(filter WorklistFilter, offset, limit int) ([]CollectionAccount, error)
+}
+
+// CollectionUseCase represents the collection use case contract
+type CollectionUseCase interface {
+	CreateCollector(collector *Collector) error
+	UpdateCollector(collector *Collector) error
+	ListCollectors(offset, limit int) ([]Collector, error)
+
+	// Sync opens and updates the accounts of contracts with overdue
+	// installments, closes the resolved ones, applies the escalation rules
+	// and assigns unassigned accounts
+	Sync(now time.Time) (int, error)
+	// GetAccount returns an account with its activity log. Collectors may
+	// only read their own accounts.
+	GetAccount(id uint, actor Actor) (*CollectionAccount, error)
+	// Worklist lists open accounts. Collectors only see their own accounts.
+	Worklist(filter WorklistFilter, actor Actor, offset, limit int) ([]CollectionAccount, error)
+	Assign(accountID, collectorID uint, actor Actor) (*CollectionAccount, error)
+	// LogActivity records a call, message, visit, note or promise to pay
+	// on an account. Collectors may only log on their own accounts.
+	LogActivity(accountID uint, activity *CollectionActivity, actor Actor) (*CollectionAccount, error)
+}
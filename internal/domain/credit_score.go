@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"time"
+)
+
+// Scoring attributes of a customer
+const (
+	AttrAge                 = "age"                  // Years
+	AttrSalary              = "salary"               // Monthly, in rupiah
+	AttrLimitUtilisation    = "limit_utilisation"    // Used share of all credit limits
+	AttrTenorsUsed          = "tenors_used"          // Tenors with credit limit in use
+	AttrPaidInstallments    = "paid_installments"    // Installments ever paid
+	AttrOnTimeRatio         = "on_time_ratio"        // Share of paid installments paid by their due date, unknown without any
+	AttrMaxDaysLate         = "max_days_late"        // Longest delay of a paid or overdue installment
+	AttrOverdueInstallments = "overdue_installments" // Installments currently overdue
+)
+
+// ScoreCharacteristic is the contribution of one attribute to a score
+type ScoreCharacteristic struct {
+	Attribute  string   `json:"attribute"`
+	Value      *float64 `json:"value"` // Nil when unknown
+	Points     int      `json:"points"`
+	MaxPoints  int      `json:"max_points"`
+	ReasonCode string   `json:"reason_code"`
+}
+
+// ScoreReason explains a characteristic that lowered a score
+type ScoreReason struct {
+	Code        string `json:"code"`
+	Description string `json:"description"`
+	PointsLost  int    `json:"points_lost"`
+}
+
+// CreditScore is a customer's score calculated by a scorecard. Every score
+// calculated is kept as the customer's score history.
+type CreditScore struct {
+	ID               uint                  `json:"id" gorm:"primaryKey"`
+	CustomerID       uint                  `json:"customer_id" gorm:"not null"`
+	Scorecard        string                `json:"scorecard" gorm:"not null"`
+	ScorecardVersion string                `json:"scorecard_version" gorm:"not null"`
+	Score            int                   `json:"score" gorm:"not null"`
+	Outcome          UnderwritingOutcome   `json:"outcome" gorm:"not null"` // Outcome the score's cutoffs imply
+	Attributes       map[string]float64    `json:"attributes" gorm:"type:jsonb;serializer:json;not null"`
+	Characteristics  []ScoreCharacteristic `json:"characteristics" gorm:"type:jsonb;serializer:json;not null"`
+	Reasons          []ScoreReason         `json:"reasons" gorm:"type:jsonb;serializer:json;not null"` // Largest loss first
+	CreatedAt        time.Time             `json:"created_at"`
+}
+
+// ReasonCodes returns the codes of the reasons lowering the score
+func (s *CreditScore) ReasonCodes() []string {
+	codes := make([]string, len(s.Reasons))
+	for i, reason := range s.Reasons {
+		codes[i] = reason.Code
+	}
+	return codes
+}
+
+// PaymentHistory summarises how a customer paid installments
+type PaymentHistory struct {
+	PaidInstallments    int
+	PaidOnTime          int
+	MaxDaysLate         int
+	OverdueInstallments int
+}
+
+// CreditScoreRepository represents the credit score repository contract
+type CreditScoreRepository interface {
+	Create(score *CreditScore) error
+	ListByCustomer(customerID uint, offset, limit int) ([]CreditScore, error)
+	GetPaymentHistory(customerID uint, asOf time.Time) (*PaymentHistory, error)
+}
+
+// ScoringUseCase represents the credit scoring use case contract
+type ScoringUseCase interface {
+	// Score calculates and records the current score of a customer
+	Score(customerID uint) (*CreditScore, error)
+	ListHistory(customerID uint, offset, limit int) ([]CreditScore, error)
+}
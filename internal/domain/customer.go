@@ -2,23 +2,30 @@ package domain
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // Customer represents the customer entity
 type Customer struct {
-	ID           uint       `json:"id" gorm:"primaryKey"`
-	NIK          string     `json:"nik" gorm:"unique;not null"`
-	FullName     string     `json:"full_name" gorm:"not null"`
-	LegalName    string     `json:"legal_name" gorm:"not null"`
-	PlaceOfBirth string     `json:"place_of_birth" gorm:"not null"`
-	DateOfBirth  time.Time  `json:"date_of_birth" gorm:"not null"`
-	Salary       float64    `json:"salary" gorm:"not null"`
-	KTPPhoto     string     `json:"ktp_photo" gorm:"not null"`
-	SelfiePhoto  string     `json:"selfie_photo" gorm:"not null"`
-	Version      int        `json:"version" gorm:"not null;default:1"` // For optimistic locking
-	CreatedAt    time.Time  `json:"created_at"`
-	UpdatedAt    time.Time  `json:"updated_at"`
-	DeletedAt    *time.Time `json:"deleted_at,omitempty" gorm:"index"`
+	ID           uint           `json:"id" gorm:"primaryKey"`
+	NIK          string         `json:"nik" gorm:"unique;not null"`
+	FullName     string         `json:"full_name" gorm:"not null"`
+	LegalName    string         `json:"legal_name" gorm:"not null"`
+	PlaceOfBirth string         `json:"place_of_birth" gorm:"not null"`
+	DateOfBirth  time.Time      `json:"date_of_birth" gorm:"not null"`
+	Salary       float64        `json:"salary" gorm:"not null"`
+	KTPPhoto     string         `json:"ktp_photo" gorm:"not null"`
+	SelfiePhoto  string         `json:"selfie_photo" gorm:"not null"`
+	Phone        string         `json:"phone,omitempty"`
+	Region       string         `json:"region,omitempty"`                                      // Region of domicile, collection accounts are worked by region
+	DeviceID     string         `json:"device_id,omitempty"`                                   // Device the customer registered from
+	RiskFlag     RiskFlag       `json:"risk_flag" gorm:"not null;default:'clear'"`             // Held customers have every contract held for review
+	RiskHits     []ScreeningHit `json:"risk_hits,omitempty" gorm:"type:jsonb;serializer:json"` // Screening hits of the registration
+	Version      int            `json:"version" gorm:"not null;default:1"`                     // For optimistic locking
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"` // Soft delete
 
 	// Relations
 	CreditLimits []CreditLimit `json:"credit_limits,omitempty" gorm:"foreignKey:CustomerID"`
@@ -35,6 +42,25 @@ type CreditLimit struct {
 	Version    int       `json:"version" gorm:"not null;default:1"` // For optimistic locking
 	CreatedAt  time.Time `json:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at"`
+
+	// Events is written to the outbox together with the credit limit
+	Events []OutboxEvent `json:"-" gorm:"-"`
+	// Processed is recorded with the update, rejecting a redelivered event
+	Processed *ProcessedEvent `json:"-" gorm:"-"`
+	// Charge is saved with the update when it charges or releases a contract
+	Charge *ContractLimitCharge `json:"-" gorm:"-"`
+}
+
+// ContractLimitCharge is the part of a credit limit held by a contract. The
+// charge and release of a contract are delivered as separate events in any
+// order, so a release only frees what the contract was charged and a charge
+// arriving after the contract closed is skipped.
+type ContractLimitCharge struct {
+	ContractNumber string    `gorm:"primaryKey"`
+	CreditLimitID  uint      `gorm:"not null"`
+	Amount         float64   `gorm:"not null;default:0"`     // Charged and not released yet
+	Closed         bool      `gorm:"not null;default:false"` // Released, later charges are skipped unless reopening
+	UpdatedAt      time.Time `gorm:"not null"`
 }
 
 // GetAvailableLimit calculates remaining credit limit
@@ -47,10 +73,14 @@ type CustomerRepository interface {
 	Create(customer *Customer) error
 	GetByID(id uint) (*Customer, error)
 	GetByNIK(nik string) (*Customer, error)
+	GetByIDUnscoped(id uint) (*Customer, error)
 	Update(customer *Customer) error
 	Delete(id uint) error
+	HasActiveContracts(customerID uint) (bool, error)
+	Anonymize(id uint) error
 	List(offset, limit int) ([]Customer, error)
 	GetCreditLimits(customerID uint) ([]CreditLimit, error)
+	GetContractLimitCharge(contractNumber string) (*ContractLimitCharge, error)
 	UpdateCreditLimit(limit *CreditLimit) error
 }
 
@@ -59,7 +89,9 @@ type CustomerUseCase interface {
 	Register(customer *Customer) error
 	GetProfile(id uint) (*Customer, error)
 	UpdateProfile(customer *Customer) error
+	Delete(id uint) error
 	GetCreditLimits(customerID uint) ([]CreditLimit, error)
 	CheckCreditLimit(customerID uint, amount float64, tenor int) (bool, error)
 	UpdateCreditLimitUsage(customerID uint, amount float64, tenor int) error
+	AdjustCreditLimitUsage(adjustment *LimitAdjustment) error
 }
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"time"
+)
+
+// ErasureStatus represents the status of an erasure request
+type ErasureStatus string
+
+const (
+	ErasurePending   ErasureStatus = "pending"
+	ErasureCompleted ErasureStatus = "completed"
+)
+
+// ErasureRequest represents a customer's right-to-be-forgotten request.
+// Personal data is anonymised once EligibleAt has passed; financial records
+// (transactions and installments) are kept.
+type ErasureRequest struct {
+	ID          uint          `json:"id" gorm:"primaryKey"`
+	CustomerID  uint          `json:"customer_id" gorm:"not null"`
+	Status      ErasureStatus `json:"status" gorm:"not null;default:'pending'"`
+	RequestedAt time.Time     `json:"requested_at" gorm:"not null"`
+	EligibleAt  time.Time     `json:"eligible_at" gorm:"not null"` // End of the legal retention period
+	CompletedAt *time.Time    `json:"completed_at,omitempty"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
+// ErasureRequestRepository represents the erasure request repository contract
+type ErasureRequestRepository interface {
+	Create(request *ErasureRequest) error
+	GetPendingByCustomerID(customerID uint) (*ErasureRequest, error)
+	ListDue(now time.Time, limit int) ([]ErasureRequest, error)
+	MarkCompleted(id uint, completedAt time.Time) error
+}
+
+// ErasureUseCase represents the erasure use case contract
+type ErasureUseCase interface {
+	RequestErasure(customerID uint) (*ErasureRequest, error)
+	ProcessDueRequests(now time.Time) (int, error)
+}
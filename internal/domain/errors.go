@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"errors"
+)
+
+// Sentinel errors describing the kind of failure. Layers wrap these so the
+// delivery layer can map them to a response without comparing strings.
+var (
+	ErrNotFound          = errors.New("not found")
+	ErrConflict          = errors.New("conflict")
+	ErrInsufficientLimit = errors.New("insufficient credit limit")
+	ErrValidation        = errors.New("validation failed")
+	ErrForbidden         = errors.New("forbidden")
+	ErrUnauthorized      = errors.New("unauthorized")
+
+	ErrPreconditionFailed   = errors.New("precondition failed")
+	ErrPreconditionRequired = errors.New("precondition required")
+)
+
+// Error is a domain error carrying a stable machine-readable code
+type Error struct {
+	Kind    error  // One of the sentinel errors above
+	Code    string // Stable error code exposed to API clients
+	Message string // Human readable message, safe to expose to API clients
+	Err     error  // Underlying cause, never exposed to API clients
+}
+
+// NewError creates a new domain error of the given kind
+func NewError(kind error, code, message string) *Error {
+	return &Error{
+		Kind:    kind,
+		Code:    code,
+		Message: message,
+	}
+}
+
+// WrapError creates a new domain error of the given kind wrapping a cause
+func WrapError(kind error, code, message string, err error) *Error {
+	return &Error{
+		Kind:    kind,
+		Code:    code,
+		Message: message,
+		Err:     err,
+	}
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+// Unwrap allows errors.Is and errors.As to match both the kind and the cause
+func (e *Error) Unwrap() []error {
+	if e.Err != nil {
+		return []error{e.Kind, e.Err}
+	}
+	return []error{e.Kind}
+}
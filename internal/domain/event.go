@@ -0,0 +1,144 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Internal domain events, published to the event bus together with the
+// webhook events
+const (
+	EventTransactionCreated       EventType = "transaction.created"
+	EventTransactionStatusChanged EventType = "transaction.status_changed"
+	EventLimitAdjusted            EventType = "credit_limit.adjusted"
+	EventContractReopened         EventType = "contract.reopened" // A paid off contract has an unpaid installment again
+	EventWriteOffRecovered        EventType = "write_off.recovered"
+)
+
+// ErrEventProcessed is returned by repositories when a change records an
+// event as processed that its consumer already processed, so the change was
+// not applied again
+var ErrEventProcessed = errors.New("event already processed")
+
+// Event is the envelope of every outbox event, as published to the event bus
+// and delivered to webhooks
+type Event struct {
+	ID         string          `json:"id"`
+	Type       EventType       `json:"type"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// Decode unmarshals the event data into v
+func (e *Event) Decode(v interface{}) error {
+	return json.Unmarshal(e.Data, v)
+}
+
+// ContractEvent is the data of contract.* events
+type ContractEvent struct {
+	TransactionID  uint              `json:"transaction_id"`
+	ContractNumber string            `json:"contract_number"`
+	Status         TransactionStatus `json:"status"`
+}
+
+// InstallmentEvent is the data of installment.* events
+type InstallmentEvent struct {
+	InstallmentID     uint       `json:"installment_id"`
+	InstallmentNumber int        `json:"installment_number"`
+	TransactionID     uint       `json:"transaction_id"`
+	ContractNumber    string     `json:"contract_number"`
+	Amount            float64    `json:"amount"`
+	DueDate           time.Time  `json:"due_date"`
+	Status            string     `json:"status"`
+	LateFee           float64    `json:"late_fee,omitempty"`
+	PaidAt            *time.Time `json:"paid_at,omitempty"`
+}
+
+// TransactionCreated is the data of transaction.created events. The ID is
+// not known until the transaction is inserted, so the contract number
+// identifies it.
+type TransactionCreated struct {
+	ContractNumber string  `json:"contract_number"`
+	CustomerID     uint    `json:"customer_id"`
+	PartnerID      *uint   `json:"partner_id,omitempty"`
+	MerchantID     *uint   `json:"merchant_id,omitempty"`
+	Amount         float64 `json:"amount"` // OTR price plus admin fee, charged to the credit limit
+	Tenor          int     `json:"tenor"`
+}
+
+// TransactionStatusChanged is the data of transaction.status_changed events
+type TransactionStatusChanged struct {
+	TransactionID  uint              `json:"transaction_id"`
+	ContractNumber string            `json:"contract_number"`
+	CustomerID     uint              `json:"customer_id"`
+	From           TransactionStatus `json:"from"`
+	To             TransactionStatus `json:"to"`
+	Amount         float64           `json:"amount"` // OTR price plus admin fee, charged to the credit limit
+	Tenor          int               `json:"tenor"`
+}
+
+// WriteOffRecovered is the data of write_off.recovered events
+type WriteOffRecovered struct {
+	WriteOffID    uint      `json:"write_off_id"`
+	TransactionID uint      `json:"transaction_id"`
+	Amount        float64   `json:"amount"`
+	ReceivedAt    time.Time `json:"received_at"`
+}
+
+// LimitAdjusted is the data of credit_limit.adjusted events
+type LimitAdjusted struct {
+	CustomerID     uint    `json:"customer_id"`
+	CreditLimitID  uint    `json:"credit_limit_id"`
+	Tenor          int     `json:"tenor"`
+	Delta          float64 `json:"delta"` // Positive when usage grew, negative when released
+	UsedAmount     float64 `json:"used_amount"`
+	Amount         float64 `json:"amount"`
+	ContractNumber string  `json:"contract_number,omitempty"`
+	Reason         string  `json:"reason"`
+}
+
+// ProcessedEvent records that a consumer group applied an event. It is
+// written in the same database transaction as the event's side effect, so a
+// redelivered event is detected and skipped.
+type ProcessedEvent struct {
+	Consumer    string    `gorm:"primaryKey"`
+	EventID     string    `gorm:"primaryKey"`
+	ProcessedAt time.Time `gorm:"not null"`
+}
+
+// LimitAdjustment changes the used amount of a customer's credit limit
+type LimitAdjustment struct {
+	CustomerID     uint
+	Tenor          int
+	Delta          float64 // Positive reserves limit, negative releases it
+	ContractNumber string  // Contract charged or released, whose charge is tracked when set
+	Reason         string
+	Source         *ProcessedEvent // Event causing the adjustment, if any
+	Force          bool            // Reserve even beyond the available limit, for charges that cannot be refused
+	Reopen         bool            // Charge the contract even though it was closed, e.g. reopened by a payment reversal
+}
+
+// EventHandler processes events delivered by the event bus. Delivery is at
+// least once, so handlers must be idempotent.
+type EventHandler interface {
+	// Group names the consumer group. Each group receives every event once.
+	Group() string
+	Handle(ctx context.Context, event *Event) error
+}
+
+// EventPublisher publishes outbox events to the event bus
+type EventPublisher interface {
+	Publish(ctx context.Context, event *OutboxEvent) error
+}
+
+// OutboxRepository represents the outbox repository contract
+type OutboxRepository interface {
+	PublishPending(limit int, publish func(events []OutboxEvent) error) (int, error)
+}
+
+// EventRelayUseCase represents the event relay use case contract
+type EventRelayUseCase interface {
+	Relay(ctx context.Context) (int, error)
+}
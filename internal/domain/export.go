@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"time"
+)
+
+// ExportFormat is the file format of a data export
+type ExportFormat string
+
+const (
+	ExportCSV  ExportFormat = "csv"
+	ExportXLSX ExportFormat = "xlsx"
+)
+
+// StatementLine is a movement of the amounts a customer owes, read from the
+// receivable accounts of the ledger
+type StatementLine struct {
+	LineID         uint        `json:"line_id"`
+	PostedAt       time.Time   `json:"posted_at"`
+	ContractNumber string      `json:"contract_number"`
+	EntryType      JournalType `json:"entry_type"`
+	Description    string      `json:"description"`
+	Reference      string      `json:"reference"`
+	Debit          float64     `json:"debit"`  // Owed more
+	Credit         float64     `json:"credit"` // Paid, or no longer owed
+}
+
+// RowWriter receives the rows of an export, a header row first
+type RowWriter interface {
+	WriteRow(cells ...interface{}) error
+}
+
+// ExportRepository represents the export repository contract. Rows are read
+// in batches after a cursor, so exports of any size are streamed.
+type ExportRepository interface {
+	// ListTransactions lists the transactions matching filter by ID after
+	// afterID, without their installments
+	ListTransactions(filter TransactionFilter, afterID uint, limit int) ([]Transaction, error)
+	// StatementBalance sums what the contracts of a customer owed on the
+	// receivable accounts before a date
+	StatementBalance(customerID uint, before time.Time) (float64, error)
+	// ListStatementLines lists the receivable lines of the contracts of a
+	// customer posted before to, in posting order after the line afterLineID
+	// posted at afterPostedAt
+	ListStatementLines(customerID uint, to, afterPostedAt time.Time, afterLineID uint, limit int) ([]StatementLine, error)
+}
+
+// ExportUseCase represents the export use case contract
+type ExportUseCase interface {
+	// Transactions writes the transactions matching filter
+	Transactions(filter TransactionFilter, w RowWriter) error
+	// Installments writes the installment schedule of a contract
+	Installments(transactionID uint, w RowWriter) error
+	// Statement writes the account statement of a customer for [from, to):
+	// the opening balance, the movements and the closing balance
+	Statement(customerID uint, from, to time.Time, w RowWriter) error
+}
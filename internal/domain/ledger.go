@@ -0,0 +1,223 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// AccountType classifies general ledger accounts
+type AccountType string
+
+const (
+	AccountAsset     AccountType = "asset"
+	AccountLiability AccountType = "liability"
+	AccountEquity    AccountType = "equity"
+	AccountIncome    AccountType = "income"
+	AccountExpense   AccountType = "expense"
+)
+
+// NormalBalance is the side an account increases on
+type NormalBalance string
+
+const (
+	NormalDebit  NormalBalance = "debit"
+	NormalCredit NormalBalance = "credit"
+)
+
+// Accounts of the chart of accounts the posting rules post to. Receivables
+// are carried at the installments still to be paid, less the interest not
+// yet earned.
+const (
+	AccountCash                = "1101" // Cash and banks
+	AccountFinancingReceivable = "1201" // Installments still to be paid
+	AccountUnearnedInterest    = "1202" // Contra asset, interest of the installments not yet earned
+	AccountLateFeeReceivable   = "1203"
+	AccountDeferredAdminFee    = "2101"
+	AccountInterestIncome      = "4101"
+	AccountAdminFeeIncome      = "4102"
+	AccountLateFeeIncome       = "4103"
+	AccountRecoveryIncome      = "4104" // Recovered after a write-off
+	AccountWriteOffExpense     = "5101"
+)
+
+// Account is an account of the chart of accounts
+type Account struct {
+	ID            uint          `json:"id" gorm:"primaryKey"`
+	Code          string        `json:"code" gorm:"unique;not null"`
+	Name          string        `json:"name" gorm:"not null"`
+	Type          AccountType   `json:"type" gorm:"not null"`
+	NormalBalance NormalBalance `json:"normal_balance" gorm:"not null"`
+	Active        bool          `json:"active" gorm:"not null;default:true"` // Inactive accounts take no new postings
+	CreatedAt     time.Time     `json:"created_at"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+}
+
+// JournalType identifies the event a journal entry records
+type JournalType string
+
+const (
+	JournalDisbursement       JournalType = "disbursement"
+	JournalAdminFee           JournalType = "admin_fee"
+	JournalInterestAccrual    JournalType = "interest_accrual"
+	JournalInstallmentPayment JournalType = "installment_payment"
+	JournalLateFee            JournalType = "late_fee"
+	JournalRestructuring      JournalType = "restructuring"
+	JournalWriteOff           JournalType = "write_off"
+	JournalRecovery           JournalType = "recovery"
+	JournalReversal           JournalType = "reversal" // Mirrors the entry it reverses
+	JournalManual             JournalType = "manual"   // Adjustment posted by finance
+)
+
+// JournalEntry is a balanced double-entry posting. Entries are never
+// changed; a mistake is corrected by a reversal.
+type JournalEntry struct {
+	ID            uint        `json:"id" gorm:"primaryKey"`
+	Type          JournalType `json:"type" gorm:"not null"`
+	TransactionID *uint       `json:"transaction_id,omitempty"` // Contract the entry belongs to
+	Reference     string      `json:"reference,omitempty"`      // e.g. installment:12
+	Description   string      `json:"description" gorm:"not null"`
+	PostedAt      time.Time   `json:"posted_at" gorm:"not null"` // Accounting date
+	ReversalOf    *uint       `json:"reversal_of,omitempty"`     // Entry reversed by this one
+	CreatedBy     *uint       `json:"created_by,omitempty"`      // Nil for entries posted from events
+	CreatedAt     time.Time   `json:"created_at"`
+
+	Lines []JournalLine `json:"lines" gorm:"foreignKey:EntryID"`
+}
+
+// JournalLine debits or credits one account
+type JournalLine struct {
+	ID          uint    `json:"id" gorm:"primaryKey"`
+	EntryID     uint    `json:"entry_id" gorm:"not null"`
+	AccountCode string  `json:"account_code" gorm:"not null"`
+	Debit       float64 `json:"debit" gorm:"not null;default:0"`
+	Credit      float64 `json:"credit" gorm:"not null;default:0"`
+}
+
+// Post adds a line to the entry, a debit when amount is positive and a
+// credit when negative. Zero amounts are skipped.
+func (e *JournalEntry) Post(accountCode string, amount float64) {
+	amount = math.Round(amount*100) / 100
+	switch {
+	case amount > 0:
+		e.Lines = append(e.Lines, JournalLine{AccountCode: accountCode, Debit: amount})
+	case amount < 0:
+		e.Lines = append(e.Lines, JournalLine{AccountCode: accountCode, Credit: -amount})
+	}
+}
+
+// Validate checks the entry debits as much as it credits, over lines that
+// each debit or credit a positive amount
+func (e *JournalEntry) Validate() error {
+	if len(e.Lines) < 2 {
+		return fmt.Errorf("journal entry needs at least two lines")
+	}
+	var debit, credit int64
+	for _, line := range e.Lines {
+		if line.AccountCode == "" {
+			return fmt.Errorf("journal line needs an account")
+		}
+		if line.Debit < 0 || line.Credit < 0 || (line.Debit > 0) == (line.Credit > 0) {
+			return fmt.Errorf("journal line of account %s must either debit or credit a positive amount", line.AccountCode)
+		}
+		debit += int64(math.Round(line.Debit * 100))
+		credit += int64(math.Round(line.Credit * 100))
+	}
+	if debit != credit {
+		return fmt.Errorf("journal entry is not balanced: debits %.2f, credits %.2f", float64(debit)/100, float64(credit)/100)
+	}
+	return nil
+}
+
+// Reversal returns the entry mirroring e, posted at postedAt
+func (e *JournalEntry) Reversal(postedAt time.Time) JournalEntry {
+	reversal := JournalEntry{
+		Type:          JournalReversal,
+		TransactionID: e.TransactionID,
+		Reference:     e.Reference,
+		Description:   fmt.Sprintf("Reversal of %s entry %d", e.Type, e.ID),
+		PostedAt:      postedAt,
+		ReversalOf:    &e.ID,
+	}
+	for _, line := range e.Lines {
+		reversal.Lines = append(reversal.Lines, JournalLine{AccountCode: line.AccountCode, Debit: line.Credit, Credit: line.Debit})
+	}
+	return reversal
+}
+
+// JournalFilter filters journal entries
+type JournalFilter struct {
+	TransactionID *uint
+	Type          JournalType
+	From          *time.Time // Posted at or after
+	To            *time.Time // Posted before
+}
+
+// TrialBalanceLine is the balance of one account, shown on its debit or
+// credit side
+type TrialBalanceLine struct {
+	Code          string        `json:"code"`
+	Name          string        `json:"name"`
+	Type          AccountType   `json:"type"`
+	NormalBalance NormalBalance `json:"normal_balance"`
+	Debit         float64       `json:"debit"`
+	Credit        float64       `json:"credit"`
+}
+
+// TrialBalance lists the balances of every account as of a date
+type TrialBalance struct {
+	AsOf        time.Time          `json:"as_of"`
+	Accounts    []TrialBalanceLine `json:"accounts"`
+	TotalDebit  float64            `json:"total_debit"`
+	TotalCredit float64            `json:"total_credit"`
+	Balanced    bool               `json:"balanced"`
+}
+
+// AccountBalance is the debits less the credits posted to an account
+type AccountBalance struct {
+	Account
+	Balance float64
+}
+
+// LedgerRepository represents the general ledger repository contract
+type LedgerRepository interface {
+	ListAccounts() ([]Account, error)
+	CreateAccount(account *Account) error
+	UpdateAccount(account *Account) error
+	// Post records the entries, refusing them as a whole when one posts to
+	// an unknown or inactive account. The entries are recorded together
+	// with the event they were posted from, and with the audit entry of
+	// manual postings.
+	Post(entries []JournalEntry, source *ProcessedEvent, audit *AuditLog) error
+	GetEntry(id uint) (*JournalEntry, error)
+	ListEntries(filter JournalFilter, offset, limit int) ([]JournalEntry, error)
+	// ContractBalances returns the debits less the credits posted for a
+	// contract, by account code
+	ContractBalances(transactionID uint) (map[string]float64, error)
+	// ListUnreversed lists the entries of a type and reference that were not
+	// reversed, the latest first
+	ListUnreversed(entryType JournalType, reference string) ([]JournalEntry, error)
+	// Balances returns the balance of every account from the entries posted
+	// before asOf
+	Balances(asOf time.Time) ([]AccountBalance, error)
+}
+
+// LedgerUseCase represents the general ledger use case contract. The Post
+// methods apply the posting rule of an event once, recording source as
+// processed.
+type LedgerUseCase interface {
+	ListAccounts() ([]Account, error)
+	CreateAccount(account *Account) error
+	UpdateAccount(account *Account) error
+	PostManual(entry *JournalEntry, actor Actor) error
+	GetEntry(id uint) (*JournalEntry, error)
+	ListEntries(filter JournalFilter, offset, limit int) ([]JournalEntry, error)
+	TrialBalance(asOf time.Time) (*TrialBalance, error)
+
+	PostDisbursement(transactionID uint, at time.Time, source *ProcessedEvent) error
+	PostInstallmentPayment(installment InstallmentEvent, at time.Time, source *ProcessedEvent) error
+	PostInstallmentReversal(installment InstallmentEvent, at time.Time, source *ProcessedEvent) error
+	PostRestructuring(restructuring ContractRestructured, at time.Time, source *ProcessedEvent) error
+	PostWriteOff(transactionID uint, at time.Time, source *ProcessedEvent) error
+	PostRecovery(recovery WriteOffRecovered, at time.Time, source *ProcessedEvent) error
+}
@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MerchantType represents the kind of merchant selling financed assets
+type MerchantType string
+
+const (
+	MerchantStore  MerchantType = "store"
+	MerchantDealer MerchantType = "dealer"
+)
+
+// MerchantStatus represents the status of a merchant
+type MerchantStatus string
+
+const (
+	MerchantActive   MerchantStatus = "active"
+	MerchantInactive MerchantStatus = "inactive"
+)
+
+// Merchant represents a store or dealer selling assets financed by us.
+// Merchants are paid the OTR price of their contracts net of commission.
+type Merchant struct {
+	ID                uint           `json:"id" gorm:"primaryKey"`
+	Code              string         `json:"code" gorm:"unique;not null"`
+	Name              string         `json:"name" gorm:"not null"`
+	Type              MerchantType   `json:"type" gorm:"not null"`
+	CommissionRate    float64        `json:"commission_rate" gorm:"not null"` // Fraction of the OTR price kept as commission, e.g. 0.025
+	BankName          string         `json:"bank_name" gorm:"not null"`
+	BankAccountNumber string         `json:"bank_account_number" gorm:"not null"`
+	BankAccountName   string         `json:"bank_account_name" gorm:"not null"`
+	Status            MerchantStatus `json:"status" gorm:"not null;default:'active'"`
+	Version           int            `json:"version" gorm:"not null;default:1"` // For optimistic locking
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// MerchantRepository represents the merchant repository contract
+type MerchantRepository interface {
+	Create(merchant *Merchant) error
+	GetByID(id uint) (*Merchant, error)
+	List(offset, limit int) ([]Merchant, error)
+	Update(merchant *Merchant) error
+	Delete(id uint) error
+	HasPendingSettlements(id uint) (bool, error)
+}
+
+// MerchantUseCase represents the merchant use case contract
+type MerchantUseCase interface {
+	Create(merchant *Merchant) error
+	GetByID(id uint) (*Merchant, error)
+	List(offset, limit int) ([]Merchant, error)
+	Update(merchant *Merchant) error
+	Delete(id uint) error
+}
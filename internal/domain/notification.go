@@ -0,0 +1,122 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// NotificationChannel is a medium notifications are delivered through
+type NotificationChannel string
+
+const (
+	ChannelEmail NotificationChannel = "email"
+	ChannelSMS   NotificationChannel = "sms"
+	ChannelPush  NotificationChannel = "push"
+)
+
+// NotificationChannels lists the channels in the order they are tried
+var NotificationChannels = []NotificationChannel{ChannelEmail, ChannelSMS, ChannelPush}
+
+// Locale selects the language of a notification
+type Locale string
+
+const (
+	LocaleID Locale = "id" // Bahasa Indonesia, the default
+	LocaleEN Locale = "en"
+)
+
+// NotificationKind identifies the template of a notification
+type NotificationKind string
+
+const (
+	NotificationInstallmentReminder NotificationKind = "installment_reminder"
+	NotificationPaymentReceipt      NotificationKind = "payment_receipt"
+)
+
+// NotificationStatus represents the outcome of sending a notification
+type NotificationStatus string
+
+const (
+	NotificationSent   NotificationStatus = "sent"
+	NotificationFailed NotificationStatus = "failed" // Retried on the next run or redelivery
+)
+
+// NotificationPreference holds a customer's contact details, language and
+// per channel opt-out. Customers without preferences are not notified.
+type NotificationPreference struct {
+	CustomerID   uint      `json:"customer_id" gorm:"primaryKey"`
+	Locale       Locale    `json:"locale" gorm:"not null;default:'id'"`
+	Email        string    `json:"email,omitempty"`
+	Phone        string    `json:"phone,omitempty"`
+	PushToken    string    `json:"push_token,omitempty"` // Device token registered by the mobile app
+	EmailEnabled bool      `json:"email_enabled" gorm:"not null;default:true"`
+	SMSEnabled   bool      `json:"sms_enabled" gorm:"column:sms_enabled;not null;default:true"`
+	PushEnabled  bool      `json:"push_enabled" gorm:"not null;default:true"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// Recipient returns the address notifications on the channel are sent to,
+// and false if the customer opted out of the channel or has no address for it
+func (p *NotificationPreference) Recipient(channel NotificationChannel) (string, bool) {
+	var address string
+	var enabled bool
+	switch channel {
+	case ChannelEmail:
+		address, enabled = p.Email, p.EmailEnabled
+	case ChannelSMS:
+		address, enabled = p.Phone, p.SMSEnabled
+	case ChannelPush:
+		address, enabled = p.PushToken, p.PushEnabled
+	}
+	return address, enabled && address != ""
+}
+
+// NotificationMessage is a rendered notification handed to a sender
+type NotificationMessage struct {
+	Channel   NotificationChannel
+	Recipient string
+	Subject   string // Email subject and push title, unused for SMS
+	Body      string
+}
+
+// Notification records a notification sent, or attempted, on one channel
+type Notification struct {
+	ID         uint                `json:"id" gorm:"primaryKey"`
+	CustomerID uint                `json:"customer_id" gorm:"not null"`
+	Kind       NotificationKind    `json:"kind" gorm:"not null"`
+	Channel    NotificationChannel `json:"channel" gorm:"not null"`
+	Reference  string              `json:"reference" gorm:"not null"` // Identifies the occasion, so it is notified once per channel
+	Recipient  string              `json:"recipient" gorm:"not null"`
+	Subject    string              `json:"subject,omitempty"`
+	Body       string              `json:"body" gorm:"not null"`
+	Status     NotificationStatus  `json:"status" gorm:"not null"`
+	Attempts   int                 `json:"attempts" gorm:"not null;default:0"`
+	LastError  string              `json:"last_error,omitempty"`
+	SentAt     *time.Time          `json:"sent_at,omitempty"`
+	CreatedAt  time.Time           `json:"created_at"`
+	UpdatedAt  time.Time           `json:"updated_at"`
+}
+
+// NotificationSender delivers messages on one channel
+type NotificationSender interface {
+	Send(ctx context.Context, message *NotificationMessage) error
+}
+
+// NotificationRepository represents the notification repository contract
+type NotificationRepository interface {
+	GetPreference(customerID uint) (*NotificationPreference, error)
+	SavePreference(preference *NotificationPreference) error
+	HasSent(reference string, channel NotificationChannel) (bool, error)
+	Record(notification *Notification) error
+	List(customerID uint, offset, limit int) ([]Notification, error)
+}
+
+// NotificationUseCase represents the notification use case contract
+type NotificationUseCase interface {
+	GetPreference(customerID uint) (*NotificationPreference, error)
+	UpdatePreference(preference *NotificationPreference) (*NotificationPreference, error)
+	ListNotifications(customerID uint, offset, limit int) ([]Notification, error)
+	SendReminders(ctx context.Context, now time.Time) (int, error)
+	SendReceipt(ctx context.Context, payment *InstallmentEvent) (int, error)
+}
@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"time"
+)
+
+// PartnerStatus represents the status of a partner
+type PartnerStatus string
+
+const (
+	PartnerActive    PartnerStatus = "active"
+	PartnerSuspended PartnerStatus = "suspended"
+)
+
+// APIKeyStatus represents the status of a partner API key
+type APIKeyStatus string
+
+const (
+	APIKeyActive  APIKeyStatus = "active"
+	APIKeyRevoked APIKeyStatus = "revoked"
+)
+
+// Partner represents an e-commerce, website or dealer partner allowed to
+// submit transactions through the API
+type Partner struct {
+	ID        uint              `json:"id" gorm:"primaryKey"`
+	Code      string            `json:"code" gorm:"unique;not null"`
+	Name      string            `json:"name" gorm:"not null"`
+	Source    TransactionSource `json:"source" gorm:"not null"` // Source assigned to transactions submitted by the partner
+	Status    PartnerStatus     `json:"status" gorm:"not null;default:'active'"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// PartnerAPIKey represents a credential used by a partner to sign requests.
+// The secret is stored encrypted and only returned once when issued.
+type PartnerAPIKey struct {
+	ID         uint         `json:"-" gorm:"primaryKey"`
+	PartnerID  uint         `json:"partner_id" gorm:"not null"`
+	KeyID      string       `json:"key_id" gorm:"unique;not null"`
+	Secret     string       `json:"-" gorm:"column:secret_encrypted;not null"`
+	Status     APIKeyStatus `json:"status" gorm:"not null;default:'active'"`
+	ExpiresAt  *time.Time   `json:"expires_at,omitempty"` // Set on keys replaced by a rotation
+	LastUsedAt *time.Time   `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time    `json:"created_at"`
+	UpdatedAt  time.Time    `json:"updated_at"`
+}
+
+// Usable reports whether the key may still authenticate requests
+func (k *PartnerAPIKey) Usable(now time.Time) bool {
+	return k.Status == APIKeyActive && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// IssuedAPIKey is a newly issued key together with its plaintext secret
+type IssuedAPIKey struct {
+	PartnerAPIKey
+	Secret string `json:"secret"`
+}
+
+// PartnerCredential is the verified identity behind a signed request
+type PartnerCredential struct {
+	Partner *Partner
+	Key     *PartnerAPIKey
+	Secret  []byte
+}
+
+// PartnerRepository represents the partner repository contract
+type PartnerRepository interface {
+	Create(partner *Partner) error
+	GetByID(id uint) (*Partner, error)
+	List(offset, limit int) ([]Partner, error)
+	UpdateStatus(id uint, status PartnerStatus) error
+	CreateKey(key *PartnerAPIKey) error
+	GetKeyByKeyID(keyID string) (*PartnerAPIKey, error)
+	ListKeys(partnerID uint) ([]PartnerAPIKey, error)
+	RotateKeys(key *PartnerAPIKey, expiresAt time.Time) error
+	RevokeKey(partnerID uint, keyID string) error
+	TouchKey(id uint, usedAt time.Time) error
+}
+
+// PartnerUseCase represents the partner use case contract
+type PartnerUseCase interface {
+	Register(partner *Partner) error
+	GetByID(id uint) (*Partner, error)
+	List(offset, limit int) ([]Partner, error)
+	UpdateStatus(id uint, status PartnerStatus) (*Partner, error)
+	IssueKey(partnerID uint) (*IssuedAPIKey, error)
+	RotateKey(partnerID uint) (*IssuedAPIKey, error)
+	RevokeKey(partnerID uint, keyID string) error
+	ListKeys(partnerID uint) ([]PartnerAPIKey, error)
+	Authenticate(keyID string) (*PartnerCredential, error)
+}
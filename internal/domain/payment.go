@@ -0,0 +1,151 @@
+package domain
+
+import (
+	"io"
+	"time"
+)
+
+// VirtualAccountStatus represents the status of a virtual account
+type VirtualAccountStatus string
+
+const (
+	VirtualAccountActive VirtualAccountStatus = "active"
+	VirtualAccountClosed VirtualAccountStatus = "closed" // Contract paid off or ended, payments are refused
+)
+
+// VirtualAccount is a bank account number assigned to one contract, through
+// which the customer pays installments
+type VirtualAccount struct {
+	ID            uint                 `json:"id" gorm:"primaryKey"`
+	TransactionID uint                 `json:"transaction_id" gorm:"not null"`
+	BankCode      string               `json:"bank_code" gorm:"not null"`
+	Number        string               `json:"number" gorm:"unique;not null"`
+	Status        VirtualAccountStatus `json:"status" gorm:"not null;default:'active'"`
+	CreatedAt     time.Time            `json:"created_at"`
+	UpdatedAt     time.Time            `json:"updated_at"`
+
+	// Relations
+	Transaction *Transaction `json:"-" gorm:"foreignKey:TransactionID"`
+}
+
+// PaymentStatus represents the status of a payment received from a bank
+type PaymentStatus string
+
+const (
+	PaymentReceived PaymentStatus = "received" // Recorded, installment not yet credited
+	PaymentCredited PaymentStatus = "credited"
+	PaymentRejected PaymentStatus = "rejected" // Refused, to be refunded by the bank
+)
+
+// Payment is a payment into a virtual account reported by a bank callback
+type Payment struct {
+	ID               uint          `json:"id" gorm:"primaryKey"`
+	VirtualAccountID uint          `json:"virtual_account_id" gorm:"not null"`
+	TransactionID    uint          `json:"transaction_id" gorm:"not null"`
+	InstallmentID    *uint         `json:"installment_id,omitempty"` // Installment credited
+	BankCode         string        `json:"bank_code" gorm:"not null"`
+	Reference        string        `json:"reference" gorm:"not null"` // Bank transaction reference, unique per bank
+	Amount           float64       `json:"amount" gorm:"not null"`
+	PaidAt           time.Time     `json:"paid_at" gorm:"not null"`
+	Status           PaymentStatus `json:"status" gorm:"not null"`
+	RejectReason     string        `json:"reject_reason,omitempty"`
+	CreatedAt        time.Time     `json:"created_at"`
+	UpdatedAt        time.Time     `json:"updated_at"`
+}
+
+// VACallback is a payment notification sent by a bank
+type VACallback struct {
+	Number    string
+	Reference string
+	Amount    float64
+	PaidAt    time.Time
+}
+
+// StatementFormat is the file format of a bank statement
+type StatementFormat string
+
+const (
+	StatementCSV   StatementFormat = "csv"
+	StatementMT940 StatementFormat = "mt940"
+)
+
+// StatementEntry is a credit to a virtual account listed in a bank statement
+type StatementEntry struct {
+	Date      time.Time
+	Reference string
+	Number    string // Virtual account number
+	Amount    float64
+}
+
+// MismatchKind describes why a statement entry or payment did not reconcile
+type MismatchKind string
+
+const (
+	MismatchMissingPayment   MismatchKind = "missing_payment"   // In the statement, not recorded
+	MismatchMissingStatement MismatchKind = "missing_statement" // Recorded, not in the statement
+	MismatchAmount           MismatchKind = "amount_mismatch"
+)
+
+// ReconciliationStatus represents the outcome of a reconciliation
+type ReconciliationStatus string
+
+const (
+	ReconciliationMatched    ReconciliationStatus = "matched"
+	ReconciliationMismatched ReconciliationStatus = "mismatched"
+)
+
+// Reconciliation is the result of matching one bank statement against the
+// payments recorded for the bank on the statement date
+type Reconciliation struct {
+	ID            uint                 `json:"id" gorm:"primaryKey"`
+	BankCode      string               `json:"bank_code" gorm:"not null"`
+	StatementDate time.Time            `json:"statement_date" gorm:"type:date;not null"`
+	FileName      string               `json:"file_name" gorm:"not null"`
+	Format        StatementFormat      `json:"format" gorm:"not null"`
+	Entries       int                  `json:"entries" gorm:"not null"` // Credits listed in the statement
+	Matched       int                  `json:"matched" gorm:"not null"`
+	Status        ReconciliationStatus `json:"status" gorm:"not null"`
+	CreatedAt     time.Time            `json:"created_at"`
+
+	// Relations
+	Mismatches []ReconciliationMismatch `json:"mismatches,omitempty" gorm:"foreignKey:ReconciliationID"`
+}
+
+// ReconciliationMismatch is a statement entry or payment that did not match
+type ReconciliationMismatch struct {
+	ID               uint         `json:"id" gorm:"primaryKey"`
+	ReconciliationID uint         `json:"-" gorm:"not null"`
+	Kind             MismatchKind `json:"kind" gorm:"not null"`
+	Reference        string       `json:"reference" gorm:"not null"`
+	Number           string       `json:"number,omitempty"`
+	StatementAmount  *float64     `json:"statement_amount,omitempty"`
+	RecordedAmount   *float64     `json:"recorded_amount,omitempty"`
+	PaymentID        *uint        `json:"payment_id,omitempty"`
+}
+
+// PaymentRepository represents the payment repository contract
+type PaymentRepository interface {
+	CreateVirtualAccount(account *VirtualAccount) error
+	GetVirtualAccountByNumber(number string) (*VirtualAccount, error)
+	ListVirtualAccounts(transactionID uint) ([]VirtualAccount, error)
+	CreatePayment(payment *Payment) error
+	GetPayment(id uint) (*Payment, error)
+	GetPaymentByReference(bankCode, reference string) (*Payment, error)
+	UpdatePayment(payment *Payment) error
+	ListPayments(bankCode string, from, to time.Time) ([]Payment, error)
+	CreateReconciliation(reconciliation *Reconciliation) error
+	GetReconciliation(id uint) (*Reconciliation, error)
+	FindReconciliation(bankCode string, statementDate time.Time) (*Reconciliation, error)
+	ListReconciliations(bankCode string, offset, limit int) ([]Reconciliation, error)
+}
+
+// PaymentUseCase represents the payment use case contract
+type PaymentUseCase interface {
+	CreateVirtualAccount(transactionID uint, bankCode string) (*VirtualAccount, error)
+	ListVirtualAccounts(transactionID uint) ([]VirtualAccount, error)
+	HandleCallback(bankCode string, callback *VACallback) (*Payment, error)
+	Reconcile(bankCode string, statementDate time.Time, format StatementFormat, fileName string, statement io.Reader) (*Reconciliation, error)
+	ReconcileDaily(now time.Time) (int, error)
+	GetReconciliation(id uint) (*Reconciliation, error)
+	ListReconciliations(bankCode string, offset, limit int) ([]Reconciliation, error)
+}
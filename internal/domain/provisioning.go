@@ -0,0 +1,182 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// Collectibility is the OJK quality grade of a financing contract, from 1
+// (lancar) to 5 (macet)
+type Collectibility int
+
+const (
+	CollectibilityCurrent        Collectibility = 1 // Lancar
+	CollectibilitySpecialMention Collectibility = 2 // Dalam perhatian khusus
+	CollectibilitySubstandard    Collectibility = 3 // Kurang lancar
+	CollectibilityDoubtful       Collectibility = 4 // Diragukan
+	CollectibilityLoss           Collectibility = 5 // Macet
+)
+
+// Collectibilities lists the grades from the best to the worst
+var Collectibilities = []Collectibility{
+	CollectibilityCurrent,
+	CollectibilitySpecialMention,
+	CollectibilitySubstandard,
+	CollectibilityDoubtful,
+	CollectibilityLoss,
+}
+
+// Name returns the OJK name of the grade, as used in reports and
+// configuration
+func (c Collectibility) Name() string {
+	switch c {
+	case CollectibilityCurrent:
+		return "lancar"
+	case CollectibilitySpecialMention:
+		return "dalam_perhatian_khusus"
+	case CollectibilitySubstandard:
+		return "kurang_lancar"
+	case CollectibilityDoubtful:
+		return "diragukan"
+	case CollectibilityLoss:
+		return "macet"
+	default:
+		return ""
+	}
+}
+
+// NonPerforming reports whether the grade counts as a non-performing loan,
+// kurang lancar or worse
+func (c Collectibility) NonPerforming() bool {
+	return c >= CollectibilitySubstandard
+}
+
+// GradeRule classifies contracts up to MaxDPD days past due into a grade,
+// provisioned at ProvisionRate of their outstanding balance
+type GradeRule struct {
+	Collectibility Collectibility
+	MaxDPD         int // Ignored for the last grade, which has no bound
+	ProvisionRate  float64
+}
+
+// ProvisioningPolicy classifies contracts into collectibility grades by the
+// days past due of their oldest overdue installment
+type ProvisioningPolicy struct {
+	Grades []GradeRule // Every grade, from the best to the worst
+}
+
+// Validate checks the policy defines every grade in order, with increasing
+// day bounds and provision rates between 0 and 1 that never decrease
+func (p ProvisioningPolicy) Validate() error {
+	if len(p.Grades) != len(Collectibilities) {
+		return fmt.Errorf("provisioning needs %d grades, got %d", len(Collectibilities), len(p.Grades))
+	}
+	for i, grade := range p.Grades {
+		if grade.Collectibility != Collectibilities[i] {
+			return fmt.Errorf("grade %d must be %s", i+1, Collectibilities[i].Name())
+		}
+		if grade.ProvisionRate < 0 || grade.ProvisionRate > 1 {
+			return fmt.Errorf("grade %s: provision rate must be between 0 and 1", grade.Collectibility.Name())
+		}
+		if i == 0 {
+			if grade.MaxDPD < 0 {
+				return fmt.Errorf("grade %s: max dpd must not be negative", grade.Collectibility.Name())
+			}
+			continue
+		}
+		previous := p.Grades[i-1]
+		if i < len(p.Grades)-1 && grade.MaxDPD <= previous.MaxDPD {
+			return fmt.Errorf("grade %s: max dpd must exceed %d", grade.Collectibility.Name(), previous.MaxDPD)
+		}
+		if grade.ProvisionRate < previous.ProvisionRate {
+			return fmt.Errorf("grade %s: provision rate must not be lower than %s", grade.Collectibility.Name(), previous.Collectibility.Name())
+		}
+	}
+	return nil
+}
+
+// Classify returns the grade of a contract dpd days past due
+func (p ProvisioningPolicy) Classify(dpd int) GradeRule {
+	for _, grade := range p.Grades[:len(p.Grades)-1] {
+		if dpd <= grade.MaxDPD {
+			return grade
+		}
+	}
+	return p.Grades[len(p.Grades)-1]
+}
+
+// Exposure is the outstanding balance of an approved contract. Balances are
+// read when classifying, so reports of a month are generated shortly after
+// it ends.
+type Exposure struct {
+	TransactionID  uint
+	ContractNumber string
+	CustomerID     uint
+	Outstanding    float64    // Installments still to be paid, late fees excluded
+	OldestOverdue  *time.Time // Due date of the oldest outstanding installment due before the date, nil when none
+}
+
+// Classification is the collectibility grade and provision of a contract
+type Classification struct {
+	TransactionID  uint           `json:"transaction_id"`
+	ContractNumber string         `json:"contract_number"`
+	DPD            int            `json:"dpd"`
+	Collectibility Collectibility `json:"collectibility"`
+	Grade          string         `json:"grade"`
+	Outstanding    float64        `json:"outstanding"`
+	ProvisionRate  float64        `json:"provision_rate"`
+	Provision      float64        `json:"provision"`
+	AsOf           time.Time      `json:"as_of"`
+}
+
+// GradeProvision totals the contracts of one grade
+type GradeProvision struct {
+	Collectibility Collectibility `json:"collectibility"`
+	Grade          string         `json:"grade"`
+	Contracts      int            `json:"contracts"`
+	Outstanding    float64        `json:"outstanding"`
+	ProvisionRate  float64        `json:"provision_rate"`
+	Provision      float64        `json:"provision"`
+}
+
+// ProvisionReport is the loan-loss provisioning of the portfolio at the end
+// of a month, with the write-offs and recoveries of the month
+type ProvisionReport struct {
+	ID               uint             `json:"id" gorm:"primaryKey"`
+	Period           string           `json:"period" gorm:"unique;not null"` // YYYY-MM
+	AsOf             time.Time        `json:"as_of" gorm:"not null"`         // Start of the following month
+	Grades           []GradeProvision `json:"grades" gorm:"type:jsonb;serializer:json;not null"`
+	Contracts        int              `json:"contracts" gorm:"not null"`
+	Outstanding      float64          `json:"outstanding" gorm:"not null"`
+	Provision        float64          `json:"provision" gorm:"not null"`
+	WrittenOffCount  int              `json:"written_off_count" gorm:"not null"`
+	WrittenOffAmount float64          `json:"written_off_amount" gorm:"not null"`
+	RecoveredAmount  float64          `json:"recovered_amount" gorm:"not null"`
+	CreatedAt        time.Time        `json:"created_at"`
+}
+
+// ProvisioningRepository represents the provisioning repository contract
+type ProvisioningRepository interface {
+	// ListExposures lists the approved contracts with an outstanding
+	// balance as of asOf by transaction ID, after afterTransactionID
+	ListExposures(asOf time.Time, afterTransactionID uint, limit int) ([]Exposure, error)
+	// WriteOffTotals sums the write-offs and the recoveries within [from, to)
+	WriteOffTotals(from, to time.Time) (count int, amount, recovered float64, err error)
+	CreateReport(report *ProvisionReport) error
+	GetReport(period string) (*ProvisionReport, error)
+	ListReports(offset, limit int) ([]ProvisionReport, error)
+}
+
+// ProvisioningUseCase represents the provisioning use case contract
+type ProvisioningUseCase interface {
+	// Classify returns the current grade and provision of a contract
+	Classify(transactionID uint) (*Classification, error)
+	// Portfolio totals the current grades and provisions of the portfolio,
+	// without recording a report
+	Portfolio(now time.Time) (*ProvisionReport, error)
+	// GenerateMonthly records the report of the month before now unless
+	// recorded already, returning nil then
+	GenerateMonthly(now time.Time) (*ProvisionReport, error)
+	GetReport(period string) (*ProvisionReport, error)
+	ListReports(offset, limit int) ([]ProvisionReport, error)
+}
@@ -0,0 +1,213 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+	"unicode"
+)
+
+// ReportSegment is a segment of the SLIK reporting to OJK, reported as one
+// file per period
+type ReportSegment string
+
+const (
+	SegmentDebtor   ReportSegment = "D01" // Debitur perorangan
+	SegmentFacility ReportSegment = "F01" // Fasilitas pembiayaan
+)
+
+// ReportFormat is the encoding of a report file
+type ReportFormat string
+
+const (
+	ReportFixedWidth ReportFormat = "fixed_width"
+	ReportCSV        ReportFormat = "csv"
+	ReportXML        ReportFormat = "xml"
+)
+
+// ReportStatus represents the status of a report file
+type ReportStatus string
+
+const (
+	ReportGenerated ReportStatus = "generated"
+	ReportSubmitted ReportStatus = "submitted" // Uploaded to SLIK, one file per period and segment
+)
+
+// FacilityCondition is the SLIK condition code of a facility
+type FacilityCondition string
+
+const (
+	FacilityActive     FacilityCondition = "00" // Fasilitas aktif
+	FacilityPaidOff    FacilityCondition = "02" // Lunas
+	FacilityWrittenOff FacilityCondition = "05" // Hapus buku
+)
+
+// DebtorRecord is a customer reported in the debtor segment
+type DebtorRecord struct {
+	CIF          string    `json:"cif"` // Customer reference the facilities are reported against
+	NIK          string    `json:"nik"`
+	FullName     string    `json:"full_name"`
+	LegalName    string    `json:"legal_name"` // Name on the identity card
+	PlaceOfBirth string    `json:"place_of_birth"`
+	DateOfBirth  time.Time `json:"date_of_birth"`
+	Phone        string    `json:"phone,omitempty"`
+	Region       string    `json:"region,omitempty"`
+}
+
+// FacilityRecord is a contract reported in the facility segment, as of the
+// end of the period
+type FacilityRecord struct {
+	AccountNumber     string            `json:"account_number"` // Contract number
+	CIF               string            `json:"cif"`
+	StartDate         time.Time         `json:"start_date"`
+	MaturityDate      time.Time         `json:"maturity_date"`
+	Plafond           float64           `json:"plafond"`     // Financed amount
+	Outstanding       float64           `json:"outstanding"` // Principal of the outstanding installments
+	ArrearsPrincipal  float64           `json:"arrears_principal"`
+	ArrearsInterest   float64           `json:"arrears_interest"`
+	DPD               int               `json:"dpd"`
+	Collectibility    Collectibility    `json:"collectibility"`
+	Restructured      bool              `json:"restructured"`
+	Condition         FacilityCondition `json:"condition"`
+	ConditionDate     *time.Time        `json:"condition_date,omitempty"` // When the facility was paid off or written off
+	WrittenOffBalance float64           `json:"written_off_balance"`      // Written off and not recovered yet
+}
+
+// ReportIssue is a mandatory field missing or malformed in a record
+type ReportIssue struct {
+	Segment   ReportSegment `json:"segment"`
+	Reference string        `json:"reference"` // CIF or account number of the record
+	Field     string        `json:"field"`
+	Message   string        `json:"message"`
+}
+
+func (i ReportIssue) String() string {
+	return fmt.Sprintf("%s %s: %s %s", i.Segment, i.Reference, i.Field, i.Message)
+}
+
+// Validate checks the mandatory fields of the record
+func (r DebtorRecord) Validate() []ReportIssue {
+	var issues []ReportIssue
+	issue := func(field, message string) {
+		issues = append(issues, ReportIssue{Segment: SegmentDebtor, Reference: r.CIF, Field: field, Message: message})
+	}
+
+	if r.CIF == "" {
+		issue("cif", "is required")
+	}
+	if len(r.NIK) != 16 || !digits(r.NIK) {
+		issue("nik", "must be 16 digits")
+	}
+	if r.LegalName == "" {
+		issue("legal_name", "is required")
+	}
+	if r.PlaceOfBirth == "" {
+		issue("place_of_birth", "is required")
+	}
+	if r.DateOfBirth.IsZero() {
+		issue("date_of_birth", "is required")
+	}
+	return issues
+}
+
+// Validate checks the mandatory fields of the record
+func (r FacilityRecord) Validate() []ReportIssue {
+	var issues []ReportIssue
+	issue := func(field, message string) {
+		issues = append(issues, ReportIssue{Segment: SegmentFacility, Reference: r.AccountNumber, Field: field, Message: message})
+	}
+
+	if r.AccountNumber == "" {
+		issue("account_number", "is required")
+	}
+	if r.CIF == "" {
+		issue("cif", "is required")
+	}
+	if r.StartDate.IsZero() {
+		issue("start_date", "is required")
+	}
+	if r.MaturityDate.IsZero() {
+		issue("maturity_date", "is required")
+	} else if r.MaturityDate.Before(r.StartDate) {
+		issue("maturity_date", "must not be before the start date")
+	}
+	if r.Plafond <= 0 {
+		issue("plafond", "must be positive")
+	}
+	if r.Collectibility < CollectibilityCurrent || r.Collectibility > CollectibilityLoss {
+		issue("collectibility", "must be between 1 and 5")
+	}
+	if r.Condition != FacilityActive && r.ConditionDate == nil {
+		issue("condition_date", "is required for closed facilities")
+	}
+	return issues
+}
+
+func digits(s string) bool {
+	for _, r := range s {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// RegulatoryReport is a SLIK report file generated for a period. Files are
+// kept with their checksum so the one submitted can be proven afterwards.
+type RegulatoryReport struct {
+	ID                  uint          `json:"id" gorm:"primaryKey"`
+	Period              string        `json:"period" gorm:"not null"` // YYYY-MM
+	Segment             ReportSegment `json:"segment" gorm:"not null"`
+	Format              ReportFormat  `json:"format" gorm:"not null"`
+	FileName            string        `json:"file_name" gorm:"not null"`
+	Records             int           `json:"records" gorm:"not null"`
+	Checksum            string        `json:"checksum" gorm:"not null"` // SHA-256 of the content, hex encoded
+	Content             []byte        `json:"-" gorm:"not null"`
+	Status              ReportStatus  `json:"status" gorm:"not null;default:'generated'"`
+	GeneratedBy         uint          `json:"generated_by" gorm:"not null"`
+	SubmittedBy         *uint         `json:"submitted_by,omitempty"`
+	SubmittedAt         *time.Time    `json:"submitted_at,omitempty"`
+	SubmissionReference string        `json:"submission_reference,omitempty"` // Receipt of the upload to SLIK
+	CreatedAt           time.Time     `json:"created_at"`
+}
+
+// RegulatoryReportFilter narrows the listing of report files, zero values
+// match any
+type RegulatoryReportFilter struct {
+	Period  string
+	Segment ReportSegment
+	Status  ReportStatus
+}
+
+// RegulatoryReportRepository represents the regulatory report repository
+// contract
+type RegulatoryReportRepository interface {
+	// ListReportable lists the approved and written off contracts created
+	// before to and not closed before from, by transaction ID after
+	// afterTransactionID, with their customer and installments
+	ListReportable(from, to time.Time, afterTransactionID uint, limit int) ([]Transaction, error)
+	// ListWriteOffs returns the write-offs of the given contracts by
+	// transaction ID
+	ListWriteOffs(transactionIDs []uint) (map[uint]WriteOff, error)
+	Create(report *RegulatoryReport, audit *AuditLog) error
+	GetByID(id uint) (*RegulatoryReport, error)
+	// List lists the report files without their content, newest first
+	List(filter RegulatoryReportFilter, offset, limit int) ([]RegulatoryReport, error)
+	// Submit marks a generated report submitted, unless another file of its
+	// period and segment was submitted already
+	Submit(report *RegulatoryReport, audit *AuditLog) error
+}
+
+// RegulatoryReportUseCase represents the regulatory report use case contract
+type RegulatoryReportUseCase interface {
+	// Validate builds the records of a segment for a period and returns the
+	// mandatory field issues, without generating a file
+	Validate(period string, segment ReportSegment) ([]ReportIssue, error)
+	// Generate builds and records the file of a segment for a period,
+	// rejecting records with mandatory field issues
+	Generate(period string, segment ReportSegment, format ReportFormat, actor Actor) (*RegulatoryReport, error)
+	GetByID(id uint) (*RegulatoryReport, error)
+	List(filter RegulatoryReportFilter, offset, limit int) ([]RegulatoryReport, error)
+	// Submit records the upload of a file to SLIK. A checksum, when given,
+	// must match the file's.
+	Submit(id uint, reference, checksum string, actor Actor) (*RegulatoryReport, error)
+}
@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"time"
+)
+
+// RestructuringType represents the relief granted to a customer in hardship
+type RestructuringType string
+
+const (
+	RestructuringTenorExtension RestructuringType = "tenor_extension" // Outstanding balance spread over more installments
+	RestructuringPaymentHoliday RestructuringType = "payment_holiday" // Installments deferred by a number of months
+)
+
+// RestructuringStatus represents the status of a restructuring
+type RestructuringStatus string
+
+const (
+	RestructuringPending  RestructuringStatus = "pending"
+	RestructuringApproved RestructuringStatus = "approved"
+	RestructuringRejected RestructuringStatus = "rejected"
+)
+
+// Restructuring replaces the outstanding installments of a contract with a
+// new schedule once approved. The superseded installments are kept with
+// status superseded.
+type Restructuring struct {
+	ID                uint                `json:"id" gorm:"primaryKey"`
+	TransactionID     uint                `json:"transaction_id" gorm:"not null"`
+	Type              RestructuringType   `json:"type" gorm:"not null"`
+	Status            RestructuringStatus `json:"status" gorm:"not null;default:'pending'"`
+	Reason            string              `json:"reason" gorm:"not null"`
+	OutstandingAmount float64             `json:"outstanding_amount" gorm:"not null"` // Outstanding installments and late fees superseded
+	InterestRate      float64             `json:"interest_rate" gorm:"not null"`      // Flat monthly rate on the outstanding amount
+	NewTenor          int                 `json:"new_tenor" gorm:"not null"`          // Installments of the new schedule
+	HolidayMonths     int                 `json:"holiday_months" gorm:"not null"`     // Months deferred before the first new installment
+	InstallmentAmount float64             `json:"installment_amount" gorm:"not null"`
+	FirstDueDate      time.Time           `json:"first_due_date" gorm:"not null"`
+	TotalAmount       float64             `json:"total_amount" gorm:"not null"` // Sum of the new installments
+	RequestedBy       uint                `json:"requested_by" gorm:"not null"`
+	ReviewedBy        *uint               `json:"reviewed_by,omitempty"`
+	ReviewNote        string              `json:"review_note,omitempty"`
+	ReviewedAt        *time.Time          `json:"reviewed_at,omitempty"`
+	CreatedAt         time.Time           `json:"created_at"`
+	UpdatedAt         time.Time           `json:"updated_at"`
+
+	// Schedule holds the new installments, created when approved
+	Schedule []Installment `json:"schedule,omitempty" gorm:"foreignKey:RestructuringID"`
+	// Superseded holds the outstanding installments replaced by Schedule
+	Superseded []Installment `json:"-" gorm:"-"`
+	// Events is written to the outbox together with the approval
+	Events []OutboxEvent `json:"-" gorm:"-"`
+}
+
+// AddedAmount is the amount the restructuring adds to the financed amount
+func (r *Restructuring) AddedAmount() float64 {
+	return r.TotalAmount - r.OutstandingAmount
+}
+
+// ContractRestructured is the data of contract.restructured events
+type ContractRestructured struct {
+	TransactionID     uint              `json:"transaction_id"`
+	ContractNumber    string            `json:"contract_number"`
+	Status            TransactionStatus `json:"status"`
+	RestructuringID   uint              `json:"restructuring_id"`
+	Type              RestructuringType `json:"type"`
+	NewTenor          int               `json:"new_tenor"`
+	InstallmentAmount float64           `json:"installment_amount"`
+	FirstDueDate      time.Time         `json:"first_due_date"`
+	AddedAmount       float64           `json:"added_amount"`
+}
+
+// RestructuringRequest is an operator's request to restructure a contract
+type RestructuringRequest struct {
+	TransactionID uint
+	Type          RestructuringType
+	Reason        string
+	InterestRate  float64
+	NewTenor      int // Defaults to the number of outstanding installments
+	HolidayMonths int
+	Actor         Actor
+}
+
+// RestructuringPolicy bounds the relief operators may request
+type RestructuringPolicy struct {
+	MaxTenor         int
+	MaxHolidayMonths int
+	MaxInterestRate  float64
+}
+
+// RestructuringRepository represents the restructuring repository contract
+type RestructuringRepository interface {
+	Create(restructuring *Restructuring, audit *AuditLog) error
+	GetByID(id uint) (*Restructuring, error)
+	ListByTransaction(transactionID uint) ([]Restructuring, error)
+	// Approve supersedes the outstanding installments, creates the new
+	// schedule and records the approval in one database transaction
+	Approve(restructuring *Restructuring, audit *AuditLog) error
+	Reject(restructuring *Restructuring, audit *AuditLog) error
+}
+
+// RestructuringUseCase represents the restructuring use case contract
+type RestructuringUseCase interface {
+	Request(request *RestructuringRequest) (*Restructuring, error)
+	GetByID(id uint) (*Restructuring, error)
+	ListByTransaction(transactionID uint) ([]Restructuring, error)
+	Approve(id uint, note string, actor Actor) (*Restructuring, error)
+	Reject(id uint, note string, actor Actor) (*Restructuring, error)
+}
@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"time"
+)
+
+// ReversalReason explains why a payment was reversed
+type ReversalReason string
+
+const (
+	ReversalBounced  ReversalReason = "bounced"  // The funds never arrived or were returned
+	ReversalMistaken ReversalReason = "mistaken" // Credited to the wrong installment or contract
+)
+
+// PaymentReversal records the reversal of an installment payment
+type PaymentReversal struct {
+	ID             uint           `json:"id" gorm:"primaryKey"`
+	InstallmentID  uint           `json:"installment_id" gorm:"not null"`
+	TransactionID  uint           `json:"transaction_id" gorm:"not null"`
+	Reason         ReversalReason `json:"reason" gorm:"not null"`
+	Note           string         `json:"note,omitempty"`
+	Amount         float64        `json:"amount" gorm:"not null"` // Installment amount no longer paid
+	PreviousPaidAt *time.Time     `json:"previous_paid_at,omitempty"`
+	RestoredStatus string         `json:"restored_status" gorm:"not null"` // unpaid or overdue
+	LateFee        float64        `json:"late_fee" gorm:"not null"`        // Late fee applied again
+	OperatorID     uint           `json:"operator_id" gorm:"not null"`
+	CreatedAt      time.Time      `json:"created_at"`
+
+	// Relations
+	Refund *Refund `json:"refund,omitempty" gorm:"foreignKey:ReversalID"`
+}
+
+// RefundStatus represents the status of a refund
+type RefundStatus string
+
+const (
+	RefundPending   RefundStatus = "pending" // Recorded, money not yet returned
+	RefundCompleted RefundStatus = "completed"
+)
+
+// Refund is money to be returned to a customer, for an overpayment or a
+// mistaken payment
+type Refund struct {
+	ID            uint         `json:"id" gorm:"primaryKey"`
+	TransactionID uint         `json:"transaction_id" gorm:"not null"`
+	PaymentID     *uint        `json:"payment_id,omitempty"`  // Virtual account payment refunded
+	ReversalID    *uint        `json:"reversal_id,omitempty"` // Reversal the refund was recorded with
+	Amount        float64      `json:"amount" gorm:"not null"`
+	Reason        string       `json:"reason" gorm:"not null"`
+	Status        RefundStatus `json:"status" gorm:"not null;default:'pending'"`
+	Reference     string       `json:"reference,omitempty"` // Bank transfer reference, once completed
+	OperatorID    uint         `json:"operator_id" gorm:"not null"`
+	CompletedAt   *time.Time   `json:"completed_at,omitempty"`
+	CreatedAt     time.Time    `json:"created_at"`
+	UpdatedAt     time.Time    `json:"updated_at"`
+}
+
+// ReverseInstallmentRequest is an operator's request to reverse the payment
+// of an installment
+type ReverseInstallmentRequest struct {
+	InstallmentID uint
+	Version       int // Installment version read by the operator
+	Reason        ReversalReason
+	Note          string
+	RefundAmount  float64 // Part of the payment to return to the customer, if any
+	Actor         Actor
+}
+
+// ReversalRepository represents the reversal and refund repository contract
+type ReversalRepository interface {
+	// ReverseInstallment writes the reversed installment, the reversal, its
+	// refund when set and the audit log in one database transaction
+	ReverseInstallment(installment *Installment, reversal *PaymentReversal, audit *AuditLog) error
+	ListReversals(transactionID uint, offset, limit int) ([]PaymentReversal, error)
+	CreateRefund(refund *Refund, audit *AuditLog) error
+	GetRefund(id uint) (*Refund, error)
+	CompleteRefund(refund *Refund, audit *AuditLog) error
+	ListRefunds(transactionID uint, offset, limit int) ([]Refund, error)
+	SumRefunds(paymentID uint) (float64, error)
+}
+
+// ReversalUseCase represents the reversal and refund use case contract
+type ReversalUseCase interface {
+	ReverseInstallment(request *ReverseInstallmentRequest) (*PaymentReversal, error)
+	ListReversals(transactionID uint, offset, limit int) ([]PaymentReversal, error)
+	CreateRefund(refund *Refund, actor Actor) (*Refund, error)
+	CompleteRefund(id uint, reference string, actor Actor) (*Refund, error)
+	ListRefunds(transactionID uint, offset, limit int) ([]Refund, error)
+}
@@ -0,0 +1,132 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// RiskFlag is the outcome of fraud screening
+type RiskFlag string
+
+const (
+	RiskClear RiskFlag = "clear"
+	RiskHold  RiskFlag = "hold"  // Held for review by an operator
+	RiskBlock RiskFlag = "block" // Refused, never recorded on a customer or contract
+)
+
+// Severity orders flags, the most severe hit flagging the subject
+func (f RiskFlag) Severity() int {
+	switch f {
+	case RiskBlock:
+		return 2
+	case RiskHold:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Screening checks
+const (
+	CheckBlacklist         = "blacklist"
+	CheckVelocity          = "velocity"
+	CheckDuplicateIdentity = "duplicate_identity"
+	CheckCustomerFlag      = "customer_flag"
+)
+
+// ScreeningHit is a screening check that flagged a customer or contract
+type ScreeningHit struct {
+	Check  string   `json:"check"`
+	Action RiskFlag `json:"action"`
+	Reason string   `json:"reason"` // Machine readable
+	Detail string   `json:"detail,omitempty"`
+}
+
+// ScreeningResult is the outcome of screening a registration or a contract
+type ScreeningResult struct {
+	Flag RiskFlag
+	Hits []ScreeningHit
+}
+
+// Add records hit, raising the flag to the hit's action
+func (r *ScreeningResult) Add(hit ScreeningHit) {
+	r.Hits = append(r.Hits, hit)
+	if hit.Action.Severity() > r.Flag.Severity() {
+		r.Flag = hit.Action
+	}
+}
+
+// Reasons returns the reasons of the hits with the given action
+func (r *ScreeningResult) Reasons(action RiskFlag) []string {
+	var reasons []string
+	for _, hit := range r.Hits {
+		if hit.Action == action {
+			reasons = append(reasons, hit.Reason)
+		}
+	}
+	return reasons
+}
+
+// VelocityKey is what a velocity rule counts contracts by
+type VelocityKey string
+
+const (
+	VelocityCustomer VelocityKey = "customer" // The customer's contracts, across sources
+	VelocityDevice   VelocityKey = "device"   // Contracts submitted from the device, across customers
+)
+
+// VelocityRule flags a contract when more than MaxContracts contracts, the
+// new one included, were submitted within Window
+type VelocityRule struct {
+	Name         string
+	Key          VelocityKey
+	Window       time.Duration
+	MaxContracts int
+	Action       RiskFlag
+}
+
+// ScreeningPolicy configures fraud screening
+type ScreeningPolicy struct {
+	DuplicateIdentity RiskFlag // Action on registrations matching another customer's identity
+	Velocity          []VelocityRule
+}
+
+// Validate checks that the policy only holds or blocks, and that every
+// velocity rule counts by a known key within a window
+func (p ScreeningPolicy) Validate() error {
+	if p.DuplicateIdentity != "" && p.DuplicateIdentity != RiskHold && p.DuplicateIdentity != RiskBlock {
+		return fmt.Errorf("duplicate identity action must be hold or block, got %q", p.DuplicateIdentity)
+	}
+	for _, rule := range p.Velocity {
+		if rule.Key != VelocityCustomer && rule.Key != VelocityDevice {
+			return fmt.Errorf("velocity rule %s: key must be customer or device, got %q", rule.Name, rule.Key)
+		}
+		if rule.Window <= 0 || rule.MaxContracts <= 0 {
+			return fmt.Errorf("velocity rule %s: window and max contracts must be positive", rule.Name)
+		}
+		if rule.Action != RiskHold && rule.Action != RiskBlock {
+			return fmt.Errorf("velocity rule %s: action must be hold or block, got %q", rule.Name, rule.Action)
+		}
+	}
+	return nil
+}
+
+// ScreeningRepository represents the screening repository contract
+type ScreeningRepository interface {
+	// CountContracts counts the contracts created since since, whatever their
+	// status, by the customer or device value identifies
+	CountContracts(key VelocityKey, value string, since time.Time) (int, error)
+	// FindDuplicateIdentities returns the customers with another NIK sharing
+	// the full name, date and place of birth of customer
+	FindDuplicateIdentities(customer *Customer) ([]Customer, error)
+}
+
+// ScreeningUseCase represents the fraud screening use case contract
+type ScreeningUseCase interface {
+	// ScreenCustomer screens a registration against the blacklist and the
+	// identities of other customers
+	ScreenCustomer(customer *Customer) (*ScreeningResult, error)
+	// ScreenTransaction screens a new contract against the blacklist, the
+	// velocity rules and the flag of its customer
+	ScreenTransaction(tx *Transaction) (*ScreeningResult, error)
+}
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"time"
+)
+
+// SettlementStatus represents the status of a merchant payout
+type SettlementStatus string
+
+const (
+	SettlementPending SettlementStatus = "pending"
+	SettlementPaid    SettlementStatus = "paid"
+)
+
+// Settlement represents a daily payout to a merchant for its approved contracts
+type Settlement struct {
+	ID               uint             `json:"id" gorm:"primaryKey"`
+	MerchantID       uint             `json:"merchant_id" gorm:"not null"`
+	SettlementDate   time.Time        `json:"settlement_date" gorm:"type:date;not null"`
+	TransactionCount int              `json:"transaction_count" gorm:"not null"`
+	GrossAmount      float64          `json:"gross_amount" gorm:"not null"`      // Sum of OTR prices
+	CommissionAmount float64          `json:"commission_amount" gorm:"not null"` // Sum of commissions kept
+	NetAmount        float64          `json:"net_amount" gorm:"not null"`        // Amount paid to the merchant
+	Status           SettlementStatus `json:"status" gorm:"not null;default:'pending'"`
+	PaymentReference string           `json:"payment_reference,omitempty"`
+	PaidAt           *time.Time       `json:"paid_at,omitempty"`
+	CreatedAt        time.Time        `json:"created_at"`
+	UpdatedAt        time.Time        `json:"updated_at"`
+
+	// Relations
+	Merchant *Merchant        `json:"merchant,omitempty" gorm:"foreignKey:MerchantID"`
+	Items    []SettlementItem `json:"items,omitempty" gorm:"foreignKey:SettlementID"`
+}
+
+// SettlementItem represents a contract included in a settlement. A contract
+// is settled at most once.
+type SettlementItem struct {
+	ID               uint      `json:"id" gorm:"primaryKey"`
+	SettlementID     uint      `json:"settlement_id" gorm:"not null"`
+	TransactionID    uint      `json:"transaction_id" gorm:"unique;not null"`
+	ContractNumber   string    `json:"contract_number" gorm:"not null"`
+	GrossAmount      float64   `json:"gross_amount" gorm:"not null"`
+	CommissionRate   float64   `json:"commission_rate" gorm:"not null"`
+	CommissionAmount float64   `json:"commission_amount" gorm:"not null"`
+	NetAmount        float64   `json:"net_amount" gorm:"not null"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// SettlementRepository represents the settlement repository contract
+type SettlementRepository interface {
+	ListUnsettledTransactions(cutoff time.Time, limit int) ([]Transaction, error)
+	Create(settlement *Settlement) error
+	GetByID(id uint) (*Settlement, error)
+	ListByMerchant(merchantID uint, offset, limit int) ([]Settlement, error)
+	MarkPaid(id uint, reference string, paidAt time.Time) error
+}
+
+// SettlementUseCase represents the settlement use case contract
+type SettlementUseCase interface {
+	RunDaily(now time.Time) ([]Settlement, error)
+	GetByID(id uint) (*Settlement, error)
+	ListByMerchant(merchantID uint, offset, limit int) ([]Settlement, error)
+	MarkPaid(id uint, reference string) (*Settlement, error)
+}
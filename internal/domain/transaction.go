@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"math"
 	"time"
 )
 
@@ -21,29 +22,41 @@ const (
 	StatusApproved  TransactionStatus = "approved"
 	StatusRejected  TransactionStatus = "rejected"
 	StatusCancelled TransactionStatus = "cancelled"
+	StatusExpired   TransactionStatus = "expired" // Not decided within the underwriting SLA
 )
 
 // Transaction represents the transaction entity
 type Transaction struct {
-	ID                uint              `json:"id" gorm:"primaryKey"`
-	ContractNumber    string            `json:"contract_number" gorm:"unique;not null"`
-	CustomerID        uint              `json:"customer_id" gorm:"not null"`
-	Source            TransactionSource `json:"source" gorm:"not null"`
-	Status            TransactionStatus `json:"status" gorm:"not null"`
-	AssetName         string            `json:"asset_name" gorm:"not null"`
-	OTRAmount         float64           `json:"otr_amount" gorm:"not null"` // On The Road price
-	AdminFee          float64           `json:"admin_fee" gorm:"not null"`
-	InstallmentAmount float64           `json:"installment_amount" gorm:"not null"`
-	InterestAmount    float64           `json:"interest_amount" gorm:"not null"`
-	Tenor             int               `json:"tenor" gorm:"not null"`             // in months
-	Version           int               `json:"version" gorm:"not null;default:1"` // For optimistic locking
-	CreatedAt         time.Time         `json:"created_at"`
-	UpdatedAt         time.Time         `json:"updated_at"`
-	DeletedAt         *time.Time        `json:"deleted_at,omitempty" gorm:"index"`
+	ID                 uint              `json:"id" gorm:"primaryKey"`
+	ContractNumber     string            `json:"contract_number" gorm:"unique;not null"`
+	CustomerID         uint              `json:"customer_id" gorm:"not null"`
+	Source             TransactionSource `json:"source" gorm:"not null"`
+	PartnerID          *uint             `json:"partner_id,omitempty"`  // Partner that submitted the transaction
+	MerchantID         *uint             `json:"merchant_id,omitempty"` // Merchant that sold the asset
+	Status             TransactionStatus `json:"status" gorm:"not null"`
+	AssetName          string            `json:"asset_name" gorm:"not null"`
+	OTRAmount          float64           `json:"otr_amount" gorm:"not null"` // On The Road price
+	AdminFee           float64           `json:"admin_fee" gorm:"not null"`
+	InstallmentAmount  float64           `json:"installment_amount" gorm:"not null"`
+	InterestAmount     float64           `json:"interest_amount" gorm:"not null"`
+	Tenor              int               `json:"tenor" gorm:"not null"`                         // in months, the credit limit the contract is charged to
+	RestructuredAmount float64           `json:"restructured_amount" gorm:"not null;default:0"` // Added to the financed amount by restructurings
+	DeviceID           string            `json:"device_id,omitempty"`                           // Device the contract was submitted from
+	RiskFlag           RiskFlag          `json:"risk_flag" gorm:"not null;default:'clear'"`     // Held contracts are referred to an operator by underwriting
+	RiskHits           []ScreeningHit    `json:"risk_hits,omitempty" gorm:"type:jsonb;serializer:json"`
+	Version            int               `json:"version" gorm:"not null;default:1"` // For optimistic locking
+	CreatedAt          time.Time         `json:"created_at"`
+	UpdatedAt          time.Time         `json:"updated_at"`
+	DeletedAt          *time.Time        `json:"deleted_at,omitempty" gorm:"index"`
 
 	// Relations
 	Customer     *Customer     `json:"customer,omitempty" gorm:"foreignKey:CustomerID"`
+	Partner      *Partner      `json:"partner,omitempty" gorm:"foreignKey:PartnerID"`
+	Merchant     *Merchant     `json:"merchant,omitempty" gorm:"foreignKey:MerchantID"`
 	Installments []Installment `json:"installments,omitempty" gorm:"foreignKey:TransactionID"`
+
+	// Events is written to the outbox together with the transaction
+	Events []OutboxEvent `json:"-" gorm:"-"`
 }
 
 // Installment represents the installment entity
@@ -53,11 +66,60 @@ type Installment struct {
 	InstallmentNumber int        `json:"installment_number" gorm:"not null"`
 	DueDate           time.Time  `json:"due_date" gorm:"not null"`
 	Amount            float64    `json:"amount" gorm:"not null"`
-	Status            string     `json:"status" gorm:"not null;default:'unpaid'"` // paid, unpaid, overdue
+	Principal         float64    `json:"principal" gorm:"not null;default:0"`        // Part of amount repaying the financed amount
+	Interest          float64    `json:"interest" gorm:"not null;default:0"`         // Part of amount paying interest, by the effective interest method
+	AccruedInterest   float64    `json:"accrued_interest" gorm:"not null;default:0"` // Interest earned so far, up to Interest by the due date
+	LateFee           float64    `json:"late_fee" gorm:"not null;default:0"`
+	Status            string     `json:"status" gorm:"not null;default:'unpaid'"` // paid, unpaid, overdue, superseded, written_off
 	Version           int        `json:"version" gorm:"not null;default:1"`       // For optimistic locking
+	FencingToken      int64      `json:"-" gorm:"not null;default:0"`             // Highest distributed lock token that wrote this row
 	PaidAt            *time.Time `json:"paid_at,omitempty"`
+	RestructuringID   *uint      `json:"restructuring_id,omitempty"` // Restructuring whose schedule the installment belongs to
+	SupersededBy      *uint      `json:"superseded_by,omitempty"`    // Restructuring that replaced the installment
 	CreatedAt         time.Time  `json:"created_at"`
 	UpdatedAt         time.Time  `json:"updated_at"`
+
+	// Relations
+	Transaction *Transaction `json:"-" gorm:"foreignKey:TransactionID"`
+
+	// Events is written to the outbox together with the installment
+	Events []OutboxEvent `json:"-" gorm:"-"`
+}
+
+// IsOutstanding reports whether the installment is still to be paid
+func (i *Installment) IsOutstanding() bool {
+	return i.Status == "unpaid" || i.Status == "overdue"
+}
+
+// LateFeePolicy charges a daily rate on the amount of an overdue
+// installment, capped at a maximum rate
+type LateFeePolicy struct {
+	DailyRate float64
+	MaxRate   float64
+}
+
+// Fee returns the late fee of an installment of amount due on dueDate, as of
+// asOf. Installments are not late on their due date.
+func (p LateFeePolicy) Fee(amount float64, dueDate, asOf time.Time) float64 {
+	days := int(asOf.Sub(dueDate).Hours() / 24)
+	if days <= 0 {
+		return 0
+	}
+	rate := p.DailyRate * float64(days)
+	if p.MaxRate > 0 && rate > p.MaxRate {
+		rate = p.MaxRate
+	}
+	return math.Round(amount*rate*100) / 100
+}
+
+// TransactionFilter narrows the listing and export of transactions, zero
+// values match any
+type TransactionFilter struct {
+	CustomerID  uint
+	Status      TransactionStatus
+	Source      TransactionSource
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time // Exclusive
 }
 
 // TransactionRepository represents the transaction repository contract
@@ -67,9 +129,12 @@ type TransactionRepository interface {
 	GetByContractNumber(contractNumber string) (*Transaction, error)
 	Update(tx *Transaction) error
 	Delete(id uint) error
-	List(customerID uint, offset, limit int) ([]Transaction, error)
+	List(filter TransactionFilter, offset, limit int) ([]Transaction, error)
 	GetInstallments(transactionID uint) ([]Installment, error)
+	GetInstallmentByID(id uint) (*Installment, error)
 	UpdateInstallment(installment *Installment) error
+	ListOverdueInstallments(asOf time.Time, limit int) ([]Installment, error)
+	ListInstallmentsDueBetween(from, to time.Time, afterID uint, limit int) ([]Installment, error)
 }
 
 // TransactionUseCase represents the transaction use case contract
@@ -77,8 +142,9 @@ type TransactionUseCase interface {
 	Create(tx *Transaction) error
 	GetByID(id uint) (*Transaction, error)
 	GetByContractNumber(contractNumber string) (*Transaction, error)
-	UpdateStatus(id uint, status TransactionStatus) error
-	GetCustomerTransactions(customerID uint, offset, limit int) ([]Transaction, error)
+	UpdateStatus(id uint, status TransactionStatus, version int) (*Transaction, error)
+	GetCustomerTransactions(filter TransactionFilter, offset, limit int) ([]Transaction, error)
 	GetInstallments(transactionID uint) ([]Installment, error)
 	PayInstallment(installmentID uint) error
+	MarkOverdueInstallments(now time.Time) (int, error)
 }
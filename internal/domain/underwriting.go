@@ -0,0 +1,146 @@
+package domain
+
+import (
+	"time"
+)
+
+// UnderwritingOutcome is the result of an underwriting rule, and of the
+// rules taken together
+type UnderwritingOutcome string
+
+const (
+	OutcomeApprove UnderwritingOutcome = "approve"
+	OutcomeRefer   UnderwritingOutcome = "refer" // Needs review by an operator
+	OutcomeReject  UnderwritingOutcome = "reject"
+)
+
+// Severity orders outcomes, the most severe rule outcome deciding an
+// application
+func (o UnderwritingOutcome) Severity() int {
+	switch o {
+	case OutcomeReject:
+		return 2
+	case OutcomeRefer:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// UnderwritingStatus represents the status of an underwriting decision
+type UnderwritingStatus string
+
+const (
+	UnderwritingDecided  UnderwritingStatus = "decided"  // Approved or rejected by the rules
+	UnderwritingQueued   UnderwritingStatus = "queued"   // Referred, awaiting an operator
+	UnderwritingApproved UnderwritingStatus = "approved" // Approved by an operator
+	UnderwritingRejected UnderwritingStatus = "rejected" // Rejected by an operator
+	UnderwritingExpired  UnderwritingStatus = "expired"  // Not reviewed within the SLA
+)
+
+// Underwriting rules
+const (
+	RuleCreditLimit = "credit_limit"
+	RuleDBR         = "debt_burden_ratio"
+	RuleAge         = "age"
+	RuleOverdue     = "existing_overdue"
+	RuleBlacklist   = "blacklist"
+	RuleAmount      = "auto_approval_amount"
+	RuleCreditScore = "credit_score"
+	RuleScreening   = "fraud_screening"
+)
+
+// RuleResult is the outcome of one underwriting rule
+type RuleResult struct {
+	Rule    string              `json:"rule"`
+	Outcome UnderwritingOutcome `json:"outcome"`
+	Reason  string              `json:"reason,omitempty"` // Machine readable, set unless approved
+	Detail  string              `json:"detail,omitempty"`
+}
+
+// UnderwritingDecision records the underwriting of a contract application.
+// Referred applications wait in the operator work queue until reviewed, or
+// expire once DueAt passes.
+type UnderwritingDecision struct {
+	ID            uint                `json:"id" gorm:"primaryKey"`
+	TransactionID uint                `json:"transaction_id" gorm:"unique;not null"`
+	Outcome       UnderwritingOutcome `json:"outcome" gorm:"not null"`
+	Status        UnderwritingStatus  `json:"status" gorm:"not null"`
+	Rules         []RuleResult        `json:"rules" gorm:"type:jsonb;serializer:json;not null"`
+	AssignedTo    *uint               `json:"assigned_to,omitempty"` // Operator who claimed the application
+	ReviewedBy    *uint               `json:"reviewed_by,omitempty"`
+	ReviewNote    string              `json:"review_note,omitempty"`
+	ReviewedAt    *time.Time          `json:"reviewed_at,omitempty"`
+	DueAt         time.Time           `json:"due_at" gorm:"not null"` // The application expires unless decided by then
+	CreatedAt     time.Time           `json:"created_at"`
+	UpdatedAt     time.Time           `json:"updated_at"`
+
+	// Relations
+	Transaction *Transaction `json:"transaction,omitempty" gorm:"foreignKey:TransactionID"`
+}
+
+// Reasons returns the reasons of the rules that did not approve
+func (d *UnderwritingDecision) Reasons() []string {
+	var reasons []string
+	for _, result := range d.Rules {
+		if result.Outcome != OutcomeApprove {
+			reasons = append(reasons, result.Reason)
+		}
+	}
+	return reasons
+}
+
+// CreditExposure summarises a customer's other contracts, as underwritten
+// against a new application
+type CreditExposure struct {
+	TenorAmount         float64    // Financed amount of open contracts charged to the same tenor
+	MonthlyInstallments float64    // Installments due each month on open contracts
+	OverdueInstallments int        // Installments currently overdue
+	OldestOverdueDate   *time.Time // Due date of the oldest overdue installment
+}
+
+// UnderwritingPolicy configures the underwriting rules
+type UnderwritingPolicy struct {
+	ReferDBR             float64 // Debt burden ratio above which applications are referred
+	MaxDBR               float64 // Debt burden ratio above which applications are rejected
+	MinAge               int     // Minimum age when applying
+	MaxAge               int     // Maximum age at the end of the tenor
+	MaxOverdueDays       int     // Days overdue on other contracts above which applications are rejected, any overdue refers
+	MaxAutoApproveAmount float64 // Financed amount above which applications are referred, 0 for no bound
+	ReviewSLA            time.Duration
+}
+
+// UnderwritingRepository represents the underwriting repository contract
+type UnderwritingRepository interface {
+	// Create records the decision, and the status of tx when the rules
+	// decided it, in one database transaction. It returns ErrEventProcessed
+	// if the contract was underwritten already.
+	Create(decision *UnderwritingDecision, tx *Transaction) error
+	GetByID(id uint) (*UnderwritingDecision, error)
+	GetByTransaction(transactionID uint) (*UnderwritingDecision, error)
+	// ListQueue lists the referred applications still pending, the earliest
+	// due first
+	ListQueue(offset, limit int) ([]UnderwritingDecision, error)
+	Claim(decision *UnderwritingDecision, audit *AuditLog) error
+	// Decide records an operator's decision and the status of tx
+	Decide(decision *UnderwritingDecision, tx *Transaction, audit *AuditLog) error
+	// ListStale lists pending transactions created before createdBefore
+	ListStale(createdBefore time.Time, limit int) ([]Transaction, error)
+	// Expire expires a pending transaction and its queued decision
+	Expire(tx *Transaction) error
+	GetExposure(customerID uint, tenor int, excludeTransactionID uint) (*CreditExposure, error)
+}
+
+// UnderwritingUseCase represents the underwriting use case contract
+type UnderwritingUseCase interface {
+	// Underwrite runs the rules on a new contract, approving or rejecting
+	// it, or referring it to the work queue
+	Underwrite(contractNumber string) (*UnderwritingDecision, error)
+	GetByTransaction(transactionID uint) (*UnderwritingDecision, error)
+	ListQueue(offset, limit int) ([]UnderwritingDecision, error)
+	Claim(id uint, actor Actor) (*UnderwritingDecision, error)
+	Approve(id uint, note string, actor Actor) (*UnderwritingDecision, error)
+	Reject(id uint, note string, actor Actor) (*UnderwritingDecision, error)
+	// ExpireStale expires the applications pending longer than the SLA
+	ExpireStale(now time.Time) (int, error)
+}
@@ -0,0 +1,224 @@
+package domain
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// EventType identifies an event recorded in the outbox
+type EventType string
+
+// Events delivered to partner webhooks
+const (
+	EventContractApproved     EventType = "contract.approved"
+	EventContractRejected     EventType = "contract.rejected"
+	EventContractPaidOff      EventType = "contract.paid_off"
+	EventInstallmentPaid      EventType = "installment.paid"
+	EventInstallmentOverdue   EventType = "installment.overdue"
+	EventInstallmentReversed  EventType = "installment.reversed"
+	EventContractRestructured EventType = "contract.restructured"
+	EventContractExpired      EventType = "contract.expired"
+	EventContractWrittenOff   EventType = "contract.written_off"
+)
+
+// Headers identifying the event of a webhook request, sent along with the
+// signing.HeaderTimestamp, signing.HeaderNonce and signing.HeaderSignature
+// headers of a signed request
+const (
+	HeaderWebhookEvent = "X-Webhook-Event"
+	HeaderWebhookID    = "X-Webhook-ID" // Event ID, stable across retries
+)
+
+// WebhookEventTypes lists the events partners may subscribe to
+var WebhookEventTypes = []EventType{
+	EventContractApproved,
+	EventContractRejected,
+	EventContractPaidOff,
+	EventInstallmentPaid,
+	EventInstallmentOverdue,
+	EventInstallmentReversed,
+	EventContractRestructured,
+	EventContractExpired,
+	EventContractWrittenOff,
+}
+
+// IsWebhookEvent reports whether partners may subscribe to the event type
+func IsWebhookEvent(eventType EventType) bool {
+	for _, t := range WebhookEventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// OutboxEvent is an event written in the same database transaction as the
+// state change that caused it, so it is published if and only if the change
+// is committed
+type OutboxEvent struct {
+	ID           uint            `json:"-" gorm:"primaryKey"`
+	EventID      string          `json:"id" gorm:"unique;not null"` // Sent to receivers for deduplication
+	EventType    EventType       `json:"type" gorm:"not null"`
+	PartnerID    *uint           `json:"partner_id,omitempty"` // Partner whose webhooks receive the event
+	Payload      json.RawMessage `json:"payload" gorm:"type:jsonb;not null"`
+	DispatchedAt *time.Time      `json:"dispatched_at,omitempty"` // Set once fanned out to subscriptions
+	PublishedAt  *time.Time      `json:"-"`                       // Set once published to the event bus
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+// SubscriptionStatus represents the status of a webhook subscription
+type SubscriptionStatus string
+
+const (
+	SubscriptionActive   SubscriptionStatus = "active"
+	SubscriptionDisabled SubscriptionStatus = "disabled"
+)
+
+// EventTypes is a list of event types stored as a comma separated column
+type EventTypes []EventType
+
+// Value implements driver.Valuer
+func (t EventTypes) Value() (driver.Value, error) {
+	values := make([]string, len(t))
+	for i, eventType := range t {
+		values[i] = string(eventType)
+	}
+	return strings.Join(values, ","), nil
+}
+
+// Scan implements sql.Scanner
+func (t *EventTypes) Scan(value interface{}) error {
+	var s string
+	switch v := value.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	case nil:
+		*t = nil
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into EventTypes", value)
+	}
+
+	*t = nil
+	for _, eventType := range strings.Split(s, ",") {
+		if eventType != "" {
+			*t = append(*t, EventType(eventType))
+		}
+	}
+	return nil
+}
+
+// WebhookSubscription is a partner endpoint receiving signed event
+// notifications. The signing secret is stored encrypted and only returned
+// once when issued.
+type WebhookSubscription struct {
+	ID         uint               `json:"id" gorm:"primaryKey"`
+	PartnerID  uint               `json:"partner_id" gorm:"not null"`
+	URL        string             `json:"url" gorm:"not null"`
+	EventTypes EventTypes         `json:"event_types" gorm:"type:text;not null"`
+	Secret     string             `json:"-" gorm:"column:secret_encrypted;not null"`
+	Status     SubscriptionStatus `json:"status" gorm:"not null;default:'active'"`
+	CreatedAt  time.Time          `json:"created_at"`
+	UpdatedAt  time.Time          `json:"updated_at"`
+}
+
+// Accepts reports whether the subscription receives events of the given type
+func (s *WebhookSubscription) Accepts(eventType EventType) bool {
+	if s.Status != SubscriptionActive {
+		return false
+	}
+	for _, t := range s.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// IssuedWebhookSubscription is a subscription together with its plaintext
+// signing secret
+type IssuedWebhookSubscription struct {
+	WebhookSubscription
+	Secret string `json:"secret"`
+}
+
+// DeliveryStatus represents the status of a webhook delivery
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliveryDelivered DeliveryStatus = "delivered"
+	DeliveryDead      DeliveryStatus = "dead" // Retries exhausted, kept for manual replay
+)
+
+// WebhookDelivery tracks the delivery of one event to one subscription
+type WebhookDelivery struct {
+	ID             uint           `json:"id" gorm:"primaryKey"`
+	SubscriptionID uint           `json:"subscription_id" gorm:"not null"`
+	EventID        uint           `json:"-" gorm:"not null"`
+	Status         DeliveryStatus `json:"status" gorm:"not null;default:'pending'"`
+	Attempts       int            `json:"attempts" gorm:"not null;default:0"`
+	NextAttemptAt  time.Time      `json:"next_attempt_at" gorm:"not null"`
+	LastStatusCode int            `json:"last_status_code,omitempty"` // HTTP status of the last attempt
+	LastError      string         `json:"last_error,omitempty"`
+	DeliveredAt    *time.Time     `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+
+	// Relations
+	Subscription *WebhookSubscription `json:"-" gorm:"foreignKey:SubscriptionID"`
+	Event        *OutboxEvent         `json:"event,omitempty" gorm:"foreignKey:EventID"`
+}
+
+// WebhookRetryPolicy controls how failed deliveries are retried
+type WebhookRetryPolicy struct {
+	MaxAttempts    int           // Attempts before a delivery is dead-lettered
+	InitialBackoff time.Duration // Delay after the first failed attempt
+	MaxBackoff     time.Duration // Upper bound of the exponential delay
+}
+
+// Backoff returns the delay before the next attempt after the given number
+// of failed attempts, doubling from InitialBackoff up to MaxBackoff
+func (p WebhookRetryPolicy) Backoff(attempts int) time.Duration {
+	delay := p.InitialBackoff
+	for i := 1; i < attempts && (p.MaxBackoff <= 0 || delay < p.MaxBackoff); i++ {
+		delay *= 2
+	}
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		return p.MaxBackoff
+	}
+	return delay
+}
+
+// WebhookRepository represents the webhook repository contract
+type WebhookRepository interface {
+	CreateSubscription(subscription *WebhookSubscription) error
+	GetSubscription(id uint) (*WebhookSubscription, error)
+	ListSubscriptions(partnerID uint) ([]WebhookSubscription, error)
+	UpdateSubscription(subscription *WebhookSubscription) error
+	DeleteSubscription(id uint) error
+	FanOut(now time.Time, limit int) (int, error)
+	ClaimDueDeliveries(now time.Time, lease time.Duration, limit int) ([]WebhookDelivery, error)
+	GetDelivery(id uint) (*WebhookDelivery, error)
+	ListDeliveries(subscriptionID uint, status DeliveryStatus, offset, limit int) ([]WebhookDelivery, error)
+	UpdateDelivery(delivery *WebhookDelivery) error
+}
+
+// WebhookUseCase represents the webhook use case contract
+type WebhookUseCase interface {
+	CreateSubscription(subscription *WebhookSubscription) (*IssuedWebhookSubscription, error)
+	GetSubscription(id uint) (*WebhookSubscription, error)
+	ListSubscriptions(partnerID uint) ([]WebhookSubscription, error)
+	UpdateSubscription(subscription *WebhookSubscription) (*WebhookSubscription, error)
+	DeleteSubscription(id uint) error
+	RotateSecret(id uint) (*IssuedWebhookSubscription, error)
+	ListDeliveries(subscriptionID uint, status DeliveryStatus, offset, limit int) ([]WebhookDelivery, error)
+	RetryDelivery(id uint) (*WebhookDelivery, error)
+	Dispatch(ctx context.Context, now time.Time) (int, error)
+}
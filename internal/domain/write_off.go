@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"time"
+)
+
+// StatusWrittenOff is the status of contracts closed by a write-off
+const StatusWrittenOff TransactionStatus = "written_off"
+
+// InstallmentWrittenOff is the status of the installments outstanding when
+// their contract was written off
+const InstallmentWrittenOff = "written_off"
+
+// WriteOff closes a contract classified macet, removing its outstanding
+// balance from the portfolio. Amounts recovered afterwards are recorded
+// against the write-off.
+type WriteOff struct {
+	ID              uint           `json:"id" gorm:"primaryKey"`
+	TransactionID   uint           `json:"transaction_id" gorm:"unique;not null"`
+	CustomerID      uint           `json:"customer_id" gorm:"not null"`
+	Collectibility  Collectibility `json:"collectibility" gorm:"not null"`
+	DPD             int            `json:"dpd" gorm:"column:dpd;not null"`
+	Amount          float64        `json:"amount" gorm:"not null"`    // Outstanding installments written off
+	LateFees        float64        `json:"late_fees" gorm:"not null"` // Late fees of the installments written off
+	RecoveredAmount float64        `json:"recovered_amount" gorm:"not null;default:0"`
+	Reason          string         `json:"reason" gorm:"not null"`
+	WrittenOffBy    uint           `json:"written_off_by" gorm:"not null"`
+	WrittenOffAt    time.Time      `json:"written_off_at" gorm:"not null"`
+	Version         int            `json:"version" gorm:"not null;default:1"` // For optimistic locking
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+
+	// Relations
+	Recoveries []Recovery `json:"recoveries,omitempty" gorm:"foreignKey:WriteOffID"`
+
+	// WrittenOff holds the installments closed by the write-off
+	WrittenOff []Installment `json:"-" gorm:"-"`
+	// Events is written to the outbox together with the write-off or the
+	// recovery
+	Events []OutboxEvent `json:"-" gorm:"-"`
+}
+
+// Balance is the amount written off and still to be recovered
+func (w *WriteOff) Balance() float64 {
+	return w.Amount + w.LateFees - w.RecoveredAmount
+}
+
+// Recovery is an amount collected on a contract after its write-off
+type Recovery struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	WriteOffID uint      `json:"write_off_id" gorm:"not null"`
+	Amount     float64   `json:"amount" gorm:"not null"`
+	Reference  string    `json:"reference,omitempty"` // e.g. bank transfer or auction reference
+	Note       string    `json:"note,omitempty"`
+	ReceivedAt time.Time `json:"received_at" gorm:"not null"`
+	RecordedBy uint      `json:"recorded_by" gorm:"not null"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ContractWrittenOff is the data of contract.written_off events
+type ContractWrittenOff struct {
+	TransactionID  uint              `json:"transaction_id"`
+	ContractNumber string            `json:"contract_number"`
+	Status         TransactionStatus `json:"status"`
+	Amount         float64           `json:"amount"`
+}
+
+// WriteOffRepository represents the write-off repository contract
+type WriteOffRepository interface {
+	// Create writes off the installments of the write-off and closes its
+	// contract in one database transaction
+	Create(writeOff *WriteOff, audit *AuditLog) error
+	GetByID(id uint) (*WriteOff, error)
+	List(offset, limit int) ([]WriteOff, error)
+	// AddRecovery records a recovery and adds it to the recovered amount of
+	// the write-off
+	AddRecovery(writeOff *WriteOff, recovery *Recovery, audit *AuditLog) error
+}
+
+// WriteOffUseCase represents the write-off use case contract
+type WriteOffUseCase interface {
+	WriteOff(transactionID uint, reason string, actor Actor) (*WriteOff, error)
+	GetByID(id uint) (*WriteOff, error)
+	List(offset, limit int) ([]WriteOff, error)
+	RecordRecovery(writeOffID uint, recovery *Recovery, actor Actor) (*WriteOff, error)
+}
@@ -1,9 +1,10 @@
 package middleware
 
 import (
-	"net/http"
+	"strconv"
 	"strings"
 	"time"
+	"xyz-multifinance/internal/domain"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
@@ -24,7 +25,7 @@ func NewAuthMiddleware(config AuthConfig) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "authorization header is required"})
+			c.Error(domain.NewError(domain.ErrUnauthorized, "missing_authorization", "authorization header is required"))
 			c.Abort()
 			return
 		}
@@ -32,7 +33,7 @@ func NewAuthMiddleware(config AuthConfig) gin.HandlerFunc {
 		// Bearer token format
 		parts := strings.Split(authHeader, " ")
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid authorization format"})
+			c.Error(domain.NewError(domain.ErrUnauthorized, "invalid_authorization_format", "invalid authorization format"))
 			c.Abort()
 			return
 		}
@@ -45,20 +46,20 @@ func NewAuthMiddleware(config AuthConfig) gin.HandlerFunc {
 		})
 
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+			c.Error(domain.NewError(domain.ErrUnauthorized, "invalid_token", "invalid token"))
 			c.Abort()
 			return
 		}
 
 		if !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+			c.Error(domain.NewError(domain.ErrUnauthorized, "invalid_token", "invalid token"))
 			c.Abort()
 			return
 		}
 
 		// Check token expiration
 		if claims.ExpiresAt.Time.Before(time.Now()) {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "token expired"})
+			c.Error(domain.NewError(domain.ErrUnauthorized, "token_expired", "token expired"))
 			c.Abort()
 			return
 		}
@@ -71,6 +72,43 @@ func NewAuthMiddleware(config AuthConfig) gin.HandlerFunc {
 	}
 }
 
+// RequireRole only lets through requests authenticated with one of roles.
+// It must run after NewAuthMiddleware.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString("role")
+		for _, allowed := range roles {
+			if role == allowed {
+				c.Next()
+				return
+			}
+		}
+
+		c.Error(domain.NewError(domain.ErrForbidden, "insufficient_role", "insufficient role"))
+		c.Abort()
+	}
+}
+
+// RequireCustomerOrRole only lets through requests authenticated with one of
+// roles, or by the customer whose ID is the path parameter param: a token of
+// the customer role carries the customer ID as its user ID. It must run after
+// NewAuthMiddleware.
+func RequireCustomerOrRole(param string, roles ...string) gin.HandlerFunc {
+	requireRole := RequireRole(roles...)
+	return func(c *gin.Context) {
+		if c.GetString("role") == "customer" {
+			if strconv.FormatUint(uint64(c.GetUint("user_id")), 10) == c.Param(param) {
+				c.Next()
+				return
+			}
+			c.Error(domain.NewError(domain.ErrForbidden, "not_resource_owner", "customers can only access their own data"))
+			c.Abort()
+			return
+		}
+		requireRole(c)
+	}
+}
+
 // GenerateToken generates a new JWT token
 func GenerateToken(userID uint, role string, config AuthConfig) (string, error) {
 	claims := Claims{
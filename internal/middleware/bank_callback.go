@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+	"xyz-multifinance/internal/domain"
+	"xyz-multifinance/internal/pkg/signing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BankCallbackConfig struct {
+	Secrets      map[string][]byte // Callback secret per bank code
+	Nonces       signing.NonceStore
+	MaxClockSkew time.Duration // Defaults to 5 minutes
+	MaxBodyBytes int64         // Defaults to 1 MB
+	Now          func() time.Time
+}
+
+// NewBankCallbackMiddleware verifies payment callbacks of the bank named by
+// the :bank path parameter. Banks sign callbacks like partners sign requests,
+// with the X-Timestamp, X-Nonce and X-Signature headers and the bank's
+// callback secret. The bank code is stored in the context under "bank_code".
+func NewBankCallbackMiddleware(config BankCallbackConfig) gin.HandlerFunc {
+	if config.MaxClockSkew <= 0 {
+		config.MaxClockSkew = 5 * time.Minute
+	}
+	if config.MaxBodyBytes <= 0 {
+		config.MaxBodyBytes = defaultMaxBodyBytes
+	}
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+
+	return func(c *gin.Context) {
+		bankCode := c.Param("bank")
+		secret, ok := config.Secrets[bankCode]
+		if !ok {
+			c.Error(domain.NewError(domain.ErrNotFound, "bank_not_found", "bank does not offer virtual accounts"))
+			c.Abort()
+			return
+		}
+
+		timestamp := c.GetHeader(signing.HeaderTimestamp)
+		nonce := c.GetHeader(signing.HeaderNonce)
+		signature := c.GetHeader(signing.HeaderSignature)
+		if timestamp == "" || nonce == "" || signature == "" {
+			abortUnauthorized(c, "missing_signature", "timestamp, nonce and signature headers are required")
+			return
+		}
+
+		if !nonceFormat.MatchString(nonce) {
+			abortUnauthorized(c, "invalid_nonce", "nonce must be 16 to 64 url-safe characters")
+			return
+		}
+
+		unix, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			abortUnauthorized(c, "invalid_timestamp", "timestamp must be unix seconds")
+			return
+		}
+		skew := config.Now().Sub(time.Unix(unix, 0))
+		if skew > config.MaxClockSkew || skew < -config.MaxClockSkew {
+			abortUnauthorized(c, "stale_request", "request timestamp outside the allowed window")
+			return
+		}
+
+		body, err := readSignedBody(c, config.MaxBodyBytes)
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+
+		message := signing.StringToSign(c.Request.Method, c.Request.URL.RequestURI(), timestamp, nonce, body)
+		if !signing.Verify(secret, message, signature) {
+			abortUnauthorized(c, "invalid_signature", "invalid request signature")
+			return
+		}
+
+		fresh, err := config.Nonces.Reserve(c.Request.Context(), "bank:"+bankCode+":"+nonce, 2*config.MaxClockSkew)
+		if err != nil {
+			c.Error(domain.WrapError(ErrUnavailable, "nonce_store_unavailable", "replay protection unavailable", err))
+			c.Abort()
+			return
+		}
+		if !fresh {
+			abortUnauthorized(c, "replayed_request", "nonce already used")
+			return
+		}
+
+		c.Set("bank_code", bankCode)
+		c.Next()
+	}
+}
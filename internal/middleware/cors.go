@@ -0,0 +1,223 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"xyz-multifinance/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// safelistedHeaders may always be sent cross-origin
+var safelistedHeaders = []string{"Accept", "Accept-Language", "Content-Language"}
+
+// CORSPolicy describes which cross-origin requests are allowed
+type CORSPolicy struct {
+	// AllowedOrigins lists exact origins, "*" for any origin, or subdomain
+	// patterns such as "https://*.xyz-multifinance.com"
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+// Validate rejects policies browsers would refuse or that would leak credentials
+func (p CORSPolicy) Validate() error {
+	for _, origin := range p.AllowedOrigins {
+		if origin == "*" && p.AllowCredentials {
+			return errors.New("cors: wildcard origin cannot be combined with credentials")
+		}
+		if strings.Count(origin, "*") > 1 || (origin != "*" && strings.Contains(origin, "*") && !strings.Contains(origin, "://*.")) {
+			return errors.New("cors: invalid origin pattern " + origin)
+		}
+	}
+	return nil
+}
+
+type CORSConfig struct {
+	Default CORSPolicy
+	// Routes overrides the default policy for route patterns such as
+	// "/api/v1/customers/:id/erasure-requests", longest pattern wins
+	Routes map[string]CORSPolicy
+}
+
+// NewCORSMiddleware answers preflight requests and adds CORS headers to
+// requests from allowed origins
+func NewCORSMiddleware(config CORSConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		policy := config.policyFor(c.Request.URL.Path)
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+
+		c.Writer.Header().Add("Vary", "Origin")
+		if preflight {
+			c.Writer.Header().Add("Vary", "Access-Control-Request-Method")
+			c.Writer.Header().Add("Vary", "Access-Control-Request-Headers")
+		}
+
+		allowOrigin, ok := policy.allowOrigin(origin)
+		if !ok {
+			if preflight {
+				c.Error(domain.NewError(domain.ErrForbidden, "cors_origin_not_allowed", "origin not allowed"))
+				c.Abort()
+				return
+			}
+			// Browsers block the response without CORS headers
+			c.Next()
+			return
+		}
+
+		if preflight {
+			policy.handlePreflight(c, allowOrigin)
+			return
+		}
+
+		c.Header("Access-Control-Allow-Origin", allowOrigin)
+		if policy.AllowCredentials {
+			c.Header("Access-Control-Allow-Credentials", "true")
+		}
+		if len(policy.ExposedHeaders) > 0 {
+			c.Header("Access-Control-Expose-Headers", strings.Join(policy.ExposedHeaders, ", "))
+		}
+		c.Next()
+	}
+}
+
+func (p CORSPolicy) handlePreflight(c *gin.Context, allowOrigin string) {
+	method := strings.ToUpper(c.GetHeader("Access-Control-Request-Method"))
+	if !containsFold(p.AllowedMethods, method) {
+		c.Error(domain.NewError(domain.ErrForbidden, "cors_method_not_allowed", "method "+method+" not allowed"))
+		c.Abort()
+		return
+	}
+
+	var requested []string
+	for _, header := range strings.Split(c.GetHeader("Access-Control-Request-Headers"), ",") {
+		header = strings.TrimSpace(header)
+		if header == "" {
+			continue
+		}
+		if !containsFold(p.AllowedHeaders, header) && !containsFold(safelistedHeaders, header) {
+			c.Error(domain.NewError(domain.ErrForbidden, "cors_header_not_allowed", "header "+header+" not allowed"))
+			c.Abort()
+			return
+		}
+		requested = append(requested, header)
+	}
+
+	c.Header("Access-Control-Allow-Origin", allowOrigin)
+	c.Header("Access-Control-Allow-Methods", strings.Join(p.AllowedMethods, ", "))
+	if len(requested) > 0 {
+		c.Header("Access-Control-Allow-Headers", strings.Join(requested, ", "))
+	}
+	if p.AllowCredentials {
+		c.Header("Access-Control-Allow-Credentials", "true")
+	}
+	if p.MaxAge > 0 {
+		c.Header("Access-Control-Max-Age", strconv.Itoa(int(p.MaxAge.Seconds())))
+	}
+	c.AbortWithStatus(http.StatusNoContent)
+}
+
+// allowOrigin returns the Access-Control-Allow-Origin value for origin
+func (p CORSPolicy) allowOrigin(origin string) (string, bool) {
+	origin = strings.ToLower(origin)
+	for _, allowed := range p.AllowedOrigins {
+		allowed = strings.ToLower(allowed)
+		switch {
+		case allowed == "*":
+			if p.AllowCredentials {
+				// Never reflect arbitrary origins on credentialed requests
+				continue
+			}
+			return "*", true
+		case allowed == origin:
+			return origin, true
+		case matchSubdomain(allowed, origin):
+			return origin, true
+		}
+	}
+	return "", false
+}
+
+// matchSubdomain matches origin against a pattern such as
+// "https://*.example.com", requiring at least one subdomain label
+func matchSubdomain(pattern, origin string) bool {
+	prefix, suffix, found := strings.Cut(pattern, "*")
+	if !found || len(origin) <= len(prefix)+len(suffix) ||
+		!strings.HasPrefix(origin, prefix) || !strings.HasSuffix(origin, suffix) {
+		return false
+	}
+
+	subdomain := origin[len(prefix) : len(origin)-len(suffix)]
+	if strings.HasPrefix(subdomain, ".") {
+		return false
+	}
+	for _, r := range subdomain {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+// policyFor selects the policy of the longest route pattern matching path
+func (config CORSConfig) policyFor(path string) CORSPolicy {
+	policy := config.Default
+	matched := -1
+	for pattern, p := range config.Routes {
+		if matchRoutePattern(pattern, path) && len(pattern) > matched {
+			policy = p
+			matched = len(pattern)
+		}
+	}
+	return policy
+}
+
+// matchRoutePattern reports whether path starts with the segments of a
+// route pattern, where ":name" segments match any value
+func matchRoutePattern(pattern, path string) bool {
+	patternSegments := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathSegments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(pathSegments) < len(patternSegments) {
+		return false
+	}
+	for i, segment := range patternSegments {
+		if !strings.HasPrefix(segment, ":") && segment != pathSegments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// Example usage:
+// config := CORSConfig{
+//     Default: CORSPolicy{
+//         AllowedOrigins:   []string{"http://localhost:3000", "https://*.xyz-multifinance.com"},
+//         AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE"},
+//         AllowedHeaders:   []string{"Authorization", "Content-Type", "If-Match"},
+//         ExposedHeaders:   []string{"ETag"},
+//         AllowCredentials: true,
+//         MaxAge:           5 * time.Minute,
+//     },
+// }
+// router.Use(NewCORSMiddleware(config))
@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"xyz-multifinance/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ProblemContentType is the media type of RFC 7807 problem details
+const ProblemContentType = "application/problem+json"
+
+// problemTypeBase is the base URI used to build the problem type
+const problemTypeBase = "https://xyz-multifinance.com/problems/"
+
+// Problem represents an RFC 7807 problem details object
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Code     string `json:"code"`
+}
+
+// errorKind maps a domain sentinel error to its HTTP status and default code
+type errorKind struct {
+	err    error
+	status int
+	code   string
+}
+
+var errorKinds = []errorKind{
+	{domain.ErrValidation, http.StatusBadRequest, "validation_failed"},
+	{domain.ErrUnauthorized, http.StatusUnauthorized, "unauthorized"},
+	{domain.ErrForbidden, http.StatusForbidden, "forbidden"},
+	{domain.ErrNotFound, http.StatusNotFound, "not_found"},
+	{domain.ErrPreconditionFailed, http.StatusPreconditionFailed, "precondition_failed"},
+	{domain.ErrPreconditionRequired, http.StatusPreconditionRequired, "precondition_required"},
+	{domain.ErrConflict, http.StatusConflict, "conflict"},
+	{domain.ErrInsufficientLimit, http.StatusUnprocessableEntity, "insufficient_credit_limit"},
+	{ErrPayloadTooLarge, http.StatusRequestEntityTooLarge, "request_body_too_large"},
+	{ErrRateLimited, http.StatusTooManyRequests, "rate_limit_exceeded"},
+	{ErrUnavailable, http.StatusServiceUnavailable, "service_unavailable"},
+}
+
+// NewProblem builds the problem details describing err
+func NewProblem(err error) Problem {
+	problem := Problem{
+		Status: http.StatusInternalServerError,
+		Code:   "internal_error",
+		Detail: "an unexpected error occurred",
+	}
+
+	for _, kind := range errorKinds {
+		if errors.Is(err, kind.err) {
+			problem.Status = kind.status
+			problem.Code = kind.code
+			problem.Detail = kind.err.Error()
+			break
+		}
+	}
+
+	// Prefer the code and message of a typed domain error when present
+	var domainErr *domain.Error
+	if problem.Status != http.StatusInternalServerError && errors.As(err, &domainErr) {
+		problem.Code = domainErr.Code
+		problem.Detail = domainErr.Message
+	}
+
+	problem.Type = problemTypeBase + problem.Code
+	problem.Title = http.StatusText(problem.Status)
+	return problem
+}
+
+// NewErrorHandlerMiddleware renders errors attached with c.Error as problem+json
+func NewErrorHandlerMiddleware(logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		err := c.Errors.Last().Err
+		problem := NewProblem(err)
+		problem.Instance = c.Request.URL.Path
+
+		if problem.Status >= http.StatusInternalServerError {
+			logger.Error("request failed",
+				zap.String("method", c.Request.Method),
+				zap.String("path", c.Request.URL.Path),
+				zap.Error(err),
+			)
+		}
+
+		c.Header("Content-Type", ProblemContentType)
+		c.JSON(problem.Status, problem)
+	}
+}
@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"time"
+	"xyz-multifinance/internal/domain"
+	"xyz-multifinance/internal/pkg/signing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// nonceFormat bounds the nonces accepted from partners
+var nonceFormat = regexp.MustCompile(`^[A-Za-z0-9_-]{16,64}$`)
+
+type PartnerAuthConfig struct {
+	Partners     domain.PartnerUseCase
+	Nonces       signing.NonceStore
+	MaxClockSkew time.Duration // Defaults to 5 minutes
+	MaxBodyBytes int64         // Defaults to 1 MB
+	Now          func() time.Time
+}
+
+// NewPartnerAuthMiddleware verifies HMAC-SHA256 signed partner requests and
+// stores the verified partner in the context under "partner" and "partner_id"
+func NewPartnerAuthMiddleware(config PartnerAuthConfig) gin.HandlerFunc {
+	if config.MaxClockSkew <= 0 {
+		config.MaxClockSkew = 5 * time.Minute
+	}
+	if config.MaxBodyBytes <= 0 {
+		config.MaxBodyBytes = defaultMaxBodyBytes
+	}
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+
+	return func(c *gin.Context) {
+		keyID := c.GetHeader(signing.HeaderKeyID)
+		timestamp := c.GetHeader(signing.HeaderTimestamp)
+		nonce := c.GetHeader(signing.HeaderNonce)
+		signature := c.GetHeader(signing.HeaderSignature)
+		if keyID == "" || timestamp == "" || nonce == "" || signature == "" {
+			abortUnauthorized(c, "missing_signature", "api key, timestamp, nonce and signature headers are required")
+			return
+		}
+
+		if !nonceFormat.MatchString(nonce) {
+			abortUnauthorized(c, "invalid_nonce", "nonce must be 16 to 64 url-safe characters")
+			return
+		}
+
+		unix, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			abortUnauthorized(c, "invalid_timestamp", "timestamp must be unix seconds")
+			return
+		}
+		skew := config.Now().Sub(time.Unix(unix, 0))
+		if skew > config.MaxClockSkew || skew < -config.MaxClockSkew {
+			abortUnauthorized(c, "stale_request", "request timestamp outside the allowed window")
+			return
+		}
+
+		body, err := readSignedBody(c, config.MaxBodyBytes)
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+
+		credential, err := config.Partners.Authenticate(keyID)
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+
+		message := signing.StringToSign(c.Request.Method, c.Request.URL.RequestURI(), timestamp, nonce, body)
+		if !signing.Verify(credential.Secret, message, signature) {
+			abortUnauthorized(c, "invalid_signature", "invalid request signature")
+			return
+		}
+
+		// Nonces only need to be remembered while the timestamp is accepted
+		fresh, err := config.Nonces.Reserve(c.Request.Context(), keyID+":"+nonce, 2*config.MaxClockSkew)
+		if err != nil {
+			c.Error(domain.WrapError(ErrUnavailable, "nonce_store_unavailable", "replay protection unavailable", err))
+			c.Abort()
+			return
+		}
+		if !fresh {
+			abortUnauthorized(c, "replayed_request", "nonce already used")
+			return
+		}
+
+		c.Set("partner", credential.Partner)
+		c.Set("partner_id", credential.Partner.ID)
+		c.Next()
+	}
+}
+
+// readSignedBody reads the body covered by the signature and restores it for the handlers
+func readSignedBody(c *gin.Context, limit int64) ([]byte, error) {
+	if c.Request.Body == nil {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	if int64(len(body)) > limit {
+		return nil, domain.NewError(ErrPayloadTooLarge, "request_body_too_large",
+			fmt.Sprintf("request body must not exceed %d bytes", limit))
+	}
+
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+func abortUnauthorized(c *gin.Context, code, message string) {
+	c.Error(domain.NewError(domain.ErrUnauthorized, code, message))
+	c.Abort()
+}
+
+// Example usage:
+// partnerAuth := NewPartnerAuthMiddleware(PartnerAuthConfig{
+//     Partners: partnerUseCase,
+//     Nonces:   signing.NewRedisNonceStore(redisClient),
+// })
+// router.POST("/api/v1/transactions", partnerAuth, handler.Create)
@@ -1,60 +1,86 @@
 package middleware
 
 import (
-	"context"
+	"errors"
 	"fmt"
-	"net/http"
+	"math"
+	"strconv"
+	"strings"
 	"time"
+	"xyz-multifinance/internal/domain"
+	"xyz-multifinance/internal/pkg/ratelimit"
 
 	"github.com/gin-gonic/gin"
-	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
 )
 
-type RateLimiterConfig struct {
-	RedisClient *redis.Client
-	MaxRequests int           // Maximum number of requests allowed
-	Window      time.Duration // Time window for rate limiting
-}
-
-func NewRateLimiterMiddleware(config RateLimiterConfig) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// Get client IP address as key
-		clientIP := c.ClientIP()
-		key := fmt.Sprintf("rate_limit:%s", clientIP)
+var (
+	// ErrRateLimited is returned when a client exceeds its rate limit
+	ErrRateLimited = errors.New("too many requests")
+	// ErrUnavailable is returned when a dependency required to serve the request is down
+	ErrUnavailable = errors.New("service unavailable")
+)
 
-		ctx := context.Background()
-		pipe := config.RedisClient.Pipeline()
+// KeyFunc returns the identity requests are counted against
+type KeyFunc func(c *gin.Context) string
 
-		// Add current timestamp to sorted set
-		now := time.Now().UnixNano()
-		pipe.ZAdd(ctx, key, redis.Z{Score: float64(now), Member: now})
+type RateLimiterConfig struct {
+	Limiter  ratelimit.Limiter
+	Default  ratelimit.Policy            // Policy for routes without an override
+	Policies map[string]ratelimit.Policy // Policies keyed by route prefix, longest prefix wins
+	KeyFunc  KeyFunc                     // Defaults to DefaultKeyFunc
+	FailOpen bool                        // Let requests through when the limiter is unavailable
+	Logger   *zap.Logger
+}
 
-		// Remove old entries outside the window
-		windowStart := time.Now().Add(-config.Window).UnixNano()
-		pipe.ZRemRangeByScore(ctx, key, "0", fmt.Sprintf("%d", windowStart))
+// DefaultKeyFunc counts requests per authenticated user, then per verified
+// partner, then per client IP. Unverified credentials are never keyed on, as
+// a client could send a new one with every request to get a fresh bucket.
+func DefaultKeyFunc(c *gin.Context) string {
+	if userID, ok := c.Get("user_id"); ok {
+		return fmt.Sprintf("user:%v", userID)
+	}
+	if partnerID, ok := c.Get("partner_id"); ok {
+		return fmt.Sprintf("partner:%v", partnerID)
+	}
+	return "ip:" + c.ClientIP()
+}
 
-		// Count requests in current window
-		pipe.ZCard(ctx, key)
+// NewRateLimiterMiddleware counts requests against the identity set by the
+// authentication middlewares that run before it, so it must be placed after
+// them on each route rather than globally.
+func NewRateLimiterMiddleware(config RateLimiterConfig) gin.HandlerFunc {
+	if config.KeyFunc == nil {
+		config.KeyFunc = DefaultKeyFunc
+	}
+	if config.Logger == nil {
+		config.Logger = zap.NewNop()
+	}
 
-		// Set key expiration
-		pipe.Expire(ctx, key, config.Window)
+	return func(c *gin.Context) {
+		policy := config.policyFor(c)
+		key := fmt.Sprintf("rate_limit:%s:%s", policy.Name, config.KeyFunc(c))
 
-		// Execute pipeline
-		cmds, err := pipe.Exec(ctx)
+		result, err := config.Limiter.Allow(c.Request.Context(), key, policy)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "rate limit check failed"})
+			config.Logger.Warn("rate limit check failed", zap.String("key", key), zap.Error(err))
+			if config.FailOpen {
+				c.Next()
+				return
+			}
+			c.Error(domain.WrapError(ErrUnavailable, "rate_limiter_unavailable", "rate limit check failed", err))
 			c.Abort()
 			return
 		}
 
-		// Get request count from pipeline result
-		requestCount := cmds[2].(*redis.IntCmd).Val()
+		c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(result.ResetAfter)))
 
-		if requestCount > int64(config.MaxRequests) {
-			c.JSON(http.StatusTooManyRequests, gin.H{
-				"error": fmt.Sprintf("rate limit exceeded. maximum %d requests allowed per %v",
-					config.MaxRequests, config.Window),
-			})
+		if !result.Allowed {
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+			c.Error(domain.NewError(ErrRateLimited, "rate_limit_exceeded",
+				fmt.Sprintf("rate limit exceeded. maximum %d requests allowed per %v", policy.Limit, policy.Window)))
 			c.Abort()
 			return
 		}
@@ -63,10 +89,41 @@ func NewRateLimiterMiddleware(config RateLimiterConfig) gin.HandlerFunc {
 	}
 }
 
+// policyFor selects the policy of the longest route prefix matching the request
+func (config RateLimiterConfig) policyFor(c *gin.Context) ratelimit.Policy {
+	path := c.FullPath()
+	if path == "" {
+		path = c.Request.URL.Path
+	}
+
+	policy := config.Default
+	matched := -1
+	for prefix, p := range config.Policies {
+		if strings.HasPrefix(path, prefix) && len(prefix) > matched {
+			policy = p
+			matched = len(prefix)
+		}
+	}
+
+	if policy.Name == "" {
+		policy.Name = "default"
+	}
+	return policy
+}
+
+// ceilSeconds rounds a duration up to whole seconds for HTTP headers
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
 // Example usage:
 // config := RateLimiterConfig{
-//     RedisClient: redisClient,
-//     MaxRequests: 100,
-//     Window:      time.Minute,
+//     Limiter: ratelimit.NewRedisLimiter(redisClient),
+//     Default: ratelimit.Policy{Algorithm: ratelimit.SlidingWindow, Limit: 100, Window: time.Minute},
+//     Policies: map[string]ratelimit.Policy{
+//         "/api/v1/transactions": {Name: "transactions", Algorithm: ratelimit.TokenBucket, Limit: 30, Window: time.Minute, Burst: 10},
+//     },
+//     FailOpen: true,
 // }
-// router.Use(NewRateLimiterMiddleware(config))
+// rateLimit := NewRateLimiterMiddleware(config)
+// router.Group("/api/v1/partners", NewAuthMiddleware(authConfig), rateLimit, RequireRole("admin"))
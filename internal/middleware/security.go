@@ -1,80 +1,9 @@
 package middleware
 
 import (
-	"net/http"
-	"regexp"
-	"strings"
-
 	"github.com/gin-gonic/gin"
 )
 
-// SQLInjectionConfig holds configuration for SQL injection prevention
-type SQLInjectionConfig struct {
-	// Add blocked patterns that might indicate SQL injection
-	BlockedPatterns []string
-}
-
-// NewSQLInjectionMiddleware creates a new middleware for SQL injection prevention
-func NewSQLInjectionMiddleware() gin.HandlerFunc {
-	// Common SQL injection patterns
-	blockedPatterns := []string{
-		`(?i)(select|update|delete|insert|drop|alter|create|truncate).*from`,
-		`(?i)union.*select`,
-		`(?i)into.*outfile`,
-		`(?i)load_file`,
-		`(?i)--`,
-		`(?i)/\*`,
-		`(?i)\*/`,
-		`(?i);`,
-	}
-
-	compiledPatterns := make([]*regexp.Regexp, len(blockedPatterns))
-	for i, pattern := range blockedPatterns {
-		compiledPatterns[i] = regexp.MustCompile(pattern)
-	}
-
-	return func(c *gin.Context) {
-		// Check query parameters
-		for _, param := range c.Request.URL.Query() {
-			for _, value := range param {
-				if containsSQLInjection(value, compiledPatterns) {
-					c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-						"error": "Possible SQL injection detected",
-					})
-					return
-				}
-			}
-		}
-
-		// Check request body if it's a form
-		if strings.Contains(c.GetHeader("Content-Type"), "application/x-www-form-urlencoded") {
-			if err := c.Request.ParseForm(); err == nil {
-				for _, values := range c.Request.Form {
-					for _, value := range values {
-						if containsSQLInjection(value, compiledPatterns) {
-							c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-								"error": "Possible SQL injection detected",
-							})
-							return
-						}
-					}
-				}
-			}
-		}
-
-		c.Next()
-	}
-}
-
-func containsSQLInjection(value string, patterns []*regexp.Regexp) bool {
-	for _, pattern := range patterns {
-		if pattern.MatchString(value) {
-			return true
-		}
-	}
-	return false
-}
-
 // SecurityHeadersMiddleware adds security headers to responses
 func SecurityHeadersMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -0,0 +1,184 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"xyz-multifinance/internal/domain"
+	"xyz-multifinance/internal/pkg/waf"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ErrPayloadTooLarge is returned when a request body exceeds the inspection limit
+var ErrPayloadTooLarge = errors.New("payload too large")
+
+// defaultMaxBodyBytes is used when WAFConfig.MaxBodyBytes is not set
+const defaultMaxBodyBytes = 1 << 20
+
+type WAFConfig struct {
+	Engine         *waf.Engine
+	Mode           waf.Mode // Defaults to waf.ModeBlock
+	MaxBodyBytes   int64    // Inspected bodies larger than this are rejected
+	InspectHeaders []string // Request headers to inspect, e.g. User-Agent
+	UploadRoutes   []string // Routes accepting multipart uploads, whose multipart bodies are not inspected
+	Logger         *zap.Logger
+}
+
+// NewWAFMiddleware inspects query parameters, path parameters, selected
+// headers and request bodies against the rules of the engine. Handlers bind
+// JSON whatever the Content-Type, so every body other than a form or an
+// upload is inspected as JSON.
+func NewWAFMiddleware(config WAFConfig) gin.HandlerFunc {
+	if config.Mode == "" {
+		config.Mode = waf.ModeBlock
+	}
+	if config.MaxBodyBytes <= 0 {
+		config.MaxBodyBytes = defaultMaxBodyBytes
+	}
+	if config.Logger == nil {
+		config.Logger = zap.NewNop()
+	}
+
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+
+		fields, err := config.requestFields(c)
+		if err != nil {
+			if errors.Is(err, ErrPayloadTooLarge) {
+				c.Error(domain.NewError(ErrPayloadTooLarge, "request_body_too_large",
+					fmt.Sprintf("request body must not exceed %d bytes", config.MaxBodyBytes)))
+				c.Abort()
+				return
+			}
+			if errors.Is(err, waf.ErrJSONTooDeep) {
+				// The fields below the limit were not inspected
+				if config.Mode == waf.ModeBlock {
+					c.Error(domain.NewError(domain.ErrValidation, "request_body_too_deep", err.Error()))
+					c.Abort()
+					return
+				}
+				config.Logger.Warn("waf inspected body partially", zap.String("route", route), zap.Error(err))
+			} else {
+				// Malformed bodies are rejected by the handlers when bound
+				config.Logger.Debug("waf skipped body inspection", zap.String("route", route), zap.Error(err))
+			}
+		}
+
+		matches := config.Engine.Inspect(route, fields)
+		for _, match := range matches {
+			config.Logger.Warn("waf rule matched",
+				zap.String("mode", string(config.Mode)),
+				zap.String("rule_id", match.RuleID),
+				zap.String("severity", match.Severity),
+				zap.String("field", match.Field),
+				zap.String("method", c.Request.Method),
+				zap.String("route", route),
+				zap.String("client_ip", c.ClientIP()),
+			)
+		}
+
+		if len(matches) > 0 && config.Mode == waf.ModeBlock {
+			c.Error(domain.NewError(domain.ErrForbidden, "request_blocked", "request blocked by security policy"))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// requestFields collects the inspected input of a request. Fields gathered
+// before a body error are still returned.
+func (config WAFConfig) requestFields(c *gin.Context) ([]waf.Field, error) {
+	var fields []waf.Field
+
+	for _, param := range c.Params {
+		fields = append(fields, waf.Field{Target: waf.TargetPath, Name: param.Key, Value: param.Value})
+	}
+	for key, values := range c.Request.URL.Query() {
+		// Keys are attacker controlled too
+		fields = append(fields, waf.Field{Target: waf.TargetQuery, Name: key, Value: key})
+		for _, value := range values {
+			fields = append(fields, waf.Field{Target: waf.TargetQuery, Name: key, Value: value})
+		}
+	}
+	for _, name := range config.InspectHeaders {
+		for _, value := range c.Request.Header.Values(name) {
+			fields = append(fields, waf.Field{Target: waf.TargetHeader, Name: strings.ToLower(name), Value: value})
+		}
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	isForm := mediaType == "application/x-www-form-urlencoded"
+	isUpload := strings.HasPrefix(mediaType, "multipart/") && config.isUploadRoute(c.FullPath())
+	if c.Request.Body == nil || c.Request.Body == http.NoBody || isUpload {
+		return fields, nil
+	}
+
+	body, err := config.readBody(c)
+	if err != nil || len(body) == 0 {
+		return fields, err
+	}
+
+	if isForm {
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return fields, err
+		}
+		for key, vals := range values {
+			fields = append(fields, waf.Field{Target: waf.TargetForm, Name: key, Value: key})
+			for _, value := range vals {
+				fields = append(fields, waf.Field{Target: waf.TargetForm, Name: key, Value: value})
+			}
+		}
+		return fields, nil
+	}
+
+	jsonFields, err := waf.JSONFields(body)
+	return append(fields, jsonFields...), err
+}
+
+// isUploadRoute reports whether the route accepts multipart uploads
+func (config WAFConfig) isUploadRoute(route string) bool {
+	for _, upload := range config.UploadRoutes {
+		if route == upload {
+			return true
+		}
+	}
+	return false
+}
+
+// readBody reads at most MaxBodyBytes of the body and restores it for the handlers
+func (config WAFConfig) readBody(c *gin.Context) ([]byte, error) {
+	if c.Request.ContentLength > config.MaxBodyBytes {
+		return nil, ErrPayloadTooLarge
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, config.MaxBodyBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	if int64(len(body)) > config.MaxBodyBytes {
+		return nil, ErrPayloadTooLarge
+	}
+
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// Example usage:
+// engine, err := waf.LoadFile("./configs/waf_rules.yaml")
+// if err != nil {
+//     log.Fatal(err)
+// }
+// router.Use(NewWAFMiddleware(WAFConfig{Engine: engine, Mode: waf.ModeBlock, Logger: logger}))
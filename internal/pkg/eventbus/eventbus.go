@@ -0,0 +1,214 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"xyz-multifinance/internal/domain"
+	"xyz-multifinance/internal/pkg/redis"
+
+	goredis "github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// Fields of a stream entry
+const (
+	fieldEventID = "event_id"
+	fieldType    = "type"
+	fieldPayload = "payload"
+	fieldGroup   = "group" // Consumer group that gave up on a dead-lettered entry
+	fieldReason  = "reason"
+)
+
+// ConsumerConfig controls how a consumer reads its group's entries
+type ConsumerConfig struct {
+	Consumer      string        // Name of this consumer within the group, unique per process
+	Batch         int64         // Entries read per poll
+	Block         time.Duration // How long a poll waits for new entries
+	ClaimIdle     time.Duration // Pending entries idle this long are retried, also when left by a crashed consumer
+	MaxDeliveries int64         // Deliveries before an entry is moved to the dead-letter stream
+}
+
+// Bus publishes domain events to a Redis Stream and delivers them to
+// consumer groups. Entries are acknowledged only after the handler succeeds,
+// so every group sees every event at least once.
+type Bus struct {
+	client redis.RedisClient
+	stream string
+	maxLen int64
+	logger *zap.Logger
+
+	groups sync.Map // Consumer groups known to exist
+}
+
+// New creates a new event bus on the given stream, trimmed to about maxLen entries
+func New(client redis.RedisClient, stream string, maxLen int64, logger *zap.Logger) *Bus {
+	return &Bus{
+		client: client,
+		stream: stream,
+		maxLen: maxLen,
+		logger: logger,
+	}
+}
+
+// DeadLetterStream returns the stream holding entries no group could process
+func (b *Bus) DeadLetterStream() string {
+	return b.stream + ":dead"
+}
+
+// Publish implements domain.EventPublisher
+func (b *Bus) Publish(ctx context.Context, event *domain.OutboxEvent) error {
+	return b.client.XAdd(ctx, &goredis.XAddArgs{
+		Stream: b.stream,
+		MaxLen: b.maxLen,
+		Approx: true,
+		Values: map[string]interface{}{
+			fieldEventID: event.EventID,
+			fieldType:    string(event.EventType),
+			fieldPayload: string(event.Payload),
+		},
+	}).Err()
+}
+
+// Poll delivers one batch of entries to handler: first entries of its group
+// left pending past ClaimIdle, then new entries
+func (b *Bus) Poll(ctx context.Context, handler domain.EventHandler, cfg ConsumerConfig) error {
+	group := handler.Group()
+	if err := b.ensureGroup(ctx, group); err != nil {
+		return err
+	}
+
+	claimed, _, err := b.client.XAutoClaim(ctx, &goredis.XAutoClaimArgs{
+		Stream:   b.stream,
+		Group:    group,
+		Consumer: cfg.Consumer,
+		MinIdle:  cfg.ClaimIdle,
+		Start:    "0-0",
+		Count:    cfg.Batch,
+	}).Result()
+	if err != nil {
+		return fmt.Errorf("failed to claim pending events: %w", err)
+	}
+	for _, message := range claimed {
+		if err := b.retry(ctx, handler, cfg, message); err != nil {
+			return err
+		}
+	}
+
+	streams, err := b.client.XReadGroup(ctx, &goredis.XReadGroupArgs{
+		Group:    group,
+		Consumer: cfg.Consumer,
+		Streams:  []string{b.stream, ">"},
+		Count:    cfg.Batch,
+		Block:    cfg.Block,
+	}).Result()
+	if errors.Is(err, goredis.Nil) || ctx.Err() != nil {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read events: %w", err)
+	}
+	for _, stream := range streams {
+		for _, message := range stream.Messages {
+			if err := b.deliver(ctx, handler, message); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ensureGroup creates the consumer group, starting at the beginning of the
+// stream, unless it already exists
+func (b *Bus) ensureGroup(ctx context.Context, group string) error {
+	if _, ok := b.groups.Load(group); ok {
+		return nil
+	}
+
+	err := b.client.XGroupCreateMkStream(ctx, b.stream, group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("failed to create consumer group %s: %w", group, err)
+	}
+	b.groups.Store(group, struct{}{})
+	return nil
+}
+
+// retry redelivers a claimed entry, dead-lettering it once it was delivered
+// MaxDeliveries times
+func (b *Bus) retry(ctx context.Context, handler domain.EventHandler, cfg ConsumerConfig, message goredis.XMessage) error {
+	pending, err := b.client.XPendingExt(ctx, &goredis.XPendingExtArgs{
+		Stream: b.stream,
+		Group:  handler.Group(),
+		Start:  message.ID,
+		End:    message.ID,
+		Count:  1,
+	}).Result()
+	if err != nil {
+		return fmt.Errorf("failed to inspect pending event: %w", err)
+	}
+
+	if len(pending) > 0 && cfg.MaxDeliveries > 0 && pending[0].RetryCount > cfg.MaxDeliveries {
+		return b.deadLetter(ctx, handler.Group(), message, "max deliveries exceeded")
+	}
+	return b.deliver(ctx, handler, message)
+}
+
+// deliver hands an entry to the handler and acknowledges it on success.
+// Failed entries stay pending and are retried after ClaimIdle.
+func (b *Bus) deliver(ctx context.Context, handler domain.EventHandler, message goredis.XMessage) error {
+	event, err := decode(message)
+	if err != nil {
+		return b.deadLetter(ctx, handler.Group(), message, err.Error())
+	}
+
+	if err := handler.Handle(ctx, event); err != nil {
+		b.logger.Warn("event handler failed",
+			zap.String("group", handler.Group()),
+			zap.String("event_id", event.ID),
+			zap.String("type", string(event.Type)),
+			zap.Error(err),
+		)
+		return nil
+	}
+
+	return b.client.XAck(ctx, b.stream, handler.Group(), message.ID).Err()
+}
+
+// deadLetter copies an entry to the dead-letter stream and acknowledges it
+func (b *Bus) deadLetter(ctx context.Context, group string, message goredis.XMessage, reason string) error {
+	values := make(map[string]interface{}, len(message.Values)+2)
+	for k, v := range message.Values {
+		values[k] = v
+	}
+	values[fieldGroup] = group
+	values[fieldReason] = reason
+
+	if err := b.client.XAdd(ctx, &goredis.XAddArgs{Stream: b.DeadLetterStream(), Values: values}).Err(); err != nil {
+		return fmt.Errorf("failed to dead-letter event: %w", err)
+	}
+
+	b.logger.Error("event dead-lettered",
+		zap.String("group", group),
+		zap.String("entry_id", message.ID),
+		zap.String("reason", reason),
+	)
+	return b.client.XAck(ctx, b.stream, group, message.ID).Err()
+}
+
+// decode reads the event envelope from a stream entry
+func decode(message goredis.XMessage) (*domain.Event, error) {
+	payload, ok := message.Values[fieldPayload].(string)
+	if !ok {
+		return nil, errors.New("entry has no payload")
+	}
+
+	var event domain.Event
+	if err := json.Unmarshal([]byte(payload), &event); err != nil {
+		return nil, fmt.Errorf("invalid event payload: %w", err)
+	}
+	return &event, nil
+}
@@ -0,0 +1,56 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/smtp"
+	"strings"
+	"xyz-multifinance/internal/domain"
+)
+
+// SMTPConfig configures the SMTP email sender
+type SMTPConfig struct {
+	Addr     string // host:port of the SMTP server
+	Username string // Leave empty for servers without authentication
+	Password string
+	From     string
+}
+
+type smtpSender struct {
+	config SMTPConfig
+	auth   smtp.Auth
+}
+
+// NewSMTPSender creates a sender delivering email through an SMTP server
+func NewSMTPSender(config SMTPConfig) domain.NotificationSender {
+	sender := &smtpSender{config: config}
+	if config.Username != "" {
+		host := config.Addr
+		if i := strings.LastIndex(host, ":"); i >= 0 {
+			host = host[:i]
+		}
+		sender.auth = smtp.PlainAuth("", config.Username, config.Password, host)
+	}
+	return sender
+}
+
+// Send implements NotificationSender.Send
+func (s *smtpSender) Send(ctx context.Context, message *domain.NotificationMessage) error {
+	if strings.ContainsAny(message.Recipient, "\r\n") {
+		return fmt.Errorf("invalid email recipient")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", s.config.From)
+	fmt.Fprintf(&b, "To: %s\r\n", message.Recipient)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(message.Body, "\n", "\r\n"))
+
+	if err := smtp.SendMail(s.config.Addr, s.auth, s.config.From, []string{message.Recipient}, []byte(b.String())); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
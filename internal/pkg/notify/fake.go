@@ -0,0 +1,47 @@
+package notify
+
+import (
+	"context"
+	"sync"
+	"xyz-multifinance/internal/domain"
+
+	"go.uber.org/zap"
+)
+
+// Fake is a sender that logs messages and keeps them in memory instead of
+// delivering them, for local development and tests
+type Fake struct {
+	logger *zap.Logger
+
+	mu       sync.Mutex
+	messages []domain.NotificationMessage
+}
+
+// NewFake creates a new fake sender. A nil logger disables logging.
+func NewFake(logger *zap.Logger) *Fake {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Fake{logger: logger}
+}
+
+// Send implements NotificationSender.Send
+func (f *Fake) Send(ctx context.Context, message *domain.NotificationMessage) error {
+	f.mu.Lock()
+	f.messages = append(f.messages, *message)
+	f.mu.Unlock()
+
+	f.logger.Info("notification sent",
+		zap.String("channel", string(message.Channel)),
+		zap.String("recipient", message.Recipient),
+		zap.String("subject", message.Subject),
+	)
+	return nil
+}
+
+// Messages returns the messages sent so far
+func (f *Fake) Messages() []domain.NotificationMessage {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]domain.NotificationMessage(nil), f.messages...)
+}
@@ -0,0 +1,81 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"xyz-multifinance/internal/domain"
+)
+
+// GatewayConfig configures an HTTP notification gateway
+type GatewayConfig struct {
+	URL    string // Endpoint receiving a JSON POST per message
+	APIKey string // Sent as a bearer token
+}
+
+type gatewaySender struct {
+	config GatewayConfig
+	client *http.Client
+	body   func(message *domain.NotificationMessage) interface{}
+}
+
+// NewSMSSender creates a sender posting SMS messages to an SMS gateway as
+// {"to": ..., "message": ...}
+func NewSMSSender(config GatewayConfig, client *http.Client) domain.NotificationSender {
+	return &gatewaySender{
+		config: config,
+		client: client,
+		body: func(message *domain.NotificationMessage) interface{} {
+			return map[string]string{
+				"to":      message.Recipient,
+				"message": message.Body,
+			}
+		},
+	}
+}
+
+// NewPushSender creates a sender posting push notifications to a push
+// gateway as {"token": ..., "title": ..., "body": ...}
+func NewPushSender(config GatewayConfig, client *http.Client) domain.NotificationSender {
+	return &gatewaySender{
+		config: config,
+		client: client,
+		body: func(message *domain.NotificationMessage) interface{} {
+			return map[string]string{
+				"token": message.Recipient,
+				"title": message.Subject,
+				"body":  message.Body,
+			}
+		},
+	}
+}
+
+// Send implements NotificationSender.Send. Any status other than 2xx fails.
+func (s *gatewaySender) Send(ctx context.Context, message *domain.NotificationMessage) error {
+	payload, err := json.Marshal(s.body(message))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+s.config.APIKey)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send %s: %w", message.Channel, err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s gateway responded with status %d", message.Channel, resp.StatusCode)
+	}
+	return nil
+}
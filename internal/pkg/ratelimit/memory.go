@@ -0,0 +1,101 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+type bucket struct {
+	tokens float64
+	ts     time.Time
+}
+
+type memoryLimiter struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	windows map[string][]time.Time
+	buckets map[string]*bucket
+}
+
+// NewMemoryLimiter creates a process-local limiter, intended for tests and
+// single instance deployments
+func NewMemoryLimiter() Limiter {
+	return NewMemoryLimiterWithClock(time.Now)
+}
+
+// NewMemoryLimiterWithClock creates a process-local limiter using the given clock
+func NewMemoryLimiterWithClock(now func() time.Time) Limiter {
+	return &memoryLimiter{
+		now:     now,
+		windows: make(map[string][]time.Time),
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Allow implements Limiter.Allow
+func (l *memoryLimiter) Allow(ctx context.Context, key string, policy Policy) (Result, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if policy.Algorithm == TokenBucket {
+		return l.allowTokenBucket(key, policy), nil
+	}
+	return l.allowSlidingWindow(key, policy), nil
+}
+
+func (l *memoryLimiter) allowSlidingWindow(key string, policy Policy) Result {
+	now := l.now()
+
+	// Drop requests that fell out of the window
+	requests := l.windows[key]
+	start := 0
+	for start < len(requests) && !requests[start].After(now.Add(-policy.Window)) {
+		start++
+	}
+	requests = requests[start:]
+
+	result := Result{Limit: policy.Limit}
+	if len(requests) < policy.Limit {
+		requests = append(requests, now)
+		result.Allowed = true
+	}
+	l.windows[key] = requests
+
+	result.Remaining = policy.Limit - len(requests)
+	if len(requests) > 0 {
+		result.ResetAfter = policy.Window - now.Sub(requests[0])
+	}
+	if !result.Allowed {
+		result.RetryAfter = result.ResetAfter
+	}
+	return result
+}
+
+func (l *memoryLimiter) allowTokenBucket(key string, policy Policy) Result {
+	now := l.now()
+	capacity := float64(policy.capacity())
+	rate := float64(policy.Limit) / float64(policy.Window) // tokens per nanosecond
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, ts: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = math.Min(capacity, b.tokens+float64(now.Sub(b.ts))*rate)
+	b.ts = now
+
+	result := Result{Limit: policy.capacity()}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration(math.Ceil((1 - b.tokens) / rate))
+	}
+
+	result.Remaining = int(math.Floor(b.tokens))
+	result.ResetAfter = time.Duration(math.Ceil((capacity - b.tokens) / rate))
+	return result
+}
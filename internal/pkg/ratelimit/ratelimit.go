@@ -0,0 +1,47 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Algorithm represents a rate limiting algorithm
+type Algorithm string
+
+const (
+	// SlidingWindow allows Limit requests in any rolling Window
+	SlidingWindow Algorithm = "sliding_window"
+	// TokenBucket refills Limit tokens per Window up to Burst tokens
+	TokenBucket Algorithm = "token_bucket"
+)
+
+// Policy describes how requests sharing a key are limited
+type Policy struct {
+	Name      string
+	Algorithm Algorithm
+	Limit     int           // Requests allowed per window
+	Window    time.Duration // Length of the window
+	Burst     int           // Token bucket capacity, defaults to Limit
+}
+
+// capacity returns the maximum number of requests allowed at once
+func (p Policy) capacity() int {
+	if p.Algorithm == TokenBucket && p.Burst > 0 {
+		return p.Burst
+	}
+	return p.Limit
+}
+
+// Result describes the outcome of a rate limit check
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration // How long to wait before the next request is allowed
+	ResetAfter time.Duration // How long until the limit is fully replenished
+}
+
+// Limiter checks requests against a policy
+type Limiter interface {
+	Allow(ctx context.Context, key string, policy Policy) (Result, error)
+}
@@ -0,0 +1,130 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+	"xyz-multifinance/internal/pkg/redis"
+)
+
+// slidingWindowScript keeps a sorted set of request timestamps and admits a
+// request only if fewer than ARGV[2] remain in the window.
+// Returns {allowed, remaining, retry_after_ms, reset_after_ms}.
+const slidingWindowScript = `
+	local t = redis.call("time")
+	local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+	local window = tonumber(ARGV[1])
+	local limit = tonumber(ARGV[2])
+
+	redis.call("zremrangebyscore", KEYS[1], 0, now - window)
+	local count = redis.call("zcard", KEYS[1])
+
+	local allowed = 0
+	if count < limit then
+		redis.call("zadd", KEYS[1], now, ARGV[3])
+		redis.call("pexpire", KEYS[1], window)
+		count = count + 1
+		allowed = 1
+	end
+
+	local reset = 0
+	local oldest = redis.call("zrange", KEYS[1], 0, 0, "WITHSCORES")
+	if oldest[2] then
+		reset = window - (now - tonumber(oldest[2]))
+	end
+
+	local retry = 0
+	if allowed == 0 then
+		retry = reset
+	end
+
+	return {allowed, limit - count, retry, reset}
+`
+
+// tokenBucketScript refills tokens at ARGV[1] tokens per millisecond up to
+// ARGV[2] and takes one token per request.
+// Returns {allowed, remaining, retry_after_ms, reset_after_ms}.
+const tokenBucketScript = `
+	local t = redis.call("time")
+	local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+	local rate = tonumber(ARGV[1])
+	local capacity = tonumber(ARGV[2])
+
+	local data = redis.call("hmget", KEYS[1], "tokens", "ts")
+	local tokens = tonumber(data[1])
+	local ts = tonumber(data[2])
+	if tokens == nil then
+		tokens = capacity
+		ts = now
+	end
+
+	tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate)
+
+	local allowed = 0
+	local retry = 0
+	if tokens >= 1 then
+		tokens = tokens - 1
+		allowed = 1
+	else
+		retry = math.ceil((1 - tokens) / rate)
+	end
+
+	redis.call("hset", KEYS[1], "tokens", tostring(tokens), "ts", now)
+	redis.call("pexpire", KEYS[1], math.ceil(capacity / rate))
+
+	return {allowed, math.floor(tokens), retry, math.ceil((capacity - tokens) / rate)}
+`
+
+type redisLimiter struct {
+	client redis.RedisClient
+}
+
+// NewRedisLimiter creates a limiter whose state is shared through Redis.
+// Each check runs as a single Lua script so concurrent requests are atomic.
+func NewRedisLimiter(client redis.RedisClient) Limiter {
+	return &redisLimiter{
+		client: client,
+	}
+}
+
+// Allow implements Limiter.Allow
+func (l *redisLimiter) Allow(ctx context.Context, key string, policy Policy) (Result, error) {
+	var values []int64
+	var err error
+
+	switch policy.Algorithm {
+	case TokenBucket:
+		rate := float64(policy.Limit) / float64(policy.Window.Milliseconds())
+		values, err = l.client.Eval(ctx, tokenBucketScript, []string{key},
+			fmt.Sprintf("%g", rate), policy.capacity(),
+		).Int64Slice()
+	default:
+		values, err = l.client.Eval(ctx, slidingWindowScript, []string{key},
+			policy.Window.Milliseconds(), policy.Limit, newMember(),
+		).Int64Slice()
+	}
+	if err != nil {
+		return Result{}, fmt.Errorf("rate limit check failed: %w", err)
+	}
+	if len(values) != 4 {
+		return Result{}, fmt.Errorf("rate limit check failed: unexpected reply %v", values)
+	}
+
+	return Result{
+		Allowed:    values[0] == 1,
+		Limit:      policy.capacity(),
+		Remaining:  int(values[1]),
+		RetryAfter: time.Duration(values[2]) * time.Millisecond,
+		ResetAfter: time.Duration(values[3]) * time.Millisecond,
+	}, nil
+}
+
+// newMember returns a unique sorted set member so concurrent requests in the
+// same millisecond are counted separately
+func newMember() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
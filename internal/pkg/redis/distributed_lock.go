@@ -2,79 +2,340 @@ package redis
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	mathrand "math/rand"
+	"sync"
 	"time"
 )
 
-// DistributedLock represents a distributed lock implementation using Redis
-type DistributedLock struct {
-	client RedisClient
-	key    string
-	value  string
-	ttl    time.Duration
-}
-
-// NewDistributedLock creates a new distributed lock instance
-func NewDistributedLock(client RedisClient, key string, ttl time.Duration) *DistributedLock {
-	return &DistributedLock{
-		client: client,
-		key:    fmt.Sprintf("lock:%s", key),
-		value:  fmt.Sprintf("%d", time.Now().UnixNano()),
-		ttl:    ttl,
+var (
+	// ErrLockNotAcquired is returned when the lock is held by another owner
+	ErrLockNotAcquired = errors.New("lock already held")
+	// ErrLockNotHeld is returned when releasing or extending a lock we no longer own
+	ErrLockNotHeld = errors.New("lock not held or expired")
+)
+
+// acquireScript sets the lock only if it is free and returns a new fencing
+// token above the floor in ARGV[3], or 0 when the lock is held by someone else
+const acquireScript = `
+	if redis.call("set", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+		local floor = tonumber(ARGV[3])
+		if floor > tonumber(redis.call("get", KEYS[2]) or "0") then
+			redis.call("set", KEYS[2], floor)
+		end
+		return redis.call("incr", KEYS[2])
+	else
+		return 0
+	end
+`
+
+// grantScript sets the lock only if it is free and returns 1, or 0 when the
+// lock is held by someone else. Nodes besides the first do not issue tokens.
+const grantScript = `
+	if redis.call("set", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+		return 1
+	else
+		return 0
+	end
+`
+
+// releaseScript deletes the lock only if we still own it
+const releaseScript = `
+	if redis.call("get", KEYS[1]) == ARGV[1] then
+		return redis.call("del", KEYS[1])
+	else
+		return 0
+	end
+`
+
+// extendScript resets the lock TTL only if we still own it
+const extendScript = `
+	if redis.call("get", KEYS[1]) == ARGV[1] then
+		return redis.call("pexpire", KEYS[1], ARGV[2])
+	else
+		return 0
+	end
+`
+
+// clockDriftFactor accounts for clock drift between Redis nodes
+const clockDriftFactor = 0.01
+
+// LockOption configures a DistributedLock
+type LockOption func(*DistributedLock)
+
+// WithAutoRenew starts a watchdog that extends the lock TTL every third of
+// the TTL for as long as the lock is held
+func WithAutoRenew() LockOption {
+	return func(dl *DistributedLock) {
+		dl.autoRenew = true
 	}
 }
 
-// Lock attempts to acquire the lock
-func (dl *DistributedLock) Lock(ctx context.Context) error {
-	// Try to set the lock key with NX option (only if it doesn't exist)
-	result, err := dl.client.SetNX(ctx, dl.key, dl.value, dl.ttl).Result()
-	if err != nil {
-		return fmt.Errorf("failed to acquire lock: %v", err)
+// WithRetryBackoff sets the bounds of the jittered exponential backoff used by TryLock
+func WithRetryBackoff(min, max time.Duration) LockOption {
+	return func(dl *DistributedLock) {
+		dl.minBackoff = min
+		dl.maxBackoff = max
 	}
-	if !result {
-		return fmt.Errorf("lock already held")
+}
+
+// WithFenceFloor makes the fencing token issued on acquisition greater than
+// floor, the highest token already written by the guarded resource. Tokens
+// then keep increasing when the counter is lost in a Redis restart or failover.
+func WithFenceFloor(floor int64) LockOption {
+	return func(dl *DistributedLock) {
+		dl.fenceFloor = floor
 	}
-	return nil
 }
 
-// Unlock releases the lock
-func (dl *DistributedLock) Unlock(ctx context.Context) error {
-	// Use Lua script to ensure we only delete our own lock
-	script := `
-		if redis.call("get", KEYS[1]) == ARGV[1] then
-			return redis.call("del", KEYS[1])
-		else
-			return 0
-		end
-	`
+// DistributedLock represents a distributed lock implementation using Redis.
+// With more than one client it follows the Redlock algorithm and is held
+// once a majority of the nodes granted it. Fencing tokens are only issued by
+// the first node, which must be among them: counters on separate nodes would
+// not order the owners served by different majorities.
+type DistributedLock struct {
+	clients    []RedisClient
+	key        string
+	fenceKey   string
+	fenceFloor int64
+	value      string
+	ttl        time.Duration
+	autoRenew  bool
+	minBackoff time.Duration
+	maxBackoff time.Duration
+
+	mu           sync.Mutex
+	fencingToken int64
+	stopRenew    context.CancelFunc
+	renewDone    chan struct{}
+	lost         chan struct{}
+}
 
-	result, err := dl.client.Eval(ctx, script, []string{dl.key}, dl.value).Int64()
-	if err != nil {
-		return fmt.Errorf("failed to release lock: %v", err)
+// NewDistributedLock creates a new distributed lock instance on a single Redis node
+func NewDistributedLock(client RedisClient, key string, ttl time.Duration, opts ...LockOption) *DistributedLock {
+	return NewRedlock([]RedisClient{client}, key, ttl, opts...)
+}
+
+// NewRedlock creates a new distributed lock instance held by quorum across
+// independent Redis nodes
+func NewRedlock(clients []RedisClient, key string, ttl time.Duration, opts ...LockOption) *DistributedLock {
+	dl := &DistributedLock{
+		clients:    clients,
+		key:        fmt.Sprintf("lock:%s", key),
+		fenceKey:   fmt.Sprintf("fence:%s", key),
+		value:      newOwnerToken(),
+		ttl:        ttl,
+		minBackoff: 50 * time.Millisecond,
+		maxBackoff: time.Second,
+	}
+	for _, opt := range opts {
+		opt(dl)
+	}
+	return dl
+}
+
+// newOwnerToken returns a random value identifying this lock owner
+func newOwnerToken() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("failed to generate lock owner token: %v", err))
+	}
+	return hex.EncodeToString(b)
+}
+
+// quorum returns the number of nodes that must grant the lock
+func (dl *DistributedLock) quorum() int {
+	return len(dl.clients)/2 + 1
+}
+
+// Lock attempts to acquire the lock once. It returns ErrLockNotAcquired when
+// the lock is held by another owner, or the errors of the nodes that could
+// not be reached when they kept the quorum from being reached.
+func (dl *DistributedLock) Lock(ctx context.Context) error {
+	start := time.Now()
+
+	acquired := 0
+	var fencingToken int64
+	var errs []error
+	for i, client := range dl.clients {
+		script, keys, args := grantScript, []string{dl.key}, []interface{}{dl.value, dl.ttl.Milliseconds()}
+		if i == 0 {
+			script, keys, args = acquireScript, []string{dl.key, dl.fenceKey}, append(args, dl.fenceFloor)
+		}
+		result, err := client.Eval(ctx, script, keys, args...).Int64()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if result == 0 {
+			continue
+		}
+		acquired++
+		if i == 0 {
+			fencingToken = result
+		}
 	}
-	if result == 0 {
-		return fmt.Errorf("lock not held or expired")
+
+	// The lock is only valid if the quorum, the token issuer among it, was
+	// reached before it expired
+	drift := time.Duration(float64(dl.ttl)*clockDriftFactor) + 2*time.Millisecond
+	validity := dl.ttl - time.Since(start) - drift
+	if acquired < dl.quorum() || fencingToken == 0 || validity <= 0 {
+		dl.release(ctx)
+		if len(errs) > 0 {
+			return fmt.Errorf("lock nodes unavailable: %w", errors.Join(errs...))
+		}
+		return ErrLockNotAcquired
+	}
+
+	dl.mu.Lock()
+	dl.fencingToken = fencingToken
+	dl.lost = make(chan struct{})
+	dl.mu.Unlock()
+
+	if dl.autoRenew {
+		dl.startWatchdog()
 	}
 	return nil
 }
 
-// TryLock attempts to acquire the lock with timeout
+// TryLock attempts to acquire the lock until the timeout elapses or the
+// context is cancelled, backing off with jitter between attempts. The error
+// of the last attempt is wrapped with the context error.
 func (dl *DistributedLock) TryLock(ctx context.Context, timeout time.Duration) error {
-	deadline := time.Now().Add(timeout)
-	for time.Now().Before(deadline) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	backoff := dl.minBackoff
+	for {
 		err := dl.Lock(ctx)
 		if err == nil {
 			return nil
 		}
-		// Wait a bit before retrying
-		time.Sleep(100 * time.Millisecond)
+
+		// Full jitter between half and the whole backoff spreads out competing owners
+		sleep := backoff/2 + time.Duration(mathrand.Int63n(int64(backoff/2)+1))
+		timer := time.NewTimer(sleep)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("timeout acquiring lock: %w: %w", ctx.Err(), err)
+		case <-timer.C:
+		}
+
+		backoff *= 2
+		if backoff > dl.maxBackoff {
+			backoff = dl.maxBackoff
+		}
+	}
+}
+
+// Extend resets the lock TTL on a quorum of nodes
+func (dl *DistributedLock) Extend(ctx context.Context) error {
+	extended := 0
+	for _, client := range dl.clients {
+		result, err := client.Eval(ctx, extendScript, []string{dl.key}, dl.value, dl.ttl.Milliseconds()).Int64()
+		if err == nil && result == 1 {
+			extended++
+		}
+	}
+	if extended < dl.quorum() {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// Unlock releases the lock
+func (dl *DistributedLock) Unlock(ctx context.Context) error {
+	dl.stopWatchdog()
+
+	if released := dl.release(ctx); released < dl.quorum() {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// FencingToken returns the monotonically increasing token issued when the
+// lock was acquired. Writers pass it to storage so that writes from an
+// owner whose lock already expired are rejected.
+func (dl *DistributedLock) FencingToken() int64 {
+	dl.mu.Lock()
+	defer dl.mu.Unlock()
+	return dl.fencingToken
+}
+
+// Lost returns a channel that is closed when the watchdog fails to extend the lock
+func (dl *DistributedLock) Lost() <-chan struct{} {
+	dl.mu.Lock()
+	defer dl.mu.Unlock()
+	return dl.lost
+}
+
+// release deletes the lock on every node and returns how many nodes released it
+func (dl *DistributedLock) release(ctx context.Context) int {
+	released := 0
+	for _, client := range dl.clients {
+		result, err := client.Eval(ctx, releaseScript, []string{dl.key}, dl.value).Int64()
+		if err == nil && result == 1 {
+			released++
+		}
+	}
+	return released
+}
+
+// startWatchdog extends the lock periodically until it is released or lost
+func (dl *DistributedLock) startWatchdog() {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	dl.mu.Lock()
+	dl.stopRenew = cancel
+	dl.renewDone = done
+	lost := dl.lost
+	dl.mu.Unlock()
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(dl.ttl / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := dl.Extend(ctx); err != nil {
+					if ctx.Err() == nil {
+						close(lost)
+					}
+					return
+				}
+			}
+		}
+	}()
+}
+
+// stopWatchdog stops the watchdog and waits for it to exit
+func (dl *DistributedLock) stopWatchdog() {
+	dl.mu.Lock()
+	cancel, done := dl.stopRenew, dl.renewDone
+	dl.stopRenew, dl.renewDone = nil, nil
+	dl.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
 	}
-	return fmt.Errorf("timeout acquiring lock")
 }
 
 // Example usage:
-// lock := NewDistributedLock(redisClient, "transaction:123", 30*time.Second)
+// lock := NewDistributedLock(redisClient, "transaction:123", 30*time.Second, WithAutoRenew(), WithFenceFloor(installment.FencingToken))
 // if err := lock.TryLock(ctx, 5*time.Second); err != nil {
 //     return err
 // }
 // defer lock.Unlock(ctx)
+// installment.FencingToken = lock.FencingToken()
@@ -0,0 +1,60 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Job is a unit of periodic background work
+type Job func(ctx context.Context) error
+
+// Scheduler runs background jobs periodically until its context is cancelled
+type Scheduler struct {
+	logger *zap.Logger
+	wg     sync.WaitGroup
+}
+
+// New creates a new scheduler instance
+func New(logger *zap.Logger) *Scheduler {
+	return &Scheduler{
+		logger: logger,
+	}
+}
+
+// Every runs the job every interval in its own goroutine. Errors are logged
+// and the job is retried on the next tick.
+func (s *Scheduler) Every(ctx context.Context, name string, interval time.Duration, job Job) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := job(ctx); err != nil {
+					s.logger.Error("scheduled job failed", zap.String("job", name), zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// Wait blocks until all jobs have stopped
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+// Example usage:
+// sched := scheduler.New(logger)
+// sched.Every(ctx, "erasure", time.Hour, func(ctx context.Context) error {
+//     _, err := erasureUseCase.ProcessDueRequests(time.Now())
+//     return err
+// })
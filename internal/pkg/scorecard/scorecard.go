@@ -0,0 +1,197 @@
+package scorecard
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Bin awards points to attribute values in [Min, Max). A nil bound is open.
+type Bin struct {
+	Min    *float64 `yaml:"min" json:"min"`
+	Max    *float64 `yaml:"max" json:"max"`
+	Points int      `yaml:"points" json:"points"`
+}
+
+// contains reports whether value falls in the bin
+func (b Bin) contains(value float64) bool {
+	return (b.Min == nil || value >= *b.Min) && (b.Max == nil || value < *b.Max)
+}
+
+// Characteristic scores one attribute. Applicants losing points on it are
+// given its reason code.
+type Characteristic struct {
+	Attribute   string `yaml:"attribute" json:"attribute"`
+	ReasonCode  string `yaml:"reason_code" json:"reason_code"`
+	Description string `yaml:"description" json:"description"` // Explains the reason code to the applicant
+	Missing     int    `yaml:"missing" json:"missing"`         // Points when the attribute is unknown, e.g. no payment history
+	Bins        []Bin  `yaml:"bins" json:"bins"`
+}
+
+// maxPoints returns the most points the characteristic awards
+func (c *Characteristic) maxPoints() int {
+	max := c.Missing
+	for _, bin := range c.Bins {
+		if bin.Points > max {
+			max = bin.Points
+		}
+	}
+	return max
+}
+
+// points returns the points awarded to value, Missing when value is nil or
+// outside every bin
+func (c *Characteristic) points(value *float64) int {
+	if value == nil {
+		return c.Missing
+	}
+	for _, bin := range c.Bins {
+		if bin.contains(*value) {
+			return bin.Points
+		}
+	}
+	return c.Missing
+}
+
+// Cutoffs classify scores. Scores below Reject are rejected, scores below
+// Refer are referred to an operator.
+type Cutoffs struct {
+	Reject int `yaml:"reject" json:"reject"`
+	Refer  int `yaml:"refer" json:"refer"`
+}
+
+// Scorecard adds the points of every characteristic to a base score
+type Scorecard struct {
+	Name            string           `yaml:"name" json:"name"`
+	Version         string           `yaml:"version" json:"version"`
+	BaseScore       int              `yaml:"base_score" json:"base_score"`
+	Cutoffs         Cutoffs          `yaml:"cutoffs" json:"cutoffs"`
+	MaxReasons      int              `yaml:"max_reasons" json:"max_reasons"` // Reason codes returned, all when zero
+	Characteristics []Characteristic `yaml:"characteristics" json:"characteristics"`
+}
+
+// Points is the contribution of one characteristic to a score
+type Points struct {
+	Attribute   string
+	Value       *float64 // Nil when the attribute is unknown
+	Points      int
+	MaxPoints   int
+	ReasonCode  string
+	Description string
+}
+
+// Lost returns the points the applicant did not get
+func (p Points) Lost() int {
+	return p.MaxPoints - p.Points
+}
+
+// Result is a calculated score
+type Result struct {
+	Score           int
+	Characteristics []Points
+	// Reasons lists the characteristics that lost the most points, the
+	// largest loss first
+	Reasons []Points
+}
+
+// Validate checks that every characteristic is named, has a reason code and
+// bins with ordered bounds
+func (s *Scorecard) Validate() error {
+	if len(s.Characteristics) == 0 {
+		return fmt.Errorf("scorecard %q has no characteristics", s.Name)
+	}
+	if s.Cutoffs.Reject > s.Cutoffs.Refer {
+		return fmt.Errorf("scorecard %q reject cutoff exceeds refer cutoff", s.Name)
+	}
+	seen := make(map[string]bool, len(s.Characteristics))
+	for _, c := range s.Characteristics {
+		if c.Attribute == "" {
+			return fmt.Errorf("scorecard %q has a characteristic without attribute", s.Name)
+		}
+		if seen[c.Attribute] {
+			return fmt.Errorf("duplicate characteristic %q", c.Attribute)
+		}
+		seen[c.Attribute] = true
+		if c.ReasonCode == "" {
+			return fmt.Errorf("characteristic %q has no reason code", c.Attribute)
+		}
+		if len(c.Bins) == 0 {
+			return fmt.Errorf("characteristic %q has no bins", c.Attribute)
+		}
+		for _, bin := range c.Bins {
+			if bin.Min != nil && bin.Max != nil && *bin.Min >= *bin.Max {
+				return fmt.Errorf("characteristic %q has a bin with min not below max", c.Attribute)
+			}
+		}
+	}
+	return nil
+}
+
+// Score scores attributes. Attributes absent from the map are unknown.
+func (s *Scorecard) Score(attributes map[string]float64) Result {
+	result := Result{Score: s.BaseScore}
+	for i := range s.Characteristics {
+		c := &s.Characteristics[i]
+		var value *float64
+		if v, ok := attributes[c.Attribute]; ok && !math.IsNaN(v) {
+			value = &v
+		}
+		points := Points{
+			Attribute:   c.Attribute,
+			Value:       value,
+			Points:      c.points(value),
+			MaxPoints:   c.maxPoints(),
+			ReasonCode:  c.ReasonCode,
+			Description: c.Description,
+		}
+		result.Score += points.Points
+		result.Characteristics = append(result.Characteristics, points)
+	}
+
+	for _, points := range result.Characteristics {
+		if points.Lost() > 0 {
+			result.Reasons = append(result.Reasons, points)
+		}
+	}
+	sort.SliceStable(result.Reasons, func(i, j int) bool {
+		return result.Reasons[i].Lost() > result.Reasons[j].Lost()
+	})
+	if s.MaxReasons > 0 && len(result.Reasons) > s.MaxReasons {
+		result.Reasons = result.Reasons[:s.MaxReasons]
+	}
+	return result
+}
+
+// Parse builds a scorecard from YAML, or from JSON when format is "json"
+func Parse(data []byte, format string) (*Scorecard, error) {
+	var s Scorecard
+	var err error
+	if format == "json" {
+		err = json.Unmarshal(data, &s)
+	} else {
+		err = yaml.Unmarshal(data, &s)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse scorecard: %w", err)
+	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// LoadFile builds a scorecard from a YAML or, given a .json extension, JSON
+// file
+func LoadFile(path string) (*Scorecard, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scorecard: %w", err)
+	}
+	return Parse(data, strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), "."))
+}
@@ -0,0 +1,73 @@
+package signing
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+	"xyz-multifinance/internal/pkg/redis"
+)
+
+// Headers carrying a signed request
+const (
+	HeaderKeyID     = "X-API-Key"
+	HeaderTimestamp = "X-Timestamp" // Unix seconds
+	HeaderNonce     = "X-Nonce"
+	HeaderSignature = "X-Signature" // Hex encoded HMAC-SHA256
+)
+
+// StringToSign builds the canonical message signed by the client:
+//
+//	METHOD\nREQUEST_URI\nTIMESTAMP\nNONCE\nHEX(SHA256(BODY))
+func StringToSign(method, requestURI, timestamp, nonce string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	return strings.Join([]string{
+		strings.ToUpper(method),
+		requestURI,
+		timestamp,
+		nonce,
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n")
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of message
+func Sign(secret []byte, message string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(message))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is the HMAC-SHA256 of message, in constant time
+func Verify(secret []byte, message, signature string) bool {
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(message))
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// NonceStore remembers nonces to reject replayed requests
+type NonceStore interface {
+	// Reserve records key for ttl and reports false if it was already seen
+	Reserve(ctx context.Context, key string, ttl time.Duration) (bool, error)
+}
+
+type redisNonceStore struct {
+	client redis.RedisClient
+}
+
+// NewRedisNonceStore creates a new instance of NonceStore backed by Redis
+func NewRedisNonceStore(client redis.RedisClient) NonceStore {
+	return &redisNonceStore{
+		client: client,
+	}
+}
+
+// Reserve implements NonceStore.Reserve
+func (s *redisNonceStore) Reserve(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	return s.client.SetNX(ctx, "nonce:"+key, 1, ttl).Result()
+}
@@ -0,0 +1,249 @@
+// Package slik encodes the debtor and facility segments reported to the OJK
+// financial information service (SLIK), as fixed-width text, CSV or XML
+package slik
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+	"xyz-multifinance/internal/domain"
+)
+
+// dateLayout formats the dates of every format
+const dateLayout = "20060102"
+
+// Header identifies the file of a segment
+type Header struct {
+	ReporterCode string // Code of the reporting institution assigned by OJK
+	Period       string // YYYY-MM
+	Segment      domain.ReportSegment
+	Records      int
+}
+
+// column is a field of a segment. Fixed-width files pad text to the right
+// with spaces and numbers to the left with zeros.
+type column struct {
+	name    string
+	width   int
+	numeric bool
+}
+
+var debtorColumns = []column{
+	{name: "cif", width: 20},
+	{name: "nik", width: 16},
+	{name: "legal_name", width: 100},
+	{name: "full_name", width: 100},
+	{name: "place_of_birth", width: 50},
+	{name: "date_of_birth", width: 8},
+	{name: "phone", width: 20},
+	{name: "region", width: 50},
+}
+
+var facilityColumns = []column{
+	{name: "account_number", width: 25},
+	{name: "cif", width: 20},
+	{name: "start_date", width: 8},
+	{name: "maturity_date", width: 8},
+	{name: "plafond", width: 15, numeric: true},
+	{name: "outstanding", width: 15, numeric: true},
+	{name: "arrears_principal", width: 15, numeric: true},
+	{name: "arrears_interest", width: 15, numeric: true},
+	{name: "dpd", width: 4, numeric: true},
+	{name: "collectibility", width: 1, numeric: true},
+	{name: "restructured", width: 1},
+	{name: "condition", width: 2},
+	{name: "condition_date", width: 8},
+	{name: "written_off_balance", width: 15, numeric: true},
+}
+
+// FileName returns the name of the file of a segment, e.g.
+// D01.123456.202406.txt
+func FileName(header Header, format domain.ReportFormat) string {
+	extension := "txt"
+	switch format {
+	case domain.ReportCSV:
+		extension = "csv"
+	case domain.ReportXML:
+		extension = "xml"
+	}
+	return fmt.Sprintf("%s.%s.%s.%s", header.Segment, header.ReporterCode, strings.ReplaceAll(header.Period, "-", ""), extension)
+}
+
+// EncodeDebtors writes the debtor segment in the given format
+func EncodeDebtors(w io.Writer, format domain.ReportFormat, header Header, debtors []domain.DebtorRecord) error {
+	rows := make([][]string, len(debtors))
+	for i, d := range debtors {
+		rows[i] = []string{
+			d.CIF,
+			d.NIK,
+			d.LegalName,
+			d.FullName,
+			d.PlaceOfBirth,
+			formatDate(&d.DateOfBirth),
+			d.Phone,
+			d.Region,
+		}
+	}
+	return encode(w, format, header, debtorColumns, rows)
+}
+
+// EncodeFacilities writes the facility segment in the given format. Amounts
+// are reported in whole rupiah.
+func EncodeFacilities(w io.Writer, format domain.ReportFormat, header Header, facilities []domain.FacilityRecord) error {
+	rows := make([][]string, len(facilities))
+	for i, f := range facilities {
+		restructured := "N"
+		if f.Restructured {
+			restructured = "Y"
+		}
+		rows[i] = []string{
+			f.AccountNumber,
+			f.CIF,
+			formatDate(&f.StartDate),
+			formatDate(&f.MaturityDate),
+			formatAmount(f.Plafond),
+			formatAmount(f.Outstanding),
+			formatAmount(f.ArrearsPrincipal),
+			formatAmount(f.ArrearsInterest),
+			strconv.Itoa(f.DPD),
+			strconv.Itoa(int(f.Collectibility)),
+			restructured,
+			string(f.Condition),
+			formatDate(f.ConditionDate),
+			formatAmount(f.WrittenOffBalance),
+		}
+	}
+	return encode(w, format, header, facilityColumns, rows)
+}
+
+func encode(w io.Writer, format domain.ReportFormat, header Header, columns []column, rows [][]string) error {
+	header.Records = len(rows)
+	switch format {
+	case domain.ReportFixedWidth:
+		return encodeFixedWidth(w, header, columns, rows)
+	case domain.ReportCSV:
+		return encodeCSV(w, columns, rows)
+	case domain.ReportXML:
+		return encodeXML(w, header, columns, rows)
+	default:
+		return fmt.Errorf("unsupported report format %q", format)
+	}
+}
+
+// encodeFixedWidth writes a header line H, then one line D per record
+func encodeFixedWidth(w io.Writer, header Header, columns []column, rows [][]string) error {
+	line := "H" +
+		pad(header.ReporterCode, 10, false) +
+		pad(strings.ReplaceAll(header.Period, "-", ""), 6, false) +
+		pad(string(header.Segment), 3, false) +
+		pad(strconv.Itoa(header.Records), 10, true)
+	if _, err := io.WriteString(w, line+"\r\n"); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		var b strings.Builder
+		b.WriteString("D")
+		for i, col := range columns {
+			b.WriteString(pad(row[i], col.width, col.numeric))
+		}
+		b.WriteString("\r\n")
+		if _, err := io.WriteString(w, b.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeCSV writes a header row naming the columns, then one row per record
+func encodeCSV(w io.Writer, columns []column, rows [][]string) error {
+	writer := csv.NewWriter(w)
+	names := make([]string, len(columns))
+	for i, col := range columns {
+		names[i] = col.name
+	}
+	if err := writer.Write(names); err != nil {
+		return err
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+// encodeXML writes a Report element carrying the header as attributes, with
+// one Record element per record
+func encodeXML(w io.Writer, header Header, columns []column, rows [][]string) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+
+	report := xml.StartElement{
+		Name: xml.Name{Local: "Report"},
+		Attr: []xml.Attr{
+			{Name: xml.Name{Local: "reporter"}, Value: header.ReporterCode},
+			{Name: xml.Name{Local: "period"}, Value: header.Period},
+			{Name: xml.Name{Local: "segment"}, Value: string(header.Segment)},
+			{Name: xml.Name{Local: "records"}, Value: strconv.Itoa(header.Records)},
+		},
+	}
+	if err := encoder.EncodeToken(report); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		record := xml.StartElement{Name: xml.Name{Local: "Record"}}
+		if err := encoder.EncodeToken(record); err != nil {
+			return err
+		}
+		for i, col := range columns {
+			if err := encoder.EncodeElement(row[i], xml.StartElement{Name: xml.Name{Local: col.name}}); err != nil {
+				return err
+			}
+		}
+		if err := encoder.EncodeToken(record.End()); err != nil {
+			return err
+		}
+	}
+	if err := encoder.EncodeToken(report.End()); err != nil {
+		return err
+	}
+	return encoder.Flush()
+}
+
+// pad fits s to width characters, truncating longer values
+func pad(s string, width int, numeric bool) string {
+	s = strings.Map(func(r rune) rune {
+		if r == '\r' || r == '\n' {
+			return ' '
+		}
+		return r
+	}, s)
+	if n := utf8.RuneCountInString(s); n > width {
+		return string([]rune(s)[:width])
+	} else if n < width {
+		if numeric {
+			return strings.Repeat("0", width-n) + s
+		}
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
+
+func formatDate(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.Format(dateLayout)
+}
+
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(math.Round(amount), 'f', 0, 64)
+}
@@ -0,0 +1,198 @@
+// Package statement parses bank statements listing credits to virtual
+// accounts, in CSV or MT940 format
+package statement
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"xyz-multifinance/internal/domain"
+)
+
+// Parse reads the credits listed in a statement of the given format
+func Parse(format domain.StatementFormat, r io.Reader) ([]domain.StatementEntry, error) {
+	switch format {
+	case domain.StatementCSV:
+		return ParseCSV(r)
+	case domain.StatementMT940:
+		return ParseMT940(r)
+	default:
+		return nil, fmt.Errorf("unsupported statement format %q", format)
+	}
+}
+
+// ParseCSV reads a CSV statement with a header row naming the columns date,
+// reference, va_number and amount, in any order. Rows with a type column
+// other than C (credit) are skipped.
+func ParseCSV(r io.Reader) ([]domain.StatementEntry, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read statement header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"date", "reference", "va_number", "amount"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("statement has no %s column", name)
+		}
+	}
+	typeColumn, hasType := columns["type"]
+
+	var entries []domain.StatementEntry
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		field := func(i int) string {
+			if i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		if hasType && !strings.EqualFold(field(typeColumn), "C") {
+			continue
+		}
+
+		date, err := parseDate(field(columns["date"]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		amount, err := strconv.ParseFloat(field(columns["amount"]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid amount %q", line, field(columns["amount"]))
+		}
+		entries = append(entries, domain.StatementEntry{
+			Date:      date,
+			Reference: field(columns["reference"]),
+			Number:    field(columns["va_number"]),
+			Amount:    amount,
+		})
+	}
+}
+
+var (
+	// statementLine matches the :61: field, YYMMDD[MMDD] mark [funds code] amount type reference[//bank reference]
+	statementLine = regexp.MustCompile(`^(\d{6})(?:\d{4})?(RC|RD|C|D)[A-Z]?(\d+,\d*)[A-Z][A-Z0-9]{3}([^/]*)(?://(.*))?$`)
+	// vaNumber finds the virtual account number in the :86: information field
+	vaNumber = regexp.MustCompile(`\d{10,20}`)
+)
+
+// ParseMT940 reads the credits of an MT940 statement. The reference is the
+// customer reference of the :61: field, or the bank reference when the
+// customer reference is NONREF, and the virtual account number is the first
+// 10 to 20 digit number of the following :86: field.
+func ParseMT940(r io.Reader) ([]domain.StatementEntry, error) {
+	var entries []domain.StatementEntry
+	var current *domain.StatementEntry
+	var field string
+
+	flush := func() error {
+		if current == nil {
+			return nil
+		}
+		if current.Number == "" {
+			return fmt.Errorf("statement line %s has no virtual account number", current.Reference)
+		}
+		entries = append(entries, *current)
+		current = nil
+		return nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if strings.HasPrefix(text, ":") {
+			end := strings.Index(text[1:], ":")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: malformed field", line)
+			}
+			field, text = text[1:end+1], text[end+2:]
+
+			switch field {
+			case "61":
+				if err := flush(); err != nil {
+					return nil, err
+				}
+				entry, credit, err := parseStatementLine(text)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %w", line, err)
+				}
+				if credit {
+					current = entry
+				}
+				continue
+			case "86":
+			default:
+				if err := flush(); err != nil {
+					return nil, err
+				}
+				continue
+			}
+		}
+
+		// Information field, possibly continued over several lines
+		if field == "86" && current != nil && current.Number == "" {
+			current.Number = vaNumber.FindString(text)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// parseStatementLine parses a :61: field, reporting whether it is a credit
+func parseStatementLine(text string) (*domain.StatementEntry, bool, error) {
+	match := statementLine.FindStringSubmatch(strings.TrimSpace(text))
+	if match == nil {
+		return nil, false, fmt.Errorf("malformed statement line %q", text)
+	}
+
+	date, err := time.Parse("060102", match[1])
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid value date %q", match[1])
+	}
+	amount, err := strconv.ParseFloat(strings.Replace(match[3], ",", ".", 1), 64)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid amount %q", match[3])
+	}
+	reference := strings.TrimSpace(match[4])
+	if reference == "" || reference == "NONREF" {
+		reference = strings.TrimSpace(match[5])
+	}
+
+	return &domain.StatementEntry{
+		Date:      date,
+		Reference: reference,
+		Amount:    amount,
+	}, match[2] == "C", nil
+}
+
+// parseDate accepts a date with or without a time of day
+func parseDate(value string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02", "2006-01-02 15:04:05", time.RFC3339} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", value)
+}
@@ -0,0 +1,66 @@
+package waf
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// maxJSONDepth bounds how deep JSON bodies are walked
+const maxJSONDepth = 32
+
+// ErrJSONTooDeep is returned when a JSON document is nested deeper than the
+// fields are walked
+var ErrJSONTooDeep = fmt.Errorf("json body nested deeper than %d levels", maxJSONDepth)
+
+// JSONFields flattens the string values and object keys of a JSON document
+// into fields named by their dotted path, e.g. "items.0.name". On
+// ErrJSONTooDeep the fields collected before the limit are still returned.
+func JSONFields(data []byte) ([]Field, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return nil, fmt.Errorf("invalid json body: %w", err)
+	}
+
+	var fields []Field
+	err := walkJSON("", document, 0, &fields)
+	return fields, err
+}
+
+func walkJSON(path string, value interface{}, depth int, fields *[]Field) error {
+	if depth > maxJSONDepth {
+		return ErrJSONTooDeep
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			childPath := joinPath(path, key)
+			// Keys are attacker controlled too
+			*fields = append(*fields, Field{Target: TargetJSON, Name: childPath, Value: key})
+			if err := walkJSON(childPath, child, depth+1, fields); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for i, child := range v {
+			if err := walkJSON(joinPath(path, strconv.Itoa(i)), child, depth+1, fields); err != nil {
+				return err
+			}
+		}
+	case string:
+		*fields = append(*fields, Field{Target: TargetJSON, Name: path, Value: v})
+	}
+	return nil
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
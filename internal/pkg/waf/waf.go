@@ -0,0 +1,203 @@
+package waf
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Mode controls what happens when a request matches a rule
+type Mode string
+
+const (
+	// ModeDetect logs matches and lets the request through
+	ModeDetect Mode = "detect"
+	// ModeBlock logs matches and rejects the request
+	ModeBlock Mode = "block"
+)
+
+// Target is the part of the request a rule inspects
+type Target string
+
+const (
+	TargetQuery  Target = "query"
+	TargetForm   Target = "form"
+	TargetJSON   Target = "json"
+	TargetHeader Target = "header"
+	TargetPath   Target = "path"
+)
+
+// Rule describes a single pattern to look for in request input
+type Rule struct {
+	ID          string   `yaml:"id"`
+	Description string   `yaml:"description"`
+	Severity    string   `yaml:"severity"`
+	Targets     []Target `yaml:"targets"`
+	Pattern     string   `yaml:"pattern"`
+
+	regexp *regexp.Regexp
+}
+
+// appliesTo reports whether the rule inspects the given target
+func (r *Rule) appliesTo(target Target) bool {
+	if len(r.Targets) == 0 {
+		return true
+	}
+	for _, t := range r.Targets {
+		if t == target {
+			return true
+		}
+	}
+	return false
+}
+
+// Allowlist skips rules for fields of a route that legitimately carry
+// input resembling an attack, e.g. free-text notes
+type Allowlist struct {
+	Route  string   `yaml:"route"`  // Route prefix as registered with the router
+	Rules  []string `yaml:"rules"`  // Rule IDs to skip, all rules when empty
+	Fields []string `yaml:"fields"` // Fields to skip such as "json:notes", all fields when empty
+}
+
+// Field is a single input value extracted from a request
+type Field struct {
+	Target Target
+	Name   string // Query/form key, header name or dotted JSON path
+	Value  string
+}
+
+// Key returns the field identifier used by allowlists
+func (f Field) Key() string {
+	return fmt.Sprintf("%s:%s", f.Target, f.Name)
+}
+
+// Match describes a rule that matched a request field
+type Match struct {
+	RuleID   string
+	Severity string
+	Field    string
+	Value    string // Matched fragment, truncated. Attacker controlled, never logged as is
+}
+
+// maxMatchValue bounds the matched fragment kept in a Match
+const maxMatchValue = 64
+
+// Engine evaluates request fields against a rule set
+type Engine struct {
+	rules      []*Rule
+	allowlists []Allowlist
+}
+
+// RuleSet is the YAML representation of an engine's configuration
+type RuleSet struct {
+	Rules      []*Rule     `yaml:"rules"`
+	Allowlists []Allowlist `yaml:"allowlists"`
+}
+
+// NewEngine compiles the rules of a rule set
+func NewEngine(ruleSet RuleSet) (*Engine, error) {
+	seen := make(map[string]bool, len(ruleSet.Rules))
+	for _, rule := range ruleSet.Rules {
+		if rule.ID == "" {
+			return nil, fmt.Errorf("waf rule without id")
+		}
+		if seen[rule.ID] {
+			return nil, fmt.Errorf("duplicate waf rule %q", rule.ID)
+		}
+		seen[rule.ID] = true
+
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern for waf rule %q: %w", rule.ID, err)
+		}
+		rule.regexp = re
+	}
+
+	return &Engine{
+		rules:      ruleSet.Rules,
+		allowlists: ruleSet.Allowlists,
+	}, nil
+}
+
+// Parse builds an engine from a YAML rule set
+func Parse(data []byte) (*Engine, error) {
+	var ruleSet RuleSet
+	if err := yaml.Unmarshal(data, &ruleSet); err != nil {
+		return nil, fmt.Errorf("failed to parse waf rules: %w", err)
+	}
+	return NewEngine(ruleSet)
+}
+
+// LoadFile builds an engine from a YAML rule file
+func LoadFile(path string) (*Engine, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read waf rules: %w", err)
+	}
+	return Parse(data)
+}
+
+// Inspect returns the rules matched by the fields of a request to route
+func (e *Engine) Inspect(route string, fields []Field) []Match {
+	var matches []Match
+	for _, field := range fields {
+		for _, rule := range e.rules {
+			if !rule.appliesTo(field.Target) || e.allowed(route, rule.ID, field.Key()) {
+				continue
+			}
+
+			loc := rule.regexp.FindStringIndex(field.Value)
+			if loc == nil {
+				continue
+			}
+			fragment := field.Value[loc[0]:loc[1]]
+			if len(fragment) > maxMatchValue {
+				fragment = fragment[:maxMatchValue]
+			}
+
+			matches = append(matches, Match{
+				RuleID:   rule.ID,
+				Severity: rule.Severity,
+				Field:    field.Key(),
+				Value:    fragment,
+			})
+		}
+	}
+	return matches
+}
+
+// allowed reports whether an allowlist exempts the field of route from rule
+func (e *Engine) allowed(route, ruleID, field string) bool {
+	for _, allowlist := range e.allowlists {
+		if !hasRoutePrefix(route, allowlist.Route) {
+			continue
+		}
+		if contains(allowlist.Rules, ruleID) && contains(allowlist.Fields, field) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasRoutePrefix matches route against prefix on path segment boundaries
+func hasRoutePrefix(route, prefix string) bool {
+	if len(route) < len(prefix) || route[:len(prefix)] != prefix {
+		return false
+	}
+	return len(route) == len(prefix) || prefix[len(prefix)-1] == '/' || route[len(prefix)] == '/'
+}
+
+// contains reports whether values contains value, treating an empty list as a wildcard
+func contains(values []string, value string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
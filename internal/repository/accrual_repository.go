@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"xyz-multifinance/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type accrualRepository struct {
+	db *gorm.DB
+}
+
+// NewAccrualRepository creates a new instance of AccrualRepository
+func NewAccrualRepository(db *gorm.DB) domain.AccrualRepository {
+	return &accrualRepository{
+		db: db,
+	}
+}
+
+// ListAccruing implements AccrualRepository.ListAccruing
+func (r *accrualRepository) ListAccruing(afterTransactionID uint, limit int) ([]uint, error) {
+	var ids []uint
+	err := r.db.Raw(`SELECT DISTINCT t."id"
+		FROM "transactions" t
+		JOIN "installments" i ON i."transaction_id" = t."id"
+		WHERE t."status" = ? AND t."deleted_at" IS NULL AND t."id" > ?
+			AND i."status" IN (?,?,?) AND i."accrued_interest" < i."interest"
+		ORDER BY t."id"
+		LIMIT ?`,
+		domain.StatusApproved, afterTransactionID, "paid", "unpaid", "overdue", limit,
+	).Scan(&ids).Error
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// Accrue implements AccrualRepository.Accrue. An installment whose accrued
+// interest changed since it was read fails the accrual as a whole.
+func (r *accrualRepository) Accrue(accrual *domain.InterestAccrual) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(accrual)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.NewError(domain.ErrConflict, "interest_accrued", "contract interest is accrued for the day already")
+		}
+
+		for _, installment := range accrual.Accrued {
+			result := tx.Exec(`UPDATE "installments" SET "accrued_interest"=? WHERE "id"=? AND "accrued_interest"=?`,
+				installment.Accrued, installment.InstallmentID, installment.Previous,
+			)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return errConcurrentModification()
+			}
+		}
+
+		if accrual.Entry == nil {
+			return nil
+		}
+		entries := []domain.JournalEntry{*accrual.Entry}
+		if err := writeJournal(tx, entries); err != nil {
+			return err
+		}
+		*accrual.Entry = entries[0]
+		return nil
+	})
+}
+
+// ListAccruals implements AccrualRepository.ListAccruals, latest first
+func (r *accrualRepository) ListAccruals(transactionID uint, offset, limit int) ([]domain.InterestAccrual, error) {
+	var accruals []domain.InterestAccrual
+	err := r.db.Where("transaction_id = ?", transactionID).
+		Order("accrual_date desc").Offset(offset).Limit(limit).Find(&accruals).Error
+	if err != nil {
+		return nil, err
+	}
+	return accruals, nil
+}
+
+// Portfolio implements AccrualRepository.Portfolio
+func (r *accrualRepository) Portfolio() (*domain.PortfolioInterest, error) {
+	var portfolio domain.PortfolioInterest
+	err := r.db.Raw(`SELECT COUNT(DISTINCT t."id") AS contracts,
+			COALESCE(SUM(i."interest"), 0) AS interest,
+			COALESCE(SUM(i."accrued_interest"), 0) AS accrued,
+			COALESCE(SUM(CASE WHEN i."status" = ? THEN i."interest" ELSE 0 END), 0) AS received
+		FROM "transactions" t
+		JOIN "installments" i ON i."transaction_id" = t."id"
+		WHERE t."status" = ? AND t."deleted_at" IS NULL AND i."status" IN (?,?,?)`,
+		"paid", domain.StatusApproved, "paid", "unpaid", "overdue",
+	).Scan(&portfolio).Error
+	if err != nil {
+		return nil, err
+	}
+	return &portfolio, nil
+}
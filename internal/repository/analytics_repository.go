@@ -0,0 +1,233 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+	"xyz-multifinance/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// utilisationBands lists the bands of credit limit utilisation in order
+var utilisationBands = []string{"0%", "1-25%", "26-50%", "51-75%", "76-99%", "100%"}
+
+type analyticsRepository struct {
+	db *gorm.DB
+}
+
+// NewAnalyticsRepository creates a new instance of AnalyticsRepository
+func NewAnalyticsRepository(db *gorm.DB) domain.AnalyticsRepository {
+	return &analyticsRepository{
+		db: db,
+	}
+}
+
+// Outstanding implements AnalyticsRepository.Outstanding
+func (r *analyticsRepository) Outstanding(asOf time.Time) (*domain.OutstandingSummary, error) {
+	summary := &domain.OutstandingSummary{AsOf: asOf}
+
+	var err error
+	if summary.BySource, err = r.outstandingBy(`t."source"`, asOf); err != nil {
+		return nil, err
+	}
+	if summary.ByTenor, err = r.outstandingBy(`t."tenor"`, asOf); err != nil {
+		return nil, err
+	}
+	for _, group := range summary.BySource {
+		summary.Contracts += group.Contracts
+		summary.Principal += group.Principal
+		summary.Interest += group.Interest
+		summary.Overdue += group.Overdue
+	}
+	return summary, nil
+}
+
+// outstandingBy groups the outstanding installments of the approved
+// contracts by a column of the contract
+func (r *analyticsRepository) outstandingBy(column string, asOf time.Time) ([]domain.OutstandingGroup, error) {
+	groups := []domain.OutstandingGroup{}
+	err := r.db.Raw(fmt.Sprintf(`SELECT CAST(%[1]s AS TEXT) AS key, COUNT(DISTINCT t."id") AS contracts,
+			COALESCE(SUM(i."principal"), 0) AS principal, COALESCE(SUM(i."interest"), 0) AS interest,
+			COALESCE(SUM(CASE WHEN i."due_date" < ? THEN i."principal" ELSE 0 END), 0) AS overdue
+		FROM "transactions" t
+		JOIN "installments" i ON i."transaction_id" = t."id"
+		WHERE t."status" = ? AND t."deleted_at" IS NULL AND i."status" IN (?,?)
+		GROUP BY %[1]s
+		ORDER BY %[1]s`, column),
+		asOf, domain.StatusApproved, "unpaid", "overdue",
+	).Scan(&groups).Error
+	if err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// Disbursements implements AnalyticsRepository.Disbursements. Written off
+// contracts were disbursed too.
+func (r *analyticsRepository) Disbursements(from, to time.Time) ([]domain.Disbursement, error) {
+	disbursements := []domain.Disbursement{}
+	err := r.db.Raw(`SELECT TO_CHAR(DATE_TRUNC('month', "created_at"), 'YYYY-MM') AS month, "source",
+			COUNT(*) AS contracts, SUM("otr_amount" + "admin_fee") AS amount
+		FROM "transactions"
+		WHERE "status" IN (?,?) AND "deleted_at" IS NULL AND "created_at" >= ? AND "created_at" < ?
+		GROUP BY 1, 2
+		ORDER BY 1, 2`,
+		domain.StatusApproved, domain.StatusWrittenOff, from, to,
+	).Scan(&disbursements).Error
+	if err != nil {
+		return nil, err
+	}
+	return disbursements, nil
+}
+
+// Utilisation implements AnalyticsRepository.Utilisation. Limits of deleted
+// customers and limits of no amount are left out.
+func (r *analyticsRepository) Utilisation() (*domain.Utilisation, error) {
+	var bands []domain.UtilisationGroup
+	err := r.db.Raw(`SELECT CASE
+				WHEN cl."used_amount" <= 0 THEN '0%'
+				WHEN cl."used_amount" <= cl."amount" * 0.25 THEN '1-25%'
+				WHEN cl."used_amount" <= cl."amount" * 0.5 THEN '26-50%'
+				WHEN cl."used_amount" <= cl."amount" * 0.75 THEN '51-75%'
+				WHEN cl."used_amount" < cl."amount" THEN '76-99%'
+				ELSE '100%'
+			END AS key, COUNT(*) AS limits, SUM(cl."amount") AS amount, SUM(cl."used_amount") AS used
+		FROM "credit_limits" cl
+		JOIN "customers" c ON c."id" = cl."customer_id" AND c."deleted_at" IS NULL
+		WHERE cl."amount" > 0
+		GROUP BY 1`,
+	).Scan(&bands).Error
+	if err != nil {
+		return nil, err
+	}
+
+	utilisation := &domain.Utilisation{
+		AsOf:    time.Now(),
+		Bands:   make([]domain.UtilisationGroup, len(utilisationBands)),
+		ByTenor: []domain.UtilisationGroup{},
+	}
+	for i, key := range utilisationBands {
+		utilisation.Bands[i].Key = key
+		for _, band := range bands {
+			if band.Key == key {
+				utilisation.Bands[i] = band
+			}
+		}
+	}
+
+	err = r.db.Raw(`SELECT CAST(cl."tenor" AS TEXT) AS key, COUNT(*) AS limits, SUM(cl."amount") AS amount, SUM(cl."used_amount") AS used
+		FROM "credit_limits" cl
+		JOIN "customers" c ON c."id" = cl."customer_id" AND c."deleted_at" IS NULL
+		WHERE cl."amount" > 0
+		GROUP BY cl."tenor"
+		ORDER BY cl."tenor"`,
+	).Scan(&utilisation.ByTenor).Error
+	if err != nil {
+		return nil, err
+	}
+	return utilisation, nil
+}
+
+// CollectionRates implements AnalyticsRepository.CollectionRates. Superseded
+// installments are left out, the schedule replacing them is counted.
+func (r *analyticsRepository) CollectionRates(from, to time.Time) ([]domain.CollectionRate, error) {
+	rates := []domain.CollectionRate{}
+	err := r.db.Raw(`SELECT TO_CHAR(DATE_TRUNC('month', i."due_date"), 'YYYY-MM') AS month, SUM(i."amount") AS due,
+			COALESCE(SUM(CASE WHEN i."paid_at" < DATE_TRUNC('month', i."due_date") + INTERVAL '1 month' THEN i."amount" END), 0) AS collected,
+			COALESCE(SUM(CASE WHEN i."paid_at" < i."due_date" + INTERVAL '1 day' THEN i."amount" END), 0) AS on_time
+		FROM "installments" i
+		JOIN "transactions" t ON t."id" = i."transaction_id" AND t."deleted_at" IS NULL
+		WHERE i."status" <> ? AND i."due_date" >= ? AND i."due_date" < ?
+		GROUP BY 1
+		ORDER BY 1`,
+		"superseded", from, to,
+	).Scan(&rates).Error
+	if err != nil {
+		return nil, err
+	}
+	return rates, nil
+}
+
+// Vintage implements AnalyticsRepository.Vintage. A cohort is one month on
+// book at the end of the month it was disbursed in. Contracts are delinquent
+// at the end of a month while an installment is unpaid more than dpd days
+// after its due date.
+func (r *analyticsRepository) Vintage(from, to time.Time, dpd, maxMOB int, asOf time.Time) ([]domain.VintagePoint, error) {
+	points := []domain.VintagePoint{}
+	err := r.db.Raw(`WITH cohorts AS (
+			SELECT "id", DATE_TRUNC('month', "created_at") AS cohort, "otr_amount" + "admin_fee" AS amount
+			FROM "transactions"
+			WHERE "status" IN (?,?) AND "deleted_at" IS NULL AND "created_at" >= ? AND "created_at" < ?
+		), points AS (
+			SELECT c."id", c.cohort, c.amount, m.mob, c.cohort + MAKE_INTERVAL(months => m.mob) AS observed_at
+			FROM cohorts c
+			CROSS JOIN GENERATE_SERIES(1, ?) AS m(mob)
+		)
+		SELECT TO_CHAR(p.cohort, 'YYYY-MM') AS cohort, p.mob, COUNT(*) AS contracts, SUM(p.amount) AS amount,
+			COALESCE(SUM(CASE WHEN EXISTS (
+				SELECT 1 FROM "installments" i
+				WHERE i."transaction_id" = p."id" AND i."status" <> ?
+					AND i."due_date" < p.observed_at - MAKE_INTERVAL(days => ?)
+					AND (i."paid_at" IS NULL OR i."paid_at" >= p.observed_at)
+			) THEN p.amount END), 0) AS delinquent
+		FROM points p
+		WHERE p.observed_at <= ?
+		GROUP BY p.cohort, p.mob
+		ORDER BY p.cohort, p.mob`,
+		domain.StatusApproved, domain.StatusWrittenOff, from, to, maxMOB, "superseded", dpd, asOf,
+	).Scan(&points).Error
+	if err != nil {
+		return nil, err
+	}
+	return points, nil
+}
+
+// RollRates implements AnalyticsRepository.RollRates. A contract's state at a
+// date is the bucket of the days past due of its oldest installment unpaid
+// then, bucketed as domain.BucketOf does.
+func (r *analyticsRepository) RollRates(from, to time.Time) ([]domain.RollTransition, error) {
+	transitions := []domain.RollTransition{}
+	err := r.db.Raw(`WITH open AS (
+			SELECT t."id" FROM "transactions" t
+			WHERE t."status" IN (?,?) AND t."deleted_at" IS NULL AND t."created_at" < ?
+				AND EXISTS (SELECT 1 FROM "installments" i
+					WHERE i."transaction_id" = t."id" AND i."status" <> ? AND (i."paid_at" IS NULL OR i."paid_at" >= ?))
+		), states AS (
+			SELECT
+				(SELECT DATE_PART('day', CAST(? AS TIMESTAMP) - MIN(i."due_date")) FROM "installments" i
+					WHERE i."transaction_id" = o."id" AND i."status" <> ? AND i."due_date" < ? AND (i."paid_at" IS NULL OR i."paid_at" >= ?)) AS from_dpd,
+				(SELECT DATE_PART('day', CAST(? AS TIMESTAMP) - MIN(i."due_date")) FROM "installments" i
+					WHERE i."transaction_id" = o."id" AND i."status" <> ? AND i."due_date" < ? AND (i."paid_at" IS NULL OR i."paid_at" >= ?)) AS to_dpd,
+				EXISTS (SELECT 1 FROM "installments" i
+					WHERE i."transaction_id" = o."id" AND i."status" <> ? AND (i."paid_at" IS NULL OR i."paid_at" >= ?)) AS to_open
+			FROM open o
+		)
+		SELECT `+dpdBucketSQL("from_dpd")+` AS "from",
+			CASE WHEN NOT to_open THEN '`+string(domain.BucketClosed)+`' ELSE `+dpdBucketSQL("to_dpd")+` END AS "to",
+			COUNT(*) AS contracts
+		FROM states
+		GROUP BY 1, 2`,
+		domain.StatusApproved, domain.StatusWrittenOff, from, "superseded", from,
+		from, "superseded", from, from,
+		to, "superseded", to, to,
+		"superseded", to,
+	).Scan(&transitions).Error
+	if err != nil {
+		return nil, err
+	}
+	return transitions, nil
+}
+
+// dpdBucketSQL returns the SQL bucketing the days past due held by column,
+// NULL when nothing is past due
+func dpdBucketSQL(column string) string {
+	return fmt.Sprintf(`CASE
+			WHEN %[1]s IS NULL THEN '%[2]s'
+			WHEN %[1]s > 180 THEN '%[3]s'
+			WHEN %[1]s > 90 THEN '%[4]s'
+			WHEN %[1]s > 60 THEN '%[5]s'
+			WHEN %[1]s > 30 THEN '%[6]s'
+			ELSE '%[7]s'
+		END`,
+		column, domain.BucketCurrent, domain.BucketOver180, domain.Bucket91To180, domain.Bucket61To90, domain.Bucket31To60, domain.Bucket1To30)
+}
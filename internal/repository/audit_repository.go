@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"xyz-multifinance/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type auditRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditRepository creates a new instance of AuditRepository
+func NewAuditRepository(db *gorm.DB) domain.AuditRepository {
+	return &auditRepository{
+		db: db,
+	}
+}
+
+// List implements AuditRepository.List, newest first. An empty entityType
+// or a zero entityID matches every entity.
+func (r *auditRepository) List(entityType string, entityID uint, offset, limit int) ([]domain.AuditLog, error) {
+	query := r.db.Model(&domain.AuditLog{})
+	if entityType != "" {
+		query = query.Where("entity_type = ?", entityType)
+	}
+	if entityID != 0 {
+		query = query.Where("entity_id = ?", entityID)
+	}
+
+	var logs []domain.AuditLog
+	err := query.Order("id desc").Offset(offset).Limit(limit).Find(&logs).Error
+	if err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+// writeAudit records an audit log entry in the caller's database transaction
+// so it is kept if and only if the audited change commits
+func writeAudit(tx *gorm.DB, audit *domain.AuditLog) error {
+	if audit == nil {
+		return nil
+	}
+	return tx.Create(audit).Error
+}
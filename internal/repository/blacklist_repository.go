@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"xyz-multifinance/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type blacklistRepository struct {
+	db *gorm.DB
+}
+
+// NewBlacklistRepository creates a new instance of BlacklistRepository
+func NewBlacklistRepository(db *gorm.DB) domain.BlacklistRepository {
+	return &blacklistRepository{
+		db: db,
+	}
+}
+
+// Create implements BlacklistRepository.Create
+func (r *blacklistRepository) Create(entry *domain.BlacklistEntry, audit *domain.AuditLog) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(entry)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.NewError(domain.ErrConflict, "blacklist_entry_exists", "identifier is blacklisted already")
+		}
+
+		audit.EntityID = entry.ID
+		return writeAudit(tx, audit)
+	})
+}
+
+// Delete implements BlacklistRepository.Delete
+func (r *blacklistRepository) Delete(id uint, audit *domain.AuditLog) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&domain.BlacklistEntry{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.NewError(domain.ErrNotFound, "blacklist_entry_not_found", "blacklist entry not found")
+		}
+		return writeAudit(tx, audit)
+	})
+}
+
+// List implements BlacklistRepository.List
+func (r *blacklistRepository) List(entryType domain.BlacklistType, offset, limit int) ([]domain.BlacklistEntry, error) {
+	query := r.db.Order("id DESC").Offset(offset).Limit(limit)
+	if entryType != "" {
+		query = query.Where("type = ?", entryType)
+	}
+
+	var entries []domain.BlacklistEntry
+	if err := query.Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Match implements BlacklistRepository.Match
+func (r *blacklistRepository) Match(identifiers map[domain.BlacklistType]string) ([]domain.BlacklistEntry, error) {
+	var conditions []clause.Expression
+	for entryType, value := range identifiers {
+		if value == "" {
+			continue
+		}
+		conditions = append(conditions, clause.And(
+			clause.Eq{Column: clause.Column{Name: "type"}, Value: entryType},
+			clause.Eq{Column: clause.Column{Name: "value"}, Value: value},
+		))
+	}
+	if len(conditions) == 0 {
+		return nil, nil
+	}
+
+	var entries []domain.BlacklistEntry
+	if err := r.db.Clauses(clause.Where{Exprs: []clause.Expression{clause.Or(conditions...)}}).Order("id").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
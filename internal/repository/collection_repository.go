@@ -0,0 +1,245 @@
+package repository
+
+import (
+	"time"
+	"xyz-multifinance/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type collectionRepository struct {
+	db *gorm.DB
+}
+
+// NewCollectionRepository creates a new instance of CollectionRepository
+func NewCollectionRepository(db *gorm.DB) domain.CollectionRepository {
+	return &collectionRepository{
+		db: db,
+	}
+}
+
+// CreateCollector implements CollectionRepository.CreateCollector
+func (r *collectionRepository) CreateCollector(collector *domain.Collector) error {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(collector)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.NewError(domain.ErrConflict, "collector_exists", "user is a collector already")
+	}
+	return nil
+}
+
+// UpdateCollector implements CollectionRepository.UpdateCollector
+func (r *collectionRepository) UpdateCollector(collector *domain.Collector) error {
+	result := r.db.Model(&domain.Collector{}).
+		Where("id = ?", collector.ID).
+		Updates(map[string]interface{}{
+			"name":         collector.Name,
+			"level":        collector.Level,
+			"region":       collector.Region,
+			"max_accounts": collector.MaxAccounts,
+			"active":       collector.Active,
+			"updated_at":   collector.UpdatedAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.NewError(domain.ErrNotFound, "collector_not_found", "collector not found")
+	}
+	return nil
+}
+
+// GetCollector implements CollectionRepository.GetCollector
+func (r *collectionRepository) GetCollector(id uint) (*domain.Collector, error) {
+	var collector domain.Collector
+	if err := r.db.First(&collector, id).Error; err != nil {
+		return nil, translateNotFound(err, "collector_not_found", "collector not found")
+	}
+	return &collector, nil
+}
+
+// GetCollectorByUser implements CollectionRepository.GetCollectorByUser
+func (r *collectionRepository) GetCollectorByUser(userID uint) (*domain.Collector, error) {
+	var collector domain.Collector
+	if err := r.db.Where("user_id = ?", userID).First(&collector).Error; err != nil {
+		return nil, translateNotFound(err, "collector_not_found", "collector not found")
+	}
+	return &collector, nil
+}
+
+// ListCollectors implements CollectionRepository.ListCollectors
+func (r *collectionRepository) ListCollectors(offset, limit int) ([]domain.Collector, error) {
+	var collectors []domain.Collector
+	if err := r.db.Order("id").Offset(offset).Limit(limit).Find(&collectors).Error; err != nil {
+		return nil, err
+	}
+	return collectors, nil
+}
+
+// LeastLoadedCollector implements CollectionRepository.LeastLoadedCollector
+func (r *collectionRepository) LeastLoadedCollector(level domain.CollectionLevel, region string) (*domain.Collector, error) {
+	var collector domain.Collector
+	result := r.db.Raw(`SELECT c.* FROM "collectors" c
+		LEFT JOIN "collection_accounts" a ON a."collector_id" = c."id" AND a."status" = ?
+		WHERE c."active" AND c."level" = ? AND (COALESCE(c."region", '') = ? OR COALESCE(c."region", '') = '')
+		GROUP BY c."id"
+		HAVING c."max_accounts" = 0 OR COUNT(a."id") < c."max_accounts"
+		ORDER BY COALESCE(c."region", '') = ? DESC, COUNT(a."id"), c."id"
+		LIMIT 1`,
+		domain.CollectionOpen, level, region, region,
+	).Scan(&collector)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, domain.NewError(domain.ErrNotFound, "no_collector_available", "no collector available")
+	}
+	return &collector, nil
+}
+
+// ListDelinquencies implements CollectionRepository.ListDelinquencies
+func (r *collectionRepository) ListDelinquencies(afterTransactionID uint, limit int) ([]domain.Delinquency, error) {
+	var delinquencies []domain.Delinquency
+	err := r.db.Raw(`SELECT t."id" AS transaction_id, t."customer_id", COALESCE(c."region", '') AS region,
+			COUNT(*) AS overdue_installments, SUM(i."amount" + i."late_fee") AS overdue_amount, MIN(i."due_date") AS oldest_due_date
+		FROM "installments" i
+		JOIN "transactions" t ON t."id" = i."transaction_id"
+		JOIN "customers" c ON c."id" = t."customer_id"
+		WHERE i."status" = ? AND t."deleted_at" IS NULL AND t."id" > ?
+		GROUP BY t."id", t."customer_id", c."region"
+		ORDER BY t."id"
+		LIMIT ?`,
+		"overdue", afterTransactionID, limit,
+	).Scan(&delinquencies).Error
+	if err != nil {
+		return nil, err
+	}
+	return delinquencies, nil
+}
+
+// GetAccount implements CollectionRepository.GetAccount
+func (r *collectionRepository) GetAccount(id uint) (*domain.CollectionAccount, error) {
+	var account domain.CollectionAccount
+	err := r.db.Preload("Collector").
+		Preload("Activities", func(db *gorm.DB) *gorm.DB { return db.Order("created_at DESC, id DESC") }).
+		First(&account, id).Error
+	if err != nil {
+		return nil, translateNotFound(err, "collection_account_not_found", "collection account not found")
+	}
+	return &account, nil
+}
+
+// GetAccountByTransaction implements CollectionRepository.GetAccountByTransaction
+func (r *collectionRepository) GetAccountByTransaction(transactionID uint) (*domain.CollectionAccount, error) {
+	var account domain.CollectionAccount
+	if err := r.db.Where("transaction_id = ?", transactionID).First(&account).Error; err != nil {
+		return nil, translateNotFound(err, "collection_account_not_found", "collection account not found")
+	}
+	return &account, nil
+}
+
+// SaveAccount implements CollectionRepository.SaveAccount. Updates require
+// the version the account was read at.
+func (r *collectionRepository) SaveAccount(account *domain.CollectionAccount) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if account.ID == 0 {
+			account.Version = 1
+			if err := tx.Omit(clause.Associations).Create(account).Error; err != nil {
+				return err
+			}
+		} else {
+			result := tx.Model(&domain.CollectionAccount{}).
+				Where("id = ? AND version = ?", account.ID, account.Version).
+				Updates(map[string]interface{}{
+					"region":           account.Region,
+					"status":           account.Status,
+					"dpd":              account.DPD,
+					"bucket":           account.Bucket,
+					"level":            account.Level,
+					"collector_id":     account.CollectorID,
+					"overdue_amount":   account.OverdueAmount,
+					"oldest_due_date":  account.OldestDueDate,
+					"promise_date":     account.PromiseDate,
+					"promise_amount":   account.PromiseAmount,
+					"broken_promises":  account.BrokenPromises,
+					"last_activity_at": account.LastActivityAt,
+					"escalated_at":     account.EscalatedAt,
+					"closed_at":        account.ClosedAt,
+					"version":          gorm.Expr("version + 1"),
+					"updated_at":       account.UpdatedAt,
+				})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return errConcurrentModification()
+			}
+			account.Version++
+		}
+
+		for i := range account.Logged {
+			account.Logged[i].AccountID = account.ID
+			if err := tx.Create(&account.Logged[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// CloseResolved implements CollectionRepository.CloseResolved
+func (r *collectionRepository) CloseResolved(at time.Time) (int, error) {
+	result := r.db.Exec(`UPDATE "collection_accounts" SET "status" = ?, "closed_at" = ?, "promise_date" = NULL, "promise_amount" = 0, "version" = "version" + 1, "updated_at" = ?
+		WHERE "status" = ? AND NOT EXISTS (
+			SELECT 1 FROM "installments" WHERE "installments"."transaction_id" = "collection_accounts"."transaction_id" AND "installments"."status" = ?
+		)`,
+		domain.CollectionClosed, at, at, domain.CollectionOpen, "overdue",
+	)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return int(result.RowsAffected), nil
+}
+
+// ListUnassigned implements CollectionRepository.ListUnassigned
+func (r *collectionRepository) ListUnassigned(limit int) ([]domain.CollectionAccount, error) {
+	var accounts []domain.CollectionAccount
+	err := r.db.Where("status = ? AND collector_id IS NULL", domain.CollectionOpen).
+		Order("dpd DESC, id").
+		Limit(limit).
+		Find(&accounts).Error
+	if err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+// ListWorklist implements CollectionRepository.ListWorklist
+func (r *collectionRepository) ListWorklist(filter domain.WorklistFilter, offset, limit int) ([]domain.CollectionAccount, error) {
+	query := r.db.Where("status = ?", domain.CollectionOpen)
+	if filter.Bucket != "" {
+		query = query.Where("bucket = ?", filter.Bucket)
+	}
+	if filter.Region != "" {
+		query = query.Where("region = ?", filter.Region)
+	}
+	if filter.Level != "" {
+		query = query.Where("level = ?", filter.Level)
+	}
+	if filter.CollectorID != nil {
+		query = query.Where("collector_id = ?", *filter.CollectorID)
+	}
+
+	var accounts []domain.CollectionAccount
+	err := query.Order("dpd DESC, overdue_amount DESC, id").
+		Offset(offset).
+		Limit(limit).
+		Find(&accounts).Error
+	if err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
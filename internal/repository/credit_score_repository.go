@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"time"
+	"xyz-multifinance/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type creditScoreRepository struct {
+	db *gorm.DB
+}
+
+// NewCreditScoreRepository creates a new instance of CreditScoreRepository
+func NewCreditScoreRepository(db *gorm.DB) domain.CreditScoreRepository {
+	return &creditScoreRepository{
+		db: db,
+	}
+}
+
+// Create implements CreditScoreRepository.Create
+func (r *creditScoreRepository) Create(score *domain.CreditScore) error {
+	return r.db.Create(score).Error
+}
+
+// ListByCustomer implements CreditScoreRepository.ListByCustomer, newest first
+func (r *creditScoreRepository) ListByCustomer(customerID uint, offset, limit int) ([]domain.CreditScore, error) {
+	var scores []domain.CreditScore
+	err := r.db.Where("customer_id = ?", customerID).Order("id desc").Offset(offset).Limit(limit).Find(&scores).Error
+	if err != nil {
+		return nil, err
+	}
+	return scores, nil
+}
+
+// GetPaymentHistory implements CreditScoreRepository.GetPaymentHistory.
+// Installments superseded by a restructuring are left out; days late count
+// calendar days past the due date.
+func (r *creditScoreRepository) GetPaymentHistory(customerID uint, asOf time.Time) (*domain.PaymentHistory, error) {
+	var history domain.PaymentHistory
+	err := r.db.Raw(`SELECT
+			COUNT(*) FILTER (WHERE i."status" = 'paid') AS "paid_installments",
+			COUNT(*) FILTER (WHERE i."status" = 'paid' AND i."paid_at"::date <= i."due_date"::date) AS "paid_on_time",
+			COALESCE(MAX(GREATEST(COALESCE(i."paid_at", ?)::date - i."due_date"::date, 0)) FILTER (WHERE i."status" IN ('paid', 'overdue')), 0) AS "max_days_late",
+			COUNT(*) FILTER (WHERE i."status" = 'overdue') AS "overdue_installments"
+		FROM "installments" i JOIN "transactions" t ON t."id" = i."transaction_id"
+		WHERE t."customer_id" = ? AND t."deleted_at" IS NULL`,
+		asOf, customerID,
+	).Scan(&history).Error
+	if err != nil {
+		return nil, err
+	}
+	return &history, nil
+}
@@ -1,14 +1,17 @@
 package repository
 
 import (
-	"xyz-multifinance/internal/domain"
-
-	"errors"
+	"fmt"
 	"time"
+	"xyz-multifinance/internal/domain"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// anonymizedValue replaces personal data of erased customers
+const anonymizedValue = "ANONYMIZED"
+
 type customerRepository struct {
 	db *gorm.DB
 }
@@ -30,17 +33,28 @@ func (r *customerRepository) GetByID(id uint) (*domain.Customer, error) {
 	var customer domain.Customer
 	err := r.db.Preload("CreditLimits").First(&customer, id).Error
 	if err != nil {
-		return nil, err
+		return nil, translateNotFound(err, "customer_not_found", "customer not found")
 	}
 	return &customer, nil
 }
 
-// GetByNIK implements CustomerRepository.GetByNIK
+// GetByNIK implements CustomerRepository.GetByNIK. Deleted customers are
+// included, as the unique index on nik covers them until they are anonymized.
 func (r *customerRepository) GetByNIK(nik string) (*domain.Customer, error) {
 	var customer domain.Customer
-	err := r.db.Preload("CreditLimits").Where("nik = ?", nik).First(&customer).Error
+	err := r.db.Unscoped().Preload("CreditLimits").Where("nik = ?", nik).First(&customer).Error
 	if err != nil {
-		return nil, err
+		return nil, translateNotFound(err, "customer_not_found", "customer not found")
+	}
+	return &customer, nil
+}
+
+// GetByIDUnscoped implements CustomerRepository.GetByIDUnscoped
+func (r *customerRepository) GetByIDUnscoped(id uint) (*domain.Customer, error) {
+	var customer domain.Customer
+	err := r.db.Unscoped().First(&customer, id).Error
+	if err != nil {
+		return nil, translateNotFound(err, "customer_not_found", "customer not found")
 	}
 	return &customer, nil
 }
@@ -48,17 +62,21 @@ func (r *customerRepository) GetByNIK(nik string) (*domain.Customer, error) {
 // Update implements CustomerRepository.Update
 func (r *customerRepository) Update(customer *domain.Customer) error {
 	return r.db.Transaction(func(tx *gorm.DB) error {
-		// Get current version using raw SQL
+		// Get and lock current version using raw SQL
 		var current struct {
 			Version int
 		}
-		if err := tx.Raw(`SELECT version FROM "customers" WHERE "customers"."id" = ? ORDER BY "customers"."id" LIMIT ?`, customer.ID, 1).Scan(&current).Error; err != nil {
-			return err
+		result := tx.Raw(`SELECT version FROM "customers" WHERE "customers"."id" = ? ORDER BY "customers"."id" LIMIT ? FOR UPDATE`, customer.ID, 1).Scan(&current)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.NewError(domain.ErrNotFound, "customer_not_found", "customer not found")
 		}
 
 		// Check version
 		if current.Version != customer.Version {
-			return errors.New("concurrent modification detected")
+			return errConcurrentModification()
 		}
 
 		// Increment version
@@ -73,9 +91,75 @@ func (r *customerRepository) Update(customer *domain.Customer) error {
 	})
 }
 
-// Delete implements CustomerRepository.Delete
+// Delete implements CustomerRepository.Delete. A customer with active
+// contracts is not deleted.
 func (r *customerRepository) Delete(id uint) error {
-	return r.db.Delete(&domain.Customer{}, id).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Lock the customer so no contract is created while it is checked
+		var current struct {
+			ID uint
+		}
+		result := tx.Raw(`SELECT id FROM "customers" WHERE "customers"."id" = ? AND "customers"."deleted_at" IS NULL ORDER BY "customers"."id" LIMIT ? FOR UPDATE`, id, 1).Scan(&current)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.NewError(domain.ErrNotFound, "customer_not_found", "customer not found")
+		}
+
+		active, err := hasActiveContracts(tx, id)
+		if err != nil {
+			return err
+		}
+		if active {
+			return domain.NewError(domain.ErrConflict, "customer_has_active_contracts", "customer has active contracts")
+		}
+
+		return tx.Delete(&domain.Customer{}, id).Error
+	})
+}
+
+// HasActiveContracts implements CustomerRepository.HasActiveContracts.
+// A contract is active while it is pending or approved with outstanding installments.
+func (r *customerRepository) HasActiveContracts(customerID uint) (bool, error) {
+	return hasActiveContracts(r.db, customerID)
+}
+
+func hasActiveContracts(db *gorm.DB, customerID uint) (bool, error) {
+	var count int64
+	err := db.Raw(`SELECT COUNT(*) FROM "transactions" WHERE "customer_id" = ? AND "deleted_at" IS NULL AND ("status" = ? OR ("status" = ? AND EXISTS (SELECT 1 FROM "installments" WHERE "installments"."transaction_id" = "transactions"."id" AND "installments"."status" IN (?,?))))`,
+		customerID, domain.StatusPending, domain.StatusApproved, "unpaid", "overdue",
+	).Scan(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// Anonymize implements CustomerRepository.Anonymize. Personal data is
+// overwritten in place so the row can still be joined from financial records.
+// Contact details kept for notifications and the screening hits of the
+// registration are removed with it.
+func (r *customerRepository) Anonymize(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Exec(`UPDATE "customers" SET "nik"=?,"full_name"=?,"legal_name"=?,"place_of_birth"=?,"date_of_birth"=date_trunc('year', "date_of_birth"),"salary"=0,"ktp_photo"=?,"selfie_photo"=?,"phone"=?,"device_id"=?,"region"=?,"risk_hits"=NULL,"version"="version"+1,"updated_at"=? WHERE "id" = ?`,
+			fmt.Sprintf("ANON%012d", id), anonymizedValue, anonymizedValue, anonymizedValue, "", "", "", "", "",
+			time.Now(), id,
+		)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.NewError(domain.ErrNotFound, "customer_not_found", "customer not found")
+		}
+
+		if err := tx.Where("customer_id = ?", id).Delete(&domain.NotificationPreference{}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&domain.Notification{}).
+			Where("customer_id = ?", id).
+			Update("recipient", anonymizedValue).Error
+	})
 }
 
 // List implements CustomerRepository.List
@@ -98,27 +182,47 @@ func (r *customerRepository) GetCreditLimits(customerID uint) ([]domain.CreditLi
 	return limits, nil
 }
 
-// UpdateCreditLimit implements CustomerRepository.UpdateCreditLimit
+// GetContractLimitCharge implements CustomerRepository.GetContractLimitCharge
+func (r *customerRepository) GetContractLimitCharge(contractNumber string) (*domain.ContractLimitCharge, error) {
+	var charge domain.ContractLimitCharge
+	err := r.db.Where("contract_number = ?", contractNumber).First(&charge).Error
+	if err != nil {
+		return nil, translateNotFound(err, "contract_limit_charge_not_found", "contract limit charge not found")
+	}
+	return &charge, nil
+}
+
+// UpdateCreditLimit implements CustomerRepository.UpdateCreditLimit. The
+// contract charge, if any, is saved under the lock of the credit limit it is
+// charged to, which serialises every change of the charge.
 func (r *customerRepository) UpdateCreditLimit(limit *domain.CreditLimit) error {
 	return r.db.Transaction(func(tx *gorm.DB) error {
-		// Get current version using raw SQL
+		if err := recordProcessed(tx, limit.Processed); err != nil {
+			return err
+		}
+
+		// Get and lock current version using raw SQL
 		var current struct {
 			Version int
 		}
-		if err := tx.Raw(`SELECT version FROM "credit_limits" WHERE "credit_limits"."id" = ? ORDER BY "credit_limits"."id" LIMIT ?`, limit.ID, 1).Scan(&current).Error; err != nil {
-			return err
+		result := tx.Raw(`SELECT version FROM "credit_limits" WHERE "credit_limits"."id" = ? ORDER BY "credit_limits"."id" LIMIT ? FOR UPDATE`, limit.ID, 1).Scan(&current)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.NewError(domain.ErrNotFound, "credit_limit_not_found", "credit limit not found")
 		}
 
 		// Check version
 		if current.Version != limit.Version {
-			return errors.New("concurrent modification detected")
+			return errConcurrentModification()
 		}
 
 		// Increment version
 		limit.Version++
 
 		// Update credit limit using raw SQL
-		result := tx.Exec(`UPDATE "credit_limits" SET "customer_id"=?,"tenor"=?,"amount"=?,"used_amount"=?,"version"=?,"created_at"=?,"updated_at"=?,"deleted_at"=? WHERE "id" = ?`,
+		result = tx.Exec(`UPDATE "credit_limits" SET "customer_id"=?,"tenor"=?,"amount"=?,"used_amount"=?,"version"=?,"created_at"=?,"updated_at"=?,"deleted_at"=? WHERE "id" = ?`,
 			limit.CustomerID, limit.Tenor, limit.Amount, limit.UsedAmount,
 			limit.Version, time.Time{}, time.Now(), nil,
 			limit.ID,
@@ -129,9 +233,15 @@ func (r *customerRepository) UpdateCreditLimit(limit *domain.CreditLimit) error
 		}
 
 		if result.RowsAffected == 0 {
-			return errors.New("optimistic lock error")
+			return errConcurrentModification()
 		}
 
-		return nil
+		if limit.Charge != nil {
+			if err := tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(limit.Charge).Error; err != nil {
+				return err
+			}
+		}
+
+		return writeOutbox(tx, limit.Events)
 	})
 }
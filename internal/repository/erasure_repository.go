@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"time"
+	"xyz-multifinance/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type erasureRequestRepository struct {
+	db *gorm.DB
+}
+
+// NewErasureRequestRepository creates a new instance of ErasureRequestRepository
+func NewErasureRequestRepository(db *gorm.DB) domain.ErasureRequestRepository {
+	return &erasureRequestRepository{
+		db: db,
+	}
+}
+
+// Create implements ErasureRequestRepository.Create
+func (r *erasureRequestRepository) Create(request *domain.ErasureRequest) error {
+	return r.db.Create(request).Error
+}
+
+// GetPendingByCustomerID implements ErasureRequestRepository.GetPendingByCustomerID
+func (r *erasureRequestRepository) GetPendingByCustomerID(customerID uint) (*domain.ErasureRequest, error) {
+	var request domain.ErasureRequest
+	err := r.db.Where("customer_id = ? AND status = ?", customerID, domain.ErasurePending).First(&request).Error
+	if err != nil {
+		return nil, translateNotFound(err, "erasure_request_not_found", "erasure request not found")
+	}
+	return &request, nil
+}
+
+// ListDue implements ErasureRequestRepository.ListDue
+func (r *erasureRequestRepository) ListDue(now time.Time, limit int) ([]domain.ErasureRequest, error) {
+	var requests []domain.ErasureRequest
+	err := r.db.Where("status = ? AND eligible_at <= ?", domain.ErasurePending, now).
+		Order("eligible_at asc").
+		Limit(limit).
+		Find(&requests).Error
+	if err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// MarkCompleted implements ErasureRequestRepository.MarkCompleted
+func (r *erasureRequestRepository) MarkCompleted(id uint, completedAt time.Time) error {
+	return r.db.Model(&domain.ErasureRequest{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":       domain.ErasureCompleted,
+			"completed_at": completedAt,
+		}).Error
+}
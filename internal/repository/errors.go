@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"errors"
+	"xyz-multifinance/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// errConcurrentModification is returned when the stored version no longer
+// matches the version the caller read
+func errConcurrentModification() error {
+	return domain.NewError(domain.ErrConflict, "concurrent_modification", "concurrent modification detected")
+}
+
+// translateNotFound converts gorm.ErrRecordNotFound into a domain not found error
+func translateNotFound(err error, code, message string) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.NewError(domain.ErrNotFound, code, message)
+	}
+	return err
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"time"
+	"xyz-multifinance/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type exportRepository struct {
+	db *gorm.DB
+}
+
+// NewExportRepository creates a new instance of ExportRepository
+func NewExportRepository(db *gorm.DB) domain.ExportRepository {
+	return &exportRepository{
+		db: db,
+	}
+}
+
+// ListTransactions implements ExportRepository.ListTransactions
+func (r *exportRepository) ListTransactions(filter domain.TransactionFilter, afterID uint, limit int) ([]domain.Transaction, error) {
+	var transactions []domain.Transaction
+	err := applyTransactionFilter(r.db, filter).
+		Where("id > ?", afterID).
+		Order("id").
+		Limit(limit).
+		Find(&transactions).Error
+	if err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// StatementBalance implements ExportRepository.StatementBalance
+func (r *exportRepository) StatementBalance(customerID uint, before time.Time) (float64, error) {
+	var balance float64
+	err := r.db.Raw(`SELECT COALESCE(SUM(l."debit" - l."credit"), 0)
+		FROM "journal_lines" l
+		JOIN "journal_entries" e ON e."id" = l."entry_id"
+		JOIN "transactions" t ON t."id" = e."transaction_id"
+		WHERE t."customer_id" = ? AND l."account_code" IN (?,?) AND e."posted_at" < ?`,
+		customerID, domain.AccountFinancingReceivable, domain.AccountLateFeeReceivable, before,
+	).Scan(&balance).Error
+	if err != nil {
+		return 0, err
+	}
+	return balance, nil
+}
+
+// ListStatementLines implements ExportRepository.ListStatementLines
+func (r *exportRepository) ListStatementLines(customerID uint, to, afterPostedAt time.Time, afterLineID uint, limit int) ([]domain.StatementLine, error) {
+	var lines []domain.StatementLine
+	err := r.db.Raw(`SELECT l."id" AS line_id, e."posted_at", t."contract_number", e."type" AS entry_type,
+			e."description", COALESCE(e."reference", '') AS reference, l."debit", l."credit"
+		FROM "journal_lines" l
+		JOIN "journal_entries" e ON e."id" = l."entry_id"
+		JOIN "transactions" t ON t."id" = e."transaction_id"
+		WHERE t."customer_id" = ? AND l."account_code" IN (?,?) AND e."posted_at" < ?
+			AND (e."posted_at", l."id") > (?, ?)
+		ORDER BY e."posted_at", l."id"
+		LIMIT ?`,
+		customerID, domain.AccountFinancingReceivable, domain.AccountLateFeeReceivable, to, afterPostedAt, afterLineID, limit,
+	).Scan(&lines).Error
+	if err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"time"
+	"xyz-multifinance/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ledgerRepository struct {
+	db *gorm.DB
+}
+
+// NewLedgerRepository creates a new instance of LedgerRepository
+func NewLedgerRepository(db *gorm.DB) domain.LedgerRepository {
+	return &ledgerRepository{
+		db: db,
+	}
+}
+
+// ListAccounts implements LedgerRepository.ListAccounts, by code
+func (r *ledgerRepository) ListAccounts() ([]domain.Account, error) {
+	var accounts []domain.Account
+	if err := r.db.Order("code").Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+// CreateAccount implements LedgerRepository.CreateAccount
+func (r *ledgerRepository) CreateAccount(account *domain.Account) error {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(account)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.NewError(domain.ErrConflict, "account_exists", "account code is in use")
+	}
+	return nil
+}
+
+// UpdateAccount implements LedgerRepository.UpdateAccount. Only the name and
+// whether the account is active change; the type of an account with postings
+// is fixed.
+func (r *ledgerRepository) UpdateAccount(account *domain.Account) error {
+	result := r.db.Model(&domain.Account{}).
+		Where("code = ?", account.Code).
+		Updates(map[string]interface{}{
+			"name":       account.Name,
+			"active":     account.Active,
+			"updated_at": account.UpdatedAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.NewError(domain.ErrNotFound, "account_not_found", "account not found")
+	}
+	return r.db.Where("code = ?", account.Code).First(account).Error
+}
+
+// Post implements LedgerRepository.Post
+func (r *ledgerRepository) Post(entries []domain.JournalEntry, source *domain.ProcessedEvent, audit *domain.AuditLog) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := recordProcessed(tx, source); err != nil {
+			return err
+		}
+		if err := writeJournal(tx, entries); err != nil {
+			return err
+		}
+
+		if audit == nil || len(entries) == 0 {
+			return nil
+		}
+		audit.EntityID = entries[0].ID
+		return writeAudit(tx, audit)
+	})
+}
+
+// writeJournal records journal entries in the caller's database transaction,
+// refusing them when one posts to an unknown or inactive account. The
+// database checks each entry balances when the transaction commits, and
+// refuses changes to posted lines.
+func writeJournal(tx *gorm.DB, entries []domain.JournalEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	codes := make(map[string]bool)
+	for _, entry := range entries {
+		for _, line := range entry.Lines {
+			codes[line.AccountCode] = true
+		}
+	}
+	list := make([]string, 0, len(codes))
+	for code := range codes {
+		list = append(list, code)
+	}
+	var active int64
+	if err := tx.Model(&domain.Account{}).Where("code IN ? AND active", list).Count(&active).Error; err != nil {
+		return err
+	}
+	if int(active) != len(list) {
+		return domain.NewError(domain.ErrValidation, "invalid_account", "journal entry posts to an unknown or inactive account")
+	}
+
+	for i := range entries {
+		if err := tx.Create(&entries[i]).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetEntry implements LedgerRepository.GetEntry
+func (r *ledgerRepository) GetEntry(id uint) (*domain.JournalEntry, error) {
+	var entry domain.JournalEntry
+	err := r.db.Preload("Lines", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		First(&entry, id).Error
+	if err != nil {
+		return nil, translateNotFound(err, "journal_entry_not_found", "journal entry not found")
+	}
+	return &entry, nil
+}
+
+// ListEntries implements LedgerRepository.ListEntries, latest first
+func (r *ledgerRepository) ListEntries(filter domain.JournalFilter, offset, limit int) ([]domain.JournalEntry, error) {
+	query := r.db.Model(&domain.JournalEntry{})
+	if filter.TransactionID != nil {
+		query = query.Where("transaction_id = ?", *filter.TransactionID)
+	}
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.From != nil {
+		query = query.Where("posted_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("posted_at < ?", *filter.To)
+	}
+
+	var entries []domain.JournalEntry
+	err := query.Preload("Lines", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Order("posted_at desc, id desc").Offset(offset).Limit(limit).Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// ContractBalances implements LedgerRepository.ContractBalances
+func (r *ledgerRepository) ContractBalances(transactionID uint) (map[string]float64, error) {
+	var rows []struct {
+		AccountCode string
+		Balance     float64
+	}
+	err := r.db.Raw(`SELECT l."account_code", SUM(l."debit" - l."credit") AS balance
+		FROM "journal_lines" l
+		JOIN "journal_entries" e ON e."id" = l."entry_id"
+		WHERE e."transaction_id" = ?
+		GROUP BY l."account_code"`,
+		transactionID,
+	).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make(map[string]float64, len(rows))
+	for _, row := range rows {
+		balances[row.AccountCode] = row.Balance
+	}
+	return balances, nil
+}
+
+// ListUnreversed implements LedgerRepository.ListUnreversed
+func (r *ledgerRepository) ListUnreversed(entryType domain.JournalType, reference string) ([]domain.JournalEntry, error) {
+	var entries []domain.JournalEntry
+	err := r.db.Preload("Lines", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Where("type = ? AND reference = ?", entryType, reference).
+		Where(`NOT EXISTS (SELECT 1 FROM "journal_entries" r WHERE r."reversal_of" = "journal_entries"."id")`).
+		Order("id desc").Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Balances implements LedgerRepository.Balances
+func (r *ledgerRepository) Balances(asOf time.Time) ([]domain.AccountBalance, error) {
+	var balances []domain.AccountBalance
+	err := r.db.Raw(`SELECT a.*, COALESCE(b."balance", 0) AS balance
+		FROM "accounts" a
+		LEFT JOIN (
+			SELECT l."account_code", SUM(l."debit" - l."credit") AS balance
+			FROM "journal_lines" l
+			JOIN "journal_entries" e ON e."id" = l."entry_id"
+			WHERE e."posted_at" < ?
+			GROUP BY l."account_code"
+		) b ON b."account_code" = a."code"
+		ORDER BY a."code"`,
+		asOf,
+	).Scan(&balances).Error
+	if err != nil {
+		return nil, err
+	}
+	return balances, nil
+}
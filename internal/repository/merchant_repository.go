@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"xyz-multifinance/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type merchantRepository struct {
+	db *gorm.DB
+}
+
+// NewMerchantRepository creates a new instance of MerchantRepository
+func NewMerchantRepository(db *gorm.DB) domain.MerchantRepository {
+	return &merchantRepository{
+		db: db,
+	}
+}
+
+// Create implements MerchantRepository.Create
+func (r *merchantRepository) Create(merchant *domain.Merchant) error {
+	return r.db.Create(merchant).Error
+}
+
+// GetByID implements MerchantRepository.GetByID
+func (r *merchantRepository) GetByID(id uint) (*domain.Merchant, error) {
+	var merchant domain.Merchant
+	err := r.db.First(&merchant, id).Error
+	if err != nil {
+		return nil, translateNotFound(err, "merchant_not_found", "merchant not found")
+	}
+	return &merchant, nil
+}
+
+// List implements MerchantRepository.List
+func (r *merchantRepository) List(offset, limit int) ([]domain.Merchant, error) {
+	var merchants []domain.Merchant
+	err := r.db.Order("id asc").Offset(offset).Limit(limit).Find(&merchants).Error
+	if err != nil {
+		return nil, err
+	}
+	return merchants, nil
+}
+
+// Update implements MerchantRepository.Update
+func (r *merchantRepository) Update(merchant *domain.Merchant) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Get and lock current version
+		var current domain.Merchant
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("version").First(&current, merchant.ID).Error; err != nil {
+			return translateNotFound(err, "merchant_not_found", "merchant not found")
+		}
+
+		// Check version
+		if current.Version != merchant.Version {
+			return errConcurrentModification()
+		}
+
+		// Increment version
+		merchant.Version++
+
+		return tx.Save(merchant).Error
+	})
+}
+
+// Delete implements MerchantRepository.Delete
+func (r *merchantRepository) Delete(id uint) error {
+	result := r.db.Delete(&domain.Merchant{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.NewError(domain.ErrNotFound, "merchant_not_found", "merchant not found")
+	}
+	return nil
+}
+
+// HasPendingSettlements implements MerchantRepository.HasPendingSettlements.
+// Money is still owed while approved contracts are unsettled or a
+// settlement is unpaid.
+func (r *merchantRepository) HasPendingSettlements(id uint) (bool, error) {
+	var count int64
+	err := r.db.Raw(`SELECT (SELECT COUNT(*) FROM "transactions" WHERE "merchant_id" = ? AND "status" = ? AND "deleted_at" IS NULL AND NOT EXISTS (SELECT 1 FROM "settlement_items" WHERE "settlement_items"."transaction_id" = "transactions"."id")) + (SELECT COUNT(*) FROM "settlements" WHERE "merchant_id" = ? AND "status" = ?)`,
+		id, domain.StatusApproved, id, domain.SettlementPending,
+	).Scan(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
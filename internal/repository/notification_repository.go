@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"xyz-multifinance/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+// NewNotificationRepository creates a new instance of NotificationRepository
+func NewNotificationRepository(db *gorm.DB) domain.NotificationRepository {
+	return &notificationRepository{
+		db: db,
+	}
+}
+
+// GetPreference implements NotificationRepository.GetPreference
+func (r *notificationRepository) GetPreference(customerID uint) (*domain.NotificationPreference, error) {
+	var preference domain.NotificationPreference
+	err := r.db.Where("customer_id = ?", customerID).First(&preference).Error
+	if err != nil {
+		return nil, translateNotFound(err, "notification_preference_not_found", "notification preference not found")
+	}
+	return &preference, nil
+}
+
+// SavePreference implements NotificationRepository.SavePreference, creating
+// the preference on first use
+func (r *notificationRepository) SavePreference(preference *domain.NotificationPreference) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "customer_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"locale", "email", "phone", "push_token",
+			"email_enabled", "sms_enabled", "push_enabled", "updated_at",
+		}),
+	}).Create(preference).Error
+}
+
+// HasSent implements NotificationRepository.HasSent
+func (r *notificationRepository) HasSent(reference string, channel domain.NotificationChannel) (bool, error) {
+	var count int64
+	err := r.db.Model(&domain.Notification{}).
+		Where("reference = ? AND channel = ? AND status = ?", reference, channel, domain.NotificationSent).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Record implements NotificationRepository.Record. A later attempt on the
+// same reference and channel replaces the outcome of the earlier one.
+func (r *notificationRepository) Record(notification *domain.Notification) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "reference"}, {Name: "channel"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"recipient":  notification.Recipient,
+			"subject":    notification.Subject,
+			"body":       notification.Body,
+			"status":     notification.Status,
+			"attempts":   gorm.Expr("notifications.attempts + 1"),
+			"last_error": notification.LastError,
+			"sent_at":    notification.SentAt,
+			"updated_at": notification.UpdatedAt,
+		}),
+	}).Create(notification).Error
+}
+
+// List implements NotificationRepository.List, newest first
+func (r *notificationRepository) List(customerID uint, offset, limit int) ([]domain.Notification, error) {
+	var notifications []domain.Notification
+	err := r.db.Where("customer_id = ?", customerID).
+		Order("created_at desc, id desc").
+		Offset(offset).
+		Limit(limit).
+		Find(&notifications).Error
+	if err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"time"
+	"xyz-multifinance/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type outboxRepository struct {
+	db *gorm.DB
+}
+
+// NewOutboxRepository creates a new instance of OutboxRepository
+func NewOutboxRepository(db *gorm.DB) domain.OutboxRepository {
+	return &outboxRepository{
+		db: db,
+	}
+}
+
+// PublishPending implements OutboxRepository.PublishPending. Unpublished
+// events are locked, handed to publish in order and marked published when it
+// succeeds. A failure after some events were published publishes them again
+// on the next run, so consumers see each event at least once.
+func (r *outboxRepository) PublishPending(limit int, publish func(events []domain.OutboxEvent) error) (int, error) {
+	var count int
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var events []domain.OutboxEvent
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("published_at IS NULL").
+			Order("id asc").
+			Limit(limit).
+			Find(&events).Error
+		if err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+
+		if err := publish(events); err != nil {
+			return err
+		}
+
+		ids := make([]uint, len(events))
+		for i, event := range events {
+			ids[i] = event.ID
+		}
+		err = tx.Model(&domain.OutboxEvent{}).
+			Where("id IN ?", ids).
+			Update("published_at", time.Now()).Error
+		if err != nil {
+			return err
+		}
+
+		count = len(events)
+		return nil
+	})
+	return count, err
+}
+
+// writeOutbox records events in the caller's database transaction so they
+// are only published when the state change that raised them commits
+func writeOutbox(tx *gorm.DB, events []domain.OutboxEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return tx.Create(&events).Error
+}
+
+// recordProcessed records that a consumer applied an event in the caller's
+// transaction, returning domain.ErrEventProcessed when it already did
+func recordProcessed(tx *gorm.DB, event *domain.ProcessedEvent) error {
+	if event == nil {
+		return nil
+	}
+
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(event)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrEventProcessed
+	}
+	return nil
+}
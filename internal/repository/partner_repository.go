@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"time"
+	"xyz-multifinance/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type partnerRepository struct {
+	db *gorm.DB
+}
+
+// NewPartnerRepository creates a new instance of PartnerRepository
+func NewPartnerRepository(db *gorm.DB) domain.PartnerRepository {
+	return &partnerRepository{
+		db: db,
+	}
+}
+
+// Create implements PartnerRepository.Create
+func (r *partnerRepository) Create(partner *domain.Partner) error {
+	return r.db.Create(partner).Error
+}
+
+// GetByID implements PartnerRepository.GetByID
+func (r *partnerRepository) GetByID(id uint) (*domain.Partner, error) {
+	var partner domain.Partner
+	err := r.db.First(&partner, id).Error
+	if err != nil {
+		return nil, translateNotFound(err, "partner_not_found", "partner not found")
+	}
+	return &partner, nil
+}
+
+// List implements PartnerRepository.List
+func (r *partnerRepository) List(offset, limit int) ([]domain.Partner, error) {
+	var partners []domain.Partner
+	err := r.db.Order("id asc").Offset(offset).Limit(limit).Find(&partners).Error
+	if err != nil {
+		return nil, err
+	}
+	return partners, nil
+}
+
+// UpdateStatus implements PartnerRepository.UpdateStatus
+func (r *partnerRepository) UpdateStatus(id uint, status domain.PartnerStatus) error {
+	result := r.db.Model(&domain.Partner{}).Where("id = ?", id).Update("status", status)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.NewError(domain.ErrNotFound, "partner_not_found", "partner not found")
+	}
+	return nil
+}
+
+// CreateKey implements PartnerRepository.CreateKey
+func (r *partnerRepository) CreateKey(key *domain.PartnerAPIKey) error {
+	return r.db.Create(key).Error
+}
+
+// GetKeyByKeyID implements PartnerRepository.GetKeyByKeyID
+func (r *partnerRepository) GetKeyByKeyID(keyID string) (*domain.PartnerAPIKey, error) {
+	var key domain.PartnerAPIKey
+	err := r.db.Where("key_id = ?", keyID).First(&key).Error
+	if err != nil {
+		return nil, translateNotFound(err, "api_key_not_found", "api key not found")
+	}
+	return &key, nil
+}
+
+// ListKeys implements PartnerRepository.ListKeys
+func (r *partnerRepository) ListKeys(partnerID uint) ([]domain.PartnerAPIKey, error) {
+	var keys []domain.PartnerAPIKey
+	err := r.db.Where("partner_id = ?", partnerID).Order("created_at desc").Find(&keys).Error
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// RotateKeys implements PartnerRepository.RotateKeys. The partner's other
+// active keys stay valid until expiresAt so clients can switch over.
+func (r *partnerRepository) RotateKeys(key *domain.PartnerAPIKey, expiresAt time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&domain.PartnerAPIKey{}).
+			Where("partner_id = ? AND status = ? AND (expires_at IS NULL OR expires_at > ?)", key.PartnerID, domain.APIKeyActive, expiresAt).
+			Update("expires_at", expiresAt).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(key).Error
+	})
+}
+
+// RevokeKey implements PartnerRepository.RevokeKey
+func (r *partnerRepository) RevokeKey(partnerID uint, keyID string) error {
+	result := r.db.Model(&domain.PartnerAPIKey{}).
+		Where("partner_id = ? AND key_id = ? AND status = ?", partnerID, keyID, domain.APIKeyActive).
+		Update("status", domain.APIKeyRevoked)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.NewError(domain.ErrNotFound, "api_key_not_found", "api key not found")
+	}
+	return nil
+}
+
+// TouchKey implements PartnerRepository.TouchKey
+func (r *partnerRepository) TouchKey(id uint, usedAt time.Time) error {
+	return r.db.Model(&domain.PartnerAPIKey{}).Where("id = ?", id).UpdateColumn("last_used_at", usedAt).Error
+}
@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"time"
+	"xyz-multifinance/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type paymentRepository struct {
+	db *gorm.DB
+}
+
+// NewPaymentRepository creates a new instance of PaymentRepository
+func NewPaymentRepository(db *gorm.DB) domain.PaymentRepository {
+	return &paymentRepository{
+		db: db,
+	}
+}
+
+// CreateVirtualAccount implements PaymentRepository.CreateVirtualAccount. A
+// contract has at most one virtual account per bank.
+func (r *paymentRepository) CreateVirtualAccount(account *domain.VirtualAccount) error {
+	result := r.db.Omit("Transaction").Clauses(clause.OnConflict{DoNothing: true}).Create(account)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.NewError(domain.ErrConflict, "virtual_account_exists", "virtual account already exists")
+	}
+	return nil
+}
+
+// GetVirtualAccountByNumber implements PaymentRepository.GetVirtualAccountByNumber
+func (r *paymentRepository) GetVirtualAccountByNumber(number string) (*domain.VirtualAccount, error) {
+	var account domain.VirtualAccount
+	err := r.db.Where("number = ?", number).First(&account).Error
+	if err != nil {
+		return nil, translateNotFound(err, "virtual_account_not_found", "virtual account not found")
+	}
+	return &account, nil
+}
+
+// ListVirtualAccounts implements PaymentRepository.ListVirtualAccounts
+func (r *paymentRepository) ListVirtualAccounts(transactionID uint) ([]domain.VirtualAccount, error) {
+	var accounts []domain.VirtualAccount
+	err := r.db.Where("transaction_id = ?", transactionID).Order("id asc").Find(&accounts).Error
+	if err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+// CreatePayment implements PaymentRepository.CreatePayment. A bank reports
+// each reference once; a repeated reference is a conflict.
+func (r *paymentRepository) CreatePayment(payment *domain.Payment) error {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(payment)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.NewError(domain.ErrConflict, "duplicate_payment", "payment reference already recorded")
+	}
+	return nil
+}
+
+// GetPayment implements PaymentRepository.GetPayment
+func (r *paymentRepository) GetPayment(id uint) (*domain.Payment, error) {
+	var payment domain.Payment
+	err := r.db.First(&payment, id).Error
+	if err != nil {
+		return nil, translateNotFound(err, "payment_not_found", "payment not found")
+	}
+	return &payment, nil
+}
+
+// GetPaymentByReference implements PaymentRepository.GetPaymentByReference
+func (r *paymentRepository) GetPaymentByReference(bankCode, reference string) (*domain.Payment, error) {
+	var payment domain.Payment
+	err := r.db.Where("bank_code = ? AND reference = ?", bankCode, reference).First(&payment).Error
+	if err != nil {
+		return nil, translateNotFound(err, "payment_not_found", "payment not found")
+	}
+	return &payment, nil
+}
+
+// UpdatePayment implements PaymentRepository.UpdatePayment
+func (r *paymentRepository) UpdatePayment(payment *domain.Payment) error {
+	return r.db.Model(payment).
+		Select("InstallmentID", "Status", "RejectReason").
+		Updates(payment).Error
+}
+
+// ListPayments implements PaymentRepository.ListPayments, listing payments
+// of the bank made in [from, to)
+func (r *paymentRepository) ListPayments(bankCode string, from, to time.Time) ([]domain.Payment, error) {
+	var payments []domain.Payment
+	err := r.db.Where("bank_code = ? AND paid_at >= ? AND paid_at < ?", bankCode, from, to).
+		Order("paid_at asc, id asc").
+		Find(&payments).Error
+	if err != nil {
+		return nil, err
+	}
+	return payments, nil
+}
+
+// CreateReconciliation implements PaymentRepository.CreateReconciliation,
+// storing the reconciliation together with its mismatches. One statement
+// per bank and date is reconciled.
+func (r *paymentRepository) CreateReconciliation(reconciliation *domain.Reconciliation) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Omit("Mismatches").Clauses(clause.OnConflict{DoNothing: true}).Create(reconciliation)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.NewError(domain.ErrConflict, "statement_already_reconciled", "statement already reconciled")
+		}
+
+		for i := range reconciliation.Mismatches {
+			reconciliation.Mismatches[i].ReconciliationID = reconciliation.ID
+		}
+		if len(reconciliation.Mismatches) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(reconciliation.Mismatches, 100).Error
+	})
+}
+
+// GetReconciliation implements PaymentRepository.GetReconciliation
+func (r *paymentRepository) GetReconciliation(id uint) (*domain.Reconciliation, error) {
+	var reconciliation domain.Reconciliation
+	err := r.db.Preload("Mismatches", func(db *gorm.DB) *gorm.DB { return db.Order("id asc") }).
+		First(&reconciliation, id).Error
+	if err != nil {
+		return nil, translateNotFound(err, "reconciliation_not_found", "reconciliation not found")
+	}
+	return &reconciliation, nil
+}
+
+// FindReconciliation implements PaymentRepository.FindReconciliation
+func (r *paymentRepository) FindReconciliation(bankCode string, statementDate time.Time) (*domain.Reconciliation, error) {
+	var reconciliation domain.Reconciliation
+	err := r.db.Where("bank_code = ? AND statement_date = ?", bankCode, statementDate.Format("2006-01-02")).
+		First(&reconciliation).Error
+	if err != nil {
+		return nil, translateNotFound(err, "reconciliation_not_found", "reconciliation not found")
+	}
+	return &reconciliation, nil
+}
+
+// ListReconciliations implements PaymentRepository.ListReconciliations. An
+// empty bankCode lists the reconciliations of all banks.
+func (r *paymentRepository) ListReconciliations(bankCode string, offset, limit int) ([]domain.Reconciliation, error) {
+	var reconciliations []domain.Reconciliation
+	query := r.db.Order("statement_date desc, id desc").Offset(offset).Limit(limit)
+	if bankCode != "" {
+		query = query.Where("bank_code = ?", bankCode)
+	}
+	if err := query.Find(&reconciliations).Error; err != nil {
+		return nil, err
+	}
+	return reconciliations, nil
+}
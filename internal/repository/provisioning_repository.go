@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"time"
+	"xyz-multifinance/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type provisioningRepository struct {
+	db *gorm.DB
+}
+
+// NewProvisioningRepository creates a new instance of ProvisioningRepository
+func NewProvisioningRepository(db *gorm.DB) domain.ProvisioningRepository {
+	return &provisioningRepository{
+		db: db,
+	}
+}
+
+// ListExposures implements ProvisioningRepository.ListExposures
+func (r *provisioningRepository) ListExposures(asOf time.Time, afterTransactionID uint, limit int) ([]domain.Exposure, error) {
+	var exposures []domain.Exposure
+	err := r.db.Raw(`SELECT t."id" AS transaction_id, t."contract_number", t."customer_id",
+			SUM(i."amount") AS outstanding, MIN(CASE WHEN i."due_date" < ? THEN i."due_date" END) AS oldest_overdue
+		FROM "transactions" t
+		JOIN "installments" i ON i."transaction_id" = t."id"
+		WHERE t."status" = ? AND t."deleted_at" IS NULL AND t."id" > ? AND i."status" IN (?,?)
+		GROUP BY t."id", t."contract_number", t."customer_id"
+		ORDER BY t."id"
+		LIMIT ?`,
+		asOf, domain.StatusApproved, afterTransactionID, "unpaid", "overdue", limit,
+	).Scan(&exposures).Error
+	if err != nil {
+		return nil, err
+	}
+	return exposures, nil
+}
+
+// WriteOffTotals implements ProvisioningRepository.WriteOffTotals
+func (r *provisioningRepository) WriteOffTotals(from, to time.Time) (int, float64, float64, error) {
+	var writeOffs struct {
+		Count  int
+		Amount float64
+	}
+	err := r.db.Raw(`SELECT COUNT(*) AS count, COALESCE(SUM("amount" + "late_fees"), 0) AS amount FROM "write_offs" WHERE "written_off_at" >= ? AND "written_off_at" < ?`,
+		from, to,
+	).Scan(&writeOffs).Error
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	var recovered float64
+	err = r.db.Raw(`SELECT COALESCE(SUM("amount"), 0) FROM "recoveries" WHERE "received_at" >= ? AND "received_at" < ?`,
+		from, to,
+	).Scan(&recovered).Error
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	return writeOffs.Count, writeOffs.Amount, recovered, nil
+}
+
+// CreateReport implements ProvisioningRepository.CreateReport. A period has
+// one report.
+func (r *provisioningRepository) CreateReport(report *domain.ProvisionReport) error {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(report)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.NewError(domain.ErrConflict, "provision_report_exists", "provision report of the period exists already")
+	}
+	return nil
+}
+
+// GetReport implements ProvisioningRepository.GetReport
+func (r *provisioningRepository) GetReport(period string) (*domain.ProvisionReport, error) {
+	var report domain.ProvisionReport
+	if err := r.db.Where("period = ?", period).First(&report).Error; err != nil {
+		return nil, translateNotFound(err, "provision_report_not_found", "provision report not found")
+	}
+	return &report, nil
+}
+
+// ListReports implements ProvisioningRepository.ListReports, latest period first
+func (r *provisioningRepository) ListReports(offset, limit int) ([]domain.ProvisionReport, error) {
+	var reports []domain.ProvisionReport
+	if err := r.db.Order("period desc").Offset(offset).Limit(limit).Find(&reports).Error; err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
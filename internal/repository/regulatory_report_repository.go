@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"time"
+	"xyz-multifinance/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type regulatoryReportRepository struct {
+	db *gorm.DB
+}
+
+// NewRegulatoryReportRepository creates a new instance of
+// RegulatoryReportRepository
+func NewRegulatoryReportRepository(db *gorm.DB) domain.RegulatoryReportRepository {
+	return &regulatoryReportRepository{
+		db: db,
+	}
+}
+
+// ListReportable implements RegulatoryReportRepository.ListReportable.
+// Approved contracts are closed once no installment is outstanding, on the
+// last payment; written off contracts on their write-off. Customers are read
+// unscoped, as contracts of erased customers are still reported.
+func (r *regulatoryReportRepository) ListReportable(from, to time.Time, afterTransactionID uint, limit int) ([]domain.Transaction, error) {
+	var transactions []domain.Transaction
+	err := r.db.
+		Preload("Customer", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Preload("Installments", func(db *gorm.DB) *gorm.DB { return db.Order("installment_number") }).
+		Where(`"status" IN ? AND "created_at" < ? AND "id" > ?`, []domain.TransactionStatus{domain.StatusApproved, domain.StatusWrittenOff}, to, afterTransactionID).
+		Where(`NOT ("status" = ? AND NOT EXISTS (SELECT 1 FROM "installments" i WHERE i."transaction_id" = "transactions"."id" AND (i."status" IN (?,?) OR i."paid_at" >= ?)))`,
+			domain.StatusApproved, "unpaid", "overdue", from).
+		Where(`NOT ("status" = ? AND EXISTS (SELECT 1 FROM "write_offs" w WHERE w."transaction_id" = "transactions"."id" AND w."written_off_at" < ?))`,
+			domain.StatusWrittenOff, from).
+		Order("id").
+		Limit(limit).
+		Find(&transactions).Error
+	if err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// ListWriteOffs implements RegulatoryReportRepository.ListWriteOffs
+func (r *regulatoryReportRepository) ListWriteOffs(transactionIDs []uint) (map[uint]domain.WriteOff, error) {
+	writeOffs := make(map[uint]domain.WriteOff, len(transactionIDs))
+	if len(transactionIDs) == 0 {
+		return writeOffs, nil
+	}
+
+	var found []domain.WriteOff
+	if err := r.db.Where("transaction_id IN ?", transactionIDs).Find(&found).Error; err != nil {
+		return nil, err
+	}
+	for _, writeOff := range found {
+		writeOffs[writeOff.TransactionID] = writeOff
+	}
+	return writeOffs, nil
+}
+
+// Create implements RegulatoryReportRepository.Create
+func (r *regulatoryReportRepository) Create(report *domain.RegulatoryReport, audit *domain.AuditLog) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(report).Error; err != nil {
+			return err
+		}
+		audit.EntityID = report.ID
+		return writeAudit(tx, audit)
+	})
+}
+
+// GetByID implements RegulatoryReportRepository.GetByID
+func (r *regulatoryReportRepository) GetByID(id uint) (*domain.RegulatoryReport, error) {
+	var report domain.RegulatoryReport
+	if err := r.db.First(&report, id).Error; err != nil {
+		return nil, translateNotFound(err, "regulatory_report_not_found", "regulatory report not found")
+	}
+	return &report, nil
+}
+
+// List implements RegulatoryReportRepository.List
+func (r *regulatoryReportRepository) List(filter domain.RegulatoryReportFilter, offset, limit int) ([]domain.RegulatoryReport, error) {
+	query := r.db.Omit("content")
+	if filter.Period != "" {
+		query = query.Where("period = ?", filter.Period)
+	}
+	if filter.Segment != "" {
+		query = query.Where("segment = ?", filter.Segment)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var reports []domain.RegulatoryReport
+	if err := query.Order("id desc").Offset(offset).Limit(limit).Find(&reports).Error; err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+// Submit implements RegulatoryReportRepository.Submit. A unique index on the
+// submitted files of a period and segment settles concurrent submissions.
+func (r *regulatoryReportRepository) Submit(report *domain.RegulatoryReport, audit *domain.AuditLog) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Exec(`UPDATE "regulatory_reports" SET "status"=?,"submitted_by"=?,"submitted_at"=?,"submission_reference"=?
+			WHERE "id"=? AND "status"=? AND NOT EXISTS (
+				SELECT 1 FROM "regulatory_reports" s WHERE s."period"=? AND s."segment"=? AND s."status"=?)`,
+			domain.ReportSubmitted, report.SubmittedBy, report.SubmittedAt, report.SubmissionReference,
+			report.ID, domain.ReportGenerated, report.Period, report.Segment, domain.ReportSubmitted,
+		)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.NewError(domain.ErrConflict, "period_submitted", "a report of the period and segment was submitted already")
+		}
+		return writeAudit(tx, audit)
+	})
+}
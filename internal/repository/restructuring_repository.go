@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"time"
+	"xyz-multifinance/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type restructuringRepository struct {
+	db *gorm.DB
+}
+
+// NewRestructuringRepository creates a new instance of RestructuringRepository
+func NewRestructuringRepository(db *gorm.DB) domain.RestructuringRepository {
+	return &restructuringRepository{
+		db: db,
+	}
+}
+
+// Create implements RestructuringRepository.Create. A contract has at most
+// one pending restructuring.
+func (r *restructuringRepository) Create(restructuring *domain.Restructuring, audit *domain.AuditLog) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Omit("Schedule").Clauses(clause.OnConflict{DoNothing: true}).Create(restructuring)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.NewError(domain.ErrConflict, "restructuring_pending", "contract already has a pending restructuring")
+		}
+
+		audit.EntityID = restructuring.ID
+		return writeAudit(tx, audit)
+	})
+}
+
+// GetByID implements RestructuringRepository.GetByID
+func (r *restructuringRepository) GetByID(id uint) (*domain.Restructuring, error) {
+	var restructuring domain.Restructuring
+	err := r.db.Preload("Schedule", func(db *gorm.DB) *gorm.DB { return db.Order("installment_number asc") }).
+		First(&restructuring, id).Error
+	if err != nil {
+		return nil, translateNotFound(err, "restructuring_not_found", "restructuring not found")
+	}
+	return &restructuring, nil
+}
+
+// ListByTransaction implements RestructuringRepository.ListByTransaction, newest first
+func (r *restructuringRepository) ListByTransaction(transactionID uint) ([]domain.Restructuring, error) {
+	var restructurings []domain.Restructuring
+	err := r.db.Where("transaction_id = ?", transactionID).Order("id desc").Find(&restructurings).Error
+	if err != nil {
+		return nil, err
+	}
+	return restructurings, nil
+}
+
+// Approve implements RestructuringRepository.Approve. Superseded
+// installments are checked against the version they were read at, so an
+// installment paid meanwhile fails the approval as a whole.
+func (r *restructuringRepository) Approve(restructuring *domain.Restructuring, audit *domain.AuditLog) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := review(tx, restructuring); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, installment := range restructuring.Superseded {
+			result := tx.Exec(`UPDATE "installments" SET "status"=?,"superseded_by"=?,"version"="version"+1,"updated_at"=? WHERE "id"=? AND "version"=? AND "status" IN (?,?)`,
+				"superseded", restructuring.ID, now, installment.ID, installment.Version, "unpaid", "overdue",
+			)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return errConcurrentModification()
+			}
+		}
+
+		for i := range restructuring.Schedule {
+			restructuring.Schedule[i].RestructuringID = &restructuring.ID
+		}
+		if err := tx.Omit("Transaction").Create(&restructuring.Schedule).Error; err != nil {
+			return err
+		}
+
+		result := tx.Exec(`UPDATE "transactions" SET "installment_amount"=?,"restructured_amount"="restructured_amount"+?,"version"="version"+1,"updated_at"=? WHERE "id"=?`,
+			restructuring.InstallmentAmount, restructuring.AddedAmount(), now, restructuring.TransactionID,
+		)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if err := writeAudit(tx, audit); err != nil {
+			return err
+		}
+		return writeOutbox(tx, restructuring.Events)
+	})
+}
+
+// Reject implements RestructuringRepository.Reject
+func (r *restructuringRepository) Reject(restructuring *domain.Restructuring, audit *domain.AuditLog) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := review(tx, restructuring); err != nil {
+			return err
+		}
+		return writeAudit(tx, audit)
+	})
+}
+
+// review records the decision on a pending restructuring within tx
+func review(tx *gorm.DB, restructuring *domain.Restructuring) error {
+	result := tx.Model(&domain.Restructuring{}).
+		Where("id = ? AND status = ?", restructuring.ID, domain.RestructuringPending).
+		Updates(map[string]interface{}{
+			"status":             restructuring.Status,
+			"reviewed_by":        restructuring.ReviewedBy,
+			"review_note":        restructuring.ReviewNote,
+			"reviewed_at":        restructuring.ReviewedAt,
+			"outstanding_amount": restructuring.OutstandingAmount,
+			"installment_amount": restructuring.InstallmentAmount,
+			"first_due_date":     restructuring.FirstDueDate,
+			"total_amount":       restructuring.TotalAmount,
+			"updated_at":         time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errRestructuringNotPending()
+	}
+	return nil
+}
+
+func errRestructuringNotPending() error {
+	return domain.NewError(domain.ErrConflict, "restructuring_not_pending", "restructuring is no longer pending")
+}
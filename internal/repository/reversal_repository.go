@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"time"
+	"xyz-multifinance/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type reversalRepository struct {
+	db *gorm.DB
+}
+
+// NewReversalRepository creates a new instance of ReversalRepository
+func NewReversalRepository(db *gorm.DB) domain.ReversalRepository {
+	return &reversalRepository{
+		db: db,
+	}
+}
+
+// ReverseInstallment implements ReversalRepository.ReverseInstallment. The
+// installment write is checked against its version and fencing token like
+// TransactionRepository.UpdateInstallment.
+func (r *reversalRepository) ReverseInstallment(installment *domain.Installment, reversal *domain.PaymentReversal, audit *domain.AuditLog) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := updateInstallment(tx, installment); err != nil {
+			return err
+		}
+		if err := tx.Omit("Refund").Create(reversal).Error; err != nil {
+			return err
+		}
+
+		if reversal.Refund != nil {
+			reversal.Refund.ReversalID = &reversal.ID
+			if err := tx.Create(reversal.Refund).Error; err != nil {
+				return err
+			}
+		}
+
+		audit.EntityID = installment.ID
+		if err := writeAudit(tx, audit); err != nil {
+			return err
+		}
+		return writeOutbox(tx, installment.Events)
+	})
+}
+
+// ListReversals implements ReversalRepository.ListReversals, newest first. A
+// zero transactionID matches every contract.
+func (r *reversalRepository) ListReversals(transactionID uint, offset, limit int) ([]domain.PaymentReversal, error) {
+	query := r.db.Preload("Refund")
+	if transactionID != 0 {
+		query = query.Where("transaction_id = ?", transactionID)
+	}
+
+	var reversals []domain.PaymentReversal
+	err := query.Order("id desc").Offset(offset).Limit(limit).Find(&reversals).Error
+	if err != nil {
+		return nil, err
+	}
+	return reversals, nil
+}
+
+// CreateRefund implements ReversalRepository.CreateRefund
+func (r *reversalRepository) CreateRefund(refund *domain.Refund, audit *domain.AuditLog) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(refund).Error; err != nil {
+			return err
+		}
+		audit.EntityID = refund.ID
+		return writeAudit(tx, audit)
+	})
+}
+
+// GetRefund implements ReversalRepository.GetRefund
+func (r *reversalRepository) GetRefund(id uint) (*domain.Refund, error) {
+	var refund domain.Refund
+	err := r.db.First(&refund, id).Error
+	if err != nil {
+		return nil, translateNotFound(err, "refund_not_found", "refund not found")
+	}
+	return &refund, nil
+}
+
+// CompleteRefund implements ReversalRepository.CompleteRefund. Only a
+// pending refund is completed, so concurrent completions conflict.
+func (r *reversalRepository) CompleteRefund(refund *domain.Refund, audit *domain.AuditLog) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&domain.Refund{}).
+			Where("id = ? AND status = ?", refund.ID, domain.RefundPending).
+			Updates(map[string]interface{}{
+				"status":       refund.Status,
+				"reference":    refund.Reference,
+				"completed_at": refund.CompletedAt,
+				"updated_at":   time.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.NewError(domain.ErrConflict, "refund_not_pending", "refund is no longer pending")
+		}
+		return writeAudit(tx, audit)
+	})
+}
+
+// ListRefunds implements ReversalRepository.ListRefunds, newest first. A
+// zero transactionID matches every contract.
+func (r *reversalRepository) ListRefunds(transactionID uint, offset, limit int) ([]domain.Refund, error) {
+	query := r.db.Model(&domain.Refund{})
+	if transactionID != 0 {
+		query = query.Where("transaction_id = ?", transactionID)
+	}
+
+	var refunds []domain.Refund
+	err := query.Order("id desc").Offset(offset).Limit(limit).Find(&refunds).Error
+	if err != nil {
+		return nil, err
+	}
+	return refunds, nil
+}
+
+// SumRefunds implements ReversalRepository.SumRefunds
+func (r *reversalRepository) SumRefunds(paymentID uint) (float64, error) {
+	var total float64
+	err := r.db.Model(&domain.Refund{}).
+		Where("payment_id = ?", paymentID).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&total).Error
+	return total, err
+}
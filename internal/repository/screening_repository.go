@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+	"xyz-multifinance/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type screeningRepository struct {
+	db *gorm.DB
+}
+
+// NewScreeningRepository creates a new instance of ScreeningRepository
+func NewScreeningRepository(db *gorm.DB) domain.ScreeningRepository {
+	return &screeningRepository{
+		db: db,
+	}
+}
+
+// CountContracts implements ScreeningRepository.CountContracts. Deleted
+// contracts still count, an attempt being what velocity rules measure.
+func (r *screeningRepository) CountContracts(key domain.VelocityKey, value string, since time.Time) (int, error) {
+	var column string
+	switch key {
+	case domain.VelocityCustomer:
+		column = "customer_id"
+	case domain.VelocityDevice:
+		column = "device_id"
+	default:
+		return 0, fmt.Errorf("unknown velocity key %q", key)
+	}
+
+	var count int64
+	err := r.db.Model(&domain.Transaction{}).
+		Where(column+" = ? AND created_at >= ?", value, since).
+		Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+// FindDuplicateIdentities implements ScreeningRepository.FindDuplicateIdentities.
+// Names and places are compared ignoring case and surrounding spaces.
+func (r *screeningRepository) FindDuplicateIdentities(customer *domain.Customer) ([]domain.Customer, error) {
+	var customers []domain.Customer
+	err := r.db.
+		Where("LOWER(TRIM(full_name)) = LOWER(TRIM(?)) AND date_of_birth = ? AND LOWER(TRIM(place_of_birth)) = LOWER(TRIM(?)) AND nik <> ?",
+			customer.FullName, customer.DateOfBirth, customer.PlaceOfBirth, customer.NIK).
+		Order("id").
+		Find(&customers).Error
+	if err != nil {
+		return nil, err
+	}
+	return customers, nil
+}
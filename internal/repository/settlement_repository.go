@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"time"
+	"xyz-multifinance/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type settlementRepository struct {
+	db *gorm.DB
+}
+
+// NewSettlementRepository creates a new instance of SettlementRepository
+func NewSettlementRepository(db *gorm.DB) domain.SettlementRepository {
+	return &settlementRepository{
+		db: db,
+	}
+}
+
+// ListUnsettledTransactions implements SettlementRepository.ListUnsettledTransactions
+func (r *settlementRepository) ListUnsettledTransactions(cutoff time.Time, limit int) ([]domain.Transaction, error) {
+	var transactions []domain.Transaction
+	err := r.db.Where(`"status" = ? AND "merchant_id" IS NOT NULL AND "deleted_at" IS NULL AND "updated_at" <= ? AND NOT EXISTS (SELECT 1 FROM "settlement_items" WHERE "settlement_items"."transaction_id" = "transactions"."id")`,
+		domain.StatusApproved, cutoff).
+		Order("merchant_id asc, id asc").
+		Limit(limit).
+		Find(&transactions).Error
+	if err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// Create implements SettlementRepository.Create. The unique transaction_id
+// of settlement items rejects the whole batch when a contract was already
+// settled by a concurrent run.
+func (r *settlementRepository) Create(settlement *domain.Settlement) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return tx.Omit("Merchant").Create(settlement).Error
+	})
+}
+
+// GetByID implements SettlementRepository.GetByID
+func (r *settlementRepository) GetByID(id uint) (*domain.Settlement, error) {
+	var settlement domain.Settlement
+	err := r.db.Preload("Merchant", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id asc") }).
+		First(&settlement, id).Error
+	if err != nil {
+		return nil, translateNotFound(err, "settlement_not_found", "settlement not found")
+	}
+	return &settlement, nil
+}
+
+// ListByMerchant implements SettlementRepository.ListByMerchant
+func (r *settlementRepository) ListByMerchant(merchantID uint, offset, limit int) ([]domain.Settlement, error) {
+	var settlements []domain.Settlement
+	err := r.db.Where("merchant_id = ?", merchantID).
+		Order("settlement_date desc, id desc").
+		Offset(offset).Limit(limit).
+		Find(&settlements).Error
+	if err != nil {
+		return nil, err
+	}
+	return settlements, nil
+}
+
+// MarkPaid implements SettlementRepository.MarkPaid
+func (r *settlementRepository) MarkPaid(id uint, reference string, paidAt time.Time) error {
+	result := r.db.Model(&domain.Settlement{}).
+		Where("id = ? AND status = ?", id, domain.SettlementPending).
+		Updates(map[string]interface{}{
+			"status":            domain.SettlementPaid,
+			"payment_reference": reference,
+			"paid_at":           paidAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.NewError(domain.ErrConflict, "settlement_not_pending", "settlement is not pending")
+	}
+	return nil
+}
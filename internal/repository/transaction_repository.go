@@ -1,12 +1,13 @@
 package repository
 
 import (
-	"errors"
+	"encoding/json"
 	"fmt"
 	"time"
 	"xyz-multifinance/internal/domain"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type transactionRepository struct {
@@ -26,13 +27,39 @@ func (r *transactionRepository) Create(transaction *domain.Transaction) error {
 		// Set initial version
 		transaction.Version = 1
 
+		if transaction.RiskFlag == "" {
+			transaction.RiskFlag = domain.RiskClear
+		}
+		var riskHits interface{}
+		if len(transaction.RiskHits) > 0 {
+			encoded, err := json.Marshal(transaction.RiskHits)
+			if err != nil {
+				return err
+			}
+			riskHits = string(encoded)
+		}
+
+		// Hold the customer until the contract is committed, so it cannot be
+		// deleted in between
+		var customer struct {
+			ID uint
+		}
+		locked := tx.Raw(`SELECT id FROM "customers" WHERE "customers"."id" = ? AND "customers"."deleted_at" IS NULL FOR SHARE`, transaction.CustomerID).Scan(&customer)
+		if locked.Error != nil {
+			return locked.Error
+		}
+		if locked.RowsAffected == 0 {
+			return domain.NewError(domain.ErrNotFound, "customer_not_found", "customer not found")
+		}
+
 		// Create transaction with specific column order using raw SQL
-		result := tx.Raw(`INSERT INTO "transactions" ("customer_id","contract_number","source","status","asset_name","otr_amount","admin_fee","installment_amount","interest_amount","tenor","version","created_at","updated_at","deleted_at") VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?) RETURNING "id"`,
+		result := tx.Raw(`INSERT INTO "transactions" ("customer_id","contract_number","source","partner_id","merchant_id","status","asset_name","otr_amount","admin_fee","installment_amount","interest_amount","tenor","device_id","risk_flag","risk_hits","version","created_at","updated_at","deleted_at") VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?) RETURNING "id"`,
 			transaction.CustomerID, transaction.ContractNumber,
-			transaction.Source, transaction.Status, transaction.AssetName,
+			transaction.Source, transaction.PartnerID, transaction.MerchantID, transaction.Status, transaction.AssetName,
 			transaction.OTRAmount, transaction.AdminFee,
 			transaction.InstallmentAmount, transaction.InterestAmount,
-			transaction.Tenor, transaction.Version,
+			transaction.Tenor, transaction.DeviceID, transaction.RiskFlag, riskHits,
+			transaction.Version,
 			time.Now(), time.Now(), nil,
 		).Scan(&transaction.ID)
 
@@ -40,11 +67,19 @@ func (r *transactionRepository) Create(transaction *domain.Transaction) error {
 			return result.Error
 		}
 
+		// Split the installments into the principal of the financed amount
+		// and interest
+		schedule := make([]domain.Installment, transaction.Tenor)
+		for i := range schedule {
+			schedule[i].Amount = transaction.InstallmentAmount
+		}
+		domain.SplitInstallments(transaction.OTRAmount+transaction.AdminFee, schedule)
+
 		// Create installments with specific column order using raw SQL
 		for i := 1; i <= transaction.Tenor; i++ {
 			dueDate := time.Now().AddDate(0, i, 0)
-			result := tx.Raw(`INSERT INTO "installments" ("transaction_id","installment_number","amount","status","due_date","version","created_at","updated_at","deleted_at") VALUES (?,?,?,?,?,?,?,?,?) RETURNING "id"`,
-				transaction.ID, i, transaction.InstallmentAmount, "unpaid",
+			result := tx.Raw(`INSERT INTO "installments" ("transaction_id","installment_number","amount","principal","interest","status","due_date","version","created_at","updated_at","deleted_at") VALUES (?,?,?,?,?,?,?,?,?,?,?) RETURNING "id"`,
+				transaction.ID, i, transaction.InstallmentAmount, schedule[i-1].Principal, schedule[i-1].Interest, "unpaid",
 				dueDate, 1, time.Now(), time.Now(), nil,
 			).Scan(new(uint))
 
@@ -53,7 +88,7 @@ func (r *transactionRepository) Create(transaction *domain.Transaction) error {
 			}
 		}
 
-		return nil
+		return writeOutbox(tx, transaction.Events)
 	})
 }
 
@@ -62,7 +97,7 @@ func (r *transactionRepository) GetByID(id uint) (*domain.Transaction, error) {
 	var transaction domain.Transaction
 	err := r.db.Preload("Customer").Preload("Installments").First(&transaction, id).Error
 	if err != nil {
-		return nil, err
+		return nil, translateNotFound(err, "transaction_not_found", "transaction not found")
 	}
 	return &transaction, nil
 }
@@ -73,20 +108,18 @@ func (r *transactionRepository) GetByContractNumber(contractNumber string) (*dom
 
 	// Get transaction
 	query := `SELECT * FROM "transactions" WHERE "contract_number" = ? AND "deleted_at" IS NULL`
-	if err := r.db.Raw(query, contractNumber).Scan(&transaction).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, err
-		}
-		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	result := r.db.Raw(query, contractNumber).Scan(&transaction)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get transaction: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, domain.NewError(domain.ErrNotFound, "transaction_not_found", "transaction not found")
 	}
 
 	// Get customer
 	var customer domain.Customer
 	customerQuery := `SELECT * FROM "customers" WHERE "id" = ? AND "deleted_at" IS NULL`
 	if err := r.db.Raw(customerQuery, transaction.CustomerID).Scan(&customer).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, err
-		}
 		return nil, fmt.Errorf("failed to get customer: %w", err)
 	}
 	transaction.Customer = &customer
@@ -105,15 +138,15 @@ func (r *transactionRepository) GetByContractNumber(contractNumber string) (*dom
 // Update implements TransactionRepository.Update
 func (r *transactionRepository) Update(tx *domain.Transaction) error {
 	return r.db.Transaction(func(db *gorm.DB) error {
-		// Get current version
+		// Get and lock current version
 		var current domain.Transaction
-		if err := db.Select("version").First(&current, tx.ID).Error; err != nil {
-			return err
+		if err := db.Clauses(clause.Locking{Strength: "UPDATE"}).Select("version").First(&current, tx.ID).Error; err != nil {
+			return translateNotFound(err, "transaction_not_found", "transaction not found")
 		}
 
 		// Check version
 		if current.Version != tx.Version {
-			return errors.New("concurrent modification detected")
+			return errConcurrentModification()
 		}
 
 		// Increment version
@@ -124,7 +157,7 @@ func (r *transactionRepository) Update(tx *domain.Transaction) error {
 			return err
 		}
 
-		return nil
+		return writeOutbox(db, tx.Events)
 	})
 }
 
@@ -134,10 +167,9 @@ func (r *transactionRepository) Delete(id uint) error {
 }
 
 // List implements TransactionRepository.List
-func (r *transactionRepository) List(customerID uint, offset, limit int) ([]domain.Transaction, error) {
+func (r *transactionRepository) List(filter domain.TransactionFilter, offset, limit int) ([]domain.Transaction, error) {
 	var transactions []domain.Transaction
-	err := r.db.Preload("Installments").
-		Where("customer_id = ?", customerID).
+	err := applyTransactionFilter(r.db.Preload("Installments"), filter).
 		Offset(offset).Limit(limit).
 		Find(&transactions).Error
 	if err != nil {
@@ -146,6 +178,26 @@ func (r *transactionRepository) List(customerID uint, offset, limit int) ([]doma
 	return transactions, nil
 }
 
+// applyTransactionFilter narrows a query of transactions to filter
+func applyTransactionFilter(query *gorm.DB, filter domain.TransactionFilter) *gorm.DB {
+	if filter.CustomerID != 0 {
+		query = query.Where("customer_id = ?", filter.CustomerID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Source != "" {
+		query = query.Where("source = ?", filter.Source)
+	}
+	if filter.CreatedFrom != nil {
+		query = query.Where("created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		query = query.Where("created_at < ?", *filter.CreatedTo)
+	}
+	return query
+}
+
 // GetInstallments implements TransactionRepository.GetInstallments
 func (r *transactionRepository) GetInstallments(transactionID uint) ([]domain.Installment, error) {
 	var installments []domain.Installment
@@ -158,33 +210,90 @@ func (r *transactionRepository) GetInstallments(transactionID uint) ([]domain.In
 	return installments, nil
 }
 
-// UpdateInstallment implements TransactionRepository.UpdateInstallment
+// GetInstallmentByID implements TransactionRepository.GetInstallmentByID
+func (r *transactionRepository) GetInstallmentByID(id uint) (*domain.Installment, error) {
+	var installment domain.Installment
+	err := r.db.First(&installment, id).Error
+	if err != nil {
+		return nil, translateNotFound(err, "installment_not_found", "installment not found")
+	}
+	return &installment, nil
+}
+
+// UpdateInstallment implements TransactionRepository.UpdateInstallment.
+// The write is rejected when the row was already written under a newer
+// distributed lock fencing token.
 func (r *transactionRepository) UpdateInstallment(installment *domain.Installment) error {
 	return r.db.Transaction(func(tx *gorm.DB) error {
-		// Increment version
-		installment.Version++
-
-		// Update installment using raw SQL
-		result := tx.Exec(`UPDATE "installments" SET "transaction_id"=?,"installment_number"=?,"amount"=?,"status"=?,"due_date"=?,"updated_at"=?,"version"=? WHERE "id"=? AND "version"=?`,
-			installment.TransactionID,
-			installment.InstallmentNumber,
-			installment.Amount,
-			installment.Status,
-			installment.DueDate,
-			time.Now(),
-			installment.Version,
-			installment.ID,
-			installment.Version-1,
-		)
-
-		if result.Error != nil {
-			return result.Error
+		if err := updateInstallment(tx, installment); err != nil {
+			return err
 		}
+		return writeOutbox(tx, installment.Events)
+	})
+}
 
-		if result.RowsAffected == 0 {
-			return errors.New("optimistic lock error")
-		}
+// updateInstallment writes installment within tx, incrementing its version
+func updateInstallment(tx *gorm.DB, installment *domain.Installment) error {
+	// Increment version
+	installment.Version++
 
-		return nil
-	})
+	// Update installment using raw SQL
+	result := tx.Exec(`UPDATE "installments" SET "transaction_id"=?,"installment_number"=?,"amount"=?,"late_fee"=?,"status"=?,"due_date"=?,"paid_at"=?,"updated_at"=?,"version"=?,"fencing_token"=? WHERE "id"=? AND "version"=? AND "fencing_token"<=?`,
+		installment.TransactionID,
+		installment.InstallmentNumber,
+		installment.Amount,
+		installment.LateFee,
+		installment.Status,
+		installment.DueDate,
+		installment.PaidAt,
+		time.Now(),
+		installment.Version,
+		installment.FencingToken,
+		installment.ID,
+		installment.Version-1,
+		installment.FencingToken,
+	)
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return errConcurrentModification()
+	}
+	return nil
+}
+
+// ListOverdueInstallments implements TransactionRepository.ListOverdueInstallments.
+// Only installments of approved contracts are collected; pending, rejected or
+// cancelled contracts keep their unpaid schedule but owe nothing.
+func (r *transactionRepository) ListOverdueInstallments(asOf time.Time, limit int) ([]domain.Installment, error) {
+	var installments []domain.Installment
+	err := r.db.Preload("Transaction").
+		Joins(`JOIN "transactions" t ON t."id" = "installments"."transaction_id" AND t."deleted_at" IS NULL`).
+		Where(`"installments"."status" = ? AND "installments"."due_date" < ? AND t."status" = ?`, "unpaid", asOf, domain.StatusApproved).
+		Order(`"installments"."due_date" asc, "installments"."id" asc`).
+		Limit(limit).
+		Find(&installments).Error
+	if err != nil {
+		return nil, err
+	}
+	return installments, nil
+}
+
+// ListInstallmentsDueBetween implements TransactionRepository.ListInstallmentsDueBetween.
+// Like overdue installments, only those of approved contracts are due.
+func (r *transactionRepository) ListInstallmentsDueBetween(from, to time.Time, afterID uint, limit int) ([]domain.Installment, error) {
+	var installments []domain.Installment
+	err := r.db.Preload("Transaction").
+		Joins(`JOIN "transactions" t ON t."id" = "installments"."transaction_id" AND t."deleted_at" IS NULL`).
+		Where(`"installments"."status" = ? AND "installments"."due_date" >= ? AND "installments"."due_date" < ? AND "installments"."id" > ? AND t."status" = ?`,
+			"unpaid", from, to, afterID, domain.StatusApproved).
+		Order(`"installments"."id" asc`).
+		Limit(limit).
+		Find(&installments).Error
+	if err != nil {
+		return nil, err
+	}
+	return installments, nil
 }
@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"time"
+	"xyz-multifinance/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type underwritingRepository struct {
+	db *gorm.DB
+}
+
+// NewUnderwritingRepository creates a new instance of UnderwritingRepository
+func NewUnderwritingRepository(db *gorm.DB) domain.UnderwritingRepository {
+	return &underwritingRepository{
+		db: db,
+	}
+}
+
+// Create implements UnderwritingRepository.Create. A contract is
+// underwritten at most once, so a redelivered transaction.created event
+// leaves the first decision in place.
+func (r *underwritingRepository) Create(decision *domain.UnderwritingDecision, transaction *domain.Transaction) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Omit("Transaction").Clauses(clause.OnConflict{DoNothing: true}).Create(decision)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrEventProcessed
+		}
+
+		if decision.Status == domain.UnderwritingQueued {
+			return nil
+		}
+		return decidePending(tx, transaction)
+	})
+}
+
+// GetByID implements UnderwritingRepository.GetByID
+func (r *underwritingRepository) GetByID(id uint) (*domain.UnderwritingDecision, error) {
+	var decision domain.UnderwritingDecision
+	if err := r.db.First(&decision, id).Error; err != nil {
+		return nil, translateNotFound(err, "underwriting_not_found", "underwriting decision not found")
+	}
+	return &decision, nil
+}
+
+// GetByTransaction implements UnderwritingRepository.GetByTransaction
+func (r *underwritingRepository) GetByTransaction(transactionID uint) (*domain.UnderwritingDecision, error) {
+	var decision domain.UnderwritingDecision
+	if err := r.db.Where("transaction_id = ?", transactionID).First(&decision).Error; err != nil {
+		return nil, translateNotFound(err, "underwriting_not_found", "contract has not been underwritten")
+	}
+	return &decision, nil
+}
+
+// ListQueue implements UnderwritingRepository.ListQueue
+func (r *underwritingRepository) ListQueue(offset, limit int) ([]domain.UnderwritingDecision, error) {
+	var decisions []domain.UnderwritingDecision
+	err := r.db.Preload("Transaction").
+		Where("status = ?", domain.UnderwritingQueued).
+		Order("due_at asc, id asc").
+		Offset(offset).Limit(limit).
+		Find(&decisions).Error
+	if err != nil {
+		return nil, err
+	}
+	return decisions, nil
+}
+
+// Claim implements UnderwritingRepository.Claim. An application claimed by
+// another operator meanwhile is not taken over.
+func (r *underwritingRepository) Claim(decision *domain.UnderwritingDecision, audit *domain.AuditLog) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&domain.UnderwritingDecision{}).
+			Where("id = ? AND status = ? AND (assigned_to IS NULL OR assigned_to = ?)", decision.ID, domain.UnderwritingQueued, decision.AssignedTo).
+			Updates(map[string]interface{}{
+				"assigned_to": decision.AssignedTo,
+				"updated_at":  decision.UpdatedAt,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errConcurrentModification()
+		}
+		return writeAudit(tx, audit)
+	})
+}
+
+// Decide implements UnderwritingRepository.Decide
+func (r *underwritingRepository) Decide(decision *domain.UnderwritingDecision, transaction *domain.Transaction, audit *domain.AuditLog) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&domain.UnderwritingDecision{}).
+			Where("id = ? AND status = ?", decision.ID, domain.UnderwritingQueued).
+			Updates(map[string]interface{}{
+				"status":      decision.Status,
+				"assigned_to": decision.AssignedTo,
+				"reviewed_by": decision.ReviewedBy,
+				"review_note": decision.ReviewNote,
+				"reviewed_at": decision.ReviewedAt,
+				"updated_at":  decision.UpdatedAt,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errDecisionNotQueued()
+		}
+
+		if err := decidePending(tx, transaction); err != nil {
+			return err
+		}
+		return writeAudit(tx, audit)
+	})
+}
+
+// ListStale implements UnderwritingRepository.ListStale, oldest first
+func (r *underwritingRepository) ListStale(createdBefore time.Time, limit int) ([]domain.Transaction, error) {
+	var transactions []domain.Transaction
+	err := r.db.Where("status = ? AND created_at < ?", domain.StatusPending, createdBefore).
+		Order("id asc").Limit(limit).
+		Find(&transactions).Error
+	if err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// Expire implements UnderwritingRepository.Expire
+func (r *underwritingRepository) Expire(transaction *domain.Transaction) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := decidePending(tx, transaction); err != nil {
+			return err
+		}
+		return tx.Model(&domain.UnderwritingDecision{}).
+			Where("transaction_id = ? AND status = ?", transaction.ID, domain.UnderwritingQueued).
+			Updates(map[string]interface{}{
+				"status":     domain.UnderwritingExpired,
+				"updated_at": transaction.UpdatedAt,
+			}).Error
+	})
+}
+
+// GetExposure implements UnderwritingRepository.GetExposure. Open contracts
+// are pending ones and approved ones with installments outstanding.
+func (r *underwritingRepository) GetExposure(customerID uint, tenor int, excludeTransactionID uint) (*domain.CreditExposure, error) {
+	var exposure domain.CreditExposure
+
+	err := r.db.Raw(`SELECT
+			COALESCE(SUM(CASE WHEN t."tenor" = ? THEN t."otr_amount" + t."admin_fee" + t."restructured_amount" END), 0) AS "tenor_amount",
+			COALESCE(SUM(t."installment_amount"), 0) AS "monthly_installments"
+		FROM "transactions" t
+		WHERE t."customer_id" = ? AND t."id" <> ? AND t."deleted_at" IS NULL
+			AND (t."status" = ? OR (t."status" = ? AND EXISTS (
+				SELECT 1 FROM "installments" i WHERE i."transaction_id" = t."id" AND i."status" IN ('unpaid', 'overdue'))))`,
+		tenor, customerID, excludeTransactionID, domain.StatusPending, domain.StatusApproved,
+	).Scan(&exposure).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var overdue struct {
+		Count  int
+		Oldest *time.Time
+	}
+	err = r.db.Raw(`SELECT COUNT(*) AS "count", MIN(i."due_date") AS "oldest"
+		FROM "installments" i JOIN "transactions" t ON t."id" = i."transaction_id"
+		WHERE t."customer_id" = ? AND t."deleted_at" IS NULL AND i."status" = 'overdue'`,
+		customerID,
+	).Scan(&overdue).Error
+	if err != nil {
+		return nil, err
+	}
+	exposure.OverdueInstallments = overdue.Count
+	exposure.OldestOverdueDate = overdue.Oldest
+	return &exposure, nil
+}
+
+// decidePending moves a pending transaction to its new status within tx,
+// checked against the version it was read at, and writes its events
+func decidePending(tx *gorm.DB, transaction *domain.Transaction) error {
+	result := tx.Exec(`UPDATE "transactions" SET "status"=?,"version"="version"+1,"updated_at"=? WHERE "id"=? AND "version"=? AND "status"=? AND "deleted_at" IS NULL`,
+		transaction.Status, transaction.UpdatedAt, transaction.ID, transaction.Version, domain.StatusPending,
+	)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errConcurrentModification()
+	}
+	transaction.Version++
+	return writeOutbox(tx, transaction.Events)
+}
+
+func errDecisionNotQueued() error {
+	return domain.NewError(domain.ErrConflict, "underwriting_not_queued", "application is no longer awaiting review")
+}
@@ -0,0 +1,235 @@
+package repository
+
+import (
+	"time"
+	"xyz-multifinance/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// deliveryInsertBatchSize bounds the rows inserted per statement during fan-out
+const deliveryInsertBatchSize = 100
+
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+// NewWebhookRepository creates a new instance of WebhookRepository
+func NewWebhookRepository(db *gorm.DB) domain.WebhookRepository {
+	return &webhookRepository{
+		db: db,
+	}
+}
+
+// CreateSubscription implements WebhookRepository.CreateSubscription
+func (r *webhookRepository) CreateSubscription(subscription *domain.WebhookSubscription) error {
+	return r.db.Create(subscription).Error
+}
+
+// GetSubscription implements WebhookRepository.GetSubscription
+func (r *webhookRepository) GetSubscription(id uint) (*domain.WebhookSubscription, error) {
+	var subscription domain.WebhookSubscription
+	err := r.db.First(&subscription, id).Error
+	if err != nil {
+		return nil, translateNotFound(err, "subscription_not_found", "webhook subscription not found")
+	}
+	return &subscription, nil
+}
+
+// ListSubscriptions implements WebhookRepository.ListSubscriptions. A zero
+// partnerID lists the subscriptions of all partners.
+func (r *webhookRepository) ListSubscriptions(partnerID uint) ([]domain.WebhookSubscription, error) {
+	var subscriptions []domain.WebhookSubscription
+	query := r.db.Order("id asc")
+	if partnerID != 0 {
+		query = query.Where("partner_id = ?", partnerID)
+	}
+	if err := query.Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// UpdateSubscription implements WebhookRepository.UpdateSubscription
+func (r *webhookRepository) UpdateSubscription(subscription *domain.WebhookSubscription) error {
+	result := r.db.Model(subscription).
+		Select("URL", "EventTypes", "Secret", "Status").
+		Updates(subscription)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.NewError(domain.ErrNotFound, "subscription_not_found", "webhook subscription not found")
+	}
+	return nil
+}
+
+// DeleteSubscription implements WebhookRepository.DeleteSubscription.
+// Deliveries of the subscription are removed with it.
+func (r *webhookRepository) DeleteSubscription(id uint) error {
+	result := r.db.Delete(&domain.WebhookSubscription{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.NewError(domain.ErrNotFound, "subscription_not_found", "webhook subscription not found")
+	}
+	return nil
+}
+
+// FanOut implements WebhookRepository.FanOut. Undispatched outbox events
+// are locked, turned into one pending delivery per matching subscription and
+// marked dispatched in a single transaction, so concurrent dispatchers never
+// fan out the same event twice.
+func (r *webhookRepository) FanOut(now time.Time, limit int) (int, error) {
+	var count int
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var events []domain.OutboxEvent
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("dispatched_at IS NULL").
+			Order("id asc").
+			Limit(limit).
+			Find(&events).Error
+		if err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+
+		eventIDs := make([]uint, len(events))
+		var partnerIDs []uint
+		for i, event := range events {
+			eventIDs[i] = event.ID
+			if event.PartnerID != nil {
+				partnerIDs = append(partnerIDs, *event.PartnerID)
+			}
+		}
+
+		var subscriptions []domain.WebhookSubscription
+		if len(partnerIDs) > 0 {
+			err := tx.Where("partner_id IN ? AND status = ?", partnerIDs, domain.SubscriptionActive).
+				Find(&subscriptions).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		var deliveries []domain.WebhookDelivery
+		for _, event := range events {
+			if event.PartnerID == nil {
+				continue
+			}
+			for i := range subscriptions {
+				if subscriptions[i].PartnerID == *event.PartnerID && subscriptions[i].Accepts(event.EventType) {
+					deliveries = append(deliveries, domain.WebhookDelivery{
+						SubscriptionID: subscriptions[i].ID,
+						EventID:        event.ID,
+						Status:         domain.DeliveryPending,
+						NextAttemptAt:  now,
+					})
+				}
+			}
+		}
+
+		if len(deliveries) > 0 {
+			if err := tx.CreateInBatches(&deliveries, deliveryInsertBatchSize).Error; err != nil {
+				return err
+			}
+		}
+
+		err = tx.Model(&domain.OutboxEvent{}).
+			Where("id IN ?", eventIDs).
+			Update("dispatched_at", now).Error
+		if err != nil {
+			return err
+		}
+
+		count = len(events)
+		return nil
+	})
+	return count, err
+}
+
+// ClaimDueDeliveries implements WebhookRepository.ClaimDueDeliveries. Claimed
+// deliveries are pushed back by lease so other dispatchers skip them while
+// they are being sent; a crashed dispatcher's claims expire with the lease.
+func (r *webhookRepository) ClaimDueDeliveries(now time.Time, lease time.Duration, limit int) ([]domain.WebhookDelivery, error) {
+	var ids []uint
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var due []domain.WebhookDelivery
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Select("id").
+			Where("status = ? AND next_attempt_at <= ?", domain.DeliveryPending, now).
+			Order("next_attempt_at asc, id asc").
+			Limit(limit).
+			Find(&due).Error
+		if err != nil {
+			return err
+		}
+		if len(due) == 0 {
+			return nil
+		}
+
+		ids = make([]uint, len(due))
+		for i, delivery := range due {
+			ids[i] = delivery.ID
+		}
+
+		return tx.Model(&domain.WebhookDelivery{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(lease)).Error
+	})
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	var deliveries []domain.WebhookDelivery
+	err = r.db.Preload("Subscription").Preload("Event").
+		Order("id asc").
+		Find(&deliveries, ids).Error
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// GetDelivery implements WebhookRepository.GetDelivery
+func (r *webhookRepository) GetDelivery(id uint) (*domain.WebhookDelivery, error) {
+	var delivery domain.WebhookDelivery
+	err := r.db.Preload("Event").First(&delivery, id).Error
+	if err != nil {
+		return nil, translateNotFound(err, "delivery_not_found", "webhook delivery not found")
+	}
+	return &delivery, nil
+}
+
+// ListDeliveries implements WebhookRepository.ListDeliveries. An empty
+// status lists deliveries in every status.
+func (r *webhookRepository) ListDeliveries(subscriptionID uint, status domain.DeliveryStatus, offset, limit int) ([]domain.WebhookDelivery, error) {
+	var deliveries []domain.WebhookDelivery
+	query := r.db.Preload("Event").Where("subscription_id = ?", subscriptionID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Order("id desc").Offset(offset).Limit(limit).Find(&deliveries).Error
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// UpdateDelivery implements WebhookRepository.UpdateDelivery
+func (r *webhookRepository) UpdateDelivery(delivery *domain.WebhookDelivery) error {
+	result := r.db.Model(delivery).
+		Select("Status", "Attempts", "NextAttemptAt", "LastStatusCode", "LastError", "DeliveredAt").
+		Updates(delivery)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.NewError(domain.ErrNotFound, "delivery_not_found", "webhook delivery not found")
+	}
+	return nil
+}
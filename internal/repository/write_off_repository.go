@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"time"
+	"xyz-multifinance/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type writeOffRepository struct {
+	db *gorm.DB
+}
+
+// NewWriteOffRepository creates a new instance of WriteOffRepository
+func NewWriteOffRepository(db *gorm.DB) domain.WriteOffRepository {
+	return &writeOffRepository{
+		db: db,
+	}
+}
+
+// Create implements WriteOffRepository.Create. Written off installments are
+// checked against the version they were read at, and the contract must still
+// be approved, so a payment made meanwhile fails the write-off as a whole.
+func (r *writeOffRepository) Create(writeOff *domain.WriteOff, audit *domain.AuditLog) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Omit(clause.Associations).Clauses(clause.OnConflict{DoNothing: true}).Create(writeOff)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.NewError(domain.ErrConflict, "contract_written_off", "contract is written off already")
+		}
+
+		now := writeOff.WrittenOffAt
+		for _, installment := range writeOff.WrittenOff {
+			result := tx.Exec(`UPDATE "installments" SET "status"=?,"version"="version"+1,"updated_at"=? WHERE "id"=? AND "version"=? AND "status" IN (?,?)`,
+				domain.InstallmentWrittenOff, now, installment.ID, installment.Version, "unpaid", "overdue",
+			)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return errConcurrentModification()
+			}
+		}
+
+		result = tx.Exec(`UPDATE "transactions" SET "status"=?,"version"="version"+1,"updated_at"=? WHERE "id"=? AND "status"=?`,
+			domain.StatusWrittenOff, now, writeOff.TransactionID, domain.StatusApproved,
+		)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errConcurrentModification()
+		}
+
+		audit.EntityID = writeOff.ID
+		if err := writeAudit(tx, audit); err != nil {
+			return err
+		}
+		return writeOutbox(tx, writeOff.Events)
+	})
+}
+
+// GetByID implements WriteOffRepository.GetByID
+func (r *writeOffRepository) GetByID(id uint) (*domain.WriteOff, error) {
+	var writeOff domain.WriteOff
+	err := r.db.Preload("Recoveries", func(db *gorm.DB) *gorm.DB { return db.Order("received_at, id") }).
+		First(&writeOff, id).Error
+	if err != nil {
+		return nil, translateNotFound(err, "write_off_not_found", "write-off not found")
+	}
+	return &writeOff, nil
+}
+
+// List implements WriteOffRepository.List, newest first
+func (r *writeOffRepository) List(offset, limit int) ([]domain.WriteOff, error) {
+	var writeOffs []domain.WriteOff
+	if err := r.db.Order("id desc").Offset(offset).Limit(limit).Find(&writeOffs).Error; err != nil {
+		return nil, err
+	}
+	return writeOffs, nil
+}
+
+// AddRecovery implements WriteOffRepository.AddRecovery. The write-off is
+// checked against the version it was read at.
+func (r *writeOffRepository) AddRecovery(writeOff *domain.WriteOff, recovery *domain.Recovery, audit *domain.AuditLog) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&domain.WriteOff{}).
+			Where("id = ? AND version = ?", writeOff.ID, writeOff.Version).
+			Updates(map[string]interface{}{
+				"recovered_amount": gorm.Expr("recovered_amount + ?", recovery.Amount),
+				"version":          gorm.Expr("version + 1"),
+				"updated_at":       now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errConcurrentModification()
+		}
+
+		recovery.WriteOffID = writeOff.ID
+		if err := tx.Create(recovery).Error; err != nil {
+			return err
+		}
+
+		writeOff.RecoveredAmount += recovery.Amount
+		writeOff.Version++
+		writeOff.UpdatedAt = now
+		audit.EntityID = writeOff.ID
+		if err := writeAudit(tx, audit); err != nil {
+			return err
+		}
+		return writeOutbox(tx, writeOff.Events)
+	})
+}
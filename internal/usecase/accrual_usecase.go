@@ -0,0 +1,172 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+	"xyz-multifinance/internal/domain"
+)
+
+// accrualBatchSize bounds the contracts accrued per query
+const accrualBatchSize = 500
+
+type accrualUseCase struct {
+	accrualRepo     domain.AccrualRepository
+	transactionRepo domain.TransactionRepository
+	policy          domain.ProvisioningPolicy
+}
+
+// NewAccrualUseCase creates a new instance of AccrualUseCase. Contracts
+// policy classifies non-performing stop accruing interest.
+func NewAccrualUseCase(
+	accrualRepo domain.AccrualRepository,
+	transactionRepo domain.TransactionRepository,
+	policy domain.ProvisioningPolicy,
+) domain.AccrualUseCase {
+	return &accrualUseCase{
+		accrualRepo:     accrualRepo,
+		transactionRepo: transactionRepo,
+		policy:          policy,
+	}
+}
+
+// AccrueDaily implements AccrualUseCase.AccrueDaily. Interest not accrued
+// while a contract was non-performing is caught up once it performs again.
+// Contracts accrued today already, or paid meanwhile, are skipped until the
+// next run.
+func (uc *accrualUseCase) AccrueDaily(now time.Time) (int, error) {
+	asOf := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	accrued := 0
+	var after uint
+	for {
+		ids, err := uc.accrualRepo.ListAccruing(after, accrualBatchSize)
+		if err != nil {
+			return accrued, err
+		}
+
+		for _, id := range ids {
+			after = id
+			tx, err := uc.transactionRepo.GetByID(id)
+			if err != nil {
+				return accrued, err
+			}
+			if classify(uc.policy, contractExposure(tx, now), now).Collectibility.NonPerforming() {
+				continue
+			}
+
+			accrual := contractAccrual(tx, asOf)
+			if accrual == nil {
+				continue
+			}
+			if err := uc.accrualRepo.Accrue(accrual); err != nil {
+				if errors.Is(err, domain.ErrConflict) {
+					continue
+				}
+				return accrued, err
+			}
+			accrued++
+		}
+
+		if len(ids) < accrualBatchSize {
+			return accrued, nil
+		}
+	}
+}
+
+// Contract implements AccrualUseCase.Contract. Superseded installments count
+// the interest they accrued before they were replaced.
+func (uc *accrualUseCase) Contract(transactionID uint) (*domain.ContractInterest, error) {
+	tx, err := uc.transactionRepo.GetByID(transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	classification := classify(uc.policy, contractExposure(tx, now), now)
+	contract := &domain.ContractInterest{
+		TransactionID:  tx.ID,
+		ContractNumber: tx.ContractNumber,
+		Collectibility: classification.Collectibility,
+		Accruing:       tx.Status == domain.StatusApproved && !classification.Collectibility.NonPerforming(),
+		Installments:   tx.Installments,
+	}
+	sort.Slice(contract.Installments, func(i, j int) bool {
+		return contract.Installments[i].InstallmentNumber < contract.Installments[j].InstallmentNumber
+	})
+	for _, installment := range tx.Installments {
+		contract.Accrued += installment.AccruedInterest
+		if installment.Status == "superseded" {
+			contract.Interest += installment.AccruedInterest
+			continue
+		}
+		contract.Principal += installment.Principal
+		contract.Interest += installment.Interest
+		if installment.Status == "paid" {
+			contract.Received += installment.Interest
+		}
+	}
+	contract.Principal = roundCents(contract.Principal)
+	contract.Interest = roundCents(contract.Interest)
+	contract.Accrued = roundCents(contract.Accrued)
+	contract.Received = roundCents(contract.Received)
+	return contract, nil
+}
+
+// ListAccruals implements AccrualUseCase.ListAccruals
+func (uc *accrualUseCase) ListAccruals(transactionID uint, offset, limit int) ([]domain.InterestAccrual, error) {
+	if _, err := uc.transactionRepo.GetByID(transactionID); err != nil {
+		return nil, err
+	}
+	return uc.accrualRepo.ListAccruals(transactionID, offset, limit)
+}
+
+// Portfolio implements AccrualUseCase.Portfolio
+func (uc *accrualUseCase) Portfolio() (*domain.PortfolioInterest, error) {
+	portfolio, err := uc.accrualRepo.Portfolio()
+	if err != nil {
+		return nil, err
+	}
+	portfolio.Interest = roundCents(portfolio.Interest)
+	portfolio.Accrued = roundCents(portfolio.Accrued)
+	portfolio.Received = roundCents(portfolio.Received)
+	portfolio.Unaccrued = roundCents(portfolio.Interest - portfolio.Accrued)
+	return portfolio, nil
+}
+
+// contractAccrual returns the interest the installments of tx accrued up to
+// asOf since the last accrual, with its journal entry, or nil when there is
+// none. The entry is posted on the day before asOf, the last day accrued.
+func contractAccrual(tx *domain.Transaction, asOf time.Time) *domain.InterestAccrual {
+	accrual := &domain.InterestAccrual{
+		TransactionID: tx.ID,
+		AccrualDate:   asOf,
+		CreatedAt:     time.Now(),
+	}
+	for _, installment := range tx.Installments {
+		if installment.Status != "paid" && !installment.IsOutstanding() {
+			continue
+		}
+		target := installment.AccruedInterestAt(asOf)
+		if target-installment.AccruedInterest < 0.005 {
+			continue
+		}
+		accrual.Accrued = append(accrual.Accrued, domain.InstallmentAccrual{
+			InstallmentID: installment.ID,
+			Previous:      installment.AccruedInterest,
+			Accrued:       target,
+		})
+		accrual.Amount += target - installment.AccruedInterest
+	}
+	accrual.Amount = roundCents(accrual.Amount)
+	if accrual.Amount <= 0 {
+		return nil
+	}
+
+	entry := newJournalEntry(domain.JournalInterestAccrual, tx.ID, "", fmt.Sprintf("Interest accrued on contract %s", tx.ContractNumber), asOf.AddDate(0, 0, -1))
+	entry.Post(domain.AccountUnearnedInterest, accrual.Amount)
+	entry.Post(domain.AccountInterestIncome, -accrual.Amount)
+	accrual.Entry = &entry
+	return accrual
+}
@@ -0,0 +1,274 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+	"xyz-multifinance/internal/domain"
+	"xyz-multifinance/internal/pkg/redis"
+)
+
+// analyticsMaxMonths bounds the months a range of analytics spans
+const analyticsMaxMonths = 36
+
+type analyticsUseCase struct {
+	analyticsRepo domain.AnalyticsRepository
+	redisClient   redis.RedisClient
+	config        domain.AnalyticsConfig
+}
+
+// NewAnalyticsUseCase creates a new instance of AnalyticsUseCase. Results
+// are cached in Redis; a cache that cannot be read or written is bypassed.
+func NewAnalyticsUseCase(
+	analyticsRepo domain.AnalyticsRepository,
+	redisClient redis.RedisClient,
+	config domain.AnalyticsConfig,
+) domain.AnalyticsUseCase {
+	return &analyticsUseCase{
+		analyticsRepo: analyticsRepo,
+		redisClient:   redisClient,
+		config:        config,
+	}
+}
+
+// Outstanding implements AnalyticsUseCase.Outstanding
+func (uc *analyticsUseCase) Outstanding(ctx context.Context) (*domain.OutstandingSummary, error) {
+	var summary *domain.OutstandingSummary
+	err := uc.cached(ctx, "analytics:outstanding", &summary, func() error {
+		var err error
+		summary, err = uc.analyticsRepo.Outstanding(time.Now())
+		if err != nil {
+			return err
+		}
+		summary.Principal = roundCents(summary.Principal)
+		summary.Interest = roundCents(summary.Interest)
+		summary.Overdue = roundCents(summary.Overdue)
+		for _, groups := range [][]domain.OutstandingGroup{summary.BySource, summary.ByTenor} {
+			for i := range groups {
+				groups[i].Principal = roundCents(groups[i].Principal)
+				groups[i].Interest = roundCents(groups[i].Interest)
+				groups[i].Overdue = roundCents(groups[i].Overdue)
+			}
+		}
+		return nil
+	})
+	return summary, err
+}
+
+// Disbursements implements AnalyticsUseCase.Disbursements
+func (uc *analyticsUseCase) Disbursements(ctx context.Context, fromMonth, toMonth string) ([]domain.Disbursement, error) {
+	from, to, err := monthRange(fromMonth, toMonth)
+	if err != nil {
+		return nil, err
+	}
+
+	var disbursements []domain.Disbursement
+	key := fmt.Sprintf("analytics:disbursements:%s:%s", fromMonth, toMonth)
+	err = uc.cached(ctx, key, &disbursements, func() error {
+		var err error
+		disbursements, err = uc.analyticsRepo.Disbursements(from, to)
+		if err != nil {
+			return err
+		}
+		for i := range disbursements {
+			disbursements[i].Amount = roundCents(disbursements[i].Amount)
+		}
+		return nil
+	})
+	return disbursements, err
+}
+
+// Utilisation implements AnalyticsUseCase.Utilisation
+func (uc *analyticsUseCase) Utilisation(ctx context.Context) (*domain.Utilisation, error) {
+	var utilisation *domain.Utilisation
+	err := uc.cached(ctx, "analytics:utilisation", &utilisation, func() error {
+		var err error
+		utilisation, err = uc.analyticsRepo.Utilisation()
+		if err != nil {
+			return err
+		}
+		for _, groups := range [][]domain.UtilisationGroup{utilisation.Bands, utilisation.ByTenor} {
+			for i := range groups {
+				groups[i].Amount = roundCents(groups[i].Amount)
+				groups[i].Used = roundCents(groups[i].Used)
+				groups[i].Rate = ratio(groups[i].Used, groups[i].Amount)
+			}
+		}
+		utilisation.Limits, utilisation.Amount, utilisation.Used = 0, 0, 0
+		for _, band := range utilisation.Bands {
+			utilisation.Limits += band.Limits
+			utilisation.Amount += band.Amount
+			utilisation.Used += band.Used
+		}
+		utilisation.Amount = roundCents(utilisation.Amount)
+		utilisation.Used = roundCents(utilisation.Used)
+		utilisation.Rate = ratio(utilisation.Used, utilisation.Amount)
+		return nil
+	})
+	return utilisation, err
+}
+
+// CollectionRates implements AnalyticsUseCase.CollectionRates
+func (uc *analyticsUseCase) CollectionRates(ctx context.Context, fromMonth, toMonth string) ([]domain.CollectionRate, error) {
+	from, to, err := monthRange(fromMonth, toMonth)
+	if err != nil {
+		return nil, err
+	}
+
+	var rates []domain.CollectionRate
+	key := fmt.Sprintf("analytics:collection_rates:%s:%s", fromMonth, toMonth)
+	err = uc.cached(ctx, key, &rates, func() error {
+		var err error
+		rates, err = uc.analyticsRepo.CollectionRates(from, to)
+		if err != nil {
+			return err
+		}
+		for i := range rates {
+			rate := &rates[i]
+			rate.Due = roundCents(rate.Due)
+			rate.Collected = roundCents(rate.Collected)
+			rate.OnTime = roundCents(rate.OnTime)
+			rate.Rate = ratio(rate.Collected, rate.Due)
+			rate.OnTimeRate = ratio(rate.OnTime, rate.Due)
+		}
+		return nil
+	})
+	return rates, err
+}
+
+// Vintage implements AnalyticsUseCase.Vintage. The configured threshold is
+// used when dpd is zero.
+func (uc *analyticsUseCase) Vintage(ctx context.Context, fromMonth, toMonth string, dpd int) (*domain.Vintage, error) {
+	from, to, err := monthRange(fromMonth, toMonth)
+	if err != nil {
+		return nil, err
+	}
+	if dpd == 0 {
+		dpd = uc.config.VintageDPD
+	}
+	if dpd < 0 {
+		return nil, domain.NewError(domain.ErrValidation, "invalid_dpd", "dpd must not be negative")
+	}
+
+	var vintage *domain.Vintage
+	key := fmt.Sprintf("analytics:vintage:%s:%s:%d", fromMonth, toMonth, dpd)
+	err = uc.cached(ctx, key, &vintage, func() error {
+		points, err := uc.analyticsRepo.Vintage(from, to, dpd, uc.config.MaxMOB, time.Now())
+		if err != nil {
+			return err
+		}
+
+		vintage = &domain.Vintage{DPD: dpd, Cohorts: []domain.VintageCohort{}}
+		for _, point := range points {
+			if n := len(vintage.Cohorts); n == 0 || vintage.Cohorts[n-1].Cohort != point.Cohort {
+				vintage.Cohorts = append(vintage.Cohorts, domain.VintageCohort{
+					Cohort:    point.Cohort,
+					Contracts: point.Contracts,
+					Amount:    roundCents(point.Amount),
+				})
+			}
+			cohort := &vintage.Cohorts[len(vintage.Cohorts)-1]
+			cohort.Cells = append(cohort.Cells, domain.VintageCell{
+				MOB:        point.MOB,
+				Delinquent: roundCents(point.Delinquent),
+				Rate:       ratio(point.Delinquent, point.Amount),
+			})
+		}
+		return nil
+	})
+	return vintage, err
+}
+
+// RollRate implements AnalyticsUseCase.RollRate. Rows list the states
+// contracts were in at the start of the month, every state is listed.
+func (uc *analyticsUseCase) RollRate(ctx context.Context, month string) (*domain.RollRate, error) {
+	from, to, err := monthRange(month, month)
+	if err != nil {
+		return nil, err
+	}
+	if to.After(time.Now()) {
+		return nil, domain.NewError(domain.ErrValidation, "period_not_ended", "roll rates are only given for ended months")
+	}
+
+	var rollRate *domain.RollRate
+	err = uc.cached(ctx, "analytics:roll_rate:"+month, &rollRate, func() error {
+		transitions, err := uc.analyticsRepo.RollRates(from, to)
+		if err != nil {
+			return err
+		}
+
+		rollRate = &domain.RollRate{Month: month}
+		for _, state := range domain.RollRateBuckets {
+			if state == domain.BucketClosed {
+				continue
+			}
+			row := domain.RollRateRow{From: state}
+			for _, next := range domain.RollRateBuckets {
+				cell := domain.RollRateCell{To: next}
+				for _, transition := range transitions {
+					if transition.From == state && transition.To == next {
+						cell.Contracts = transition.Contracts
+					}
+				}
+				row.Contracts += cell.Contracts
+				row.To = append(row.To, cell)
+			}
+			for i := range row.To {
+				row.To[i].Rate = ratio(float64(row.To[i].Contracts), float64(row.Contracts))
+			}
+			rollRate.Rows = append(rollRate.Rows, row)
+		}
+		return nil
+	})
+	return rollRate, err
+}
+
+// cached reads the result cached under key into result, or computes it and
+// caches it for the configured TTL
+func (uc *analyticsUseCase) cached(ctx context.Context, key string, result interface{}, compute func() error) error {
+	if uc.config.CacheTTL <= 0 {
+		return compute()
+	}
+
+	if data, err := uc.redisClient.Get(ctx, key).Bytes(); err == nil && json.Unmarshal(data, result) == nil {
+		return nil
+	}
+	if err := compute(); err != nil {
+		return err
+	}
+	if data, err := json.Marshal(result); err == nil {
+		uc.redisClient.Set(ctx, key, data, uc.config.CacheTTL)
+	}
+	return nil
+}
+
+// monthRange returns the bounds [from, to) of the months fromMonth to
+// toMonth
+func monthRange(fromMonth, toMonth string) (time.Time, time.Time, error) {
+	from, err := time.ParseInLocation(reportPeriodLayout, fromMonth, time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, domain.NewError(domain.ErrValidation, "invalid_month", "months must be formatted YYYY-MM")
+	}
+	last, err := time.ParseInLocation(reportPeriodLayout, toMonth, time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, domain.NewError(domain.ErrValidation, "invalid_month", "months must be formatted YYYY-MM")
+	}
+	if last.Before(from) {
+		return time.Time{}, time.Time{}, domain.NewError(domain.ErrValidation, "invalid_month_range", "to must not be before from")
+	}
+	to := last.AddDate(0, 1, 0)
+	if to.After(from.AddDate(0, analyticsMaxMonths, 0)) {
+		return time.Time{}, time.Time{}, domain.NewError(domain.ErrValidation, "invalid_month_range", fmt.Sprintf("ranges span at most %d months", analyticsMaxMonths))
+	}
+	return from, to, nil
+}
+
+// ratio returns part of whole rounded to four decimals, zero of nothing
+func ratio(part, whole float64) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return math.Round(part/whole*10000) / 10000
+}
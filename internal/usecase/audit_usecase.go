@@ -0,0 +1,43 @@
+package usecase
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+	"xyz-multifinance/internal/domain"
+)
+
+type auditUseCase struct {
+	auditRepo domain.AuditRepository
+}
+
+// NewAuditUseCase creates a new instance of AuditUseCase
+func NewAuditUseCase(auditRepo domain.AuditRepository) domain.AuditUseCase {
+	return &auditUseCase{
+		auditRepo: auditRepo,
+	}
+}
+
+// List implements AuditUseCase.List
+func (uc *auditUseCase) List(entityType string, entityID uint, offset, limit int) ([]domain.AuditLog, error) {
+	return uc.auditRepo.List(entityType, entityID, offset, limit)
+}
+
+// newAuditLog builds the audit log entry of an operation by actor. The
+// entity ID may be left zero for the repository to fill in once the entity
+// is created.
+func newAuditLog(actor domain.Actor, action, entityType string, entityID uint, details interface{}, at time.Time) (*domain.AuditLog, error) {
+	encoded, err := json.Marshal(details)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode audit details: %w", err)
+	}
+	return &domain.AuditLog{
+		ActorID:    actor.ID,
+		ActorRole:  actor.Role,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Details:    encoded,
+		CreatedAt:  at,
+	}, nil
+}
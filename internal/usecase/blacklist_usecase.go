@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"time"
+	"xyz-multifinance/internal/domain"
+)
+
+// Audit log actions of blacklist maintenance
+const (
+	auditBlacklistAdded   = "blacklist.added"
+	auditBlacklistRemoved = "blacklist.removed"
+)
+
+type blacklistUseCase struct {
+	blacklistRepo domain.BlacklistRepository
+}
+
+// NewBlacklistUseCase creates a new instance of BlacklistUseCase
+func NewBlacklistUseCase(blacklistRepo domain.BlacklistRepository) domain.BlacklistUseCase {
+	return &blacklistUseCase{
+		blacklistRepo: blacklistRepo,
+	}
+}
+
+// Add implements BlacklistUseCase.Add
+func (uc *blacklistUseCase) Add(entry *domain.BlacklistEntry, actor domain.Actor) error {
+	if !entry.Type.Valid() {
+		return errInvalidBlacklistType()
+	}
+	entry.Value = domain.NormalizeIdentifier(entry.Type, entry.Value)
+	if entry.Value == "" {
+		return domain.NewError(domain.ErrValidation, "invalid_blacklist_value", "blacklisted value must not be empty")
+	}
+
+	now := time.Now()
+	entry.CreatedBy = &actor.ID
+	entry.CreatedAt = now
+
+	audit, err := newAuditLog(actor, auditBlacklistAdded, "blacklist_entry", 0, map[string]interface{}{
+		"type":   entry.Type,
+		"value":  entry.Value,
+		"reason": entry.Reason,
+	}, now)
+	if err != nil {
+		return err
+	}
+	return uc.blacklistRepo.Create(entry, audit)
+}
+
+// Remove implements BlacklistUseCase.Remove
+func (uc *blacklistUseCase) Remove(id uint, actor domain.Actor) error {
+	audit, err := newAuditLog(actor, auditBlacklistRemoved, "blacklist_entry", id, nil, time.Now())
+	if err != nil {
+		return err
+	}
+	return uc.blacklistRepo.Delete(id, audit)
+}
+
+// List implements BlacklistUseCase.List, newest first
+func (uc *blacklistUseCase) List(entryType domain.BlacklistType, offset, limit int) ([]domain.BlacklistEntry, error) {
+	if entryType != "" && !entryType.Valid() {
+		return nil, errInvalidBlacklistType()
+	}
+	return uc.blacklistRepo.List(entryType, offset, limit)
+}
+
+func errInvalidBlacklistType() error {
+	return domain.NewError(domain.ErrValidation, "invalid_blacklist_type", "blacklist type must be nik, phone or device")
+}
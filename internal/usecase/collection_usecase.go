@@ -0,0 +1,352 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"xyz-multifinance/internal/domain"
+)
+
+// collectionBatchSize bounds the accounts tracked or assigned per query
+const collectionBatchSize = 100
+
+// roleCollector is the role of back office users chasing overdue accounts
+const roleCollector = "collector"
+
+type collectionUseCase struct {
+	collectionRepo domain.CollectionRepository
+	policy         domain.CollectionPolicy
+}
+
+// NewCollectionUseCase creates a new instance of CollectionUseCase
+func NewCollectionUseCase(collectionRepo domain.CollectionRepository, policy domain.CollectionPolicy) domain.CollectionUseCase {
+	return &collectionUseCase{
+		collectionRepo: collectionRepo,
+		policy:         policy,
+	}
+}
+
+// CreateCollector implements CollectionUseCase.CreateCollector
+func (uc *collectionUseCase) CreateCollector(collector *domain.Collector) error {
+	now := time.Now()
+	collector.Active = true
+	collector.CreatedAt = now
+	collector.UpdatedAt = now
+	return uc.collectionRepo.CreateCollector(collector)
+}
+
+// UpdateCollector implements CollectionUseCase.UpdateCollector. Accounts
+// assigned already stay with the collector.
+func (uc *collectionUseCase) UpdateCollector(collector *domain.Collector) error {
+	existing, err := uc.collectionRepo.GetCollector(collector.ID)
+	if err != nil {
+		return err
+	}
+
+	existing.Name = collector.Name
+	existing.Level = collector.Level
+	existing.Region = collector.Region
+	existing.MaxAccounts = collector.MaxAccounts
+	existing.Active = collector.Active
+	existing.UpdatedAt = time.Now()
+	if err := uc.collectionRepo.UpdateCollector(existing); err != nil {
+		return err
+	}
+
+	*collector = *existing
+	return nil
+}
+
+// ListCollectors implements CollectionUseCase.ListCollectors
+func (uc *collectionUseCase) ListCollectors(offset, limit int) ([]domain.Collector, error) {
+	return uc.collectionRepo.ListCollectors(offset, limit)
+}
+
+// Sync implements CollectionUseCase.Sync, returning the accounts of
+// contracts with overdue installments tracked. Accounts modified
+// concurrently are left for the next run.
+func (uc *collectionUseCase) Sync(now time.Time) (int, error) {
+	tracked := 0
+	var after uint
+	for {
+		delinquencies, err := uc.collectionRepo.ListDelinquencies(after, collectionBatchSize)
+		if err != nil {
+			return tracked, err
+		}
+		for _, delinquency := range delinquencies {
+			after = delinquency.TransactionID
+			if err := uc.track(delinquency, now); err != nil {
+				if errors.Is(err, domain.ErrConflict) {
+					continue
+				}
+				return tracked, err
+			}
+			tracked++
+		}
+		if len(delinquencies) < collectionBatchSize {
+			break
+		}
+	}
+
+	if _, err := uc.collectionRepo.CloseResolved(now); err != nil {
+		return tracked, err
+	}
+	return tracked, uc.assignUnassigned(now)
+}
+
+// track opens, reopens or updates the account of a delinquent contract,
+// recording broken promises and escalating it as the policy requires
+func (uc *collectionUseCase) track(delinquency domain.Delinquency, now time.Time) error {
+	account, err := uc.collectionRepo.GetAccountByTransaction(delinquency.TransactionID)
+	if errors.Is(err, domain.ErrNotFound) {
+		account = &domain.CollectionAccount{
+			TransactionID: delinquency.TransactionID,
+			CustomerID:    delinquency.CustomerID,
+			Status:        domain.CollectionOpen,
+			Level:         domain.LevelDesk,
+			CreatedAt:     now,
+		}
+	} else if err != nil {
+		return err
+	}
+
+	if account.Status == domain.CollectionClosed {
+		account.Status = domain.CollectionOpen
+		account.Level = domain.LevelDesk
+		account.CollectorID = nil
+		account.BrokenPromises = 0
+		account.ClosedAt = nil
+		account.EscalatedAt = nil
+	}
+
+	dpd := int(now.Sub(delinquency.OldestDueDate).Hours() / 24)
+	if dpd < 1 {
+		dpd = 1
+	}
+	account.Region = delinquency.Region
+	account.DPD = dpd
+	account.Bucket = domain.BucketOf(dpd)
+	account.OverdueAmount = delinquency.OverdueAmount
+	account.OldestDueDate = delinquency.OldestDueDate
+	account.UpdatedAt = now
+
+	// A promise is broken once its whole day passed with the account still
+	// overdue
+	if account.PromiseDate != nil && now.Sub(*account.PromiseDate) >= 24*time.Hour {
+		account.Logged = append(account.Logged, domain.CollectionActivity{
+			Type:          domain.ActivityPromiseBroken,
+			Note:          fmt.Sprintf("promise to pay %.2f by %s not kept", account.PromiseAmount, account.PromiseDate.Format("2006-01-02")),
+			PromiseDate:   account.PromiseDate,
+			PromiseAmount: account.PromiseAmount,
+			CreatedAt:     now,
+		})
+		account.BrokenPromises++
+		account.PromiseDate = nil
+		account.PromiseAmount = 0
+	}
+
+	uc.escalate(account, now)
+	return uc.collectionRepo.SaveAccount(account)
+}
+
+// escalate raises the level of account to the level of its days past due,
+// or one level when too many promises were broken. Escalated accounts are
+// unassigned, to be assigned to a collector of the new level.
+func (uc *collectionUseCase) escalate(account *domain.CollectionAccount, now time.Time) {
+	target := uc.policy.LevelFor(account.DPD)
+	reason := fmt.Sprintf("%d days past due", account.DPD)
+	if uc.policy.MaxBrokenPromises > 0 && account.BrokenPromises >= uc.policy.MaxBrokenPromises {
+		if next := account.Level.Next(); next.Rank() > target.Rank() {
+			target = next
+			reason = fmt.Sprintf("%d promises to pay broken", account.BrokenPromises)
+		}
+	}
+	if target.Rank() <= account.Level.Rank() {
+		return
+	}
+
+	account.Logged = append(account.Logged, domain.CollectionActivity{
+		Type:      domain.ActivityEscalation,
+		Note:      fmt.Sprintf("escalated from %s to %s: %s", account.Level, target, reason),
+		CreatedAt: now,
+	})
+	account.Level = target
+	account.CollectorID = nil
+	account.Collector = nil
+	account.BrokenPromises = 0
+	account.EscalatedAt = &now
+}
+
+// assignUnassigned assigns open accounts without a collector to the least
+// loaded collector of their level and region
+func (uc *collectionUseCase) assignUnassigned(now time.Time) error {
+	accounts, err := uc.collectionRepo.ListUnassigned(collectionBatchSize)
+	if err != nil {
+		return err
+	}
+	for i := range accounts {
+		account := &accounts[i]
+		collector, err := uc.collectionRepo.LeastLoadedCollector(account.Level, account.Region)
+		if errors.Is(err, domain.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		assignTo(account, collector, nil, now)
+		if err := uc.collectionRepo.SaveAccount(account); err != nil && !errors.Is(err, domain.ErrConflict) {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetAccount implements CollectionUseCase.GetAccount
+func (uc *collectionUseCase) GetAccount(id uint, actor domain.Actor) (*domain.CollectionAccount, error) {
+	account, err := uc.collectionRepo.GetAccount(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.checkAssignee(account, actor); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// Worklist implements CollectionUseCase.Worklist
+func (uc *collectionUseCase) Worklist(filter domain.WorklistFilter, actor domain.Actor, offset, limit int) ([]domain.CollectionAccount, error) {
+	if actor.Role == roleCollector {
+		collector, err := uc.collector(actor)
+		if err != nil {
+			return nil, err
+		}
+		filter.CollectorID = &collector.ID
+	}
+	return uc.collectionRepo.ListWorklist(filter, offset, limit)
+}
+
+// Assign implements CollectionUseCase.Assign
+func (uc *collectionUseCase) Assign(accountID, collectorID uint, actor domain.Actor) (*domain.CollectionAccount, error) {
+	account, err := uc.collectionRepo.GetAccount(accountID)
+	if err != nil {
+		return nil, err
+	}
+	if account.Status != domain.CollectionOpen {
+		return nil, errAccountClosed()
+	}
+	collector, err := uc.collectionRepo.GetCollector(collectorID)
+	if err != nil {
+		return nil, err
+	}
+	if !collector.Active {
+		return nil, domain.NewError(domain.ErrValidation, "collector_inactive", "collector is not active")
+	}
+
+	assignTo(account, collector, &actor.ID, time.Now())
+	if err := uc.collectionRepo.SaveAccount(account); err != nil {
+		return nil, err
+	}
+	prependLogged(account)
+	return account, nil
+}
+
+// LogActivity implements CollectionUseCase.LogActivity. A promise to pay
+// replaces any open promise of the account.
+func (uc *collectionUseCase) LogActivity(accountID uint, activity *domain.CollectionActivity, actor domain.Actor) (*domain.CollectionAccount, error) {
+	switch activity.Type {
+	case domain.ActivityCall, domain.ActivityMessage, domain.ActivityVisit, domain.ActivityNote, domain.ActivityPromiseToPay:
+	default:
+		return nil, domain.NewError(domain.ErrValidation, "invalid_activity_type", "activity type must be call, message, visit, note or promise_to_pay")
+	}
+
+	account, err := uc.collectionRepo.GetAccount(accountID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.checkAssignee(account, actor); err != nil {
+		return nil, err
+	}
+	if account.Status != domain.CollectionOpen {
+		return nil, errAccountClosed()
+	}
+
+	now := time.Now()
+	if activity.Type == domain.ActivityPromiseToPay {
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		if activity.PromiseDate == nil || activity.PromiseDate.Before(today) || activity.PromiseAmount <= 0 {
+			return nil, domain.NewError(domain.ErrValidation, "invalid_promise", "a promise to pay needs a positive amount and a date from today")
+		}
+		account.PromiseDate = activity.PromiseDate
+		account.PromiseAmount = activity.PromiseAmount
+	} else {
+		activity.PromiseDate = nil
+		activity.PromiseAmount = 0
+	}
+
+	activity.ActorID = &actor.ID
+	activity.CreatedAt = now
+	account.Logged = append(account.Logged, *activity)
+	account.LastActivityAt = &now
+	account.UpdatedAt = now
+	if err := uc.collectionRepo.SaveAccount(account); err != nil {
+		return nil, err
+	}
+
+	*activity = account.Logged[len(account.Logged)-1]
+	prependLogged(account)
+	return account, nil
+}
+
+// checkAssignee only lets collectors access the accounts assigned to them
+func (uc *collectionUseCase) checkAssignee(account *domain.CollectionAccount, actor domain.Actor) error {
+	if actor.Role != roleCollector {
+		return nil
+	}
+	collector, err := uc.collector(actor)
+	if err != nil {
+		return err
+	}
+	if account.CollectorID == nil || *account.CollectorID != collector.ID {
+		return domain.NewError(domain.ErrForbidden, "collection_account_not_assigned", "collection account is not assigned to you")
+	}
+	return nil
+}
+
+// collector returns the collector a collector role user signs in as
+func (uc *collectionUseCase) collector(actor domain.Actor) (*domain.Collector, error) {
+	collector, err := uc.collectionRepo.GetCollectorByUser(actor.ID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, domain.NewError(domain.ErrForbidden, "not_a_collector", "user is not registered as a collector")
+	}
+	return collector, err
+}
+
+// assignTo assigns account to collector, by actorID or by the system when
+// nil
+func assignTo(account *domain.CollectionAccount, collector *domain.Collector, actorID *uint, now time.Time) {
+	account.CollectorID = &collector.ID
+	account.Collector = collector
+	account.UpdatedAt = now
+	account.Logged = append(account.Logged, domain.CollectionActivity{
+		Type:      domain.ActivityAssignment,
+		Note:      fmt.Sprintf("assigned to %s", collector.Name),
+		ActorID:   actorID,
+		CreatedAt: now,
+	})
+}
+
+// prependLogged adds the activities account logged to its activity log,
+// which lists the latest first
+func prependLogged(account *domain.CollectionAccount) {
+	activities := make([]domain.CollectionActivity, 0, len(account.Logged)+len(account.Activities))
+	for i := len(account.Logged) - 1; i >= 0; i-- {
+		activities = append(activities, account.Logged[i])
+	}
+	account.Activities = append(activities, account.Activities...)
+	account.Logged = nil
+}
+
+func errAccountClosed() error {
+	return domain.NewError(domain.ErrConflict, "collection_account_closed", "collection account is closed")
+}
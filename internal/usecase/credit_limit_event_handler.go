@@ -0,0 +1,146 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+	"xyz-multifinance/internal/domain"
+)
+
+// creditLimitEventGroup is the consumer group keeping credit limit usage in
+// step with contracts
+const creditLimitEventGroup = "credit-limit"
+
+type creditLimitEventHandler struct {
+	customerUseCase domain.CustomerUseCase
+	transactionRepo domain.TransactionRepository
+}
+
+// NewCreditLimitEventHandler creates the event handler that charges a new
+// contract to the customer's credit limit and releases it when the contract
+// is rejected, cancelled, expired or paid off. A paid off contract reopened
+// by a payment reversal is charged again, and a restructuring is charged the
+// amount it adds to the contract. Events of a contract may be handled in any
+// order: the charge of each contract is kept, so a release never frees more
+// than the contract holds and a charge after the release is skipped.
+func NewCreditLimitEventHandler(customerUseCase domain.CustomerUseCase, transactionRepo domain.TransactionRepository) domain.EventHandler {
+	return &creditLimitEventHandler{
+		customerUseCase: customerUseCase,
+		transactionRepo: transactionRepo,
+	}
+}
+
+// Group implements EventHandler.Group
+func (h *creditLimitEventHandler) Group() string {
+	return creditLimitEventGroup
+}
+
+// Handle implements EventHandler.Handle
+func (h *creditLimitEventHandler) Handle(ctx context.Context, event *domain.Event) error {
+	var adjustment *domain.LimitAdjustment
+
+	switch event.Type {
+	case domain.EventTransactionCreated:
+		var data domain.TransactionCreated
+		if err := event.Decode(&data); err != nil {
+			return err
+		}
+		adjustment = &domain.LimitAdjustment{
+			CustomerID:     data.CustomerID,
+			Tenor:          data.Tenor,
+			Delta:          data.Amount,
+			ContractNumber: data.ContractNumber,
+			Reason:         string(domain.EventTransactionCreated),
+			// The limit was checked when the contract was created. Contracts
+			// created concurrently may all have passed the check, and each
+			// must be charged to be released again when it is closed.
+			Force: true,
+		}
+
+	case domain.EventTransactionStatusChanged:
+		var data domain.TransactionStatusChanged
+		if err := event.Decode(&data); err != nil {
+			return err
+		}
+		if !releasesLimit(data.From, data.To) {
+			return nil
+		}
+		adjustment = &domain.LimitAdjustment{
+			CustomerID:     data.CustomerID,
+			Tenor:          data.Tenor,
+			Delta:          -data.Amount,
+			ContractNumber: data.ContractNumber,
+			Reason:         "transaction_" + string(data.To),
+		}
+
+	case domain.EventContractPaidOff, domain.EventContractReopened:
+		var data domain.ContractEvent
+		if err := event.Decode(&data); err != nil {
+			return err
+		}
+		tx, err := h.transactionRepo.GetByID(data.TransactionID)
+		if err != nil {
+			return err
+		}
+		adjustment = &domain.LimitAdjustment{
+			CustomerID:     tx.CustomerID,
+			Tenor:          tx.Tenor,
+			Delta:          tx.OTRAmount + tx.AdminFee + tx.RestructuredAmount,
+			ContractNumber: tx.ContractNumber,
+			Reason:         string(event.Type),
+			// The contract already exists, so it is charged even when the
+			// limit was used by other contracts meanwhile
+			Force:  true,
+			Reopen: true,
+		}
+		if event.Type == domain.EventContractPaidOff {
+			adjustment.Delta = -adjustment.Delta
+			adjustment.Force = false
+			adjustment.Reopen = false
+		}
+
+	case domain.EventContractRestructured:
+		var data domain.ContractRestructured
+		if err := event.Decode(&data); err != nil {
+			return err
+		}
+		if data.AddedAmount == 0 {
+			return nil
+		}
+		tx, err := h.transactionRepo.GetByID(data.TransactionID)
+		if err != nil {
+			return err
+		}
+		adjustment = &domain.LimitAdjustment{
+			CustomerID:     tx.CustomerID,
+			Tenor:          tx.Tenor,
+			Delta:          data.AddedAmount,
+			ContractNumber: tx.ContractNumber,
+			Reason:         string(event.Type),
+			Force:          true, // Approved relief is not refused for lack of limit
+		}
+
+	default:
+		return nil
+	}
+
+	adjustment.Source = &domain.ProcessedEvent{
+		Consumer:    creditLimitEventGroup,
+		EventID:     event.ID,
+		ProcessedAt: time.Now(),
+	}
+	err := h.customerUseCase.AdjustCreditLimitUsage(adjustment)
+	if errors.Is(err, domain.ErrEventProcessed) {
+		return nil
+	}
+	return err
+}
+
+// releasesLimit reports whether a status change ends a contract that was
+// still charged to the credit limit
+func releasesLimit(from, to domain.TransactionStatus) bool {
+	closed := func(status domain.TransactionStatus) bool {
+		return status == domain.StatusRejected || status == domain.StatusCancelled || status == domain.StatusExpired
+	}
+	return closed(to) && !closed(from)
+}
@@ -8,14 +8,16 @@ import (
 )
 
 type customerUseCase struct {
-	customerRepo domain.CustomerRepository
-	mutex        sync.Mutex // For handling concurrent credit limit updates
+	customerRepo     domain.CustomerRepository
+	screeningUseCase domain.ScreeningUseCase
+	mutex            sync.Mutex // For handling concurrent credit limit updates
 }
 
 // NewCustomerUseCase creates a new instance of CustomerUseCase
-func NewCustomerUseCase(customerRepo domain.CustomerRepository) domain.CustomerUseCase {
+func NewCustomerUseCase(customerRepo domain.CustomerRepository, screeningUseCase domain.ScreeningUseCase) domain.CustomerUseCase {
 	return &customerUseCase{
-		customerRepo: customerRepo,
+		customerRepo:     customerRepo,
+		screeningUseCase: screeningUseCase,
 	}
 }
 
@@ -23,16 +25,30 @@ func NewCustomerUseCase(customerRepo domain.CustomerRepository) domain.CustomerU
 func (uc *customerUseCase) Register(customer *domain.Customer) error {
 	// Check if customer with same NIK already exists
 	existing, err := uc.customerRepo.GetByNIK(customer.NIK)
-	if err != nil {
-		if err.Error() != "not found" {
-			return err
-		}
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return err
 	}
 
+	if existing != nil && existing.DeletedAt.Valid {
+		return domain.NewError(domain.ErrConflict, "customer_nik_deleted", "customer with this NIK was deleted and has not been erased yet")
+	}
 	if existing != nil {
-		return errors.New("customer with this NIK already exists")
+		return domain.NewError(domain.ErrConflict, "customer_nik_exists", "customer with this NIK already exists")
 	}
 
+	// Blocked registrations are refused, held customers are registered with
+	// every contract held for review
+	customer.Phone = domain.NormalizeIdentifier(domain.BlacklistPhone, customer.Phone)
+	screening, err := uc.screeningUseCase.ScreenCustomer(customer)
+	if err != nil {
+		return err
+	}
+	if screening.Flag == domain.RiskBlock {
+		return errScreeningBlocked("registration")
+	}
+	customer.RiskFlag = screening.Flag
+	customer.RiskHits = screening.Hits
+
 	// Set timestamps
 	now := time.Now()
 	customer.CreatedAt = now
@@ -47,7 +63,8 @@ func (uc *customerUseCase) GetProfile(id uint) (*domain.Customer, error) {
 	return uc.customerRepo.GetByID(id)
 }
 
-// UpdateProfile implements CustomerUseCase.UpdateProfile
+// UpdateProfile implements CustomerUseCase.UpdateProfile. customer.Version
+// must carry the version the client last read.
 func (uc *customerUseCase) UpdateProfile(customer *domain.Customer) error {
 	existing, err := uc.customerRepo.GetByID(customer.ID)
 	if err != nil {
@@ -58,9 +75,24 @@ func (uc *customerUseCase) UpdateProfile(customer *domain.Customer) error {
 	existing.FullName = customer.FullName
 	existing.LegalName = customer.LegalName
 	existing.Salary = customer.Salary
+	existing.Version = customer.Version
 	existing.UpdatedAt = time.Now()
 
-	return uc.customerRepo.Update(existing)
+	if err := uc.customerRepo.Update(existing); err != nil {
+		if errors.Is(err, domain.ErrConflict) {
+			return errVersionMismatch()
+		}
+		return err
+	}
+
+	*customer = *existing
+	return nil
+}
+
+// Delete implements CustomerUseCase.Delete. The repository refuses to
+// delete a customer with active contracts.
+func (uc *customerUseCase) Delete(id uint) error {
+	return uc.customerRepo.Delete(id)
 }
 
 // GetCreditLimits implements CustomerUseCase.GetCreditLimits
@@ -81,31 +113,131 @@ func (uc *customerUseCase) CheckCreditLimit(customerID uint, amount float64, ten
 		}
 	}
 
-	return false, errors.New("no credit limit found for the specified tenor")
+	return false, errNoCreditLimitForTenor()
 }
 
 // UpdateCreditLimitUsage implements CustomerUseCase.UpdateCreditLimitUsage
 func (uc *customerUseCase) UpdateCreditLimitUsage(customerID uint, amount float64, tenor int) error {
+	return uc.AdjustCreditLimitUsage(&domain.LimitAdjustment{
+		CustomerID: customerID,
+		Tenor:      tenor,
+		Delta:      amount,
+		Reason:     "manual",
+	})
+}
+
+// maxLimitAdjustRetries bounds the attempts of a credit limit adjustment
+// that keeps losing the race with adjustments made by other instances
+const maxLimitAdjustRetries = 3
+
+// AdjustCreditLimitUsage implements CustomerUseCase.AdjustCreditLimitUsage.
+// Releases never take the used amount below zero, forced reservations may
+// take it beyond the limit. Adjustments of a contract are applied to its
+// charge: a release frees at most what the contract was charged and closes
+// it, and a charge of a closed contract is skipped unless it reopens it. A
+// credit_limit.adjusted event is recorded with the update, which is retried
+// when another instance updated the limit first.
+func (uc *customerUseCase) AdjustCreditLimitUsage(adjustment *domain.LimitAdjustment) error {
 	// Use mutex to prevent race conditions when updating credit limit
 	uc.mutex.Lock()
 	defer uc.mutex.Unlock()
 
-	limits, err := uc.customerRepo.GetCreditLimits(customerID)
+	var lastError error
+	for i := 0; i < maxLimitAdjustRetries; i++ {
+		err := uc.adjustCreditLimitUsage(adjustment)
+		if !errors.Is(err, domain.ErrConflict) {
+			return err
+		}
+		lastError = err
+	}
+	return lastError
+}
+
+// adjustCreditLimitUsage makes one attempt of AdjustCreditLimitUsage
+func (uc *customerUseCase) adjustCreditLimitUsage(adjustment *domain.LimitAdjustment) error {
+	limits, err := uc.customerRepo.GetCreditLimits(adjustment.CustomerID)
 	if err != nil {
 		return err
 	}
 
 	for _, limit := range limits {
-		if limit.Tenor == tenor {
-			if limit.GetAvailableLimit() < amount {
-				return errors.New("insufficient credit limit")
+		if limit.Tenor != adjustment.Tenor {
+			continue
+		}
+
+		delta := adjustment.Delta
+		now := time.Now()
+		if adjustment.ContractNumber != "" {
+			charge, err := uc.customerRepo.GetContractLimitCharge(adjustment.ContractNumber)
+			if errors.Is(err, domain.ErrNotFound) {
+				charge, err = &domain.ContractLimitCharge{ContractNumber: adjustment.ContractNumber, CreditLimitID: limit.ID}, nil
+			}
+			if err != nil {
+				return err
 			}
 
-			limit.UsedAmount += amount
-			limit.UpdatedAt = time.Now()
-			return uc.customerRepo.UpdateCreditLimit(&limit)
+			switch {
+			case delta > 0 && charge.Closed && !adjustment.Reopen:
+				// Released before this charge was delivered
+				return nil
+			case delta > 0:
+				charge.Closed = false
+			default:
+				if -delta > charge.Amount {
+					delta = -charge.Amount
+				}
+				charge.Closed = true
+			}
+			charge.Amount += delta
+			charge.UpdatedAt = now
+			limit.Charge = charge
 		}
+
+		if delta > 0 && !adjustment.Force && limit.GetAvailableLimit() < delta {
+			return domain.ErrInsufficientLimit
+		}
+
+		limit.UsedAmount += delta
+		if limit.UsedAmount < 0 {
+			limit.UsedAmount = 0
+		}
+		limit.UpdatedAt = now
+		limit.Processed = adjustment.Source
+
+		if delta != 0 {
+			event, err := newOutboxEvent(domain.EventLimitAdjusted, nil, domain.LimitAdjusted{
+				CustomerID:     limit.CustomerID,
+				CreditLimitID:  limit.ID,
+				Tenor:          limit.Tenor,
+				Delta:          delta,
+				UsedAmount:     limit.UsedAmount,
+				Amount:         limit.Amount,
+				ContractNumber: adjustment.ContractNumber,
+				Reason:         adjustment.Reason,
+			}, now)
+			if err != nil {
+				return err
+			}
+			limit.Events = []domain.OutboxEvent{event}
+		}
+
+		return uc.customerRepo.UpdateCreditLimit(&limit)
 	}
 
-	return errors.New("no credit limit found for the specified tenor")
+	return errNoCreditLimitForTenor()
+}
+
+// errNoCreditLimitForTenor is returned when the customer has no limit for the requested tenor
+func errNoCreditLimitForTenor() error {
+	return domain.NewError(domain.ErrInsufficientLimit, "credit_limit_not_found", "no credit limit found for the specified tenor")
+}
+
+// errActiveContracts is returned when a customer still has contracts to settle
+func errActiveContracts() error {
+	return domain.NewError(domain.ErrConflict, "customer_has_active_contracts", "customer has active contracts")
+}
+
+// errVersionMismatch is returned when the client's version is stale
+func errVersionMismatch() error {
+	return domain.NewError(domain.ErrPreconditionFailed, "version_mismatch", "resource has been modified")
 }
@@ -0,0 +1,100 @@
+package usecase
+
+import (
+	"errors"
+	"time"
+	"xyz-multifinance/internal/domain"
+)
+
+// erasureBatchSize limits how many requests are processed per run
+const erasureBatchSize = 100
+
+type erasureUseCase struct {
+	customerRepo domain.CustomerRepository
+	erasureRepo  domain.ErasureRequestRepository
+	retention    time.Duration
+}
+
+// NewErasureUseCase creates a new instance of ErasureUseCase. Personal data
+// is kept for the retention period after the customer is deleted.
+func NewErasureUseCase(
+	customerRepo domain.CustomerRepository,
+	erasureRepo domain.ErasureRequestRepository,
+	retention time.Duration,
+) domain.ErasureUseCase {
+	return &erasureUseCase{
+		customerRepo: customerRepo,
+		erasureRepo:  erasureRepo,
+		retention:    retention,
+	}
+}
+
+// RequestErasure implements ErasureUseCase.RequestErasure
+func (uc *erasureUseCase) RequestErasure(customerID uint) (*domain.ErasureRequest, error) {
+	customer, err := uc.customerRepo.GetByIDUnscoped(customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := uc.erasureRepo.GetPendingByCustomerID(customerID)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, domain.NewError(domain.ErrConflict, "erasure_already_requested", "erasure already requested")
+	}
+
+	active, err := uc.customerRepo.HasActiveContracts(customerID)
+	if err != nil {
+		return nil, err
+	}
+	if active {
+		return nil, errActiveContracts()
+	}
+
+	// The retention period starts when the customer relationship ends
+	now := time.Now()
+	deletedAt := now
+	if customer.DeletedAt.Valid {
+		deletedAt = customer.DeletedAt.Time
+	} else if err := uc.customerRepo.Delete(customerID); err != nil {
+		return nil, err
+	}
+
+	request := &domain.ErasureRequest{
+		CustomerID:  customerID,
+		Status:      domain.ErasurePending,
+		RequestedAt: now,
+		EligibleAt:  deletedAt.Add(uc.retention),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := uc.erasureRepo.Create(request); err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+// ProcessDueRequests implements ErasureUseCase.ProcessDueRequests.
+// Anonymisation is idempotent, so a request that fails to be marked
+// completed is safely retried on the next run.
+func (uc *erasureUseCase) ProcessDueRequests(now time.Time) (int, error) {
+	requests, err := uc.erasureRepo.ListDue(now, erasureBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	processed := 0
+	for _, request := range requests {
+		if err := uc.customerRepo.Anonymize(request.CustomerID); err != nil {
+			return processed, err
+		}
+		if err := uc.erasureRepo.MarkCompleted(request.ID, now); err != nil {
+			return processed, err
+		}
+		processed++
+	}
+
+	return processed, nil
+}
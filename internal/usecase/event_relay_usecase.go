@@ -0,0 +1,34 @@
+package usecase
+
+import (
+	"context"
+	"xyz-multifinance/internal/domain"
+)
+
+type eventRelayUseCase struct {
+	outboxRepo domain.OutboxRepository
+	publisher  domain.EventPublisher
+	batchSize  int
+}
+
+// NewEventRelayUseCase creates a new instance of EventRelayUseCase, moving
+// at most batchSize outbox events to the event bus per run
+func NewEventRelayUseCase(outboxRepo domain.OutboxRepository, publisher domain.EventPublisher, batchSize int) domain.EventRelayUseCase {
+	return &eventRelayUseCase{
+		outboxRepo: outboxRepo,
+		publisher:  publisher,
+		batchSize:  batchSize,
+	}
+}
+
+// Relay implements EventRelayUseCase.Relay
+func (uc *eventRelayUseCase) Relay(ctx context.Context) (int, error) {
+	return uc.outboxRepo.PublishPending(uc.batchSize, func(events []domain.OutboxEvent) error {
+		for i := range events {
+			if err := uc.publisher.Publish(ctx, &events[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,136 @@
+package usecase
+
+import (
+	"time"
+	"xyz-multifinance/internal/domain"
+)
+
+// exportBatchSize is the number of rows read at a time while exporting
+const exportBatchSize = 500
+
+type exportUseCase struct {
+	exportRepo      domain.ExportRepository
+	customerRepo    domain.CustomerRepository
+	transactionRepo domain.TransactionRepository
+}
+
+// NewExportUseCase creates a new instance of ExportUseCase
+func NewExportUseCase(
+	exportRepo domain.ExportRepository,
+	customerRepo domain.CustomerRepository,
+	transactionRepo domain.TransactionRepository,
+) domain.ExportUseCase {
+	return &exportUseCase{
+		exportRepo:      exportRepo,
+		customerRepo:    customerRepo,
+		transactionRepo: transactionRepo,
+	}
+}
+
+// Transactions implements ExportUseCase.Transactions
+func (uc *exportUseCase) Transactions(filter domain.TransactionFilter, w domain.RowWriter) error {
+	if filter.CreatedFrom != nil && filter.CreatedTo != nil && !filter.CreatedTo.After(*filter.CreatedFrom) {
+		return domain.NewError(domain.ErrValidation, "invalid_period", "from must be before to")
+	}
+
+	err := w.WriteRow("id", "contract_number", "customer_id", "source", "status", "asset_name",
+		"otr_amount", "admin_fee", "installment_amount", "interest_amount", "tenor",
+		"restructured_amount", "created_at")
+	if err != nil {
+		return err
+	}
+
+	var afterID uint
+	for {
+		transactions, err := uc.exportRepo.ListTransactions(filter, afterID, exportBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, tx := range transactions {
+			err := w.WriteRow(tx.ID, tx.ContractNumber, tx.CustomerID, string(tx.Source), string(tx.Status), tx.AssetName,
+				tx.OTRAmount, tx.AdminFee, tx.InstallmentAmount, tx.InterestAmount, tx.Tenor,
+				tx.RestructuredAmount, tx.CreatedAt)
+			if err != nil {
+				return err
+			}
+		}
+		if len(transactions) < exportBatchSize {
+			return nil
+		}
+		afterID = transactions[len(transactions)-1].ID
+	}
+}
+
+// Installments implements ExportUseCase.Installments
+func (uc *exportUseCase) Installments(transactionID uint, w domain.RowWriter) error {
+	tx, err := uc.transactionRepo.GetByID(transactionID)
+	if err != nil {
+		return err
+	}
+	installments, err := uc.transactionRepo.GetInstallments(transactionID)
+	if err != nil {
+		return err
+	}
+
+	err = w.WriteRow("contract_number", "installment_number", "due_date", "amount", "principal",
+		"interest", "late_fee", "status", "paid_at")
+	if err != nil {
+		return err
+	}
+	for _, installment := range installments {
+		err := w.WriteRow(tx.ContractNumber, installment.InstallmentNumber, installment.DueDate, installment.Amount,
+			installment.Principal, installment.Interest, installment.LateFee, installment.Status, installment.PaidAt)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Statement implements ExportUseCase.Statement. The balance runs over the
+// movements of the receivable accounts, so it includes late fees charged.
+func (uc *exportUseCase) Statement(customerID uint, from, to time.Time, w domain.RowWriter) error {
+	if !to.After(from) {
+		return domain.NewError(domain.ErrValidation, "invalid_period", "from must be before to")
+	}
+	if _, err := uc.customerRepo.GetByID(customerID); err != nil {
+		return err
+	}
+	balance, err := uc.exportRepo.StatementBalance(customerID, from)
+	if err != nil {
+		return err
+	}
+
+	err = w.WriteRow("date", "contract_number", "type", "description", "reference", "debit", "credit", "balance")
+	if err != nil {
+		return err
+	}
+	balance = roundCents(balance)
+	if err := w.WriteRow(from, nil, nil, "Opening balance", nil, nil, nil, balance); err != nil {
+		return err
+	}
+
+	// Line IDs start at 1, so the cursor starts at the first line posted at from
+	afterPostedAt, afterLineID := from, uint(0)
+	for {
+		lines, err := uc.exportRepo.ListStatementLines(customerID, to, afterPostedAt, afterLineID, exportBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, line := range lines {
+			balance = roundCents(balance + line.Debit - line.Credit)
+			err := w.WriteRow(line.PostedAt, line.ContractNumber, string(line.EntryType), line.Description,
+				line.Reference, line.Debit, line.Credit, balance)
+			if err != nil {
+				return err
+			}
+		}
+		if len(lines) < exportBatchSize {
+			break
+		}
+		last := lines[len(lines)-1]
+		afterPostedAt, afterLineID = last.PostedAt, last.LineID
+	}
+
+	return w.WriteRow(to.AddDate(0, 0, -1), nil, nil, "Closing balance", nil, nil, nil, balance)
+}
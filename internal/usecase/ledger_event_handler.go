@@ -0,0 +1,86 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+	"xyz-multifinance/internal/domain"
+)
+
+// ledgerEventGroup is the consumer group posting journal entries
+const ledgerEventGroup = "ledger"
+
+type ledgerEventHandler struct {
+	ledgerUseCase domain.LedgerUseCase
+}
+
+// NewLedgerEventHandler creates the event handler that posts the journal
+// entries of disbursements, payments, reversals, restructurings, write-offs
+// and recoveries to the general ledger
+func NewLedgerEventHandler(ledgerUseCase domain.LedgerUseCase) domain.EventHandler {
+	return &ledgerEventHandler{
+		ledgerUseCase: ledgerUseCase,
+	}
+}
+
+// Group implements EventHandler.Group
+func (h *ledgerEventHandler) Group() string {
+	return ledgerEventGroup
+}
+
+// Handle implements EventHandler.Handle. Entries are posted at the time the
+// event occurred.
+func (h *ledgerEventHandler) Handle(ctx context.Context, event *domain.Event) error {
+	source := &domain.ProcessedEvent{
+		Consumer:    ledgerEventGroup,
+		EventID:     event.ID,
+		ProcessedAt: time.Now(),
+	}
+
+	var err error
+	switch event.Type {
+	case domain.EventContractApproved, domain.EventContractWrittenOff:
+		var data domain.ContractEvent
+		if err := event.Decode(&data); err != nil {
+			return err
+		}
+		if event.Type == domain.EventContractApproved {
+			err = h.ledgerUseCase.PostDisbursement(data.TransactionID, event.OccurredAt, source)
+		} else {
+			err = h.ledgerUseCase.PostWriteOff(data.TransactionID, event.OccurredAt, source)
+		}
+
+	case domain.EventInstallmentPaid, domain.EventInstallmentReversed:
+		var data domain.InstallmentEvent
+		if err := event.Decode(&data); err != nil {
+			return err
+		}
+		if event.Type == domain.EventInstallmentPaid {
+			err = h.ledgerUseCase.PostInstallmentPayment(data, event.OccurredAt, source)
+		} else {
+			err = h.ledgerUseCase.PostInstallmentReversal(data, event.OccurredAt, source)
+		}
+
+	case domain.EventContractRestructured:
+		var data domain.ContractRestructured
+		if err := event.Decode(&data); err != nil {
+			return err
+		}
+		err = h.ledgerUseCase.PostRestructuring(data, event.OccurredAt, source)
+
+	case domain.EventWriteOffRecovered:
+		var data domain.WriteOffRecovered
+		if err := event.Decode(&data); err != nil {
+			return err
+		}
+		err = h.ledgerUseCase.PostRecovery(data, event.OccurredAt, source)
+
+	default:
+		return nil
+	}
+
+	if errors.Is(err, domain.ErrEventProcessed) {
+		return nil
+	}
+	return err
+}
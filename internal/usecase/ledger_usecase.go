@@ -0,0 +1,292 @@
+package usecase
+
+import (
+	"fmt"
+	"time"
+	"xyz-multifinance/internal/domain"
+)
+
+// auditJournalPosted is the audit log action of manual journal entries
+const auditJournalPosted = "journal_entry.posted"
+
+// postingAccounts are the accounts the posting rules post to, which cannot
+// be deactivated
+var postingAccounts = map[string]bool{
+	domain.AccountCash:                true,
+	domain.AccountFinancingReceivable: true,
+	domain.AccountUnearnedInterest:    true,
+	domain.AccountLateFeeReceivable:   true,
+	domain.AccountDeferredAdminFee:    true,
+	domain.AccountInterestIncome:      true,
+	domain.AccountAdminFeeIncome:      true,
+	domain.AccountLateFeeIncome:       true,
+	domain.AccountRecoveryIncome:      true,
+	domain.AccountWriteOffExpense:     true,
+}
+
+type ledgerUseCase struct {
+	ledgerRepo      domain.LedgerRepository
+	transactionRepo domain.TransactionRepository
+}
+
+// NewLedgerUseCase creates a new instance of LedgerUseCase
+func NewLedgerUseCase(ledgerRepo domain.LedgerRepository, transactionRepo domain.TransactionRepository) domain.LedgerUseCase {
+	return &ledgerUseCase{
+		ledgerRepo:      ledgerRepo,
+		transactionRepo: transactionRepo,
+	}
+}
+
+// ListAccounts implements LedgerUseCase.ListAccounts
+func (uc *ledgerUseCase) ListAccounts() ([]domain.Account, error) {
+	return uc.ledgerRepo.ListAccounts()
+}
+
+// CreateAccount implements LedgerUseCase.CreateAccount
+func (uc *ledgerUseCase) CreateAccount(account *domain.Account) error {
+	now := time.Now()
+	account.Active = true
+	account.CreatedAt = now
+	account.UpdatedAt = now
+	return uc.ledgerRepo.CreateAccount(account)
+}
+
+// UpdateAccount implements LedgerUseCase.UpdateAccount
+func (uc *ledgerUseCase) UpdateAccount(account *domain.Account) error {
+	if !account.Active && postingAccounts[account.Code] {
+		return domain.NewError(domain.ErrConflict, "system_account", "accounts used by the posting rules cannot be deactivated")
+	}
+	account.UpdatedAt = time.Now()
+	return uc.ledgerRepo.UpdateAccount(account)
+}
+
+// PostManual implements LedgerUseCase.PostManual
+func (uc *ledgerUseCase) PostManual(entry *domain.JournalEntry, actor domain.Actor) error {
+	now := time.Now()
+	entry.Type = domain.JournalManual
+	entry.ReversalOf = nil
+	entry.CreatedBy = &actor.ID
+	entry.CreatedAt = now
+	if entry.PostedAt.IsZero() {
+		entry.PostedAt = now
+	}
+	if err := entry.Validate(); err != nil {
+		return domain.NewError(domain.ErrValidation, "invalid_journal_entry", err.Error())
+	}
+
+	audit, err := newAuditLog(actor, auditJournalPosted, "journal_entry", 0, map[string]interface{}{
+		"description":    entry.Description,
+		"posted_at":      entry.PostedAt,
+		"transaction_id": entry.TransactionID,
+		"lines":          entry.Lines,
+	}, now)
+	if err != nil {
+		return err
+	}
+
+	entries := []domain.JournalEntry{*entry}
+	if err := uc.ledgerRepo.Post(entries, nil, audit); err != nil {
+		return err
+	}
+	*entry = entries[0]
+	return nil
+}
+
+// GetEntry implements LedgerUseCase.GetEntry
+func (uc *ledgerUseCase) GetEntry(id uint) (*domain.JournalEntry, error) {
+	return uc.ledgerRepo.GetEntry(id)
+}
+
+// ListEntries implements LedgerUseCase.ListEntries
+func (uc *ledgerUseCase) ListEntries(filter domain.JournalFilter, offset, limit int) ([]domain.JournalEntry, error) {
+	return uc.ledgerRepo.ListEntries(filter, offset, limit)
+}
+
+// TrialBalance implements LedgerUseCase.TrialBalance, over the entries
+// posted before asOf
+func (uc *ledgerUseCase) TrialBalance(asOf time.Time) (*domain.TrialBalance, error) {
+	balances, err := uc.ledgerRepo.Balances(asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.TrialBalance{AsOf: asOf, Accounts: []domain.TrialBalanceLine{}}
+	var debit, credit float64
+	for _, balance := range balances {
+		line := domain.TrialBalanceLine{
+			Code:          balance.Code,
+			Name:          balance.Name,
+			Type:          balance.Type,
+			NormalBalance: balance.NormalBalance,
+		}
+		amount := roundCents(balance.Balance)
+		if amount >= 0 {
+			line.Debit = amount
+		} else {
+			line.Credit = -amount
+		}
+		debit += line.Debit
+		credit += line.Credit
+		report.Accounts = append(report.Accounts, line)
+	}
+	report.TotalDebit = roundCents(debit)
+	report.TotalCredit = roundCents(credit)
+	report.Balanced = report.TotalDebit == report.TotalCredit
+	return report, nil
+}
+
+// PostDisbursement implements LedgerUseCase.PostDisbursement. The contract
+// is booked at the installments to be paid, the interest in them unearned
+// until accrued, and the admin fee is recognised at once.
+func (uc *ledgerUseCase) PostDisbursement(transactionID uint, at time.Time, source *domain.ProcessedEvent) error {
+	tx, err := uc.transactionRepo.GetByID(transactionID)
+	if err != nil {
+		return err
+	}
+
+	receivable := 0.0
+	for _, installment := range tx.Installments {
+		if installment.Status != "superseded" {
+			receivable += installment.Amount
+		}
+	}
+	receivable = roundCents(receivable)
+
+	disbursement := newJournalEntry(domain.JournalDisbursement, tx.ID, "", fmt.Sprintf("Disbursement of contract %s", tx.ContractNumber), at)
+	disbursement.Post(domain.AccountFinancingReceivable, receivable)
+	disbursement.Post(domain.AccountCash, -tx.OTRAmount)
+	disbursement.Post(domain.AccountDeferredAdminFee, -tx.AdminFee)
+	disbursement.Post(domain.AccountUnearnedInterest, -(receivable - tx.OTRAmount - tx.AdminFee))
+	entries := []domain.JournalEntry{disbursement}
+
+	if tx.AdminFee > 0 {
+		fee := newJournalEntry(domain.JournalAdminFee, tx.ID, "", fmt.Sprintf("Admin fee of contract %s", tx.ContractNumber), at)
+		fee.Post(domain.AccountDeferredAdminFee, tx.AdminFee)
+		fee.Post(domain.AccountAdminFeeIncome, -tx.AdminFee)
+		entries = append(entries, fee)
+	}
+	return uc.post(entries, source)
+}
+
+// PostInstallmentPayment implements LedgerUseCase.PostInstallmentPayment.
+// The payment settles the receivable and its late fee; its interest is
+// earned by the daily accrual.
+func (uc *ledgerUseCase) PostInstallmentPayment(installment domain.InstallmentEvent, at time.Time, source *domain.ProcessedEvent) error {
+	payment := newJournalEntry(domain.JournalInstallmentPayment, installment.TransactionID, installmentReference(installment.InstallmentID),
+		fmt.Sprintf("Installment %d of contract %s paid", installment.InstallmentNumber, installment.ContractNumber), at)
+	payment.Post(domain.AccountCash, installment.Amount+installment.LateFee)
+	payment.Post(domain.AccountFinancingReceivable, -installment.Amount)
+	payment.Post(domain.AccountLateFeeReceivable, -installment.LateFee)
+	return uc.post([]domain.JournalEntry{payment}, source)
+}
+
+// PostInstallmentReversal implements LedgerUseCase.PostInstallmentReversal.
+// The latest payment of the installment is reversed, and the late fee
+// charged again is recognised.
+func (uc *ledgerUseCase) PostInstallmentReversal(installment domain.InstallmentEvent, at time.Time, source *domain.ProcessedEvent) error {
+	reference := installmentReference(installment.InstallmentID)
+	posted, err := uc.ledgerRepo.ListUnreversed(domain.JournalInstallmentPayment, reference)
+	if err != nil {
+		return err
+	}
+	var entries []domain.JournalEntry
+	if len(posted) > 0 {
+		entries = append(entries, posted[0].Reversal(at))
+	}
+
+	if installment.LateFee > 0 {
+		fee := newJournalEntry(domain.JournalLateFee, installment.TransactionID, reference,
+			fmt.Sprintf("Late fee of installment %d of contract %s", installment.InstallmentNumber, installment.ContractNumber), at)
+		fee.Post(domain.AccountLateFeeReceivable, installment.LateFee)
+		fee.Post(domain.AccountLateFeeIncome, -installment.LateFee)
+		entries = append(entries, fee)
+	}
+	return uc.post(entries, source)
+}
+
+// PostRestructuring implements LedgerUseCase.PostRestructuring. Late fees
+// are capitalised into the new schedule, and the interest it adds is
+// unearned until accrued.
+func (uc *ledgerUseCase) PostRestructuring(restructuring domain.ContractRestructured, at time.Time, source *domain.ProcessedEvent) error {
+	balances, err := uc.ledgerRepo.ContractBalances(restructuring.TransactionID)
+	if err != nil {
+		return err
+	}
+	lateFees := roundCents(balances[domain.AccountLateFeeReceivable])
+
+	entry := newJournalEntry(domain.JournalRestructuring, restructuring.TransactionID, fmt.Sprintf("restructuring:%d", restructuring.RestructuringID),
+		fmt.Sprintf("Restructuring of contract %s", restructuring.ContractNumber), at)
+	entry.Post(domain.AccountFinancingReceivable, lateFees+restructuring.AddedAmount)
+	entry.Post(domain.AccountLateFeeReceivable, -lateFees)
+	entry.Post(domain.AccountUnearnedInterest, -restructuring.AddedAmount)
+
+	var entries []domain.JournalEntry
+	if len(entry.Lines) >= 2 {
+		entries = append(entries, entry)
+	}
+	return uc.post(entries, source)
+}
+
+// PostWriteOff implements LedgerUseCase.PostWriteOff. The contract's
+// receivables are derecognised, net of the interest not earned.
+func (uc *ledgerUseCase) PostWriteOff(transactionID uint, at time.Time, source *domain.ProcessedEvent) error {
+	tx, err := uc.transactionRepo.GetByID(transactionID)
+	if err != nil {
+		return err
+	}
+	balances, err := uc.ledgerRepo.ContractBalances(transactionID)
+	if err != nil {
+		return err
+	}
+	receivable := roundCents(balances[domain.AccountFinancingReceivable])
+	lateFees := roundCents(balances[domain.AccountLateFeeReceivable])
+	unearned := roundCents(-balances[domain.AccountUnearnedInterest])
+
+	entry := newJournalEntry(domain.JournalWriteOff, transactionID, "", fmt.Sprintf("Write-off of contract %s", tx.ContractNumber), at)
+	entry.Post(domain.AccountWriteOffExpense, receivable+lateFees-unearned)
+	entry.Post(domain.AccountUnearnedInterest, unearned)
+	entry.Post(domain.AccountFinancingReceivable, -receivable)
+	entry.Post(domain.AccountLateFeeReceivable, -lateFees)
+
+	var entries []domain.JournalEntry
+	if len(entry.Lines) >= 2 {
+		entries = append(entries, entry)
+	}
+	return uc.post(entries, source)
+}
+
+// PostRecovery implements LedgerUseCase.PostRecovery
+func (uc *ledgerUseCase) PostRecovery(recovery domain.WriteOffRecovered, at time.Time, source *domain.ProcessedEvent) error {
+	entry := newJournalEntry(domain.JournalRecovery, recovery.TransactionID, fmt.Sprintf("write_off:%d", recovery.WriteOffID),
+		fmt.Sprintf("Recovery of write-off %d", recovery.WriteOffID), recovery.ReceivedAt)
+	entry.Post(domain.AccountCash, recovery.Amount)
+	entry.Post(domain.AccountRecoveryIncome, -recovery.Amount)
+	return uc.post([]domain.JournalEntry{entry}, source)
+}
+
+// post checks the entries balance before recording them
+func (uc *ledgerUseCase) post(entries []domain.JournalEntry, source *domain.ProcessedEvent) error {
+	for i := range entries {
+		if err := entries[i].Validate(); err != nil {
+			return fmt.Errorf("%s entry: %w", entries[i].Type, err)
+		}
+	}
+	return uc.ledgerRepo.Post(entries, source, nil)
+}
+
+// newJournalEntry builds an entry of a contract, to which lines are posted
+func newJournalEntry(entryType domain.JournalType, transactionID uint, reference, description string, postedAt time.Time) domain.JournalEntry {
+	return domain.JournalEntry{
+		Type:          entryType,
+		TransactionID: &transactionID,
+		Reference:     reference,
+		Description:   description,
+		PostedAt:      postedAt,
+		CreatedAt:     time.Now(),
+	}
+}
+
+// installmentReference references the entries of an installment
+func installmentReference(installmentID uint) string {
+	return fmt.Sprintf("installment:%d", installmentID)
+}
@@ -0,0 +1,80 @@
+package usecase
+
+import (
+	"errors"
+	"time"
+	"xyz-multifinance/internal/domain"
+)
+
+type merchantUseCase struct {
+	merchantRepo domain.MerchantRepository
+}
+
+// NewMerchantUseCase creates a new instance of MerchantUseCase
+func NewMerchantUseCase(merchantRepo domain.MerchantRepository) domain.MerchantUseCase {
+	return &merchantUseCase{
+		merchantRepo: merchantRepo,
+	}
+}
+
+// Create implements MerchantUseCase.Create
+func (uc *merchantUseCase) Create(merchant *domain.Merchant) error {
+	now := time.Now()
+	merchant.Status = domain.MerchantActive
+	merchant.Version = 1
+	merchant.CreatedAt = now
+	merchant.UpdatedAt = now
+	return uc.merchantRepo.Create(merchant)
+}
+
+// GetByID implements MerchantUseCase.GetByID
+func (uc *merchantUseCase) GetByID(id uint) (*domain.Merchant, error) {
+	return uc.merchantRepo.GetByID(id)
+}
+
+// List implements MerchantUseCase.List
+func (uc *merchantUseCase) List(offset, limit int) ([]domain.Merchant, error) {
+	return uc.merchantRepo.List(offset, limit)
+}
+
+// Update implements MerchantUseCase.Update. merchant.Version must be the
+// version the client last read.
+func (uc *merchantUseCase) Update(merchant *domain.Merchant) error {
+	existing, err := uc.merchantRepo.GetByID(merchant.ID)
+	if err != nil {
+		return err
+	}
+
+	existing.Name = merchant.Name
+	existing.Type = merchant.Type
+	existing.CommissionRate = merchant.CommissionRate
+	existing.BankName = merchant.BankName
+	existing.BankAccountNumber = merchant.BankAccountNumber
+	existing.BankAccountName = merchant.BankAccountName
+	existing.Status = merchant.Status
+	existing.Version = merchant.Version
+	existing.UpdatedAt = time.Now()
+
+	if err := uc.merchantRepo.Update(existing); err != nil {
+		if errors.Is(err, domain.ErrConflict) {
+			return errVersionMismatch()
+		}
+		return err
+	}
+
+	*merchant = *existing
+	return nil
+}
+
+// Delete implements MerchantUseCase.Delete
+func (uc *merchantUseCase) Delete(id uint) error {
+	pending, err := uc.merchantRepo.HasPendingSettlements(id)
+	if err != nil {
+		return err
+	}
+	if pending {
+		return domain.NewError(domain.ErrConflict, "merchant_has_pending_settlements", "merchant has unsettled contracts or unpaid settlements")
+	}
+
+	return uc.merchantRepo.Delete(id)
+}
@@ -0,0 +1,41 @@
+package usecase
+
+import (
+	"context"
+	"xyz-multifinance/internal/domain"
+)
+
+// notificationEventGroup is the consumer group sending customer notifications
+const notificationEventGroup = "notifications"
+
+type notificationEventHandler struct {
+	notificationUseCase domain.NotificationUseCase
+}
+
+// NewNotificationEventHandler creates the event handler that sends a receipt
+// to the customer once an installment is paid
+func NewNotificationEventHandler(notificationUseCase domain.NotificationUseCase) domain.EventHandler {
+	return &notificationEventHandler{
+		notificationUseCase: notificationUseCase,
+	}
+}
+
+// Group implements EventHandler.Group
+func (h *notificationEventHandler) Group() string {
+	return notificationEventGroup
+}
+
+// Handle implements EventHandler.Handle. Receipts already sent on a channel
+// are not sent again when the event is redelivered.
+func (h *notificationEventHandler) Handle(ctx context.Context, event *domain.Event) error {
+	if event.Type != domain.EventInstallmentPaid {
+		return nil
+	}
+
+	var data domain.InstallmentEvent
+	if err := event.Decode(&data); err != nil {
+		return err
+	}
+	_, err := h.notificationUseCase.SendReceipt(ctx, &data)
+	return err
+}
@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"text/template"
+	"time"
+	"xyz-multifinance/internal/domain"
+)
+
+// notificationData is the data notification templates are rendered with
+type notificationData struct {
+	ContractNumber    string
+	InstallmentNumber int
+	Tenor             int
+	Amount            float64
+	DueDate           time.Time
+	PaidAt            time.Time
+	DaysBefore        int
+}
+
+type notificationTemplate struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+// notificationTemplates holds the subject and body of every notification
+// kind per locale. SMS messages use the body only, so bodies stay short.
+var notificationTemplates = map[domain.NotificationKind]map[domain.Locale]notificationTemplate{
+	domain.NotificationInstallmentReminder: {
+		domain.LocaleID: mustParseTemplate(domain.LocaleID,
+			`Cicilan ke-{{.InstallmentNumber}} jatuh tempo {{date .DueDate}}`,
+			`Cicilan ke-{{.InstallmentNumber}}/{{.Tenor}} kontrak {{.ContractNumber}} sebesar {{rupiah .Amount}} jatuh tempo {{if eq .DaysBefore 0}}hari ini{{else}}dalam {{.DaysBefore}} hari{{end}} ({{date .DueDate}}). Abaikan pesan ini jika sudah membayar.`,
+		),
+		domain.LocaleEN: mustParseTemplate(domain.LocaleEN,
+			`Installment {{.InstallmentNumber}} due on {{date .DueDate}}`,
+			`Installment {{.InstallmentNumber}}/{{.Tenor}} of contract {{.ContractNumber}} for {{rupiah .Amount}} is due {{if eq .DaysBefore 0}}today{{else}}in {{.DaysBefore}} day{{if gt .DaysBefore 1}}s{{end}}{{end}} ({{date .DueDate}}). Please ignore this message if you have already paid.`,
+		),
+	},
+	domain.NotificationPaymentReceipt: {
+		domain.LocaleID: mustParseTemplate(domain.LocaleID,
+			`Pembayaran cicilan ke-{{.InstallmentNumber}} diterima`,
+			`Pembayaran cicilan ke-{{.InstallmentNumber}}/{{.Tenor}} kontrak {{.ContractNumber}} sebesar {{rupiah .Amount}} telah kami terima pada {{date .PaidAt}}. Terima kasih.`,
+		),
+		domain.LocaleEN: mustParseTemplate(domain.LocaleEN,
+			`Payment for installment {{.InstallmentNumber}} received`,
+			`We received your payment of {{rupiah .Amount}} for installment {{.InstallmentNumber}}/{{.Tenor}} of contract {{.ContractNumber}} on {{date .PaidAt}}. Thank you.`,
+		),
+	},
+}
+
+// renderNotification renders the subject and body of a notification,
+// falling back to Indonesian for unknown locales
+func renderNotification(kind domain.NotificationKind, locale domain.Locale, data notificationData) (string, string, error) {
+	templates, ok := notificationTemplates[kind][locale]
+	if !ok {
+		templates, ok = notificationTemplates[kind][domain.LocaleID]
+	}
+	if !ok {
+		return "", "", fmt.Errorf("no template for notification %s", kind)
+	}
+
+	var subject, body bytes.Buffer
+	if err := templates.subject.Execute(&subject, data); err != nil {
+		return "", "", err
+	}
+	if err := templates.body.Execute(&body, data); err != nil {
+		return "", "", err
+	}
+	return subject.String(), body.String(), nil
+}
+
+func mustParseTemplate(locale domain.Locale, subject, body string) notificationTemplate {
+	funcs := template.FuncMap{
+		"rupiah": formatRupiah,
+		"date": func(t time.Time) string {
+			return formatDate(locale, t)
+		},
+	}
+	return notificationTemplate{
+		subject: template.Must(template.New("subject").Funcs(funcs).Parse(subject)),
+		body:    template.Must(template.New("body").Funcs(funcs).Parse(body)),
+	}
+}
+
+var indonesianMonths = [...]string{
+	"Januari", "Februari", "Maret", "April", "Mei", "Juni",
+	"Juli", "Agustus", "September", "Oktober", "November", "Desember",
+}
+
+// formatDate formats a date as "2 Januari 2024" or "2 January 2024"
+func formatDate(locale domain.Locale, t time.Time) string {
+	if locale == domain.LocaleEN {
+		return t.Format("2 January 2006")
+	}
+	return fmt.Sprintf("%d %s %d", t.Day(), indonesianMonths[t.Month()-1], t.Year())
+}
+
+// formatRupiah formats an amount as "Rp1.250.000", rounded to whole rupiah
+func formatRupiah(amount float64) string {
+	sign := ""
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	digits := strconv.FormatFloat(amount, 'f', 0, 64)
+
+	var b bytes.Buffer
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(d)
+	}
+	return sign + "Rp" + b.String()
+}
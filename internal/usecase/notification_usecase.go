@@ -0,0 +1,237 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"xyz-multifinance/internal/domain"
+)
+
+// reminderBatchSize bounds the installments loaded per query of a reminder run
+const reminderBatchSize = 100
+
+type notificationUseCase struct {
+	notificationRepo domain.NotificationRepository
+	customerRepo     domain.CustomerRepository
+	transactionRepo  domain.TransactionRepository
+	senders          map[domain.NotificationChannel]domain.NotificationSender
+	reminderDays     []int
+}
+
+// NewNotificationUseCase creates a new instance of NotificationUseCase.
+// Channels without a sender are skipped. Reminders are sent reminderDays
+// days before an installment is due, 0 meaning on the due date.
+func NewNotificationUseCase(
+	notificationRepo domain.NotificationRepository,
+	customerRepo domain.CustomerRepository,
+	transactionRepo domain.TransactionRepository,
+	senders map[domain.NotificationChannel]domain.NotificationSender,
+	reminderDays []int,
+) domain.NotificationUseCase {
+	return &notificationUseCase{
+		notificationRepo: notificationRepo,
+		customerRepo:     customerRepo,
+		transactionRepo:  transactionRepo,
+		senders:          senders,
+		reminderDays:     reminderDays,
+	}
+}
+
+// GetPreference implements NotificationUseCase.GetPreference. Customers who
+// never saved preferences get the defaults, without contact details.
+func (uc *notificationUseCase) GetPreference(customerID uint) (*domain.NotificationPreference, error) {
+	preference, err := uc.notificationRepo.GetPreference(customerID)
+	if errors.Is(err, domain.ErrNotFound) {
+		if _, err := uc.customerRepo.GetByID(customerID); err != nil {
+			return nil, err
+		}
+		return defaultPreference(customerID), nil
+	}
+	return preference, err
+}
+
+// UpdatePreference implements NotificationUseCase.UpdatePreference
+func (uc *notificationUseCase) UpdatePreference(preference *domain.NotificationPreference) (*domain.NotificationPreference, error) {
+	if preference.Locale == "" {
+		preference.Locale = domain.LocaleID
+	}
+	if preference.Locale != domain.LocaleID && preference.Locale != domain.LocaleEN {
+		return nil, domain.NewError(domain.ErrValidation, "invalid_locale", "locale must be id or en")
+	}
+	if _, err := uc.customerRepo.GetByID(preference.CustomerID); err != nil {
+		return nil, err
+	}
+
+	preference.UpdatedAt = time.Now()
+	if err := uc.notificationRepo.SavePreference(preference); err != nil {
+		return nil, err
+	}
+	return uc.notificationRepo.GetPreference(preference.CustomerID)
+}
+
+// ListNotifications implements NotificationUseCase.ListNotifications
+func (uc *notificationUseCase) ListNotifications(customerID uint, offset, limit int) ([]domain.Notification, error) {
+	return uc.notificationRepo.List(customerID, offset, limit)
+}
+
+// SendReminders implements NotificationUseCase.SendReminders. Each
+// installment is reminded once per configured day and channel, so the job
+// may run several times a day; failed sends are retried on the next run.
+func (uc *notificationUseCase) SendReminders(ctx context.Context, now time.Time) (int, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	sent := 0
+	var failures []error
+	for _, days := range uc.reminderDays {
+		from := today.AddDate(0, 0, days)
+		to := from.AddDate(0, 0, 1)
+
+		var afterID uint
+		for {
+			installments, err := uc.transactionRepo.ListInstallmentsDueBetween(from, to, afterID, reminderBatchSize)
+			if err != nil {
+				return sent, err
+			}
+
+			for i := range installments {
+				installment := &installments[i]
+				afterID = installment.ID
+				if installment.Transaction == nil {
+					continue
+				}
+
+				n, err := uc.notify(ctx, installment.Transaction.CustomerID, domain.NotificationInstallmentReminder,
+					fmt.Sprintf("installment:%d:reminder:%d", installment.ID, days),
+					notificationData{
+						ContractNumber:    installment.Transaction.ContractNumber,
+						InstallmentNumber: installment.InstallmentNumber,
+						Tenor:             installment.Transaction.Tenor,
+						Amount:            installment.Amount,
+						DueDate:           installment.DueDate,
+						DaysBefore:        days,
+					},
+				)
+				sent += n
+				if err != nil {
+					failures = append(failures, err)
+				}
+			}
+
+			if len(installments) < reminderBatchSize {
+				break
+			}
+		}
+	}
+	return sent, errors.Join(failures...)
+}
+
+// SendReceipt implements NotificationUseCase.SendReceipt. Channels that
+// already received the receipt are skipped, so a failed receipt can be
+// retried as a whole.
+func (uc *notificationUseCase) SendReceipt(ctx context.Context, payment *domain.InstallmentEvent) (int, error) {
+	tx, err := uc.transactionRepo.GetByID(payment.TransactionID)
+	if err != nil {
+		return 0, err
+	}
+
+	paidAt := time.Now()
+	if payment.PaidAt != nil {
+		paidAt = *payment.PaidAt
+	}
+	return uc.notify(ctx, tx.CustomerID, domain.NotificationPaymentReceipt,
+		fmt.Sprintf("installment:%d:receipt", payment.InstallmentID),
+		notificationData{
+			ContractNumber:    tx.ContractNumber,
+			InstallmentNumber: payment.InstallmentNumber,
+			Tenor:             tx.Tenor,
+			Amount:            payment.Amount,
+			DueDate:           payment.DueDate,
+			PaidAt:            paidAt,
+		},
+	)
+}
+
+// notify sends a notification on every channel the customer has enabled and
+// has not yet received it on. Send failures are recorded and returned after
+// the remaining channels were tried.
+func (uc *notificationUseCase) notify(ctx context.Context, customerID uint, kind domain.NotificationKind, reference string, data notificationData) (int, error) {
+	preference, err := uc.notificationRepo.GetPreference(customerID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	subject, body, err := renderNotification(kind, preference.Locale, data)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	var failures []error
+	for _, channel := range domain.NotificationChannels {
+		recipient, ok := preference.Recipient(channel)
+		if !ok {
+			continue
+		}
+		sender, ok := uc.senders[channel]
+		if !ok {
+			continue
+		}
+		done, err := uc.notificationRepo.HasSent(reference, channel)
+		if err != nil {
+			return sent, err
+		}
+		if done {
+			continue
+		}
+
+		message := &domain.NotificationMessage{
+			Channel:   channel,
+			Recipient: recipient,
+			Subject:   subject,
+			Body:      body,
+		}
+		now := time.Now()
+		notification := &domain.Notification{
+			CustomerID: customerID,
+			Kind:       kind,
+			Channel:    channel,
+			Reference:  reference,
+			Recipient:  recipient,
+			Subject:    subject,
+			Body:       body,
+			Status:     domain.NotificationSent,
+			Attempts:   1,
+			SentAt:     &now,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+		if err := sender.Send(ctx, message); err != nil {
+			notification.Status = domain.NotificationFailed
+			notification.LastError = err.Error()
+			notification.SentAt = nil
+			failures = append(failures, fmt.Errorf("%s %s: %w", reference, channel, err))
+		} else {
+			sent++
+		}
+
+		if err := uc.notificationRepo.Record(notification); err != nil {
+			return sent, err
+		}
+	}
+	return sent, errors.Join(failures...)
+}
+
+// defaultPreference returns the preference of a customer who never saved one
+func defaultPreference(customerID uint) *domain.NotificationPreference {
+	return &domain.NotificationPreference{
+		CustomerID:   customerID,
+		Locale:       domain.LocaleID,
+		EmailEnabled: true,
+		SMSEnabled:   true,
+		PushEnabled:  true,
+	}
+}
@@ -0,0 +1,75 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+	"xyz-multifinance/internal/domain"
+)
+
+// newOutboxEvent builds an event, addressed to the webhooks of partnerID
+// when set
+func newOutboxEvent(eventType domain.EventType, partnerID *uint, data interface{}, occurredAt time.Time) (domain.OutboxEvent, error) {
+	id, err := randomHex(16)
+	if err != nil {
+		return domain.OutboxEvent{}, fmt.Errorf("failed to generate event id: %w", err)
+	}
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return domain.OutboxEvent{}, fmt.Errorf("failed to encode event: %w", err)
+	}
+	envelope := domain.Event{
+		ID:         "evt_" + id,
+		Type:       eventType,
+		OccurredAt: occurredAt,
+		Data:       encoded,
+	}
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		return domain.OutboxEvent{}, fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	return domain.OutboxEvent{
+		EventID:   envelope.ID,
+		EventType: eventType,
+		PartnerID: partnerID,
+		Payload:   payload,
+		CreatedAt: occurredAt,
+	}, nil
+}
+
+// newContractEvent builds a contract.* event for tx
+func newContractEvent(eventType domain.EventType, tx *domain.Transaction, occurredAt time.Time) (domain.OutboxEvent, error) {
+	return newOutboxEvent(eventType, tx.PartnerID, domain.ContractEvent{
+		TransactionID:  tx.ID,
+		ContractNumber: tx.ContractNumber,
+		Status:         tx.Status,
+	}, occurredAt)
+}
+
+// newInstallmentEvent builds an installment.* event for an installment of tx
+func newInstallmentEvent(eventType domain.EventType, tx *domain.Transaction, installment *domain.Installment, occurredAt time.Time) (domain.OutboxEvent, error) {
+	return newOutboxEvent(eventType, tx.PartnerID, domain.InstallmentEvent{
+		InstallmentID:     installment.ID,
+		InstallmentNumber: installment.InstallmentNumber,
+		TransactionID:     tx.ID,
+		ContractNumber:    tx.ContractNumber,
+		Amount:            installment.Amount,
+		DueDate:           installment.DueDate,
+		Status:            installment.Status,
+		LateFee:           installment.LateFee,
+		PaidAt:            installment.PaidAt,
+	}, occurredAt)
+}
+
+// randomHex returns n random bytes, hex encoded
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,166 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+	"xyz-multifinance/internal/domain"
+	"xyz-multifinance/internal/pkg/crypto"
+)
+
+type partnerUseCase struct {
+	partnerRepo   domain.PartnerRepository
+	rotationGrace time.Duration
+}
+
+// NewPartnerUseCase creates a new instance of PartnerUseCase. Keys replaced
+// by a rotation keep working for the rotation grace period.
+func NewPartnerUseCase(partnerRepo domain.PartnerRepository, rotationGrace time.Duration) domain.PartnerUseCase {
+	return &partnerUseCase{
+		partnerRepo:   partnerRepo,
+		rotationGrace: rotationGrace,
+	}
+}
+
+// errInvalidCredential hides whether the key is unknown, revoked or expired
+func errInvalidCredential() error {
+	return domain.NewError(domain.ErrUnauthorized, "invalid_api_key", "invalid api key")
+}
+
+// Register implements PartnerUseCase.Register
+func (uc *partnerUseCase) Register(partner *domain.Partner) error {
+	partner.Status = domain.PartnerActive
+	return uc.partnerRepo.Create(partner)
+}
+
+// GetByID implements PartnerUseCase.GetByID
+func (uc *partnerUseCase) GetByID(id uint) (*domain.Partner, error) {
+	return uc.partnerRepo.GetByID(id)
+}
+
+// List implements PartnerUseCase.List
+func (uc *partnerUseCase) List(offset, limit int) ([]domain.Partner, error) {
+	return uc.partnerRepo.List(offset, limit)
+}
+
+// UpdateStatus implements PartnerUseCase.UpdateStatus
+func (uc *partnerUseCase) UpdateStatus(id uint, status domain.PartnerStatus) (*domain.Partner, error) {
+	if err := uc.partnerRepo.UpdateStatus(id, status); err != nil {
+		return nil, err
+	}
+	return uc.partnerRepo.GetByID(id)
+}
+
+// IssueKey implements PartnerUseCase.IssueKey
+func (uc *partnerUseCase) IssueKey(partnerID uint) (*domain.IssuedAPIKey, error) {
+	if _, err := uc.partnerRepo.GetByID(partnerID); err != nil {
+		return nil, err
+	}
+
+	issued, err := newAPIKey(partnerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.partnerRepo.CreateKey(&issued.PartnerAPIKey); err != nil {
+		return nil, err
+	}
+	return issued, nil
+}
+
+// RotateKey implements PartnerUseCase.RotateKey
+func (uc *partnerUseCase) RotateKey(partnerID uint) (*domain.IssuedAPIKey, error) {
+	if _, err := uc.partnerRepo.GetByID(partnerID); err != nil {
+		return nil, err
+	}
+
+	issued, err := newAPIKey(partnerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.partnerRepo.RotateKeys(&issued.PartnerAPIKey, time.Now().Add(uc.rotationGrace)); err != nil {
+		return nil, err
+	}
+	return issued, nil
+}
+
+// RevokeKey implements PartnerUseCase.RevokeKey
+func (uc *partnerUseCase) RevokeKey(partnerID uint, keyID string) error {
+	return uc.partnerRepo.RevokeKey(partnerID, keyID)
+}
+
+// ListKeys implements PartnerUseCase.ListKeys
+func (uc *partnerUseCase) ListKeys(partnerID uint) ([]domain.PartnerAPIKey, error) {
+	if _, err := uc.partnerRepo.GetByID(partnerID); err != nil {
+		return nil, err
+	}
+	return uc.partnerRepo.ListKeys(partnerID)
+}
+
+// Authenticate implements PartnerUseCase.Authenticate
+func (uc *partnerUseCase) Authenticate(keyID string) (*domain.PartnerCredential, error) {
+	key, err := uc.partnerRepo.GetKeyByKeyID(keyID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, errInvalidCredential()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if !key.Usable(now) {
+		return nil, errInvalidCredential()
+	}
+
+	partner, err := uc.partnerRepo.GetByID(key.PartnerID)
+	if err != nil {
+		return nil, err
+	}
+	if partner.Status != domain.PartnerActive {
+		return nil, domain.NewError(domain.ErrForbidden, "partner_suspended", "partner is suspended")
+	}
+
+	secret, err := crypto.Decrypt(key.Secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt api key secret: %w", err)
+	}
+
+	// Usage tracking must not fail authentication
+	_ = uc.partnerRepo.TouchKey(key.ID, now)
+
+	return &domain.PartnerCredential{
+		Partner: partner,
+		Key:     key,
+		Secret:  []byte(secret),
+	}, nil
+}
+
+// newAPIKey generates a key ID and secret, storing the secret encrypted
+func newAPIKey(partnerID uint) (*domain.IssuedAPIKey, error) {
+	id := make([]byte, 12)
+	secret := make([]byte, 32)
+	if _, err := rand.Read(id); err != nil {
+		return nil, fmt.Errorf("failed to generate api key: %w", err)
+	}
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate api key: %w", err)
+	}
+
+	plaintext := base64.RawURLEncoding.EncodeToString(secret)
+	encrypted, err := crypto.Encrypt(plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt api key secret: %w", err)
+	}
+
+	return &domain.IssuedAPIKey{
+		PartnerAPIKey: domain.PartnerAPIKey{
+			PartnerID: partnerID,
+			KeyID:     "pk_" + hex.EncodeToString(id),
+			Secret:    encrypted,
+			Status:    domain.APIKeyActive,
+		},
+		Secret: plaintext,
+	}, nil
+}
@@ -0,0 +1,346 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+	"xyz-multifinance/internal/domain"
+	"xyz-multifinance/internal/pkg/statement"
+)
+
+// amountTolerance absorbs rounding when comparing amounts in rupiah
+const amountTolerance = 0.005
+
+// Reasons a payment is rejected, to be refunded by the bank
+const (
+	rejectAccountClosed    = "virtual_account_closed"
+	rejectNothingDue       = "no_outstanding_installment"
+	rejectAmountMismatch   = "amount_mismatch"
+	rejectInstallmentTaken = "installment_already_paid"
+	rejectNotApproved      = "contract_not_approved"
+)
+
+type paymentUseCase struct {
+	paymentRepo        domain.PaymentRepository
+	transactionRepo    domain.TransactionRepository
+	transactionUseCase domain.TransactionUseCase
+	banks              map[string]string
+	statementDir       string
+}
+
+// NewPaymentUseCase creates a new instance of PaymentUseCase. banks maps the
+// code of every bank offering virtual accounts to the prefix of its account
+// numbers. Daily statements are read from statementDir/<bank>/<YYYYMMDD>.csv
+// or .mt940.
+func NewPaymentUseCase(
+	paymentRepo domain.PaymentRepository,
+	transactionRepo domain.TransactionRepository,
+	transactionUseCase domain.TransactionUseCase,
+	banks map[string]string,
+	statementDir string,
+) domain.PaymentUseCase {
+	return &paymentUseCase{
+		paymentRepo:        paymentRepo,
+		transactionRepo:    transactionRepo,
+		transactionUseCase: transactionUseCase,
+		banks:              banks,
+		statementDir:       statementDir,
+	}
+}
+
+// CreateVirtualAccount implements PaymentUseCase.CreateVirtualAccount. The
+// number is the bank prefix followed by the zero padded transaction ID, so
+// it is stable and unique per bank. An existing account is returned as is.
+func (uc *paymentUseCase) CreateVirtualAccount(transactionID uint, bankCode string) (*domain.VirtualAccount, error) {
+	prefix, ok := uc.banks[bankCode]
+	if !ok {
+		return nil, errUnknownBank()
+	}
+
+	tx, err := uc.transactionRepo.GetByID(transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if tx.Status != domain.StatusApproved {
+		return nil, domain.NewError(domain.ErrConflict, "transaction_not_approved", "virtual accounts are only issued for approved transactions")
+	}
+
+	accounts, err := uc.paymentRepo.ListVirtualAccounts(transactionID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range accounts {
+		if accounts[i].BankCode == bankCode {
+			return &accounts[i], nil
+		}
+	}
+
+	account := &domain.VirtualAccount{
+		TransactionID: transactionID,
+		BankCode:      bankCode,
+		Number:        fmt.Sprintf("%s%011d", prefix, transactionID),
+		Status:        domain.VirtualAccountActive,
+	}
+	if err := uc.paymentRepo.CreateVirtualAccount(account); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// ListVirtualAccounts implements PaymentUseCase.ListVirtualAccounts
+func (uc *paymentUseCase) ListVirtualAccounts(transactionID uint) ([]domain.VirtualAccount, error) {
+	return uc.paymentRepo.ListVirtualAccounts(transactionID)
+}
+
+// HandleCallback implements PaymentUseCase.HandleCallback. The payment
+// credits the oldest outstanding installment of the contract and must match
+// its amount plus any late fee; otherwise it is recorded as rejected. Callbacks repeated by
+// the bank return the recorded payment.
+func (uc *paymentUseCase) HandleCallback(bankCode string, callback *domain.VACallback) (*domain.Payment, error) {
+	if _, ok := uc.banks[bankCode]; !ok {
+		return nil, errUnknownBank()
+	}
+
+	payment, err := uc.paymentRepo.GetPaymentByReference(bankCode, callback.Reference)
+	if err == nil {
+		if payment.Status == domain.PaymentReceived {
+			return payment, uc.credit(payment)
+		}
+		return payment, nil
+	}
+	if !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+
+	account, err := uc.paymentRepo.GetVirtualAccountByNumber(callback.Number)
+	if err != nil {
+		return nil, err
+	}
+	if account.BankCode != bankCode {
+		return nil, domain.NewError(domain.ErrNotFound, "virtual_account_not_found", "virtual account not found")
+	}
+
+	payment = &domain.Payment{
+		VirtualAccountID: account.ID,
+		TransactionID:    account.TransactionID,
+		BankCode:         bankCode,
+		Reference:        callback.Reference,
+		Amount:           callback.Amount,
+		PaidAt:           callback.PaidAt,
+		Status:           domain.PaymentReceived,
+	}
+	if account.Status != domain.VirtualAccountActive {
+		payment.Status = domain.PaymentRejected
+		payment.RejectReason = rejectAccountClosed
+	}
+
+	if err := uc.paymentRepo.CreatePayment(payment); err != nil {
+		if errors.Is(err, domain.ErrConflict) {
+			// Recorded by a concurrent callback for the same reference
+			return uc.paymentRepo.GetPaymentByReference(bankCode, callback.Reference)
+		}
+		return nil, err
+	}
+	if payment.Status == domain.PaymentReceived {
+		return payment, uc.credit(payment)
+	}
+	return payment, nil
+}
+
+// credit pays the installment a received payment is for
+func (uc *paymentUseCase) credit(payment *domain.Payment) error {
+	installments, err := uc.transactionRepo.GetInstallments(payment.TransactionID)
+	if err != nil {
+		return err
+	}
+	sort.Slice(installments, func(i, j int) bool {
+		return installments[i].InstallmentNumber < installments[j].InstallmentNumber
+	})
+
+	var due *domain.Installment
+	for i := range installments {
+		if installments[i].IsOutstanding() {
+			due = &installments[i]
+			break
+		}
+	}
+
+	switch {
+	case due == nil:
+		payment.Status = domain.PaymentRejected
+		payment.RejectReason = rejectNothingDue
+	case math.Abs(due.Amount+due.LateFee-payment.Amount) > amountTolerance:
+		payment.Status = domain.PaymentRejected
+		payment.RejectReason = rejectAmountMismatch
+	default:
+		err := uc.transactionUseCase.PayInstallment(due.ID)
+		var domainErr *domain.Error
+		switch {
+		case err == nil:
+			payment.Status = domain.PaymentCredited
+			payment.InstallmentID = &due.ID
+		case errors.As(err, &domainErr) && domainErr.Code == "installment_already_paid":
+			payment.Status = domain.PaymentRejected
+			payment.RejectReason = rejectInstallmentTaken
+		case errors.As(err, &domainErr) && domainErr.Code == "contract_not_approved":
+			payment.Status = domain.PaymentRejected
+			payment.RejectReason = rejectNotApproved
+		default:
+			// Left received, credited when the bank repeats the callback
+			return err
+		}
+	}
+
+	return uc.paymentRepo.UpdatePayment(payment)
+}
+
+// Reconcile implements PaymentUseCase.Reconcile, matching the credits of a
+// statement by reference against the payments recorded for the bank on the
+// statement date
+func (uc *paymentUseCase) Reconcile(bankCode string, statementDate time.Time, format domain.StatementFormat, fileName string, r io.Reader) (*domain.Reconciliation, error) {
+	if _, ok := uc.banks[bankCode]; !ok {
+		return nil, errUnknownBank()
+	}
+
+	entries, err := statement.Parse(format, r)
+	if err != nil {
+		return nil, domain.WrapError(domain.ErrValidation, "invalid_statement", "invalid bank statement", err)
+	}
+
+	day := time.Date(statementDate.Year(), statementDate.Month(), statementDate.Day(), 0, 0, 0, 0, time.Local)
+	payments, err := uc.paymentRepo.ListPayments(bankCode, day, day.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	reconciliation := &domain.Reconciliation{
+		BankCode:      bankCode,
+		StatementDate: day,
+		FileName:      fileName,
+		Format:        format,
+		Entries:       len(entries),
+	}
+
+	recorded := make(map[string]*domain.Payment, len(payments))
+	for i := range payments {
+		recorded[payments[i].Reference] = &payments[i]
+	}
+	for _, entry := range entries {
+		amount := entry.Amount
+		payment, ok := recorded[entry.Reference]
+		if !ok {
+			reconciliation.Mismatches = append(reconciliation.Mismatches, domain.ReconciliationMismatch{
+				Kind:            domain.MismatchMissingPayment,
+				Reference:       entry.Reference,
+				Number:          entry.Number,
+				StatementAmount: &amount,
+			})
+			continue
+		}
+		delete(recorded, entry.Reference)
+
+		if math.Abs(payment.Amount-entry.Amount) > amountTolerance {
+			reconciliation.Mismatches = append(reconciliation.Mismatches, domain.ReconciliationMismatch{
+				Kind:            domain.MismatchAmount,
+				Reference:       entry.Reference,
+				Number:          entry.Number,
+				StatementAmount: &amount,
+				RecordedAmount:  &payment.Amount,
+				PaymentID:       &payment.ID,
+			})
+			continue
+		}
+		reconciliation.Matched++
+	}
+	for i := range payments {
+		payment := &payments[i]
+		if _, ok := recorded[payment.Reference]; !ok {
+			continue
+		}
+		reconciliation.Mismatches = append(reconciliation.Mismatches, domain.ReconciliationMismatch{
+			Kind:           domain.MismatchMissingStatement,
+			Reference:      payment.Reference,
+			RecordedAmount: &payment.Amount,
+			PaymentID:      &payment.ID,
+		})
+	}
+
+	reconciliation.Status = domain.ReconciliationMatched
+	if len(reconciliation.Mismatches) > 0 {
+		reconciliation.Status = domain.ReconciliationMismatched
+	}
+	if err := uc.paymentRepo.CreateReconciliation(reconciliation); err != nil {
+		return nil, err
+	}
+	return reconciliation, nil
+}
+
+// ReconcileDaily implements PaymentUseCase.ReconcileDaily, reconciling the
+// statements of the previous day that were delivered and not reconciled yet
+func (uc *paymentUseCase) ReconcileDaily(now time.Time) (int, error) {
+	statementDate := time.Date(now.Year(), now.Month(), now.Day()-1, 0, 0, 0, 0, time.Local)
+
+	reconciled := 0
+	var failures []error
+	for bankCode := range uc.banks {
+		_, err := uc.paymentRepo.FindReconciliation(bankCode, statementDate)
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, domain.ErrNotFound) {
+			return reconciled, err
+		}
+
+		path, format, ok := uc.findStatement(bankCode, statementDate)
+		if !ok {
+			continue
+		}
+		if err := uc.reconcileFile(bankCode, statementDate, format, path); err != nil {
+			failures = append(failures, fmt.Errorf("%s: %w", path, err))
+			continue
+		}
+		reconciled++
+	}
+	return reconciled, errors.Join(failures...)
+}
+
+func (uc *paymentUseCase) reconcileFile(bankCode string, statementDate time.Time, format domain.StatementFormat, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = uc.Reconcile(bankCode, statementDate, format, filepath.Base(path), file)
+	return err
+}
+
+// findStatement looks up the statement file of a bank for a date
+func (uc *paymentUseCase) findStatement(bankCode string, statementDate time.Time) (string, domain.StatementFormat, bool) {
+	for _, format := range []domain.StatementFormat{domain.StatementCSV, domain.StatementMT940} {
+		path := filepath.Join(uc.statementDir, bankCode, statementDate.Format("20060102")+"."+string(format))
+		if _, err := os.Stat(path); err == nil {
+			return path, format, true
+		}
+	}
+	return "", "", false
+}
+
+// GetReconciliation implements PaymentUseCase.GetReconciliation
+func (uc *paymentUseCase) GetReconciliation(id uint) (*domain.Reconciliation, error) {
+	return uc.paymentRepo.GetReconciliation(id)
+}
+
+// ListReconciliations implements PaymentUseCase.ListReconciliations
+func (uc *paymentUseCase) ListReconciliations(bankCode string, offset, limit int) ([]domain.Reconciliation, error) {
+	return uc.paymentRepo.ListReconciliations(bankCode, offset, limit)
+}
+
+func errUnknownBank() error {
+	return domain.NewError(domain.ErrNotFound, "bank_not_found", "bank does not offer virtual accounts")
+}
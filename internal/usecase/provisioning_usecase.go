@@ -0,0 +1,197 @@
+package usecase
+
+import (
+	"errors"
+	"time"
+	"xyz-multifinance/internal/domain"
+)
+
+// provisioningBatchSize bounds the contracts classified per query
+const provisioningBatchSize = 500
+
+// reportPeriodLayout formats the month of a provision report
+const reportPeriodLayout = "2006-01"
+
+type provisioningUseCase struct {
+	provisioningRepo domain.ProvisioningRepository
+	transactionRepo  domain.TransactionRepository
+	policy           domain.ProvisioningPolicy
+}
+
+// NewProvisioningUseCase creates a new instance of ProvisioningUseCase
+func NewProvisioningUseCase(
+	provisioningRepo domain.ProvisioningRepository,
+	transactionRepo domain.TransactionRepository,
+	policy domain.ProvisioningPolicy,
+) domain.ProvisioningUseCase {
+	return &provisioningUseCase{
+		provisioningRepo: provisioningRepo,
+		transactionRepo:  transactionRepo,
+		policy:           policy,
+	}
+}
+
+// Classify implements ProvisioningUseCase.Classify
+func (uc *provisioningUseCase) Classify(transactionID uint) (*domain.Classification, error) {
+	tx, err := uc.transactionRepo.GetByID(transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if tx.Status != domain.StatusApproved {
+		return nil, domain.NewError(domain.ErrConflict, "transaction_not_approved", "only approved transactions are classified")
+	}
+
+	now := time.Now()
+	classification := classify(uc.policy, contractExposure(tx, now), now)
+	return &classification, nil
+}
+
+// Portfolio implements ProvisioningUseCase.Portfolio. Write-offs and
+// recoveries are those of the current month.
+func (uc *provisioningUseCase) Portfolio(now time.Time) (*domain.ProvisionReport, error) {
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	return uc.report(start, now)
+}
+
+// GenerateMonthly implements ProvisioningUseCase.GenerateMonthly
+func (uc *provisioningUseCase) GenerateMonthly(now time.Time) (*domain.ProvisionReport, error) {
+	end := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	start := end.AddDate(0, -1, 0)
+
+	_, err := uc.provisioningRepo.GetReport(start.Format(reportPeriodLayout))
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+
+	report, err := uc.report(start, end)
+	if err != nil {
+		return nil, err
+	}
+	report.CreatedAt = now
+	if err := uc.provisioningRepo.CreateReport(report); err != nil {
+		if errors.Is(err, domain.ErrConflict) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return report, nil
+}
+
+// GetReport implements ProvisioningUseCase.GetReport
+func (uc *provisioningUseCase) GetReport(period string) (*domain.ProvisionReport, error) {
+	if _, err := time.Parse(reportPeriodLayout, period); err != nil {
+		return nil, domain.NewError(domain.ErrValidation, "invalid_period", "period must be formatted YYYY-MM")
+	}
+	return uc.provisioningRepo.GetReport(period)
+}
+
+// ListReports implements ProvisioningUseCase.ListReports
+func (uc *provisioningUseCase) ListReports(offset, limit int) ([]domain.ProvisionReport, error) {
+	return uc.provisioningRepo.ListReports(offset, limit)
+}
+
+// report classifies the portfolio as of asOf, and totals the write-offs and
+// recoveries within [from, asOf)
+func (uc *provisioningUseCase) report(from, asOf time.Time) (*domain.ProvisionReport, error) {
+	report := &domain.ProvisionReport{
+		Period: from.Format(reportPeriodLayout),
+		AsOf:   asOf,
+		Grades: make([]domain.GradeProvision, len(uc.policy.Grades)),
+	}
+	for i, grade := range uc.policy.Grades {
+		report.Grades[i] = domain.GradeProvision{
+			Collectibility: grade.Collectibility,
+			Grade:          grade.Collectibility.Name(),
+			ProvisionRate:  grade.ProvisionRate,
+		}
+	}
+
+	var after uint
+	for {
+		exposures, err := uc.provisioningRepo.ListExposures(asOf, after, provisioningBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, exposure := range exposures {
+			after = exposure.TransactionID
+			classification := classify(uc.policy, exposure, asOf)
+			grade := &report.Grades[classification.Collectibility-1]
+			grade.Contracts++
+			grade.Outstanding += classification.Outstanding
+			grade.Provision += classification.Provision
+		}
+		if len(exposures) < provisioningBatchSize {
+			break
+		}
+	}
+
+	for i := range report.Grades {
+		grade := &report.Grades[i]
+		grade.Outstanding = roundCents(grade.Outstanding)
+		grade.Provision = roundCents(grade.Provision)
+		report.Contracts += grade.Contracts
+		report.Outstanding += grade.Outstanding
+		report.Provision += grade.Provision
+	}
+	report.Outstanding = roundCents(report.Outstanding)
+	report.Provision = roundCents(report.Provision)
+
+	count, amount, recovered, err := uc.provisioningRepo.WriteOffTotals(from, asOf)
+	if err != nil {
+		return nil, err
+	}
+	report.WrittenOffCount = count
+	report.WrittenOffAmount = roundCents(amount)
+	report.RecoveredAmount = roundCents(recovered)
+	return report, nil
+}
+
+// contractExposure returns the outstanding balance of tx as of asOf
+func contractExposure(tx *domain.Transaction, asOf time.Time) domain.Exposure {
+	exposure := domain.Exposure{
+		TransactionID:  tx.ID,
+		ContractNumber: tx.ContractNumber,
+		CustomerID:     tx.CustomerID,
+	}
+	for _, installment := range tx.Installments {
+		if !installment.IsOutstanding() {
+			continue
+		}
+		exposure.Outstanding += installment.Amount
+		if installment.DueDate.Before(asOf) && (exposure.OldestOverdue == nil || installment.DueDate.Before(*exposure.OldestOverdue)) {
+			dueDate := installment.DueDate
+			exposure.OldestOverdue = &dueDate
+		}
+	}
+	exposure.Outstanding = roundCents(exposure.Outstanding)
+	return exposure
+}
+
+// classify grades an exposure by the days past due of its oldest overdue
+// installment as of asOf. An installment is past due from the day after
+// its due date.
+func classify(policy domain.ProvisioningPolicy, exposure domain.Exposure, asOf time.Time) domain.Classification {
+	dpd := 0
+	if exposure.OldestOverdue != nil {
+		dpd = int(asOf.Sub(*exposure.OldestOverdue).Hours() / 24)
+		if dpd < 1 {
+			dpd = 1
+		}
+	}
+
+	grade := policy.Classify(dpd)
+	return domain.Classification{
+		TransactionID:  exposure.TransactionID,
+		ContractNumber: exposure.ContractNumber,
+		DPD:            dpd,
+		Collectibility: grade.Collectibility,
+		Grade:          grade.Collectibility.Name(),
+		Outstanding:    exposure.Outstanding,
+		ProvisionRate:  grade.ProvisionRate,
+		Provision:      roundCents(exposure.Outstanding * grade.ProvisionRate),
+		AsOf:           asOf,
+	}
+}
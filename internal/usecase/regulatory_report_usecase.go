@@ -0,0 +1,341 @@
+package usecase
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+	"xyz-multifinance/internal/domain"
+	"xyz-multifinance/internal/pkg/slik"
+)
+
+// regulatoryBatchSize bounds the contracts reported per query
+const regulatoryBatchSize = 500
+
+// Audit log actions of regulatory reports
+const (
+	auditReportGenerated = "regulatory_report.generated"
+	auditReportSubmitted = "regulatory_report.submitted"
+)
+
+type regulatoryReportUseCase struct {
+	reportRepo   domain.RegulatoryReportRepository
+	policy       domain.ProvisioningPolicy
+	reporterCode string
+}
+
+// NewRegulatoryReportUseCase creates a new instance of
+// RegulatoryReportUseCase. Facilities are graded by policy, and files are
+// issued under the reporter code OJK assigned to the company.
+func NewRegulatoryReportUseCase(
+	reportRepo domain.RegulatoryReportRepository,
+	policy domain.ProvisioningPolicy,
+	reporterCode string,
+) domain.RegulatoryReportUseCase {
+	return &regulatoryReportUseCase{
+		reportRepo:   reportRepo,
+		policy:       policy,
+		reporterCode: reporterCode,
+	}
+}
+
+// Validate implements RegulatoryReportUseCase.Validate
+func (uc *regulatoryReportUseCase) Validate(period string, segment domain.ReportSegment) ([]domain.ReportIssue, error) {
+	from, to, err := reportPeriod(period, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := validateSegment(segment); err != nil {
+		return nil, err
+	}
+
+	debtors, facilities, err := uc.records(from, to)
+	if err != nil {
+		return nil, err
+	}
+	return segmentIssues(segment, debtors, facilities), nil
+}
+
+// Generate implements RegulatoryReportUseCase.Generate. A period may be
+// generated again, e.g. after correcting customer data.
+func (uc *regulatoryReportUseCase) Generate(period string, segment domain.ReportSegment, format domain.ReportFormat, actor domain.Actor) (*domain.RegulatoryReport, error) {
+	now := time.Now()
+	from, to, err := reportPeriod(period, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateSegment(segment); err != nil {
+		return nil, err
+	}
+	if format != domain.ReportFixedWidth && format != domain.ReportCSV && format != domain.ReportXML {
+		return nil, domain.NewError(domain.ErrValidation, "invalid_format", "format must be fixed_width, csv or xml")
+	}
+
+	debtors, facilities, err := uc.records(from, to)
+	if err != nil {
+		return nil, err
+	}
+	if issues := segmentIssues(segment, debtors, facilities); len(issues) > 0 {
+		return nil, domain.NewError(domain.ErrValidation, "report_invalid",
+			fmt.Sprintf("%d mandatory field issues, the first: %s", len(issues), issues[0]))
+	}
+
+	header := slik.Header{ReporterCode: uc.reporterCode, Period: period, Segment: segment}
+	var content bytes.Buffer
+	records := len(facilities)
+	if segment == domain.SegmentDebtor {
+		records = len(debtors)
+		err = slik.EncodeDebtors(&content, format, header, debtors)
+	} else {
+		err = slik.EncodeFacilities(&content, format, header, facilities)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode report: %w", err)
+	}
+
+	sum := sha256.Sum256(content.Bytes())
+	report := &domain.RegulatoryReport{
+		Period:      period,
+		Segment:     segment,
+		Format:      format,
+		FileName:    slik.FileName(header, format),
+		Records:     records,
+		Checksum:    hex.EncodeToString(sum[:]),
+		Content:     content.Bytes(),
+		Status:      domain.ReportGenerated,
+		GeneratedBy: actor.ID,
+		CreatedAt:   now,
+	}
+	audit, err := newAuditLog(actor, auditReportGenerated, "regulatory_report", 0, map[string]interface{}{
+		"period":   period,
+		"segment":  segment,
+		"format":   format,
+		"records":  records,
+		"checksum": report.Checksum,
+	}, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.reportRepo.Create(report, audit); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// GetByID implements RegulatoryReportUseCase.GetByID
+func (uc *regulatoryReportUseCase) GetByID(id uint) (*domain.RegulatoryReport, error) {
+	return uc.reportRepo.GetByID(id)
+}
+
+// List implements RegulatoryReportUseCase.List
+func (uc *regulatoryReportUseCase) List(filter domain.RegulatoryReportFilter, offset, limit int) ([]domain.RegulatoryReport, error) {
+	return uc.reportRepo.List(filter, offset, limit)
+}
+
+// Submit implements RegulatoryReportUseCase.Submit
+func (uc *regulatoryReportUseCase) Submit(id uint, reference, checksum string, actor domain.Actor) (*domain.RegulatoryReport, error) {
+	report, err := uc.reportRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if report.Status != domain.ReportGenerated {
+		return nil, domain.NewError(domain.ErrConflict, "report_submitted", "report is submitted already")
+	}
+	if checksum != "" && !strings.EqualFold(checksum, report.Checksum) {
+		return nil, domain.NewError(domain.ErrValidation, "checksum_mismatch", "checksum does not match the report file")
+	}
+
+	now := time.Now()
+	report.Status = domain.ReportSubmitted
+	report.SubmittedBy = &actor.ID
+	report.SubmittedAt = &now
+	report.SubmissionReference = reference
+
+	audit, err := newAuditLog(actor, auditReportSubmitted, "regulatory_report", report.ID, map[string]interface{}{
+		"period":    report.Period,
+		"segment":   report.Segment,
+		"reference": reference,
+		"checksum":  report.Checksum,
+	}, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.reportRepo.Submit(report, audit); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// records builds the facilities reported for [from, to) and the debtors they
+// belong to, in the order of the contracts
+func (uc *regulatoryReportUseCase) records(from, to time.Time) ([]domain.DebtorRecord, []domain.FacilityRecord, error) {
+	var debtors []domain.DebtorRecord
+	var facilities []domain.FacilityRecord
+	reported := make(map[uint]bool)
+
+	var after uint
+	for {
+		transactions, err := uc.reportRepo.ListReportable(from, to, after, regulatoryBatchSize)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var writtenOff []uint
+		for _, tx := range transactions {
+			if tx.Status == domain.StatusWrittenOff {
+				writtenOff = append(writtenOff, tx.ID)
+			}
+		}
+		writeOffs, err := uc.reportRepo.ListWriteOffs(writtenOff)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for i := range transactions {
+			tx := &transactions[i]
+			after = tx.ID
+
+			var writeOff *domain.WriteOff
+			if w, ok := writeOffs[tx.ID]; ok {
+				writeOff = &w
+			}
+			facilities = append(facilities, uc.facilityRecord(tx, writeOff, to))
+
+			if !reported[tx.CustomerID] {
+				reported[tx.CustomerID] = true
+				debtors = append(debtors, debtorRecord(tx))
+			}
+		}
+
+		if len(transactions) < regulatoryBatchSize {
+			return debtors, facilities, nil
+		}
+	}
+}
+
+// facilityRecord reports tx as it stood at asOf. Payments and a write-off
+// made since are undone; restructurings are read as they are now.
+func (uc *regulatoryReportUseCase) facilityRecord(tx *domain.Transaction, writeOff *domain.WriteOff, asOf time.Time) domain.FacilityRecord {
+	record := domain.FacilityRecord{
+		AccountNumber: tx.ContractNumber,
+		CIF:           customerCIF(tx.CustomerID),
+		StartDate:     tx.CreatedAt,
+		Plafond:       roundCents(tx.OTRAmount + tx.AdminFee),
+		Condition:     domain.FacilityActive,
+	}
+	if writeOff != nil && writeOff.WrittenOffAt.Before(asOf) {
+		writtenOffAt := writeOff.WrittenOffAt
+		record.Condition = domain.FacilityWrittenOff
+		record.ConditionDate = &writtenOffAt
+		record.Collectibility = writeOff.Collectibility
+		record.DPD = writeOff.DPD
+		record.WrittenOffBalance = roundCents(writeOff.Balance())
+		writeOff = nil
+	}
+
+	// Installments as of asOf, for the balances and the grade
+	standing := domain.Transaction{ID: tx.ID, ContractNumber: tx.ContractNumber, CustomerID: tx.CustomerID}
+	var lastPaid *time.Time
+	for _, installment := range tx.Installments {
+		if installment.Status == "superseded" {
+			continue
+		}
+		if installment.RestructuringID != nil {
+			record.Restructured = true
+		}
+		if installment.DueDate.After(record.MaturityDate) {
+			record.MaturityDate = installment.DueDate
+		}
+
+		switch {
+		case installment.Status == "paid" && installment.PaidAt != nil && !installment.PaidAt.Before(asOf):
+			installment.Status = "unpaid"
+		case installment.Status == domain.InstallmentWrittenOff && writeOff != nil:
+			installment.Status = "unpaid"
+		case installment.Status == "paid" && installment.PaidAt != nil && (lastPaid == nil || installment.PaidAt.After(*lastPaid)):
+			lastPaid = installment.PaidAt
+		}
+		standing.Installments = append(standing.Installments, installment)
+
+		if !installment.IsOutstanding() {
+			continue
+		}
+		record.Outstanding += installment.Principal
+		if installment.DueDate.Before(asOf) {
+			record.ArrearsPrincipal += installment.Principal
+			record.ArrearsInterest += installment.Interest
+		}
+	}
+	record.Outstanding = roundCents(record.Outstanding)
+	record.ArrearsPrincipal = roundCents(record.ArrearsPrincipal)
+	record.ArrearsInterest = roundCents(record.ArrearsInterest)
+
+	if record.Condition == domain.FacilityWrittenOff {
+		return record
+	}
+	exposure := contractExposure(&standing, asOf)
+	classification := classify(uc.policy, exposure, asOf)
+	record.Collectibility = classification.Collectibility
+	record.DPD = classification.DPD
+	if exposure.Outstanding == 0 && lastPaid != nil {
+		record.Condition = domain.FacilityPaidOff
+		record.ConditionDate = lastPaid
+	}
+	return record
+}
+
+func debtorRecord(tx *domain.Transaction) domain.DebtorRecord {
+	record := domain.DebtorRecord{CIF: customerCIF(tx.CustomerID)}
+	if customer := tx.Customer; customer != nil {
+		record.NIK = customer.NIK
+		record.FullName = customer.FullName
+		record.LegalName = customer.LegalName
+		record.PlaceOfBirth = customer.PlaceOfBirth
+		record.DateOfBirth = customer.DateOfBirth
+		record.Phone = customer.Phone
+		record.Region = customer.Region
+	}
+	return record
+}
+
+// customerCIF returns the reference customers are reported under
+func customerCIF(customerID uint) string {
+	return fmt.Sprintf("%010d", customerID)
+}
+
+func segmentIssues(segment domain.ReportSegment, debtors []domain.DebtorRecord, facilities []domain.FacilityRecord) []domain.ReportIssue {
+	issues := []domain.ReportIssue{}
+	if segment == domain.SegmentDebtor {
+		for _, debtor := range debtors {
+			issues = append(issues, debtor.Validate()...)
+		}
+		return issues
+	}
+	for _, facility := range facilities {
+		issues = append(issues, facility.Validate()...)
+	}
+	return issues
+}
+
+func validateSegment(segment domain.ReportSegment) error {
+	if segment != domain.SegmentDebtor && segment != domain.SegmentFacility {
+		return domain.NewError(domain.ErrValidation, "invalid_segment", "segment must be D01 or F01")
+	}
+	return nil
+}
+
+// reportPeriod returns the bounds [from, to) of a YYYY-MM period, which must
+// have ended by now
+func reportPeriod(period string, now time.Time) (time.Time, time.Time, error) {
+	from, err := time.ParseInLocation(reportPeriodLayout, period, now.Location())
+	if err != nil {
+		return time.Time{}, time.Time{}, domain.NewError(domain.ErrValidation, "invalid_period", "period must be formatted YYYY-MM")
+	}
+	to := from.AddDate(0, 1, 0)
+	if to.After(now) {
+		return time.Time{}, time.Time{}, domain.NewError(domain.ErrValidation, "period_not_ended", "only ended periods are reported")
+	}
+	return from, to, nil
+}
@@ -0,0 +1,310 @@
+package usecase
+
+import (
+	"math"
+	"sort"
+	"time"
+	"xyz-multifinance/internal/domain"
+)
+
+// Audit log actions of restructurings
+const (
+	auditRestructuringRequested = "restructuring.requested"
+	auditRestructuringApproved  = "restructuring.approved"
+	auditRestructuringRejected  = "restructuring.rejected"
+)
+
+type restructuringUseCase struct {
+	restructuringRepo domain.RestructuringRepository
+	transactionRepo   domain.TransactionRepository
+	policy            domain.RestructuringPolicy
+}
+
+// NewRestructuringUseCase creates a new instance of RestructuringUseCase.
+// Requests beyond policy are refused.
+func NewRestructuringUseCase(
+	restructuringRepo domain.RestructuringRepository,
+	transactionRepo domain.TransactionRepository,
+	policy domain.RestructuringPolicy,
+) domain.RestructuringUseCase {
+	return &restructuringUseCase{
+		restructuringRepo: restructuringRepo,
+		transactionRepo:   transactionRepo,
+		policy:            policy,
+	}
+}
+
+// Request implements RestructuringUseCase.Request. The new schedule is
+// computed for review; it is recomputed when approved.
+func (uc *restructuringUseCase) Request(request *domain.RestructuringRequest) (*domain.Restructuring, error) {
+	if request.InterestRate < 0 || request.InterestRate > uc.policy.MaxInterestRate {
+		return nil, domain.NewError(domain.ErrValidation, "invalid_interest_rate", "interest rate is outside the allowed range")
+	}
+
+	tx, err := uc.transactionRepo.GetByID(request.TransactionID)
+	if err != nil {
+		return nil, err
+	}
+	if tx.Status != domain.StatusApproved {
+		return nil, domain.NewError(domain.ErrConflict, "transaction_not_approved", "only approved transactions can be restructured")
+	}
+	outstanding := outstandingInstallments(tx.Installments)
+	if len(outstanding) == 0 {
+		return nil, domain.NewError(domain.ErrConflict, "nothing_outstanding", "transaction has no outstanding installments")
+	}
+
+	newTenor := request.NewTenor
+	if newTenor == 0 {
+		newTenor = len(outstanding)
+	}
+	switch request.Type {
+	case domain.RestructuringTenorExtension:
+		if newTenor <= len(outstanding) {
+			return nil, domain.NewError(domain.ErrValidation, "tenor_not_extended", "new tenor must exceed the number of outstanding installments")
+		}
+	case domain.RestructuringPaymentHoliday:
+		if request.HolidayMonths < 1 {
+			return nil, domain.NewError(domain.ErrValidation, "invalid_holiday_months", "a payment holiday defers at least one month")
+		}
+	default:
+		return nil, domain.NewError(domain.ErrValidation, "invalid_restructuring_type", "type must be tenor_extension or payment_holiday")
+	}
+	if newTenor > uc.policy.MaxTenor {
+		return nil, domain.NewError(domain.ErrValidation, "invalid_tenor", "new tenor exceeds the maximum tenor")
+	}
+	if request.HolidayMonths < 0 || request.HolidayMonths > uc.policy.MaxHolidayMonths {
+		return nil, domain.NewError(domain.ErrValidation, "invalid_holiday_months", "holiday months exceed the maximum")
+	}
+
+	now := time.Now()
+	restructuring := &domain.Restructuring{
+		TransactionID: tx.ID,
+		Type:          request.Type,
+		Status:        domain.RestructuringPending,
+		Reason:        request.Reason,
+		InterestRate:  request.InterestRate,
+		NewTenor:      newTenor,
+		HolidayMonths: request.HolidayMonths,
+		RequestedBy:   request.Actor.ID,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	planRestructuring(restructuring, outstanding, now)
+
+	audit, err := newAuditLog(request.Actor, auditRestructuringRequested, "restructuring", 0, map[string]interface{}{
+		"transaction_id":     tx.ID,
+		"type":               restructuring.Type,
+		"reason":             restructuring.Reason,
+		"outstanding_amount": restructuring.OutstandingAmount,
+		"new_tenor":          restructuring.NewTenor,
+		"holiday_months":     restructuring.HolidayMonths,
+		"interest_rate":      restructuring.InterestRate,
+	}, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.restructuringRepo.Create(restructuring, audit); err != nil {
+		return nil, err
+	}
+	return restructuring, nil
+}
+
+// GetByID implements RestructuringUseCase.GetByID
+func (uc *restructuringUseCase) GetByID(id uint) (*domain.Restructuring, error) {
+	return uc.restructuringRepo.GetByID(id)
+}
+
+// ListByTransaction implements RestructuringUseCase.ListByTransaction
+func (uc *restructuringUseCase) ListByTransaction(transactionID uint) ([]domain.Restructuring, error) {
+	if _, err := uc.transactionRepo.GetByID(transactionID); err != nil {
+		return nil, err
+	}
+	return uc.restructuringRepo.ListByTransaction(transactionID)
+}
+
+// Approve implements RestructuringUseCase.Approve. The approver must not be
+// the requester. A restructuring whose outstanding balance changed since it
+// was requested, e.g. by a payment, must be requested again.
+func (uc *restructuringUseCase) Approve(id uint, note string, actor domain.Actor) (*domain.Restructuring, error) {
+	restructuring, err := uc.pending(id, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := uc.transactionRepo.GetByID(restructuring.TransactionID)
+	if err != nil {
+		return nil, err
+	}
+	outstanding := outstandingInstallments(tx.Installments)
+	requested := restructuring.OutstandingAmount
+
+	now := time.Now()
+	schedule := planRestructuring(restructuring, outstanding, now)
+	if len(outstanding) == 0 || math.Abs(restructuring.OutstandingAmount-requested) > amountTolerance {
+		return nil, domain.NewError(domain.ErrConflict, "restructuring_stale", "outstanding balance changed since the restructuring was requested")
+	}
+
+	number := 0
+	for _, installment := range tx.Installments {
+		if installment.InstallmentNumber > number {
+			number = installment.InstallmentNumber
+		}
+	}
+	for i := range schedule {
+		schedule[i].TransactionID = tx.ID
+		schedule[i].InstallmentNumber = number + i + 1
+	}
+
+	restructuring.Status = domain.RestructuringApproved
+	restructuring.ReviewedBy = &actor.ID
+	restructuring.ReviewNote = note
+	restructuring.ReviewedAt = &now
+	restructuring.Superseded = outstanding
+	restructuring.Schedule = schedule
+
+	event, err := newOutboxEvent(domain.EventContractRestructured, tx.PartnerID, domain.ContractRestructured{
+		TransactionID:     tx.ID,
+		ContractNumber:    tx.ContractNumber,
+		Status:            tx.Status,
+		RestructuringID:   restructuring.ID,
+		Type:              restructuring.Type,
+		NewTenor:          restructuring.NewTenor,
+		InstallmentAmount: restructuring.InstallmentAmount,
+		FirstDueDate:      restructuring.FirstDueDate,
+		AddedAmount:       restructuring.AddedAmount(),
+	}, now)
+	if err != nil {
+		return nil, err
+	}
+	restructuring.Events = []domain.OutboxEvent{event}
+
+	superseded := make([]uint, len(outstanding))
+	for i, installment := range outstanding {
+		superseded[i] = installment.ID
+	}
+	audit, err := newAuditLog(actor, auditRestructuringApproved, "restructuring", restructuring.ID, map[string]interface{}{
+		"transaction_id":     tx.ID,
+		"note":               note,
+		"superseded":         superseded,
+		"installment_amount": restructuring.InstallmentAmount,
+		"first_due_date":     restructuring.FirstDueDate,
+		"total_amount":       restructuring.TotalAmount,
+		"added_amount":       restructuring.AddedAmount(),
+	}, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.restructuringRepo.Approve(restructuring, audit); err != nil {
+		return nil, err
+	}
+	return restructuring, nil
+}
+
+// Reject implements RestructuringUseCase.Reject
+func (uc *restructuringUseCase) Reject(id uint, note string, actor domain.Actor) (*domain.Restructuring, error) {
+	restructuring, err := uc.pending(id, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	restructuring.Status = domain.RestructuringRejected
+	restructuring.ReviewedBy = &actor.ID
+	restructuring.ReviewNote = note
+	restructuring.ReviewedAt = &now
+
+	audit, err := newAuditLog(actor, auditRestructuringRejected, "restructuring", restructuring.ID, map[string]interface{}{
+		"transaction_id": restructuring.TransactionID,
+		"note":           note,
+	}, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.restructuringRepo.Reject(restructuring, audit); err != nil {
+		return nil, err
+	}
+	return restructuring, nil
+}
+
+// pending returns a restructuring awaiting review by actor
+func (uc *restructuringUseCase) pending(id uint, actor domain.Actor) (*domain.Restructuring, error) {
+	restructuring, err := uc.restructuringRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if restructuring.Status != domain.RestructuringPending {
+		return nil, domain.NewError(domain.ErrConflict, "restructuring_not_pending", "restructuring is no longer pending")
+	}
+	if restructuring.RequestedBy == actor.ID {
+		return nil, domain.NewError(domain.ErrForbidden, "self_approval", "a restructuring must be reviewed by someone other than its requester")
+	}
+	return restructuring, nil
+}
+
+// outstandingInstallments returns the installments still to be paid, in
+// due date order
+func outstandingInstallments(installments []domain.Installment) []domain.Installment {
+	var outstanding []domain.Installment
+	for _, installment := range installments {
+		if installment.IsOutstanding() {
+			outstanding = append(outstanding, installment)
+		}
+	}
+	sort.Slice(outstanding, func(i, j int) bool {
+		return outstanding[i].DueDate.Before(outstanding[j].DueDate)
+	})
+	return outstanding
+}
+
+// planRestructuring computes the new schedule replacing the outstanding
+// installments, with late fees, at a flat monthly rate. The first new
+// installment falls on the first monthly due date after now, deferred by
+// the holiday months; the last absorbs rounding. The interest the
+// outstanding installments had not accrued is carried into the interest of
+// the new schedule.
+func planRestructuring(restructuring *domain.Restructuring, outstanding []domain.Installment, now time.Time) []domain.Installment {
+	balance, unaccrued := 0.0, 0.0
+	for _, installment := range outstanding {
+		balance += installment.Amount + installment.LateFee
+		unaccrued += installment.Interest - installment.AccruedInterest
+	}
+	restructuring.OutstandingAmount = roundCents(balance)
+	if len(outstanding) == 0 {
+		return nil
+	}
+
+	n := restructuring.NewTenor
+	total := roundCents(balance * (1 + restructuring.InterestRate*float64(n)))
+	amount := roundCents(total / float64(n))
+
+	start := outstanding[0].DueDate
+	for !start.After(now) {
+		start = start.AddDate(0, 1, 0)
+	}
+	start = start.AddDate(0, restructuring.HolidayMonths, 0)
+
+	restructuring.TotalAmount = total
+	restructuring.InstallmentAmount = amount
+	restructuring.FirstDueDate = start
+
+	schedule := make([]domain.Installment, n)
+	for i := range schedule {
+		schedule[i] = domain.Installment{
+			DueDate:   start.AddDate(0, i, 0),
+			Amount:    amount,
+			Status:    "unpaid",
+			Version:   1,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+	}
+	schedule[n-1].Amount = roundCents(total - amount*float64(n-1))
+	domain.SplitInstallments(roundCents(balance-unaccrued), schedule)
+	return schedule
+}
+
+// roundCents rounds an amount in rupiah to two decimals
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
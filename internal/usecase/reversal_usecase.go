@@ -0,0 +1,259 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+	"xyz-multifinance/internal/domain"
+	"xyz-multifinance/internal/pkg/redis"
+)
+
+// Audit log actions of reversals and refunds
+const (
+	auditInstallmentReversed = "installment.reversed"
+	auditRefundCreated       = "refund.created"
+	auditRefundCompleted     = "refund.completed"
+)
+
+type reversalUseCase struct {
+	reversalRepo    domain.ReversalRepository
+	transactionRepo domain.TransactionRepository
+	paymentRepo     domain.PaymentRepository
+	redisClient     redis.RedisClient
+	lateFees        domain.LateFeePolicy
+}
+
+// NewReversalUseCase creates a new instance of ReversalUseCase. Installments
+// overdue again after a reversal are charged the late fee of lateFees.
+func NewReversalUseCase(
+	reversalRepo domain.ReversalRepository,
+	transactionRepo domain.TransactionRepository,
+	paymentRepo domain.PaymentRepository,
+	redisClient redis.RedisClient,
+	lateFees domain.LateFeePolicy,
+) domain.ReversalUseCase {
+	return &reversalUseCase{
+		reversalRepo:    reversalRepo,
+		transactionRepo: transactionRepo,
+		paymentRepo:     paymentRepo,
+		redisClient:     redisClient,
+		lateFees:        lateFees,
+	}
+}
+
+// ReverseInstallment implements ReversalUseCase.ReverseInstallment. The
+// installment is restored to unpaid, or to overdue with its late fee when
+// past its due date. It takes the same distributed lock as a payment, so a
+// reversal and a payment of one installment never interleave.
+func (uc *reversalUseCase) ReverseInstallment(request *domain.ReverseInstallmentRequest) (*domain.PaymentReversal, error) {
+	switch request.Reason {
+	case domain.ReversalBounced:
+		if request.RefundAmount != 0 {
+			return nil, domain.NewError(domain.ErrValidation, "refund_not_allowed", "a bounced payment cannot be refunded")
+		}
+	case domain.ReversalMistaken:
+		if request.RefundAmount < 0 {
+			return nil, domain.NewError(domain.ErrValidation, "invalid_refund_amount", "refund amount must not be negative")
+		}
+	default:
+		return nil, domain.NewError(domain.ErrValidation, "invalid_reversal_reason", "reason must be bounced or mistaken")
+	}
+
+	ctx := context.Background()
+	lock, err := lockInstallment(ctx, uc.redisClient, uc.transactionRepo, request.InstallmentID)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Unlock(ctx)
+
+	installment, err := uc.transactionRepo.GetInstallmentByID(request.InstallmentID)
+	if err != nil {
+		return nil, err
+	}
+	if installment.Version != request.Version {
+		return nil, errVersionMismatch()
+	}
+	if installment.Status != "paid" {
+		return nil, domain.NewError(domain.ErrConflict, "installment_not_paid", "installment is not paid")
+	}
+	if request.RefundAmount > installment.Amount+amountTolerance {
+		return nil, domain.NewError(domain.ErrValidation, "refund_exceeds_payment", "refund amount exceeds the installment amount")
+	}
+
+	tx, err := uc.transactionRepo.GetByID(installment.TransactionID)
+	if err != nil {
+		return nil, err
+	}
+	paidOff := true
+	for _, other := range tx.Installments {
+		if other.ID != installment.ID && other.IsOutstanding() {
+			paidOff = false
+			break
+		}
+	}
+
+	now := time.Now()
+	reversal := &domain.PaymentReversal{
+		InstallmentID:  installment.ID,
+		TransactionID:  installment.TransactionID,
+		Reason:         request.Reason,
+		Note:           request.Note,
+		Amount:         installment.Amount,
+		PreviousPaidAt: installment.PaidAt,
+		OperatorID:     request.Actor.ID,
+		CreatedAt:      now,
+	}
+
+	installment.Status = "unpaid"
+	installment.LateFee = 0
+	if installment.DueDate.Before(now) {
+		installment.Status = "overdue"
+		installment.LateFee = uc.lateFees.Fee(installment.Amount, installment.DueDate, now)
+	}
+	installment.PaidAt = nil
+	installment.UpdatedAt = now
+	installment.FencingToken = lock.FencingToken()
+	reversal.RestoredStatus = installment.Status
+	reversal.LateFee = installment.LateFee
+
+	if request.RefundAmount > 0 {
+		reversal.Refund = &domain.Refund{
+			TransactionID: installment.TransactionID,
+			Amount:        request.RefundAmount,
+			Reason:        string(request.Reason),
+			Status:        domain.RefundPending,
+			OperatorID:    request.Actor.ID,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}
+	}
+
+	event, err := newInstallmentEvent(domain.EventInstallmentReversed, tx, installment, now)
+	if err != nil {
+		return nil, err
+	}
+	installment.Events = []domain.OutboxEvent{event}
+	if paidOff {
+		event, err := newContractEvent(domain.EventContractReopened, tx, now)
+		if err != nil {
+			return nil, err
+		}
+		installment.Events = append(installment.Events, event)
+	}
+
+	audit, err := newAuditLog(request.Actor, auditInstallmentReversed, "installment", installment.ID, map[string]interface{}{
+		"transaction_id":   installment.TransactionID,
+		"reason":           request.Reason,
+		"note":             request.Note,
+		"previous_paid_at": reversal.PreviousPaidAt,
+		"restored_status":  reversal.RestoredStatus,
+		"late_fee":         reversal.LateFee,
+		"refund_amount":    request.RefundAmount,
+		"version":          installment.Version + 1,
+	}, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.reversalRepo.ReverseInstallment(installment, reversal, audit); err != nil {
+		if errors.Is(err, domain.ErrConflict) {
+			return nil, errVersionMismatch()
+		}
+		return nil, err
+	}
+	return reversal, nil
+}
+
+// ListReversals implements ReversalUseCase.ListReversals
+func (uc *reversalUseCase) ListReversals(transactionID uint, offset, limit int) ([]domain.PaymentReversal, error) {
+	return uc.reversalRepo.ListReversals(transactionID, offset, limit)
+}
+
+// CreateRefund implements ReversalUseCase.CreateRefund. A refund of a
+// virtual account payment belongs to the payment's contract, and the refunds
+// of one payment never exceed its amount.
+func (uc *reversalUseCase) CreateRefund(refund *domain.Refund, actor domain.Actor) (*domain.Refund, error) {
+	if refund.Amount <= 0 {
+		return nil, domain.NewError(domain.ErrValidation, "invalid_refund_amount", "refund amount must be positive")
+	}
+
+	if refund.PaymentID != nil {
+		payment, err := uc.paymentRepo.GetPayment(*refund.PaymentID)
+		if err != nil {
+			return nil, err
+		}
+		if refund.TransactionID != 0 && refund.TransactionID != payment.TransactionID {
+			return nil, domain.NewError(domain.ErrValidation, "payment_transaction_mismatch", "payment belongs to another transaction")
+		}
+		refund.TransactionID = payment.TransactionID
+
+		refunded, err := uc.reversalRepo.SumRefunds(payment.ID)
+		if err != nil {
+			return nil, err
+		}
+		if refunded+refund.Amount > payment.Amount+amountTolerance {
+			return nil, domain.NewError(domain.ErrValidation, "refund_exceeds_payment", "refunds exceed the payment amount")
+		}
+	} else if _, err := uc.transactionRepo.GetByID(refund.TransactionID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	refund.ReversalID = nil
+	refund.Status = domain.RefundPending
+	refund.Reference = ""
+	refund.CompletedAt = nil
+	refund.OperatorID = actor.ID
+	refund.CreatedAt = now
+	refund.UpdatedAt = now
+
+	audit, err := newAuditLog(actor, auditRefundCreated, "refund", 0, map[string]interface{}{
+		"transaction_id": refund.TransactionID,
+		"payment_id":     refund.PaymentID,
+		"amount":         refund.Amount,
+		"reason":         refund.Reason,
+	}, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.reversalRepo.CreateRefund(refund, audit); err != nil {
+		return nil, err
+	}
+	return refund, nil
+}
+
+// CompleteRefund implements ReversalUseCase.CompleteRefund, recording the
+// bank transfer that returned the money
+func (uc *reversalUseCase) CompleteRefund(id uint, reference string, actor domain.Actor) (*domain.Refund, error) {
+	refund, err := uc.reversalRepo.GetRefund(id)
+	if err != nil {
+		return nil, err
+	}
+	if refund.Status != domain.RefundPending {
+		return nil, domain.NewError(domain.ErrConflict, "refund_not_pending", "refund is no longer pending")
+	}
+
+	now := time.Now()
+	refund.Status = domain.RefundCompleted
+	refund.Reference = reference
+	refund.CompletedAt = &now
+	refund.UpdatedAt = now
+
+	audit, err := newAuditLog(actor, auditRefundCompleted, "refund", refund.ID, map[string]interface{}{
+		"transaction_id": refund.TransactionID,
+		"amount":         refund.Amount,
+		"reference":      reference,
+	}, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.reversalRepo.CompleteRefund(refund, audit); err != nil {
+		return nil, err
+	}
+	return refund, nil
+}
+
+// ListRefunds implements ReversalUseCase.ListRefunds
+func (uc *reversalUseCase) ListRefunds(transactionID uint, offset, limit int) ([]domain.Refund, error) {
+	return uc.reversalRepo.ListRefunds(transactionID, offset, limit)
+}
@@ -0,0 +1,123 @@
+package usecase
+
+import (
+	"time"
+	"xyz-multifinance/internal/domain"
+	"xyz-multifinance/internal/pkg/scorecard"
+)
+
+type scoringUseCase struct {
+	scoreRepo    domain.CreditScoreRepository
+	customerRepo domain.CustomerRepository
+	scorecard    *scorecard.Scorecard
+}
+
+// NewScoringUseCase creates a new instance of ScoringUseCase scoring
+// customers with card
+func NewScoringUseCase(
+	scoreRepo domain.CreditScoreRepository,
+	customerRepo domain.CustomerRepository,
+	card *scorecard.Scorecard,
+) domain.ScoringUseCase {
+	return &scoringUseCase{
+		scoreRepo:    scoreRepo,
+		customerRepo: customerRepo,
+		scorecard:    card,
+	}
+}
+
+// Score implements ScoringUseCase.Score
+func (uc *scoringUseCase) Score(customerID uint) (*domain.CreditScore, error) {
+	customer, err := uc.customerRepo.GetByID(customerID)
+	if err != nil {
+		return nil, err
+	}
+	limits, err := uc.customerRepo.GetCreditLimits(customerID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	history, err := uc.scoreRepo.GetPaymentHistory(customerID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	attributes := scoringAttributes(customer, limits, history, now)
+	result := uc.scorecard.Score(attributes)
+
+	score := &domain.CreditScore{
+		CustomerID:       customerID,
+		Scorecard:        uc.scorecard.Name,
+		ScorecardVersion: uc.scorecard.Version,
+		Score:            result.Score,
+		Outcome:          domain.OutcomeApprove,
+		Attributes:       attributes,
+		Characteristics:  make([]domain.ScoreCharacteristic, len(result.Characteristics)),
+		Reasons:          make([]domain.ScoreReason, len(result.Reasons)),
+		CreatedAt:        now,
+	}
+	switch {
+	case result.Score < uc.scorecard.Cutoffs.Reject:
+		score.Outcome = domain.OutcomeReject
+	case result.Score < uc.scorecard.Cutoffs.Refer:
+		score.Outcome = domain.OutcomeRefer
+	}
+	for i, points := range result.Characteristics {
+		score.Characteristics[i] = domain.ScoreCharacteristic{
+			Attribute:  points.Attribute,
+			Value:      points.Value,
+			Points:     points.Points,
+			MaxPoints:  points.MaxPoints,
+			ReasonCode: points.ReasonCode,
+		}
+	}
+	for i, points := range result.Reasons {
+		score.Reasons[i] = domain.ScoreReason{
+			Code:        points.ReasonCode,
+			Description: points.Description,
+			PointsLost:  points.Lost(),
+		}
+	}
+
+	if err := uc.scoreRepo.Create(score); err != nil {
+		return nil, err
+	}
+	return score, nil
+}
+
+// ListHistory implements ScoringUseCase.ListHistory, newest first
+func (uc *scoringUseCase) ListHistory(customerID uint, offset, limit int) ([]domain.CreditScore, error) {
+	if _, err := uc.customerRepo.GetByID(customerID); err != nil {
+		return nil, err
+	}
+	return uc.scoreRepo.ListByCustomer(customerID, offset, limit)
+}
+
+// scoringAttributes derives the scoring attributes of a customer. The on-time
+// ratio is left unknown for customers who never paid an installment.
+func scoringAttributes(customer *domain.Customer, limits []domain.CreditLimit, history *domain.PaymentHistory, now time.Time) map[string]float64 {
+	attributes := map[string]float64{
+		domain.AttrAge:                 float64(ageAt(customer.DateOfBirth, now)),
+		domain.AttrSalary:              customer.Salary,
+		domain.AttrPaidInstallments:    float64(history.PaidInstallments),
+		domain.AttrMaxDaysLate:         float64(history.MaxDaysLate),
+		domain.AttrOverdueInstallments: float64(history.OverdueInstallments),
+	}
+
+	total, used, tenorsUsed := 0.0, 0.0, 0
+	for _, limit := range limits {
+		total += limit.Amount
+		used += limit.UsedAmount
+		if limit.UsedAmount > 0 {
+			tenorsUsed++
+		}
+	}
+	attributes[domain.AttrTenorsUsed] = float64(tenorsUsed)
+	if total > 0 {
+		attributes[domain.AttrLimitUtilisation] = used / total
+	}
+	if history.PaidInstallments > 0 {
+		attributes[domain.AttrOnTimeRatio] = float64(history.PaidOnTime) / float64(history.PaidInstallments)
+	}
+	return attributes
+}
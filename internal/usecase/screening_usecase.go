@@ -0,0 +1,142 @@
+package usecase
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"xyz-multifinance/internal/domain"
+)
+
+type screeningUseCase struct {
+	screeningRepo domain.ScreeningRepository
+	blacklistRepo domain.BlacklistRepository
+	customerRepo  domain.CustomerRepository
+	policy        domain.ScreeningPolicy
+}
+
+// NewScreeningUseCase creates a new instance of ScreeningUseCase
+func NewScreeningUseCase(
+	screeningRepo domain.ScreeningRepository,
+	blacklistRepo domain.BlacklistRepository,
+	customerRepo domain.CustomerRepository,
+	policy domain.ScreeningPolicy,
+) domain.ScreeningUseCase {
+	return &screeningUseCase{
+		screeningRepo: screeningRepo,
+		blacklistRepo: blacklistRepo,
+		customerRepo:  customerRepo,
+		policy:        policy,
+	}
+}
+
+// ScreenCustomer implements ScreeningUseCase.ScreenCustomer
+func (uc *screeningUseCase) ScreenCustomer(customer *domain.Customer) (*domain.ScreeningResult, error) {
+	result := &domain.ScreeningResult{Flag: domain.RiskClear}
+	if err := uc.screenBlacklist(result, map[domain.BlacklistType]string{
+		domain.BlacklistNIK:    customer.NIK,
+		domain.BlacklistPhone:  customer.Phone,
+		domain.BlacklistDevice: customer.DeviceID,
+	}); err != nil {
+		return nil, err
+	}
+
+	if uc.policy.DuplicateIdentity.Severity() > 0 {
+		duplicates, err := uc.screeningRepo.FindDuplicateIdentities(customer)
+		if err != nil {
+			return nil, err
+		}
+		if len(duplicates) > 0 {
+			ids := make([]string, len(duplicates))
+			for i, duplicate := range duplicates {
+				ids[i] = strconv.FormatUint(uint64(duplicate.ID), 10)
+			}
+			result.Add(domain.ScreeningHit{
+				Check:  domain.CheckDuplicateIdentity,
+				Action: uc.policy.DuplicateIdentity,
+				Reason: "duplicate_identity",
+				Detail: "same name, date and place of birth as customers " + strings.Join(ids, ", "),
+			})
+		}
+	}
+	return result, nil
+}
+
+// ScreenTransaction implements ScreeningUseCase.ScreenTransaction
+func (uc *screeningUseCase) ScreenTransaction(tx *domain.Transaction) (*domain.ScreeningResult, error) {
+	customer, err := uc.customerRepo.GetByID(tx.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.ScreeningResult{Flag: domain.RiskClear}
+	if err := uc.screenBlacklist(result, map[domain.BlacklistType]string{
+		domain.BlacklistNIK:    customer.NIK,
+		domain.BlacklistPhone:  customer.Phone,
+		domain.BlacklistDevice: tx.DeviceID,
+	}); err != nil {
+		return nil, err
+	}
+
+	if customer.RiskFlag == domain.RiskHold {
+		result.Add(domain.ScreeningHit{
+			Check:  domain.CheckCustomerFlag,
+			Action: domain.RiskHold,
+			Reason: "customer_held",
+			Detail: "customer was held for review on registration",
+		})
+	}
+
+	now := time.Now()
+	for _, rule := range uc.policy.Velocity {
+		value := strconv.FormatUint(uint64(tx.CustomerID), 10)
+		if rule.Key == domain.VelocityDevice {
+			if tx.DeviceID == "" {
+				continue
+			}
+			value = tx.DeviceID
+		}
+
+		count, err := uc.screeningRepo.CountContracts(rule.Key, value, now.Add(-rule.Window))
+		if err != nil {
+			return nil, err
+		}
+		// The new contract is not recorded yet
+		if count+1 > rule.MaxContracts {
+			result.Add(domain.ScreeningHit{
+				Check:  domain.CheckVelocity,
+				Action: rule.Action,
+				Reason: "velocity_" + rule.Name,
+				Detail: fmt.Sprintf("%d contracts by %s within %s, at most %d allowed", count+1, rule.Key, rule.Window, rule.MaxContracts),
+			})
+		}
+	}
+	return result, nil
+}
+
+// screenBlacklist blocks identifiers on the blacklist
+func (uc *screeningUseCase) screenBlacklist(result *domain.ScreeningResult, identifiers map[domain.BlacklistType]string) error {
+	for entryType, value := range identifiers {
+		identifiers[entryType] = domain.NormalizeIdentifier(entryType, value)
+	}
+	entries, err := uc.blacklistRepo.Match(identifiers)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		result.Add(domain.ScreeningHit{
+			Check:  domain.CheckBlacklist,
+			Action: domain.RiskBlock,
+			Reason: "blacklisted_" + string(entry.Type),
+			Detail: entry.Reason,
+		})
+	}
+	return nil
+}
+
+// errScreeningBlocked is returned when screening refuses a registration or
+// a contract. The reasons are not disclosed, so a blacklist or velocity rule
+// cannot be probed through the API.
+func errScreeningBlocked(subject string) error {
+	return domain.NewError(domain.ErrForbidden, "screening_blocked", subject+" refused by screening")
+}
@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"math"
+	"time"
+	"xyz-multifinance/internal/domain"
+)
+
+// settlementBatchSize limits how many contracts are loaded per query
+const settlementBatchSize = 500
+
+type settlementUseCase struct {
+	settlementRepo domain.SettlementRepository
+	merchantRepo   domain.MerchantRepository
+}
+
+// NewSettlementUseCase creates a new instance of SettlementUseCase
+func NewSettlementUseCase(
+	settlementRepo domain.SettlementRepository,
+	merchantRepo domain.MerchantRepository,
+) domain.SettlementUseCase {
+	return &settlementUseCase{
+		settlementRepo: settlementRepo,
+		merchantRepo:   merchantRepo,
+	}
+}
+
+// RunDaily implements SettlementUseCase.RunDaily. Contracts approved up to
+// now are grouped per merchant into one payout each, net of the merchant's
+// commission on the OTR price.
+func (uc *settlementUseCase) RunDaily(now time.Time) ([]domain.Settlement, error) {
+	settlementDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	var settlements []domain.Settlement
+	for {
+		transactions, err := uc.settlementRepo.ListUnsettledTransactions(now, settlementBatchSize)
+		if err != nil {
+			return settlements, err
+		}
+
+		// Transactions are ordered by merchant
+		full := len(transactions) == settlementBatchSize
+		for start := 0; start < len(transactions); {
+			merchantID := *transactions[start].MerchantID
+			end := start
+			for end < len(transactions) && *transactions[end].MerchantID == merchantID {
+				end++
+			}
+
+			// The last merchant of a full batch may continue in the next
+			// batch; it is settled from there in one piece
+			if full && end == len(transactions) && start > 0 {
+				break
+			}
+
+			settlement, err := uc.settle(merchantID, settlementDate, transactions[start:end])
+			if err != nil {
+				return settlements, err
+			}
+			settlements = append(settlements, *settlement)
+			start = end
+		}
+
+		if !full {
+			return settlements, nil
+		}
+	}
+}
+
+// settle creates the settlement of a merchant's contracts
+func (uc *settlementUseCase) settle(merchantID uint, settlementDate time.Time, transactions []domain.Transaction) (*domain.Settlement, error) {
+	merchant, err := uc.merchantRepo.GetByID(merchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	settlement := &domain.Settlement{
+		MerchantID:     merchantID,
+		SettlementDate: settlementDate,
+		Status:         domain.SettlementPending,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	for _, tx := range transactions {
+		commission := roundCurrency(tx.OTRAmount * merchant.CommissionRate)
+		item := domain.SettlementItem{
+			TransactionID:    tx.ID,
+			ContractNumber:   tx.ContractNumber,
+			GrossAmount:      tx.OTRAmount,
+			CommissionRate:   merchant.CommissionRate,
+			CommissionAmount: commission,
+			NetAmount:        roundCurrency(tx.OTRAmount - commission),
+			CreatedAt:        now,
+		}
+
+		settlement.Items = append(settlement.Items, item)
+		settlement.TransactionCount++
+		settlement.GrossAmount = roundCurrency(settlement.GrossAmount + item.GrossAmount)
+		settlement.CommissionAmount = roundCurrency(settlement.CommissionAmount + item.CommissionAmount)
+		settlement.NetAmount = roundCurrency(settlement.NetAmount + item.NetAmount)
+	}
+
+	if err := uc.settlementRepo.Create(settlement); err != nil {
+		return nil, err
+	}
+	return settlement, nil
+}
+
+// GetByID implements SettlementUseCase.GetByID
+func (uc *settlementUseCase) GetByID(id uint) (*domain.Settlement, error) {
+	return uc.settlementRepo.GetByID(id)
+}
+
+// ListByMerchant implements SettlementUseCase.ListByMerchant
+func (uc *settlementUseCase) ListByMerchant(merchantID uint, offset, limit int) ([]domain.Settlement, error) {
+	if _, err := uc.merchantRepo.GetByID(merchantID); err != nil {
+		return nil, err
+	}
+	return uc.settlementRepo.ListByMerchant(merchantID, offset, limit)
+}
+
+// MarkPaid implements SettlementUseCase.MarkPaid
+func (uc *settlementUseCase) MarkPaid(id uint, reference string) (*domain.Settlement, error) {
+	if _, err := uc.settlementRepo.GetByID(id); err != nil {
+		return nil, err
+	}
+	if err := uc.settlementRepo.MarkPaid(id, reference, time.Now()); err != nil {
+		return nil, err
+	}
+	return uc.settlementRepo.GetByID(id)
+}
+
+// roundCurrency rounds an amount to whole cents
+func roundCurrency(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -9,27 +9,42 @@ import (
 	"xyz-multifinance/internal/pkg/redis"
 )
 
+// overdueBatchSize bounds the installments marked overdue per query
+const overdueBatchSize = 100
+
 type transactionUseCase struct {
-	transactionRepo domain.TransactionRepository
-	customerUseCase domain.CustomerUseCase
-	redisClient     redis.RedisClient
+	transactionRepo  domain.TransactionRepository
+	customerUseCase  domain.CustomerUseCase
+	merchantRepo     domain.MerchantRepository
+	screeningUseCase domain.ScreeningUseCase
+	redisClient      redis.RedisClient
 }
 
 // NewTransactionUseCase creates a new instance of TransactionUseCase
 func NewTransactionUseCase(
 	transactionRepo domain.TransactionRepository,
 	customerUseCase domain.CustomerUseCase,
+	merchantRepo domain.MerchantRepository,
+	screeningUseCase domain.ScreeningUseCase,
 	redisClient redis.RedisClient,
 ) domain.TransactionUseCase {
 	return &transactionUseCase{
-		transactionRepo: transactionRepo,
-		customerUseCase: customerUseCase,
-		redisClient:     redisClient,
+		transactionRepo:  transactionRepo,
+		customerUseCase:  customerUseCase,
+		merchantRepo:     merchantRepo,
+		screeningUseCase: screeningUseCase,
+		redisClient:      redisClient,
 	}
 }
 
 // Create implements TransactionUseCase.Create
 func (uc *transactionUseCase) Create(tx *domain.Transaction) error {
+	if tx.MerchantID != nil {
+		if err := uc.checkMerchant(*tx.MerchantID); err != nil {
+			return err
+		}
+	}
+
 	// Check credit limit
 	totalAmount := tx.OTRAmount + tx.AdminFee
 	hasLimit, err := uc.customerUseCase.CheckCreditLimit(tx.CustomerID, totalAmount, tx.Tenor)
@@ -37,8 +52,20 @@ func (uc *transactionUseCase) Create(tx *domain.Transaction) error {
 		return err
 	}
 	if !hasLimit {
-		return errors.New("insufficient credit limit")
+		return domain.ErrInsufficientLimit
+	}
+
+	// Blocked contracts are refused, held ones are referred to an operator
+	// by underwriting
+	screening, err := uc.screeningUseCase.ScreenTransaction(tx)
+	if err != nil {
+		return err
 	}
+	if screening.Flag == domain.RiskBlock {
+		return errScreeningBlocked("contract")
+	}
+	tx.RiskFlag = screening.Flag
+	tx.RiskHits = screening.Hits
 
 	// Generate contract number
 	tx.ContractNumber = fmt.Sprintf("XYZ-%d-%d", tx.CustomerID, time.Now().Unix())
@@ -65,13 +92,38 @@ func (uc *transactionUseCase) Create(tx *domain.Transaction) error {
 		tx.Installments = append(tx.Installments, installment)
 	}
 
-	// Create transaction
-	if err := uc.transactionRepo.Create(tx); err != nil {
+	// The credit limit is charged by the transaction.created consumer, even
+	// when contracts created meanwhile used it up
+	event, err := newOutboxEvent(domain.EventTransactionCreated, nil, domain.TransactionCreated{
+		ContractNumber: tx.ContractNumber,
+		CustomerID:     tx.CustomerID,
+		PartnerID:      tx.PartnerID,
+		MerchantID:     tx.MerchantID,
+		Amount:         totalAmount,
+		Tenor:          tx.Tenor,
+	}, now)
+	if err != nil {
 		return err
 	}
+	tx.Events = append(tx.Events, event)
 
-	// Update credit limit usage
-	return uc.customerUseCase.UpdateCreditLimitUsage(tx.CustomerID, totalAmount, tx.Tenor)
+	// Create transaction
+	return uc.transactionRepo.Create(tx)
+}
+
+// checkMerchant ensures contracts are only booked for active merchants
+func (uc *transactionUseCase) checkMerchant(merchantID uint) error {
+	merchant, err := uc.merchantRepo.GetByID(merchantID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return domain.NewError(domain.ErrValidation, "invalid_merchant", "merchant does not exist")
+	}
+	if err != nil {
+		return err
+	}
+	if merchant.Status != domain.MerchantActive {
+		return domain.NewError(domain.ErrValidation, "merchant_inactive", "merchant is not active")
+	}
+	return nil
 }
 
 // GetByID implements TransactionUseCase.GetByID
@@ -84,22 +136,59 @@ func (uc *transactionUseCase) GetByContractNumber(contractNumber string) (*domai
 	return uc.transactionRepo.GetByContractNumber(contractNumber)
 }
 
-// UpdateStatus implements TransactionUseCase.UpdateStatus
-func (uc *transactionUseCase) UpdateStatus(id uint, status domain.TransactionStatus) error {
+// statusTransitions lists the status changes allowed through UpdateStatus.
+// Contracts are approved or rejected by underwriting, expired by the
+// underwriting SLA and written off by the write-off use case, each of which
+// also keeps the credit limit and schedule in step.
+var statusTransitions = map[domain.TransactionStatus][]domain.TransactionStatus{
+	domain.StatusPending: {domain.StatusCancelled},
+}
+
+// UpdateStatus implements TransactionUseCase.UpdateStatus. version must be
+// the version the client last read.
+func (uc *transactionUseCase) UpdateStatus(id uint, status domain.TransactionStatus, version int) (*domain.Transaction, error) {
 	tx, err := uc.transactionRepo.GetByID(id)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	if !canTransition(tx.Status, status) {
+		return nil, domain.NewError(domain.ErrConflict, "invalid_status_transition",
+			fmt.Sprintf("transaction status cannot change from %s to %s", tx.Status, status))
+	}
+
+	previous := tx.Status
 	tx.Status = status
+	tx.Version = version
 	tx.UpdatedAt = time.Now()
 
-	return uc.transactionRepo.Update(tx)
+	if err := recordStatusEvents(tx, previous); err != nil {
+		return nil, err
+	}
+
+	if err := uc.transactionRepo.Update(tx); err != nil {
+		if errors.Is(err, domain.ErrConflict) {
+			return nil, errVersionMismatch()
+		}
+		return nil, err
+	}
+
+	return tx, nil
+}
+
+// canTransition reports whether statusTransitions allows the status change
+func canTransition(from, to domain.TransactionStatus) bool {
+	for _, allowed := range statusTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
 }
 
 // GetCustomerTransactions implements TransactionUseCase.GetCustomerTransactions
-func (uc *transactionUseCase) GetCustomerTransactions(customerID uint, offset, limit int) ([]domain.Transaction, error) {
-	return uc.transactionRepo.List(customerID, offset, limit)
+func (uc *transactionUseCase) GetCustomerTransactions(filter domain.TransactionFilter, offset, limit int) ([]domain.Transaction, error) {
+	return uc.transactionRepo.List(filter, offset, limit)
 }
 
 // GetInstallments implements TransactionUseCase.GetInstallments
@@ -107,52 +196,58 @@ func (uc *transactionUseCase) GetInstallments(transactionID uint) ([]domain.Inst
 	return uc.transactionRepo.GetInstallments(transactionID)
 }
 
-// PayInstallment implements TransactionUseCase.PayInstallment
+// PayInstallment implements TransactionUseCase.PayInstallment. Only
+// installments of approved contracts can be paid.
 func (uc *transactionUseCase) PayInstallment(installmentID uint) error {
-	// Create distributed lock
-	lock := redis.NewDistributedLock(uc.redisClient, fmt.Sprintf("installment:%d", installmentID), 30*time.Second)
-
-	// Try to acquire lock with timeout
 	ctx := context.Background()
-	if err := lock.TryLock(ctx, 5*time.Second); err != nil {
-		return fmt.Errorf("failed to acquire lock: %v", err)
+	lock, err := lockInstallment(ctx, uc.redisClient, uc.transactionRepo, installmentID)
+	if err != nil {
+		return err
 	}
 	defer lock.Unlock(ctx)
 
-	// Get installment with retries for optimistic locking
-	var targetInstallment *domain.Installment
+	// Update installment with retries for optimistic locking
 	maxRetries := 3
 	var lastError error
 
 	for i := 0; i < maxRetries; i++ {
-		installments, err := uc.transactionRepo.GetInstallments(installmentID)
+		installment, err := uc.transactionRepo.GetInstallmentByID(installmentID)
 		if err != nil {
 			return err
 		}
 
-		for _, inst := range installments {
-			if inst.ID == installmentID {
-				targetInstallment = &inst
-				break
-			}
+		if installment.Status == "paid" {
+			return domain.NewError(domain.ErrConflict, "installment_already_paid", "installment already paid")
 		}
-
-		if targetInstallment == nil {
-			return errors.New("installment not found")
+		if installment.Status == domain.InstallmentWrittenOff {
+			return domain.NewError(domain.ErrConflict, "installment_written_off", "installment was written off, record the payment as a recovery")
+		}
+		if !installment.IsOutstanding() {
+			return domain.NewError(domain.ErrConflict, "installment_superseded", "installment was replaced by a restructured schedule")
 		}
 
-		if targetInstallment.Status == "paid" {
-			return errors.New("installment already paid")
+		tx, err := uc.transactionRepo.GetByID(installment.TransactionID)
+		if err != nil {
+			return err
+		}
+		// Only approved contracts are owed; paying off any other would
+		// release a credit limit the contract does not hold
+		if tx.Status != domain.StatusApproved {
+			return domain.NewError(domain.ErrConflict, "contract_not_approved", "installments can only be paid on approved contracts")
 		}
 
 		now := time.Now()
-		targetInstallment.Status = "paid"
-		targetInstallment.PaidAt = &now
-		targetInstallment.UpdatedAt = now
+		installment.Status = "paid"
+		installment.PaidAt = &now
+		installment.UpdatedAt = now
+		installment.FencingToken = lock.FencingToken()
+		if err := recordPaymentEvents(tx, installment, now); err != nil {
+			return err
+		}
 
-		err = uc.transactionRepo.UpdateInstallment(targetInstallment)
+		err = uc.transactionRepo.UpdateInstallment(installment)
 		if err != nil {
-			if err.Error() == "concurrent modification detected" {
+			if errors.Is(err, domain.ErrConflict) {
 				lastError = err
 				time.Sleep(100 * time.Millisecond) // Wait before retry
 				continue
@@ -162,5 +257,127 @@ func (uc *transactionUseCase) PayInstallment(installmentID uint) error {
 		return nil
 	}
 
-	return fmt.Errorf("failed to update installment after %d retries: %v", maxRetries, lastError)
+	return fmt.Errorf("failed to update installment after %d retries: %w", maxRetries, lastError)
+}
+
+// lockInstallment acquires the distributed lock of an installment. Its
+// fencing token is issued above the highest token already written to the
+// row, so the fencing check holds when the Redis counter was reset.
+func lockInstallment(ctx context.Context, client redis.RedisClient, repo domain.TransactionRepository, installmentID uint) (*redis.DistributedLock, error) {
+	installment, err := repo.GetInstallmentByID(installmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	lock := redis.NewDistributedLock(client, fmt.Sprintf("installment:%d", installmentID), 30*time.Second,
+		redis.WithAutoRenew(), redis.WithFenceFloor(installment.FencingToken))
+	if err := lock.TryLock(ctx, 5*time.Second); err != nil {
+		if errors.Is(err, redis.ErrLockNotAcquired) {
+			return nil, domain.WrapError(domain.ErrConflict, "installment_locked", "installment is being processed", err)
+		}
+		return nil, err
+	}
+	return lock, nil
+}
+
+// MarkOverdueInstallments implements TransactionUseCase.MarkOverdueInstallments.
+// Installments paid concurrently fail the version check and are skipped.
+func (uc *transactionUseCase) MarkOverdueInstallments(now time.Time) (int, error) {
+	marked := 0
+	for {
+		installments, err := uc.transactionRepo.ListOverdueInstallments(now, overdueBatchSize)
+		if err != nil {
+			return marked, err
+		}
+
+		progress := 0
+		for i := range installments {
+			installment := &installments[i]
+			installment.Status = "overdue"
+			installment.UpdatedAt = now
+
+			tx := installment.Transaction
+			if tx == nil {
+				tx = &domain.Transaction{ID: installment.TransactionID}
+			}
+			event, err := newInstallmentEvent(domain.EventInstallmentOverdue, tx, installment, now)
+			if err != nil {
+				return marked, err
+			}
+			installment.Events = []domain.OutboxEvent{event}
+
+			if err := uc.transactionRepo.UpdateInstallment(installment); err != nil {
+				if errors.Is(err, domain.ErrConflict) {
+					continue
+				}
+				return marked, err
+			}
+			progress++
+		}
+		marked += progress
+
+		if len(installments) < overdueBatchSize || progress == 0 {
+			return marked, nil
+		}
+	}
+}
+
+// recordStatusEvents records transaction.status_changed, and the webhook
+// event for a contract decision
+func recordStatusEvents(tx *domain.Transaction, previous domain.TransactionStatus) error {
+	event, err := newOutboxEvent(domain.EventTransactionStatusChanged, nil, domain.TransactionStatusChanged{
+		TransactionID:  tx.ID,
+		ContractNumber: tx.ContractNumber,
+		CustomerID:     tx.CustomerID,
+		From:           previous,
+		To:             tx.Status,
+		Amount:         tx.OTRAmount + tx.AdminFee,
+		Tenor:          tx.Tenor,
+	}, tx.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	tx.Events = append(tx.Events, event)
+
+	var eventType domain.EventType
+	switch tx.Status {
+	case domain.StatusApproved:
+		eventType = domain.EventContractApproved
+	case domain.StatusRejected:
+		eventType = domain.EventContractRejected
+	case domain.StatusExpired:
+		eventType = domain.EventContractExpired
+	default:
+		return nil
+	}
+
+	event, err = newContractEvent(eventType, tx, tx.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	tx.Events = append(tx.Events, event)
+	return nil
+}
+
+// recordPaymentEvents records installment.paid, and contract.paid_off when
+// installment is the last unpaid installment of tx
+func recordPaymentEvents(tx *domain.Transaction, installment *domain.Installment, now time.Time) error {
+	event, err := newInstallmentEvent(domain.EventInstallmentPaid, tx, installment, now)
+	if err != nil {
+		return err
+	}
+	installment.Events = []domain.OutboxEvent{event}
+
+	for _, other := range tx.Installments {
+		if other.ID != installment.ID && other.IsOutstanding() {
+			return nil
+		}
+	}
+
+	event, err = newContractEvent(domain.EventContractPaidOff, tx, now)
+	if err != nil {
+		return err
+	}
+	installment.Events = append(installment.Events, event)
+	return nil
 }
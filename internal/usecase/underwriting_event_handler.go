@@ -0,0 +1,45 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"xyz-multifinance/internal/domain"
+)
+
+// underwritingEventGroup is the consumer group underwriting new contracts
+const underwritingEventGroup = "underwriting"
+
+type underwritingEventHandler struct {
+	underwritingUseCase domain.UnderwritingUseCase
+}
+
+// NewUnderwritingEventHandler creates the event handler that underwrites a
+// contract once it is created
+func NewUnderwritingEventHandler(underwritingUseCase domain.UnderwritingUseCase) domain.EventHandler {
+	return &underwritingEventHandler{
+		underwritingUseCase: underwritingUseCase,
+	}
+}
+
+// Group implements EventHandler.Group
+func (h *underwritingEventHandler) Group() string {
+	return underwritingEventGroup
+}
+
+// Handle implements EventHandler.Handle. A redelivered event leaves the
+// first decision in place.
+func (h *underwritingEventHandler) Handle(ctx context.Context, event *domain.Event) error {
+	if event.Type != domain.EventTransactionCreated {
+		return nil
+	}
+
+	var data domain.TransactionCreated
+	if err := event.Decode(&data); err != nil {
+		return err
+	}
+	_, err := h.underwritingUseCase.Underwrite(data.ContractNumber)
+	if errors.Is(err, domain.ErrEventProcessed) {
+		return nil
+	}
+	return err
+}
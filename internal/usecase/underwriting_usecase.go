@@ -0,0 +1,404 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"xyz-multifinance/internal/domain"
+)
+
+// Audit log actions of underwriting reviews
+const (
+	auditUnderwritingClaimed  = "underwriting.claimed"
+	auditUnderwritingApproved = "underwriting.approved"
+	auditUnderwritingRejected = "underwriting.rejected"
+)
+
+// staleBatchSize bounds the stale applications expired per query
+const staleBatchSize = 100
+
+type underwritingUseCase struct {
+	underwritingRepo domain.UnderwritingRepository
+	transactionRepo  domain.TransactionRepository
+	customerRepo     domain.CustomerRepository
+	blacklistRepo    domain.BlacklistRepository
+	scoringUseCase   domain.ScoringUseCase
+	policy           domain.UnderwritingPolicy
+}
+
+// NewUnderwritingUseCase creates a new instance of UnderwritingUseCase
+func NewUnderwritingUseCase(
+	underwritingRepo domain.UnderwritingRepository,
+	transactionRepo domain.TransactionRepository,
+	customerRepo domain.CustomerRepository,
+	blacklistRepo domain.BlacklistRepository,
+	scoringUseCase domain.ScoringUseCase,
+	policy domain.UnderwritingPolicy,
+) domain.UnderwritingUseCase {
+	return &underwritingUseCase{
+		underwritingRepo: underwritingRepo,
+		transactionRepo:  transactionRepo,
+		customerRepo:     customerRepo,
+		blacklistRepo:    blacklistRepo,
+		scoringUseCase:   scoringUseCase,
+		policy:           policy,
+	}
+}
+
+// Underwrite implements UnderwritingUseCase.Underwrite. The most severe rule
+// outcome decides the application. A contract no longer pending, e.g.
+// cancelled before its event was consumed, is left alone and nil returned.
+func (uc *underwritingUseCase) Underwrite(contractNumber string) (*domain.UnderwritingDecision, error) {
+	tx, err := uc.transactionRepo.GetByContractNumber(contractNumber)
+	if err != nil {
+		return nil, err
+	}
+	if tx.Status != domain.StatusPending {
+		return nil, nil
+	}
+
+	customer := tx.Customer
+	if customer == nil || customer.ID == 0 {
+		if customer, err = uc.customerRepo.GetByID(tx.CustomerID); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	rules, err := uc.evaluate(tx, customer, now)
+	if err != nil {
+		return nil, err
+	}
+
+	decision := &domain.UnderwritingDecision{
+		TransactionID: tx.ID,
+		Outcome:       domain.OutcomeApprove,
+		Status:        domain.UnderwritingDecided,
+		Rules:         rules,
+		DueAt:         tx.CreatedAt.Add(uc.policy.ReviewSLA),
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	for _, result := range rules {
+		if result.Outcome.Severity() > decision.Outcome.Severity() {
+			decision.Outcome = result.Outcome
+		}
+	}
+
+	switch decision.Outcome {
+	case domain.OutcomeApprove:
+		tx.Status = domain.StatusApproved
+	case domain.OutcomeReject:
+		tx.Status = domain.StatusRejected
+	default:
+		decision.Status = domain.UnderwritingQueued
+	}
+	if decision.Status == domain.UnderwritingDecided {
+		tx.UpdatedAt = now
+		if err := recordStatusEvents(tx, domain.StatusPending); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := uc.underwritingRepo.Create(decision, tx); err != nil {
+		return nil, err
+	}
+	return decision, nil
+}
+
+// evaluate runs every underwriting rule on the application tx of customer
+func (uc *underwritingUseCase) evaluate(tx *domain.Transaction, customer *domain.Customer, now time.Time) ([]domain.RuleResult, error) {
+	exposure, err := uc.underwritingRepo.GetExposure(tx.CustomerID, tx.Tenor, tx.ID)
+	if err != nil {
+		return nil, err
+	}
+	limits, err := uc.customerRepo.GetCreditLimits(tx.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+	blacklisted, err := uc.blacklistRepo.Match(map[domain.BlacklistType]string{domain.BlacklistNIK: customer.NIK})
+	if err != nil {
+		return nil, err
+	}
+	score, err := uc.scoringUseCase.Score(tx.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+
+	amount := tx.OTRAmount + tx.AdminFee
+	return []domain.RuleResult{
+		creditLimitRule(limits, tx.Tenor, exposure.TenorAmount+amount),
+		uc.dbrRule(customer.Salary, exposure.MonthlyInstallments+tx.InstallmentAmount),
+		uc.ageRule(customer.DateOfBirth, tx.Tenor, now),
+		uc.overdueRule(exposure, now),
+		blacklistRule(len(blacklisted) > 0),
+		uc.amountRule(amount),
+		creditScoreRule(score),
+		screeningRule(tx),
+	}, nil
+}
+
+// creditLimitRule rejects applications taking the open contracts of a
+// tenor beyond its limit. The usage counter is charged asynchronously, so
+// the open contracts are summed instead.
+func creditLimitRule(limits []domain.CreditLimit, tenor int, total float64) domain.RuleResult {
+	for _, limit := range limits {
+		if limit.Tenor != tenor {
+			continue
+		}
+		if total > limit.Amount+amountTolerance {
+			return rejectRule(domain.RuleCreditLimit, "insufficient_limit", fmt.Sprintf("open contracts of %.2f exceed the %d month limit of %.2f", total, tenor, limit.Amount))
+		}
+		return approveRule(domain.RuleCreditLimit)
+	}
+	return rejectRule(domain.RuleCreditLimit, "no_credit_limit", fmt.Sprintf("customer has no %d month credit limit", tenor))
+}
+
+// dbrRule bounds the share of the monthly salary spent on installments
+func (uc *underwritingUseCase) dbrRule(salary, monthlyInstallments float64) domain.RuleResult {
+	if salary <= 0 {
+		return referRule(domain.RuleDBR, "income_unverified", "customer has no recorded salary")
+	}
+	dbr := monthlyInstallments / salary
+	detail := fmt.Sprintf("installments are %.1f%% of salary", dbr*100)
+	switch {
+	case dbr > uc.policy.MaxDBR:
+		return rejectRule(domain.RuleDBR, "dbr_exceeded", detail)
+	case dbr > uc.policy.ReferDBR:
+		return referRule(domain.RuleDBR, "dbr_high", detail)
+	}
+	return approveRule(domain.RuleDBR)
+}
+
+// ageRule requires the minimum age when applying, and at most the maximum
+// age when the last installment falls due
+func (uc *underwritingUseCase) ageRule(dateOfBirth time.Time, tenor int, now time.Time) domain.RuleResult {
+	if age := ageAt(dateOfBirth, now); age < uc.policy.MinAge {
+		return rejectRule(domain.RuleAge, "under_age", fmt.Sprintf("customer is %d, the minimum age is %d", age, uc.policy.MinAge))
+	}
+	if age := ageAt(dateOfBirth, now.AddDate(0, tenor, 0)); age > uc.policy.MaxAge {
+		return rejectRule(domain.RuleAge, "over_age", fmt.Sprintf("customer is %d at the end of the tenor, the maximum age is %d", age, uc.policy.MaxAge))
+	}
+	return approveRule(domain.RuleAge)
+}
+
+// overdueRule refers customers with overdue installments on other
+// contracts, and rejects them when overdue for too long
+func (uc *underwritingUseCase) overdueRule(exposure *domain.CreditExposure, now time.Time) domain.RuleResult {
+	if exposure.OverdueInstallments == 0 || exposure.OldestOverdueDate == nil {
+		return approveRule(domain.RuleOverdue)
+	}
+	days := int(now.Sub(*exposure.OldestOverdueDate).Hours() / 24)
+	detail := fmt.Sprintf("%d installments overdue, the oldest %d days", exposure.OverdueInstallments, days)
+	if days > uc.policy.MaxOverdueDays {
+		return rejectRule(domain.RuleOverdue, "overdue_exceeded", detail)
+	}
+	return referRule(domain.RuleOverdue, "existing_overdue", detail)
+}
+
+func blacklistRule(blacklisted bool) domain.RuleResult {
+	if blacklisted {
+		return rejectRule(domain.RuleBlacklist, "blacklisted", "customer identity is blacklisted")
+	}
+	return approveRule(domain.RuleBlacklist)
+}
+
+// screeningRule refers contracts held by fraud screening
+func screeningRule(tx *domain.Transaction) domain.RuleResult {
+	if tx.RiskFlag == domain.RiskHold {
+		var reasons []string
+		for _, hit := range tx.RiskHits {
+			reasons = append(reasons, hit.Reason)
+		}
+		return referRule(domain.RuleScreening, "risk_hold", strings.Join(reasons, ", "))
+	}
+	return approveRule(domain.RuleScreening)
+}
+
+// creditScoreRule applies the cutoffs of the scorecard, explaining a low
+// score with its reason codes
+func creditScoreRule(score *domain.CreditScore) domain.RuleResult {
+	detail := fmt.Sprintf("score %d, reason codes %s", score.Score, strings.Join(score.ReasonCodes(), ", "))
+	switch score.Outcome {
+	case domain.OutcomeReject:
+		return rejectRule(domain.RuleCreditScore, "low_credit_score", detail)
+	case domain.OutcomeRefer:
+		return referRule(domain.RuleCreditScore, "marginal_credit_score", detail)
+	}
+	return approveRule(domain.RuleCreditScore)
+}
+
+// amountRule refers large contracts to an operator
+func (uc *underwritingUseCase) amountRule(amount float64) domain.RuleResult {
+	if uc.policy.MaxAutoApproveAmount > 0 && amount > uc.policy.MaxAutoApproveAmount {
+		return referRule(domain.RuleAmount, "manual_review_amount", fmt.Sprintf("financed amount exceeds %.2f", uc.policy.MaxAutoApproveAmount))
+	}
+	return approveRule(domain.RuleAmount)
+}
+
+func approveRule(rule string) domain.RuleResult {
+	return domain.RuleResult{Rule: rule, Outcome: domain.OutcomeApprove}
+}
+
+func referRule(rule, reason, detail string) domain.RuleResult {
+	return domain.RuleResult{Rule: rule, Outcome: domain.OutcomeRefer, Reason: reason, Detail: detail}
+}
+
+func rejectRule(rule, reason, detail string) domain.RuleResult {
+	return domain.RuleResult{Rule: rule, Outcome: domain.OutcomeReject, Reason: reason, Detail: detail}
+}
+
+// ageAt returns the age in whole years, on at, of someone born on dateOfBirth
+func ageAt(dateOfBirth, at time.Time) int {
+	age := at.Year() - dateOfBirth.Year()
+	if at.Month() < dateOfBirth.Month() || (at.Month() == dateOfBirth.Month() && at.Day() < dateOfBirth.Day()) {
+		age--
+	}
+	return age
+}
+
+// GetByTransaction implements UnderwritingUseCase.GetByTransaction
+func (uc *underwritingUseCase) GetByTransaction(transactionID uint) (*domain.UnderwritingDecision, error) {
+	return uc.underwritingRepo.GetByTransaction(transactionID)
+}
+
+// ListQueue implements UnderwritingUseCase.ListQueue
+func (uc *underwritingUseCase) ListQueue(offset, limit int) ([]domain.UnderwritingDecision, error) {
+	return uc.underwritingRepo.ListQueue(offset, limit)
+}
+
+// Claim implements UnderwritingUseCase.Claim, assigning a referred
+// application to the operator reviewing it
+func (uc *underwritingUseCase) Claim(id uint, actor domain.Actor) (*domain.UnderwritingDecision, error) {
+	decision, err := uc.queued(id, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	decision.AssignedTo = &actor.ID
+	decision.UpdatedAt = now
+
+	audit, err := newAuditLog(actor, auditUnderwritingClaimed, "underwriting", decision.ID, map[string]interface{}{
+		"transaction_id": decision.TransactionID,
+	}, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.underwritingRepo.Claim(decision, audit); err != nil {
+		if errors.Is(err, domain.ErrConflict) {
+			return nil, errClaimedByOther()
+		}
+		return nil, err
+	}
+	return decision, nil
+}
+
+// Approve implements UnderwritingUseCase.Approve
+func (uc *underwritingUseCase) Approve(id uint, note string, actor domain.Actor) (*domain.UnderwritingDecision, error) {
+	return uc.decide(id, note, actor, domain.UnderwritingApproved, domain.StatusApproved, auditUnderwritingApproved)
+}
+
+// Reject implements UnderwritingUseCase.Reject
+func (uc *underwritingUseCase) Reject(id uint, note string, actor domain.Actor) (*domain.UnderwritingDecision, error) {
+	return uc.decide(id, note, actor, domain.UnderwritingRejected, domain.StatusRejected, auditUnderwritingRejected)
+}
+
+// decide records an operator's review of a referred application, moving
+// its contract out of pending
+func (uc *underwritingUseCase) decide(id uint, note string, actor domain.Actor, status domain.UnderwritingStatus, txStatus domain.TransactionStatus, action string) (*domain.UnderwritingDecision, error) {
+	decision, err := uc.queued(id, actor)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := uc.transactionRepo.GetByID(decision.TransactionID)
+	if err != nil {
+		return nil, err
+	}
+	if tx.Status != domain.StatusPending {
+		return nil, domain.NewError(domain.ErrConflict, "transaction_not_pending", "contract is no longer pending")
+	}
+
+	now := time.Now()
+	decision.Status = status
+	decision.AssignedTo = &actor.ID
+	decision.ReviewedBy = &actor.ID
+	decision.ReviewNote = note
+	decision.ReviewedAt = &now
+	decision.UpdatedAt = now
+
+	tx.Status = txStatus
+	tx.UpdatedAt = now
+	if err := recordStatusEvents(tx, domain.StatusPending); err != nil {
+		return nil, err
+	}
+
+	audit, err := newAuditLog(actor, action, "underwriting", decision.ID, map[string]interface{}{
+		"transaction_id": decision.TransactionID,
+		"outcome":        decision.Outcome,
+		"reasons":        decision.Reasons(),
+		"note":           note,
+	}, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.underwritingRepo.Decide(decision, tx, audit); err != nil {
+		return nil, err
+	}
+	return decision, nil
+}
+
+// queued returns a referred application actor may review
+func (uc *underwritingUseCase) queued(id uint, actor domain.Actor) (*domain.UnderwritingDecision, error) {
+	decision, err := uc.underwritingRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if decision.Status != domain.UnderwritingQueued {
+		return nil, domain.NewError(domain.ErrConflict, "underwriting_not_queued", "application is no longer awaiting review")
+	}
+	if decision.AssignedTo != nil && *decision.AssignedTo != actor.ID {
+		return nil, errClaimedByOther()
+	}
+	return decision, nil
+}
+
+func errClaimedByOther() error {
+	return domain.NewError(domain.ErrForbidden, "underwriting_claimed", "application is claimed by another operator")
+}
+
+// ExpireStale implements UnderwritingUseCase.ExpireStale. Applications
+// decided concurrently fail the version check and are skipped.
+func (uc *underwritingUseCase) ExpireStale(now time.Time) (int, error) {
+	expired := 0
+	for {
+		transactions, err := uc.underwritingRepo.ListStale(now.Add(-uc.policy.ReviewSLA), staleBatchSize)
+		if err != nil {
+			return expired, err
+		}
+
+		progress := 0
+		for i := range transactions {
+			tx := &transactions[i]
+			tx.Status = domain.StatusExpired
+			tx.UpdatedAt = now
+			if err := recordStatusEvents(tx, domain.StatusPending); err != nil {
+				return expired, err
+			}
+
+			if err := uc.underwritingRepo.Expire(tx); err != nil {
+				if errors.Is(err, domain.ErrConflict) {
+					continue
+				}
+				return expired, err
+			}
+			progress++
+		}
+		expired += progress
+
+		if len(transactions) < staleBatchSize || progress == 0 {
+			return expired, nil
+		}
+	}
+}
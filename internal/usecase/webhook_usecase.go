@@ -0,0 +1,296 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+	"xyz-multifinance/internal/domain"
+	"xyz-multifinance/internal/pkg/crypto"
+	"xyz-multifinance/internal/pkg/signing"
+)
+
+const (
+	// deliveryLease hides a claimed delivery from other dispatchers while it is sent
+	deliveryLease = 2 * time.Minute
+	// maxResponseExcerpt bounds the response body kept with a failed attempt
+	maxResponseExcerpt = 512
+)
+
+type webhookUseCase struct {
+	webhookRepo domain.WebhookRepository
+	partnerRepo domain.PartnerRepository
+	client      *http.Client
+	retry       domain.WebhookRetryPolicy
+	batchSize   int
+}
+
+// NewWebhookUseCase creates a new instance of WebhookUseCase. Each dispatch
+// run fans out and sends at most batchSize events and deliveries.
+func NewWebhookUseCase(
+	webhookRepo domain.WebhookRepository,
+	partnerRepo domain.PartnerRepository,
+	client *http.Client,
+	retry domain.WebhookRetryPolicy,
+	batchSize int,
+) domain.WebhookUseCase {
+	return &webhookUseCase{
+		webhookRepo: webhookRepo,
+		partnerRepo: partnerRepo,
+		client:      client,
+		retry:       retry,
+		batchSize:   batchSize,
+	}
+}
+
+// CreateSubscription implements WebhookUseCase.CreateSubscription
+func (uc *webhookUseCase) CreateSubscription(subscription *domain.WebhookSubscription) (*domain.IssuedWebhookSubscription, error) {
+	if err := validateSubscription(subscription); err != nil {
+		return nil, err
+	}
+	if _, err := uc.partnerRepo.GetByID(subscription.PartnerID); err != nil {
+		return nil, err
+	}
+
+	secret, err := assignSecret(subscription)
+	if err != nil {
+		return nil, err
+	}
+	subscription.Status = domain.SubscriptionActive
+
+	if err := uc.webhookRepo.CreateSubscription(subscription); err != nil {
+		return nil, err
+	}
+	return &domain.IssuedWebhookSubscription{WebhookSubscription: *subscription, Secret: secret}, nil
+}
+
+// GetSubscription implements WebhookUseCase.GetSubscription
+func (uc *webhookUseCase) GetSubscription(id uint) (*domain.WebhookSubscription, error) {
+	return uc.webhookRepo.GetSubscription(id)
+}
+
+// ListSubscriptions implements WebhookUseCase.ListSubscriptions
+func (uc *webhookUseCase) ListSubscriptions(partnerID uint) ([]domain.WebhookSubscription, error) {
+	return uc.webhookRepo.ListSubscriptions(partnerID)
+}
+
+// UpdateSubscription implements WebhookUseCase.UpdateSubscription. Only the
+// URL, event types and status can be changed.
+func (uc *webhookUseCase) UpdateSubscription(subscription *domain.WebhookSubscription) (*domain.WebhookSubscription, error) {
+	if err := validateSubscription(subscription); err != nil {
+		return nil, err
+	}
+
+	current, err := uc.webhookRepo.GetSubscription(subscription.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	current.URL = subscription.URL
+	current.EventTypes = subscription.EventTypes
+	current.Status = subscription.Status
+
+	if err := uc.webhookRepo.UpdateSubscription(current); err != nil {
+		return nil, err
+	}
+	return current, nil
+}
+
+// DeleteSubscription implements WebhookUseCase.DeleteSubscription
+func (uc *webhookUseCase) DeleteSubscription(id uint) error {
+	return uc.webhookRepo.DeleteSubscription(id)
+}
+
+// RotateSecret implements WebhookUseCase.RotateSecret. The previous secret
+// stops working immediately.
+func (uc *webhookUseCase) RotateSecret(id uint) (*domain.IssuedWebhookSubscription, error) {
+	subscription, err := uc.webhookRepo.GetSubscription(id)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := assignSecret(subscription)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.webhookRepo.UpdateSubscription(subscription); err != nil {
+		return nil, err
+	}
+	return &domain.IssuedWebhookSubscription{WebhookSubscription: *subscription, Secret: secret}, nil
+}
+
+// ListDeliveries implements WebhookUseCase.ListDeliveries
+func (uc *webhookUseCase) ListDeliveries(subscriptionID uint, status domain.DeliveryStatus, offset, limit int) ([]domain.WebhookDelivery, error) {
+	if _, err := uc.webhookRepo.GetSubscription(subscriptionID); err != nil {
+		return nil, err
+	}
+	return uc.webhookRepo.ListDeliveries(subscriptionID, status, offset, limit)
+}
+
+// RetryDelivery implements WebhookUseCase.RetryDelivery. A dead-lettered
+// delivery is queued again with a fresh retry budget.
+func (uc *webhookUseCase) RetryDelivery(id uint) (*domain.WebhookDelivery, error) {
+	delivery, err := uc.webhookRepo.GetDelivery(id)
+	if err != nil {
+		return nil, err
+	}
+	if delivery.Status != domain.DeliveryDead {
+		return nil, domain.NewError(domain.ErrConflict, "delivery_not_dead", "only dead-lettered deliveries can be retried")
+	}
+
+	delivery.Status = domain.DeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now()
+
+	if err := uc.webhookRepo.UpdateDelivery(delivery); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// Dispatch implements WebhookUseCase.Dispatch. New outbox events are fanned
+// out to subscriptions, then due deliveries are sent. Failed attempts are
+// retried with exponential backoff until the retry policy dead-letters them.
+// It returns the number of deliveries that succeeded.
+func (uc *webhookUseCase) Dispatch(ctx context.Context, now time.Time) (int, error) {
+	if _, err := uc.webhookRepo.FanOut(now, uc.batchSize); err != nil {
+		return 0, err
+	}
+
+	deliveries, err := uc.webhookRepo.ClaimDueDeliveries(now, deliveryLease, uc.batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for i := range deliveries {
+		delivery := &deliveries[i]
+
+		statusCode, err := uc.send(ctx, delivery, now)
+		if ctx.Err() != nil {
+			// Shutting down, the claim expires and the delivery is retried
+			return delivered, ctx.Err()
+		}
+		uc.recordAttempt(delivery, statusCode, err, now)
+
+		if err := uc.webhookRepo.UpdateDelivery(delivery); err != nil {
+			return delivered, err
+		}
+		if delivery.Status == domain.DeliveryDelivered {
+			delivered++
+		}
+	}
+	return delivered, nil
+}
+
+// errSubscriptionDisabled dead-letters deliveries of disabled subscriptions
+var errSubscriptionDisabled = errors.New("subscription is disabled")
+
+// send posts the event to the subscription URL, signed with the subscription
+// secret the same way partners sign their requests
+func (uc *webhookUseCase) send(ctx context.Context, delivery *domain.WebhookDelivery, now time.Time) (int, error) {
+	subscription, event := delivery.Subscription, delivery.Event
+	if subscription == nil || event == nil {
+		return 0, errors.New("delivery has no subscription or event")
+	}
+	if subscription.Status != domain.SubscriptionActive {
+		return 0, errSubscriptionDisabled
+	}
+
+	secret, err := crypto.Decrypt(subscription.Secret)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decrypt webhook secret: %w", err)
+	}
+	nonce, err := randomHex(16)
+	if err != nil {
+		return 0, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(event.Payload))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	message := signing.StringToSign(http.MethodPost, req.URL.RequestURI(), timestamp, nonce, event.Payload)
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(domain.HeaderWebhookEvent, string(event.EventType))
+	req.Header.Set(domain.HeaderWebhookID, event.EventID)
+	req.Header.Set(signing.HeaderTimestamp, timestamp)
+	req.Header.Set(signing.HeaderNonce, nonce)
+	req.Header.Set(signing.HeaderSignature, signing.Sign([]byte(secret), message))
+
+	resp, err := uc.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	excerpt, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseExcerpt))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, excerpt)
+	}
+	return resp.StatusCode, nil
+}
+
+// recordAttempt updates the delivery with the outcome of one attempt
+func (uc *webhookUseCase) recordAttempt(delivery *domain.WebhookDelivery, statusCode int, err error, now time.Time) {
+	delivery.Attempts++
+	delivery.LastStatusCode = statusCode
+
+	if err == nil {
+		delivery.Status = domain.DeliveryDelivered
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+		return
+	}
+
+	delivery.LastError = err.Error()
+	if errors.Is(err, errSubscriptionDisabled) || delivery.Attempts >= uc.retry.MaxAttempts {
+		delivery.Status = domain.DeliveryDead
+		return
+	}
+	delivery.NextAttemptAt = now.Add(uc.retry.Backoff(delivery.Attempts))
+}
+
+// assignSecret generates a signing secret for the subscription, storing it
+// encrypted, and returns the plaintext
+func assignSecret(subscription *domain.WebhookSubscription) (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	plaintext := "whsec_" + base64.RawURLEncoding.EncodeToString(secret)
+
+	encrypted, err := crypto.Encrypt(plaintext)
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt webhook secret: %w", err)
+	}
+	subscription.Secret = encrypted
+	return plaintext, nil
+}
+
+// validateSubscription checks the URL and event types of a subscription
+func validateSubscription(subscription *domain.WebhookSubscription) error {
+	target, err := url.Parse(subscription.URL)
+	if err != nil || (target.Scheme != "https" && target.Scheme != "http") || target.Host == "" {
+		return domain.NewError(domain.ErrValidation, "invalid_webhook_url", "webhook url must be an absolute http or https url")
+	}
+
+	if len(subscription.EventTypes) == 0 {
+		return domain.NewError(domain.ErrValidation, "invalid_event_type", "at least one event type is required")
+	}
+	for _, eventType := range subscription.EventTypes {
+		if !domain.IsWebhookEvent(eventType) {
+			return domain.NewError(domain.ErrValidation, "invalid_event_type", fmt.Sprintf("unknown event type %q", eventType))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package usecase
+
+import (
+	"time"
+	"xyz-multifinance/internal/domain"
+)
+
+// Audit log actions of write-offs
+const (
+	auditContractWrittenOff = "contract.written_off"
+	auditWriteOffRecovered  = "write_off.recovered"
+)
+
+type writeOffUseCase struct {
+	writeOffRepo    domain.WriteOffRepository
+	transactionRepo domain.TransactionRepository
+	policy          domain.ProvisioningPolicy
+}
+
+// NewWriteOffUseCase creates a new instance of WriteOffUseCase. Contracts
+// are written off once policy classifies them macet.
+func NewWriteOffUseCase(
+	writeOffRepo domain.WriteOffRepository,
+	transactionRepo domain.TransactionRepository,
+	policy domain.ProvisioningPolicy,
+) domain.WriteOffUseCase {
+	return &writeOffUseCase{
+		writeOffRepo:    writeOffRepo,
+		transactionRepo: transactionRepo,
+		policy:          policy,
+	}
+}
+
+// WriteOff implements WriteOffUseCase.WriteOff. The contract's credit limit
+// usage is not released.
+func (uc *writeOffUseCase) WriteOff(transactionID uint, reason string, actor domain.Actor) (*domain.WriteOff, error) {
+	tx, err := uc.transactionRepo.GetByID(transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if tx.Status != domain.StatusApproved {
+		return nil, domain.NewError(domain.ErrConflict, "transaction_not_approved", "only approved transactions can be written off")
+	}
+	outstanding := outstandingInstallments(tx.Installments)
+	if len(outstanding) == 0 {
+		return nil, domain.NewError(domain.ErrConflict, "nothing_outstanding", "transaction has no outstanding installments")
+	}
+
+	now := time.Now()
+	classification := classify(uc.policy, contractExposure(tx, now), now)
+	if classification.Collectibility != domain.CollectibilityLoss {
+		return nil, domain.NewError(domain.ErrConflict, "write_off_not_allowed", "only contracts classified macet can be written off")
+	}
+
+	writeOff := &domain.WriteOff{
+		TransactionID:  tx.ID,
+		CustomerID:     tx.CustomerID,
+		Collectibility: classification.Collectibility,
+		DPD:            classification.DPD,
+		Reason:         reason,
+		WrittenOffBy:   actor.ID,
+		WrittenOffAt:   now,
+		Version:        1,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+		WrittenOff:     outstanding,
+	}
+	written := make([]uint, len(outstanding))
+	for i, installment := range outstanding {
+		writeOff.Amount += installment.Amount
+		writeOff.LateFees += installment.LateFee
+		written[i] = installment.ID
+	}
+	writeOff.Amount = roundCents(writeOff.Amount)
+	writeOff.LateFees = roundCents(writeOff.LateFees)
+
+	tx.Status = domain.StatusWrittenOff
+	event, err := newOutboxEvent(domain.EventContractWrittenOff, tx.PartnerID, domain.ContractWrittenOff{
+		TransactionID:  tx.ID,
+		ContractNumber: tx.ContractNumber,
+		Status:         tx.Status,
+		Amount:         writeOff.Amount + writeOff.LateFees,
+	}, now)
+	if err != nil {
+		return nil, err
+	}
+	writeOff.Events = []domain.OutboxEvent{event}
+
+	audit, err := newAuditLog(actor, auditContractWrittenOff, "write_off", 0, map[string]interface{}{
+		"transaction_id": tx.ID,
+		"reason":         reason,
+		"dpd":            writeOff.DPD,
+		"amount":         writeOff.Amount,
+		"late_fees":      writeOff.LateFees,
+		"installments":   written,
+	}, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.writeOffRepo.Create(writeOff, audit); err != nil {
+		return nil, err
+	}
+	return writeOff, nil
+}
+
+// GetByID implements WriteOffUseCase.GetByID
+func (uc *writeOffUseCase) GetByID(id uint) (*domain.WriteOff, error) {
+	return uc.writeOffRepo.GetByID(id)
+}
+
+// List implements WriteOffUseCase.List
+func (uc *writeOffUseCase) List(offset, limit int) ([]domain.WriteOff, error) {
+	return uc.writeOffRepo.List(offset, limit)
+}
+
+// RecordRecovery implements WriteOffUseCase.RecordRecovery. Recoveries never
+// exceed the balance written off.
+func (uc *writeOffUseCase) RecordRecovery(writeOffID uint, recovery *domain.Recovery, actor domain.Actor) (*domain.WriteOff, error) {
+	if recovery.Amount <= 0 {
+		return nil, domain.NewError(domain.ErrValidation, "invalid_amount", "recovered amount must be positive")
+	}
+
+	now := time.Now()
+	if recovery.ReceivedAt.IsZero() {
+		recovery.ReceivedAt = now
+	}
+	if recovery.ReceivedAt.After(now) {
+		return nil, domain.NewError(domain.ErrValidation, "invalid_received_at", "recovery cannot be received in the future")
+	}
+
+	writeOff, err := uc.writeOffRepo.GetByID(writeOffID)
+	if err != nil {
+		return nil, err
+	}
+	if recovery.ReceivedAt.Before(writeOff.WrittenOffAt) {
+		return nil, domain.NewError(domain.ErrValidation, "invalid_received_at", "recovery cannot be received before the write-off")
+	}
+	if recovery.Amount > writeOff.Balance()+amountTolerance {
+		return nil, domain.NewError(domain.ErrValidation, "recovery_exceeds_balance", "recovered amount exceeds the balance written off")
+	}
+
+	recovery.RecordedBy = actor.ID
+	recovery.CreatedAt = now
+	event, err := newOutboxEvent(domain.EventWriteOffRecovered, nil, domain.WriteOffRecovered{
+		WriteOffID:    writeOff.ID,
+		TransactionID: writeOff.TransactionID,
+		Amount:        recovery.Amount,
+		ReceivedAt:    recovery.ReceivedAt,
+	}, now)
+	if err != nil {
+		return nil, err
+	}
+	writeOff.Events = []domain.OutboxEvent{event}
+
+	audit, err := newAuditLog(actor, auditWriteOffRecovered, "write_off", writeOff.ID, map[string]interface{}{
+		"amount":      recovery.Amount,
+		"reference":   recovery.Reference,
+		"received_at": recovery.ReceivedAt,
+	}, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.writeOffRepo.AddRecovery(writeOff, recovery, audit); err != nil {
+		return nil, err
+	}
+	writeOff.Recoveries = append(writeOff.Recoveries, *recovery)
+	return writeOff, nil
+}
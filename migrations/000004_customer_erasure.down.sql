@@ -0,0 +1,10 @@
+-- Drop triggers
+DROP TRIGGER IF EXISTS update_erasure_requests_updated_at ON erasure_requests;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_erasure_requests_status_eligible_at;
+DROP INDEX IF EXISTS idx_erasure_requests_customer_id;
+DROP INDEX IF EXISTS idx_customers_deleted_at;
+
+-- Drop tables
+DROP TABLE IF EXISTS erasure_requests;
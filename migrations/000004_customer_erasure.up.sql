@@ -0,0 +1,22 @@
+-- Create erasure_requests table
+CREATE TABLE erasure_requests (
+    id SERIAL PRIMARY KEY,
+    customer_id INTEGER NOT NULL REFERENCES customers(id),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'completed')),
+    requested_at TIMESTAMP NOT NULL,
+    eligible_at TIMESTAMP NOT NULL,
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes
+CREATE INDEX idx_customers_deleted_at ON customers(deleted_at);
+CREATE INDEX idx_erasure_requests_customer_id ON erasure_requests(customer_id);
+CREATE INDEX idx_erasure_requests_status_eligible_at ON erasure_requests(status, eligible_at);
+
+-- Create trigger to update updated_at timestamp
+CREATE TRIGGER update_erasure_requests_updated_at
+    BEFORE UPDATE ON erasure_requests
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
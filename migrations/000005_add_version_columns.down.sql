@@ -0,0 +1,3 @@
+-- Drop optimistic locking version from customers and credit limits
+ALTER TABLE credit_limits DROP COLUMN IF EXISTS version;
+ALTER TABLE customers DROP COLUMN IF EXISTS version;
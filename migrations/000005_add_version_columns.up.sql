@@ -0,0 +1,3 @@
+-- Add optimistic locking version to customers and credit limits
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE credit_limits ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
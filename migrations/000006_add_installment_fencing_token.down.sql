@@ -0,0 +1,2 @@
+-- Drop distributed lock fencing token from installments
+ALTER TABLE installments DROP COLUMN IF EXISTS fencing_token;
@@ -0,0 +1,2 @@
+-- Add distributed lock fencing token to installments
+ALTER TABLE installments ADD COLUMN IF NOT EXISTS fencing_token BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,14 @@
+-- Drop triggers
+DROP TRIGGER IF EXISTS update_partner_api_keys_updated_at ON partner_api_keys;
+DROP TRIGGER IF EXISTS update_partners_updated_at ON partners;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_transactions_partner_id;
+DROP INDEX IF EXISTS idx_partner_api_keys_partner_id;
+
+-- Drop partner reference from transactions
+ALTER TABLE transactions DROP COLUMN IF EXISTS partner_id;
+
+-- Drop tables
+DROP TABLE IF EXISTS partner_api_keys;
+DROP TABLE IF EXISTS partners;
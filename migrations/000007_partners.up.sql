@@ -0,0 +1,41 @@
+-- Create partners table
+CREATE TABLE partners (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(50) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    source VARCHAR(20) NOT NULL CHECK (source IN ('e-commerce', 'website', 'dealer')),
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'suspended')),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create partner_api_keys table
+CREATE TABLE partner_api_keys (
+    id SERIAL PRIMARY KEY,
+    partner_id INTEGER NOT NULL REFERENCES partners(id),
+    key_id VARCHAR(64) NOT NULL UNIQUE,
+    secret_encrypted TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'revoked')),
+    expires_at TIMESTAMP,
+    last_used_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Record the partner that submitted each transaction
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS partner_id INTEGER REFERENCES partners(id);
+
+-- Create indexes
+CREATE INDEX idx_partner_api_keys_partner_id ON partner_api_keys(partner_id);
+CREATE INDEX idx_transactions_partner_id ON transactions(partner_id);
+
+-- Create triggers to update updated_at timestamp
+CREATE TRIGGER update_partners_updated_at
+    BEFORE UPDATE ON partners
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_partner_api_keys_updated_at
+    BEFORE UPDATE ON partner_api_keys
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
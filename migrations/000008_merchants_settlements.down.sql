@@ -0,0 +1,18 @@
+-- Drop triggers
+DROP TRIGGER IF EXISTS update_settlements_updated_at ON settlements;
+DROP TRIGGER IF EXISTS update_merchants_updated_at ON merchants;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_settlement_items_settlement_id;
+DROP INDEX IF EXISTS idx_settlements_merchant_id_settlement_date;
+DROP INDEX IF EXISTS idx_transactions_merchant_id;
+DROP INDEX IF EXISTS idx_merchants_deleted_at;
+
+-- Drop tables
+DROP TABLE IF EXISTS settlement_items;
+DROP TABLE IF EXISTS settlements;
+
+-- Drop merchant reference from transactions
+ALTER TABLE transactions DROP COLUMN IF EXISTS merchant_id;
+
+DROP TABLE IF EXISTS merchants;
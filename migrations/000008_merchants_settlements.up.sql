@@ -0,0 +1,65 @@
+-- Create merchants table
+CREATE TABLE merchants (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(50) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('store', 'dealer')),
+    commission_rate DECIMAL(5,4) NOT NULL DEFAULT 0 CHECK (commission_rate >= 0 AND commission_rate < 1),
+    bank_name VARCHAR(100) NOT NULL,
+    bank_account_number VARCHAR(30) NOT NULL,
+    bank_account_name VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'inactive')),
+    version INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+-- Record the merchant that sold the financed asset
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS merchant_id INTEGER REFERENCES merchants(id);
+
+-- Create settlements table
+CREATE TABLE settlements (
+    id SERIAL PRIMARY KEY,
+    merchant_id INTEGER NOT NULL REFERENCES merchants(id),
+    settlement_date DATE NOT NULL,
+    transaction_count INTEGER NOT NULL,
+    gross_amount DECIMAL(15,2) NOT NULL,
+    commission_amount DECIMAL(15,2) NOT NULL,
+    net_amount DECIMAL(15,2) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'paid')),
+    payment_reference VARCHAR(100),
+    paid_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create settlement_items table
+CREATE TABLE settlement_items (
+    id SERIAL PRIMARY KEY,
+    settlement_id INTEGER NOT NULL REFERENCES settlements(id),
+    transaction_id INTEGER NOT NULL UNIQUE REFERENCES transactions(id),
+    contract_number VARCHAR(50) NOT NULL,
+    gross_amount DECIMAL(15,2) NOT NULL,
+    commission_rate DECIMAL(5,4) NOT NULL,
+    commission_amount DECIMAL(15,2) NOT NULL,
+    net_amount DECIMAL(15,2) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes
+CREATE INDEX idx_merchants_deleted_at ON merchants(deleted_at);
+CREATE INDEX idx_transactions_merchant_id ON transactions(merchant_id);
+CREATE INDEX idx_settlements_merchant_id_settlement_date ON settlements(merchant_id, settlement_date);
+CREATE INDEX idx_settlement_items_settlement_id ON settlement_items(settlement_id);
+
+-- Create triggers to update updated_at timestamp
+CREATE TRIGGER update_merchants_updated_at
+    BEFORE UPDATE ON merchants
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_settlements_updated_at
+    BEFORE UPDATE ON settlements
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,14 @@
+-- Drop triggers
+DROP TRIGGER IF EXISTS update_webhook_deliveries_updated_at ON webhook_deliveries;
+DROP TRIGGER IF EXISTS update_webhook_subscriptions_updated_at ON webhook_subscriptions;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_installments_status_due_date;
+DROP INDEX IF EXISTS idx_webhook_deliveries_due;
+DROP INDEX IF EXISTS idx_webhook_subscriptions_partner_id;
+DROP INDEX IF EXISTS idx_outbox_events_undispatched;
+
+-- Drop tables
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_subscriptions;
+DROP TABLE IF EXISTS outbox_events;
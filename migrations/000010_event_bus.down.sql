@@ -0,0 +1,8 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_outbox_events_unpublished;
+
+-- Drop tables
+DROP TABLE IF EXISTS processed_events;
+
+-- Drop columns
+ALTER TABLE outbox_events DROP COLUMN IF EXISTS published_at;
@@ -0,0 +1,13 @@
+-- Track publication of outbox events to the event bus
+ALTER TABLE outbox_events ADD COLUMN published_at TIMESTAMP;
+
+-- Create processed_events table
+CREATE TABLE processed_events (
+    consumer VARCHAR(50) NOT NULL,
+    event_id VARCHAR(50) NOT NULL,
+    processed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (consumer, event_id)
+);
+
+-- Create indexes
+CREATE INDEX idx_outbox_events_unpublished ON outbox_events(id) WHERE published_at IS NULL;
@@ -0,0 +1,10 @@
+-- Drop triggers
+DROP TRIGGER IF EXISTS update_notifications_updated_at ON notifications;
+DROP TRIGGER IF EXISTS update_notification_preferences_updated_at ON notification_preferences;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_notifications_customer_id;
+
+-- Drop tables
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS notification_preferences;
@@ -0,0 +1,14 @@
+-- Drop triggers
+DROP TRIGGER IF EXISTS update_payments_updated_at ON payments;
+DROP TRIGGER IF EXISTS update_virtual_accounts_updated_at ON virtual_accounts;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_reconciliation_mismatches_reconciliation_id;
+DROP INDEX IF EXISTS idx_payments_transaction_id;
+DROP INDEX IF EXISTS idx_payments_bank_code_paid_at;
+
+-- Drop tables
+DROP TABLE IF EXISTS reconciliation_mismatches;
+DROP TABLE IF EXISTS reconciliations;
+DROP TABLE IF EXISTS payments;
+DROP TABLE IF EXISTS virtual_accounts;
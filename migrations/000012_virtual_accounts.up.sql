@@ -0,0 +1,70 @@
+-- Create virtual_accounts table
+CREATE TABLE virtual_accounts (
+    id SERIAL PRIMARY KEY,
+    transaction_id INTEGER NOT NULL REFERENCES transactions(id),
+    bank_code VARCHAR(20) NOT NULL,
+    number VARCHAR(30) NOT NULL UNIQUE,
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'closed')),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (transaction_id, bank_code)
+);
+
+-- Create payments table
+CREATE TABLE payments (
+    id BIGSERIAL PRIMARY KEY,
+    virtual_account_id INTEGER NOT NULL REFERENCES virtual_accounts(id),
+    transaction_id INTEGER NOT NULL REFERENCES transactions(id),
+    installment_id INTEGER REFERENCES installments(id),
+    bank_code VARCHAR(20) NOT NULL,
+    reference VARCHAR(100) NOT NULL,
+    amount DECIMAL(15,2) NOT NULL,
+    paid_at TIMESTAMP NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('received', 'credited', 'rejected')),
+    reject_reason VARCHAR(50),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (bank_code, reference)
+);
+
+-- Create reconciliations table
+CREATE TABLE reconciliations (
+    id SERIAL PRIMARY KEY,
+    bank_code VARCHAR(20) NOT NULL,
+    statement_date DATE NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    format VARCHAR(10) NOT NULL CHECK (format IN ('csv', 'mt940')),
+    entries INTEGER NOT NULL,
+    matched INTEGER NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('matched', 'mismatched')),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (bank_code, statement_date)
+);
+
+-- Create reconciliation_mismatches table
+CREATE TABLE reconciliation_mismatches (
+    id BIGSERIAL PRIMARY KEY,
+    reconciliation_id INTEGER NOT NULL REFERENCES reconciliations(id) ON DELETE CASCADE,
+    kind VARCHAR(30) NOT NULL CHECK (kind IN ('missing_payment', 'missing_statement', 'amount_mismatch')),
+    reference VARCHAR(100) NOT NULL,
+    number VARCHAR(30),
+    statement_amount DECIMAL(15,2),
+    recorded_amount DECIMAL(15,2),
+    payment_id BIGINT REFERENCES payments(id)
+);
+
+-- Create indexes
+CREATE INDEX idx_payments_bank_code_paid_at ON payments(bank_code, paid_at);
+CREATE INDEX idx_payments_transaction_id ON payments(transaction_id);
+CREATE INDEX idx_reconciliation_mismatches_reconciliation_id ON reconciliation_mismatches(reconciliation_id);
+
+-- Create triggers to update updated_at timestamp
+CREATE TRIGGER update_virtual_accounts_updated_at
+    BEFORE UPDATE ON virtual_accounts
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_payments_updated_at
+    BEFORE UPDATE ON payments
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
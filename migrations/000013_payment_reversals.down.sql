@@ -0,0 +1,16 @@
+-- Drop triggers
+DROP TRIGGER IF EXISTS update_refunds_updated_at ON refunds;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_audit_logs_entity;
+DROP INDEX IF EXISTS idx_refunds_payment_id;
+DROP INDEX IF EXISTS idx_refunds_transaction_id;
+DROP INDEX IF EXISTS idx_payment_reversals_transaction_id;
+
+-- Drop tables
+DROP TABLE IF EXISTS audit_logs;
+DROP TABLE IF EXISTS refunds;
+DROP TABLE IF EXISTS payment_reversals;
+
+-- Drop late fee column
+ALTER TABLE installments DROP COLUMN IF EXISTS late_fee;
@@ -0,0 +1,57 @@
+-- Add late fee column to installments
+ALTER TABLE installments ADD COLUMN IF NOT EXISTS late_fee DECIMAL(15,2) NOT NULL DEFAULT 0;
+
+-- Create payment_reversals table
+CREATE TABLE payment_reversals (
+    id SERIAL PRIMARY KEY,
+    installment_id INTEGER NOT NULL REFERENCES installments(id),
+    transaction_id INTEGER NOT NULL REFERENCES transactions(id),
+    reason VARCHAR(20) NOT NULL CHECK (reason IN ('bounced', 'mistaken')),
+    note VARCHAR(500),
+    amount DECIMAL(15,2) NOT NULL,
+    previous_paid_at TIMESTAMP,
+    restored_status VARCHAR(20) NOT NULL CHECK (restored_status IN ('unpaid', 'overdue')),
+    late_fee DECIMAL(15,2) NOT NULL DEFAULT 0,
+    operator_id INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create refunds table
+CREATE TABLE refunds (
+    id SERIAL PRIMARY KEY,
+    transaction_id INTEGER NOT NULL REFERENCES transactions(id),
+    payment_id BIGINT REFERENCES payments(id),
+    reversal_id INTEGER REFERENCES payment_reversals(id),
+    amount DECIMAL(15,2) NOT NULL CHECK (amount > 0),
+    reason VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'completed')),
+    reference VARCHAR(100),
+    operator_id INTEGER NOT NULL,
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create audit_logs table
+CREATE TABLE audit_logs (
+    id BIGSERIAL PRIMARY KEY,
+    actor_id INTEGER NOT NULL,
+    actor_role VARCHAR(20) NOT NULL,
+    action VARCHAR(50) NOT NULL,
+    entity_type VARCHAR(50) NOT NULL,
+    entity_id INTEGER NOT NULL,
+    details JSONB,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes
+CREATE INDEX idx_payment_reversals_transaction_id ON payment_reversals(transaction_id);
+CREATE INDEX idx_refunds_transaction_id ON refunds(transaction_id);
+CREATE INDEX idx_refunds_payment_id ON refunds(payment_id);
+CREATE INDEX idx_audit_logs_entity ON audit_logs(entity_type, entity_id);
+
+-- Create trigger to update updated_at timestamp
+CREATE TRIGGER update_refunds_updated_at
+    BEFORE UPDATE ON refunds
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
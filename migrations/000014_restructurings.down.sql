@@ -0,0 +1,18 @@
+-- Drop restructuring columns
+ALTER TABLE transactions DROP COLUMN IF EXISTS restructured_amount;
+DELETE FROM installments WHERE restructuring_id IS NOT NULL;
+UPDATE installments SET status = 'unpaid' WHERE status = 'superseded';
+ALTER TABLE installments DROP CONSTRAINT IF EXISTS installments_status_check;
+ALTER TABLE installments ADD CONSTRAINT installments_status_check CHECK (status IN ('paid', 'unpaid', 'overdue'));
+ALTER TABLE installments DROP COLUMN IF EXISTS superseded_by;
+ALTER TABLE installments DROP COLUMN IF EXISTS restructuring_id;
+
+-- Drop triggers
+DROP TRIGGER IF EXISTS update_restructurings_updated_at ON restructurings;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_restructurings_pending;
+DROP INDEX IF EXISTS idx_restructurings_transaction_id;
+
+-- Drop tables
+DROP TABLE IF EXISTS restructurings;
@@ -0,0 +1,38 @@
+-- Create restructurings table
+CREATE TABLE restructurings (
+    id SERIAL PRIMARY KEY,
+    transaction_id INTEGER NOT NULL REFERENCES transactions(id),
+    type VARCHAR(20) NOT NULL CHECK (type IN ('tenor_extension', 'payment_holiday')),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+    reason VARCHAR(500) NOT NULL,
+    outstanding_amount DECIMAL(15,2) NOT NULL,
+    interest_rate DECIMAL(7,4) NOT NULL,
+    new_tenor INTEGER NOT NULL CHECK (new_tenor > 0),
+    holiday_months INTEGER NOT NULL DEFAULT 0,
+    installment_amount DECIMAL(15,2) NOT NULL,
+    first_due_date TIMESTAMP NOT NULL,
+    total_amount DECIMAL(15,2) NOT NULL,
+    requested_by INTEGER NOT NULL,
+    reviewed_by INTEGER,
+    review_note VARCHAR(500),
+    reviewed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Add restructuring columns to installments and transactions
+ALTER TABLE installments ADD COLUMN IF NOT EXISTS restructuring_id INTEGER REFERENCES restructurings(id);
+ALTER TABLE installments ADD COLUMN IF NOT EXISTS superseded_by INTEGER REFERENCES restructurings(id);
+ALTER TABLE installments DROP CONSTRAINT IF EXISTS installments_status_check;
+ALTER TABLE installments ADD CONSTRAINT installments_status_check CHECK (status IN ('paid', 'unpaid', 'overdue', 'superseded'));
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS restructured_amount DECIMAL(15,2) NOT NULL DEFAULT 0;
+
+-- Create indexes
+CREATE INDEX idx_restructurings_transaction_id ON restructurings(transaction_id);
+CREATE UNIQUE INDEX idx_restructurings_pending ON restructurings(transaction_id) WHERE status = 'pending';
+
+-- Create trigger to update updated_at timestamp
+CREATE TRIGGER update_restructurings_updated_at
+    BEFORE UPDATE ON restructurings
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,15 @@
+-- Restore the transaction statuses
+UPDATE transactions SET status = 'rejected' WHERE status = 'expired';
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_status_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_status_check CHECK (status IN ('pending', 'approved', 'rejected', 'cancelled'));
+
+-- Drop triggers
+DROP TRIGGER IF EXISTS update_underwriting_decisions_updated_at ON underwriting_decisions;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_transactions_pending;
+DROP INDEX IF EXISTS idx_underwriting_decisions_queue;
+
+-- Drop tables
+DROP TABLE IF EXISTS blacklist_entries;
+DROP TABLE IF EXISTS underwriting_decisions;
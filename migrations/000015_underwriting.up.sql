@@ -0,0 +1,37 @@
+-- Create underwriting_decisions table
+CREATE TABLE underwriting_decisions (
+    id SERIAL PRIMARY KEY,
+    transaction_id INTEGER NOT NULL UNIQUE REFERENCES transactions(id),
+    outcome VARCHAR(20) NOT NULL CHECK (outcome IN ('approve', 'refer', 'reject')),
+    status VARCHAR(20) NOT NULL CHECK (status IN ('decided', 'queued', 'approved', 'rejected', 'expired')),
+    rules JSONB NOT NULL,
+    assigned_to INTEGER,
+    reviewed_by INTEGER,
+    review_note VARCHAR(500),
+    reviewed_at TIMESTAMP,
+    due_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create blacklist_entries table
+CREATE TABLE blacklist_entries (
+    id SERIAL PRIMARY KEY,
+    nik VARCHAR(16) NOT NULL UNIQUE,
+    reason VARCHAR(500) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Add the expired transaction status
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_status_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_status_check CHECK (status IN ('pending', 'approved', 'rejected', 'cancelled', 'expired'));
+
+-- Create indexes
+CREATE INDEX idx_underwriting_decisions_queue ON underwriting_decisions(due_at) WHERE status = 'queued';
+CREATE INDEX idx_transactions_pending ON transactions(created_at) WHERE status = 'pending';
+
+-- Create trigger to update updated_at timestamp
+CREATE TRIGGER update_underwriting_decisions_updated_at
+    BEFORE UPDATE ON underwriting_decisions
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,5 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_credit_scores_customer_id;
+
+-- Drop tables
+DROP TABLE IF EXISTS credit_scores;
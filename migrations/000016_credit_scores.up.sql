@@ -0,0 +1,16 @@
+-- Create credit_scores table
+CREATE TABLE credit_scores (
+    id SERIAL PRIMARY KEY,
+    customer_id INTEGER NOT NULL REFERENCES customers(id),
+    scorecard VARCHAR(100) NOT NULL,
+    scorecard_version VARCHAR(50) NOT NULL,
+    score INTEGER NOT NULL,
+    outcome VARCHAR(20) NOT NULL CHECK (outcome IN ('approve', 'refer', 'reject')),
+    attributes JSONB NOT NULL,
+    characteristics JSONB NOT NULL,
+    reasons JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes
+CREATE INDEX idx_credit_scores_customer_id ON credit_scores(customer_id);
@@ -0,0 +1,22 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_transactions_device_created;
+DROP INDEX IF EXISTS idx_transactions_customer_created;
+DROP INDEX IF EXISTS idx_customers_identity;
+
+-- Drop screening columns
+ALTER TABLE transactions DROP COLUMN IF EXISTS risk_hits;
+ALTER TABLE transactions DROP COLUMN IF EXISTS risk_flag;
+ALTER TABLE transactions DROP COLUMN IF EXISTS device_id;
+ALTER TABLE customers DROP COLUMN IF EXISTS risk_hits;
+ALTER TABLE customers DROP COLUMN IF EXISTS risk_flag;
+ALTER TABLE customers DROP COLUMN IF EXISTS device_id;
+ALTER TABLE customers DROP COLUMN IF EXISTS phone;
+
+-- Restore NIK only blacklist entries
+DELETE FROM blacklist_entries WHERE type <> 'nik';
+ALTER TABLE blacklist_entries DROP CONSTRAINT IF EXISTS blacklist_entries_type_value_key;
+ALTER TABLE blacklist_entries DROP COLUMN IF EXISTS created_by;
+ALTER TABLE blacklist_entries DROP COLUMN IF EXISTS type;
+ALTER TABLE blacklist_entries ALTER COLUMN value TYPE VARCHAR(16);
+ALTER TABLE blacklist_entries RENAME COLUMN value TO nik;
+ALTER TABLE blacklist_entries ADD CONSTRAINT blacklist_entries_nik_key UNIQUE (nik);
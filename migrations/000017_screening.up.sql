@@ -0,0 +1,24 @@
+-- Extend blacklist_entries to phone numbers and devices
+ALTER TABLE blacklist_entries RENAME COLUMN nik TO value;
+ALTER TABLE blacklist_entries DROP CONSTRAINT IF EXISTS blacklist_entries_nik_key;
+ALTER TABLE blacklist_entries ALTER COLUMN value TYPE VARCHAR(100);
+ALTER TABLE blacklist_entries ADD COLUMN type VARCHAR(10) NOT NULL DEFAULT 'nik' CHECK (type IN ('nik', 'phone', 'device'));
+ALTER TABLE blacklist_entries ALTER COLUMN type DROP DEFAULT;
+ALTER TABLE blacklist_entries ADD COLUMN created_by INTEGER;
+ALTER TABLE blacklist_entries ADD CONSTRAINT blacklist_entries_type_value_key UNIQUE (type, value);
+
+-- Add screening columns to customers
+ALTER TABLE customers ADD COLUMN phone VARCHAR(20);
+ALTER TABLE customers ADD COLUMN device_id VARCHAR(100);
+ALTER TABLE customers ADD COLUMN risk_flag VARCHAR(10) NOT NULL DEFAULT 'clear' CHECK (risk_flag IN ('clear', 'hold'));
+ALTER TABLE customers ADD COLUMN risk_hits JSONB;
+
+-- Add screening columns to transactions
+ALTER TABLE transactions ADD COLUMN device_id VARCHAR(100);
+ALTER TABLE transactions ADD COLUMN risk_flag VARCHAR(10) NOT NULL DEFAULT 'clear' CHECK (risk_flag IN ('clear', 'hold'));
+ALTER TABLE transactions ADD COLUMN risk_hits JSONB;
+
+-- Create indexes
+CREATE INDEX idx_customers_identity ON customers(LOWER(TRIM(full_name)), date_of_birth);
+CREATE INDEX idx_transactions_customer_created ON transactions(customer_id, created_at);
+CREATE INDEX idx_transactions_device_created ON transactions(device_id, created_at) WHERE device_id IS NOT NULL AND device_id <> '';
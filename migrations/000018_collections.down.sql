@@ -0,0 +1,17 @@
+-- Drop triggers
+DROP TRIGGER IF EXISTS update_collection_accounts_updated_at ON collection_accounts;
+DROP TRIGGER IF EXISTS update_collectors_updated_at ON collectors;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_installments_overdue;
+DROP INDEX IF EXISTS idx_collection_activities_account;
+DROP INDEX IF EXISTS idx_collection_accounts_collector;
+DROP INDEX IF EXISTS idx_collection_accounts_worklist;
+
+-- Drop tables
+DROP TABLE IF EXISTS collection_activities;
+DROP TABLE IF EXISTS collection_accounts;
+DROP TABLE IF EXISTS collectors;
+
+-- Drop the region of customers
+ALTER TABLE customers DROP COLUMN IF EXISTS region;
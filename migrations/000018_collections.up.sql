@@ -0,0 +1,69 @@
+-- Add the region of domicile to customers
+ALTER TABLE customers ADD COLUMN region VARCHAR(50);
+
+-- Create collectors table
+CREATE TABLE collectors (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    level VARCHAR(10) NOT NULL CHECK (level IN ('desk', 'field', 'legal')),
+    region VARCHAR(50),
+    max_accounts INTEGER NOT NULL DEFAULT 0 CHECK (max_accounts >= 0),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create collection_accounts table
+CREATE TABLE collection_accounts (
+    id SERIAL PRIMARY KEY,
+    transaction_id INTEGER NOT NULL UNIQUE REFERENCES transactions(id),
+    customer_id INTEGER NOT NULL REFERENCES customers(id),
+    region VARCHAR(50),
+    status VARCHAR(10) NOT NULL CHECK (status IN ('open', 'closed')),
+    dpd INTEGER NOT NULL CHECK (dpd >= 0),
+    bucket VARCHAR(10) NOT NULL CHECK (bucket IN ('1-30', '31-60', '61-90', '91-180', '180+')),
+    level VARCHAR(10) NOT NULL CHECK (level IN ('desk', 'field', 'legal')),
+    collector_id INTEGER REFERENCES collectors(id),
+    overdue_amount DECIMAL(15,2) NOT NULL,
+    oldest_due_date TIMESTAMP NOT NULL,
+    promise_date TIMESTAMP,
+    promise_amount DECIMAL(15,2) NOT NULL DEFAULT 0,
+    broken_promises INTEGER NOT NULL DEFAULT 0,
+    last_activity_at TIMESTAMP,
+    escalated_at TIMESTAMP,
+    closed_at TIMESTAMP,
+    version INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create collection_activities table
+CREATE TABLE collection_activities (
+    id SERIAL PRIMARY KEY,
+    account_id INTEGER NOT NULL REFERENCES collection_accounts(id),
+    type VARCHAR(20) NOT NULL CHECK (type IN ('call', 'message', 'visit', 'note', 'promise_to_pay', 'promise_broken', 'assignment', 'escalation')),
+    outcome VARCHAR(50),
+    note VARCHAR(1000),
+    promise_date TIMESTAMP,
+    promise_amount DECIMAL(15,2),
+    actor_id INTEGER,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes
+CREATE INDEX idx_collection_accounts_worklist ON collection_accounts(status, bucket, region);
+CREATE INDEX idx_collection_accounts_collector ON collection_accounts(collector_id) WHERE status = 'open';
+CREATE INDEX idx_collection_activities_account ON collection_activities(account_id, created_at);
+CREATE INDEX idx_installments_overdue ON installments(transaction_id) WHERE status = 'overdue';
+
+-- Create triggers to update updated_at timestamp
+CREATE TRIGGER update_collectors_updated_at
+    BEFORE UPDATE ON collectors
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_collection_accounts_updated_at
+    BEFORE UPDATE ON collection_accounts
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,20 @@
+-- Drop triggers
+DROP TRIGGER IF EXISTS update_write_offs_updated_at ON write_offs;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_recoveries_received_at;
+DROP INDEX IF EXISTS idx_recoveries_write_off_id;
+DROP INDEX IF EXISTS idx_write_offs_written_off_at;
+
+-- Drop tables
+DROP TABLE IF EXISTS provision_reports;
+DROP TABLE IF EXISTS recoveries;
+DROP TABLE IF EXISTS write_offs;
+
+-- Restore written off contracts and installments
+UPDATE installments SET status = 'overdue' WHERE status = 'written_off';
+UPDATE transactions SET status = 'approved' WHERE status = 'written_off';
+ALTER TABLE installments DROP CONSTRAINT IF EXISTS installments_status_check;
+ALTER TABLE installments ADD CONSTRAINT installments_status_check CHECK (status IN ('paid', 'unpaid', 'overdue', 'superseded'));
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_status_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_status_check CHECK (status IN ('pending', 'approved', 'rejected', 'cancelled', 'expired'));
@@ -0,0 +1,61 @@
+-- Add the written off transaction and installment statuses
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_status_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_status_check CHECK (status IN ('pending', 'approved', 'rejected', 'cancelled', 'expired', 'written_off'));
+ALTER TABLE installments DROP CONSTRAINT IF EXISTS installments_status_check;
+ALTER TABLE installments ADD CONSTRAINT installments_status_check CHECK (status IN ('paid', 'unpaid', 'overdue', 'superseded', 'written_off'));
+
+-- Create write_offs table
+CREATE TABLE write_offs (
+    id SERIAL PRIMARY KEY,
+    transaction_id INTEGER NOT NULL UNIQUE REFERENCES transactions(id),
+    customer_id INTEGER NOT NULL REFERENCES customers(id),
+    collectibility SMALLINT NOT NULL CHECK (collectibility BETWEEN 1 AND 5),
+    dpd INTEGER NOT NULL,
+    amount DECIMAL(15,2) NOT NULL,
+    late_fees DECIMAL(15,2) NOT NULL,
+    recovered_amount DECIMAL(15,2) NOT NULL DEFAULT 0 CHECK (recovered_amount >= 0),
+    reason VARCHAR(500) NOT NULL,
+    written_off_by INTEGER NOT NULL,
+    written_off_at TIMESTAMP NOT NULL,
+    version INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create recoveries table
+CREATE TABLE recoveries (
+    id SERIAL PRIMARY KEY,
+    write_off_id INTEGER NOT NULL REFERENCES write_offs(id),
+    amount DECIMAL(15,2) NOT NULL CHECK (amount > 0),
+    reference VARCHAR(100),
+    note VARCHAR(500),
+    received_at TIMESTAMP NOT NULL,
+    recorded_by INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create provision_reports table
+CREATE TABLE provision_reports (
+    id SERIAL PRIMARY KEY,
+    period VARCHAR(7) NOT NULL UNIQUE,
+    as_of TIMESTAMP NOT NULL,
+    grades JSONB NOT NULL,
+    contracts INTEGER NOT NULL,
+    outstanding DECIMAL(15,2) NOT NULL,
+    provision DECIMAL(15,2) NOT NULL,
+    written_off_count INTEGER NOT NULL,
+    written_off_amount DECIMAL(15,2) NOT NULL,
+    recovered_amount DECIMAL(15,2) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes
+CREATE INDEX idx_write_offs_written_off_at ON write_offs(written_off_at);
+CREATE INDEX idx_recoveries_write_off_id ON recoveries(write_off_id);
+CREATE INDEX idx_recoveries_received_at ON recoveries(received_at);
+
+-- Create trigger to update updated_at timestamp
+CREATE TRIGGER update_write_offs_updated_at
+    BEFORE UPDATE ON write_offs
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,21 @@
+-- Drop triggers
+DROP TRIGGER IF EXISTS update_accounts_updated_at ON accounts;
+DROP TRIGGER IF EXISTS prevent_journal_lines_change ON journal_lines;
+DROP TRIGGER IF EXISTS prevent_journal_entries_change ON journal_entries;
+DROP TRIGGER IF EXISTS check_journal_lines_balanced ON journal_lines;
+
+-- Drop functions
+DROP FUNCTION IF EXISTS prevent_journal_change();
+DROP FUNCTION IF EXISTS check_journal_entry_balanced();
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_journal_lines_account_code;
+DROP INDEX IF EXISTS idx_journal_lines_entry_id;
+DROP INDEX IF EXISTS idx_journal_entries_posted_at;
+DROP INDEX IF EXISTS idx_journal_entries_reference;
+DROP INDEX IF EXISTS idx_journal_entries_transaction_id;
+
+-- Drop tables
+DROP TABLE IF EXISTS journal_lines;
+DROP TABLE IF EXISTS journal_entries;
+DROP TABLE IF EXISTS accounts;
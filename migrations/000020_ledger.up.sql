@@ -0,0 +1,95 @@
+-- Create accounts table
+CREATE TABLE accounts (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(10) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('asset', 'liability', 'equity', 'income', 'expense')),
+    normal_balance VARCHAR(10) NOT NULL CHECK (normal_balance IN ('debit', 'credit')),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Seed the chart of accounts used by the posting rules
+INSERT INTO accounts (code, name, type, normal_balance) VALUES
+    ('1101', 'Cash and banks', 'asset', 'debit'),
+    ('1201', 'Financing receivable', 'asset', 'debit'),
+    ('1202', 'Unearned interest', 'asset', 'credit'),
+    ('1203', 'Late fee receivable', 'asset', 'debit'),
+    ('2101', 'Deferred admin fee', 'liability', 'credit'),
+    ('4101', 'Interest income', 'income', 'credit'),
+    ('4102', 'Admin fee income', 'income', 'credit'),
+    ('4103', 'Late fee income', 'income', 'credit'),
+    ('4104', 'Recovery income', 'income', 'credit'),
+    ('5101', 'Write-off expense', 'expense', 'debit');
+
+-- Create journal_entries table
+CREATE TABLE journal_entries (
+    id SERIAL PRIMARY KEY,
+    type VARCHAR(30) NOT NULL CHECK (type IN ('disbursement', 'admin_fee', 'interest_accrual', 'installment_payment', 'late_fee', 'restructuring', 'write_off', 'recovery', 'reversal', 'manual')),
+    transaction_id INTEGER REFERENCES transactions(id),
+    reference VARCHAR(50),
+    description VARCHAR(500) NOT NULL,
+    posted_at TIMESTAMP NOT NULL,
+    reversal_of INTEGER UNIQUE REFERENCES journal_entries(id),
+    created_by INTEGER,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create journal_lines table
+CREATE TABLE journal_lines (
+    id SERIAL PRIMARY KEY,
+    entry_id INTEGER NOT NULL REFERENCES journal_entries(id),
+    account_code VARCHAR(10) NOT NULL REFERENCES accounts(code),
+    debit DECIMAL(15,2) NOT NULL DEFAULT 0 CHECK (debit >= 0),
+    credit DECIMAL(15,2) NOT NULL DEFAULT 0 CHECK (credit >= 0),
+    CHECK ((debit > 0) <> (credit > 0))
+);
+
+-- Create indexes
+CREATE INDEX idx_journal_entries_transaction_id ON journal_entries(transaction_id);
+CREATE INDEX idx_journal_entries_reference ON journal_entries(type, reference);
+CREATE INDEX idx_journal_entries_posted_at ON journal_entries(posted_at);
+CREATE INDEX idx_journal_lines_entry_id ON journal_lines(entry_id);
+CREATE INDEX idx_journal_lines_account_code ON journal_lines(account_code);
+
+-- Check each entry balances once the database transaction posting it commits
+CREATE OR REPLACE FUNCTION check_journal_entry_balanced()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF (SELECT SUM(debit) - SUM(credit) FROM journal_lines WHERE entry_id = NEW.entry_id) <> 0 THEN
+        RAISE EXCEPTION 'journal entry % is not balanced', NEW.entry_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ language 'plpgsql';
+
+CREATE CONSTRAINT TRIGGER check_journal_lines_balanced
+    AFTER INSERT ON journal_lines
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW
+    EXECUTE FUNCTION check_journal_entry_balanced();
+
+-- Posted entries are corrected by reversal, never changed
+CREATE OR REPLACE FUNCTION prevent_journal_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'posted journal entries cannot be changed';
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER prevent_journal_entries_change
+    BEFORE UPDATE OR DELETE ON journal_entries
+    FOR EACH ROW
+    EXECUTE FUNCTION prevent_journal_change();
+
+CREATE TRIGGER prevent_journal_lines_change
+    BEFORE UPDATE OR DELETE ON journal_lines
+    FOR EACH ROW
+    EXECUTE FUNCTION prevent_journal_change();
+
+-- Create trigger to update updated_at timestamp
+CREATE TRIGGER update_accounts_updated_at
+    BEFORE UPDATE ON accounts
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,10 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_installments_accruing;
+
+-- Drop tables
+DROP TABLE IF EXISTS interest_accruals;
+
+-- Drop columns
+ALTER TABLE installments DROP COLUMN IF EXISTS accrued_interest;
+ALTER TABLE installments DROP COLUMN IF EXISTS interest;
+ALTER TABLE installments DROP COLUMN IF EXISTS principal;
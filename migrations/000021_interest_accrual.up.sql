@@ -0,0 +1,32 @@
+-- Split installments into principal and interest
+ALTER TABLE installments ADD COLUMN principal DECIMAL(15,2) NOT NULL DEFAULT 0;
+ALTER TABLE installments ADD COLUMN interest DECIMAL(15,2) NOT NULL DEFAULT 0;
+ALTER TABLE installments ADD COLUMN accrued_interest DECIMAL(15,2) NOT NULL DEFAULT 0 CHECK (accrued_interest >= 0);
+
+-- Existing installments carry the interest of their contract in proportion
+-- to their amount; the interest of paid installments counts as accrued
+UPDATE installments i
+SET interest = ROUND(i.amount * s.interest / s.total, 2)
+FROM (
+    SELECT t.id, SUM(i.amount) AS total, GREATEST(SUM(i.amount) - t.otr_amount - t.admin_fee, 0) AS interest
+    FROM transactions t
+    JOIN installments i ON i.transaction_id = t.id
+    WHERE i.status <> 'superseded'
+    GROUP BY t.id, t.otr_amount, t.admin_fee
+) s
+WHERE i.transaction_id = s.id AND i.status <> 'superseded' AND s.total > 0;
+UPDATE installments SET principal = amount - interest;
+UPDATE installments SET accrued_interest = interest WHERE status = 'paid';
+
+-- Create interest_accruals table
+CREATE TABLE interest_accruals (
+    id SERIAL PRIMARY KEY,
+    transaction_id INTEGER NOT NULL REFERENCES transactions(id),
+    accrual_date DATE NOT NULL,
+    amount DECIMAL(15,2) NOT NULL CHECK (amount > 0),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (transaction_id, accrual_date)
+);
+
+-- Create indexes
+CREATE INDEX idx_installments_accruing ON installments(transaction_id) WHERE accrued_interest < interest;
@@ -0,0 +1,10 @@
+-- Drop triggers
+DROP TRIGGER IF EXISTS prevent_regulatory_report_change ON regulatory_reports;
+DROP FUNCTION IF EXISTS prevent_regulatory_report_change();
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_regulatory_reports_submitted;
+DROP INDEX IF EXISTS idx_regulatory_reports_period;
+
+-- Drop tables
+DROP TABLE IF EXISTS regulatory_reports;
@@ -0,0 +1,37 @@
+-- Create regulatory_reports table
+CREATE TABLE regulatory_reports (
+    id SERIAL PRIMARY KEY,
+    period VARCHAR(7) NOT NULL,
+    segment VARCHAR(3) NOT NULL CHECK (segment IN ('D01', 'F01')),
+    format VARCHAR(20) NOT NULL CHECK (format IN ('fixed_width', 'csv', 'xml')),
+    file_name VARCHAR(100) NOT NULL,
+    records INTEGER NOT NULL,
+    checksum CHAR(64) NOT NULL,
+    content BYTEA NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'generated' CHECK (status IN ('generated', 'submitted')),
+    generated_by INTEGER NOT NULL,
+    submitted_by INTEGER,
+    submitted_at TIMESTAMP,
+    submission_reference VARCHAR(100),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes
+CREATE INDEX idx_regulatory_reports_period ON regulatory_reports(period, segment);
+CREATE UNIQUE INDEX idx_regulatory_reports_submitted ON regulatory_reports(period, segment) WHERE status = 'submitted';
+
+-- Report files are kept as generated, only their submission is recorded
+CREATE OR REPLACE FUNCTION prevent_regulatory_report_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.content IS DISTINCT FROM OLD.content OR NEW.checksum IS DISTINCT FROM OLD.checksum THEN
+        RAISE EXCEPTION 'regulatory report files cannot be changed';
+    END IF;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER prevent_regulatory_report_change
+    BEFORE UPDATE ON regulatory_reports
+    FOR EACH ROW
+    EXECUTE FUNCTION prevent_regulatory_report_change();
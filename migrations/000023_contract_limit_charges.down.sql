@@ -0,0 +1,5 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_contract_limit_charges_credit_limit_id;
+
+-- Drop tables
+DROP TABLE IF EXISTS contract_limit_charges;
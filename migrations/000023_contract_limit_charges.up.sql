@@ -0,0 +1,27 @@
+-- Create contract_limit_charges table
+CREATE TABLE contract_limit_charges (
+    contract_number VARCHAR(50) PRIMARY KEY REFERENCES transactions(contract_number),
+    credit_limit_id INTEGER NOT NULL REFERENCES credit_limits(id),
+    amount DECIMAL(15,2) NOT NULL DEFAULT 0 CHECK (amount >= 0),
+    closed BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Existing contracts hold their financed amount until they are closed or
+-- paid off
+INSERT INTO contract_limit_charges (contract_number, credit_limit_id, amount, closed)
+SELECT t.contract_number, cl.id,
+       CASE WHEN s.open THEN t.otr_amount + t.admin_fee + t.restructured_amount ELSE 0 END,
+       NOT s.open
+FROM transactions t
+JOIN credit_limits cl ON cl.customer_id = t.customer_id AND cl.tenor = t.tenor
+CROSS JOIN LATERAL (
+    SELECT t.status IN ('pending', 'approved', 'written_off') AND EXISTS (
+        SELECT 1 FROM installments i
+        WHERE i.transaction_id = t.id AND i.status NOT IN ('paid', 'superseded')
+    ) AS open
+) s
+WHERE t.deleted_at IS NULL;
+
+-- Create indexes
+CREATE INDEX idx_contract_limit_charges_credit_limit_id ON contract_limit_charges(credit_limit_id);
@@ -0,0 +1,154 @@
+package tests
+
+import (
+	"testing"
+	"time"
+	"xyz-multifinance/internal/domain"
+	"xyz-multifinance/internal/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockAccrualRepository is a mock implementation of domain.AccrualRepository
+type MockAccrualRepository struct {
+	mock.Mock
+}
+
+func (m *MockAccrualRepository) ListAccruing(afterTransactionID uint, limit int) ([]uint, error) {
+	args := m.Called(afterTransactionID, limit)
+	return args.Get(0).([]uint), args.Error(1)
+}
+
+func (m *MockAccrualRepository) Accrue(accrual *domain.InterestAccrual) error {
+	args := m.Called(accrual)
+	return args.Error(0)
+}
+
+func (m *MockAccrualRepository) ListAccruals(transactionID uint, offset, limit int) ([]domain.InterestAccrual, error) {
+	args := m.Called(transactionID, offset, limit)
+	return args.Get(0).([]domain.InterestAccrual), args.Error(1)
+}
+
+func (m *MockAccrualRepository) Portfolio() (*domain.PortfolioInterest, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.PortfolioInterest), args.Error(1)
+}
+
+func TestSplitInstallments(t *testing.T) {
+	schedule := make([]domain.Installment, 12)
+	for i := range schedule {
+		schedule[i].Amount = 1000000
+	}
+
+	domain.SplitInstallments(10800000, schedule)
+
+	principal, interest := 0.0, 0.0
+	for i, installment := range schedule {
+		assert.InDelta(t, installment.Amount, installment.Principal+installment.Interest, 0.001)
+		if i > 0 {
+			assert.Less(t, installment.Interest, schedule[i-1].Interest, "interest falls with the balance")
+		}
+		principal += installment.Principal
+		interest += installment.Interest
+	}
+	assert.InDelta(t, 10800000, principal, 0.001)
+	assert.InDelta(t, 1200000, interest, 0.001)
+
+	free := []domain.Installment{{Amount: 500000}, {Amount: 500000}}
+	domain.SplitInstallments(1000000, free)
+	assert.Equal(t, 0.0, free[0].Interest)
+	assert.Equal(t, 500000.0, free[1].Principal)
+}
+
+func TestInstallment_AccruedInterestAt(t *testing.T) {
+	due := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	installment := domain.Installment{DueDate: due, Interest: 300000}
+
+	assert.Equal(t, 0.0, installment.AccruedInterestAt(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 100000.0, installment.AccruedInterestAt(time.Date(2024, 6, 11, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 300000.0, installment.AccruedInterestAt(due.AddDate(0, 0, 5)))
+}
+
+func TestAccrualUseCase_AccrueDaily(t *testing.T) {
+	now := time.Date(2024, 6, 11, 3, 0, 0, 0, time.UTC)
+	asOf := time.Date(2024, 6, 11, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Accrues Performing Contracts", func(t *testing.T) {
+		mockRepo := new(MockAccrualRepository)
+		mockTxRepo := new(MockTransactionRepository)
+		useCase := usecase.NewAccrualUseCase(mockRepo, mockTxRepo, testProvisioningPolicy)
+
+		mockRepo.On("ListAccruing", uint(0), 500).Return([]uint{1}, nil)
+		mockTxRepo.On("GetByID", uint(1)).Return(&domain.Transaction{
+			ID: 1, ContractNumber: "CTR-1", Status: domain.StatusApproved,
+			Installments: []domain.Installment{
+				{ID: 1, DueDate: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), Amount: 1000000, Interest: 300000, AccruedInterest: 300000, Status: "paid"},
+				{ID: 2, DueDate: time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), Amount: 1000000, Interest: 300000, AccruedInterest: 80000, Status: "unpaid"},
+				{ID: 3, DueDate: time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC), Amount: 1000000, Interest: 200000, Status: "unpaid"},
+			},
+		}, nil)
+		mockRepo.On("Accrue", mock.MatchedBy(func(a *domain.InterestAccrual) bool {
+			return a.TransactionID == 1 && a.AccrualDate.Equal(asOf) && a.Amount == 20000 &&
+				len(a.Accrued) == 1 && a.Accrued[0].InstallmentID == 2 && a.Accrued[0].Previous == 80000 && a.Accrued[0].Accrued == 100000 &&
+				a.Entry != nil && a.Entry.Type == domain.JournalInterestAccrual && a.Entry.PostedAt.Equal(asOf.AddDate(0, 0, -1)) &&
+				a.Entry.Validate() == nil
+		})).Return(nil)
+
+		accrued, err := useCase.AccrueDaily(now)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, accrued)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Skips Non-Performing Contracts", func(t *testing.T) {
+		mockRepo := new(MockAccrualRepository)
+		mockTxRepo := new(MockTransactionRepository)
+		useCase := usecase.NewAccrualUseCase(mockRepo, mockTxRepo, testProvisioningPolicy)
+
+		mockRepo.On("ListAccruing", uint(0), 500).Return([]uint{1}, nil)
+		mockTxRepo.On("GetByID", uint(1)).Return(&domain.Transaction{
+			ID: 1, Status: domain.StatusApproved,
+			Installments: []domain.Installment{
+				{ID: 1, DueDate: now.AddDate(0, 0, -100), Amount: 1000000, Interest: 300000, Status: "overdue"},
+				{ID: 2, DueDate: now.AddDate(0, 0, 10), Amount: 1000000, Interest: 300000, Status: "unpaid"},
+			},
+		}, nil)
+
+		accrued, err := useCase.AccrueDaily(now)
+
+		require.NoError(t, err)
+		assert.Equal(t, 0, accrued)
+		mockRepo.AssertNotCalled(t, "Accrue", mock.Anything)
+	})
+}
+
+func TestAccrualUseCase_Contract(t *testing.T) {
+	mockTxRepo := new(MockTransactionRepository)
+	useCase := usecase.NewAccrualUseCase(new(MockAccrualRepository), mockTxRepo, testProvisioningPolicy)
+
+	now := time.Now()
+	mockTxRepo.On("GetByID", uint(1)).Return(&domain.Transaction{
+		ID: 1, ContractNumber: "CTR-1", Status: domain.StatusApproved,
+		Installments: []domain.Installment{
+			{ID: 2, InstallmentNumber: 2, DueDate: now.AddDate(0, 0, 10), Amount: 1000000, Principal: 750000, Interest: 250000, AccruedInterest: 160000, Status: "unpaid"},
+			{ID: 1, InstallmentNumber: 1, DueDate: now.AddDate(0, 0, -20), Amount: 1000000, Principal: 700000, Interest: 300000, AccruedInterest: 300000, Status: "paid"},
+			{ID: 3, InstallmentNumber: 3, DueDate: now.AddDate(0, 0, 40), Amount: 1000000, Principal: 800000, Interest: 200000, Status: "superseded"},
+		},
+	}, nil)
+
+	contract, err := useCase.Contract(1)
+
+	require.NoError(t, err)
+	assert.True(t, contract.Accruing)
+	assert.Equal(t, 1450000.0, contract.Principal)
+	assert.Equal(t, 550000.0, contract.Interest)
+	assert.Equal(t, 460000.0, contract.Accrued)
+	assert.Equal(t, 300000.0, contract.Received)
+	assert.Equal(t, 1, contract.Installments[0].InstallmentNumber)
+}
@@ -0,0 +1,173 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+	"xyz-multifinance/internal/domain"
+	"xyz-multifinance/internal/usecase"
+
+	redisClient "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockAnalyticsRepository is a mock implementation of domain.AnalyticsRepository
+type MockAnalyticsRepository struct {
+	mock.Mock
+}
+
+func (m *MockAnalyticsRepository) Outstanding(asOf time.Time) (*domain.OutstandingSummary, error) {
+	args := m.Called(asOf)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.OutstandingSummary), args.Error(1)
+}
+
+func (m *MockAnalyticsRepository) Disbursements(from, to time.Time) ([]domain.Disbursement, error) {
+	args := m.Called(from, to)
+	return args.Get(0).([]domain.Disbursement), args.Error(1)
+}
+
+func (m *MockAnalyticsRepository) Utilisation() (*domain.Utilisation, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Utilisation), args.Error(1)
+}
+
+func (m *MockAnalyticsRepository) CollectionRates(from, to time.Time) ([]domain.CollectionRate, error) {
+	args := m.Called(from, to)
+	return args.Get(0).([]domain.CollectionRate), args.Error(1)
+}
+
+func (m *MockAnalyticsRepository) Vintage(from, to time.Time, dpd, maxMOB int, asOf time.Time) ([]domain.VintagePoint, error) {
+	args := m.Called(from, to, dpd, maxMOB, asOf)
+	return args.Get(0).([]domain.VintagePoint), args.Error(1)
+}
+
+func (m *MockAnalyticsRepository) RollRates(from, to time.Time) ([]domain.RollTransition, error) {
+	args := m.Called(from, to)
+	return args.Get(0).([]domain.RollTransition), args.Error(1)
+}
+
+var testAnalyticsConfig = domain.AnalyticsConfig{CacheTTL: 15 * time.Minute, VintageDPD: 30, MaxMOB: 12}
+
+func TestAnalyticsUseCase_Cache(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Computes And Caches On Miss", func(t *testing.T) {
+		mockRepo := new(MockAnalyticsRepository)
+		mockRedis := new(MockRedisClient)
+		useCase := usecase.NewAnalyticsUseCase(mockRepo, mockRedis, testAnalyticsConfig)
+
+		from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local)
+		to := time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local)
+		mockRedis.On("Get", ctx, "analytics:collection_rates:2024-01:2024-02").Return("", redisClient.Nil)
+		mockRepo.On("CollectionRates", from, to).Return([]domain.CollectionRate{
+			{Month: "2024-01", Due: 3000000, Collected: 2500000, OnTime: 2000000},
+		}, nil)
+		mockRedis.On("Set", ctx, "analytics:collection_rates:2024-01:2024-02", mock.Anything, 15*time.Minute).Return("OK", nil)
+
+		rates, err := useCase.CollectionRates(ctx, "2024-01", "2024-02")
+
+		require.NoError(t, err)
+		require.Len(t, rates, 1)
+		assert.Equal(t, 0.8333, rates[0].Rate)
+		assert.Equal(t, 0.6667, rates[0].OnTimeRate)
+		mockRedis.AssertExpectations(t)
+	})
+
+	t.Run("Serves Cached Results", func(t *testing.T) {
+		mockRepo := new(MockAnalyticsRepository)
+		mockRedis := new(MockRedisClient)
+		useCase := usecase.NewAnalyticsUseCase(mockRepo, mockRedis, testAnalyticsConfig)
+
+		mockRedis.On("Get", ctx, "analytics:outstanding").Return(`{"contracts":4,"principal":1500000.5}`, nil)
+
+		summary, err := useCase.Outstanding(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, 4, summary.Contracts)
+		assert.Equal(t, 1500000.5, summary.Principal)
+		mockRepo.AssertNotCalled(t, "Outstanding", mock.Anything)
+	})
+
+	t.Run("Bypasses An Unavailable Cache", func(t *testing.T) {
+		mockRepo := new(MockAnalyticsRepository)
+		mockRedis := new(MockRedisClient)
+		useCase := usecase.NewAnalyticsUseCase(mockRepo, mockRedis, testAnalyticsConfig)
+
+		mockRedis.On("Get", ctx, "analytics:utilisation").Return("", assert.AnError)
+		mockRepo.On("Utilisation").Return(&domain.Utilisation{Bands: []domain.UtilisationGroup{
+			{Key: "0%", Limits: 2, Amount: 10000000},
+			{Key: "51-75%", Limits: 1, Amount: 4000000, Used: 2800000},
+		}}, nil)
+		mockRedis.On("Set", ctx, "analytics:utilisation", mock.Anything, 15*time.Minute).Return("", assert.AnError)
+
+		utilisation, err := useCase.Utilisation(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, utilisation.Limits)
+		assert.Equal(t, 0.2, utilisation.Rate)
+		assert.Equal(t, 0.7, utilisation.Bands[1].Rate)
+	})
+}
+
+func TestAnalyticsUseCase_Vintage(t *testing.T) {
+	mockRepo := new(MockAnalyticsRepository)
+	useCase := usecase.NewAnalyticsUseCase(mockRepo, new(MockRedisClient), domain.AnalyticsConfig{VintageDPD: 30, MaxMOB: 12})
+
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local)
+	to := time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local)
+	mockRepo.On("Vintage", from, to, 30, 12, mock.AnythingOfType("time.Time")).Return([]domain.VintagePoint{
+		{Cohort: "2024-01", MOB: 1, Contracts: 10, Amount: 50000000},
+		{Cohort: "2024-01", MOB: 2, Contracts: 10, Amount: 50000000, Delinquent: 5000000},
+		{Cohort: "2024-02", MOB: 1, Contracts: 4, Amount: 20000000},
+	}, nil)
+
+	vintage, err := useCase.Vintage(context.Background(), "2024-01", "2024-02", 0)
+
+	require.NoError(t, err)
+	assert.Equal(t, 30, vintage.DPD)
+	require.Len(t, vintage.Cohorts, 2)
+	assert.Equal(t, 10, vintage.Cohorts[0].Contracts)
+	require.Len(t, vintage.Cohorts[0].Cells, 2)
+	assert.Equal(t, 0.1, vintage.Cohorts[0].Cells[1].Rate)
+	assert.Len(t, vintage.Cohorts[1].Cells, 1)
+
+	_, err = useCase.Vintage(context.Background(), "2024-03", "2024-01", 0)
+	assert.ErrorIs(t, err, domain.ErrValidation)
+}
+
+func TestAnalyticsUseCase_RollRate(t *testing.T) {
+	mockRepo := new(MockAnalyticsRepository)
+	useCase := usecase.NewAnalyticsUseCase(mockRepo, new(MockRedisClient), domain.AnalyticsConfig{})
+
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local)
+	to := time.Date(2024, 6, 1, 0, 0, 0, 0, time.Local)
+	mockRepo.On("RollRates", from, to).Return([]domain.RollTransition{
+		{From: domain.BucketCurrent, To: domain.BucketCurrent, Contracts: 90},
+		{From: domain.BucketCurrent, To: domain.Bucket1To30, Contracts: 8},
+		{From: domain.BucketCurrent, To: domain.BucketClosed, Contracts: 2},
+		{From: domain.Bucket1To30, To: domain.Bucket31To60, Contracts: 3},
+	}, nil)
+
+	rollRate, err := useCase.RollRate(context.Background(), "2024-05")
+
+	require.NoError(t, err)
+	require.Len(t, rollRate.Rows, len(domain.RollRateBuckets)-1)
+	current := rollRate.Rows[0]
+	assert.Equal(t, domain.BucketCurrent, current.From)
+	assert.Equal(t, 100, current.Contracts)
+	assert.Equal(t, 0.9, current.To[0].Rate)
+	assert.Equal(t, 0.08, current.To[1].Rate)
+	assert.Equal(t, 0.02, current.To[len(current.To)-1].Rate)
+	assert.Equal(t, 1.0, rollRate.Rows[1].To[2].Rate)
+
+	_, err = useCase.RollRate(context.Background(), time.Now().Format("2006-01"))
+	assert.ErrorIs(t, err, domain.ErrValidation)
+}
@@ -0,0 +1,308 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"xyz-multifinance/internal/domain"
+	"xyz-multifinance/internal/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockCollectionRepository is a mock implementation of domain.CollectionRepository
+type MockCollectionRepository struct {
+	mock.Mock
+}
+
+func (m *MockCollectionRepository) CreateCollector(collector *domain.Collector) error {
+	args := m.Called(collector)
+	return args.Error(0)
+}
+
+func (m *MockCollectionRepository) UpdateCollector(collector *domain.Collector) error {
+	args := m.Called(collector)
+	return args.Error(0)
+}
+
+func (m *MockCollectionRepository) GetCollector(id uint) (*domain.Collector, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Collector), args.Error(1)
+}
+
+func (m *MockCollectionRepository) GetCollectorByUser(userID uint) (*domain.Collector, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Collector), args.Error(1)
+}
+
+func (m *MockCollectionRepository) ListCollectors(offset, limit int) ([]domain.Collector, error) {
+	args := m.Called(offset, limit)
+	return args.Get(0).([]domain.Collector), args.Error(1)
+}
+
+func (m *MockCollectionRepository) LeastLoadedCollector(level domain.CollectionLevel, region string) (*domain.Collector, error) {
+	args := m.Called(level, region)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Collector), args.Error(1)
+}
+
+func (m *MockCollectionRepository) ListDelinquencies(afterTransactionID uint, limit int) ([]domain.Delinquency, error) {
+	args := m.Called(afterTransactionID, limit)
+	return args.Get(0).([]domain.Delinquency), args.Error(1)
+}
+
+func (m *MockCollectionRepository) GetAccount(id uint) (*domain.CollectionAccount, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.CollectionAccount), args.Error(1)
+}
+
+func (m *MockCollectionRepository) GetAccountByTransaction(transactionID uint) (*domain.CollectionAccount, error) {
+	args := m.Called(transactionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.CollectionAccount), args.Error(1)
+}
+
+func (m *MockCollectionRepository) SaveAccount(account *domain.CollectionAccount) error {
+	args := m.Called(account)
+	return args.Error(0)
+}
+
+func (m *MockCollectionRepository) CloseResolved(at time.Time) (int, error) {
+	args := m.Called(at)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockCollectionRepository) ListUnassigned(limit int) ([]domain.CollectionAccount, error) {
+	args := m.Called(limit)
+	return args.Get(0).([]domain.CollectionAccount), args.Error(1)
+}
+
+func (m *MockCollectionRepository) ListWorklist(filter domain.WorklistFilter, offset, limit int) ([]domain.CollectionAccount, error) {
+	args := m.Called(filter, offset, limit)
+	return args.Get(0).([]domain.CollectionAccount), args.Error(1)
+}
+
+var testCollectionPolicy = domain.CollectionPolicy{FieldDPD: 31, LegalDPD: 91, MaxBrokenPromises: 2}
+
+func TestBucketOf(t *testing.T) {
+	assert.Equal(t, domain.Bucket1To30, domain.BucketOf(1))
+	assert.Equal(t, domain.Bucket1To30, domain.BucketOf(30))
+	assert.Equal(t, domain.Bucket31To60, domain.BucketOf(31))
+	assert.Equal(t, domain.Bucket61To90, domain.BucketOf(90))
+	assert.Equal(t, domain.Bucket91To180, domain.BucketOf(180))
+	assert.Equal(t, domain.BucketOver180, domain.BucketOf(181))
+}
+
+func TestCollectionUseCase_Sync(t *testing.T) {
+	now := time.Date(2024, 6, 15, 9, 0, 0, 0, time.UTC)
+	notFound := domain.NewError(domain.ErrNotFound, "collection_account_not_found", "collection account not found")
+
+	t.Run("Opens Account And Assigns Collector", func(t *testing.T) {
+		mockRepo := new(MockCollectionRepository)
+		useCase := usecase.NewCollectionUseCase(mockRepo, testCollectionPolicy)
+
+		mockRepo.On("ListDelinquencies", uint(0), 100).Return([]domain.Delinquency{{
+			TransactionID: 7, CustomerID: 3, Region: "jakarta", OverdueInstallments: 1, OverdueAmount: 1050000,
+			OldestDueDate: now.AddDate(0, 0, -12),
+		}}, nil)
+		mockRepo.On("GetAccountByTransaction", uint(7)).Return(nil, notFound)
+		mockRepo.On("SaveAccount", mock.MatchedBy(func(a *domain.CollectionAccount) bool {
+			return a.ID == 0 && a.TransactionID == 7 && a.Status == domain.CollectionOpen && a.DPD == 12 &&
+				a.Bucket == domain.Bucket1To30 && a.Level == domain.LevelDesk && a.Region == "jakarta" && len(a.Logged) == 0
+		})).Return(nil).Once()
+		mockRepo.On("CloseResolved", now).Return(0, nil)
+		mockRepo.On("ListUnassigned", 100).Return([]domain.CollectionAccount{
+			{ID: 1, TransactionID: 7, Status: domain.CollectionOpen, Level: domain.LevelDesk, Region: "jakarta"},
+		}, nil)
+		mockRepo.On("LeastLoadedCollector", domain.LevelDesk, "jakarta").Return(&domain.Collector{ID: 4, Name: "Sari"}, nil)
+		mockRepo.On("SaveAccount", mock.MatchedBy(func(a *domain.CollectionAccount) bool {
+			return a.ID == 1 && a.CollectorID != nil && *a.CollectorID == 4 &&
+				len(a.Logged) == 1 && a.Logged[0].Type == domain.ActivityAssignment && a.Logged[0].ActorID == nil
+		})).Return(nil).Once()
+
+		tracked, err := useCase.Sync(now)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, tracked)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Escalates By Days Past Due", func(t *testing.T) {
+		mockRepo := new(MockCollectionRepository)
+		useCase := usecase.NewCollectionUseCase(mockRepo, testCollectionPolicy)
+
+		collectorID := uint(4)
+		mockRepo.On("ListDelinquencies", uint(0), 100).Return([]domain.Delinquency{{
+			TransactionID: 7, CustomerID: 3, OverdueAmount: 2100000, OldestDueDate: now.AddDate(0, 0, -35),
+		}}, nil)
+		mockRepo.On("GetAccountByTransaction", uint(7)).Return(&domain.CollectionAccount{
+			ID: 1, TransactionID: 7, Status: domain.CollectionOpen, Level: domain.LevelDesk, CollectorID: &collectorID, Version: 3,
+		}, nil)
+		mockRepo.On("SaveAccount", mock.MatchedBy(func(a *domain.CollectionAccount) bool {
+			return a.Level == domain.LevelField && a.Bucket == domain.Bucket31To60 && a.CollectorID == nil &&
+				a.EscalatedAt != nil && len(a.Logged) == 1 && a.Logged[0].Type == domain.ActivityEscalation
+		})).Return(nil)
+		mockRepo.On("CloseResolved", now).Return(0, nil)
+		mockRepo.On("ListUnassigned", 100).Return([]domain.CollectionAccount{}, nil)
+
+		_, err := useCase.Sync(now)
+
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Escalates On Broken Promises", func(t *testing.T) {
+		mockRepo := new(MockCollectionRepository)
+		useCase := usecase.NewCollectionUseCase(mockRepo, testCollectionPolicy)
+
+		promised := now.AddDate(0, 0, -2)
+		mockRepo.On("ListDelinquencies", uint(0), 100).Return([]domain.Delinquency{{
+			TransactionID: 7, CustomerID: 3, OverdueAmount: 1050000, OldestDueDate: now.AddDate(0, 0, -20),
+		}}, nil)
+		mockRepo.On("GetAccountByTransaction", uint(7)).Return(&domain.CollectionAccount{
+			ID: 1, TransactionID: 7, Status: domain.CollectionOpen, Level: domain.LevelDesk,
+			PromiseDate: &promised, PromiseAmount: 1050000, BrokenPromises: 1,
+		}, nil)
+		mockRepo.On("SaveAccount", mock.MatchedBy(func(a *domain.CollectionAccount) bool {
+			return a.Level == domain.LevelField && a.BrokenPromises == 0 && a.PromiseDate == nil &&
+				len(a.Logged) == 2 && a.Logged[0].Type == domain.ActivityPromiseBroken && a.Logged[1].Type == domain.ActivityEscalation
+		})).Return(nil)
+		mockRepo.On("CloseResolved", now).Return(0, nil)
+		mockRepo.On("ListUnassigned", 100).Return([]domain.CollectionAccount{}, nil)
+
+		_, err := useCase.Sync(now)
+
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Skips Concurrently Modified Account", func(t *testing.T) {
+		mockRepo := new(MockCollectionRepository)
+		useCase := usecase.NewCollectionUseCase(mockRepo, testCollectionPolicy)
+
+		mockRepo.On("ListDelinquencies", uint(0), 100).Return([]domain.Delinquency{{
+			TransactionID: 7, OldestDueDate: now.AddDate(0, 0, -3),
+		}}, nil)
+		mockRepo.On("GetAccountByTransaction", uint(7)).Return(&domain.CollectionAccount{
+			ID: 1, TransactionID: 7, Status: domain.CollectionOpen, Level: domain.LevelDesk,
+		}, nil)
+		mockRepo.On("SaveAccount", mock.Anything).Return(domain.NewError(domain.ErrConflict, "concurrent_modification", "modified concurrently"))
+		mockRepo.On("CloseResolved", now).Return(0, nil)
+		mockRepo.On("ListUnassigned", 100).Return([]domain.CollectionAccount{}, nil)
+
+		tracked, err := useCase.Sync(now)
+
+		require.NoError(t, err)
+		assert.Equal(t, 0, tracked)
+	})
+}
+
+func TestCollectionUseCase_LogActivity(t *testing.T) {
+	collectorID := uint(4)
+	collectorActor := domain.Actor{ID: 20, Role: "collector"}
+
+	t.Run("Records Promise To Pay", func(t *testing.T) {
+		mockRepo := new(MockCollectionRepository)
+		useCase := usecase.NewCollectionUseCase(mockRepo, testCollectionPolicy)
+
+		promise := time.Now().AddDate(0, 0, 3)
+		mockRepo.On("GetAccount", uint(1)).Return(&domain.CollectionAccount{
+			ID: 1, Status: domain.CollectionOpen, CollectorID: &collectorID,
+			Activities: []domain.CollectionActivity{{ID: 9, Type: domain.ActivityCall}},
+		}, nil)
+		mockRepo.On("GetCollectorByUser", uint(20)).Return(&domain.Collector{ID: collectorID, UserID: 20}, nil)
+		mockRepo.On("SaveAccount", mock.MatchedBy(func(a *domain.CollectionAccount) bool {
+			return a.PromiseDate != nil && a.PromiseAmount == 500000 && len(a.Logged) == 1 && *a.Logged[0].ActorID == 20
+		})).Return(nil)
+
+		activity := &domain.CollectionActivity{Type: domain.ActivityPromiseToPay, Outcome: "reached", PromiseDate: &promise, PromiseAmount: 500000}
+		account, err := useCase.LogActivity(1, activity, collectorActor)
+
+		require.NoError(t, err)
+		require.Len(t, account.Activities, 2)
+		assert.Equal(t, domain.ActivityPromiseToPay, account.Activities[0].Type)
+		assert.NotNil(t, account.LastActivityAt)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Rejects Past Promise", func(t *testing.T) {
+		mockRepo := new(MockCollectionRepository)
+		useCase := usecase.NewCollectionUseCase(mockRepo, testCollectionPolicy)
+
+		promise := time.Now().AddDate(0, 0, -1)
+		mockRepo.On("GetAccount", uint(1)).Return(&domain.CollectionAccount{ID: 1, Status: domain.CollectionOpen}, nil)
+
+		activity := &domain.CollectionActivity{Type: domain.ActivityPromiseToPay, PromiseDate: &promise, PromiseAmount: 500000}
+		_, err := useCase.LogActivity(1, activity, domain.Actor{ID: 1, Role: "operator"})
+
+		assert.True(t, errors.Is(err, domain.ErrValidation))
+		mockRepo.AssertNotCalled(t, "SaveAccount", mock.Anything)
+	})
+
+	t.Run("Rejects System Activity", func(t *testing.T) {
+		useCase := usecase.NewCollectionUseCase(new(MockCollectionRepository), testCollectionPolicy)
+
+		_, err := useCase.LogActivity(1, &domain.CollectionActivity{Type: domain.ActivityEscalation}, domain.Actor{ID: 1, Role: "operator"})
+
+		assert.True(t, errors.Is(err, domain.ErrValidation))
+	})
+
+	t.Run("Forbids Collector Of Another Account", func(t *testing.T) {
+		mockRepo := new(MockCollectionRepository)
+		useCase := usecase.NewCollectionUseCase(mockRepo, testCollectionPolicy)
+
+		otherID := uint(5)
+		mockRepo.On("GetAccount", uint(1)).Return(&domain.CollectionAccount{ID: 1, Status: domain.CollectionOpen, CollectorID: &otherID}, nil)
+		mockRepo.On("GetCollectorByUser", uint(20)).Return(&domain.Collector{ID: collectorID, UserID: 20}, nil)
+
+		_, err := useCase.LogActivity(1, &domain.CollectionActivity{Type: domain.ActivityCall}, collectorActor)
+
+		assert.True(t, errors.Is(err, domain.ErrForbidden))
+		mockRepo.AssertNotCalled(t, "SaveAccount", mock.Anything)
+	})
+}
+
+func TestCollectionUseCase_Worklist(t *testing.T) {
+	t.Run("Restricts Collector To Own Accounts", func(t *testing.T) {
+		mockRepo := new(MockCollectionRepository)
+		useCase := usecase.NewCollectionUseCase(mockRepo, testCollectionPolicy)
+
+		mockRepo.On("GetCollectorByUser", uint(20)).Return(&domain.Collector{ID: 4, UserID: 20}, nil)
+		mockRepo.On("ListWorklist", mock.MatchedBy(func(f domain.WorklistFilter) bool {
+			return f.Bucket == domain.Bucket31To60 && f.CollectorID != nil && *f.CollectorID == 4
+		}), 0, 10).Return([]domain.CollectionAccount{{ID: 1}}, nil)
+
+		otherID := uint(5)
+		accounts, err := useCase.Worklist(domain.WorklistFilter{Bucket: domain.Bucket31To60, CollectorID: &otherID}, domain.Actor{ID: 20, Role: "collector"}, 0, 10)
+
+		require.NoError(t, err)
+		assert.Len(t, accounts, 1)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Forbids Unregistered Collector", func(t *testing.T) {
+		mockRepo := new(MockCollectionRepository)
+		useCase := usecase.NewCollectionUseCase(mockRepo, testCollectionPolicy)
+
+		mockRepo.On("GetCollectorByUser", uint(21)).Return(nil, domain.NewError(domain.ErrNotFound, "collector_not_found", "collector not found"))
+
+		_, err := useCase.Worklist(domain.WorklistFilter{}, domain.Actor{ID: 21, Role: "collector"}, 0, 10)
+
+		assert.True(t, errors.Is(err, domain.ErrForbidden))
+	})
+}
@@ -0,0 +1,176 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"xyz-multifinance/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+var defaultCORSPolicy = middleware.CORSPolicy{
+	AllowedOrigins:   []string{"http://localhost:3000", "https://*.xyz-multifinance.com"},
+	AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE"},
+	AllowedHeaders:   []string{"Authorization", "Content-Type", "If-Match"},
+	ExposedHeaders:   []string{"ETag", "X-RateLimit-Remaining"},
+	AllowCredentials: true,
+	MaxAge:           5 * time.Minute,
+}
+
+func newCORSRouter(config middleware.CORSConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.NewErrorHandlerMiddleware(zap.NewNop()))
+	router.Use(middleware.NewCORSMiddleware(config))
+	router.GET("/api/v1/customers/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.POST("/api/v1/customers/:id/erasure-requests", func(c *gin.Context) { c.Status(http.StatusAccepted) })
+	return router
+}
+
+func corsRequest(router *gin.Engine, method, path, origin string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestCORSMiddleware(t *testing.T) {
+	router := newCORSRouter(middleware.CORSConfig{
+		Default: defaultCORSPolicy,
+		Routes: map[string]middleware.CORSPolicy{
+			"/api/v1/customers/:id/erasure-requests": {
+				AllowedOrigins: []string{"https://xyz-multifinance.com"},
+				AllowedMethods: []string{"POST"},
+				AllowedHeaders: []string{"Content-Type"},
+			},
+		},
+	})
+
+	t.Run("Request Without Origin", func(t *testing.T) {
+		w := corsRequest(router, http.MethodGet, "/api/v1/customers/1", "", nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("Allowed Origin", func(t *testing.T) {
+		w := corsRequest(router, http.MethodGet, "/api/v1/customers/1", "http://localhost:3000", nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "http://localhost:3000", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+		assert.Equal(t, "ETag, X-RateLimit-Remaining", w.Header().Get("Access-Control-Expose-Headers"))
+		assert.Contains(t, w.Header().Values("Vary"), "Origin")
+	})
+
+	t.Run("Disallowed Origin", func(t *testing.T) {
+		w := corsRequest(router, http.MethodGet, "/api/v1/customers/1", "https://evil.example.com", nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+	})
+
+	t.Run("Wildcard Subdomain", func(t *testing.T) {
+		allowed := []string{"https://app.xyz-multifinance.com", "https://staging.app.xyz-multifinance.com"}
+		for _, origin := range allowed {
+			w := corsRequest(router, http.MethodGet, "/api/v1/customers/1", origin, nil)
+			assert.Equal(t, origin, w.Header().Get("Access-Control-Allow-Origin"), origin)
+		}
+
+		rejected := []string{
+			"https://xyz-multifinance.com",             // apex is not a subdomain
+			"http://app.xyz-multifinance.com",          // scheme mismatch
+			"https://app.xyz-multifinance.com.evil.io", // suffix attack
+			"https://evilxyz-multifinance.com",
+		}
+		for _, origin := range rejected {
+			w := corsRequest(router, http.MethodGet, "/api/v1/customers/1", origin, nil)
+			assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"), origin)
+		}
+	})
+
+	t.Run("Preflight", func(t *testing.T) {
+		w := corsRequest(router, http.MethodOptions, "/api/v1/customers/1", "http://localhost:3000", map[string]string{
+			"Access-Control-Request-Method":  "PUT",
+			"Access-Control-Request-Headers": "content-type, if-match",
+		})
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, "http://localhost:3000", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "GET, POST, PUT, DELETE", w.Header().Get("Access-Control-Allow-Methods"))
+		assert.Equal(t, "content-type, if-match", w.Header().Get("Access-Control-Allow-Headers"))
+		assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+		assert.Equal(t, "300", w.Header().Get("Access-Control-Max-Age"))
+	})
+
+	t.Run("Preflight Disallowed Origin", func(t *testing.T) {
+		w := corsRequest(router, http.MethodOptions, "/api/v1/customers/1", "https://evil.example.com", map[string]string{
+			"Access-Control-Request-Method": "GET",
+		})
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"cors_origin_not_allowed"`)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("Preflight Disallowed Method", func(t *testing.T) {
+		w := corsRequest(router, http.MethodOptions, "/api/v1/customers/1", "http://localhost:3000", map[string]string{
+			"Access-Control-Request-Method": "PATCH",
+		})
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"cors_method_not_allowed"`)
+	})
+
+	t.Run("Preflight Disallowed Header", func(t *testing.T) {
+		w := corsRequest(router, http.MethodOptions, "/api/v1/customers/1", "http://localhost:3000", map[string]string{
+			"Access-Control-Request-Method":  "GET",
+			"Access-Control-Request-Headers": "X-Debug",
+		})
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"cors_header_not_allowed"`)
+	})
+
+	t.Run("Route Override", func(t *testing.T) {
+		w := corsRequest(router, http.MethodOptions, "/api/v1/customers/7/erasure-requests", "http://localhost:3000", map[string]string{
+			"Access-Control-Request-Method": "POST",
+		})
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		w = corsRequest(router, http.MethodOptions, "/api/v1/customers/7/erasure-requests", "https://xyz-multifinance.com", map[string]string{
+			"Access-Control-Request-Method": "POST",
+		})
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, "POST", w.Header().Get("Access-Control-Allow-Methods"))
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+	})
+
+	t.Run("Any Origin Without Credentials", func(t *testing.T) {
+		router := newCORSRouter(middleware.CORSConfig{
+			Default: middleware.CORSPolicy{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET"}},
+		})
+
+		w := corsRequest(router, http.MethodGet, "/api/v1/customers/1", "https://anything.example.com", nil)
+
+		assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+	})
+}
+
+func TestCORSPolicy_Validate(t *testing.T) {
+	assert.NoError(t, defaultCORSPolicy.Validate())
+	assert.Error(t, middleware.CORSPolicy{AllowedOrigins: []string{"*"}, AllowCredentials: true}.Validate())
+	assert.Error(t, middleware.CORSPolicy{AllowedOrigins: []string{"https://app.*.com"}}.Validate())
+}
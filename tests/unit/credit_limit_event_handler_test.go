@@ -0,0 +1,275 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"xyz-multifinance/internal/domain"
+	"xyz-multifinance/internal/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestEvent(t *testing.T, id string, eventType domain.EventType, data interface{}) *domain.Event {
+	encoded, err := json.Marshal(data)
+	require.NoError(t, err)
+	return &domain.Event{ID: id, Type: eventType, Data: encoded}
+}
+
+// limitStore is a customer repository keeping the credit limit and contract
+// charges saved by the use case, versioned like the database
+type limitStore struct {
+	MockCustomerRepository
+	limit     domain.CreditLimit
+	charges   map[string]domain.ContractLimitCharge
+	conflicts int // Updates failing as if another instance updated the limit first
+}
+
+func newLimitStore(amount float64) *limitStore {
+	return &limitStore{
+		limit:   domain.CreditLimit{ID: 4, CustomerID: 1, Tenor: 3, Amount: amount, Version: 1},
+		charges: map[string]domain.ContractLimitCharge{},
+	}
+}
+
+func (s *limitStore) GetCreditLimits(customerID uint) ([]domain.CreditLimit, error) {
+	return []domain.CreditLimit{s.limit}, nil
+}
+
+func (s *limitStore) GetContractLimitCharge(contractNumber string) (*domain.ContractLimitCharge, error) {
+	charge, ok := s.charges[contractNumber]
+	if !ok {
+		return nil, domain.NewError(domain.ErrNotFound, "contract_limit_charge_not_found", "contract limit charge not found")
+	}
+	return &charge, nil
+}
+
+func (s *limitStore) UpdateCreditLimit(limit *domain.CreditLimit) error {
+	if s.conflicts > 0 {
+		s.conflicts--
+		s.limit.Version++
+		return domain.NewError(domain.ErrConflict, "concurrent_modification", "concurrent modification detected")
+	}
+	if limit.Version != s.limit.Version {
+		return domain.NewError(domain.ErrConflict, "concurrent_modification", "concurrent modification detected")
+	}
+	limit.Version++
+	s.limit = *limit
+	if limit.Charge != nil {
+		s.charges[limit.Charge.ContractNumber] = *limit.Charge
+	}
+	return nil
+}
+
+func TestCreditLimitEventHandler_ContractCharges(t *testing.T) {
+	created := func(id, contract string, amount float64) *domain.Event {
+		return newTestEvent(t, id, domain.EventTransactionCreated, domain.TransactionCreated{
+			ContractNumber: contract,
+			CustomerID:     1,
+			Amount:         amount,
+			Tenor:          3,
+		})
+	}
+	closed := func(id, contract string, amount float64) *domain.Event {
+		return newTestEvent(t, id, domain.EventTransactionStatusChanged, domain.TransactionStatusChanged{
+			ContractNumber: contract,
+			CustomerID:     1,
+			From:           domain.StatusPending,
+			To:             domain.StatusRejected,
+			Amount:         amount,
+			Tenor:          3,
+		})
+	}
+
+	t.Run("Concurrent Contracts Are All Charged", func(t *testing.T) {
+		store := newLimitStore(1000000)
+		customerUseCase := usecase.NewCustomerUseCase(store, new(MockScreeningUseCase))
+		handler := usecase.NewCreditLimitEventHandler(customerUseCase, new(MockTransactionRepository))
+
+		// Both contracts pass the check before either is charged
+		for i := 0; i < 2; i++ {
+			hasLimit, err := customerUseCase.CheckCreditLimit(1, 600000, 3)
+			require.NoError(t, err)
+			require.True(t, hasLimit)
+		}
+
+		assert.NoError(t, handler.Handle(context.Background(), created("evt_a", "XYZ-A", 600000)))
+		assert.NoError(t, handler.Handle(context.Background(), created("evt_b", "XYZ-B", 600000)))
+		assert.Equal(t, float64(1200000), store.limit.UsedAmount)
+
+		// Rejecting one contract only releases its own charge
+		assert.NoError(t, handler.Handle(context.Background(), closed("evt_c", "XYZ-B", 600000)))
+		assert.Equal(t, float64(600000), store.limit.UsedAmount)
+	})
+
+	t.Run("Charge After Release Is Skipped", func(t *testing.T) {
+		store := newLimitStore(1000000)
+		handler := usecase.NewCreditLimitEventHandler(usecase.NewCustomerUseCase(store, new(MockScreeningUseCase)), new(MockTransactionRepository))
+
+		assert.NoError(t, handler.Handle(context.Background(), created("evt_a", "XYZ-A", 300000)))
+		// The rejection of B is handled before its delayed charge
+		assert.NoError(t, handler.Handle(context.Background(), closed("evt_b", "XYZ-B", 600000)))
+		assert.Equal(t, float64(300000), store.limit.UsedAmount)
+
+		assert.NoError(t, handler.Handle(context.Background(), created("evt_c", "XYZ-B", 600000)))
+		assert.Equal(t, float64(300000), store.limit.UsedAmount)
+		assert.True(t, store.charges["XYZ-B"].Closed)
+		assert.Zero(t, store.charges["XYZ-B"].Amount)
+	})
+
+	t.Run("Reopened Contract Is Charged After Release", func(t *testing.T) {
+		store := newLimitStore(1000000)
+		transactions := new(MockTransactionRepository)
+		handler := usecase.NewCreditLimitEventHandler(usecase.NewCustomerUseCase(store, new(MockScreeningUseCase)), transactions)
+
+		transactions.On("GetByID", uint(9)).Return(&domain.Transaction{ID: 9, ContractNumber: "XYZ-A", CustomerID: 1, Tenor: 3, OTRAmount: 500000}, nil)
+
+		assert.NoError(t, handler.Handle(context.Background(), created("evt_a", "XYZ-A", 500000)))
+		assert.NoError(t, handler.Handle(context.Background(), newTestEvent(t, "evt_b", domain.EventContractPaidOff, domain.ContractEvent{TransactionID: 9})))
+		assert.Zero(t, store.limit.UsedAmount)
+
+		assert.NoError(t, handler.Handle(context.Background(), newTestEvent(t, "evt_c", domain.EventContractReopened, domain.ContractEvent{TransactionID: 9})))
+		assert.Equal(t, float64(500000), store.limit.UsedAmount)
+	})
+}
+
+func TestCreditLimitEventHandler_Handle(t *testing.T) {
+	t.Run("Transaction Created Charges Limit", func(t *testing.T) {
+		mockCustomerUseCase := new(MockCustomerUseCase)
+		handler := usecase.NewCreditLimitEventHandler(mockCustomerUseCase, new(MockTransactionRepository))
+
+		mockCustomerUseCase.On("AdjustCreditLimitUsage", mock.MatchedBy(func(a *domain.LimitAdjustment) bool {
+			return a.CustomerID == 1 && a.Tenor == 3 && a.Delta == 1100000 && a.Force &&
+				a.Source.Consumer == handler.Group() && a.Source.EventID == "evt_1"
+		})).Return(nil)
+
+		err := handler.Handle(context.Background(), newTestEvent(t, "evt_1", domain.EventTransactionCreated, domain.TransactionCreated{
+			ContractNumber: "XYZ-1-1",
+			CustomerID:     1,
+			Amount:         1100000,
+			Tenor:          3,
+		}))
+
+		assert.NoError(t, err)
+		mockCustomerUseCase.AssertExpectations(t)
+	})
+
+	t.Run("Rejection Releases Limit", func(t *testing.T) {
+		mockCustomerUseCase := new(MockCustomerUseCase)
+		handler := usecase.NewCreditLimitEventHandler(mockCustomerUseCase, new(MockTransactionRepository))
+
+		mockCustomerUseCase.On("AdjustCreditLimitUsage", mock.MatchedBy(func(a *domain.LimitAdjustment) bool {
+			return a.Delta == -1100000 && a.Reason == "transaction_rejected"
+		})).Return(nil)
+
+		err := handler.Handle(context.Background(), newTestEvent(t, "evt_2", domain.EventTransactionStatusChanged, domain.TransactionStatusChanged{
+			CustomerID: 1,
+			From:       domain.StatusPending,
+			To:         domain.StatusRejected,
+			Amount:     1100000,
+			Tenor:      3,
+		}))
+
+		assert.NoError(t, err)
+		mockCustomerUseCase.AssertExpectations(t)
+	})
+
+	t.Run("Approval Keeps Limit", func(t *testing.T) {
+		mockCustomerUseCase := new(MockCustomerUseCase)
+		handler := usecase.NewCreditLimitEventHandler(mockCustomerUseCase, new(MockTransactionRepository))
+
+		err := handler.Handle(context.Background(), newTestEvent(t, "evt_3", domain.EventTransactionStatusChanged, domain.TransactionStatusChanged{
+			From: domain.StatusPending,
+			To:   domain.StatusApproved,
+		}))
+
+		assert.NoError(t, err)
+		mockCustomerUseCase.AssertNotCalled(t, "AdjustCreditLimitUsage", mock.Anything)
+	})
+
+	t.Run("Redelivered Event Is Skipped", func(t *testing.T) {
+		mockCustomerUseCase := new(MockCustomerUseCase)
+		handler := usecase.NewCreditLimitEventHandler(mockCustomerUseCase, new(MockTransactionRepository))
+
+		mockCustomerUseCase.On("AdjustCreditLimitUsage", mock.Anything).Return(domain.ErrEventProcessed)
+
+		err := handler.Handle(context.Background(), newTestEvent(t, "evt_1", domain.EventTransactionCreated, domain.TransactionCreated{
+			CustomerID: 1,
+			Amount:     1100000,
+			Tenor:      3,
+		}))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("Paid Off Contract Releases Limit", func(t *testing.T) {
+		mockCustomerUseCase := new(MockCustomerUseCase)
+		mockTransactionRepo := new(MockTransactionRepository)
+		handler := usecase.NewCreditLimitEventHandler(mockCustomerUseCase, mockTransactionRepo)
+
+		mockTransactionRepo.On("GetByID", uint(7)).Return(&domain.Transaction{
+			ID:             7,
+			ContractNumber: "XYZ-1-1",
+			CustomerID:     1,
+			OTRAmount:      1000000,
+			AdminFee:       100000,
+			Tenor:          3,
+		}, nil)
+		mockCustomerUseCase.On("AdjustCreditLimitUsage", mock.MatchedBy(func(a *domain.LimitAdjustment) bool {
+			return a.CustomerID == 1 && a.Tenor == 3 && a.Delta == -1100000 && !a.Force &&
+				a.Reason == string(domain.EventContractPaidOff)
+		})).Return(nil)
+
+		err := handler.Handle(context.Background(), newTestEvent(t, "evt_4", domain.EventContractPaidOff, domain.ContractEvent{
+			TransactionID: 7,
+		}))
+
+		assert.NoError(t, err)
+		mockCustomerUseCase.AssertExpectations(t)
+	})
+
+	t.Run("Reopened Contract Is Charged Again", func(t *testing.T) {
+		mockCustomerUseCase := new(MockCustomerUseCase)
+		mockTransactionRepo := new(MockTransactionRepository)
+		handler := usecase.NewCreditLimitEventHandler(mockCustomerUseCase, mockTransactionRepo)
+
+		mockTransactionRepo.On("GetByID", uint(7)).Return(&domain.Transaction{
+			ID:         7,
+			CustomerID: 1,
+			OTRAmount:  1000000,
+			AdminFee:   100000,
+			Tenor:      3,
+		}, nil)
+		mockCustomerUseCase.On("AdjustCreditLimitUsage", mock.MatchedBy(func(a *domain.LimitAdjustment) bool {
+			return a.Delta == 1100000 && a.Force
+		})).Return(nil)
+
+		err := handler.Handle(context.Background(), newTestEvent(t, "evt_5", domain.EventContractReopened, domain.ContractEvent{
+			TransactionID: 7,
+		}))
+
+		assert.NoError(t, err)
+		mockCustomerUseCase.AssertExpectations(t)
+	})
+
+	t.Run("Restructuring Charges Added Amount", func(t *testing.T) {
+		mockCustomerUseCase := new(MockCustomerUseCase)
+		mockTransactionRepo := new(MockTransactionRepository)
+		handler := usecase.NewCreditLimitEventHandler(mockCustomerUseCase, mockTransactionRepo)
+
+		mockTransactionRepo.On("GetByID", uint(7)).Return(&domain.Transaction{ID: 7, CustomerID: 1, Tenor: 3}, nil)
+		mockCustomerUseCase.On("AdjustCreditLimitUsage", mock.MatchedBy(func(a *domain.LimitAdjustment) bool {
+			return a.Tenor == 3 && a.Delta == 121200 && a.Force
+		})).Return(nil)
+
+		err := handler.Handle(context.Background(), newTestEvent(t, "evt_6", domain.EventContractRestructured, domain.ContractRestructured{
+			TransactionID: 7,
+			AddedAmount:   121200,
+		}))
+
+		assert.NoError(t, err)
+		mockCustomerUseCase.AssertExpectations(t)
+	})
+}
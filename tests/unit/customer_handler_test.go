@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	httpHandler "xyz-multifinance/internal/delivery/http"
+	"xyz-multifinance/internal/domain"
+	"xyz-multifinance/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+func newCustomerRouter(customerUseCase domain.CustomerUseCase) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.NewErrorHandlerMiddleware(zap.NewNop()))
+	httpHandler.NewCustomerHandler(router, customerUseCase, noRateLimit)
+	return router
+}
+
+func TestCustomerHandler_Delete_BackOffice(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUseCase := new(MockCustomerUseCase)
+	router := gin.New()
+	router.Use(middleware.NewErrorHandlerMiddleware(zap.NewNop()))
+	httpHandler.NewCustomerHandler(router, mockUseCase, noRateLimit, middleware.RequireRole("admin"))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/customers/1", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockUseCase.AssertNotCalled(t, "Delete", mock.Anything)
+}
+
+func TestCustomerHandler_GetProfile_ETag(t *testing.T) {
+	mockUseCase := new(MockCustomerUseCase)
+	router := newCustomerRouter(mockUseCase)
+
+	mockUseCase.On("GetProfile", uint(1)).Return(&domain.Customer{ID: 1, Version: 3}, nil)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/customers/1", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+}
+
+func TestCustomerHandler_UpdateProfile_IfMatch(t *testing.T) {
+	body := `{"full_name":"John Doe","legal_name":"John Doe","salary":6000000}`
+
+	t.Run("Missing If-Match", func(t *testing.T) {
+		mockUseCase := new(MockCustomerUseCase)
+		router := newCustomerRouter(mockUseCase)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/customers/1", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusPreconditionRequired, w.Code)
+		mockUseCase.AssertNotCalled(t, "UpdateProfile", mock.Anything)
+	})
+
+	t.Run("Version Passed To Use Case", func(t *testing.T) {
+		mockUseCase := new(MockCustomerUseCase)
+		router := newCustomerRouter(mockUseCase)
+
+		mockUseCase.On("UpdateProfile", mock.MatchedBy(func(c *domain.Customer) bool {
+			return c.ID == 1 && c.Version == 2
+		})).Run(func(args mock.Arguments) {
+			args.Get(0).(*domain.Customer).Version = 3
+		}).Return(nil)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/customers/1", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", `"2"`)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+		mockUseCase.AssertExpectations(t)
+	})
+
+	t.Run("Stale Version", func(t *testing.T) {
+		mockUseCase := new(MockCustomerUseCase)
+		router := newCustomerRouter(mockUseCase)
+
+		mockUseCase.On("UpdateProfile", mock.AnythingOfType("*domain.Customer")).
+			Return(domain.NewError(domain.ErrPreconditionFailed, "version_mismatch", "resource has been modified"))
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/customers/1", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", `W/"1"`)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	})
+}
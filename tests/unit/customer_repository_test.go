@@ -51,6 +51,11 @@ func TestCustomerRepository_Create(t *testing.T) {
 				customer.Salary,
 				customer.KTPPhoto,
 				customer.SelfiePhoto,
+				customer.Phone,
+				customer.Region,
+				customer.DeviceID,
+				sqlmock.AnyArg(), // risk_flag
+				sqlmock.AnyArg(), // risk_hits
 				sqlmock.AnyArg(), // version
 				sqlmock.AnyArg(), // created_at
 				sqlmock.AnyArg(), // updated_at
@@ -88,6 +93,11 @@ func TestCustomerRepository_Create(t *testing.T) {
 				customer.Salary,
 				customer.KTPPhoto,
 				customer.SelfiePhoto,
+				customer.Phone,
+				customer.Region,
+				customer.DeviceID,
+				sqlmock.AnyArg(), // risk_flag
+				sqlmock.AnyArg(), // risk_hits
 				sqlmock.AnyArg(), // version
 				sqlmock.AnyArg(), // created_at
 				sqlmock.AnyArg(), // updated_at
@@ -140,7 +150,7 @@ func TestCustomerRepository_GetByID(t *testing.T) {
 			"created_at", "updated_at", "deleted_at", "version",
 		})
 
-		mock.ExpectQuery(`SELECT \* FROM "customers" WHERE "customers"."id" = \$1 ORDER BY "customers"."id" LIMIT \$2`).
+		mock.ExpectQuery(`SELECT \* FROM "customers" WHERE "customers"."id" = \$1 AND "customers"."deleted_at" IS NULL ORDER BY "customers"."id" LIMIT \$2`).
 			WithArgs(id, 1).
 			WillReturnRows(customerRows)
 
@@ -159,13 +169,13 @@ func TestCustomerRepository_GetByID(t *testing.T) {
 	t.Run("Not Found", func(t *testing.T) {
 		id := uint(1)
 
-		mock.ExpectQuery(`SELECT \* FROM "customers" WHERE "customers"."id" = \$1 ORDER BY "customers"."id" LIMIT \$2`).
+		mock.ExpectQuery(`SELECT \* FROM "customers" WHERE "customers"."id" = \$1 AND "customers"."deleted_at" IS NULL ORDER BY "customers"."id" LIMIT \$2`).
 			WithArgs(id, 1).
 			WillReturnRows(sqlmock.NewRows([]string{}))
 
 		customer, err := repo.GetByID(id)
 
-		assert.Error(t, err)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
 		assert.Nil(t, customer)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
@@ -274,7 +284,7 @@ func TestCustomerRepository_Update(t *testing.T) {
 			WithArgs(customer.ID, 1).
 			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(1))
 
-		mock.ExpectExec(`UPDATE "customers" SET "nik"=\$1,"full_name"=\$2,"legal_name"=\$3,"place_of_birth"=\$4,"date_of_birth"=\$5,"salary"=\$6,"ktp_photo"=\$7,"selfie_photo"=\$8,"version"=\$9,"created_at"=\$10,"updated_at"=\$11,"deleted_at"=\$12 WHERE "id" = \$13`).
+		mock.ExpectExec(`UPDATE "customers" SET "nik"=\$1,"full_name"=\$2,"legal_name"=\$3,"place_of_birth"=\$4,"date_of_birth"=\$5,"salary"=\$6,"ktp_photo"=\$7,"selfie_photo"=\$8,"phone"=\$9,"region"=\$10,"device_id"=\$11,"risk_flag"=\$12,"risk_hits"=\$13,"version"=\$14,"created_at"=\$15,"updated_at"=\$16,"deleted_at"=\$17 WHERE "customers"."deleted_at" IS NULL AND "id" = \$18`).
 			WithArgs(
 				customer.NIK,
 				customer.FullName,
@@ -284,6 +294,11 @@ func TestCustomerRepository_Update(t *testing.T) {
 				customer.Salary,
 				customer.KTPPhoto,
 				customer.SelfiePhoto,
+				customer.Phone,
+				customer.Region,
+				customer.DeviceID,
+				customer.RiskFlag,
+				sqlmock.AnyArg(), // risk_hits
 				customer.Version+1,
 				sqlmock.AnyArg(), // created_at
 				sqlmock.AnyArg(), // updated_at
@@ -322,7 +337,7 @@ func TestCustomerRepository_Update(t *testing.T) {
 		err := repo.Update(customer)
 
 		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "concurrent modification detected")
+		assert.ErrorIs(t, err, domain.ErrConflict)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
@@ -454,7 +469,123 @@ func TestCustomerRepository_UpdateCreditLimit(t *testing.T) {
 		err := repo.UpdateCreditLimit(limit)
 
 		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "concurrent modification detected")
+		assert.ErrorIs(t, err, domain.ErrConflict)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCustomerRepository_Delete(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{
+		Conn: db,
+	}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening gorm database", err)
+	}
+
+	repo := repository.NewCustomerRepository(gormDB)
+
+	lockQuery := `SELECT id FROM "customers" WHERE "customers"\."id" = \$1 AND "customers"\."deleted_at" IS NULL ORDER BY "customers"\."id" LIMIT \$2 FOR UPDATE`
+	activeQuery := `SELECT COUNT\(\*\) FROM "transactions" WHERE "customer_id" = \$1`
+
+	t.Run("Soft Delete", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(lockQuery).
+			WithArgs(1, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectQuery(activeQuery).
+			WithArgs(1, domain.StatusPending, domain.StatusApproved, "unpaid", "overdue").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectExec(`UPDATE "customers" SET "deleted_at"=\$1 WHERE "customers"\."id" = \$2 AND "customers"\."deleted_at" IS NULL`).
+			WithArgs(sqlmock.AnyArg(), 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := repo.Delete(1)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Active Contracts", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(lockQuery).
+			WithArgs(1, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectQuery(activeQuery).
+			WithArgs(1, domain.StatusPending, domain.StatusApproved, "unpaid", "overdue").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectRollback()
+
+		err := repo.Delete(1)
+
+		assert.ErrorIs(t, err, domain.ErrConflict)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(lockQuery).
+			WithArgs(1, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectRollback()
+
+		err := repo.Delete(1)
+
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCustomerRepository_Anonymize(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{
+		Conn: db,
+	}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening gorm database", err)
+	}
+
+	repo := repository.NewCustomerRepository(gormDB)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE "customers" SET "nik"=\$1,"full_name"=\$2,"legal_name"=\$3,"place_of_birth"=\$4,.*"salary"=0,.*"region"=\$9,"risk_hits"=NULL,`).
+			WithArgs(
+				"ANON000000000001",
+				"ANONYMIZED",
+				"ANONYMIZED",
+				"ANONYMIZED",
+				"",               // ktp_photo
+				"",               // selfie_photo
+				"",               // phone
+				"",               // device_id
+				"",               // region
+				sqlmock.AnyArg(), // updated_at
+				1,
+			).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`DELETE FROM "notification_preferences" WHERE customer_id = \$1`).
+			WithArgs(1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`UPDATE "notifications" SET "recipient"=\$1,"updated_at"=\$2 WHERE customer_id = \$3`).
+			WithArgs("ANONYMIZED", sqlmock.AnyArg(), 1).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectCommit()
+
+		err := repo.Anonymize(1)
+
+		assert.NoError(t, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
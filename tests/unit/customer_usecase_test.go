@@ -1,7 +1,6 @@
 package tests
 
 import (
-	"errors"
 	"testing"
 	"time"
 	"xyz-multifinance/internal/domain"
@@ -36,6 +35,14 @@ func (m *MockCustomerRepository) GetByNIK(nik string) (*domain.Customer, error)
 	return args.Get(0).(*domain.Customer), args.Error(1)
 }
 
+func (m *MockCustomerRepository) GetByIDUnscoped(id uint) (*domain.Customer, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Customer), args.Error(1)
+}
+
 func (m *MockCustomerRepository) Update(customer *domain.Customer) error {
 	args := m.Called(customer)
 	return args.Error(0)
@@ -46,6 +53,16 @@ func (m *MockCustomerRepository) Delete(id uint) error {
 	return args.Error(0)
 }
 
+func (m *MockCustomerRepository) HasActiveContracts(customerID uint) (bool, error) {
+	args := m.Called(customerID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockCustomerRepository) Anonymize(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
 func (m *MockCustomerRepository) List(offset, limit int) ([]domain.Customer, error) {
 	args := m.Called(offset, limit)
 	return args.Get(0).([]domain.Customer), args.Error(1)
@@ -56,6 +73,14 @@ func (m *MockCustomerRepository) GetCreditLimits(customerID uint) ([]domain.Cred
 	return args.Get(0).([]domain.CreditLimit), args.Error(1)
 }
 
+func (m *MockCustomerRepository) GetContractLimitCharge(contractNumber string) (*domain.ContractLimitCharge, error) {
+	args := m.Called(contractNumber)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ContractLimitCharge), args.Error(1)
+}
+
 func (m *MockCustomerRepository) UpdateCreditLimit(limit *domain.CreditLimit) error {
 	args := m.Called(limit)
 	return args.Error(0)
@@ -64,7 +89,8 @@ func (m *MockCustomerRepository) UpdateCreditLimit(limit *domain.CreditLimit) er
 func TestCustomerUseCase_Register(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		mockRepo := new(MockCustomerRepository)
-		useCase := usecase.NewCustomerUseCase(mockRepo)
+		mockScreening := new(MockScreeningUseCase)
+		useCase := usecase.NewCustomerUseCase(mockRepo, mockScreening)
 
 		customer := &domain.Customer{
 			NIK:          "1234567890123456",
@@ -77,7 +103,8 @@ func TestCustomerUseCase_Register(t *testing.T) {
 			SelfiePhoto:  "selfie.jpg",
 		}
 
-		mockRepo.On("GetByNIK", customer.NIK).Return(nil, errors.New("not found"))
+		mockRepo.On("GetByNIK", customer.NIK).Return(nil, domain.ErrNotFound)
+		mockScreening.On("ScreenCustomer", customer).Return(&domain.ScreeningResult{Flag: domain.RiskClear}, nil)
 		mockRepo.On("Create", mock.AnythingOfType("*domain.Customer")).Return(nil)
 
 		err := useCase.Register(customer)
@@ -88,7 +115,7 @@ func TestCustomerUseCase_Register(t *testing.T) {
 
 	t.Run("NIK Already Exists", func(t *testing.T) {
 		mockRepo := new(MockCustomerRepository)
-		useCase := usecase.NewCustomerUseCase(mockRepo)
+		useCase := usecase.NewCustomerUseCase(mockRepo, new(MockScreeningUseCase))
 
 		customer := &domain.Customer{
 			NIK:          "1234567890123456",
@@ -115,14 +142,72 @@ func TestCustomerUseCase_Register(t *testing.T) {
 		err := useCase.Register(customer)
 
 		assert.Error(t, err)
+		assert.ErrorIs(t, err, domain.ErrConflict)
 		assert.Equal(t, "customer with this NIK already exists", err.Error())
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("NIK Of Deleted Customer", func(t *testing.T) {
+		mockRepo := new(MockCustomerRepository)
+		useCase := usecase.NewCustomerUseCase(mockRepo, new(MockScreeningUseCase))
+
+		deleted := &domain.Customer{ID: 1, NIK: "1234567890123456"}
+		deleted.DeletedAt.Time, deleted.DeletedAt.Valid = time.Now(), true
+		mockRepo.On("GetByNIK", deleted.NIK).Return(deleted, nil).Once()
+
+		err := useCase.Register(&domain.Customer{NIK: deleted.NIK})
+
+		assert.ErrorIs(t, err, domain.ErrConflict)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+}
+
+func TestCustomerUseCase_UpdateProfile(t *testing.T) {
+	t.Run("Uses Client Version", func(t *testing.T) {
+		mockRepo := new(MockCustomerRepository)
+		useCase := usecase.NewCustomerUseCase(mockRepo, new(MockScreeningUseCase))
+
+		mockRepo.On("GetByID", uint(1)).Return(&domain.Customer{ID: 1, Version: 5}, nil)
+		mockRepo.On("Update", mock.MatchedBy(func(c *domain.Customer) bool {
+			return c.Version == 4
+		})).Return(domain.NewError(domain.ErrConflict, "concurrent_modification", "concurrent modification detected"))
+
+		err := useCase.UpdateProfile(&domain.Customer{ID: 1, FullName: "John Doe", Version: 4})
+
+		assert.ErrorIs(t, err, domain.ErrPreconditionFailed)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestCustomerUseCase_Delete(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockCustomerRepository)
+		useCase := usecase.NewCustomerUseCase(mockRepo, new(MockScreeningUseCase))
+
+		mockRepo.On("Delete", uint(1)).Return(nil)
+
+		err := useCase.Delete(1)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Active Contracts", func(t *testing.T) {
+		mockRepo := new(MockCustomerRepository)
+		useCase := usecase.NewCustomerUseCase(mockRepo, new(MockScreeningUseCase))
+
+		mockRepo.On("Delete", uint(1)).Return(domain.NewError(domain.ErrConflict, "customer_has_active_contracts", "customer has active contracts"))
+
+		err := useCase.Delete(1)
+
+		assert.ErrorIs(t, err, domain.ErrConflict)
+		mockRepo.AssertExpectations(t)
+	})
 }
 
 func TestCustomerUseCase_UpdateCreditLimitUsage(t *testing.T) {
 	mockRepo := new(MockCustomerRepository)
-	useCase := usecase.NewCustomerUseCase(mockRepo)
+	useCase := usecase.NewCustomerUseCase(mockRepo, new(MockScreeningUseCase))
 
 	t.Run("Success", func(t *testing.T) {
 		customerID := uint(1)
@@ -166,14 +251,58 @@ func TestCustomerUseCase_UpdateCreditLimitUsage(t *testing.T) {
 		err := useCase.UpdateCreditLimitUsage(customerID, amount, tenor)
 
 		assert.Error(t, err)
-		assert.Equal(t, "insufficient credit limit", err.Error())
+		assert.ErrorIs(t, err, domain.ErrInsufficientLimit)
 		mockRepo.AssertExpectations(t)
 	})
 }
 
+func TestCustomerUseCase_AdjustCreditLimitUsage(t *testing.T) {
+	t.Run("Release Stops At Zero", func(t *testing.T) {
+		mockRepo := new(MockCustomerRepository)
+		useCase := usecase.NewCustomerUseCase(mockRepo, new(MockScreeningUseCase))
+
+		source := &domain.ProcessedEvent{Consumer: "credit-limit", EventID: "evt_1"}
+		mockRepo.On("GetCreditLimits", uint(1)).Return([]domain.CreditLimit{
+			{ID: 4, CustomerID: 1, Tenor: 3, Amount: 2000000, UsedAmount: 500000},
+		}, nil)
+		mockRepo.On("UpdateCreditLimit", mock.MatchedBy(func(l *domain.CreditLimit) bool {
+			return l.UsedAmount == 0 &&
+				l.Processed == source &&
+				len(l.Events) == 1 && l.Events[0].EventType == domain.EventLimitAdjusted
+		})).Return(nil)
+
+		err := useCase.AdjustCreditLimitUsage(&domain.LimitAdjustment{
+			CustomerID: 1,
+			Tenor:      3,
+			Delta:      -800000,
+			Source:     source,
+		})
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestCustomerUseCase_AdjustCreditLimitUsage_RetriesConflict(t *testing.T) {
+	store := newLimitStore(1000000)
+	store.conflicts = 2
+	useCase := usecase.NewCustomerUseCase(store, new(MockScreeningUseCase))
+
+	err := useCase.AdjustCreditLimitUsage(&domain.LimitAdjustment{
+		CustomerID:     1,
+		Tenor:          3,
+		Delta:          400000,
+		ContractNumber: "XYZ-A",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, float64(400000), store.limit.UsedAmount)
+	assert.Equal(t, float64(400000), store.charges["XYZ-A"].Amount)
+}
+
 func TestCustomerUseCase_CheckCreditLimit(t *testing.T) {
 	mockRepo := new(MockCustomerRepository)
-	useCase := usecase.NewCustomerUseCase(mockRepo)
+	useCase := usecase.NewCustomerUseCase(mockRepo, new(MockScreeningUseCase))
 
 	t.Run("Has Sufficient Limit", func(t *testing.T) {
 		customerID := uint(1)
@@ -0,0 +1,197 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"xyz-multifinance/internal/pkg/redis"
+
+	redisClient "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	lockKeys  = []string{"lock:resource", "fence:resource"}
+	ownerKeys = []string{"lock:resource"}
+)
+
+func isScript(command string) interface{} {
+	return mock.MatchedBy(func(script string) bool {
+		return strings.Contains(script, command)
+	})
+}
+
+func TestDistributedLock_Lock(t *testing.T) {
+	t.Run("Issues Fencing Token", func(t *testing.T) {
+		client := new(MockRedisClient)
+		client.On("Eval", mock.Anything, isScript("incr"), lockKeys, mock.Anything).
+			Return(redisClient.NewIntResult(7, nil))
+		client.On("Eval", mock.Anything, isScript("del"), ownerKeys, mock.Anything).
+			Return(redisClient.NewIntResult(1, nil))
+
+		lock := redis.NewDistributedLock(client, "resource", time.Second)
+
+		assert.NoError(t, lock.Lock(context.Background()))
+		assert.Equal(t, int64(7), lock.FencingToken())
+		assert.NoError(t, lock.Unlock(context.Background()))
+	})
+
+	t.Run("Token Issued Above Fence Floor", func(t *testing.T) {
+		client := new(MockRedisClient)
+		client.On("Eval", mock.Anything, isScript("incr"), lockKeys, mock.MatchedBy(func(args []interface{}) bool {
+			return len(args) == 3 && args[2] == int64(9)
+		})).Return(redisClient.NewIntResult(10, nil))
+
+		lock := redis.NewDistributedLock(client, "resource", time.Second, redis.WithFenceFloor(9))
+
+		assert.NoError(t, lock.Lock(context.Background()))
+		assert.Equal(t, int64(10), lock.FencingToken())
+		client.AssertExpectations(t)
+	})
+
+	t.Run("Unique Owner Tokens", func(t *testing.T) {
+		var owners []string
+		client := new(MockRedisClient)
+		client.On("Eval", mock.Anything, isScript("incr"), lockKeys, mock.Anything).
+			Run(func(args mock.Arguments) {
+				owners = append(owners, args.Get(3).([]interface{})[0].(string))
+			}).
+			Return(redisClient.NewIntResult(1, nil))
+
+		assert.NoError(t, redis.NewDistributedLock(client, "resource", time.Second).Lock(context.Background()))
+		assert.NoError(t, redis.NewDistributedLock(client, "resource", time.Second).Lock(context.Background()))
+
+		assert.Len(t, owners, 2)
+		assert.NotEqual(t, owners[0], owners[1])
+	})
+
+	t.Run("Already Held", func(t *testing.T) {
+		client := new(MockRedisClient)
+		client.On("Eval", mock.Anything, isScript("incr"), lockKeys, mock.Anything).
+			Return(redisClient.NewIntResult(0, nil))
+		client.On("Eval", mock.Anything, isScript("del"), ownerKeys, mock.Anything).
+			Return(redisClient.NewIntResult(0, nil))
+
+		lock := redis.NewDistributedLock(client, "resource", time.Second)
+
+		assert.ErrorIs(t, lock.Lock(context.Background()), redis.ErrLockNotAcquired)
+	})
+}
+
+func TestDistributedLock_TryLock(t *testing.T) {
+	t.Run("Stops On Context Cancellation", func(t *testing.T) {
+		client := new(MockRedisClient)
+		client.On("Eval", mock.Anything, isScript("incr"), lockKeys, mock.Anything).
+			Return(redisClient.NewIntResult(0, nil))
+		client.On("Eval", mock.Anything, isScript("del"), ownerKeys, mock.Anything).
+			Return(redisClient.NewIntResult(0, nil))
+
+		lock := redis.NewDistributedLock(client, "resource", time.Second, redis.WithRetryBackoff(10*time.Millisecond, 20*time.Millisecond))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		err := lock.TryLock(ctx, time.Minute)
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.ErrorIs(t, err, redis.ErrLockNotAcquired)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("Retries Until Acquired", func(t *testing.T) {
+		client := new(MockRedisClient)
+		client.On("Eval", mock.Anything, isScript("incr"), lockKeys, mock.Anything).
+			Return(redisClient.NewIntResult(0, nil)).Twice()
+		client.On("Eval", mock.Anything, isScript("del"), ownerKeys, mock.Anything).
+			Return(redisClient.NewIntResult(0, nil))
+		client.On("Eval", mock.Anything, isScript("incr"), lockKeys, mock.Anything).
+			Return(redisClient.NewIntResult(3, nil)).Once()
+
+		lock := redis.NewDistributedLock(client, "resource", time.Second, redis.WithRetryBackoff(time.Millisecond, 5*time.Millisecond))
+
+		assert.NoError(t, lock.TryLock(context.Background(), time.Second))
+		assert.Equal(t, int64(3), lock.FencingToken())
+	})
+}
+
+func TestDistributedLock_AutoRenew(t *testing.T) {
+	client := new(MockRedisClient)
+	client.On("Eval", mock.Anything, isScript("incr"), lockKeys, mock.Anything).
+		Return(redisClient.NewIntResult(1, nil))
+	client.On("Eval", mock.Anything, isScript("pexpire"), ownerKeys, mock.Anything).
+		Return(redisClient.NewIntResult(1, nil))
+	client.On("Eval", mock.Anything, isScript("del"), ownerKeys, mock.Anything).
+		Return(redisClient.NewIntResult(1, nil))
+
+	lock := redis.NewDistributedLock(client, "resource", 30*time.Millisecond, redis.WithAutoRenew())
+
+	assert.NoError(t, lock.Lock(context.Background()))
+	time.Sleep(50 * time.Millisecond)
+	assert.NoError(t, lock.Unlock(context.Background()))
+
+	client.AssertCalled(t, "Eval", mock.Anything, isScript("pexpire"), ownerKeys, mock.Anything)
+	select {
+	case <-lock.Lost():
+		t.Fatal("lock reported lost while renewals succeeded")
+	default:
+	}
+}
+
+func TestDistributedLock_Redlock(t *testing.T) {
+	// The first node issues fencing tokens, the others only grant the lock
+	newIssuer := func(token int64, err error) *MockRedisClient {
+		client := new(MockRedisClient)
+		client.On("Eval", mock.Anything, isScript("incr"), lockKeys, mock.Anything).
+			Return(redisClient.NewIntResult(token, err))
+		client.On("Eval", mock.Anything, isScript("del"), ownerKeys, mock.Anything).
+			Return(redisClient.NewIntResult(1, nil))
+		return client
+	}
+	newNode := func(granted int64, err error) *MockRedisClient {
+		client := new(MockRedisClient)
+		client.On("Eval", mock.Anything, isScript("NX"), ownerKeys, mock.Anything).
+			Return(redisClient.NewIntResult(granted, err))
+		client.On("Eval", mock.Anything, isScript("del"), ownerKeys, mock.Anything).
+			Return(redisClient.NewIntResult(1, nil))
+		return client
+	}
+
+	t.Run("Quorum Reached", func(t *testing.T) {
+		nodes := []redis.RedisClient{newIssuer(4, nil), newNode(1, nil), newNode(0, errors.New("connection refused"))}
+		lock := redis.NewRedlock(nodes, "resource", time.Second)
+
+		assert.NoError(t, lock.Lock(context.Background()))
+		assert.Equal(t, int64(4), lock.FencingToken())
+	})
+
+	t.Run("Quorum Without Token Issuer", func(t *testing.T) {
+		nodes := []redis.RedisClient{newIssuer(0, nil), newNode(1, nil), newNode(1, nil)}
+		lock := redis.NewRedlock(nodes, "resource", time.Second)
+
+		assert.ErrorIs(t, lock.Lock(context.Background()), redis.ErrLockNotAcquired)
+	})
+
+	t.Run("Quorum Not Reached Releases Nodes", func(t *testing.T) {
+		granted := newIssuer(4, nil)
+		nodes := []redis.RedisClient{granted, newNode(0, nil), newNode(0, nil)}
+		lock := redis.NewRedlock(nodes, "resource", time.Second)
+
+		assert.ErrorIs(t, lock.Lock(context.Background()), redis.ErrLockNotAcquired)
+		granted.AssertCalled(t, "Eval", mock.Anything, isScript("del"), ownerKeys, mock.Anything)
+	})
+
+	t.Run("Unavailable Nodes Reported", func(t *testing.T) {
+		refused := errors.New("connection refused")
+		nodes := []redis.RedisClient{newIssuer(4, nil), newNode(0, refused), newNode(0, refused)}
+		lock := redis.NewRedlock(nodes, "resource", time.Second)
+
+		err := lock.Lock(context.Background())
+
+		assert.ErrorIs(t, err, refused)
+		assert.NotErrorIs(t, err, redis.ErrLockNotAcquired)
+	})
+}
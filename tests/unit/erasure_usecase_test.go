@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"testing"
+	"time"
+	"xyz-multifinance/internal/domain"
+	"xyz-multifinance/internal/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockErasureRequestRepository struct {
+	mock.Mock
+}
+
+func (m *MockErasureRequestRepository) Create(request *domain.ErasureRequest) error {
+	args := m.Called(request)
+	return args.Error(0)
+}
+
+func (m *MockErasureRequestRepository) GetPendingByCustomerID(customerID uint) (*domain.ErasureRequest, error) {
+	args := m.Called(customerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ErasureRequest), args.Error(1)
+}
+
+func (m *MockErasureRequestRepository) ListDue(now time.Time, limit int) ([]domain.ErasureRequest, error) {
+	args := m.Called(now, limit)
+	return args.Get(0).([]domain.ErasureRequest), args.Error(1)
+}
+
+func (m *MockErasureRequestRepository) MarkCompleted(id uint, completedAt time.Time) error {
+	args := m.Called(id, completedAt)
+	return args.Error(0)
+}
+
+func TestErasureUseCase_RequestErasure(t *testing.T) {
+	retention := 5 * 365 * 24 * time.Hour
+
+	t.Run("Active Customer Is Deleted", func(t *testing.T) {
+		mockCustomerRepo := new(MockCustomerRepository)
+		mockErasureRepo := new(MockErasureRequestRepository)
+		useCase := usecase.NewErasureUseCase(mockCustomerRepo, mockErasureRepo, retention)
+
+		mockCustomerRepo.On("GetByIDUnscoped", uint(1)).Return(&domain.Customer{ID: 1}, nil)
+		mockErasureRepo.On("GetPendingByCustomerID", uint(1)).Return(nil, domain.ErrNotFound)
+		mockCustomerRepo.On("HasActiveContracts", uint(1)).Return(false, nil)
+		mockCustomerRepo.On("Delete", uint(1)).Return(nil)
+		mockErasureRepo.On("Create", mock.AnythingOfType("*domain.ErasureRequest")).Return(nil)
+
+		request, err := useCase.RequestErasure(1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.ErasurePending, request.Status)
+		assert.WithinDuration(t, time.Now().Add(retention), request.EligibleAt, time.Minute)
+		mockCustomerRepo.AssertExpectations(t)
+		mockErasureRepo.AssertExpectations(t)
+	})
+
+	t.Run("Retention Starts At Deletion", func(t *testing.T) {
+		mockCustomerRepo := new(MockCustomerRepository)
+		mockErasureRepo := new(MockErasureRequestRepository)
+		useCase := usecase.NewErasureUseCase(mockCustomerRepo, mockErasureRepo, retention)
+
+		deletedAt := time.Now().AddDate(-1, 0, 0)
+		customer := &domain.Customer{ID: 1, DeletedAt: gorm.DeletedAt{Time: deletedAt, Valid: true}}
+
+		mockCustomerRepo.On("GetByIDUnscoped", uint(1)).Return(customer, nil)
+		mockErasureRepo.On("GetPendingByCustomerID", uint(1)).Return(nil, domain.ErrNotFound)
+		mockCustomerRepo.On("HasActiveContracts", uint(1)).Return(false, nil)
+		mockErasureRepo.On("Create", mock.AnythingOfType("*domain.ErasureRequest")).Return(nil)
+
+		request, err := useCase.RequestErasure(1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, deletedAt.Add(retention), request.EligibleAt)
+		mockCustomerRepo.AssertNotCalled(t, "Delete", uint(1))
+		mockErasureRepo.AssertExpectations(t)
+	})
+
+	t.Run("Active Contracts", func(t *testing.T) {
+		mockCustomerRepo := new(MockCustomerRepository)
+		mockErasureRepo := new(MockErasureRequestRepository)
+		useCase := usecase.NewErasureUseCase(mockCustomerRepo, mockErasureRepo, retention)
+
+		mockCustomerRepo.On("GetByIDUnscoped", uint(1)).Return(&domain.Customer{ID: 1}, nil)
+		mockErasureRepo.On("GetPendingByCustomerID", uint(1)).Return(nil, domain.ErrNotFound)
+		mockCustomerRepo.On("HasActiveContracts", uint(1)).Return(true, nil)
+
+		request, err := useCase.RequestErasure(1)
+
+		assert.ErrorIs(t, err, domain.ErrConflict)
+		assert.Nil(t, request)
+		mockErasureRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+}
+
+func TestErasureUseCase_ProcessDueRequests(t *testing.T) {
+	mockCustomerRepo := new(MockCustomerRepository)
+	mockErasureRepo := new(MockErasureRequestRepository)
+	useCase := usecase.NewErasureUseCase(mockCustomerRepo, mockErasureRepo, time.Hour)
+
+	now := time.Now()
+	requests := []domain.ErasureRequest{
+		{ID: 1, CustomerID: 10, Status: domain.ErasurePending},
+		{ID: 2, CustomerID: 20, Status: domain.ErasurePending},
+	}
+
+	mockErasureRepo.On("ListDue", now, mock.AnythingOfType("int")).Return(requests, nil)
+	mockCustomerRepo.On("Anonymize", uint(10)).Return(nil)
+	mockCustomerRepo.On("Anonymize", uint(20)).Return(nil)
+	mockErasureRepo.On("MarkCompleted", uint(1), now).Return(nil)
+	mockErasureRepo.On("MarkCompleted", uint(2), now).Return(nil)
+
+	processed, err := useCase.ProcessDueRequests(now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, processed)
+	mockCustomerRepo.AssertExpectations(t)
+	mockErasureRepo.AssertExpectations(t)
+}
@@ -0,0 +1,98 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"xyz-multifinance/internal/domain"
+	"xyz-multifinance/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func newErrorRouter(err error) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.NewErrorHandlerMiddleware(zap.NewNop()))
+	router.GET("/test", func(c *gin.Context) {
+		c.Error(err)
+	})
+	return router
+}
+
+func TestErrorHandlerMiddleware(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+		detail string
+	}{
+		{
+			name:   "Typed Not Found",
+			err:    domain.NewError(domain.ErrNotFound, "customer_not_found", "customer not found"),
+			status: http.StatusNotFound,
+			code:   "customer_not_found",
+			detail: "customer not found",
+		},
+		{
+			name:   "Wrapped Conflict",
+			err:    fmt.Errorf("update failed: %w", domain.NewError(domain.ErrConflict, "concurrent_modification", "concurrent modification detected")),
+			status: http.StatusConflict,
+			code:   "concurrent_modification",
+			detail: "concurrent modification detected",
+		},
+		{
+			name:   "Sentinel Insufficient Limit",
+			err:    domain.ErrInsufficientLimit,
+			status: http.StatusUnprocessableEntity,
+			code:   "insufficient_credit_limit",
+			detail: "insufficient credit limit",
+		},
+		{
+			name:   "Validation",
+			err:    domain.NewError(domain.ErrValidation, "invalid_customer_id", "invalid customer ID"),
+			status: http.StatusBadRequest,
+			code:   "invalid_customer_id",
+			detail: "invalid customer ID",
+		},
+		{
+			name:   "Forbidden",
+			err:    domain.ErrForbidden,
+			status: http.StatusForbidden,
+			code:   "forbidden",
+			detail: "forbidden",
+		},
+		{
+			name:   "Unknown Error Is Not Leaked",
+			err:    errors.New("pq: connection refused"),
+			status: http.StatusInternalServerError,
+			code:   "internal_error",
+			detail: "an unexpected error occurred",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newErrorRouter(tt.err)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			router.ServeHTTP(w, req)
+
+			var problem middleware.Problem
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, middleware.ProblemContentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, tt.status, problem.Status)
+			assert.Equal(t, tt.code, problem.Code)
+			assert.Equal(t, tt.detail, problem.Detail)
+			assert.Equal(t, "/test", problem.Instance)
+		})
+	}
+}
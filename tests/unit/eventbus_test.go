@@ -0,0 +1,155 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"xyz-multifinance/internal/domain"
+	"xyz-multifinance/internal/pkg/eventbus"
+
+	redisClient "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+type stubEventHandler struct {
+	err     error
+	handled []string
+}
+
+func (h *stubEventHandler) Group() string {
+	return "test-group"
+}
+
+func (h *stubEventHandler) Handle(ctx context.Context, event *domain.Event) error {
+	h.handled = append(h.handled, event.ID)
+	return h.err
+}
+
+var testConsumerConfig = eventbus.ConsumerConfig{
+	Consumer:      "worker-1",
+	Batch:         10,
+	Block:         time.Second,
+	ClaimIdle:     time.Minute,
+	MaxDeliveries: 3,
+}
+
+func streamEntry(id, eventID string) redisClient.XMessage {
+	return redisClient.XMessage{
+		ID: id,
+		Values: map[string]interface{}{
+			"event_id": eventID,
+			"type":     "transaction.created",
+			"payload":  `{"id":"` + eventID + `","type":"transaction.created","data":{}}`,
+		},
+	}
+}
+
+// newStreamClient returns a client with the group already created and no
+// pending entries to claim
+func newStreamClient() *MockRedisClient {
+	client := new(MockRedisClient)
+	client.On("XGroupCreateMkStream", mock.Anything, "events", "test-group", "0").
+		Return("", errors.New("BUSYGROUP Consumer Group name already exists"))
+	return client
+}
+
+func TestEventBus_Publish(t *testing.T) {
+	client := new(MockRedisClient)
+	bus := eventbus.New(client, "events", 1000, zap.NewNop())
+
+	client.On("XAdd", mock.Anything, mock.MatchedBy(func(a *redisClient.XAddArgs) bool {
+		values := a.Values.(map[string]interface{})
+		return a.Stream == "events" && a.MaxLen == 1000 && a.Approx &&
+			values["event_id"] == "evt_1" && values["type"] == "installment.paid"
+	})).Return("1-0", nil)
+
+	err := bus.Publish(context.Background(), &domain.OutboxEvent{
+		EventID:   "evt_1",
+		EventType: domain.EventInstallmentPaid,
+		Payload:   []byte(`{}`),
+	})
+
+	assert.NoError(t, err)
+	client.AssertExpectations(t)
+}
+
+func TestEventBus_Poll(t *testing.T) {
+	t.Run("Acknowledges Handled Entries", func(t *testing.T) {
+		client := newStreamClient()
+		bus := eventbus.New(client, "events", 1000, zap.NewNop())
+		handler := &stubEventHandler{}
+
+		client.On("XAutoClaim", mock.Anything, mock.Anything).Return(nil, nil)
+		client.On("XReadGroup", mock.Anything, mock.MatchedBy(func(a *redisClient.XReadGroupArgs) bool {
+			return a.Group == "test-group" && a.Consumer == "worker-1" && a.Streams[1] == ">"
+		})).Return([]redisClient.XStream{{Stream: "events", Messages: []redisClient.XMessage{streamEntry("1-0", "evt_1")}}}, nil)
+		client.On("XAck", mock.Anything, "events", "test-group", []string{"1-0"}).Return(nil)
+
+		err := bus.Poll(context.Background(), handler, testConsumerConfig)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"evt_1"}, handler.handled)
+		client.AssertExpectations(t)
+	})
+
+	t.Run("Leaves Failed Entries Pending", func(t *testing.T) {
+		client := newStreamClient()
+		bus := eventbus.New(client, "events", 1000, zap.NewNop())
+		handler := &stubEventHandler{err: errors.New("database unavailable")}
+
+		client.On("XAutoClaim", mock.Anything, mock.Anything).Return(nil, nil)
+		client.On("XReadGroup", mock.Anything, mock.Anything).
+			Return([]redisClient.XStream{{Stream: "events", Messages: []redisClient.XMessage{streamEntry("1-0", "evt_1")}}}, nil)
+
+		err := bus.Poll(context.Background(), handler, testConsumerConfig)
+
+		assert.NoError(t, err)
+		client.AssertNotCalled(t, "XAck", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Retries Claimed Entries", func(t *testing.T) {
+		client := newStreamClient()
+		bus := eventbus.New(client, "events", 1000, zap.NewNop())
+		handler := &stubEventHandler{}
+
+		client.On("XAutoClaim", mock.Anything, mock.MatchedBy(func(a *redisClient.XAutoClaimArgs) bool {
+			return a.MinIdle == time.Minute && a.Consumer == "worker-1"
+		})).Return([]redisClient.XMessage{streamEntry("1-0", "evt_1")}, nil)
+		client.On("XPendingExt", mock.Anything, mock.Anything).
+			Return([]redisClient.XPendingExt{{ID: "1-0", RetryCount: 2}}, nil)
+		client.On("XAck", mock.Anything, "events", "test-group", []string{"1-0"}).Return(nil)
+		client.On("XReadGroup", mock.Anything, mock.Anything).Return(nil, redisClient.Nil)
+
+		err := bus.Poll(context.Background(), handler, testConsumerConfig)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"evt_1"}, handler.handled)
+		client.AssertExpectations(t)
+	})
+
+	t.Run("Dead Letters After Max Deliveries", func(t *testing.T) {
+		client := newStreamClient()
+		bus := eventbus.New(client, "events", 1000, zap.NewNop())
+		handler := &stubEventHandler{}
+
+		client.On("XAutoClaim", mock.Anything, mock.Anything).
+			Return([]redisClient.XMessage{streamEntry("1-0", "evt_1")}, nil)
+		client.On("XPendingExt", mock.Anything, mock.Anything).
+			Return([]redisClient.XPendingExt{{ID: "1-0", RetryCount: 4}}, nil)
+		client.On("XAdd", mock.Anything, mock.MatchedBy(func(a *redisClient.XAddArgs) bool {
+			values := a.Values.(map[string]interface{})
+			return a.Stream == "events:dead" && values["group"] == "test-group" && values["event_id"] == "evt_1"
+		})).Return("2-0", nil)
+		client.On("XAck", mock.Anything, "events", "test-group", []string{"1-0"}).Return(nil)
+		client.On("XReadGroup", mock.Anything, mock.Anything).Return(nil, redisClient.Nil)
+
+		err := bus.Poll(context.Background(), handler, testConsumerConfig)
+
+		assert.NoError(t, err)
+		assert.Empty(t, handler.handled)
+		client.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,177 @@
+package tests
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+	"xyz-multifinance/internal/domain"
+	"xyz-multifinance/internal/pkg/export"
+	"xyz-multifinance/internal/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockExportRepository is a mock implementation of domain.ExportRepository
+type MockExportRepository struct {
+	mock.Mock
+}
+
+func (m *MockExportRepository) ListTransactions(filter domain.TransactionFilter, afterID uint, limit int) ([]domain.Transaction, error) {
+	args := m.Called(filter, afterID, limit)
+	return args.Get(0).([]domain.Transaction), args.Error(1)
+}
+
+func (m *MockExportRepository) StatementBalance(customerID uint, before time.Time) (float64, error) {
+	args := m.Called(customerID, before)
+	return args.Get(0).(float64), args.Error(1)
+}
+
+func (m *MockExportRepository) ListStatementLines(customerID uint, to, afterPostedAt time.Time, afterLineID uint, limit int) ([]domain.StatementLine, error) {
+	args := m.Called(customerID, to, afterPostedAt, afterLineID, limit)
+	return args.Get(0).([]domain.StatementLine), args.Error(1)
+}
+
+func TestExportUseCase_Transactions(t *testing.T) {
+	mockRepo := new(MockExportRepository)
+	useCase := usecase.NewExportUseCase(mockRepo, new(MockCustomerRepository), new(MockTransactionRepository))
+
+	filter := domain.TransactionFilter{Status: domain.StatusApproved}
+	firstBatch := make([]domain.Transaction, 500)
+	for i := range firstBatch {
+		firstBatch[i] = domain.Transaction{ID: uint(i + 1), Status: domain.StatusApproved}
+	}
+	mockRepo.On("ListTransactions", filter, uint(0), 500).Return(firstBatch, nil)
+	mockRepo.On("ListTransactions", filter, uint(500), 500).Return([]domain.Transaction{{
+		ID:             501,
+		ContractNumber: "XYZ-501",
+		CustomerID:     7,
+		Source:         domain.SourceDealer,
+		Status:         domain.StatusApproved,
+		AssetName:      "Motor, Honda",
+		OTRAmount:      25000000,
+		Tenor:          4,
+		CreatedAt:      time.Date(2024, 3, 5, 10, 0, 0, 0, time.Local),
+	}}, nil)
+
+	var buf bytes.Buffer
+	w, err := export.NewWriter(&buf, domain.ExportCSV)
+	require.NoError(t, err)
+
+	require.NoError(t, useCase.Transactions(filter, w))
+	require.NoError(t, w.Close())
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 502)
+	assert.True(t, strings.HasPrefix(lines[0], "id,contract_number,customer_id"))
+	assert.Equal(t, `501,XYZ-501,7,dealer,approved,"Motor, Honda",25000000.00,0.00,0.00,0.00,4,0.00,2024-03-05`, lines[501])
+	mockRepo.AssertExpectations(t)
+}
+
+func TestExportUseCase_Statement(t *testing.T) {
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local)
+	to := time.Date(2024, 4, 1, 0, 0, 0, 0, time.Local)
+
+	t.Run("Runs The Balance From The Opening Balance", func(t *testing.T) {
+		mockRepo := new(MockExportRepository)
+		mockCustomerRepo := new(MockCustomerRepository)
+		useCase := usecase.NewExportUseCase(mockRepo, mockCustomerRepo, new(MockTransactionRepository))
+
+		mockCustomerRepo.On("GetByID", uint(7)).Return(&domain.Customer{ID: 7}, nil)
+		mockRepo.On("StatementBalance", uint(7), from).Return(3000000.0, nil)
+		mockRepo.On("ListStatementLines", uint(7), to, from, uint(0), 500).Return([]domain.StatementLine{
+			{LineID: 11, PostedAt: from.AddDate(0, 0, 4), ContractNumber: "XYZ-1", EntryType: domain.JournalInstallmentPayment, Credit: 1000000},
+			{LineID: 12, PostedAt: from.AddDate(0, 0, 9), ContractNumber: "XYZ-1", EntryType: domain.JournalLateFee, Debit: 25000.5},
+		}, nil)
+
+		var buf bytes.Buffer
+		w, err := export.NewWriter(&buf, domain.ExportCSV)
+		require.NoError(t, err)
+
+		require.NoError(t, useCase.Statement(7, from, to, w))
+		require.NoError(t, w.Close())
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		require.Len(t, lines, 5)
+		assert.Equal(t, "2024-03-01,,,Opening balance,,,,3000000.00", lines[1])
+		assert.Equal(t, "2024-03-05,XYZ-1,installment_payment,,,0.00,1000000.00,2000000.00", lines[2])
+		assert.Equal(t, "2024-03-10,XYZ-1,late_fee,,,25000.50,0.00,2025000.50", lines[3])
+		assert.Equal(t, "2024-03-31,,,Closing balance,,,,2025000.50", lines[4])
+	})
+
+	t.Run("Customer Not Found", func(t *testing.T) {
+		mockRepo := new(MockExportRepository)
+		mockCustomerRepo := new(MockCustomerRepository)
+		useCase := usecase.NewExportUseCase(mockRepo, mockCustomerRepo, new(MockTransactionRepository))
+
+		mockCustomerRepo.On("GetByID", uint(8)).Return(nil, domain.NewError(domain.ErrNotFound, "customer_not_found", "customer not found"))
+
+		var buf bytes.Buffer
+		w, err := export.NewWriter(&buf, domain.ExportCSV)
+		require.NoError(t, err)
+
+		err = useCase.Statement(8, from, to, w)
+
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		assert.Zero(t, buf.Len())
+	})
+
+	t.Run("Invalid Period", func(t *testing.T) {
+		useCase := usecase.NewExportUseCase(new(MockExportRepository), new(MockCustomerRepository), new(MockTransactionRepository))
+
+		err := useCase.Statement(7, to, from, nil)
+
+		assert.ErrorIs(t, err, domain.ErrValidation)
+	})
+}
+
+func TestExportUseCase_InstallmentsXLSX(t *testing.T) {
+	mockTransactionRepo := new(MockTransactionRepository)
+	useCase := usecase.NewExportUseCase(new(MockExportRepository), new(MockCustomerRepository), mockTransactionRepo)
+
+	paidAt := time.Date(2024, 2, 1, 0, 0, 0, 0, time.Local)
+	mockTransactionRepo.On("GetByID", uint(3)).Return(&domain.Transaction{ID: 3, ContractNumber: "XYZ-3"}, nil)
+	mockTransactionRepo.On("GetInstallments", uint(3)).Return([]domain.Installment{
+		{InstallmentNumber: 1, DueDate: paidAt, Amount: 1500000, Status: "paid", PaidAt: &paidAt},
+		{InstallmentNumber: 2, DueDate: paidAt.AddDate(0, 1, 0), Amount: 1500000, Status: "unpaid"},
+	}, nil)
+
+	var buf bytes.Buffer
+	w, err := export.NewWriter(&buf, domain.ExportXLSX)
+	require.NoError(t, err)
+
+	require.NoError(t, useCase.Installments(3, w))
+	require.NoError(t, w.Close())
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	var sheet string
+	for _, f := range archive.File {
+		if f.Name == "xl/worksheets/sheet1.xml" {
+			r, err := f.Open()
+			require.NoError(t, err)
+			content, err := io.ReadAll(r)
+			require.NoError(t, err)
+			sheet = string(content)
+		}
+	}
+	assert.Equal(t, 3, strings.Count(sheet, "<row "))
+	assert.Contains(t, sheet, `<c r="A2" t="inlineStr"><is><t xml:space="preserve">XYZ-3</t></is></c>`)
+	assert.Contains(t, sheet, `<c r="D3"><v>1500000.00</v></c>`)
+	assert.NotContains(t, sheet, `r="I3"`)
+}
+
+func TestExportWriter_EscapesFormulas(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := export.NewWriter(&buf, domain.ExportCSV)
+	require.NoError(t, err)
+
+	require.NoError(t, w.WriteRow(`=HYPERLINK("http://evil","x")`, "+62812", "-1", "@SUM(A1)", "\tname", "\rname", "Budi - Santoso", -1500.5, -3))
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, `"'=HYPERLINK(""http://evil"",""x"")",'+62812,'-1,'@SUM(A1),'	name,"'`+"\r"+`name",Budi - Santoso,-1500.50,-3`+"\n", buf.String())
+}
@@ -0,0 +1,301 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+	"xyz-multifinance/internal/domain"
+	"xyz-multifinance/internal/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockLedgerRepository is a mock implementation of domain.LedgerRepository
+type MockLedgerRepository struct {
+	mock.Mock
+}
+
+func (m *MockLedgerRepository) ListAccounts() ([]domain.Account, error) {
+	args := m.Called()
+	return args.Get(0).([]domain.Account), args.Error(1)
+}
+
+func (m *MockLedgerRepository) CreateAccount(account *domain.Account) error {
+	args := m.Called(account)
+	return args.Error(0)
+}
+
+func (m *MockLedgerRepository) UpdateAccount(account *domain.Account) error {
+	args := m.Called(account)
+	return args.Error(0)
+}
+
+func (m *MockLedgerRepository) Post(entries []domain.JournalEntry, source *domain.ProcessedEvent, audit *domain.AuditLog) error {
+	args := m.Called(entries, source, audit)
+	return args.Error(0)
+}
+
+func (m *MockLedgerRepository) GetEntry(id uint) (*domain.JournalEntry, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.JournalEntry), args.Error(1)
+}
+
+func (m *MockLedgerRepository) ListEntries(filter domain.JournalFilter, offset, limit int) ([]domain.JournalEntry, error) {
+	args := m.Called(filter, offset, limit)
+	return args.Get(0).([]domain.JournalEntry), args.Error(1)
+}
+
+func (m *MockLedgerRepository) ContractBalances(transactionID uint) (map[string]float64, error) {
+	args := m.Called(transactionID)
+	return args.Get(0).(map[string]float64), args.Error(1)
+}
+
+func (m *MockLedgerRepository) ListUnreversed(entryType domain.JournalType, reference string) ([]domain.JournalEntry, error) {
+	args := m.Called(entryType, reference)
+	return args.Get(0).([]domain.JournalEntry), args.Error(1)
+}
+
+func (m *MockLedgerRepository) Balances(asOf time.Time) ([]domain.AccountBalance, error) {
+	args := m.Called(asOf)
+	return args.Get(0).([]domain.AccountBalance), args.Error(1)
+}
+
+// lineAmounts returns the debits less the credits of entry, by account
+func lineAmounts(entry domain.JournalEntry) map[string]float64 {
+	amounts := make(map[string]float64)
+	for _, line := range entry.Lines {
+		amounts[line.AccountCode] += line.Debit - line.Credit
+	}
+	return amounts
+}
+
+func TestJournalEntry_Validate(t *testing.T) {
+	entry := domain.JournalEntry{}
+	entry.Post(domain.AccountCash, 100.10)
+	entry.Post(domain.AccountInterestIncome, -60.05)
+	entry.Post(domain.AccountAdminFeeIncome, -40.05)
+	entry.Post(domain.AccountLateFeeIncome, 0)
+	require.Len(t, entry.Lines, 3)
+	assert.NoError(t, entry.Validate())
+
+	unbalanced := domain.JournalEntry{Lines: []domain.JournalLine{
+		{AccountCode: domain.AccountCash, Debit: 100},
+		{AccountCode: domain.AccountInterestIncome, Credit: 99.99},
+	}}
+	assert.Error(t, unbalanced.Validate())
+
+	bothSides := domain.JournalEntry{Lines: []domain.JournalLine{
+		{AccountCode: domain.AccountCash, Debit: 100, Credit: 100},
+		{AccountCode: domain.AccountInterestIncome, Debit: 50, Credit: 50},
+	}}
+	assert.Error(t, bothSides.Validate())
+
+	single := domain.JournalEntry{Lines: entry.Lines[:1]}
+	assert.Error(t, single.Validate())
+
+	entry.ID = 9
+	reversal := entry.Reversal(time.Now())
+	assert.Equal(t, domain.JournalReversal, reversal.Type)
+	assert.Equal(t, uint(9), *reversal.ReversalOf)
+	assert.Equal(t, 100.10, reversal.Lines[0].Credit)
+	assert.Equal(t, 60.05, reversal.Lines[1].Debit)
+}
+
+func TestLedgerUseCase_PostDisbursement(t *testing.T) {
+	mockRepo := new(MockLedgerRepository)
+	mockTxRepo := new(MockTransactionRepository)
+	useCase := usecase.NewLedgerUseCase(mockRepo, mockTxRepo)
+
+	at := time.Now()
+	source := &domain.ProcessedEvent{Consumer: "ledger", EventID: "evt_1"}
+	mockTxRepo.On("GetByID", uint(1)).Return(&domain.Transaction{
+		ID: 1, ContractNumber: "CTR-1", OTRAmount: 10000000, AdminFee: 500000,
+		Installments: []domain.Installment{
+			{ID: 1, Amount: 4000000, Status: "unpaid"},
+			{ID: 2, Amount: 4000000, Status: "unpaid"},
+			{ID: 3, Amount: 4000000, Status: "unpaid"},
+		},
+	}, nil)
+	mockRepo.On("Post", mock.MatchedBy(func(entries []domain.JournalEntry) bool {
+		if len(entries) != 2 {
+			return false
+		}
+		disbursement, fee := lineAmounts(entries[0]), lineAmounts(entries[1])
+		return entries[0].Type == domain.JournalDisbursement && entries[0].PostedAt.Equal(at) &&
+			disbursement[domain.AccountFinancingReceivable] == 12000000 &&
+			disbursement[domain.AccountCash] == -10000000 &&
+			disbursement[domain.AccountDeferredAdminFee] == -500000 &&
+			disbursement[domain.AccountUnearnedInterest] == -1500000 &&
+			entries[1].Type == domain.JournalAdminFee &&
+			fee[domain.AccountDeferredAdminFee] == 500000 && fee[domain.AccountAdminFeeIncome] == -500000
+	}), source, (*domain.AuditLog)(nil)).Return(nil)
+
+	require.NoError(t, useCase.PostDisbursement(1, at, source))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestLedgerUseCase_PostInstallmentPayment(t *testing.T) {
+	mockRepo := new(MockLedgerRepository)
+	useCase := usecase.NewLedgerUseCase(mockRepo, new(MockTransactionRepository))
+
+	mockRepo.On("Post", mock.MatchedBy(func(entries []domain.JournalEntry) bool {
+		if len(entries) != 1 {
+			return false
+		}
+		payment := lineAmounts(entries[0])
+		return entries[0].Type == domain.JournalInstallmentPayment && entries[0].Reference == "installment:5" &&
+			payment[domain.AccountCash] == 4100000 &&
+			payment[domain.AccountFinancingReceivable] == -4000000 &&
+			payment[domain.AccountLateFeeReceivable] == -100000
+	}), mock.Anything, (*domain.AuditLog)(nil)).Return(nil)
+
+	err := useCase.PostInstallmentPayment(domain.InstallmentEvent{
+		InstallmentID: 5, TransactionID: 1, Amount: 4000000, LateFee: 100000,
+	}, time.Now(), nil)
+
+	require.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestLedgerUseCase_PostInstallmentReversal(t *testing.T) {
+	mockRepo := new(MockLedgerRepository)
+	useCase := usecase.NewLedgerUseCase(mockRepo, new(MockTransactionRepository))
+
+	at := time.Now()
+	txID := uint(1)
+	payment := domain.JournalEntry{ID: 11, Type: domain.JournalInstallmentPayment, TransactionID: &txID, Reference: "installment:5",
+		Lines: []domain.JournalLine{
+			{AccountCode: domain.AccountCash, Debit: 4000000},
+			{AccountCode: domain.AccountFinancingReceivable, Credit: 4000000},
+		}}
+	mockRepo.On("ListUnreversed", domain.JournalInstallmentPayment, "installment:5").Return([]domain.JournalEntry{payment}, nil)
+	mockRepo.On("Post", mock.MatchedBy(func(entries []domain.JournalEntry) bool {
+		if len(entries) != 2 {
+			return false
+		}
+		return *entries[0].ReversalOf == 11 && lineAmounts(entries[0])[domain.AccountCash] == -4000000 &&
+			entries[1].Type == domain.JournalLateFee && lineAmounts(entries[1])[domain.AccountLateFeeIncome] == -20000
+	}), mock.Anything, (*domain.AuditLog)(nil)).Return(nil)
+
+	err := useCase.PostInstallmentReversal(domain.InstallmentEvent{InstallmentID: 5, TransactionID: 1, LateFee: 20000}, at, nil)
+
+	require.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestLedgerUseCase_PostWriteOff(t *testing.T) {
+	mockRepo := new(MockLedgerRepository)
+	mockTxRepo := new(MockTransactionRepository)
+	useCase := usecase.NewLedgerUseCase(mockRepo, mockTxRepo)
+
+	mockTxRepo.On("GetByID", uint(1)).Return(&domain.Transaction{ID: 1, ContractNumber: "CTR-1"}, nil)
+	mockRepo.On("ContractBalances", uint(1)).Return(map[string]float64{
+		domain.AccountFinancingReceivable: 8000000,
+		domain.AccountUnearnedInterest:    -1000000,
+		domain.AccountLateFeeReceivable:   200000,
+		domain.AccountCash:                -6000000,
+	}, nil)
+	mockRepo.On("Post", mock.MatchedBy(func(entries []domain.JournalEntry) bool {
+		if len(entries) != 1 {
+			return false
+		}
+		amounts := lineAmounts(entries[0])
+		return entries[0].Type == domain.JournalWriteOff && len(amounts) == 4 &&
+			amounts[domain.AccountWriteOffExpense] == 7200000 &&
+			amounts[domain.AccountUnearnedInterest] == 1000000 &&
+			amounts[domain.AccountFinancingReceivable] == -8000000 &&
+			amounts[domain.AccountLateFeeReceivable] == -200000
+	}), mock.Anything, (*domain.AuditLog)(nil)).Return(nil)
+
+	require.NoError(t, useCase.PostWriteOff(1, time.Now(), nil))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestLedgerUseCase_PostManual(t *testing.T) {
+	actor := domain.Actor{ID: 4, Role: "finance"}
+
+	t.Run("Posts Balanced Entry", func(t *testing.T) {
+		mockRepo := new(MockLedgerRepository)
+		useCase := usecase.NewLedgerUseCase(mockRepo, new(MockTransactionRepository))
+
+		mockRepo.On("Post", mock.MatchedBy(func(entries []domain.JournalEntry) bool {
+			return len(entries) == 1 && entries[0].Type == domain.JournalManual && *entries[0].CreatedBy == 4
+		}), (*domain.ProcessedEvent)(nil), mock.MatchedBy(func(a *domain.AuditLog) bool {
+			return a.Action == "journal_entry.posted" && a.ActorID == 4
+		})).Return(nil)
+
+		entry := &domain.JournalEntry{Description: "Bank charges", Lines: []domain.JournalLine{
+			{AccountCode: domain.AccountWriteOffExpense, Debit: 15000},
+			{AccountCode: domain.AccountCash, Credit: 15000},
+		}}
+
+		require.NoError(t, useCase.PostManual(entry, actor))
+		assert.False(t, entry.PostedAt.IsZero())
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Refuses Unbalanced Entry", func(t *testing.T) {
+		mockRepo := new(MockLedgerRepository)
+		useCase := usecase.NewLedgerUseCase(mockRepo, new(MockTransactionRepository))
+
+		entry := &domain.JournalEntry{Description: "Bank charges", Lines: []domain.JournalLine{
+			{AccountCode: domain.AccountWriteOffExpense, Debit: 15000},
+			{AccountCode: domain.AccountCash, Credit: 14000},
+		}}
+
+		err := useCase.PostManual(entry, actor)
+
+		assert.ErrorIs(t, err, domain.ErrValidation)
+		mockRepo.AssertNotCalled(t, "Post", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestLedgerUseCase_TrialBalance(t *testing.T) {
+	mockRepo := new(MockLedgerRepository)
+	useCase := usecase.NewLedgerUseCase(mockRepo, new(MockTransactionRepository))
+
+	asOf := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	mockRepo.On("Balances", asOf).Return([]domain.AccountBalance{
+		{Account: domain.Account{Code: domain.AccountCash, Type: domain.AccountAsset}, Balance: -6000000},
+		{Account: domain.Account{Code: domain.AccountFinancingReceivable, Type: domain.AccountAsset}, Balance: 8000000},
+		{Account: domain.Account{Code: domain.AccountUnearnedInterest, Type: domain.AccountAsset}, Balance: -1000000},
+		{Account: domain.Account{Code: domain.AccountInterestIncome, Type: domain.AccountIncome}, Balance: -1000000},
+		{Account: domain.Account{Code: domain.AccountWriteOffExpense, Type: domain.AccountExpense}},
+	}, nil)
+
+	report, err := useCase.TrialBalance(asOf)
+
+	require.NoError(t, err)
+	assert.Len(t, report.Accounts, 5)
+	assert.Equal(t, 6000000.0, report.Accounts[0].Credit)
+	assert.Equal(t, 8000000.0, report.TotalDebit)
+	assert.Equal(t, 8000000.0, report.TotalCredit)
+	assert.True(t, report.Balanced)
+}
+
+func TestLedgerEventHandler(t *testing.T) {
+	mockRepo := new(MockLedgerRepository)
+	useCase := usecase.NewLedgerUseCase(mockRepo, new(MockTransactionRepository))
+	handler := usecase.NewLedgerEventHandler(useCase)
+
+	data, err := json.Marshal(domain.WriteOffRecovered{WriteOffID: 2, TransactionID: 1, Amount: 250000, ReceivedAt: time.Now()})
+	require.NoError(t, err)
+	event := &domain.Event{ID: "evt_1", Type: domain.EventWriteOffRecovered, OccurredAt: time.Now(), Data: data}
+
+	mockRepo.On("Post", mock.MatchedBy(func(entries []domain.JournalEntry) bool {
+		return len(entries) == 1 && entries[0].Type == domain.JournalRecovery &&
+			lineAmounts(entries[0])[domain.AccountRecoveryIncome] == -250000
+	}), mock.MatchedBy(func(source *domain.ProcessedEvent) bool {
+		return source.Consumer == "ledger" && source.EventID == "evt_1"
+	}), (*domain.AuditLog)(nil)).Return(domain.ErrEventProcessed)
+
+	assert.Equal(t, "ledger", handler.Group())
+	assert.NoError(t, handler.Handle(context.Background(), event))
+	mockRepo.AssertExpectations(t)
+}
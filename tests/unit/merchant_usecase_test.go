@@ -0,0 +1,106 @@
+package tests
+
+import (
+	"testing"
+	"xyz-multifinance/internal/domain"
+	"xyz-multifinance/internal/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockMerchantRepository struct {
+	mock.Mock
+}
+
+func (m *MockMerchantRepository) Create(merchant *domain.Merchant) error {
+	args := m.Called(merchant)
+	return args.Error(0)
+}
+
+func (m *MockMerchantRepository) GetByID(id uint) (*domain.Merchant, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Merchant), args.Error(1)
+}
+
+func (m *MockMerchantRepository) List(offset, limit int) ([]domain.Merchant, error) {
+	args := m.Called(offset, limit)
+	return args.Get(0).([]domain.Merchant), args.Error(1)
+}
+
+func (m *MockMerchantRepository) Update(merchant *domain.Merchant) error {
+	args := m.Called(merchant)
+	return args.Error(0)
+}
+
+func (m *MockMerchantRepository) Delete(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockMerchantRepository) HasPendingSettlements(id uint) (bool, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Error(1)
+}
+
+func TestMerchantUseCase_Update(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockMerchantRepository)
+		useCase := usecase.NewMerchantUseCase(mockRepo)
+
+		mockRepo.On("GetByID", uint(1)).Return(&domain.Merchant{ID: 1, Code: "DLR01", CommissionRate: 0.02, Version: 2}, nil)
+		mockRepo.On("Update", mock.MatchedBy(func(m *domain.Merchant) bool {
+			return m.Code == "DLR01" && m.CommissionRate == 0.03 && m.Version == 2
+		})).Run(func(args mock.Arguments) {
+			args.Get(0).(*domain.Merchant).Version++
+		}).Return(nil)
+
+		merchant := &domain.Merchant{ID: 1, Code: "IGNORED", CommissionRate: 0.03, Status: domain.MerchantActive, Version: 2}
+		err := useCase.Update(merchant)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "DLR01", merchant.Code, "code is immutable")
+		assert.Equal(t, 3, merchant.Version)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Version Mismatch", func(t *testing.T) {
+		mockRepo := new(MockMerchantRepository)
+		useCase := usecase.NewMerchantUseCase(mockRepo)
+
+		mockRepo.On("GetByID", uint(1)).Return(&domain.Merchant{ID: 1, Version: 5}, nil)
+		mockRepo.On("Update", mock.Anything).Return(domain.NewError(domain.ErrConflict, "concurrent_modification", "concurrent modification detected"))
+
+		err := useCase.Update(&domain.Merchant{ID: 1, Version: 4})
+
+		assert.ErrorIs(t, err, domain.ErrPreconditionFailed)
+	})
+}
+
+func TestMerchantUseCase_Delete(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockMerchantRepository)
+		useCase := usecase.NewMerchantUseCase(mockRepo)
+
+		mockRepo.On("HasPendingSettlements", uint(1)).Return(false, nil)
+		mockRepo.On("Delete", uint(1)).Return(nil)
+
+		assert.NoError(t, useCase.Delete(1))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Pending Settlements", func(t *testing.T) {
+		mockRepo := new(MockMerchantRepository)
+		useCase := usecase.NewMerchantUseCase(mockRepo)
+
+		mockRepo.On("HasPendingSettlements", uint(1)).Return(true, nil)
+
+		err := useCase.Delete(1)
+
+		assert.ErrorIs(t, err, domain.ErrConflict)
+		mockRepo.AssertNotCalled(t, "Delete", uint(1))
+	})
+}
@@ -2,6 +2,7 @@ package tests
 
 import (
 	"context"
+	"io"
 	"time"
 	"xyz-multifinance/internal/domain"
 	"xyz-multifinance/internal/pkg/redis"
@@ -33,6 +34,11 @@ func (m *MockCustomerUseCase) UpdateProfile(customer *domain.Customer) error {
 	return args.Error(0)
 }
 
+func (m *MockCustomerUseCase) Delete(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
 func (m *MockCustomerUseCase) GetCreditLimits(customerID uint) ([]domain.CreditLimit, error) {
 	args := m.Called(customerID)
 	return args.Get(0).([]domain.CreditLimit), args.Error(1)
@@ -48,6 +54,130 @@ func (m *MockCustomerUseCase) UpdateCreditLimitUsage(customerID uint, amount flo
 	return args.Error(0)
 }
 
+func (m *MockCustomerUseCase) AdjustCreditLimitUsage(adjustment *domain.LimitAdjustment) error {
+	args := m.Called(adjustment)
+	return args.Error(0)
+}
+
+// MockTransactionUseCase is a mock for TransactionUseCase interface
+type MockTransactionUseCase struct {
+	mock.Mock
+}
+
+func (m *MockTransactionUseCase) Create(tx *domain.Transaction) error {
+	args := m.Called(tx)
+	return args.Error(0)
+}
+
+func (m *MockTransactionUseCase) GetByID(id uint) (*domain.Transaction, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionUseCase) GetByContractNumber(contractNumber string) (*domain.Transaction, error) {
+	args := m.Called(contractNumber)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionUseCase) UpdateStatus(id uint, status domain.TransactionStatus, version int) (*domain.Transaction, error) {
+	args := m.Called(id, status, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionUseCase) GetCustomerTransactions(filter domain.TransactionFilter, offset, limit int) ([]domain.Transaction, error) {
+	args := m.Called(filter, offset, limit)
+	return args.Get(0).([]domain.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionUseCase) GetInstallments(transactionID uint) ([]domain.Installment, error) {
+	args := m.Called(transactionID)
+	return args.Get(0).([]domain.Installment), args.Error(1)
+}
+
+func (m *MockTransactionUseCase) PayInstallment(installmentID uint) error {
+	args := m.Called(installmentID)
+	return args.Error(0)
+}
+
+func (m *MockTransactionUseCase) MarkOverdueInstallments(now time.Time) (int, error) {
+	args := m.Called(now)
+	return args.Int(0), args.Error(1)
+}
+
+// MockPartnerUseCase is a mock for PartnerUseCase interface
+type MockPartnerUseCase struct {
+	mock.Mock
+}
+
+func (m *MockPartnerUseCase) Register(partner *domain.Partner) error {
+	args := m.Called(partner)
+	return args.Error(0)
+}
+
+func (m *MockPartnerUseCase) GetByID(id uint) (*domain.Partner, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Partner), args.Error(1)
+}
+
+func (m *MockPartnerUseCase) List(offset, limit int) ([]domain.Partner, error) {
+	args := m.Called(offset, limit)
+	return args.Get(0).([]domain.Partner), args.Error(1)
+}
+
+func (m *MockPartnerUseCase) UpdateStatus(id uint, status domain.PartnerStatus) (*domain.Partner, error) {
+	args := m.Called(id, status)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Partner), args.Error(1)
+}
+
+func (m *MockPartnerUseCase) IssueKey(partnerID uint) (*domain.IssuedAPIKey, error) {
+	args := m.Called(partnerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.IssuedAPIKey), args.Error(1)
+}
+
+func (m *MockPartnerUseCase) RotateKey(partnerID uint) (*domain.IssuedAPIKey, error) {
+	args := m.Called(partnerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.IssuedAPIKey), args.Error(1)
+}
+
+func (m *MockPartnerUseCase) RevokeKey(partnerID uint, keyID string) error {
+	args := m.Called(partnerID, keyID)
+	return args.Error(0)
+}
+
+func (m *MockPartnerUseCase) ListKeys(partnerID uint) ([]domain.PartnerAPIKey, error) {
+	args := m.Called(partnerID)
+	return args.Get(0).([]domain.PartnerAPIKey), args.Error(1)
+}
+
+func (m *MockPartnerUseCase) Authenticate(keyID string) (*domain.PartnerCredential, error) {
+	args := m.Called(keyID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.PartnerCredential), args.Error(1)
+}
+
 // Ensure MockRedisClient implements redis.RedisClient interface
 var _ redis.RedisClient = (*MockRedisClient)(nil)
 
@@ -71,6 +201,7 @@ func (m *MockRedisClient) Eval(ctx context.Context, script string, keys []string
 	if cmd, ok := mockArgs.Get(0).(*redisClient.IntCmd); ok {
 		result := redisClient.NewCmd(ctx)
 		result.SetVal(cmd.Val())
+		result.SetErr(cmd.Err())
 		return result
 	}
 	return mockArgs.Get(0).(*redisClient.Cmd)
@@ -89,6 +220,45 @@ func (m *MockRedisClient) Set(ctx context.Context, key string, value interface{}
 	return redisClient.NewStatusResult(args.String(0), args.Error(1))
 }
 
+func (m *MockRedisClient) XAdd(ctx context.Context, a *redisClient.XAddArgs) *redisClient.StringCmd {
+	args := m.Called(ctx, a)
+	return redisClient.NewStringResult(args.String(0), args.Error(1))
+}
+
+func (m *MockRedisClient) XGroupCreateMkStream(ctx context.Context, stream, group, start string) *redisClient.StatusCmd {
+	args := m.Called(ctx, stream, group, start)
+	return redisClient.NewStatusResult(args.String(0), args.Error(1))
+}
+
+func (m *MockRedisClient) XReadGroup(ctx context.Context, a *redisClient.XReadGroupArgs) *redisClient.XStreamSliceCmd {
+	args := m.Called(ctx, a)
+	streams, _ := args.Get(0).([]redisClient.XStream)
+	return redisClient.NewXStreamSliceCmdResult(streams, args.Error(1))
+}
+
+func (m *MockRedisClient) XAutoClaim(ctx context.Context, a *redisClient.XAutoClaimArgs) *redisClient.XAutoClaimCmd {
+	args := m.Called(ctx, a)
+	cmd := redisClient.NewXAutoClaimCmd(ctx)
+	messages, _ := args.Get(0).([]redisClient.XMessage)
+	cmd.SetVal(messages, "0-0")
+	cmd.SetErr(args.Error(1))
+	return cmd
+}
+
+func (m *MockRedisClient) XPendingExt(ctx context.Context, a *redisClient.XPendingExtArgs) *redisClient.XPendingExtCmd {
+	args := m.Called(ctx, a)
+	cmd := redisClient.NewXPendingExtCmd(ctx)
+	pending, _ := args.Get(0).([]redisClient.XPendingExt)
+	cmd.SetVal(pending)
+	cmd.SetErr(args.Error(1))
+	return cmd
+}
+
+func (m *MockRedisClient) XAck(ctx context.Context, stream, group string, ids ...string) *redisClient.IntCmd {
+	args := m.Called(ctx, stream, group, ids)
+	return redisClient.NewIntResult(int64(len(ids)), args.Error(0))
+}
+
 // Implement other required methods for redis.Cmdable
 func (m *MockRedisClient) Pipeline() redisClient.Pipeliner {
 	args := m.Called()
@@ -144,3 +314,55 @@ func (m *MockRedisClient) Do(ctx context.Context, args ...interface{}) *redisCli
 	mockArgs := m.Called(append([]interface{}{ctx}, args...)...)
 	return mockArgs.Get(0).(*redisClient.Cmd)
 }
+
+// MockPaymentUseCase is a mock for PaymentUseCase interface
+type MockPaymentUseCase struct {
+	mock.Mock
+}
+
+func (m *MockPaymentUseCase) CreateVirtualAccount(transactionID uint, bankCode string) (*domain.VirtualAccount, error) {
+	args := m.Called(transactionID, bankCode)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.VirtualAccount), args.Error(1)
+}
+
+func (m *MockPaymentUseCase) ListVirtualAccounts(transactionID uint) ([]domain.VirtualAccount, error) {
+	args := m.Called(transactionID)
+	return args.Get(0).([]domain.VirtualAccount), args.Error(1)
+}
+
+func (m *MockPaymentUseCase) HandleCallback(bankCode string, callback *domain.VACallback) (*domain.Payment, error) {
+	args := m.Called(bankCode, callback)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Payment), args.Error(1)
+}
+
+func (m *MockPaymentUseCase) Reconcile(bankCode string, statementDate time.Time, format domain.StatementFormat, fileName string, statement io.Reader) (*domain.Reconciliation, error) {
+	args := m.Called(bankCode, statementDate, format, fileName, statement)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Reconciliation), args.Error(1)
+}
+
+func (m *MockPaymentUseCase) ReconcileDaily(now time.Time) (int, error) {
+	args := m.Called(now)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockPaymentUseCase) GetReconciliation(id uint) (*domain.Reconciliation, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Reconciliation), args.Error(1)
+}
+
+func (m *MockPaymentUseCase) ListReconciliations(bankCode string, offset, limit int) ([]domain.Reconciliation, error) {
+	args := m.Called(bankCode, offset, limit)
+	return args.Get(0).([]domain.Reconciliation), args.Error(1)
+}
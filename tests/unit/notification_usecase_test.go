@@ -0,0 +1,258 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	httpHandler "xyz-multifinance/internal/delivery/http"
+	"xyz-multifinance/internal/domain"
+	"xyz-multifinance/internal/middleware"
+	"xyz-multifinance/internal/pkg/notify"
+	"xyz-multifinance/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type MockNotificationRepository struct {
+	mock.Mock
+}
+
+func (m *MockNotificationRepository) GetPreference(customerID uint) (*domain.NotificationPreference, error) {
+	args := m.Called(customerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.NotificationPreference), args.Error(1)
+}
+
+func (m *MockNotificationRepository) SavePreference(preference *domain.NotificationPreference) error {
+	args := m.Called(preference)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) HasSent(reference string, channel domain.NotificationChannel) (bool, error) {
+	args := m.Called(reference, channel)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockNotificationRepository) Record(notification *domain.Notification) error {
+	args := m.Called(notification)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) List(customerID uint, offset, limit int) ([]domain.Notification, error) {
+	args := m.Called(customerID, offset, limit)
+	return args.Get(0).([]domain.Notification), args.Error(1)
+}
+
+type failingSender struct{}
+
+func (failingSender) Send(ctx context.Context, message *domain.NotificationMessage) error {
+	return errors.New("gateway unavailable")
+}
+
+func TestNotificationUseCase_SendReceipt(t *testing.T) {
+	paidAt := time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC)
+	payment := &domain.InstallmentEvent{
+		InstallmentID:     7,
+		InstallmentNumber: 2,
+		TransactionID:     5,
+		Amount:            1100000,
+		PaidAt:            &paidAt,
+	}
+	tx := &domain.Transaction{ID: 5, CustomerID: 1, ContractNumber: "XYZ-1-1", Tenor: 3}
+
+	t.Run("Sends On Enabled Channels", func(t *testing.T) {
+		mockRepo := new(MockNotificationRepository)
+		mockTxRepo := new(MockTransactionRepository)
+		email, sms := notify.NewFake(nil), notify.NewFake(nil)
+		useCase := usecase.NewNotificationUseCase(mockRepo, new(MockCustomerRepository), mockTxRepo,
+			map[domain.NotificationChannel]domain.NotificationSender{
+				domain.ChannelEmail: email,
+				domain.ChannelSMS:   sms,
+			}, nil)
+
+		mockTxRepo.On("GetByID", uint(5)).Return(tx, nil)
+		mockRepo.On("GetPreference", uint(1)).Return(&domain.NotificationPreference{
+			CustomerID:   1,
+			Locale:       domain.LocaleID,
+			Email:        "budi@example.com",
+			Phone:        "+6281234567890",
+			EmailEnabled: true,
+			SMSEnabled:   false,
+		}, nil)
+		mockRepo.On("HasSent", "installment:7:receipt", domain.ChannelEmail).Return(false, nil)
+		mockRepo.On("Record", mock.MatchedBy(func(n *domain.Notification) bool {
+			return n.Channel == domain.ChannelEmail && n.Status == domain.NotificationSent &&
+				n.Kind == domain.NotificationPaymentReceipt && n.CustomerID == 1
+		})).Return(nil)
+
+		sent, err := useCase.SendReceipt(context.Background(), payment)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, sent)
+		assert.Empty(t, sms.Messages())
+		require.Len(t, email.Messages(), 1)
+		assert.Equal(t, "budi@example.com", email.Messages()[0].Recipient)
+		assert.Equal(t, "Pembayaran cicilan ke-2/3 kontrak XYZ-1-1 sebesar Rp1.100.000 telah kami terima pada 5 Maret 2024. Terima kasih.", email.Messages()[0].Body)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Skips Channels Already Sent", func(t *testing.T) {
+		mockRepo := new(MockNotificationRepository)
+		mockTxRepo := new(MockTransactionRepository)
+		email := notify.NewFake(nil)
+		useCase := usecase.NewNotificationUseCase(mockRepo, new(MockCustomerRepository), mockTxRepo,
+			map[domain.NotificationChannel]domain.NotificationSender{domain.ChannelEmail: email}, nil)
+
+		mockTxRepo.On("GetByID", uint(5)).Return(tx, nil)
+		mockRepo.On("GetPreference", uint(1)).Return(&domain.NotificationPreference{
+			CustomerID: 1, Locale: domain.LocaleID, Email: "budi@example.com", EmailEnabled: true,
+		}, nil)
+		mockRepo.On("HasSent", "installment:7:receipt", domain.ChannelEmail).Return(true, nil)
+
+		sent, err := useCase.SendReceipt(context.Background(), payment)
+
+		require.NoError(t, err)
+		assert.Zero(t, sent)
+		assert.Empty(t, email.Messages())
+		mockRepo.AssertNotCalled(t, "Record", mock.Anything)
+	})
+
+	t.Run("Customer Without Preference", func(t *testing.T) {
+		mockRepo := new(MockNotificationRepository)
+		mockTxRepo := new(MockTransactionRepository)
+		useCase := usecase.NewNotificationUseCase(mockRepo, new(MockCustomerRepository), mockTxRepo,
+			map[domain.NotificationChannel]domain.NotificationSender{domain.ChannelEmail: notify.NewFake(nil)}, nil)
+
+		mockTxRepo.On("GetByID", uint(5)).Return(tx, nil)
+		mockRepo.On("GetPreference", uint(1)).Return(nil, domain.NewError(domain.ErrNotFound, "notification_preference_not_found", "notification preference not found"))
+
+		sent, err := useCase.SendReceipt(context.Background(), payment)
+
+		assert.NoError(t, err)
+		assert.Zero(t, sent)
+	})
+}
+
+func TestNotificationUseCase_SendReminders(t *testing.T) {
+	now := time.Date(2024, 3, 2, 8, 0, 0, 0, time.UTC)
+	from := time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 1)
+
+	mockRepo := new(MockNotificationRepository)
+	mockTxRepo := new(MockTransactionRepository)
+	push := notify.NewFake(nil)
+	useCase := usecase.NewNotificationUseCase(mockRepo, new(MockCustomerRepository), mockTxRepo,
+		map[domain.NotificationChannel]domain.NotificationSender{
+			domain.ChannelSMS:  failingSender{},
+			domain.ChannelPush: push,
+		}, []int{3})
+
+	mockTxRepo.On("ListInstallmentsDueBetween", from, to, uint(0), 100).Return([]domain.Installment{
+		{
+			ID:                9,
+			InstallmentNumber: 1,
+			Amount:            375000,
+			DueDate:           from,
+			Transaction:       &domain.Transaction{CustomerID: 1, ContractNumber: "XYZ-1-1", Tenor: 3},
+		},
+	}, nil)
+	mockRepo.On("GetPreference", uint(1)).Return(&domain.NotificationPreference{
+		CustomerID:  1,
+		Locale:      domain.LocaleEN,
+		Phone:       "+6281234567890",
+		PushToken:   "device-token",
+		SMSEnabled:  true,
+		PushEnabled: true,
+	}, nil)
+	mockRepo.On("HasSent", "installment:9:reminder:3", mock.Anything).Return(false, nil)
+	mockRepo.On("Record", mock.MatchedBy(func(n *domain.Notification) bool {
+		return n.Channel == domain.ChannelSMS && n.Status == domain.NotificationFailed && n.LastError == "gateway unavailable"
+	})).Return(nil)
+	mockRepo.On("Record", mock.MatchedBy(func(n *domain.Notification) bool {
+		return n.Channel == domain.ChannelPush && n.Status == domain.NotificationSent
+	})).Return(nil)
+
+	sent, err := useCase.SendReminders(context.Background(), now)
+
+	assert.Error(t, err)
+	assert.Equal(t, 1, sent)
+	require.Len(t, push.Messages(), 1)
+	assert.Equal(t, "Installment 1 due on 5 March 2024", push.Messages()[0].Subject)
+	assert.Equal(t, "Installment 1/3 of contract XYZ-1-1 for Rp375.000 is due in 3 days (5 March 2024). Please ignore this message if you have already paid.", push.Messages()[0].Body)
+	mockRepo.AssertExpectations(t)
+	mockTxRepo.AssertExpectations(t)
+}
+
+func TestNotificationUseCase_UpdatePreference(t *testing.T) {
+	t.Run("Invalid Locale", func(t *testing.T) {
+		useCase := usecase.NewNotificationUseCase(new(MockNotificationRepository), new(MockCustomerRepository), new(MockTransactionRepository), nil, nil)
+
+		_, err := useCase.UpdatePreference(&domain.NotificationPreference{CustomerID: 1, Locale: "fr"})
+
+		assert.ErrorIs(t, err, domain.ErrValidation)
+	})
+
+	t.Run("Default Preference", func(t *testing.T) {
+		mockRepo := new(MockNotificationRepository)
+		mockCustomerRepo := new(MockCustomerRepository)
+		useCase := usecase.NewNotificationUseCase(mockRepo, mockCustomerRepo, new(MockTransactionRepository), nil, nil)
+
+		mockRepo.On("GetPreference", uint(1)).Return(nil, domain.NewError(domain.ErrNotFound, "notification_preference_not_found", "notification preference not found"))
+		mockCustomerRepo.On("GetByID", uint(1)).Return(&domain.Customer{ID: 1}, nil)
+
+		preference, err := useCase.GetPreference(1)
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.LocaleID, preference.Locale)
+		assert.True(t, preference.EmailEnabled)
+	})
+}
+
+func TestNotificationHandler_CustomerOrBackOffice(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	authConfig := middleware.AuthConfig{SecretKey: "jwt-secret"}
+	mockRepo := new(MockNotificationRepository)
+	router := gin.New()
+	router.Use(middleware.NewErrorHandlerMiddleware(zap.NewNop()))
+	httpHandler.NewNotificationHandler(router,
+		usecase.NewNotificationUseCase(mockRepo, new(MockCustomerRepository), new(MockTransactionRepository), nil, nil),
+		middleware.NewAuthMiddleware(authConfig),
+		middleware.RequireCustomerOrRole("id", "admin", "operator"),
+	)
+
+	mockRepo.On("List", uint(1), 0, 10).Return([]domain.Notification{}, nil)
+
+	tests := []struct {
+		name          string
+		authorization string
+		code          int
+	}{
+		{"Unauthenticated", "", http.StatusUnauthorized},
+		{"Own Notifications", bearerToken(t, authConfig, 1, "customer"), http.StatusOK},
+		{"Other Customer", bearerToken(t, authConfig, 2, "customer"), http.StatusForbidden},
+		{"Operator", bearerToken(t, authConfig, 7, "operator"), http.StatusOK},
+		{"Collector", bearerToken(t, authConfig, 7, "collector"), http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/customers/1/notifications", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.code, w.Code)
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	httpHandler "xyz-multifinance/internal/delivery/http"
+	"xyz-multifinance/internal/domain"
+	"xyz-multifinance/internal/middleware"
+	"xyz-multifinance/internal/pkg/signing"
+
+	"github.com/gin-gonic/gin"
+	redisClient "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+var (
+	testPartner = &domain.Partner{ID: 5, Code: "TOKOKU", Source: domain.SourceECommerce, Status: domain.PartnerActive}
+	testSecret  = []byte("partner-secret")
+)
+
+const testNonce = "0123456789abcdef"
+
+func newPartnerRouter(partners domain.PartnerUseCase, redis *MockRedisClient, transactions domain.TransactionUseCase) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.NewErrorHandlerMiddleware(zap.NewNop()))
+	partnerAuth := middleware.NewPartnerAuthMiddleware(middleware.PartnerAuthConfig{
+		Partners: partners,
+		Nonces:   signing.NewRedisNonceStore(redis),
+	})
+	httpHandler.NewTransactionHandler(router, transactions, noRateLimit, partnerAuth)
+	return router
+}
+
+func signedRequest(body string, timestamp time.Time, nonce string, secret []byte) *http.Request {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/transactions", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(signing.HeaderKeyID, "pk_1")
+	req.Header.Set(signing.HeaderTimestamp, ts)
+	req.Header.Set(signing.HeaderNonce, nonce)
+	req.Header.Set(signing.HeaderSignature, signing.Sign(secret, signing.StringToSign(http.MethodPost, "/api/v1/transactions", ts, nonce, []byte(body))))
+	return req
+}
+
+func TestSigning_Verify(t *testing.T) {
+	message := signing.StringToSign("post", "/api/v1/transactions?a=1", "1700000000", testNonce, []byte(`{}`))
+	signature := signing.Sign(testSecret, message)
+
+	assert.True(t, signing.Verify(testSecret, message, signature))
+	assert.False(t, signing.Verify([]byte("other"), message, signature))
+	assert.False(t, signing.Verify(testSecret, message+"x", signature))
+	assert.False(t, signing.Verify(testSecret, message, "not-hex"))
+}
+
+func TestPartnerAuthMiddleware(t *testing.T) {
+	body := `{"customer_id":1,"source":"dealer","asset_name":"Laptop","otr_amount":10000000,"admin_fee":100000,"installment_amount":2575000,"interest_amount":200000,"tenor":4}`
+	credential := &domain.PartnerCredential{Partner: testPartner, Secret: testSecret}
+
+	t.Run("Source Derived From Partner", func(t *testing.T) {
+		partners := new(MockPartnerUseCase)
+		redis := new(MockRedisClient)
+		transactions := new(MockTransactionUseCase)
+		router := newPartnerRouter(partners, redis, transactions)
+
+		partners.On("Authenticate", "pk_1").Return(credential, nil)
+		redis.On("SetNX", mock.Anything, "nonce:pk_1:"+testNonce, 1, 10*time.Minute).Return(redisClient.NewBoolResult(true, nil))
+		transactions.On("Create", mock.MatchedBy(func(tx *domain.Transaction) bool {
+			return tx.Source == domain.SourceECommerce && tx.PartnerID != nil && *tx.PartnerID == testPartner.ID
+		})).Return(nil)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, signedRequest(body, time.Now(), testNonce, testSecret))
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		transactions.AssertExpectations(t)
+	})
+
+	t.Run("Missing Signature", func(t *testing.T) {
+		transactions := new(MockTransactionUseCase)
+		router := newPartnerRouter(new(MockPartnerUseCase), new(MockRedisClient), transactions)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/transactions", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"missing_signature"`)
+		transactions.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("Stale Timestamp", func(t *testing.T) {
+		router := newPartnerRouter(new(MockPartnerUseCase), new(MockRedisClient), new(MockTransactionUseCase))
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, signedRequest(body, time.Now().Add(-10*time.Minute), testNonce, testSecret))
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"stale_request"`)
+	})
+
+	t.Run("Invalid Signature", func(t *testing.T) {
+		partners := new(MockPartnerUseCase)
+		redis := new(MockRedisClient)
+		router := newPartnerRouter(partners, redis, new(MockTransactionUseCase))
+
+		partners.On("Authenticate", "pk_1").Return(credential, nil)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, signedRequest(body, time.Now(), testNonce, []byte("wrong-secret")))
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"invalid_signature"`)
+		redis.AssertNotCalled(t, "SetNX", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Tampered Body", func(t *testing.T) {
+		partners := new(MockPartnerUseCase)
+		router := newPartnerRouter(partners, new(MockRedisClient), new(MockTransactionUseCase))
+
+		partners.On("Authenticate", "pk_1").Return(credential, nil)
+		req := signedRequest(body, time.Now(), testNonce, testSecret)
+		req.Body = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(strings.Replace(body, `"tenor":4`, `"tenor":1`, 1))).Body
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("Replayed Nonce", func(t *testing.T) {
+		partners := new(MockPartnerUseCase)
+		redis := new(MockRedisClient)
+		transactions := new(MockTransactionUseCase)
+		router := newPartnerRouter(partners, redis, transactions)
+
+		partners.On("Authenticate", "pk_1").Return(credential, nil)
+		redis.On("SetNX", mock.Anything, "nonce:pk_1:"+testNonce, 1, 10*time.Minute).Return(redisClient.NewBoolResult(false, nil))
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, signedRequest(body, time.Now(), testNonce, testSecret))
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"replayed_request"`)
+		transactions.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("Suspended Partner", func(t *testing.T) {
+		partners := new(MockPartnerUseCase)
+		router := newPartnerRouter(partners, new(MockRedisClient), new(MockTransactionUseCase))
+
+		partners.On("Authenticate", "pk_1").Return(nil, domain.NewError(domain.ErrForbidden, "partner_suspended", "partner is suspended"))
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, signedRequest(body, time.Now(), testNonce, testSecret))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
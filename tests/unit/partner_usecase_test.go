@@ -0,0 +1,179 @@
+package tests
+
+import (
+	"testing"
+	"time"
+	"xyz-multifinance/internal/domain"
+	"xyz-multifinance/internal/pkg/crypto"
+	"xyz-multifinance/internal/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockPartnerRepository struct {
+	mock.Mock
+}
+
+func (m *MockPartnerRepository) Create(partner *domain.Partner) error {
+	args := m.Called(partner)
+	return args.Error(0)
+}
+
+func (m *MockPartnerRepository) GetByID(id uint) (*domain.Partner, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Partner), args.Error(1)
+}
+
+func (m *MockPartnerRepository) List(offset, limit int) ([]domain.Partner, error) {
+	args := m.Called(offset, limit)
+	return args.Get(0).([]domain.Partner), args.Error(1)
+}
+
+func (m *MockPartnerRepository) UpdateStatus(id uint, status domain.PartnerStatus) error {
+	args := m.Called(id, status)
+	return args.Error(0)
+}
+
+func (m *MockPartnerRepository) CreateKey(key *domain.PartnerAPIKey) error {
+	args := m.Called(key)
+	return args.Error(0)
+}
+
+func (m *MockPartnerRepository) GetKeyByKeyID(keyID string) (*domain.PartnerAPIKey, error) {
+	args := m.Called(keyID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.PartnerAPIKey), args.Error(1)
+}
+
+func (m *MockPartnerRepository) ListKeys(partnerID uint) ([]domain.PartnerAPIKey, error) {
+	args := m.Called(partnerID)
+	return args.Get(0).([]domain.PartnerAPIKey), args.Error(1)
+}
+
+func (m *MockPartnerRepository) RotateKeys(key *domain.PartnerAPIKey, expiresAt time.Time) error {
+	args := m.Called(key, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockPartnerRepository) RevokeKey(partnerID uint, keyID string) error {
+	args := m.Called(partnerID, keyID)
+	return args.Error(0)
+}
+
+func (m *MockPartnerRepository) TouchKey(id uint, usedAt time.Time) error {
+	args := m.Called(id, usedAt)
+	return args.Error(0)
+}
+
+func initTestEncryption(t *testing.T) {
+	require.NoError(t, crypto.InitEncryption("0123456789abcdef0123456789abcdef"))
+}
+
+func TestPartnerUseCase_IssueKey(t *testing.T) {
+	initTestEncryption(t)
+	mockRepo := new(MockPartnerRepository)
+	useCase := usecase.NewPartnerUseCase(mockRepo, 24*time.Hour)
+
+	mockRepo.On("GetByID", uint(1)).Return(&domain.Partner{ID: 1}, nil)
+	mockRepo.On("CreateKey", mock.AnythingOfType("*domain.PartnerAPIKey")).Return(nil)
+
+	issued, err := useCase.IssueKey(1)
+
+	require.NoError(t, err)
+	assert.Regexp(t, `^pk_[0-9a-f]{24}$`, issued.KeyID)
+	assert.NotEmpty(t, issued.Secret)
+	assert.NotEqual(t, issued.Secret, issued.PartnerAPIKey.Secret, "secret must be stored encrypted")
+
+	decrypted, err := crypto.Decrypt(issued.PartnerAPIKey.Secret)
+	require.NoError(t, err)
+	assert.Equal(t, issued.Secret, decrypted)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPartnerUseCase_RotateKey(t *testing.T) {
+	initTestEncryption(t)
+	mockRepo := new(MockPartnerRepository)
+	useCase := usecase.NewPartnerUseCase(mockRepo, 24*time.Hour)
+
+	mockRepo.On("GetByID", uint(1)).Return(&domain.Partner{ID: 1}, nil)
+	mockRepo.On("RotateKeys", mock.AnythingOfType("*domain.PartnerAPIKey"), mock.MatchedBy(func(expiresAt time.Time) bool {
+		return expiresAt.Sub(time.Now().Add(24*time.Hour)).Abs() < time.Minute
+	})).Return(nil)
+
+	issued, err := useCase.RotateKey(1)
+
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), issued.PartnerID)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPartnerUseCase_Authenticate(t *testing.T) {
+	initTestEncryption(t)
+	secret, err := crypto.Encrypt("s3cret")
+	require.NoError(t, err)
+	past := time.Now().Add(-time.Minute)
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockPartnerRepository)
+		useCase := usecase.NewPartnerUseCase(mockRepo, time.Hour)
+
+		mockRepo.On("GetKeyByKeyID", "pk_1").Return(&domain.PartnerAPIKey{ID: 7, PartnerID: 1, KeyID: "pk_1", Secret: secret, Status: domain.APIKeyActive}, nil)
+		mockRepo.On("GetByID", uint(1)).Return(&domain.Partner{ID: 1, Source: domain.SourceDealer, Status: domain.PartnerActive}, nil)
+		mockRepo.On("TouchKey", uint(7), mock.AnythingOfType("time.Time")).Return(nil)
+
+		credential, err := useCase.Authenticate("pk_1")
+
+		require.NoError(t, err)
+		assert.Equal(t, []byte("s3cret"), credential.Secret)
+		assert.Equal(t, domain.SourceDealer, credential.Partner.Source)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Unknown Key", func(t *testing.T) {
+		mockRepo := new(MockPartnerRepository)
+		useCase := usecase.NewPartnerUseCase(mockRepo, time.Hour)
+
+		mockRepo.On("GetKeyByKeyID", "pk_x").Return(nil, domain.NewError(domain.ErrNotFound, "api_key_not_found", "api key not found"))
+
+		_, err := useCase.Authenticate("pk_x")
+
+		assert.ErrorIs(t, err, domain.ErrUnauthorized)
+	})
+
+	t.Run("Revoked Or Expired Key", func(t *testing.T) {
+		keys := []*domain.PartnerAPIKey{
+			{PartnerID: 1, Secret: secret, Status: domain.APIKeyRevoked},
+			{PartnerID: 1, Secret: secret, Status: domain.APIKeyActive, ExpiresAt: &past},
+		}
+		for _, key := range keys {
+			mockRepo := new(MockPartnerRepository)
+			useCase := usecase.NewPartnerUseCase(mockRepo, time.Hour)
+
+			mockRepo.On("GetKeyByKeyID", "pk_1").Return(key, nil)
+
+			_, err := useCase.Authenticate("pk_1")
+
+			assert.ErrorIs(t, err, domain.ErrUnauthorized)
+			mockRepo.AssertNotCalled(t, "GetByID", mock.Anything)
+		}
+	})
+
+	t.Run("Suspended Partner", func(t *testing.T) {
+		mockRepo := new(MockPartnerRepository)
+		useCase := usecase.NewPartnerUseCase(mockRepo, time.Hour)
+
+		mockRepo.On("GetKeyByKeyID", "pk_1").Return(&domain.PartnerAPIKey{PartnerID: 1, Secret: secret, Status: domain.APIKeyActive}, nil)
+		mockRepo.On("GetByID", uint(1)).Return(&domain.Partner{ID: 1, Status: domain.PartnerSuspended}, nil)
+
+		_, err := useCase.Authenticate("pk_1")
+
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+	})
+}
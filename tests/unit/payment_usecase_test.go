@@ -0,0 +1,347 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	httpHandler "xyz-multifinance/internal/delivery/http"
+	"xyz-multifinance/internal/domain"
+	"xyz-multifinance/internal/middleware"
+	"xyz-multifinance/internal/pkg/signing"
+	"xyz-multifinance/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	redisClient "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type MockPaymentRepository struct {
+	mock.Mock
+}
+
+func (m *MockPaymentRepository) CreateVirtualAccount(account *domain.VirtualAccount) error {
+	args := m.Called(account)
+	return args.Error(0)
+}
+
+func (m *MockPaymentRepository) GetVirtualAccountByNumber(number string) (*domain.VirtualAccount, error) {
+	args := m.Called(number)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.VirtualAccount), args.Error(1)
+}
+
+func (m *MockPaymentRepository) ListVirtualAccounts(transactionID uint) ([]domain.VirtualAccount, error) {
+	args := m.Called(transactionID)
+	return args.Get(0).([]domain.VirtualAccount), args.Error(1)
+}
+
+func (m *MockPaymentRepository) CreatePayment(payment *domain.Payment) error {
+	args := m.Called(payment)
+	return args.Error(0)
+}
+
+func (m *MockPaymentRepository) GetPayment(id uint) (*domain.Payment, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Payment), args.Error(1)
+}
+
+func (m *MockPaymentRepository) GetPaymentByReference(bankCode, reference string) (*domain.Payment, error) {
+	args := m.Called(bankCode, reference)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Payment), args.Error(1)
+}
+
+func (m *MockPaymentRepository) UpdatePayment(payment *domain.Payment) error {
+	args := m.Called(payment)
+	return args.Error(0)
+}
+
+func (m *MockPaymentRepository) ListPayments(bankCode string, from, to time.Time) ([]domain.Payment, error) {
+	args := m.Called(bankCode, from, to)
+	return args.Get(0).([]domain.Payment), args.Error(1)
+}
+
+func (m *MockPaymentRepository) CreateReconciliation(reconciliation *domain.Reconciliation) error {
+	args := m.Called(reconciliation)
+	return args.Error(0)
+}
+
+func (m *MockPaymentRepository) GetReconciliation(id uint) (*domain.Reconciliation, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Reconciliation), args.Error(1)
+}
+
+func (m *MockPaymentRepository) FindReconciliation(bankCode string, statementDate time.Time) (*domain.Reconciliation, error) {
+	args := m.Called(bankCode, statementDate)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Reconciliation), args.Error(1)
+}
+
+func (m *MockPaymentRepository) ListReconciliations(bankCode string, offset, limit int) ([]domain.Reconciliation, error) {
+	args := m.Called(bankCode, offset, limit)
+	return args.Get(0).([]domain.Reconciliation), args.Error(1)
+}
+
+var testBanks = map[string]string{"bca": "39358"}
+
+func errPaymentNotFound() error {
+	return domain.NewError(domain.ErrNotFound, "payment_not_found", "payment not found")
+}
+
+func TestPaymentUseCase_CreateVirtualAccount(t *testing.T) {
+	mockRepo := new(MockPaymentRepository)
+	mockTxRepo := new(MockTransactionRepository)
+	useCase := usecase.NewPaymentUseCase(mockRepo, mockTxRepo, new(MockTransactionUseCase), testBanks, "")
+
+	mockTxRepo.On("GetByID", uint(42)).Return(&domain.Transaction{ID: 42, Status: domain.StatusApproved}, nil)
+	mockRepo.On("ListVirtualAccounts", uint(42)).Return([]domain.VirtualAccount{}, nil)
+	mockRepo.On("CreateVirtualAccount", mock.AnythingOfType("*domain.VirtualAccount")).Return(nil)
+
+	account, err := useCase.CreateVirtualAccount(42, "bca")
+
+	require.NoError(t, err)
+	assert.Equal(t, "3935800000000042", account.Number)
+	assert.Equal(t, domain.VirtualAccountActive, account.Status)
+
+	_, err = useCase.CreateVirtualAccount(42, "mandiri")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestPaymentUseCase_HandleCallback(t *testing.T) {
+	account := &domain.VirtualAccount{ID: 3, TransactionID: 42, BankCode: "bca", Number: "3935800000000042", Status: domain.VirtualAccountActive}
+	installments := []domain.Installment{
+		{ID: 12, InstallmentNumber: 2, Amount: 375000, Status: "unpaid"},
+		{ID: 11, InstallmentNumber: 1, Amount: 375000, Status: "paid"},
+		{ID: 13, InstallmentNumber: 3, Amount: 375000, Status: "unpaid"},
+	}
+	callback := &domain.VACallback{Number: account.Number, Reference: "BCA123", Amount: 375000, PaidAt: time.Now()}
+
+	t.Run("Credits Oldest Outstanding Installment", func(t *testing.T) {
+		mockRepo := new(MockPaymentRepository)
+		mockTxRepo := new(MockTransactionRepository)
+		mockTxUseCase := new(MockTransactionUseCase)
+		useCase := usecase.NewPaymentUseCase(mockRepo, mockTxRepo, mockTxUseCase, testBanks, "")
+
+		mockRepo.On("GetPaymentByReference", "bca", "BCA123").Return(nil, errPaymentNotFound())
+		mockRepo.On("GetVirtualAccountByNumber", account.Number).Return(account, nil)
+		mockRepo.On("CreatePayment", mock.AnythingOfType("*domain.Payment")).Return(nil)
+		mockTxRepo.On("GetInstallments", uint(42)).Return(append([]domain.Installment(nil), installments...), nil)
+		mockTxUseCase.On("PayInstallment", uint(12)).Return(nil)
+		mockRepo.On("UpdatePayment", mock.MatchedBy(func(p *domain.Payment) bool {
+			return p.Status == domain.PaymentCredited && p.InstallmentID != nil && *p.InstallmentID == 12
+		})).Return(nil)
+
+		payment, err := useCase.HandleCallback("bca", callback)
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.PaymentCredited, payment.Status)
+		mockRepo.AssertExpectations(t)
+		mockTxUseCase.AssertExpectations(t)
+	})
+
+	t.Run("Amount Mismatch Is Rejected", func(t *testing.T) {
+		mockRepo := new(MockPaymentRepository)
+		mockTxRepo := new(MockTransactionRepository)
+		mockTxUseCase := new(MockTransactionUseCase)
+		useCase := usecase.NewPaymentUseCase(mockRepo, mockTxRepo, mockTxUseCase, testBanks, "")
+
+		mockRepo.On("GetPaymentByReference", "bca", "BCA124").Return(nil, errPaymentNotFound())
+		mockRepo.On("GetVirtualAccountByNumber", account.Number).Return(account, nil)
+		mockRepo.On("CreatePayment", mock.AnythingOfType("*domain.Payment")).Return(nil)
+		mockTxRepo.On("GetInstallments", uint(42)).Return(append([]domain.Installment(nil), installments...), nil)
+		mockRepo.On("UpdatePayment", mock.MatchedBy(func(p *domain.Payment) bool {
+			return p.Status == domain.PaymentRejected && p.RejectReason == "amount_mismatch"
+		})).Return(nil)
+
+		payment, err := useCase.HandleCallback("bca", &domain.VACallback{Number: account.Number, Reference: "BCA124", Amount: 300000, PaidAt: time.Now()})
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.PaymentRejected, payment.Status)
+		mockTxUseCase.AssertNotCalled(t, "PayInstallment", mock.Anything)
+	})
+
+	t.Run("Late Fee Must Be Paid With Installment", func(t *testing.T) {
+		overdue := []domain.Installment{{ID: 12, InstallmentNumber: 2, Amount: 375000, LateFee: 7500, Status: "overdue"}}
+
+		mockRepo := new(MockPaymentRepository)
+		mockTxRepo := new(MockTransactionRepository)
+		mockTxUseCase := new(MockTransactionUseCase)
+		useCase := usecase.NewPaymentUseCase(mockRepo, mockTxRepo, mockTxUseCase, testBanks, "")
+
+		mockRepo.On("GetPaymentByReference", "bca", mock.Anything).Return(nil, errPaymentNotFound())
+		mockRepo.On("GetVirtualAccountByNumber", account.Number).Return(account, nil)
+		mockRepo.On("CreatePayment", mock.AnythingOfType("*domain.Payment")).Return(nil)
+		mockTxRepo.On("GetInstallments", uint(42)).Return(overdue, nil)
+		mockTxUseCase.On("PayInstallment", uint(12)).Return(nil)
+		mockRepo.On("UpdatePayment", mock.AnythingOfType("*domain.Payment")).Return(nil)
+
+		payment, err := useCase.HandleCallback("bca", &domain.VACallback{Number: account.Number, Reference: "BCA125", Amount: 375000, PaidAt: time.Now()})
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.PaymentRejected, payment.Status)
+		assert.Equal(t, "amount_mismatch", payment.RejectReason)
+		mockTxUseCase.AssertNotCalled(t, "PayInstallment", mock.Anything)
+
+		payment, err = useCase.HandleCallback("bca", &domain.VACallback{Number: account.Number, Reference: "BCA126", Amount: 382500, PaidAt: time.Now()})
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.PaymentCredited, payment.Status)
+		mockTxUseCase.AssertCalled(t, "PayInstallment", uint(12))
+	})
+
+	t.Run("Contract Not Approved Is Rejected", func(t *testing.T) {
+		mockRepo := new(MockPaymentRepository)
+		mockTxRepo := new(MockTransactionRepository)
+		mockTxUseCase := new(MockTransactionUseCase)
+		useCase := usecase.NewPaymentUseCase(mockRepo, mockTxRepo, mockTxUseCase, testBanks, "")
+
+		mockRepo.On("GetPaymentByReference", "bca", "BCA125").Return(nil, errPaymentNotFound())
+		mockRepo.On("GetVirtualAccountByNumber", account.Number).Return(account, nil)
+		mockRepo.On("CreatePayment", mock.AnythingOfType("*domain.Payment")).Return(nil)
+		mockTxRepo.On("GetInstallments", uint(42)).Return(append([]domain.Installment(nil), installments...), nil)
+		mockTxUseCase.On("PayInstallment", uint(12)).
+			Return(domain.NewError(domain.ErrConflict, "contract_not_approved", "installments can only be paid on approved contracts"))
+		mockRepo.On("UpdatePayment", mock.MatchedBy(func(p *domain.Payment) bool {
+			return p.Status == domain.PaymentRejected && p.RejectReason == "contract_not_approved"
+		})).Return(nil)
+
+		payment, err := useCase.HandleCallback("bca", &domain.VACallback{Number: account.Number, Reference: "BCA125", Amount: 375000, PaidAt: time.Now()})
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.PaymentRejected, payment.Status)
+	})
+
+	t.Run("Repeated Callback", func(t *testing.T) {
+		mockRepo := new(MockPaymentRepository)
+		mockTxUseCase := new(MockTransactionUseCase)
+		useCase := usecase.NewPaymentUseCase(mockRepo, new(MockTransactionRepository), mockTxUseCase, testBanks, "")
+
+		recorded := &domain.Payment{ID: 8, Reference: "BCA123", Status: domain.PaymentCredited}
+		mockRepo.On("GetPaymentByReference", "bca", "BCA123").Return(recorded, nil)
+
+		payment, err := useCase.HandleCallback("bca", callback)
+
+		require.NoError(t, err)
+		assert.Equal(t, recorded, payment)
+		mockRepo.AssertNotCalled(t, "CreatePayment", mock.Anything)
+		mockTxUseCase.AssertNotCalled(t, "PayInstallment", mock.Anything)
+	})
+}
+
+func TestPaymentUseCase_Reconcile(t *testing.T) {
+	mockRepo := new(MockPaymentRepository)
+	useCase := usecase.NewPaymentUseCase(mockRepo, new(MockTransactionRepository), new(MockTransactionUseCase), testBanks, "")
+
+	day := time.Date(2024, 3, 5, 0, 0, 0, 0, time.Local)
+	mockRepo.On("ListPayments", "bca", day, day.AddDate(0, 0, 1)).Return([]domain.Payment{
+		{ID: 1, Reference: "REF1", Amount: 375000},
+		{ID: 2, Reference: "REF2", Amount: 375000},
+		{ID: 3, Reference: "REF3", Amount: 500000},
+	}, nil)
+	mockRepo.On("CreateReconciliation", mock.AnythingOfType("*domain.Reconciliation")).Return(nil)
+
+	statement := strings.Join([]string{
+		"date,reference,va_number,amount,type",
+		"2024-03-05,REF1,3935800000000042,375000.00,C",
+		"2024-03-05,REF2,3935800000000043,370000.00,C",
+		"2024-03-05,REF9,3935800000000044,250000.00,C",
+		"2024-03-05,FEE,3935800000000044,6500.00,D",
+	}, "\n")
+
+	reconciliation, err := useCase.Reconcile("bca", time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC), domain.StatementCSV, "20240305.csv", strings.NewReader(statement))
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, reconciliation.Entries)
+	assert.Equal(t, 1, reconciliation.Matched)
+	assert.Equal(t, domain.ReconciliationMismatched, reconciliation.Status)
+	require.Len(t, reconciliation.Mismatches, 3)
+	assert.Equal(t, domain.MismatchAmount, reconciliation.Mismatches[0].Kind)
+	assert.Equal(t, "REF2", reconciliation.Mismatches[0].Reference)
+	assert.Equal(t, domain.MismatchMissingPayment, reconciliation.Mismatches[1].Kind)
+	assert.Equal(t, "REF9", reconciliation.Mismatches[1].Reference)
+	assert.Equal(t, domain.MismatchMissingStatement, reconciliation.Mismatches[2].Kind)
+	assert.Equal(t, "REF3", reconciliation.Mismatches[2].Reference)
+}
+
+func newBankCallbackRouter(payments domain.PaymentUseCase, redis *MockRedisClient) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.NewErrorHandlerMiddleware(zap.NewNop()))
+	callbackAuth := middleware.NewBankCallbackMiddleware(middleware.BankCallbackConfig{
+		Secrets: map[string][]byte{"bca": []byte("bca-secret")},
+		Nonces:  signing.NewRedisNonceStore(redis),
+	})
+	httpHandler.NewPaymentHandler(router, payments, noRateLimit, callbackAuth)
+	return router
+}
+
+func signedCallback(bank, body string, secret []byte) *http.Request {
+	path := "/api/v1/payments/callbacks/" + bank
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(signing.HeaderTimestamp, ts)
+	req.Header.Set(signing.HeaderNonce, testNonce)
+	req.Header.Set(signing.HeaderSignature, signing.Sign(secret, signing.StringToSign(http.MethodPost, path, ts, testNonce, []byte(body))))
+	return req
+}
+
+func TestBankCallbackMiddleware(t *testing.T) {
+	body := `{"va_number":"3935800000000042","reference":"BCA123","amount":375000,"paid_at":"2024-03-05T10:00:00+07:00"}`
+
+	t.Run("Signed Callback", func(t *testing.T) {
+		payments := new(MockPaymentUseCase)
+		redis := new(MockRedisClient)
+		router := newBankCallbackRouter(payments, redis)
+
+		redis.On("SetNX", mock.Anything, "nonce:bank:bca:"+testNonce, 1, 10*time.Minute).Return(redisClient.NewBoolResult(true, nil))
+		payments.On("HandleCallback", "bca", mock.MatchedBy(func(cb *domain.VACallback) bool {
+			return cb.Number == "3935800000000042" && cb.Reference == "BCA123" && cb.Amount == 375000
+		})).Return(&domain.Payment{ID: 1, Status: domain.PaymentCredited}, nil)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, signedCallback("bca", body, []byte("bca-secret")))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		payments.AssertExpectations(t)
+	})
+
+	t.Run("Invalid Signature", func(t *testing.T) {
+		payments := new(MockPaymentUseCase)
+		router := newBankCallbackRouter(payments, new(MockRedisClient))
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, signedCallback("bca", body, []byte("wrong-secret")))
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"invalid_signature"`)
+		payments.AssertNotCalled(t, "HandleCallback", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Unknown Bank", func(t *testing.T) {
+		router := newBankCallbackRouter(new(MockPaymentUseCase), new(MockRedisClient))
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, signedCallback("mandiri", body, []byte("bca-secret")))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
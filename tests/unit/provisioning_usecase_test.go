@@ -0,0 +1,251 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"xyz-multifinance/internal/domain"
+	"xyz-multifinance/internal/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockProvisioningRepository is a mock implementation of domain.ProvisioningRepository
+type MockProvisioningRepository struct {
+	mock.Mock
+}
+
+func (m *MockProvisioningRepository) ListExposures(asOf time.Time, afterTransactionID uint, limit int) ([]domain.Exposure, error) {
+	args := m.Called(asOf, afterTransactionID, limit)
+	return args.Get(0).([]domain.Exposure), args.Error(1)
+}
+
+func (m *MockProvisioningRepository) WriteOffTotals(from, to time.Time) (int, float64, float64, error) {
+	args := m.Called(from, to)
+	return args.Int(0), args.Get(1).(float64), args.Get(2).(float64), args.Error(3)
+}
+
+func (m *MockProvisioningRepository) CreateReport(report *domain.ProvisionReport) error {
+	args := m.Called(report)
+	return args.Error(0)
+}
+
+func (m *MockProvisioningRepository) GetReport(period string) (*domain.ProvisionReport, error) {
+	args := m.Called(period)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ProvisionReport), args.Error(1)
+}
+
+func (m *MockProvisioningRepository) ListReports(offset, limit int) ([]domain.ProvisionReport, error) {
+	args := m.Called(offset, limit)
+	return args.Get(0).([]domain.ProvisionReport), args.Error(1)
+}
+
+// MockWriteOffRepository is a mock implementation of domain.WriteOffRepository
+type MockWriteOffRepository struct {
+	mock.Mock
+}
+
+func (m *MockWriteOffRepository) Create(writeOff *domain.WriteOff, audit *domain.AuditLog) error {
+	args := m.Called(writeOff, audit)
+	return args.Error(0)
+}
+
+func (m *MockWriteOffRepository) GetByID(id uint) (*domain.WriteOff, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.WriteOff), args.Error(1)
+}
+
+func (m *MockWriteOffRepository) List(offset, limit int) ([]domain.WriteOff, error) {
+	args := m.Called(offset, limit)
+	return args.Get(0).([]domain.WriteOff), args.Error(1)
+}
+
+func (m *MockWriteOffRepository) AddRecovery(writeOff *domain.WriteOff, recovery *domain.Recovery, audit *domain.AuditLog) error {
+	args := m.Called(writeOff, recovery, audit)
+	return args.Error(0)
+}
+
+var testProvisioningPolicy = domain.ProvisioningPolicy{Grades: []domain.GradeRule{
+	{Collectibility: domain.CollectibilityCurrent, MaxDPD: 0, ProvisionRate: 0.01},
+	{Collectibility: domain.CollectibilitySpecialMention, MaxDPD: 90, ProvisionRate: 0.05},
+	{Collectibility: domain.CollectibilitySubstandard, MaxDPD: 120, ProvisionRate: 0.15},
+	{Collectibility: domain.CollectibilityDoubtful, MaxDPD: 180, ProvisionRate: 0.5},
+	{Collectibility: domain.CollectibilityLoss, ProvisionRate: 1},
+}}
+
+func TestProvisioningPolicy(t *testing.T) {
+	require.NoError(t, testProvisioningPolicy.Validate())
+
+	assert.Equal(t, domain.CollectibilityCurrent, testProvisioningPolicy.Classify(0).Collectibility)
+	assert.Equal(t, domain.CollectibilitySpecialMention, testProvisioningPolicy.Classify(1).Collectibility)
+	assert.Equal(t, domain.CollectibilitySubstandard, testProvisioningPolicy.Classify(91).Collectibility)
+	assert.Equal(t, domain.CollectibilityDoubtful, testProvisioningPolicy.Classify(180).Collectibility)
+	assert.Equal(t, domain.CollectibilityLoss, testProvisioningPolicy.Classify(181).Collectibility)
+
+	decreasing := domain.ProvisioningPolicy{Grades: append([]domain.GradeRule(nil), testProvisioningPolicy.Grades...)}
+	decreasing.Grades[3].ProvisionRate = 0.1
+	assert.Error(t, decreasing.Validate())
+
+	assert.Error(t, domain.ProvisioningPolicy{Grades: testProvisioningPolicy.Grades[:4]}.Validate())
+}
+
+func TestProvisioningUseCase_Classify(t *testing.T) {
+	mockTxRepo := new(MockTransactionRepository)
+	useCase := usecase.NewProvisioningUseCase(new(MockProvisioningRepository), mockTxRepo, testProvisioningPolicy)
+
+	now := time.Now()
+	mockTxRepo.On("GetByID", uint(1)).Return(&domain.Transaction{
+		ID: 1, ContractNumber: "CTR-1", Status: domain.StatusApproved,
+		Installments: []domain.Installment{
+			{ID: 1, DueDate: now.AddDate(0, 0, -100), Amount: 1000000, Status: "paid"},
+			{ID: 2, DueDate: now.AddDate(0, 0, -95), Amount: 1000000, Status: "overdue"},
+			{ID: 3, DueDate: now.AddDate(0, 0, 5), Amount: 1000000, Status: "unpaid"},
+		},
+	}, nil)
+
+	classification, err := useCase.Classify(1)
+
+	require.NoError(t, err)
+	assert.Equal(t, 95, classification.DPD)
+	assert.Equal(t, domain.CollectibilitySubstandard, classification.Collectibility)
+	assert.Equal(t, "kurang_lancar", classification.Grade)
+	assert.Equal(t, 2000000.0, classification.Outstanding)
+	assert.Equal(t, 300000.0, classification.Provision)
+}
+
+func TestProvisioningUseCase_GenerateMonthly(t *testing.T) {
+	now := time.Date(2024, 7, 1, 2, 0, 0, 0, time.UTC)
+	start := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Records Report Of Previous Month", func(t *testing.T) {
+		mockRepo := new(MockProvisioningRepository)
+		useCase := usecase.NewProvisioningUseCase(mockRepo, new(MockTransactionRepository), testProvisioningPolicy)
+
+		overdue := end.AddDate(0, 0, -200)
+		mockRepo.On("GetReport", "2024-06").Return(nil, domain.NewError(domain.ErrNotFound, "provision_report_not_found", "provision report not found"))
+		mockRepo.On("ListExposures", end, uint(0), 500).Return([]domain.Exposure{
+			{TransactionID: 1, Outstanding: 3000000},
+			{TransactionID: 2, Outstanding: 2000000, OldestOverdue: &overdue},
+		}, nil)
+		mockRepo.On("WriteOffTotals", start, end).Return(1, 4500000.0, 250000.0, nil)
+		mockRepo.On("CreateReport", mock.Anything).Return(nil)
+
+		report, err := useCase.GenerateMonthly(now)
+
+		require.NoError(t, err)
+		require.NotNil(t, report)
+		assert.Equal(t, "2024-06", report.Period)
+		assert.Equal(t, 2, report.Contracts)
+		assert.Equal(t, 5000000.0, report.Outstanding)
+		assert.Equal(t, 2030000.0, report.Provision)
+		assert.Equal(t, 1, report.Grades[0].Contracts)
+		assert.Equal(t, 1, report.Grades[4].Contracts)
+		assert.Equal(t, 4500000.0, report.WrittenOffAmount)
+		assert.Equal(t, 250000.0, report.RecoveredAmount)
+	})
+
+	t.Run("Skips Recorded Month", func(t *testing.T) {
+		mockRepo := new(MockProvisioningRepository)
+		useCase := usecase.NewProvisioningUseCase(mockRepo, new(MockTransactionRepository), testProvisioningPolicy)
+
+		mockRepo.On("GetReport", "2024-06").Return(&domain.ProvisionReport{Period: "2024-06"}, nil)
+
+		report, err := useCase.GenerateMonthly(now)
+
+		require.NoError(t, err)
+		assert.Nil(t, report)
+		mockRepo.AssertNotCalled(t, "ListExposures", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestWriteOffUseCase_WriteOff(t *testing.T) {
+	actor := domain.Actor{ID: 7, Role: "finance"}
+	now := time.Now()
+
+	t.Run("Writes Off Macet Contract", func(t *testing.T) {
+		mockRepo := new(MockWriteOffRepository)
+		mockTxRepo := new(MockTransactionRepository)
+		useCase := usecase.NewWriteOffUseCase(mockRepo, mockTxRepo, testProvisioningPolicy)
+
+		mockTxRepo.On("GetByID", uint(1)).Return(&domain.Transaction{
+			ID: 1, CustomerID: 3, ContractNumber: "CTR-1", Status: domain.StatusApproved,
+			Installments: []domain.Installment{
+				{ID: 1, DueDate: now.AddDate(0, 0, -200), Amount: 1000000, LateFee: 100000, Status: "overdue", Version: 2},
+				{ID: 2, DueDate: now.AddDate(0, 0, -170), Amount: 1000000, LateFee: 100000, Status: "overdue", Version: 2},
+				{ID: 3, DueDate: now.AddDate(0, 0, 10), Amount: 1000000, Status: "unpaid", Version: 1},
+			},
+		}, nil)
+		mockRepo.On("Create", mock.MatchedBy(func(w *domain.WriteOff) bool {
+			return w.TransactionID == 1 && w.Collectibility == domain.CollectibilityLoss && w.DPD == 200 &&
+				w.Amount == 3000000 && w.LateFees == 200000 && len(w.WrittenOff) == 3 &&
+				len(w.Events) == 1 && w.Events[0].EventType == domain.EventContractWrittenOff
+		}), mock.MatchedBy(func(a *domain.AuditLog) bool {
+			return a.Action == "contract.written_off" && a.ActorID == 7
+		})).Return(nil)
+
+		writeOff, err := useCase.WriteOff(1, "uncollectible", actor)
+
+		require.NoError(t, err)
+		assert.Equal(t, 3200000.0, writeOff.Balance())
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Refuses Contract Not Macet", func(t *testing.T) {
+		mockRepo := new(MockWriteOffRepository)
+		mockTxRepo := new(MockTransactionRepository)
+		useCase := usecase.NewWriteOffUseCase(mockRepo, mockTxRepo, testProvisioningPolicy)
+
+		mockTxRepo.On("GetByID", uint(1)).Return(&domain.Transaction{
+			ID: 1, Status: domain.StatusApproved,
+			Installments: []domain.Installment{{ID: 1, DueDate: now.AddDate(0, 0, -150), Amount: 1000000, Status: "overdue"}},
+		}, nil)
+
+		_, err := useCase.WriteOff(1, "uncollectible", actor)
+
+		assert.True(t, errors.Is(err, domain.ErrConflict))
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestWriteOffUseCase_RecordRecovery(t *testing.T) {
+	actor := domain.Actor{ID: 7, Role: "finance"}
+	writtenOffAt := time.Now().AddDate(0, -1, 0)
+
+	t.Run("Records Recovery", func(t *testing.T) {
+		mockRepo := new(MockWriteOffRepository)
+		useCase := usecase.NewWriteOffUseCase(mockRepo, new(MockTransactionRepository), testProvisioningPolicy)
+
+		writeOff := &domain.WriteOff{ID: 4, Amount: 3000000, LateFees: 200000, RecoveredAmount: 1000000, WrittenOffAt: writtenOffAt, Version: 2}
+		mockRepo.On("GetByID", uint(4)).Return(writeOff, nil)
+		mockRepo.On("AddRecovery", writeOff, mock.MatchedBy(func(r *domain.Recovery) bool {
+			return r.Amount == 2200000 && r.RecordedBy == 7 && !r.ReceivedAt.IsZero()
+		}), mock.Anything).Return(nil)
+
+		result, err := useCase.RecordRecovery(4, &domain.Recovery{Amount: 2200000, Reference: "AUCTION-9"}, actor)
+
+		require.NoError(t, err)
+		assert.Len(t, result.Recoveries, 1)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Refuses Recovery Beyond Balance", func(t *testing.T) {
+		mockRepo := new(MockWriteOffRepository)
+		useCase := usecase.NewWriteOffUseCase(mockRepo, new(MockTransactionRepository), testProvisioningPolicy)
+
+		mockRepo.On("GetByID", uint(4)).Return(&domain.WriteOff{ID: 4, Amount: 3000000, RecoveredAmount: 2500000, WrittenOffAt: writtenOffAt}, nil)
+
+		_, err := useCase.RecordRecovery(4, &domain.Recovery{Amount: 600000}, actor)
+
+		assert.True(t, errors.Is(err, domain.ErrValidation))
+		mockRepo.AssertNotCalled(t, "AddRecovery", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,243 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+	"xyz-multifinance/internal/domain"
+	"xyz-multifinance/internal/middleware"
+	"xyz-multifinance/internal/pkg/ratelimit"
+	"xyz-multifinance/internal/pkg/signing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	redisClient "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type failingLimiter struct{}
+
+func (failingLimiter) Allow(ctx context.Context, key string, policy ratelimit.Policy) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("dial tcp: connection refused")
+}
+
+type recordingLimiter struct {
+	mu   sync.Mutex
+	keys []string
+	ratelimit.Limiter
+}
+
+func (l *recordingLimiter) Allow(ctx context.Context, key string, policy ratelimit.Policy) (ratelimit.Result, error) {
+	l.mu.Lock()
+	l.keys = append(l.keys, key)
+	l.mu.Unlock()
+	return l.Limiter.Allow(ctx, key, policy)
+}
+
+// noRateLimit stands in for the rate limiter of handlers under test
+func noRateLimit(c *gin.Context) {
+	c.Next()
+}
+
+// newRateLimitedRouter limits requests after the given authentication
+// middlewares, as routes are wired in main
+func newRateLimitedRouter(config middleware.RateLimiterConfig, auth ...gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.NewErrorHandlerMiddleware(zap.NewNop()))
+	routes := router.Group("", append(auth, middleware.NewRateLimiterMiddleware(config))...)
+	routes.GET("/api/v1/customers/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+	routes.POST("/api/v1/transactions", func(c *gin.Context) { c.Status(http.StatusCreated) })
+	return router
+}
+
+func TestMemoryLimiter_SlidingWindow(t *testing.T) {
+	now := time.Now()
+	limiter := ratelimit.NewMemoryLimiterWithClock(func() time.Time { return now })
+	policy := ratelimit.Policy{Algorithm: ratelimit.SlidingWindow, Limit: 2, Window: time.Minute}
+
+	first, _ := limiter.Allow(context.Background(), "key", policy)
+	second, _ := limiter.Allow(context.Background(), "key", policy)
+	third, _ := limiter.Allow(context.Background(), "key", policy)
+
+	assert.True(t, first.Allowed)
+	assert.Equal(t, 1, first.Remaining)
+	assert.True(t, second.Allowed)
+	assert.False(t, third.Allowed)
+	assert.Equal(t, time.Minute, third.RetryAfter)
+
+	now = now.Add(time.Minute + time.Second)
+	fourth, _ := limiter.Allow(context.Background(), "key", policy)
+	assert.True(t, fourth.Allowed)
+}
+
+func TestMemoryLimiter_TokenBucket(t *testing.T) {
+	now := time.Now()
+	limiter := ratelimit.NewMemoryLimiterWithClock(func() time.Time { return now })
+	policy := ratelimit.Policy{Algorithm: ratelimit.TokenBucket, Limit: 60, Window: time.Minute, Burst: 2}
+
+	first, _ := limiter.Allow(context.Background(), "key", policy)
+	second, _ := limiter.Allow(context.Background(), "key", policy)
+	third, _ := limiter.Allow(context.Background(), "key", policy)
+
+	assert.True(t, first.Allowed)
+	assert.Equal(t, 2, first.Limit)
+	assert.True(t, second.Allowed)
+	assert.False(t, third.Allowed)
+	assert.Equal(t, time.Second, third.RetryAfter)
+
+	// One token is refilled every second
+	now = now.Add(time.Second)
+	fourth, _ := limiter.Allow(context.Background(), "key", policy)
+	assert.True(t, fourth.Allowed)
+}
+
+func TestRedisLimiter_Allow(t *testing.T) {
+	client := new(MockRedisClient)
+	client.On("Eval", mock.Anything, isScript("zremrangebyscore"), []string{"rate_limit:default:ip:1"}, mock.Anything).
+		Return(redisClient.NewCmdResult([]interface{}{int64(0), int64(0), int64(1500), int64(1500)}, nil))
+
+	limiter := ratelimit.NewRedisLimiter(client)
+	result, err := limiter.Allow(context.Background(), "rate_limit:default:ip:1", ratelimit.Policy{Limit: 10, Window: time.Minute})
+
+	assert.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 10, result.Limit)
+	assert.Equal(t, 1500*time.Millisecond, result.RetryAfter)
+}
+
+func TestRateLimiterMiddleware(t *testing.T) {
+	policy := ratelimit.Policy{Algorithm: ratelimit.SlidingWindow, Limit: 1, Window: time.Minute}
+
+	t.Run("Headers And Retry-After", func(t *testing.T) {
+		router := newRateLimitedRouter(middleware.RateLimiterConfig{
+			Limiter: ratelimit.NewMemoryLimiter(),
+			Default: policy,
+		})
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/customers/1", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "1", w.Header().Get("X-RateLimit-Limit"))
+		assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+		assert.Equal(t, "60", w.Header().Get("X-RateLimit-Reset"))
+
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/customers/1", nil))
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "60", w.Header().Get("Retry-After"))
+	})
+
+	t.Run("Per Route Policy", func(t *testing.T) {
+		limiter := &recordingLimiter{Limiter: ratelimit.NewMemoryLimiter()}
+		router := newRateLimitedRouter(middleware.RateLimiterConfig{
+			Limiter: limiter,
+			Default: policy,
+			Policies: map[string]ratelimit.Policy{
+				"/api/v1/transactions": {Name: "transactions", Algorithm: ratelimit.TokenBucket, Limit: 10, Window: time.Minute, Burst: 5},
+			},
+		})
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/transactions", nil))
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, "5", w.Header().Get("X-RateLimit-Limit"))
+		assert.Equal(t, "rate_limit:transactions:ip:192.0.2.1", limiter.keys[0])
+	})
+
+	t.Run("Per User Key", func(t *testing.T) {
+		authConfig := middleware.AuthConfig{SecretKey: "jwt-secret"}
+		limiter := &recordingLimiter{Limiter: ratelimit.NewMemoryLimiter()}
+		router := newRateLimitedRouter(middleware.RateLimiterConfig{
+			Limiter: limiter,
+			Default: policy,
+		}, middleware.NewAuthMiddleware(authConfig))
+
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &middleware.Claims{
+			UserID:           42,
+			Role:             "admin",
+			RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+		}).SignedString([]byte(authConfig.SecretKey))
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/customers/1", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, []string{"rate_limit:default:user:42"}, limiter.keys)
+	})
+
+	t.Run("Per Partner Key", func(t *testing.T) {
+		partners := new(MockPartnerUseCase)
+		redis := new(MockRedisClient)
+		limiter := &recordingLimiter{Limiter: ratelimit.NewMemoryLimiter()}
+		router := newRateLimitedRouter(middleware.RateLimiterConfig{
+			Limiter: limiter,
+			Default: policy,
+		}, middleware.NewPartnerAuthMiddleware(middleware.PartnerAuthConfig{
+			Partners: partners,
+			Nonces:   signing.NewRedisNonceStore(redis),
+		}))
+
+		partners.On("Authenticate", "pk_1").Return(&domain.PartnerCredential{Partner: testPartner, Secret: testSecret}, nil)
+		redis.On("SetNX", mock.Anything, "nonce:pk_1:"+testNonce, 1, 10*time.Minute).Return(redisClient.NewBoolResult(true, nil))
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, signedRequest(`{}`, time.Now(), testNonce, testSecret))
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, []string{"rate_limit:default:partner:5"}, limiter.keys)
+	})
+
+	t.Run("Unverified API Key Ignored", func(t *testing.T) {
+		router := newRateLimitedRouter(middleware.RateLimiterConfig{
+			Limiter: ratelimit.NewMemoryLimiter(),
+			Default: policy,
+		})
+
+		for i, code := range []int{http.StatusOK, http.StatusTooManyRequests} {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/customers/1", nil)
+			req.Header.Set("X-API-Key", fmt.Sprintf("random-%d", i))
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, code, w.Code)
+		}
+	})
+
+	t.Run("Fail Open", func(t *testing.T) {
+		router := newRateLimitedRouter(middleware.RateLimiterConfig{
+			Limiter:  failingLimiter{},
+			Default:  policy,
+			FailOpen: true,
+		})
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/customers/1", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Fail Closed", func(t *testing.T) {
+		router := newRateLimitedRouter(middleware.RateLimiterConfig{
+			Limiter: failingLimiter{},
+			Default: policy,
+		})
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/customers/1", nil))
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+}
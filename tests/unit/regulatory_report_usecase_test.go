@@ -0,0 +1,250 @@
+package tests
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+	"xyz-multifinance/internal/domain"
+	"xyz-multifinance/internal/pkg/slik"
+	"xyz-multifinance/internal/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockRegulatoryReportRepository is a mock implementation of
+// domain.RegulatoryReportRepository
+type MockRegulatoryReportRepository struct {
+	mock.Mock
+}
+
+func (m *MockRegulatoryReportRepository) ListReportable(from, to time.Time, afterTransactionID uint, limit int) ([]domain.Transaction, error) {
+	args := m.Called(from, to, afterTransactionID, limit)
+	return args.Get(0).([]domain.Transaction), args.Error(1)
+}
+
+func (m *MockRegulatoryReportRepository) ListWriteOffs(transactionIDs []uint) (map[uint]domain.WriteOff, error) {
+	args := m.Called(transactionIDs)
+	return args.Get(0).(map[uint]domain.WriteOff), args.Error(1)
+}
+
+func (m *MockRegulatoryReportRepository) Create(report *domain.RegulatoryReport, audit *domain.AuditLog) error {
+	args := m.Called(report, audit)
+	return args.Error(0)
+}
+
+func (m *MockRegulatoryReportRepository) GetByID(id uint) (*domain.RegulatoryReport, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.RegulatoryReport), args.Error(1)
+}
+
+func (m *MockRegulatoryReportRepository) List(filter domain.RegulatoryReportFilter, offset, limit int) ([]domain.RegulatoryReport, error) {
+	args := m.Called(filter, offset, limit)
+	return args.Get(0).([]domain.RegulatoryReport), args.Error(1)
+}
+
+func (m *MockRegulatoryReportRepository) Submit(report *domain.RegulatoryReport, audit *domain.AuditLog) error {
+	args := m.Called(report, audit)
+	return args.Error(0)
+}
+
+// reportableContract returns a contract of customer 7 created in May 2024,
+// its first installment paid in June and the second overdue since June
+func reportableContract(nik string) domain.Transaction {
+	paidAt := time.Date(2024, 6, 3, 10, 0, 0, 0, time.Local)
+	return domain.Transaction{
+		ID: 1, ContractNumber: "CTR-1", CustomerID: 7, Status: domain.StatusApproved,
+		OTRAmount: 2700000, AdminFee: 100000, CreatedAt: time.Date(2024, 5, 2, 0, 0, 0, 0, time.Local),
+		Customer: &domain.Customer{
+			ID: 7, NIK: nik, FullName: "Budi", LegalName: "Budi Santoso", PlaceOfBirth: "Jakarta",
+			DateOfBirth: time.Date(1990, 1, 15, 0, 0, 0, 0, time.Local),
+		},
+		Installments: []domain.Installment{
+			{ID: 1, InstallmentNumber: 1, DueDate: time.Date(2024, 6, 2, 0, 0, 0, 0, time.Local), Amount: 1000000, Principal: 900000, Interest: 100000, Status: "paid", PaidAt: &paidAt},
+			{ID: 2, InstallmentNumber: 2, DueDate: time.Date(2024, 6, 15, 0, 0, 0, 0, time.Local), Amount: 1000000, Principal: 930000, Interest: 70000, Status: "overdue"},
+			{ID: 3, InstallmentNumber: 3, DueDate: time.Date(2024, 8, 2, 0, 0, 0, 0, time.Local), Amount: 1000000, Principal: 970000, Interest: 30000, Status: "unpaid"},
+		},
+	}
+}
+
+func TestFacilityRecord_Validate(t *testing.T) {
+	record := domain.FacilityRecord{
+		AccountNumber:  "CTR-1",
+		CIF:            "0000000007",
+		StartDate:      time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC),
+		MaturityDate:   time.Date(2024, 8, 2, 0, 0, 0, 0, time.UTC),
+		Plafond:        2800000,
+		Collectibility: domain.CollectibilityCurrent,
+		Condition:      domain.FacilityActive,
+	}
+	assert.Empty(t, record.Validate())
+
+	record.Condition = domain.FacilityPaidOff
+	record.Collectibility = 0
+	issues := record.Validate()
+	require.Len(t, issues, 2)
+	assert.Equal(t, "collectibility", issues[0].Field)
+	assert.Equal(t, "condition_date", issues[1].Field)
+}
+
+func TestSlik_EncodeFacilities(t *testing.T) {
+	conditionDate := time.Date(2024, 6, 20, 0, 0, 0, 0, time.UTC)
+	facilities := []domain.FacilityRecord{{
+		AccountNumber: "CTR-1", CIF: "0000000007",
+		StartDate:    time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC),
+		MaturityDate: time.Date(2024, 8, 2, 0, 0, 0, 0, time.UTC),
+		Plafond:      2800000.4, Collectibility: 1, Condition: domain.FacilityPaidOff, ConditionDate: &conditionDate,
+	}}
+	header := slik.Header{ReporterCode: "123456", Period: "2024-06", Segment: domain.SegmentFacility}
+
+	var fixed bytes.Buffer
+	require.NoError(t, slik.EncodeFacilities(&fixed, domain.ReportFixedWidth, header, facilities))
+	lines := strings.Split(strings.TrimSuffix(fixed.String(), "\r\n"), "\r\n")
+	require.Len(t, lines, 2)
+	assert.Equal(t, "H123456    202406F010000000001", lines[0])
+	assert.Len(t, lines[1], 1+25+20+8+8+15*4+4+1+1+2+8+15)
+	assert.True(t, strings.HasPrefix(lines[1], "DCTR-1                    0000000007          2024050220240802000000002800000"))
+
+	var csv bytes.Buffer
+	require.NoError(t, slik.EncodeFacilities(&csv, domain.ReportCSV, header, facilities))
+	assert.Contains(t, csv.String(), "CTR-1,0000000007,20240502,20240802,2800000,0,0,0,0,1,N,02,20240620,0")
+
+	var xml bytes.Buffer
+	require.NoError(t, slik.EncodeFacilities(&xml, domain.ReportXML, header, facilities))
+	assert.Contains(t, xml.String(), `<Report reporter="123456" period="2024-06" segment="F01" records="1">`)
+	assert.Contains(t, xml.String(), "<account_number>CTR-1</account_number>")
+
+	assert.Equal(t, "F01.123456.202406.xml", slik.FileName(header, domain.ReportXML))
+}
+
+func TestRegulatoryReportUseCase_Generate(t *testing.T) {
+	from := time.Date(2024, 6, 1, 0, 0, 0, 0, time.Local)
+	to := time.Date(2024, 7, 1, 0, 0, 0, 0, time.Local)
+	actor := domain.Actor{ID: 3, Role: "finance"}
+
+	t.Run("Facility File", func(t *testing.T) {
+		mockRepo := new(MockRegulatoryReportRepository)
+		useCase := usecase.NewRegulatoryReportUseCase(mockRepo, testProvisioningPolicy, "123456")
+
+		mockRepo.On("ListReportable", from, to, uint(0), 500).Return([]domain.Transaction{reportableContract("3171234567890123")}, nil)
+		mockRepo.On("ListWriteOffs", []uint(nil)).Return(map[uint]domain.WriteOff{}, nil)
+		var created *domain.RegulatoryReport
+		mockRepo.On("Create", mock.AnythingOfType("*domain.RegulatoryReport"), mock.MatchedBy(func(a *domain.AuditLog) bool {
+			return a.Action == "regulatory_report.generated" && a.ActorID == 3
+		})).Run(func(args mock.Arguments) {
+			created = args.Get(0).(*domain.RegulatoryReport)
+		}).Return(nil)
+
+		report, err := useCase.Generate("2024-06", domain.SegmentFacility, domain.ReportCSV, actor)
+
+		require.NoError(t, err)
+		require.NotNil(t, created)
+		assert.Equal(t, "F01.123456.202406.csv", report.FileName)
+		assert.Equal(t, 1, report.Records)
+		assert.Equal(t, domain.ReportGenerated, report.Status)
+		sum := sha256.Sum256(report.Content)
+		assert.Equal(t, hex.EncodeToString(sum[:]), report.Checksum)
+		// Installment 2, overdue 16 days at the end of June, is in arrears;
+		// installment 3 is outstanding only
+		assert.Contains(t, string(report.Content), "CTR-1,0000000007,20240502,20240802,2800000,1900000,930000,70000,16,2,N,00,,0")
+	})
+
+	t.Run("Payments After The Period Are Undone", func(t *testing.T) {
+		mockRepo := new(MockRegulatoryReportRepository)
+		useCase := usecase.NewRegulatoryReportUseCase(mockRepo, testProvisioningPolicy, "123456")
+
+		contract := reportableContract("3171234567890123")
+		paidAt := time.Date(2024, 7, 5, 0, 0, 0, 0, time.Local)
+		contract.Installments[1].Status = "paid"
+		contract.Installments[1].PaidAt = &paidAt
+		mockRepo.On("ListReportable", from, to, uint(0), 500).Return([]domain.Transaction{contract}, nil)
+		mockRepo.On("ListWriteOffs", []uint(nil)).Return(map[uint]domain.WriteOff{}, nil)
+		mockRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+		report, err := useCase.Generate("2024-06", domain.SegmentFacility, domain.ReportCSV, actor)
+
+		require.NoError(t, err)
+		assert.Contains(t, string(report.Content), ",1900000,930000,70000,16,2,N,00,")
+	})
+
+	t.Run("Rejects Invalid Records", func(t *testing.T) {
+		mockRepo := new(MockRegulatoryReportRepository)
+		useCase := usecase.NewRegulatoryReportUseCase(mockRepo, testProvisioningPolicy, "123456")
+
+		mockRepo.On("ListReportable", from, to, uint(0), 500).Return([]domain.Transaction{reportableContract("31712345")}, nil)
+		mockRepo.On("ListWriteOffs", []uint(nil)).Return(map[uint]domain.WriteOff{}, nil)
+
+		report, err := useCase.Generate("2024-06", domain.SegmentDebtor, domain.ReportFixedWidth, actor)
+
+		assert.Nil(t, report)
+		assert.ErrorIs(t, err, domain.ErrValidation)
+		assert.Contains(t, err.Error(), "nik")
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+
+		issues, err := useCase.Validate("2024-06", domain.SegmentDebtor)
+		require.NoError(t, err)
+		require.Len(t, issues, 1)
+		assert.Equal(t, "0000000007", issues[0].Reference)
+	})
+
+	t.Run("Rejects Periods Not Ended", func(t *testing.T) {
+		useCase := usecase.NewRegulatoryReportUseCase(new(MockRegulatoryReportRepository), testProvisioningPolicy, "123456")
+
+		_, err := useCase.Generate(time.Now().Format("2006-01"), domain.SegmentFacility, domain.ReportCSV, actor)
+
+		assert.ErrorIs(t, err, domain.ErrValidation)
+	})
+}
+
+func TestRegulatoryReportUseCase_Submit(t *testing.T) {
+	actor := domain.Actor{ID: 3, Role: "finance"}
+	checksum := strings.Repeat("ab", 32)
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockRegulatoryReportRepository)
+		useCase := usecase.NewRegulatoryReportUseCase(mockRepo, testProvisioningPolicy, "123456")
+
+		mockRepo.On("GetByID", uint(1)).Return(&domain.RegulatoryReport{ID: 1, Period: "2024-06", Segment: domain.SegmentFacility, Checksum: checksum, Status: domain.ReportGenerated}, nil)
+		mockRepo.On("Submit", mock.MatchedBy(func(r *domain.RegulatoryReport) bool {
+			return r.Status == domain.ReportSubmitted && *r.SubmittedBy == 3 && r.SubmissionReference == "SLIK-42"
+		}), mock.MatchedBy(func(a *domain.AuditLog) bool {
+			return a.Action == "regulatory_report.submitted" && a.EntityID == 1
+		})).Return(nil)
+
+		report, err := useCase.Submit(1, "SLIK-42", strings.ToUpper(checksum), actor)
+
+		require.NoError(t, err)
+		assert.NotNil(t, report.SubmittedAt)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Checksum Mismatch", func(t *testing.T) {
+		mockRepo := new(MockRegulatoryReportRepository)
+		useCase := usecase.NewRegulatoryReportUseCase(mockRepo, testProvisioningPolicy, "123456")
+
+		mockRepo.On("GetByID", uint(1)).Return(&domain.RegulatoryReport{ID: 1, Checksum: checksum, Status: domain.ReportGenerated}, nil)
+
+		_, err := useCase.Submit(1, "SLIK-42", strings.Repeat("cd", 32), actor)
+
+		assert.ErrorIs(t, err, domain.ErrValidation)
+		mockRepo.AssertNotCalled(t, "Submit", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Submitted Already", func(t *testing.T) {
+		mockRepo := new(MockRegulatoryReportRepository)
+		useCase := usecase.NewRegulatoryReportUseCase(mockRepo, testProvisioningPolicy, "123456")
+
+		mockRepo.On("GetByID", uint(1)).Return(&domain.RegulatoryReport{ID: 1, Status: domain.ReportSubmitted}, nil)
+
+		_, err := useCase.Submit(1, "SLIK-42", "", actor)
+
+		assert.ErrorIs(t, err, domain.ErrConflict)
+	})
+}
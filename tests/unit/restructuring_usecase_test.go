@@ -0,0 +1,243 @@
+package tests
+
+import (
+	"testing"
+	"time"
+	"xyz-multifinance/internal/domain"
+	"xyz-multifinance/internal/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockRestructuringRepository is a mock implementation of domain.RestructuringRepository
+type MockRestructuringRepository struct {
+	mock.Mock
+}
+
+func (m *MockRestructuringRepository) Create(restructuring *domain.Restructuring, audit *domain.AuditLog) error {
+	args := m.Called(restructuring, audit)
+	return args.Error(0)
+}
+
+func (m *MockRestructuringRepository) GetByID(id uint) (*domain.Restructuring, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Restructuring), args.Error(1)
+}
+
+func (m *MockRestructuringRepository) ListByTransaction(transactionID uint) ([]domain.Restructuring, error) {
+	args := m.Called(transactionID)
+	return args.Get(0).([]domain.Restructuring), args.Error(1)
+}
+
+func (m *MockRestructuringRepository) Approve(restructuring *domain.Restructuring, audit *domain.AuditLog) error {
+	args := m.Called(restructuring, audit)
+	return args.Error(0)
+}
+
+func (m *MockRestructuringRepository) Reject(restructuring *domain.Restructuring, audit *domain.AuditLog) error {
+	args := m.Called(restructuring, audit)
+	return args.Error(0)
+}
+
+var testRestructuringPolicy = domain.RestructuringPolicy{MaxTenor: 36, MaxHolidayMonths: 6, MaxInterestRate: 0.03}
+
+// restructurableTransaction returns an approved contract with one paid and
+// two outstanding installments of 1,000,000, the overdue one with a late fee
+func restructurableTransaction() *domain.Transaction {
+	now := time.Now()
+	return &domain.Transaction{
+		ID:         5,
+		CustomerID: 1,
+		Status:     domain.StatusApproved,
+		Tenor:      3,
+		Installments: []domain.Installment{
+			{ID: 1, InstallmentNumber: 1, Amount: 1000000, Status: "paid", DueDate: now.AddDate(0, -2, 0), Version: 2},
+			{ID: 2, InstallmentNumber: 2, Amount: 1000000, LateFee: 20000, Status: "overdue", DueDate: now.AddDate(0, -1, 0), Version: 2},
+			{ID: 3, InstallmentNumber: 3, Amount: 1000000, Status: "unpaid", DueDate: now.AddDate(0, 0, -1).AddDate(0, 1, 0), Version: 1},
+		},
+	}
+}
+
+func TestRestructuringUseCase_Request(t *testing.T) {
+	t.Run("Tenor Extension", func(t *testing.T) {
+		mockRepo := new(MockRestructuringRepository)
+		mockTxRepo := new(MockTransactionRepository)
+		useCase := usecase.NewRestructuringUseCase(mockRepo, mockTxRepo, testRestructuringPolicy)
+
+		mockTxRepo.On("GetByID", uint(5)).Return(restructurableTransaction(), nil)
+		mockRepo.On("Create",
+			mock.AnythingOfType("*domain.Restructuring"),
+			mock.MatchedBy(func(a *domain.AuditLog) bool {
+				return a.Action == "restructuring.requested" && a.ActorID == testOperator.ID
+			}),
+		).Return(nil)
+
+		restructuring, err := useCase.Request(&domain.RestructuringRequest{
+			TransactionID: 5,
+			Type:          domain.RestructuringTenorExtension,
+			Reason:        "job loss",
+			InterestRate:  0.01,
+			NewTenor:      6,
+			Actor:         testOperator,
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.RestructuringPending, restructuring.Status)
+		assert.Equal(t, 2020000.0, restructuring.OutstandingAmount)
+		assert.Equal(t, 2141200.0, restructuring.TotalAmount)
+		assert.Equal(t, 356866.67, restructuring.InstallmentAmount)
+		assert.True(t, restructuring.FirstDueDate.After(time.Now()))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Extension Must Add Installments", func(t *testing.T) {
+		mockTxRepo := new(MockTransactionRepository)
+		useCase := usecase.NewRestructuringUseCase(new(MockRestructuringRepository), mockTxRepo, testRestructuringPolicy)
+
+		mockTxRepo.On("GetByID", uint(5)).Return(restructurableTransaction(), nil)
+
+		_, err := useCase.Request(&domain.RestructuringRequest{
+			TransactionID: 5,
+			Type:          domain.RestructuringTenorExtension,
+			Reason:        "job loss",
+			NewTenor:      2,
+			Actor:         testOperator,
+		})
+
+		assert.ErrorIs(t, err, domain.ErrValidation)
+	})
+
+	t.Run("Payment Holiday Defers First Installment", func(t *testing.T) {
+		mockRepo := new(MockRestructuringRepository)
+		mockTxRepo := new(MockTransactionRepository)
+		useCase := usecase.NewRestructuringUseCase(mockRepo, mockTxRepo, testRestructuringPolicy)
+
+		tx := restructurableTransaction()
+		mockTxRepo.On("GetByID", uint(5)).Return(tx, nil)
+		mockRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+		restructuring, err := useCase.Request(&domain.RestructuringRequest{
+			TransactionID: 5,
+			Type:          domain.RestructuringPaymentHoliday,
+			Reason:        "hospitalised",
+			HolidayMonths: 2,
+			Actor:         testOperator,
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, restructuring.NewTenor)
+		// The next monthly due date of the overdue installment, then two
+		// holiday months
+		assert.Equal(t, tx.Installments[1].DueDate.AddDate(0, 2, 0).AddDate(0, 2, 0), restructuring.FirstDueDate)
+	})
+
+	t.Run("Transaction Not Approved", func(t *testing.T) {
+		mockTxRepo := new(MockTransactionRepository)
+		useCase := usecase.NewRestructuringUseCase(new(MockRestructuringRepository), mockTxRepo, testRestructuringPolicy)
+
+		mockTxRepo.On("GetByID", uint(5)).Return(&domain.Transaction{ID: 5, Status: domain.StatusPending}, nil)
+
+		_, err := useCase.Request(&domain.RestructuringRequest{
+			TransactionID: 5,
+			Type:          domain.RestructuringPaymentHoliday,
+			HolidayMonths: 1,
+			Actor:         testOperator,
+		})
+
+		assert.ErrorIs(t, err, domain.ErrConflict)
+	})
+}
+
+func TestRestructuringUseCase_Approve(t *testing.T) {
+	approver := domain.Actor{ID: 9, Role: "admin"}
+	pending := func() *domain.Restructuring {
+		return &domain.Restructuring{
+			ID:                11,
+			TransactionID:     5,
+			Type:              domain.RestructuringTenorExtension,
+			Status:            domain.RestructuringPending,
+			OutstandingAmount: 2020000,
+			InterestRate:      0.01,
+			NewTenor:          6,
+			RequestedBy:       testOperator.ID,
+		}
+	}
+
+	t.Run("Supersedes Outstanding Installments", func(t *testing.T) {
+		mockRepo := new(MockRestructuringRepository)
+		mockTxRepo := new(MockTransactionRepository)
+		useCase := usecase.NewRestructuringUseCase(mockRepo, mockTxRepo, testRestructuringPolicy)
+
+		mockRepo.On("GetByID", uint(11)).Return(pending(), nil)
+		mockTxRepo.On("GetByID", uint(5)).Return(restructurableTransaction(), nil)
+		mockRepo.On("Approve",
+			mock.MatchedBy(func(r *domain.Restructuring) bool {
+				total := 0.0
+				for _, installment := range r.Schedule {
+					total += installment.Amount
+				}
+				return r.Status == domain.RestructuringApproved &&
+					*r.ReviewedBy == approver.ID &&
+					len(r.Superseded) == 2 && r.Superseded[0].ID == 2 && r.Superseded[1].ID == 3 &&
+					len(r.Schedule) == 6 && r.Schedule[0].InstallmentNumber == 4 &&
+					r.Schedule[5].Amount == 356866.65 && total > 2141199.99 && total < 2141200.01 &&
+					len(r.Events) == 1 && r.Events[0].EventType == domain.EventContractRestructured
+			}),
+			mock.MatchedBy(func(a *domain.AuditLog) bool {
+				return a.Action == "restructuring.approved" && a.EntityID == 11
+			}),
+		).Return(nil)
+
+		restructuring, err := useCase.Approve(11, "documents verified", approver)
+
+		require.NoError(t, err)
+		assert.Equal(t, 121200.0, restructuring.AddedAmount())
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Requester Cannot Approve", func(t *testing.T) {
+		mockRepo := new(MockRestructuringRepository)
+		useCase := usecase.NewRestructuringUseCase(mockRepo, new(MockTransactionRepository), testRestructuringPolicy)
+
+		mockRepo.On("GetByID", uint(11)).Return(pending(), nil)
+
+		_, err := useCase.Approve(11, "", testOperator)
+
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+		mockRepo.AssertNotCalled(t, "Approve", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Balance Changed Since Request", func(t *testing.T) {
+		mockRepo := new(MockRestructuringRepository)
+		mockTxRepo := new(MockTransactionRepository)
+		useCase := usecase.NewRestructuringUseCase(mockRepo, mockTxRepo, testRestructuringPolicy)
+
+		tx := restructurableTransaction()
+		tx.Installments[1].Status = "paid"
+		mockRepo.On("GetByID", uint(11)).Return(pending(), nil)
+		mockTxRepo.On("GetByID", uint(5)).Return(tx, nil)
+
+		_, err := useCase.Approve(11, "", approver)
+
+		assert.ErrorIs(t, err, domain.ErrConflict)
+		mockRepo.AssertNotCalled(t, "Approve", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Already Reviewed", func(t *testing.T) {
+		mockRepo := new(MockRestructuringRepository)
+		useCase := usecase.NewRestructuringUseCase(mockRepo, new(MockTransactionRepository), testRestructuringPolicy)
+
+		restructuring := pending()
+		restructuring.Status = domain.RestructuringRejected
+		mockRepo.On("GetByID", uint(11)).Return(restructuring, nil)
+
+		_, err := useCase.Reject(11, "", approver)
+
+		assert.ErrorIs(t, err, domain.ErrConflict)
+	})
+}
@@ -0,0 +1,305 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+	"xyz-multifinance/internal/domain"
+	"xyz-multifinance/internal/usecase"
+
+	redisClient "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockReversalRepository is a mock implementation of domain.ReversalRepository
+type MockReversalRepository struct {
+	mock.Mock
+}
+
+func (m *MockReversalRepository) ReverseInstallment(installment *domain.Installment, reversal *domain.PaymentReversal, audit *domain.AuditLog) error {
+	args := m.Called(installment, reversal, audit)
+	return args.Error(0)
+}
+
+func (m *MockReversalRepository) ListReversals(transactionID uint, offset, limit int) ([]domain.PaymentReversal, error) {
+	args := m.Called(transactionID, offset, limit)
+	return args.Get(0).([]domain.PaymentReversal), args.Error(1)
+}
+
+func (m *MockReversalRepository) CreateRefund(refund *domain.Refund, audit *domain.AuditLog) error {
+	args := m.Called(refund, audit)
+	return args.Error(0)
+}
+
+func (m *MockReversalRepository) GetRefund(id uint) (*domain.Refund, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Refund), args.Error(1)
+}
+
+func (m *MockReversalRepository) CompleteRefund(refund *domain.Refund, audit *domain.AuditLog) error {
+	args := m.Called(refund, audit)
+	return args.Error(0)
+}
+
+func (m *MockReversalRepository) ListRefunds(transactionID uint, offset, limit int) ([]domain.Refund, error) {
+	args := m.Called(transactionID, offset, limit)
+	return args.Get(0).([]domain.Refund), args.Error(1)
+}
+
+func (m *MockReversalRepository) SumRefunds(paymentID uint) (float64, error) {
+	args := m.Called(paymentID)
+	return args.Get(0).(float64), args.Error(1)
+}
+
+var testLateFees = domain.LateFeePolicy{DailyRate: 0.001, MaxRate: 0.05}
+
+var testOperator = domain.Actor{ID: 3, Role: "operator"}
+
+// lockInstallment makes the distributed lock of an installment succeed with
+// the given fencing token
+func lockInstallment(mockRedis *MockRedisClient, id string, token int64) {
+	mockRedis.On("Eval", mock.Anything, mock.Anything, []string{"lock:installment:" + id, "fence:installment:" + id}, mock.Anything).
+		Return(redisClient.NewIntResult(token, nil))
+	mockRedis.On("Eval", mock.Anything, mock.Anything, []string{"lock:installment:" + id}, mock.Anything).
+		Return(redisClient.NewIntResult(1, nil))
+}
+
+func TestReversalUseCase_ReverseInstallment(t *testing.T) {
+	t.Run("Overdue Installment Of Paid Off Contract", func(t *testing.T) {
+		mockRepo := new(MockReversalRepository)
+		mockTxRepo := new(MockTransactionRepository)
+		mockRedis := new(MockRedisClient)
+		useCase := usecase.NewReversalUseCase(mockRepo, mockTxRepo, new(MockPaymentRepository), mockRedis, testLateFees)
+
+		lockInstallment(mockRedis, "2", 42)
+		paidAt := time.Now().AddDate(0, 0, -20)
+		mockTxRepo.On("GetInstallmentByID", uint(2)).Return(&domain.Installment{
+			ID:            2,
+			TransactionID: 5,
+			Amount:        1000000,
+			DueDate:       time.Now().AddDate(0, 0, -10),
+			Status:        "paid",
+			PaidAt:        &paidAt,
+			Version:       3,
+		}, nil)
+		mockTxRepo.On("GetByID", uint(5)).Return(&domain.Transaction{ID: 5, Installments: []domain.Installment{
+			{ID: 1, Status: "paid"},
+			{ID: 2, Status: "paid"},
+		}}, nil)
+
+		var audit *domain.AuditLog
+		mockRepo.On("ReverseInstallment",
+			mock.MatchedBy(func(i *domain.Installment) bool {
+				return i.Status == "overdue" && i.PaidAt == nil && i.LateFee == 10000 &&
+					i.FencingToken == 42 && len(i.Events) == 2 &&
+					i.Events[0].EventType == domain.EventInstallmentReversed &&
+					i.Events[1].EventType == domain.EventContractReopened
+			}),
+			mock.MatchedBy(func(r *domain.PaymentReversal) bool {
+				return r.RestoredStatus == "overdue" && r.OperatorID == testOperator.ID &&
+					r.PreviousPaidAt == &paidAt && r.Refund != nil && r.Refund.Amount == 50000
+			}),
+			mock.AnythingOfType("*domain.AuditLog"),
+		).Run(func(args mock.Arguments) {
+			audit = args.Get(2).(*domain.AuditLog)
+		}).Return(nil)
+
+		reversal, err := useCase.ReverseInstallment(&domain.ReverseInstallmentRequest{
+			InstallmentID: 2,
+			Version:       3,
+			Reason:        domain.ReversalMistaken,
+			RefundAmount:  50000,
+			Actor:         testOperator,
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, 10000.0, reversal.LateFee)
+		require.NotNil(t, audit)
+		assert.Equal(t, "installment.reversed", audit.Action)
+		assert.Equal(t, "operator", audit.ActorRole)
+
+		var details map[string]interface{}
+		require.NoError(t, json.Unmarshal(audit.Details, &details))
+		assert.Equal(t, "mistaken", details["reason"])
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Installment Not Yet Due Is Unpaid", func(t *testing.T) {
+		mockRepo := new(MockReversalRepository)
+		mockTxRepo := new(MockTransactionRepository)
+		mockRedis := new(MockRedisClient)
+		useCase := usecase.NewReversalUseCase(mockRepo, mockTxRepo, new(MockPaymentRepository), mockRedis, testLateFees)
+
+		lockInstallment(mockRedis, "1", 7)
+		mockTxRepo.On("GetInstallmentByID", uint(1)).Return(&domain.Installment{
+			ID:            1,
+			TransactionID: 5,
+			Amount:        1000000,
+			DueDate:       time.Now().AddDate(0, 0, 10),
+			Status:        "paid",
+			Version:       2,
+		}, nil)
+		mockTxRepo.On("GetByID", uint(5)).Return(&domain.Transaction{ID: 5, Installments: []domain.Installment{
+			{ID: 1, Status: "paid"},
+			{ID: 2, Status: "unpaid"},
+		}}, nil)
+		mockRepo.On("ReverseInstallment",
+			mock.MatchedBy(func(i *domain.Installment) bool {
+				return i.Status == "unpaid" && i.LateFee == 0 && len(i.Events) == 1
+			}),
+			mock.MatchedBy(func(r *domain.PaymentReversal) bool {
+				return r.Refund == nil
+			}),
+			mock.AnythingOfType("*domain.AuditLog"),
+		).Return(nil)
+
+		_, err := useCase.ReverseInstallment(&domain.ReverseInstallmentRequest{
+			InstallmentID: 1,
+			Version:       2,
+			Reason:        domain.ReversalBounced,
+			Actor:         testOperator,
+		})
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Stale Version", func(t *testing.T) {
+		mockRepo := new(MockReversalRepository)
+		mockTxRepo := new(MockTransactionRepository)
+		mockRedis := new(MockRedisClient)
+		useCase := usecase.NewReversalUseCase(mockRepo, mockTxRepo, new(MockPaymentRepository), mockRedis, testLateFees)
+
+		lockInstallment(mockRedis, "1", 7)
+		mockTxRepo.On("GetInstallmentByID", uint(1)).Return(&domain.Installment{ID: 1, Status: "paid", Version: 4}, nil)
+
+		_, err := useCase.ReverseInstallment(&domain.ReverseInstallmentRequest{
+			InstallmentID: 1,
+			Version:       3,
+			Reason:        domain.ReversalBounced,
+			Actor:         testOperator,
+		})
+
+		assert.ErrorIs(t, err, domain.ErrPreconditionFailed)
+		mockRepo.AssertNotCalled(t, "ReverseInstallment", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Installment Not Paid", func(t *testing.T) {
+		mockTxRepo := new(MockTransactionRepository)
+		mockRedis := new(MockRedisClient)
+		useCase := usecase.NewReversalUseCase(new(MockReversalRepository), mockTxRepo, new(MockPaymentRepository), mockRedis, testLateFees)
+
+		lockInstallment(mockRedis, "1", 7)
+		mockTxRepo.On("GetInstallmentByID", uint(1)).Return(&domain.Installment{ID: 1, Status: "unpaid", Version: 1}, nil)
+
+		_, err := useCase.ReverseInstallment(&domain.ReverseInstallmentRequest{
+			InstallmentID: 1,
+			Version:       1,
+			Reason:        domain.ReversalMistaken,
+			Actor:         testOperator,
+		})
+
+		assert.ErrorIs(t, err, domain.ErrConflict)
+	})
+
+	t.Run("Bounced Payment Cannot Be Refunded", func(t *testing.T) {
+		useCase := usecase.NewReversalUseCase(new(MockReversalRepository), new(MockTransactionRepository), new(MockPaymentRepository), new(MockRedisClient), testLateFees)
+
+		_, err := useCase.ReverseInstallment(&domain.ReverseInstallmentRequest{
+			InstallmentID: 1,
+			Version:       1,
+			Reason:        domain.ReversalBounced,
+			RefundAmount:  1000,
+			Actor:         testOperator,
+		})
+
+		assert.ErrorIs(t, err, domain.ErrValidation)
+	})
+}
+
+func TestReversalUseCase_CreateRefund(t *testing.T) {
+	t.Run("Refund Of Payment", func(t *testing.T) {
+		mockRepo := new(MockReversalRepository)
+		mockPaymentRepo := new(MockPaymentRepository)
+		useCase := usecase.NewReversalUseCase(mockRepo, new(MockTransactionRepository), mockPaymentRepo, nil, testLateFees)
+
+		paymentID := uint(8)
+		mockPaymentRepo.On("GetPayment", paymentID).Return(&domain.Payment{ID: 8, TransactionID: 5, Amount: 1000000}, nil)
+		mockRepo.On("SumRefunds", paymentID).Return(400000.0, nil)
+		mockRepo.On("CreateRefund",
+			mock.MatchedBy(func(r *domain.Refund) bool {
+				return r.TransactionID == 5 && r.Status == domain.RefundPending && r.OperatorID == testOperator.ID
+			}),
+			mock.MatchedBy(func(a *domain.AuditLog) bool {
+				return a.Action == "refund.created" && a.EntityType == "refund"
+			}),
+		).Return(nil)
+
+		refund, err := useCase.CreateRefund(&domain.Refund{PaymentID: &paymentID, Amount: 600000, Reason: "overpayment"}, testOperator)
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(5), refund.TransactionID)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Refunds Exceed Payment", func(t *testing.T) {
+		mockRepo := new(MockReversalRepository)
+		mockPaymentRepo := new(MockPaymentRepository)
+		useCase := usecase.NewReversalUseCase(mockRepo, new(MockTransactionRepository), mockPaymentRepo, nil, testLateFees)
+
+		paymentID := uint(8)
+		mockPaymentRepo.On("GetPayment", paymentID).Return(&domain.Payment{ID: 8, TransactionID: 5, Amount: 1000000}, nil)
+		mockRepo.On("SumRefunds", paymentID).Return(400000.0, nil)
+
+		_, err := useCase.CreateRefund(&domain.Refund{PaymentID: &paymentID, Amount: 600001, Reason: "overpayment"}, testOperator)
+
+		assert.ErrorIs(t, err, domain.ErrValidation)
+		mockRepo.AssertNotCalled(t, "CreateRefund", mock.Anything, mock.Anything)
+	})
+}
+
+func TestReversalUseCase_CompleteRefund(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockReversalRepository)
+		useCase := usecase.NewReversalUseCase(mockRepo, new(MockTransactionRepository), new(MockPaymentRepository), nil, testLateFees)
+
+		mockRepo.On("GetRefund", uint(4)).Return(&domain.Refund{ID: 4, Status: domain.RefundPending, Amount: 50000}, nil)
+		mockRepo.On("CompleteRefund",
+			mock.MatchedBy(func(r *domain.Refund) bool {
+				return r.Status == domain.RefundCompleted && r.Reference == "TRF-1" && r.CompletedAt != nil
+			}),
+			mock.MatchedBy(func(a *domain.AuditLog) bool {
+				return a.Action == "refund.completed" && a.EntityID == 4
+			}),
+		).Return(nil)
+
+		_, err := useCase.CompleteRefund(4, "TRF-1", testOperator)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Already Completed", func(t *testing.T) {
+		mockRepo := new(MockReversalRepository)
+		useCase := usecase.NewReversalUseCase(mockRepo, new(MockTransactionRepository), new(MockPaymentRepository), nil, testLateFees)
+
+		mockRepo.On("GetRefund", uint(4)).Return(&domain.Refund{ID: 4, Status: domain.RefundCompleted}, nil)
+
+		_, err := useCase.CompleteRefund(4, "TRF-1", testOperator)
+
+		assert.ErrorIs(t, err, domain.ErrConflict)
+	})
+}
+
+func TestLateFeePolicy_Fee(t *testing.T) {
+	due := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 0.0, testLateFees.Fee(1000000, due, due))
+	assert.Equal(t, 3000.0, testLateFees.Fee(1000000, due, due.AddDate(0, 0, 3)))
+	assert.Equal(t, 50000.0, testLateFees.Fee(1000000, due, due.AddDate(0, 0, 90)), "capped at the maximum rate")
+}
@@ -0,0 +1,158 @@
+package tests
+
+import (
+	"testing"
+	"time"
+	"xyz-multifinance/internal/domain"
+	"xyz-multifinance/internal/pkg/scorecard"
+	"xyz-multifinance/internal/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockCreditScoreRepository is a mock implementation of domain.CreditScoreRepository
+type MockCreditScoreRepository struct {
+	mock.Mock
+}
+
+func (m *MockCreditScoreRepository) Create(score *domain.CreditScore) error {
+	args := m.Called(score)
+	return args.Error(0)
+}
+
+func (m *MockCreditScoreRepository) ListByCustomer(customerID uint, offset, limit int) ([]domain.CreditScore, error) {
+	args := m.Called(customerID, offset, limit)
+	return args.Get(0).([]domain.CreditScore), args.Error(1)
+}
+
+func (m *MockCreditScoreRepository) GetPaymentHistory(customerID uint, asOf time.Time) (*domain.PaymentHistory, error) {
+	args := m.Called(customerID, asOf)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.PaymentHistory), args.Error(1)
+}
+
+// MockScoringUseCase is a mock implementation of domain.ScoringUseCase
+type MockScoringUseCase struct {
+	mock.Mock
+}
+
+func (m *MockScoringUseCase) Score(customerID uint) (*domain.CreditScore, error) {
+	args := m.Called(customerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.CreditScore), args.Error(1)
+}
+
+func (m *MockScoringUseCase) ListHistory(customerID uint, offset, limit int) ([]domain.CreditScore, error) {
+	args := m.Called(customerID, offset, limit)
+	return args.Get(0).([]domain.CreditScore), args.Error(1)
+}
+
+func loadScorecard(t *testing.T) *scorecard.Scorecard {
+	card, err := scorecard.LoadFile("../../configs/scorecard.yaml")
+	require.NoError(t, err)
+	return card
+}
+
+func TestScorecard_Score(t *testing.T) {
+	card := loadScorecard(t)
+
+	t.Run("Bins And Missing Values", func(t *testing.T) {
+		result := card.Score(map[string]float64{
+			domain.AttrAge:                 30,       // 60 of 80
+			domain.AttrSalary:              10000000, // 90 of 100, bins include their minimum
+			domain.AttrLimitUtilisation:    0.2,      // 100
+			domain.AttrTenorsUsed:          1,        // 60
+			domain.AttrPaidInstallments:    0,        // 20 of 90
+			domain.AttrMaxDaysLate:         0,        // 70
+			domain.AttrOverdueInstallments: 0,        // 80
+			// on_time_ratio unknown, 60 of 120
+		})
+
+		assert.Equal(t, 150+60+90+100+60+20+60+70+80, result.Score)
+		require.Len(t, result.Reasons, 4)
+		assert.Equal(t, "R05", result.Reasons[0].ReasonCode)
+		assert.Equal(t, 70, result.Reasons[0].Lost())
+		assert.Equal(t, "R06", result.Reasons[1].ReasonCode)
+		assert.Nil(t, result.Characteristics[5].Value)
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		card, err := scorecard.Parse([]byte(`{
+			"name": "test", "version": "1", "base_score": 100,
+			"characteristics": [
+				{"attribute": "salary", "reason_code": "R02", "bins": [{"max": 5000000, "points": 10}, {"min": 5000000, "points": 50}]}
+			]
+		}`), "json")
+
+		require.NoError(t, err)
+		assert.Equal(t, 150, card.Score(map[string]float64{"salary": 5000000}).Score)
+		assert.Equal(t, 110, card.Score(map[string]float64{"salary": 1}).Score)
+	})
+
+	t.Run("Invalid Bin", func(t *testing.T) {
+		_, err := scorecard.Parse([]byte(`
+name: test
+characteristics:
+  - attribute: age
+    reason_code: R01
+    bins:
+      - {min: 30, max: 20, points: 10}
+`), "yaml")
+
+		assert.Error(t, err)
+	})
+}
+
+func TestScoringUseCase_Score(t *testing.T) {
+	card := loadScorecard(t)
+
+	t.Run("Good Payer", func(t *testing.T) {
+		mockRepo := new(MockCreditScoreRepository)
+		mockCustomerRepo := new(MockCustomerRepository)
+		useCase := usecase.NewScoringUseCase(mockRepo, mockCustomerRepo, card)
+
+		mockCustomerRepo.On("GetByID", uint(1)).Return(&domain.Customer{ID: 1, DateOfBirth: time.Now().AddDate(-40, 0, 0), Salary: 25000000}, nil)
+		mockCustomerRepo.On("GetCreditLimits", uint(1)).Return([]domain.CreditLimit{
+			{Tenor: 1, Amount: 1000000, UsedAmount: 0},
+			{Tenor: 3, Amount: 4000000, UsedAmount: 1000000},
+		}, nil)
+		mockRepo.On("GetPaymentHistory", uint(1), mock.Anything).Return(&domain.PaymentHistory{PaidInstallments: 12, PaidOnTime: 12}, nil)
+		mockRepo.On("Create", mock.AnythingOfType("*domain.CreditScore")).Return(nil)
+
+		score, err := useCase.Score(1)
+
+		require.NoError(t, err)
+		assert.Equal(t, 850, score.Score)
+		assert.Equal(t, domain.OutcomeApprove, score.Outcome)
+		assert.Equal(t, 0.2, score.Attributes[domain.AttrLimitUtilisation])
+		assert.Equal(t, 1.0, score.Attributes[domain.AttrOnTimeRatio])
+		assert.Empty(t, score.Reasons)
+		assert.Equal(t, "2026.10", score.ScorecardVersion)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Late Payer Is Rejected With Reasons", func(t *testing.T) {
+		mockRepo := new(MockCreditScoreRepository)
+		mockCustomerRepo := new(MockCustomerRepository)
+		useCase := usecase.NewScoringUseCase(mockRepo, mockCustomerRepo, card)
+
+		mockCustomerRepo.On("GetByID", uint(1)).Return(&domain.Customer{ID: 1, DateOfBirth: time.Now().AddDate(-22, 0, 0), Salary: 4000000}, nil)
+		mockCustomerRepo.On("GetCreditLimits", uint(1)).Return([]domain.CreditLimit{{Tenor: 2, Amount: 2000000, UsedAmount: 1950000}}, nil)
+		mockRepo.On("GetPaymentHistory", uint(1), mock.Anything).Return(&domain.PaymentHistory{PaidInstallments: 4, PaidOnTime: 2, MaxDaysLate: 45, OverdueInstallments: 2}, nil)
+		mockRepo.On("Create", mock.Anything).Return(nil)
+
+		score, err := useCase.Score(1)
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.OutcomeReject, score.Outcome)
+		assert.Equal(t, []string{"R06", "R03", "R08", "R07"}, score.ReasonCodes())
+		assert.Equal(t, 120, score.Reasons[0].PointsLost)
+		assert.NotEmpty(t, score.Reasons[0].Description)
+	})
+}
@@ -0,0 +1,249 @@
+package tests
+
+import (
+	"testing"
+	"time"
+	"xyz-multifinance/internal/domain"
+	"xyz-multifinance/internal/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockScreeningRepository is a mock implementation of domain.ScreeningRepository
+type MockScreeningRepository struct {
+	mock.Mock
+}
+
+func (m *MockScreeningRepository) CountContracts(key domain.VelocityKey, value string, since time.Time) (int, error) {
+	args := m.Called(key, value, since)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockScreeningRepository) FindDuplicateIdentities(customer *domain.Customer) ([]domain.Customer, error) {
+	args := m.Called(customer)
+	return args.Get(0).([]domain.Customer), args.Error(1)
+}
+
+// MockScreeningUseCase is a mock implementation of domain.ScreeningUseCase
+type MockScreeningUseCase struct {
+	mock.Mock
+}
+
+func (m *MockScreeningUseCase) ScreenCustomer(customer *domain.Customer) (*domain.ScreeningResult, error) {
+	args := m.Called(customer)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ScreeningResult), args.Error(1)
+}
+
+func (m *MockScreeningUseCase) ScreenTransaction(tx *domain.Transaction) (*domain.ScreeningResult, error) {
+	args := m.Called(tx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ScreeningResult), args.Error(1)
+}
+
+var testScreeningPolicy = domain.ScreeningPolicy{
+	DuplicateIdentity: domain.RiskHold,
+	Velocity: []domain.VelocityRule{
+		{Name: "customer_daily", Key: domain.VelocityCustomer, Window: 24 * time.Hour, MaxContracts: 3, Action: domain.RiskHold},
+		{Name: "device_hourly", Key: domain.VelocityDevice, Window: time.Hour, MaxContracts: 3, Action: domain.RiskBlock},
+	},
+}
+
+func TestScreeningUseCase_ScreenCustomer(t *testing.T) {
+	customer := &domain.Customer{NIK: "3201234567890001", FullName: "Budi Santoso", PlaceOfBirth: "Bandung", Phone: "+62 812-3456-7890"}
+
+	t.Run("Blocks Blacklisted Phone", func(t *testing.T) {
+		mockRepo := new(MockScreeningRepository)
+		mockBlacklist := new(MockBlacklistRepository)
+		useCase := usecase.NewScreeningUseCase(mockRepo, mockBlacklist, new(MockCustomerRepository), testScreeningPolicy)
+
+		mockBlacklist.On("Match", map[domain.BlacklistType]string{
+			domain.BlacklistNIK:    "3201234567890001",
+			domain.BlacklistPhone:  "081234567890",
+			domain.BlacklistDevice: "",
+		}).Return([]domain.BlacklistEntry{{Type: domain.BlacklistPhone, Value: "081234567890", Reason: "fraud ring"}}, nil)
+		mockRepo.On("FindDuplicateIdentities", customer).Return([]domain.Customer{}, nil)
+
+		result, err := useCase.ScreenCustomer(customer)
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.RiskBlock, result.Flag)
+		assert.Equal(t, []string{"blacklisted_phone"}, result.Reasons(domain.RiskBlock))
+	})
+
+	t.Run("Holds Duplicate Identity", func(t *testing.T) {
+		mockRepo := new(MockScreeningRepository)
+		mockBlacklist := new(MockBlacklistRepository)
+		useCase := usecase.NewScreeningUseCase(mockRepo, mockBlacklist, new(MockCustomerRepository), testScreeningPolicy)
+
+		mockBlacklist.On("Match", mock.Anything).Return([]domain.BlacklistEntry{}, nil)
+		mockRepo.On("FindDuplicateIdentities", customer).Return([]domain.Customer{{ID: 4}, {ID: 9}}, nil)
+
+		result, err := useCase.ScreenCustomer(customer)
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.RiskHold, result.Flag)
+		require.Len(t, result.Hits, 1)
+		assert.Equal(t, domain.CheckDuplicateIdentity, result.Hits[0].Check)
+		assert.Contains(t, result.Hits[0].Detail, "4, 9")
+	})
+}
+
+func TestScreeningUseCase_ScreenTransaction(t *testing.T) {
+	t.Run("Velocity Rules", func(t *testing.T) {
+		mockRepo := new(MockScreeningRepository)
+		mockBlacklist := new(MockBlacklistRepository)
+		mockCustomerRepo := new(MockCustomerRepository)
+		useCase := usecase.NewScreeningUseCase(mockRepo, mockBlacklist, mockCustomerRepo, testScreeningPolicy)
+
+		mockCustomerRepo.On("GetByID", uint(1)).Return(&domain.Customer{ID: 1, NIK: "3201234567890001", RiskFlag: domain.RiskClear}, nil)
+		mockBlacklist.On("Match", mock.Anything).Return([]domain.BlacklistEntry{}, nil)
+		mockRepo.On("CountContracts", domain.VelocityCustomer, "1", mock.Anything).Return(3, nil)
+		mockRepo.On("CountContracts", domain.VelocityDevice, "device-1", mock.Anything).Return(2, nil)
+
+		result, err := useCase.ScreenTransaction(&domain.Transaction{CustomerID: 1, DeviceID: "device-1"})
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.RiskHold, result.Flag)
+		assert.Equal(t, []string{"velocity_customer_daily"}, result.Reasons(domain.RiskHold))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Device Rules Skip Contracts Without Device", func(t *testing.T) {
+		mockRepo := new(MockScreeningRepository)
+		mockBlacklist := new(MockBlacklistRepository)
+		mockCustomerRepo := new(MockCustomerRepository)
+		useCase := usecase.NewScreeningUseCase(mockRepo, mockBlacklist, mockCustomerRepo, testScreeningPolicy)
+
+		mockCustomerRepo.On("GetByID", uint(1)).Return(&domain.Customer{ID: 1, RiskFlag: domain.RiskHold}, nil)
+		mockBlacklist.On("Match", mock.Anything).Return([]domain.BlacklistEntry{}, nil)
+		mockRepo.On("CountContracts", domain.VelocityCustomer, "1", mock.Anything).Return(0, nil)
+
+		result, err := useCase.ScreenTransaction(&domain.Transaction{CustomerID: 1})
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.RiskHold, result.Flag)
+		assert.Equal(t, []string{"customer_held"}, result.Reasons(domain.RiskHold))
+		mockRepo.AssertNotCalled(t, "CountContracts", domain.VelocityDevice, mock.Anything, mock.Anything)
+	})
+}
+
+func TestCustomerUseCase_Register_Screening(t *testing.T) {
+	t.Run("Blocked Registration Is Refused", func(t *testing.T) {
+		mockRepo := new(MockCustomerRepository)
+		mockScreening := new(MockScreeningUseCase)
+		useCase := usecase.NewCustomerUseCase(mockRepo, mockScreening)
+
+		mockRepo.On("GetByNIK", "3201234567890001").Return(nil, domain.ErrNotFound)
+		result := &domain.ScreeningResult{Flag: domain.RiskClear}
+		result.Add(domain.ScreeningHit{Check: domain.CheckBlacklist, Action: domain.RiskBlock, Reason: "blacklisted_nik"})
+		mockScreening.On("ScreenCustomer", mock.Anything).Return(result, nil)
+
+		err := useCase.Register(&domain.Customer{NIK: "3201234567890001"})
+
+		var domainErr *domain.Error
+		require.ErrorAs(t, err, &domainErr)
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+		assert.Equal(t, "screening_blocked", domainErr.Code)
+		assert.NotContains(t, err.Error(), "blacklisted_nik")
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("Held Customer Is Registered With Flag", func(t *testing.T) {
+		mockRepo := new(MockCustomerRepository)
+		mockScreening := new(MockScreeningUseCase)
+		useCase := usecase.NewCustomerUseCase(mockRepo, mockScreening)
+
+		mockRepo.On("GetByNIK", "3201234567890001").Return(nil, domain.ErrNotFound)
+		result := &domain.ScreeningResult{Flag: domain.RiskClear}
+		result.Add(domain.ScreeningHit{Check: domain.CheckDuplicateIdentity, Action: domain.RiskHold, Reason: "duplicate_identity"})
+		mockScreening.On("ScreenCustomer", mock.Anything).Return(result, nil)
+		mockRepo.On("Create", mock.MatchedBy(func(c *domain.Customer) bool {
+			return c.RiskFlag == domain.RiskHold && len(c.RiskHits) == 1 && c.Phone == "081234567890"
+		})).Return(nil)
+
+		err := useCase.Register(&domain.Customer{NIK: "3201234567890001", Phone: "+62 812 3456 7890"})
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestTransactionUseCase_Create_Screening(t *testing.T) {
+	newTransaction := func() *domain.Transaction {
+		return &domain.Transaction{CustomerID: 1, OTRAmount: 1000000, Tenor: 1, InstallmentAmount: 1000000, DeviceID: "device-1"}
+	}
+
+	t.Run("Blocked Contract Is Refused", func(t *testing.T) {
+		mockRepo := new(MockTransactionRepository)
+		mockCustomerUseCase := new(MockCustomerUseCase)
+		mockScreening := new(MockScreeningUseCase)
+		useCase := usecase.NewTransactionUseCase(mockRepo, mockCustomerUseCase, new(MockMerchantRepository), mockScreening, nil)
+
+		mockCustomerUseCase.On("CheckCreditLimit", uint(1), 1000000.0, 1).Return(true, nil)
+		result := &domain.ScreeningResult{Flag: domain.RiskClear}
+		result.Add(domain.ScreeningHit{Check: domain.CheckVelocity, Action: domain.RiskBlock, Reason: "velocity_device_hourly"})
+		mockScreening.On("ScreenTransaction", mock.Anything).Return(result, nil)
+
+		err := useCase.Create(newTransaction())
+
+		var domainErr *domain.Error
+		require.ErrorAs(t, err, &domainErr)
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+		assert.Equal(t, "screening_blocked", domainErr.Code)
+		assert.NotContains(t, err.Error(), "velocity_device_hourly")
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("Held Contract Is Recorded Pending With Flag", func(t *testing.T) {
+		mockRepo := new(MockTransactionRepository)
+		mockCustomerUseCase := new(MockCustomerUseCase)
+		mockScreening := new(MockScreeningUseCase)
+		useCase := usecase.NewTransactionUseCase(mockRepo, mockCustomerUseCase, new(MockMerchantRepository), mockScreening, nil)
+
+		mockCustomerUseCase.On("CheckCreditLimit", uint(1), 1000000.0, 1).Return(true, nil)
+		result := &domain.ScreeningResult{Flag: domain.RiskClear}
+		result.Add(domain.ScreeningHit{Check: domain.CheckVelocity, Action: domain.RiskHold, Reason: "velocity_customer_daily"})
+		mockScreening.On("ScreenTransaction", mock.Anything).Return(result, nil)
+		mockRepo.On("Create", mock.MatchedBy(func(tx *domain.Transaction) bool {
+			return tx.Status == domain.StatusPending && tx.RiskFlag == domain.RiskHold && tx.DeviceID == "device-1"
+		})).Return(nil)
+
+		err := useCase.Create(newTransaction())
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestBlacklistUseCase_Add(t *testing.T) {
+	t.Run("Normalises Phone Numbers", func(t *testing.T) {
+		mockRepo := new(MockBlacklistRepository)
+		useCase := usecase.NewBlacklistUseCase(mockRepo)
+
+		mockRepo.On("Create", mock.MatchedBy(func(e *domain.BlacklistEntry) bool {
+			return e.Value == "081234567890" && *e.CreatedBy == 3
+		}), mock.MatchedBy(func(a *domain.AuditLog) bool {
+			return a.Action == "blacklist.added" && a.ActorID == 3
+		})).Return(nil)
+
+		err := useCase.Add(&domain.BlacklistEntry{Type: domain.BlacklistPhone, Value: "+62 812-3456-7890", Reason: "fraud ring"}, domain.Actor{ID: 3, Role: "operator"})
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Unknown Type", func(t *testing.T) {
+		useCase := usecase.NewBlacklistUseCase(new(MockBlacklistRepository))
+
+		err := useCase.Add(&domain.BlacklistEntry{Type: "email", Value: "a@b.c"}, domain.Actor{ID: 3})
+
+		assert.ErrorIs(t, err, domain.ErrValidation)
+	})
+}
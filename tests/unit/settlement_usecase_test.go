@@ -0,0 +1,114 @@
+package tests
+
+import (
+	"testing"
+	"time"
+	"xyz-multifinance/internal/domain"
+	"xyz-multifinance/internal/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockSettlementRepository struct {
+	mock.Mock
+}
+
+func (m *MockSettlementRepository) ListUnsettledTransactions(cutoff time.Time, limit int) ([]domain.Transaction, error) {
+	args := m.Called(cutoff, limit)
+	return args.Get(0).([]domain.Transaction), args.Error(1)
+}
+
+func (m *MockSettlementRepository) Create(settlement *domain.Settlement) error {
+	args := m.Called(settlement)
+	return args.Error(0)
+}
+
+func (m *MockSettlementRepository) GetByID(id uint) (*domain.Settlement, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Settlement), args.Error(1)
+}
+
+func (m *MockSettlementRepository) ListByMerchant(merchantID uint, offset, limit int) ([]domain.Settlement, error) {
+	args := m.Called(merchantID, offset, limit)
+	return args.Get(0).([]domain.Settlement), args.Error(1)
+}
+
+func (m *MockSettlementRepository) MarkPaid(id uint, reference string, paidAt time.Time) error {
+	args := m.Called(id, reference, paidAt)
+	return args.Error(0)
+}
+
+func uintPtr(v uint) *uint {
+	return &v
+}
+
+func TestSettlementUseCase_RunDaily(t *testing.T) {
+	now := time.Date(2024, 3, 15, 23, 0, 0, 0, time.UTC)
+
+	t.Run("Groups Contracts Per Merchant Net Of Commission", func(t *testing.T) {
+		mockSettlementRepo := new(MockSettlementRepository)
+		mockMerchantRepo := new(MockMerchantRepository)
+		useCase := usecase.NewSettlementUseCase(mockSettlementRepo, mockMerchantRepo)
+
+		mockSettlementRepo.On("ListUnsettledTransactions", now, mock.Anything).Return([]domain.Transaction{
+			{ID: 1, ContractNumber: "XYZ-1", MerchantID: uintPtr(10), OTRAmount: 10000000},
+			{ID: 2, ContractNumber: "XYZ-2", MerchantID: uintPtr(10), OTRAmount: 5000000},
+			{ID: 3, ContractNumber: "XYZ-3", MerchantID: uintPtr(20), OTRAmount: 3333333},
+		}, nil)
+		mockMerchantRepo.On("GetByID", uint(10)).Return(&domain.Merchant{ID: 10, CommissionRate: 0.025}, nil)
+		mockMerchantRepo.On("GetByID", uint(20)).Return(&domain.Merchant{ID: 20, CommissionRate: 0.015}, nil)
+		mockSettlementRepo.On("Create", mock.AnythingOfType("*domain.Settlement")).Return(nil)
+
+		settlements, err := useCase.RunDaily(now)
+
+		require.NoError(t, err)
+		require.Len(t, settlements, 2)
+
+		first := settlements[0]
+		assert.Equal(t, uint(10), first.MerchantID)
+		assert.Equal(t, time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC), first.SettlementDate)
+		assert.Equal(t, 2, first.TransactionCount)
+		assert.Equal(t, 15000000.0, first.GrossAmount)
+		assert.Equal(t, 375000.0, first.CommissionAmount)
+		assert.Equal(t, 14625000.0, first.NetAmount)
+		assert.Equal(t, domain.SettlementPending, first.Status)
+		assert.Len(t, first.Items, 2)
+
+		second := settlements[1]
+		assert.Equal(t, 50000.0, second.CommissionAmount) // 3333333 * 1.5% rounded to cents
+		assert.Equal(t, 3283333.0, second.NetAmount)
+		mockSettlementRepo.AssertNumberOfCalls(t, "Create", 2)
+	})
+
+	t.Run("Nothing To Settle", func(t *testing.T) {
+		mockSettlementRepo := new(MockSettlementRepository)
+		mockMerchantRepo := new(MockMerchantRepository)
+		useCase := usecase.NewSettlementUseCase(mockSettlementRepo, mockMerchantRepo)
+
+		mockSettlementRepo.On("ListUnsettledTransactions", now, mock.Anything).Return([]domain.Transaction{}, nil)
+
+		settlements, err := useCase.RunDaily(now)
+
+		assert.NoError(t, err)
+		assert.Empty(t, settlements)
+		mockSettlementRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+}
+
+func TestSettlementUseCase_MarkPaid(t *testing.T) {
+	mockSettlementRepo := new(MockSettlementRepository)
+	useCase := usecase.NewSettlementUseCase(mockSettlementRepo, new(MockMerchantRepository))
+
+	mockSettlementRepo.On("GetByID", uint(1)).Return(&domain.Settlement{ID: 1, Status: domain.SettlementPaid}, nil)
+	mockSettlementRepo.On("MarkPaid", uint(1), "TRF-001", mock.AnythingOfType("time.Time")).
+		Return(domain.NewError(domain.ErrConflict, "settlement_not_pending", "settlement is not pending"))
+
+	_, err := useCase.MarkPaid(1, "TRF-001")
+
+	assert.ErrorIs(t, err, domain.ErrConflict)
+}
@@ -0,0 +1,55 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"xyz-multifinance/internal/pkg/statement"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatement_ParseMT940(t *testing.T) {
+	mt940 := strings.Join([]string{
+		":20:STMT240305",
+		":25:0123456789",
+		":28C:00064/001",
+		":60F:C240304IDR100000000,00",
+		":61:2403050305C375000,00NTRFBCA123//9912345",
+		":86:VA PAYMENT 3935800000000042",
+		"BUDI SANTOSO",
+		":61:2403050305D6500,00NMSCNONREF//FEE001",
+		":86:ADMIN FEE",
+		":61:240305CK250000,00NTRFNONREF//9912346",
+		":86:TRANSFER VA 3935800000000043",
+		":62F:C240305IDR100618500,00",
+		"-",
+	}, "\r\n")
+
+	entries, err := statement.ParseMT940(strings.NewReader(mt940))
+
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "BCA123", entries[0].Reference)
+	assert.Equal(t, "3935800000000042", entries[0].Number)
+	assert.Equal(t, 375000.0, entries[0].Amount)
+	assert.Equal(t, time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC), entries[0].Date)
+	assert.Equal(t, "9912346", entries[1].Reference)
+	assert.Equal(t, "3935800000000043", entries[1].Number)
+	assert.Equal(t, 250000.0, entries[1].Amount)
+}
+
+func TestStatement_ParseCSV(t *testing.T) {
+	t.Run("Missing Column", func(t *testing.T) {
+		_, err := statement.ParseCSV(strings.NewReader("date,reference,amount\n2024-03-05,REF1,1000\n"))
+
+		assert.Error(t, err)
+	})
+
+	t.Run("Invalid Amount", func(t *testing.T) {
+		_, err := statement.ParseCSV(strings.NewReader("date,reference,va_number,amount\n2024-03-05,REF1,3935800000000042,abc\n"))
+
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,72 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	httpHandler "xyz-multifinance/internal/delivery/http"
+	"xyz-multifinance/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// bearerToken returns the Authorization header of a valid token
+func bearerToken(t *testing.T, config middleware.AuthConfig, userID uint, role string) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &middleware.Claims{
+		UserID:           userID,
+		Role:             role,
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+	}).SignedString([]byte(config.SecretKey))
+	require.NoError(t, err)
+	return "Bearer " + token
+}
+
+func TestTransactionHandler_UpdateStatus_BackOffice(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	authConfig := middleware.AuthConfig{SecretKey: "jwt-secret"}
+	mockUseCase := new(MockTransactionUseCase)
+	router := gin.New()
+	router.Use(middleware.NewErrorHandlerMiddleware(zap.NewNop()))
+	httpHandler.NewTransactionHandler(router, mockUseCase, noRateLimit, noRateLimit,
+		middleware.NewAuthMiddleware(authConfig),
+		middleware.RequireRole("admin", "operator"),
+	)
+
+	bearer := func(role string) string {
+		return bearerToken(t, authConfig, 1, role)
+	}
+
+	tests := []struct {
+		name          string
+		authorization string
+		body          string
+		code          int
+	}{
+		{"Unauthenticated", "", `{"status":"cancelled"}`, http.StatusUnauthorized},
+		{"Customer Role", bearer("customer"), `{"status":"cancelled"}`, http.StatusForbidden},
+		{"Approval Refused", bearer("operator"), `{"status":"approved"}`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/transactions/5/status", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("If-Match", `"1"`)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.code, w.Code)
+		})
+	}
+	mockUseCase.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -43,11 +43,16 @@ func TestTransactionRepository_Create(t *testing.T) {
 		}
 
 		mock.ExpectBegin()
-		mock.ExpectQuery(`INSERT INTO "transactions" \("customer_id","contract_number","source","status","asset_name","otr_amount","admin_fee","installment_amount","interest_amount","tenor","version","created_at","updated_at","deleted_at"\) VALUES \(\$1,\$2,\$3,\$4,\$5,\$6,\$7,\$8,\$9,\$10,\$11,\$12,\$13,\$14\) RETURNING "id"`).
+		mock.ExpectQuery(`SELECT id FROM "customers" WHERE "customers"\."id" = \$1 AND "customers"\."deleted_at" IS NULL FOR SHARE`).
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectQuery(`INSERT INTO "transactions" \("customer_id","contract_number","source","partner_id","merchant_id","status","asset_name","otr_amount","admin_fee","installment_amount","interest_amount","tenor","device_id","risk_flag","risk_hits","version","created_at","updated_at","deleted_at"\) VALUES \(\$1,\$2,\$3,\$4,\$5,\$6,\$7,\$8,\$9,\$10,\$11,\$12,\$13,\$14,\$15,\$16,\$17,\$18,\$19\) RETURNING "id"`).
 			WithArgs(
 				tx.CustomerID,
 				tx.ContractNumber,
 				tx.Source,
+				nil, // partner_id
+				nil, // merchant_id
 				tx.Status,
 				tx.AssetName,
 				tx.OTRAmount,
@@ -55,6 +60,9 @@ func TestTransactionRepository_Create(t *testing.T) {
 				tx.InstallmentAmount,
 				tx.InterestAmount,
 				tx.Tenor,
+				"",               // device_id
+				domain.RiskClear, // risk_flag
+				nil,              // risk_hits
 				1,                // version
 				sqlmock.AnyArg(), // created_at
 				sqlmock.AnyArg(), // updated_at
@@ -64,11 +72,13 @@ func TestTransactionRepository_Create(t *testing.T) {
 
 		// Expect installment creation
 		for i := 1; i <= tx.Tenor; i++ {
-			mock.ExpectQuery(`INSERT INTO "installments" \("transaction_id","installment_number","amount","status","due_date","version","created_at","updated_at","deleted_at"\) VALUES \(\$1,\$2,\$3,\$4,\$5,\$6,\$7,\$8,\$9\) RETURNING "id"`).
+			mock.ExpectQuery(`INSERT INTO "installments" \("transaction_id","installment_number","amount","principal","interest","status","due_date","version","created_at","updated_at","deleted_at"\) VALUES \(\$1,\$2,\$3,\$4,\$5,\$6,\$7,\$8,\$9,\$10,\$11\) RETURNING "id"`).
 				WithArgs(
 					1, // transaction_id
 					i, // installment_number
 					tx.InstallmentAmount,
+					sqlmock.AnyArg(), // principal
+					sqlmock.AnyArg(), // interest
 					"unpaid",
 					sqlmock.AnyArg(), // due_date
 					1,                // version
@@ -102,11 +112,16 @@ func TestTransactionRepository_Create(t *testing.T) {
 		}
 
 		mock.ExpectBegin()
-		mock.ExpectQuery(`INSERT INTO "transactions" \("customer_id","contract_number","source","status","asset_name","otr_amount","admin_fee","installment_amount","interest_amount","tenor","version","created_at","updated_at","deleted_at"\) VALUES \(\$1,\$2,\$3,\$4,\$5,\$6,\$7,\$8,\$9,\$10,\$11,\$12,\$13,\$14\) RETURNING "id"`).
+		mock.ExpectQuery(`SELECT id FROM "customers" WHERE "customers"\."id" = \$1 AND "customers"\."deleted_at" IS NULL FOR SHARE`).
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectQuery(`INSERT INTO "transactions" \("customer_id","contract_number","source","partner_id","merchant_id","status","asset_name","otr_amount","admin_fee","installment_amount","interest_amount","tenor","device_id","risk_flag","risk_hits","version","created_at","updated_at","deleted_at"\) VALUES \(\$1,\$2,\$3,\$4,\$5,\$6,\$7,\$8,\$9,\$10,\$11,\$12,\$13,\$14,\$15,\$16,\$17,\$18,\$19\) RETURNING "id"`).
 			WithArgs(
 				tx.CustomerID,
 				tx.ContractNumber,
 				tx.Source,
+				nil, // partner_id
+				nil, // merchant_id
 				tx.Status,
 				tx.AssetName,
 				tx.OTRAmount,
@@ -114,6 +129,9 @@ func TestTransactionRepository_Create(t *testing.T) {
 				tx.InstallmentAmount,
 				tx.InterestAmount,
 				tx.Tenor,
+				"",               // device_id
+				domain.RiskClear, // risk_flag
+				nil,              // risk_hits
 				1,                // version
 				sqlmock.AnyArg(), // created_at
 				sqlmock.AnyArg(), // updated_at
@@ -217,7 +235,7 @@ func TestTransactionRepository_GetByID(t *testing.T) {
 
 		tx, err := repo.GetByID(id)
 
-		assert.Error(t, err)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
 		assert.Nil(t, tx)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
@@ -311,7 +329,7 @@ func TestTransactionRepository_GetByContractNumber(t *testing.T) {
 
 		tx, err := repo.GetByContractNumber(contractNumber)
 
-		assert.Error(t, err)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
 		assert.Nil(t, tx)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
@@ -376,6 +394,76 @@ func TestTransactionRepository_GetInstallments(t *testing.T) {
 	})
 }
 
+func TestTransactionRepository_ListOverdueInstallments(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{
+		Conn: db,
+	}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening gorm database", err)
+	}
+
+	repo := repository.NewTransactionRepository(gormDB)
+
+	t.Run("Only Approved Contracts", func(t *testing.T) {
+		asOf := time.Now()
+		rows := sqlmock.NewRows([]string{"id", "transaction_id", "installment_number", "amount", "status", "due_date"}).
+			AddRow(1, 7, 1, 916667, "unpaid", asOf.AddDate(0, 0, -3))
+
+		mock.ExpectQuery(`^SELECT "installments"\."id",(.+) FROM "installments" JOIN "transactions" t ON t\."id" = "installments"\."transaction_id" AND t\."deleted_at" IS NULL WHERE "installments"\."status" = \$1 AND "installments"\."due_date" < \$2 AND t\."status" = \$3 ORDER BY "installments"\."due_date" asc, "installments"\."id" asc LIMIT \$4`).
+			WithArgs("unpaid", asOf, domain.StatusApproved, 100).
+			WillReturnRows(rows)
+		mock.ExpectQuery(`^SELECT (.+) FROM "transactions" WHERE "transactions"\."id" = \$1`).
+			WithArgs(7).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(7, domain.StatusApproved))
+
+		installments, err := repo.ListOverdueInstallments(asOf, 100)
+
+		assert.NoError(t, err)
+		if assert.Len(t, installments, 1) {
+			assert.Equal(t, domain.StatusApproved, installments[0].Transaction.Status)
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestTransactionRepository_ListInstallmentsDueBetween(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{
+		Conn: db,
+	}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening gorm database", err)
+	}
+
+	repo := repository.NewTransactionRepository(gormDB)
+
+	t.Run("Only Approved Contracts", func(t *testing.T) {
+		from := time.Now()
+		to := from.AddDate(0, 0, 1)
+
+		mock.ExpectQuery(`^SELECT "installments"\."id",(.+) FROM "installments" JOIN "transactions" t ON t\."id" = "installments"\."transaction_id" AND t\."deleted_at" IS NULL WHERE "installments"\."status" = \$1 AND "installments"\."due_date" >= \$2 AND "installments"\."due_date" < \$3 AND "installments"\."id" > \$4 AND t\."status" = \$5 ORDER BY "installments"\."id" asc LIMIT \$6`).
+			WithArgs("unpaid", from, to, 0, domain.StatusApproved, 100).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "transaction_id", "status", "due_date"}))
+
+		installments, err := repo.ListInstallmentsDueBetween(from, to, 0, 100)
+
+		assert.NoError(t, err)
+		assert.Empty(t, installments)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
 func TestTransactionRepository_UpdateInstallment(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -401,6 +489,7 @@ func TestTransactionRepository_UpdateInstallment(t *testing.T) {
 			Status:            "paid",
 			DueDate:           time.Now().AddDate(0, 1, 0),
 			Version:           1,
+			FencingToken:      7,
 		}
 
 		mock.ExpectBegin()
@@ -409,12 +498,16 @@ func TestTransactionRepository_UpdateInstallment(t *testing.T) {
 				installment.TransactionID,
 				installment.InstallmentNumber,
 				installment.Amount,
+				installment.LateFee,
 				installment.Status,
 				installment.DueDate,
+				installment.PaidAt,
 				sqlmock.AnyArg(), // updated_at
 				installment.Version+1,
+				installment.FencingToken,
 				installment.ID,
 				installment.Version,
+				installment.FencingToken,
 			).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 		mock.ExpectCommit()
@@ -434,6 +527,7 @@ func TestTransactionRepository_UpdateInstallment(t *testing.T) {
 			Status:            "paid",
 			DueDate:           time.Now().AddDate(0, 1, 0),
 			Version:           1,
+			FencingToken:      7,
 		}
 
 		mock.ExpectBegin()
@@ -442,12 +536,16 @@ func TestTransactionRepository_UpdateInstallment(t *testing.T) {
 				installment.TransactionID,
 				installment.InstallmentNumber,
 				installment.Amount,
+				installment.LateFee,
 				installment.Status,
 				installment.DueDate,
+				installment.PaidAt,
 				sqlmock.AnyArg(), // updated_at
 				installment.Version+1,
+				installment.FencingToken,
 				installment.ID,
 				installment.Version,
+				installment.FencingToken,
 			).
 			WillReturnResult(sqlmock.NewResult(0, 0))
 		mock.ExpectRollback()
@@ -455,7 +553,7 @@ func TestTransactionRepository_UpdateInstallment(t *testing.T) {
 		err := repo.UpdateInstallment(installment)
 
 		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "optimistic lock error")
+		assert.ErrorIs(t, err, domain.ErrConflict)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
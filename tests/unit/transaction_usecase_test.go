@@ -1,12 +1,16 @@
 package tests
 
 import (
+	"encoding/json"
 	"testing"
+	"time"
 	"xyz-multifinance/internal/domain"
 	"xyz-multifinance/internal/usecase"
 
+	redisClient "github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 type MockTransactionRepository struct {
@@ -44,8 +48,8 @@ func (m *MockTransactionRepository) Delete(id uint) error {
 	return args.Error(0)
 }
 
-func (m *MockTransactionRepository) List(customerID uint, offset, limit int) ([]domain.Transaction, error) {
-	args := m.Called(customerID, offset, limit)
+func (m *MockTransactionRepository) List(filter domain.TransactionFilter, offset, limit int) ([]domain.Transaction, error) {
+	args := m.Called(filter, offset, limit)
 	return args.Get(0).([]domain.Transaction), args.Error(1)
 }
 
@@ -54,16 +58,36 @@ func (m *MockTransactionRepository) GetInstallments(transactionID uint) ([]domai
 	return args.Get(0).([]domain.Installment), args.Error(1)
 }
 
+func (m *MockTransactionRepository) GetInstallmentByID(id uint) (*domain.Installment, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Installment), args.Error(1)
+}
+
 func (m *MockTransactionRepository) UpdateInstallment(installment *domain.Installment) error {
 	args := m.Called(installment)
 	return args.Error(0)
 }
 
+func (m *MockTransactionRepository) ListOverdueInstallments(asOf time.Time, limit int) ([]domain.Installment, error) {
+	args := m.Called(asOf, limit)
+	return args.Get(0).([]domain.Installment), args.Error(1)
+}
+
+func (m *MockTransactionRepository) ListInstallmentsDueBetween(from, to time.Time, afterID uint, limit int) ([]domain.Installment, error) {
+	args := m.Called(from, to, afterID, limit)
+	return args.Get(0).([]domain.Installment), args.Error(1)
+}
+
 func TestTransactionUseCase_Create(t *testing.T) {
 	mockRepo := new(MockTransactionRepository)
 	mockCustomerUseCase := new(MockCustomerUseCase)
 
-	useCase := usecase.NewTransactionUseCase(mockRepo, mockCustomerUseCase, nil)
+	mockScreening := new(MockScreeningUseCase)
+
+	useCase := usecase.NewTransactionUseCase(mockRepo, mockCustomerUseCase, new(MockMerchantRepository), mockScreening, nil)
 
 	t.Run("Success", func(t *testing.T) {
 		tx := &domain.Transaction{
@@ -80,6 +104,7 @@ func TestTransactionUseCase_Create(t *testing.T) {
 		// Mock credit limit check
 		totalAmount := tx.OTRAmount + tx.AdminFee
 		mockCustomerUseCase.On("CheckCreditLimit", tx.CustomerID, totalAmount, tx.Tenor).Return(true, nil)
+		mockScreening.On("ScreenTransaction", tx).Return(&domain.ScreeningResult{Flag: domain.RiskClear}, nil)
 
 		// Mock transaction creation
 		mockRepo.On("Create", mock.MatchedBy(func(t *domain.Transaction) bool {
@@ -93,14 +118,217 @@ func TestTransactionUseCase_Create(t *testing.T) {
 				t.Tenor == tx.Tenor
 		})).Return(nil)
 
-		// Mock credit limit update
-		mockCustomerUseCase.On("UpdateCreditLimitUsage", tx.CustomerID, totalAmount, tx.Tenor).Return(nil)
-
 		err := useCase.Create(tx)
 
 		assert.NoError(t, err)
 		assert.NotEmpty(t, tx.ContractNumber)
 		mockRepo.AssertExpectations(t)
 		mockCustomerUseCase.AssertExpectations(t)
+
+		// Credit limit usage is charged by the transaction.created consumer
+		mockCustomerUseCase.AssertNotCalled(t, "UpdateCreditLimitUsage", mock.Anything, mock.Anything, mock.Anything)
+		require.Len(t, tx.Events, 1)
+		assert.Equal(t, domain.EventTransactionCreated, tx.Events[0].EventType)
+
+		var event domain.Event
+		require.NoError(t, json.Unmarshal(tx.Events[0].Payload, &event))
+		var data domain.TransactionCreated
+		require.NoError(t, event.Decode(&data))
+		assert.Equal(t, totalAmount, data.Amount)
+		assert.Equal(t, tx.ContractNumber, data.ContractNumber)
+	})
+
+	t.Run("Inactive Merchant", func(t *testing.T) {
+		mockRepo := new(MockTransactionRepository)
+		mockMerchantRepo := new(MockMerchantRepository)
+		useCase := usecase.NewTransactionUseCase(mockRepo, new(MockCustomerUseCase), mockMerchantRepo, new(MockScreeningUseCase), nil)
+
+		mockMerchantRepo.On("GetByID", uint(3)).Return(&domain.Merchant{ID: 3, Status: domain.MerchantInactive}, nil)
+
+		err := useCase.Create(&domain.Transaction{CustomerID: 1, MerchantID: uintPtr(3), OTRAmount: 1000000, Tenor: 1})
+
+		assert.ErrorIs(t, err, domain.ErrValidation)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+}
+
+func TestTransactionUseCase_PayInstallment(t *testing.T) {
+	t.Run("Retries On Conflict", func(t *testing.T) {
+		mockRepo := new(MockTransactionRepository)
+		mockCustomerUseCase := new(MockCustomerUseCase)
+		mockRedis := new(MockRedisClient)
+
+		useCase := usecase.NewTransactionUseCase(mockRepo, mockCustomerUseCase, new(MockMerchantRepository), new(MockScreeningUseCase), mockRedis)
+
+		// The fencing token is issued above the one stored on the row
+		mockRedis.On("Eval", mock.Anything, mock.Anything, []string{"lock:installment:1", "fence:installment:1"}, mock.MatchedBy(func(args []interface{}) bool {
+			return len(args) == 3 && args[2] == int64(41)
+		})).Return(redisClient.NewIntResult(42, nil))
+		mockRedis.On("Eval", mock.Anything, mock.Anything, []string{"lock:installment:1"}, mock.Anything).
+			Return(redisClient.NewIntResult(1, nil))
+
+		mockRepo.On("GetInstallmentByID", uint(1)).Return(&domain.Installment{ID: 1, TransactionID: 5, Status: "unpaid", Version: 1, FencingToken: 41}, nil).Twice()
+		mockRepo.On("GetByID", uint(5)).Return(&domain.Transaction{ID: 5, Status: domain.StatusApproved, Installments: []domain.Installment{
+			{ID: 1, Status: "unpaid"},
+			{ID: 2, Status: "unpaid"},
+		}}, nil)
+		mockRepo.On("UpdateInstallment", mock.AnythingOfType("*domain.Installment")).
+			Return(domain.NewError(domain.ErrConflict, "concurrent_modification", "concurrent modification detected")).Once()
+		mockRepo.On("GetInstallmentByID", uint(1)).Return(&domain.Installment{ID: 1, TransactionID: 5, Status: "unpaid", Version: 2}, nil).Once()
+		mockRepo.On("UpdateInstallment", mock.MatchedBy(func(i *domain.Installment) bool {
+			return i.FencingToken == 42 &&
+				len(i.Events) == 1 &&
+				i.Events[0].EventType == domain.EventInstallmentPaid
+		})).Return(nil).Once()
+
+		err := useCase.PayInstallment(1)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Already Paid", func(t *testing.T) {
+		mockRepo := new(MockTransactionRepository)
+		mockCustomerUseCase := new(MockCustomerUseCase)
+		mockRedis := new(MockRedisClient)
+
+		useCase := usecase.NewTransactionUseCase(mockRepo, mockCustomerUseCase, new(MockMerchantRepository), new(MockScreeningUseCase), mockRedis)
+
+		mockRedis.On("Eval", mock.Anything, mock.Anything, []string{"lock:installment:1", "fence:installment:1"}, mock.Anything).
+			Return(redisClient.NewIntResult(42, nil))
+		mockRedis.On("Eval", mock.Anything, mock.Anything, []string{"lock:installment:1"}, mock.Anything).
+			Return(redisClient.NewIntResult(1, nil))
+
+		mockRepo.On("GetInstallmentByID", uint(1)).Return(&domain.Installment{ID: 1, Status: "paid", Version: 2}, nil)
+
+		err := useCase.PayInstallment(1)
+
+		assert.ErrorIs(t, err, domain.ErrConflict)
+		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("Last Installment Pays Off Contract", func(t *testing.T) {
+		mockRepo := new(MockTransactionRepository)
+		mockRedis := new(MockRedisClient)
+
+		useCase := usecase.NewTransactionUseCase(mockRepo, new(MockCustomerUseCase), new(MockMerchantRepository), new(MockScreeningUseCase), mockRedis)
+
+		mockRedis.On("Eval", mock.Anything, mock.Anything, []string{"lock:installment:2", "fence:installment:2"}, mock.Anything).
+			Return(redisClient.NewIntResult(1, nil))
+		mockRedis.On("Eval", mock.Anything, mock.Anything, []string{"lock:installment:2"}, mock.Anything).
+			Return(redisClient.NewIntResult(1, nil))
+
+		partnerID := uint(9)
+		mockRepo.On("GetInstallmentByID", uint(2)).Return(&domain.Installment{ID: 2, TransactionID: 5, Status: "overdue", Version: 1}, nil)
+		mockRepo.On("GetByID", uint(5)).Return(&domain.Transaction{ID: 5, Status: domain.StatusApproved, PartnerID: &partnerID, Installments: []domain.Installment{
+			{ID: 1, Status: "paid"},
+			{ID: 2, Status: "overdue"},
+		}}, nil)
+		mockRepo.On("UpdateInstallment", mock.MatchedBy(func(i *domain.Installment) bool {
+			return len(i.Events) == 2 &&
+				i.Events[0].EventType == domain.EventInstallmentPaid &&
+				i.Events[1].EventType == domain.EventContractPaidOff &&
+				*i.Events[1].PartnerID == partnerID
+		})).Return(nil)
+
+		err := useCase.PayInstallment(2)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	for _, status := range []domain.TransactionStatus{domain.StatusPending, domain.StatusRejected, domain.StatusCancelled, domain.StatusExpired} {
+		t.Run("Contract "+string(status), func(t *testing.T) {
+			mockRepo := new(MockTransactionRepository)
+			mockRedis := new(MockRedisClient)
+
+			useCase := usecase.NewTransactionUseCase(mockRepo, new(MockCustomerUseCase), new(MockMerchantRepository), new(MockScreeningUseCase), mockRedis)
+
+			mockRedis.On("Eval", mock.Anything, mock.Anything, []string{"lock:installment:2", "fence:installment:2"}, mock.Anything).
+				Return(redisClient.NewIntResult(1, nil))
+			mockRedis.On("Eval", mock.Anything, mock.Anything, []string{"lock:installment:2"}, mock.Anything).
+				Return(redisClient.NewIntResult(1, nil))
+
+			mockRepo.On("GetInstallmentByID", uint(2)).Return(&domain.Installment{ID: 2, TransactionID: 5, Status: "unpaid", Version: 1}, nil)
+			mockRepo.On("GetByID", uint(5)).Return(&domain.Transaction{ID: 5, Status: status}, nil)
+
+			err := useCase.PayInstallment(2)
+
+			assert.ErrorIs(t, err, domain.ErrConflict)
+			mockRepo.AssertNotCalled(t, "UpdateInstallment", mock.Anything)
+		})
+	}
+}
+
+func TestTransactionUseCase_UpdateStatus_RecordsEvent(t *testing.T) {
+	mockRepo := new(MockTransactionRepository)
+	useCase := usecase.NewTransactionUseCase(mockRepo, new(MockCustomerUseCase), new(MockMerchantRepository), new(MockScreeningUseCase), nil)
+
+	mockRepo.On("GetByID", uint(5)).Return(&domain.Transaction{ID: 5, Status: domain.StatusPending, Version: 1}, nil)
+	mockRepo.On("Update", mock.MatchedBy(func(tx *domain.Transaction) bool {
+		var event domain.Event
+		var data domain.TransactionStatusChanged
+		return len(tx.Events) == 1 &&
+			tx.Events[0].EventType == domain.EventTransactionStatusChanged &&
+			json.Unmarshal(tx.Events[0].Payload, &event) == nil && event.Decode(&data) == nil &&
+			data.From == domain.StatusPending && data.To == domain.StatusCancelled
+	})).Return(nil)
+
+	tx, err := useCase.UpdateStatus(5, domain.StatusCancelled, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.StatusCancelled, tx.Status)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTransactionUseCase_UpdateStatus_IllegalTransition(t *testing.T) {
+	tests := []struct {
+		from, to domain.TransactionStatus
+	}{
+		{domain.StatusPending, domain.StatusApproved},
+		{domain.StatusPending, domain.StatusPending},
+		{domain.StatusApproved, domain.StatusCancelled},
+		{domain.StatusRejected, domain.StatusApproved},
+		{domain.StatusWrittenOff, domain.StatusApproved},
+		{domain.StatusCancelled, domain.StatusPending},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+" To "+string(tt.to), func(t *testing.T) {
+			mockRepo := new(MockTransactionRepository)
+			useCase := usecase.NewTransactionUseCase(mockRepo, new(MockCustomerUseCase), new(MockMerchantRepository), new(MockScreeningUseCase), nil)
+
+			mockRepo.On("GetByID", uint(5)).Return(&domain.Transaction{ID: 5, Status: tt.from, Version: 1}, nil)
+
+			_, err := useCase.UpdateStatus(5, tt.to, 1)
+
+			assert.ErrorIs(t, err, domain.ErrConflict)
+			mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+		})
+	}
+}
+
+func TestTransactionUseCase_MarkOverdueInstallments(t *testing.T) {
+	mockRepo := new(MockTransactionRepository)
+	useCase := usecase.NewTransactionUseCase(mockRepo, new(MockCustomerUseCase), new(MockMerchantRepository), new(MockScreeningUseCase), nil)
+
+	now := time.Now()
+	tx := &domain.Transaction{ID: 5, ContractNumber: "XYZ-1-1"}
+	mockRepo.On("ListOverdueInstallments", now, 100).Return([]domain.Installment{
+		{ID: 1, TransactionID: 5, Status: "unpaid", Version: 1, Transaction: tx},
+		{ID: 2, TransactionID: 5, Status: "unpaid", Version: 1, Transaction: tx},
+	}, nil)
+	mockRepo.On("UpdateInstallment", mock.MatchedBy(func(i *domain.Installment) bool {
+		return i.ID == 1 && i.Status == "overdue" &&
+			len(i.Events) == 1 && i.Events[0].EventType == domain.EventInstallmentOverdue
+	})).Return(nil)
+	mockRepo.On("UpdateInstallment", mock.MatchedBy(func(i *domain.Installment) bool {
+		return i.ID == 2
+	})).Return(domain.NewError(domain.ErrConflict, "concurrent_modification", "concurrent modification detected"))
+
+	marked, err := useCase.MarkOverdueInstallments(now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, marked)
+	mockRepo.AssertExpectations(t)
 }
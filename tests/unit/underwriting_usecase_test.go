@@ -0,0 +1,412 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+	"xyz-multifinance/internal/domain"
+	"xyz-multifinance/internal/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockUnderwritingRepository is a mock implementation of domain.UnderwritingRepository
+type MockUnderwritingRepository struct {
+	mock.Mock
+}
+
+func (m *MockUnderwritingRepository) Create(decision *domain.UnderwritingDecision, tx *domain.Transaction) error {
+	args := m.Called(decision, tx)
+	return args.Error(0)
+}
+
+func (m *MockUnderwritingRepository) GetByID(id uint) (*domain.UnderwritingDecision, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.UnderwritingDecision), args.Error(1)
+}
+
+func (m *MockUnderwritingRepository) GetByTransaction(transactionID uint) (*domain.UnderwritingDecision, error) {
+	args := m.Called(transactionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.UnderwritingDecision), args.Error(1)
+}
+
+func (m *MockUnderwritingRepository) ListQueue(offset, limit int) ([]domain.UnderwritingDecision, error) {
+	args := m.Called(offset, limit)
+	return args.Get(0).([]domain.UnderwritingDecision), args.Error(1)
+}
+
+func (m *MockUnderwritingRepository) Claim(decision *domain.UnderwritingDecision, audit *domain.AuditLog) error {
+	args := m.Called(decision, audit)
+	return args.Error(0)
+}
+
+func (m *MockUnderwritingRepository) Decide(decision *domain.UnderwritingDecision, tx *domain.Transaction, audit *domain.AuditLog) error {
+	args := m.Called(decision, tx, audit)
+	return args.Error(0)
+}
+
+func (m *MockUnderwritingRepository) ListStale(createdBefore time.Time, limit int) ([]domain.Transaction, error) {
+	args := m.Called(createdBefore, limit)
+	return args.Get(0).([]domain.Transaction), args.Error(1)
+}
+
+func (m *MockUnderwritingRepository) Expire(tx *domain.Transaction) error {
+	args := m.Called(tx)
+	return args.Error(0)
+}
+
+func (m *MockUnderwritingRepository) GetExposure(customerID uint, tenor int, excludeTransactionID uint) (*domain.CreditExposure, error) {
+	args := m.Called(customerID, tenor, excludeTransactionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.CreditExposure), args.Error(1)
+}
+
+// MockBlacklistRepository is a mock implementation of domain.BlacklistRepository
+type MockBlacklistRepository struct {
+	mock.Mock
+}
+
+func (m *MockBlacklistRepository) Create(entry *domain.BlacklistEntry, audit *domain.AuditLog) error {
+	args := m.Called(entry, audit)
+	return args.Error(0)
+}
+
+func (m *MockBlacklistRepository) Delete(id uint, audit *domain.AuditLog) error {
+	args := m.Called(id, audit)
+	return args.Error(0)
+}
+
+func (m *MockBlacklistRepository) List(entryType domain.BlacklistType, offset, limit int) ([]domain.BlacklistEntry, error) {
+	args := m.Called(entryType, offset, limit)
+	return args.Get(0).([]domain.BlacklistEntry), args.Error(1)
+}
+
+func (m *MockBlacklistRepository) Match(identifiers map[domain.BlacklistType]string) ([]domain.BlacklistEntry, error) {
+	args := m.Called(identifiers)
+	return args.Get(0).([]domain.BlacklistEntry), args.Error(1)
+}
+
+var testUnderwritingPolicy = domain.UnderwritingPolicy{
+	ReferDBR:             0.3,
+	MaxDBR:               0.4,
+	MinAge:               21,
+	MaxAge:               60,
+	MaxOverdueDays:       30,
+	MaxAutoApproveAmount: 50000000,
+	ReviewSLA:            48 * time.Hour,
+}
+
+// underwritingFixture mocks a pending 3 month application of 3,000,000 with
+// installments of 1,100,000, by a 30 year old customer earning 10,000,000
+// with a limit of 5,000,000 and no other contracts
+type underwritingFixture struct {
+	repo      *MockUnderwritingRepository
+	txRepo    *MockTransactionRepository
+	customers *MockCustomerRepository
+	blacklist *MockBlacklistRepository
+	scoring   *MockScoringUseCase
+	tx        *domain.Transaction
+	exposure  *domain.CreditExposure
+	useCase   domain.UnderwritingUseCase
+}
+
+func newUnderwritingFixture() *underwritingFixture {
+	f := &underwritingFixture{
+		repo:      new(MockUnderwritingRepository),
+		txRepo:    new(MockTransactionRepository),
+		customers: new(MockCustomerRepository),
+		blacklist: new(MockBlacklistRepository),
+		scoring:   new(MockScoringUseCase),
+		tx: &domain.Transaction{
+			ID:                7,
+			ContractNumber:    "XYZ-1-7",
+			CustomerID:        1,
+			Status:            domain.StatusPending,
+			OTRAmount:         2900000,
+			AdminFee:          100000,
+			InstallmentAmount: 1100000,
+			Tenor:             3,
+			Version:           1,
+			CreatedAt:         time.Now(),
+			Customer: &domain.Customer{
+				ID:          1,
+				NIK:         "3201234567890001",
+				DateOfBirth: time.Now().AddDate(-30, 0, 0),
+				Salary:      10000000,
+			},
+		},
+		exposure: &domain.CreditExposure{},
+	}
+	f.useCase = usecase.NewUnderwritingUseCase(f.repo, f.txRepo, f.customers, f.blacklist, f.scoring, testUnderwritingPolicy)
+
+	f.txRepo.On("GetByContractNumber", "XYZ-1-7").Return(f.tx, nil)
+	f.repo.On("GetExposure", uint(1), 3, uint(7)).Return(f.exposure, nil)
+	f.customers.On("GetCreditLimits", uint(1)).Return([]domain.CreditLimit{{CustomerID: 1, Tenor: 3, Amount: 5000000}}, nil)
+	f.blacklist.On("Match", map[domain.BlacklistType]string{domain.BlacklistNIK: "3201234567890001"}).Return([]domain.BlacklistEntry{}, nil)
+	f.scoring.On("Score", uint(1)).Return(&domain.CreditScore{CustomerID: 1, Score: 690, Outcome: domain.OutcomeApprove}, nil)
+	return f
+}
+
+func TestUnderwritingUseCase_Underwrite(t *testing.T) {
+	t.Run("Approves When Every Rule Passes", func(t *testing.T) {
+		f := newUnderwritingFixture()
+		f.repo.On("Create",
+			mock.MatchedBy(func(d *domain.UnderwritingDecision) bool {
+				return d.Outcome == domain.OutcomeApprove && d.Status == domain.UnderwritingDecided && len(d.Rules) == 8
+			}),
+			mock.MatchedBy(func(tx *domain.Transaction) bool {
+				return tx.Status == domain.StatusApproved && len(tx.Events) == 2 &&
+					tx.Events[0].EventType == domain.EventTransactionStatusChanged &&
+					tx.Events[1].EventType == domain.EventContractApproved
+			}),
+		).Return(nil)
+
+		decision, err := f.useCase.Underwrite("XYZ-1-7")
+
+		require.NoError(t, err)
+		assert.Empty(t, decision.Reasons())
+		f.repo.AssertExpectations(t)
+	})
+
+	t.Run("Refers High Debt Burden", func(t *testing.T) {
+		f := newUnderwritingFixture()
+		f.exposure.MonthlyInstallments = 2000000 // 31% of salary with the new installment
+		f.repo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+		decision, err := f.useCase.Underwrite("XYZ-1-7")
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.OutcomeRefer, decision.Outcome)
+		assert.Equal(t, domain.UnderwritingQueued, decision.Status)
+		assert.Equal(t, []string{"dbr_high"}, decision.Reasons())
+		assert.Equal(t, f.tx.CreatedAt.Add(48*time.Hour), decision.DueAt)
+		assert.Equal(t, domain.StatusPending, f.tx.Status)
+		assert.Empty(t, f.tx.Events)
+	})
+
+	t.Run("Rejection Outweighs Referral", func(t *testing.T) {
+		f := newUnderwritingFixture()
+		f.tx.OTRAmount = 60000000 // beyond the limit and referred for its amount
+		f.blacklist.ExpectedCalls = nil
+		f.blacklist.On("Match", mock.Anything).Return([]domain.BlacklistEntry{{Type: domain.BlacklistNIK, Value: "3201234567890001"}}, nil)
+		f.repo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+		decision, err := f.useCase.Underwrite("XYZ-1-7")
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.OutcomeReject, decision.Outcome)
+		assert.ElementsMatch(t, []string{"insufficient_limit", "blacklisted", "manual_review_amount"}, decision.Reasons())
+		assert.Equal(t, domain.StatusRejected, f.tx.Status)
+	})
+
+	t.Run("Open Contracts Count Against The Limit", func(t *testing.T) {
+		f := newUnderwritingFixture()
+		f.exposure.TenorAmount = 2500000
+		f.repo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+		decision, err := f.useCase.Underwrite("XYZ-1-7")
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"insufficient_limit"}, decision.Reasons())
+	})
+
+	t.Run("Refers Contracts Held By Screening", func(t *testing.T) {
+		f := newUnderwritingFixture()
+		f.tx.RiskFlag = domain.RiskHold
+		f.tx.RiskHits = []domain.ScreeningHit{{Check: domain.CheckVelocity, Action: domain.RiskHold, Reason: "velocity_customer_daily"}}
+		f.repo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+		decision, err := f.useCase.Underwrite("XYZ-1-7")
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.UnderwritingQueued, decision.Status)
+		assert.Equal(t, []string{"risk_hold"}, decision.Reasons())
+		assert.Equal(t, "velocity_customer_daily", decision.Rules[7].Detail)
+	})
+
+	t.Run("Low Credit Score", func(t *testing.T) {
+		f := newUnderwritingFixture()
+		f.scoring.ExpectedCalls = nil
+		f.scoring.On("Score", uint(1)).Return(&domain.CreditScore{
+			CustomerID: 1,
+			Score:      420,
+			Outcome:    domain.OutcomeReject,
+			Reasons:    []domain.ScoreReason{{Code: "R06"}, {Code: "R08"}},
+		}, nil)
+		f.repo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+		decision, err := f.useCase.Underwrite("XYZ-1-7")
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.OutcomeReject, decision.Outcome)
+		assert.Equal(t, []string{"low_credit_score"}, decision.Reasons())
+		assert.Contains(t, decision.Rules[6].Detail, "R06, R08")
+	})
+
+	t.Run("Age At End Of Tenor", func(t *testing.T) {
+		f := newUnderwritingFixture()
+		f.tx.Customer.DateOfBirth = time.Now().AddDate(-61, 1, 0) // 60, turning 61 before the last installment
+		f.repo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+		decision, err := f.useCase.Underwrite("XYZ-1-7")
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"over_age"}, decision.Reasons())
+	})
+
+	t.Run("Existing Overdue", func(t *testing.T) {
+		f := newUnderwritingFixture()
+		oldest := time.Now().AddDate(0, 0, -10)
+		f.exposure.OverdueInstallments = 1
+		f.exposure.OldestOverdueDate = &oldest
+		f.repo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+		decision, err := f.useCase.Underwrite("XYZ-1-7")
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.OutcomeRefer, decision.Outcome)
+		assert.Equal(t, []string{"existing_overdue"}, decision.Reasons())
+	})
+
+	t.Run("No Longer Pending", func(t *testing.T) {
+		f := newUnderwritingFixture()
+		f.tx.Status = domain.StatusCancelled
+
+		decision, err := f.useCase.Underwrite("XYZ-1-7")
+
+		assert.NoError(t, err)
+		assert.Nil(t, decision)
+		f.repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestUnderwritingUseCase_Review(t *testing.T) {
+	queued := func() *domain.UnderwritingDecision {
+		return &domain.UnderwritingDecision{
+			ID:            4,
+			TransactionID: 7,
+			Outcome:       domain.OutcomeRefer,
+			Status:        domain.UnderwritingQueued,
+			Rules:         []domain.RuleResult{{Rule: domain.RuleDBR, Outcome: domain.OutcomeRefer, Reason: "dbr_high"}},
+		}
+	}
+
+	t.Run("Approve", func(t *testing.T) {
+		mockRepo := new(MockUnderwritingRepository)
+		mockTxRepo := new(MockTransactionRepository)
+		useCase := usecase.NewUnderwritingUseCase(mockRepo, mockTxRepo, new(MockCustomerRepository), new(MockBlacklistRepository), new(MockScoringUseCase), testUnderwritingPolicy)
+
+		mockRepo.On("GetByID", uint(4)).Return(queued(), nil)
+		mockTxRepo.On("GetByID", uint(7)).Return(&domain.Transaction{ID: 7, Status: domain.StatusPending, Version: 1}, nil)
+		mockRepo.On("Decide",
+			mock.MatchedBy(func(d *domain.UnderwritingDecision) bool {
+				return d.Status == domain.UnderwritingApproved && *d.ReviewedBy == testOperator.ID
+			}),
+			mock.MatchedBy(func(tx *domain.Transaction) bool {
+				return tx.Status == domain.StatusApproved && len(tx.Events) == 2
+			}),
+			mock.MatchedBy(func(a *domain.AuditLog) bool {
+				var details map[string]interface{}
+				json.Unmarshal(a.Details, &details)
+				return a.Action == "underwriting.approved" && a.EntityID == 4 && details["note"] == "income verified"
+			}),
+		).Return(nil)
+
+		decision, err := useCase.Approve(4, "income verified", testOperator)
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.UnderwritingApproved, decision.Status)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Claimed By Another Operator", func(t *testing.T) {
+		mockRepo := new(MockUnderwritingRepository)
+		useCase := usecase.NewUnderwritingUseCase(mockRepo, new(MockTransactionRepository), new(MockCustomerRepository), new(MockBlacklistRepository), new(MockScoringUseCase), testUnderwritingPolicy)
+
+		decision := queued()
+		other := uint(8)
+		decision.AssignedTo = &other
+		mockRepo.On("GetByID", uint(4)).Return(decision, nil)
+
+		_, err := useCase.Reject(4, "", testOperator)
+
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+		mockRepo.AssertNotCalled(t, "Decide", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Claim", func(t *testing.T) {
+		mockRepo := new(MockUnderwritingRepository)
+		useCase := usecase.NewUnderwritingUseCase(mockRepo, new(MockTransactionRepository), new(MockCustomerRepository), new(MockBlacklistRepository), new(MockScoringUseCase), testUnderwritingPolicy)
+
+		mockRepo.On("GetByID", uint(4)).Return(queued(), nil)
+		mockRepo.On("Claim",
+			mock.MatchedBy(func(d *domain.UnderwritingDecision) bool { return *d.AssignedTo == testOperator.ID }),
+			mock.MatchedBy(func(a *domain.AuditLog) bool { return a.Action == "underwriting.claimed" }),
+		).Return(nil)
+
+		_, err := useCase.Claim(4, testOperator)
+
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Already Decided", func(t *testing.T) {
+		mockRepo := new(MockUnderwritingRepository)
+		useCase := usecase.NewUnderwritingUseCase(mockRepo, new(MockTransactionRepository), new(MockCustomerRepository), new(MockBlacklistRepository), new(MockScoringUseCase), testUnderwritingPolicy)
+
+		decision := queued()
+		decision.Status = domain.UnderwritingExpired
+		mockRepo.On("GetByID", uint(4)).Return(decision, nil)
+
+		_, err := useCase.Approve(4, "", testOperator)
+
+		assert.ErrorIs(t, err, domain.ErrConflict)
+	})
+}
+
+func TestUnderwritingUseCase_ExpireStale(t *testing.T) {
+	mockRepo := new(MockUnderwritingRepository)
+	useCase := usecase.NewUnderwritingUseCase(mockRepo, new(MockTransactionRepository), new(MockCustomerRepository), new(MockBlacklistRepository), new(MockScoringUseCase), testUnderwritingPolicy)
+
+	now := time.Now()
+	mockRepo.On("ListStale", now.Add(-48*time.Hour), 100).Return([]domain.Transaction{
+		{ID: 7, Status: domain.StatusPending, Version: 1},
+		{ID: 8, Status: domain.StatusPending, Version: 2},
+	}, nil)
+	mockRepo.On("Expire", mock.MatchedBy(func(tx *domain.Transaction) bool { return tx.ID == 7 })).
+		Return(domain.NewError(domain.ErrConflict, "concurrent_modification", "concurrent modification detected"))
+	mockRepo.On("Expire", mock.MatchedBy(func(tx *domain.Transaction) bool {
+		return tx.ID == 8 && tx.Status == domain.StatusExpired && len(tx.Events) == 2 &&
+			tx.Events[1].EventType == domain.EventContractExpired
+	})).Return(nil)
+
+	expired, err := useCase.ExpireStale(now)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, expired)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUnderwritingEventHandler(t *testing.T) {
+	f := newUnderwritingFixture()
+	f.repo.On("Create", mock.Anything, mock.Anything).Return(domain.ErrEventProcessed)
+	handler := usecase.NewUnderwritingEventHandler(f.useCase)
+
+	data, _ := json.Marshal(domain.TransactionCreated{ContractNumber: "XYZ-1-7", CustomerID: 1, Amount: 3000000, Tenor: 3})
+	err := handler.Handle(context.Background(), &domain.Event{ID: "evt_1", Type: domain.EventTransactionCreated, Data: data})
+
+	// A redelivered event leaves the first decision in place
+	assert.NoError(t, err)
+	f.repo.AssertCalled(t, "Create", mock.Anything, mock.Anything)
+}
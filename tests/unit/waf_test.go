@@ -0,0 +1,208 @@
+package tests
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"xyz-multifinance/internal/middleware"
+	"xyz-multifinance/internal/pkg/waf"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func loadWAFRules(t *testing.T) *waf.Engine {
+	engine, err := waf.LoadFile("../../configs/waf_rules.yaml")
+	require.NoError(t, err)
+	return engine
+}
+
+func newWAFRouter(config middleware.WAFConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.NewErrorHandlerMiddleware(zap.NewNop()))
+	router.Use(middleware.NewWAFMiddleware(config))
+	router.GET("/api/v1/customers", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.POST("/api/v1/customers", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusCreated, string(body))
+	})
+	return router
+}
+
+func postJSON(router *gin.Engine, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/customers", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestWAFEngine_Inspect(t *testing.T) {
+	engine := loadWAFRules(t)
+
+	legitimate := []string{
+		"O'Brien",
+		"Jl. Sudirman No. 5; RT 03/RW 01",
+		"Select Furniture -- Cabang Jakarta",
+		"Update alamat; pindah ke Bandung",
+		"Toko Union Jaya",
+		"ukuran 10/20",
+	}
+	for _, value := range legitimate {
+		matches := engine.Inspect("/api/v1/customers", []waf.Field{{Target: waf.TargetJSON, Name: "full_name", Value: value}})
+		assert.Empty(t, matches, value)
+	}
+
+	attacks := map[string]string{
+		"1 UNION ALL SELECT password FROM users": "sqli-union-select",
+		"' OR '1'='1":                            "sqli-tautology",
+		"admin'--":                               "sqli-comment-terminator",
+		"1; DROP TABLE customers":                "sqli-stacked-query",
+		"1 AND pg_sleep(5)":                      "sqli-time-based",
+		"<script>alert(1)</script>":              "xss-script-tag",
+		`<img src=x onerror="alert(1)">`:         "xss-event-handler",
+		"../../etc/passwd":                       "path-traversal",
+	}
+	for value, ruleID := range attacks {
+		matches := engine.Inspect("/api/v1/customers", []waf.Field{{Target: waf.TargetQuery, Name: "q", Value: value}})
+		if assert.NotEmpty(t, matches, value) {
+			assert.Equal(t, ruleID, matches[0].RuleID, value)
+			assert.Equal(t, "query:q", matches[0].Field)
+		}
+	}
+}
+
+func TestWAFEngine_Parse(t *testing.T) {
+	t.Run("Invalid Pattern", func(t *testing.T) {
+		_, err := waf.Parse([]byte("rules:\n  - id: broken\n    pattern: '(['\n"))
+		assert.Error(t, err)
+	})
+
+	t.Run("Duplicate Rule", func(t *testing.T) {
+		_, err := waf.Parse([]byte("rules:\n  - id: a\n    pattern: x\n  - id: a\n    pattern: y\n"))
+		assert.Error(t, err)
+	})
+}
+
+func TestWAFMiddleware(t *testing.T) {
+	engine := loadWAFRules(t)
+
+	t.Run("Allows Legitimate JSON Body", func(t *testing.T) {
+		router := newWAFRouter(middleware.WAFConfig{Engine: engine})
+		body := `{"full_name":"O'Brien","address":"Jl. Sudirman No. 5; RT 03"}`
+
+		w := postJSON(router, body)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, body, w.Body.String())
+	})
+
+	t.Run("Blocks Nested JSON Value", func(t *testing.T) {
+		router := newWAFRouter(middleware.WAFConfig{Engine: engine})
+
+		w := postJSON(router, `{"contacts":[{"name":"x' OR 1=1"}]}`)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Equal(t, middleware.ProblemContentType, w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Body.String(), `"code":"request_blocked"`)
+	})
+
+	t.Run("Blocks Query Parameter", func(t *testing.T) {
+		router := newWAFRouter(middleware.WAFConfig{Engine: engine})
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/customers?q=1+UNION+SELECT+1", nil))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Detect Mode Logs Match", func(t *testing.T) {
+		core, logs := observer.New(zap.WarnLevel)
+		router := newWAFRouter(middleware.WAFConfig{Engine: engine, Mode: waf.ModeDetect, Logger: zap.New(core)})
+
+		w := postJSON(router, `{"notes":"<script>alert(1)</script>"}`)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		entries := logs.FilterMessage("waf rule matched").All()
+		if assert.Len(t, entries, 1) {
+			fields := entries[0].ContextMap()
+			assert.Equal(t, "xss-script-tag", fields["rule_id"])
+			assert.Equal(t, "json:notes", fields["field"])
+			assert.Equal(t, "/api/v1/customers", fields["route"])
+			assert.NotContains(t, fields, "value")
+		}
+	})
+
+	t.Run("Inspects Body Whatever Content-Type", func(t *testing.T) {
+		router := newWAFRouter(middleware.WAFConfig{Engine: engine})
+
+		for _, contentType := range []string{"text/plain", "multipart/form-data; boundary=x", ""} {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/customers", strings.NewReader(`{"full_name":"x' OR 1=1"}`))
+			req.Header.Set("Content-Type", contentType)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusForbidden, w.Code, contentType)
+		}
+	})
+
+	t.Run("Skips Upload Route", func(t *testing.T) {
+		router := newWAFRouter(middleware.WAFConfig{Engine: engine, UploadRoutes: []string{"/api/v1/customers"}})
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/customers", strings.NewReader("--x\r\n\r\n<script>\r\n--x--"))
+		req.Header.Set("Content-Type", "multipart/form-data; boundary=x")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+	})
+
+	t.Run("Rejects Deeply Nested Body", func(t *testing.T) {
+		router := newWAFRouter(middleware.WAFConfig{Engine: engine})
+
+		w := postJSON(router, strings.Repeat("[", 40)+strings.Repeat("]", 40))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"request_body_too_deep"`)
+	})
+
+	t.Run("Detect Mode Inspects Fields Before Depth Limit", func(t *testing.T) {
+		core, logs := observer.New(zap.WarnLevel)
+		router := newWAFRouter(middleware.WAFConfig{Engine: engine, Mode: waf.ModeDetect, Logger: zap.New(core)})
+
+		w := postJSON(router, `[{"notes":"<script>"},`+strings.Repeat("[", 40)+strings.Repeat("]", 40)+`]`)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Len(t, logs.FilterMessage("waf inspected body partially").All(), 1)
+		assert.Len(t, logs.FilterMessage("waf rule matched").All(), 1)
+	})
+
+	t.Run("Allowlisted Field", func(t *testing.T) {
+		engine, err := waf.NewEngine(waf.RuleSet{
+			Rules: []*waf.Rule{{ID: "xss-script-tag", Pattern: `(?i)<\s*script\b`}},
+			Allowlists: []waf.Allowlist{
+				{Route: "/api/v1/customers", Rules: []string{"xss-script-tag"}, Fields: []string{"json:notes"}},
+			},
+		})
+		require.NoError(t, err)
+		router := newWAFRouter(middleware.WAFConfig{Engine: engine})
+
+		assert.Equal(t, http.StatusCreated, postJSON(router, `{"notes":"<script>"}`).Code)
+		assert.Equal(t, http.StatusForbidden, postJSON(router, `{"full_name":"<script>"}`).Code)
+	})
+
+	t.Run("Body Too Large", func(t *testing.T) {
+		router := newWAFRouter(middleware.WAFConfig{Engine: engine, MaxBodyBytes: 16})
+
+		w := postJSON(router, `{"full_name":"John Doe"}`)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"request_body_too_large"`)
+	})
+}
@@ -0,0 +1,281 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"xyz-multifinance/internal/domain"
+	"xyz-multifinance/internal/pkg/crypto"
+	"xyz-multifinance/internal/pkg/signing"
+	"xyz-multifinance/internal/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockWebhookRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookRepository) CreateSubscription(subscription *domain.WebhookSubscription) error {
+	args := m.Called(subscription)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) GetSubscription(id uint) (*domain.WebhookSubscription, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookRepository) ListSubscriptions(partnerID uint) ([]domain.WebhookSubscription, error) {
+	args := m.Called(partnerID)
+	return args.Get(0).([]domain.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookRepository) UpdateSubscription(subscription *domain.WebhookSubscription) error {
+	args := m.Called(subscription)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) DeleteSubscription(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) FanOut(now time.Time, limit int) (int, error) {
+	args := m.Called(now, limit)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockWebhookRepository) ClaimDueDeliveries(now time.Time, lease time.Duration, limit int) ([]domain.WebhookDelivery, error) {
+	args := m.Called(now, lease, limit)
+	return args.Get(0).([]domain.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) GetDelivery(id uint) (*domain.WebhookDelivery, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) ListDeliveries(subscriptionID uint, status domain.DeliveryStatus, offset, limit int) ([]domain.WebhookDelivery, error) {
+	args := m.Called(subscriptionID, status, offset, limit)
+	return args.Get(0).([]domain.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) UpdateDelivery(delivery *domain.WebhookDelivery) error {
+	args := m.Called(delivery)
+	return args.Error(0)
+}
+
+var testRetryPolicy = domain.WebhookRetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 30 * time.Second,
+	MaxBackoff:     time.Hour,
+}
+
+// newTestDelivery returns a due delivery of an installment.paid event to url
+func newTestDelivery(t *testing.T, url, secret string, attempts int) domain.WebhookDelivery {
+	encrypted, err := crypto.Encrypt(secret)
+	require.NoError(t, err)
+
+	return domain.WebhookDelivery{
+		ID:             1,
+		SubscriptionID: 2,
+		EventID:        3,
+		Status:         domain.DeliveryPending,
+		Attempts:       attempts,
+		Subscription: &domain.WebhookSubscription{
+			ID:         2,
+			PartnerID:  1,
+			URL:        url,
+			EventTypes: domain.EventTypes{domain.EventInstallmentPaid},
+			Secret:     encrypted,
+			Status:     domain.SubscriptionActive,
+		},
+		Event: &domain.OutboxEvent{
+			ID:        3,
+			EventID:   "evt_123",
+			EventType: domain.EventInstallmentPaid,
+			Payload:   []byte(`{"id":"evt_123","type":"installment.paid"}`),
+		},
+	}
+}
+
+func TestWebhookUseCase_CreateSubscription(t *testing.T) {
+	initTestEncryption(t)
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockWebhookRepository)
+		mockPartnerRepo := new(MockPartnerRepository)
+		useCase := usecase.NewWebhookUseCase(mockRepo, mockPartnerRepo, http.DefaultClient, testRetryPolicy, 10)
+
+		mockPartnerRepo.On("GetByID", uint(1)).Return(&domain.Partner{ID: 1}, nil)
+		mockRepo.On("CreateSubscription", mock.AnythingOfType("*domain.WebhookSubscription")).Return(nil)
+
+		issued, err := useCase.CreateSubscription(&domain.WebhookSubscription{
+			PartnerID:  1,
+			URL:        "https://partner.example.com/hooks",
+			EventTypes: domain.EventTypes{domain.EventContractApproved},
+		})
+
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(issued.Secret, "whsec_"))
+		assert.Equal(t, domain.SubscriptionActive, issued.Status)
+
+		stored, err := crypto.Decrypt(issued.WebhookSubscription.Secret)
+		require.NoError(t, err)
+		assert.Equal(t, issued.Secret, stored)
+	})
+
+	t.Run("Unknown Event Type", func(t *testing.T) {
+		mockRepo := new(MockWebhookRepository)
+		useCase := usecase.NewWebhookUseCase(mockRepo, new(MockPartnerRepository), http.DefaultClient, testRetryPolicy, 10)
+
+		_, err := useCase.CreateSubscription(&domain.WebhookSubscription{
+			PartnerID:  1,
+			URL:        "https://partner.example.com/hooks",
+			EventTypes: domain.EventTypes{"customer.created"},
+		})
+
+		assert.ErrorIs(t, err, domain.ErrValidation)
+		mockRepo.AssertNotCalled(t, "CreateSubscription", mock.Anything)
+	})
+}
+
+func TestWebhookUseCase_Dispatch(t *testing.T) {
+	initTestEncryption(t)
+	now := time.Unix(1700000000, 0)
+
+	t.Run("Delivers Signed Request", func(t *testing.T) {
+		var received *http.Request
+		var body []byte
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received = r
+			body, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		mockRepo := new(MockWebhookRepository)
+		useCase := usecase.NewWebhookUseCase(mockRepo, new(MockPartnerRepository), server.Client(), testRetryPolicy, 10)
+
+		mockRepo.On("FanOut", now, 10).Return(1, nil)
+		mockRepo.On("ClaimDueDeliveries", now, mock.Anything, 10).
+			Return([]domain.WebhookDelivery{newTestDelivery(t, server.URL+"/hooks?v=1", "whsec_test", 0)}, nil)
+		mockRepo.On("UpdateDelivery", mock.MatchedBy(func(d *domain.WebhookDelivery) bool {
+			return d.Status == domain.DeliveryDelivered && d.Attempts == 1 && d.LastStatusCode == http.StatusNoContent
+		})).Return(nil)
+
+		delivered, err := useCase.Dispatch(context.Background(), now)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, delivered)
+		require.NotNil(t, received)
+		assert.Equal(t, "installment.paid", received.Header.Get(domain.HeaderWebhookEvent))
+		assert.Equal(t, "evt_123", received.Header.Get(domain.HeaderWebhookID))
+		assert.Equal(t, "1700000000", received.Header.Get(signing.HeaderTimestamp))
+
+		message := signing.StringToSign(received.Method, received.URL.RequestURI(),
+			received.Header.Get(signing.HeaderTimestamp), received.Header.Get(signing.HeaderNonce), body)
+		assert.True(t, signing.Verify([]byte("whsec_test"), message, received.Header.Get(signing.HeaderSignature)))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Retries With Backoff", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		mockRepo := new(MockWebhookRepository)
+		useCase := usecase.NewWebhookUseCase(mockRepo, new(MockPartnerRepository), server.Client(), testRetryPolicy, 10)
+
+		mockRepo.On("FanOut", now, 10).Return(0, nil)
+		mockRepo.On("ClaimDueDeliveries", now, mock.Anything, 10).
+			Return([]domain.WebhookDelivery{newTestDelivery(t, server.URL, "whsec_test", 1)}, nil)
+		mockRepo.On("UpdateDelivery", mock.MatchedBy(func(d *domain.WebhookDelivery) bool {
+			return d.Status == domain.DeliveryPending &&
+				d.Attempts == 2 &&
+				d.LastStatusCode == http.StatusInternalServerError &&
+				d.NextAttemptAt.Equal(now.Add(time.Minute))
+		})).Return(nil)
+
+		delivered, err := useCase.Dispatch(context.Background(), now)
+
+		require.NoError(t, err)
+		assert.Equal(t, 0, delivered)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Dead Letters After Max Attempts", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		mockRepo := new(MockWebhookRepository)
+		useCase := usecase.NewWebhookUseCase(mockRepo, new(MockPartnerRepository), server.Client(), testRetryPolicy, 10)
+
+		mockRepo.On("FanOut", now, 10).Return(0, nil)
+		mockRepo.On("ClaimDueDeliveries", now, mock.Anything, 10).
+			Return([]domain.WebhookDelivery{newTestDelivery(t, server.URL, "whsec_test", 2)}, nil)
+		mockRepo.On("UpdateDelivery", mock.MatchedBy(func(d *domain.WebhookDelivery) bool {
+			return d.Status == domain.DeliveryDead && d.Attempts == 3
+		})).Return(nil)
+
+		_, err := useCase.Dispatch(context.Background(), now)
+
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestWebhookUseCase_RetryDelivery(t *testing.T) {
+	t.Run("Requeues Dead Delivery", func(t *testing.T) {
+		mockRepo := new(MockWebhookRepository)
+		useCase := usecase.NewWebhookUseCase(mockRepo, new(MockPartnerRepository), http.DefaultClient, testRetryPolicy, 10)
+
+		mockRepo.On("GetDelivery", uint(1)).Return(&domain.WebhookDelivery{ID: 1, Status: domain.DeliveryDead, Attempts: 3}, nil)
+		mockRepo.On("UpdateDelivery", mock.MatchedBy(func(d *domain.WebhookDelivery) bool {
+			return d.Status == domain.DeliveryPending && d.Attempts == 0
+		})).Return(nil)
+
+		_, err := useCase.RetryDelivery(1)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Not Dead", func(t *testing.T) {
+		mockRepo := new(MockWebhookRepository)
+		useCase := usecase.NewWebhookUseCase(mockRepo, new(MockPartnerRepository), http.DefaultClient, testRetryPolicy, 10)
+
+		mockRepo.On("GetDelivery", uint(1)).Return(&domain.WebhookDelivery{ID: 1, Status: domain.DeliveryDelivered}, nil)
+
+		_, err := useCase.RetryDelivery(1)
+
+		assert.ErrorIs(t, err, domain.ErrConflict)
+		mockRepo.AssertNotCalled(t, "UpdateDelivery", mock.Anything)
+	})
+}
+
+func TestWebhookRetryPolicy_Backoff(t *testing.T) {
+	policy := domain.WebhookRetryPolicy{InitialBackoff: 30 * time.Second, MaxBackoff: 5 * time.Minute}
+
+	assert.Equal(t, 30*time.Second, policy.Backoff(1))
+	assert.Equal(t, time.Minute, policy.Backoff(2))
+	assert.Equal(t, 4*time.Minute, policy.Backoff(4))
+	assert.Equal(t, 5*time.Minute, policy.Backoff(5))
+	assert.Equal(t, 5*time.Minute, policy.Backoff(50))
+}